@@ -20,12 +20,19 @@
 //	@name						Authorization
 //	@description				Type "Bearer" followed by a space and JWT token.
 //
+//	@securityDefinitions.apikey	RunnerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Type "Bearer" followed by a space and a runner token.
+//
 //	@tag.name			Authentication
 //	@tag.description	User authentication and authorization operations
 //	@tag.name			Tasks
 //	@tag.description	Task management operations
 //	@tag.name			Executions
 //	@tag.description	Task execution operations
+//	@tag.name			Runners
+//	@tag.description	Job API for remote runner agents
 package main
 
 import (
@@ -40,19 +47,28 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
 	"github.com/voidrunnerhq/voidrunner/internal/api/routes"
 	"github.com/voidrunnerhq/voidrunner/internal/auth"
 	"github.com/voidrunnerhq/voidrunner/internal/config"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
 	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 	"github.com/voidrunnerhq/voidrunner/internal/queue"
 	"github.com/voidrunnerhq/voidrunner/internal/services"
+	"github.com/voidrunnerhq/voidrunner/internal/templates"
 	"github.com/voidrunnerhq/voidrunner/internal/worker"
 	"github.com/voidrunnerhq/voidrunner/pkg/logger"
 	"github.com/voidrunnerhq/voidrunner/pkg/utils"
 )
 
 func main() {
+	// Sandboxed scripts re-execute this binary to set up their namespaces
+	if executor.SandboxInit() {
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
@@ -95,6 +111,15 @@ func main() {
 
 	log.Info("database initialized successfully")
 
+	// Open the spilled output store, which must be on the schedulers' volume
+	storeCtx, storeCancel := context.WithTimeout(context.Background(), config.DefaultDatabaseTimeout)
+	outputStore, err := services.OpenOutputStore(storeCtx, cfg.Executor.OutputSpillDir, repos.OutputVolumes)
+	storeCancel()
+	if err != nil {
+		log.Error("failed to open the output spill directory", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize queue manager
 	queueManager, err := queue.NewRedisQueueManager(&cfg.Redis, &cfg.Queue, log.Logger)
 	if err != nil {
@@ -123,29 +148,7 @@ func main() {
 	authService := auth.NewService(repos.Users, jwtService, log.Logger, cfg)
 
 	// Initialize executor configuration
-	executorConfig := &executor.Config{
-		DockerEndpoint: cfg.Executor.DockerEndpoint,
-		DefaultResourceLimits: executor.ResourceLimits{
-			MemoryLimitBytes: int64(cfg.Executor.DefaultMemoryLimitMB) * 1024 * 1024,
-			CPUQuota:         cfg.Executor.DefaultCPUQuota,
-			PidsLimit:        cfg.Executor.DefaultPidsLimit,
-			TimeoutSeconds:   cfg.Executor.DefaultTimeoutSeconds,
-		},
-		DefaultTimeoutSeconds: cfg.Executor.DefaultTimeoutSeconds,
-		Images: executor.ImageConfig{
-			Python:     cfg.Executor.PythonImage,
-			Bash:       cfg.Executor.BashImage,
-			JavaScript: cfg.Executor.JavaScriptImage,
-			Go:         cfg.Executor.GoImage,
-		},
-		Security: executor.SecuritySettings{
-			EnableSeccomp:      cfg.Executor.EnableSeccomp,
-			SeccompProfilePath: cfg.Executor.SeccompProfilePath,
-			EnableAppArmor:     cfg.Executor.EnableAppArmor,
-			AppArmorProfile:    cfg.Executor.AppArmorProfile,
-			ExecutionUser:      cfg.Executor.ExecutionUser,
-		},
-	}
+	executorConfig := executor.NewConfig(&cfg.Executor)
 
 	// Create seccomp profile directory if it doesn't exist
 	if cfg.Executor.EnableSeccomp {
@@ -175,35 +178,35 @@ func main() {
 		}
 	}
 
-	// Initialize executor (Docker or Mock based on availability)
+	// Initialize executor (configured backend or mock based on availability)
 	var taskExecutor executor.TaskExecutor
 
-	// Try to initialize Docker executor first
-	dockerExecutor, err := executor.NewExecutor(executorConfig, log.Logger)
+	// Try to initialize the configured executor backend first
+	backendExecutor, err := executor.NewTaskExecutor(executorConfig, log.Logger)
 	if err != nil {
-		log.Warn("failed to initialize Docker executor, falling back to mock executor", "error", err)
+		log.Warn("failed to initialize executor, falling back to mock executor", "error", err)
 		// Use mock executor for environments without Docker (e.g., CI)
 		taskExecutor = executor.NewMockExecutor(executorConfig, log.Logger)
 		log.Info("mock executor initialized successfully")
 	} else {
-		// Check Docker executor health
+		// Check executor health
 		healthCtx, healthCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer healthCancel()
 
-		if err := dockerExecutor.IsHealthy(healthCtx); err != nil {
-			log.Warn("Docker executor health check failed, falling back to mock executor", "error", err)
-			// Cleanup failed Docker executor
-			_ = dockerExecutor.Cleanup(context.Background())
+		if err := backendExecutor.IsHealthy(healthCtx); err != nil {
+			log.Warn("executor health check failed, falling back to mock executor", "error", err)
+			// Cleanup failed executor
+			_ = backendExecutor.Cleanup(context.Background())
 			// Use mock executor instead
 			taskExecutor = executor.NewMockExecutor(executorConfig, log.Logger)
 			log.Info("mock executor initialized successfully")
 		} else {
-			taskExecutor = dockerExecutor
-			log.Info("Docker executor initialized successfully")
-			// Add cleanup for successful Docker executor
+			taskExecutor = backendExecutor
+			log.Info("container executor initialized successfully", "backend", executorConfig.Backend)
+			// Add cleanup for successful executor
 			defer func() {
-				if err := dockerExecutor.Cleanup(context.Background()); err != nil {
-					log.Error("failed to cleanup Docker executor", "error", err)
+				if err := backendExecutor.Cleanup(context.Background()); err != nil {
+					log.Error("failed to cleanup executor", "error", err)
 				}
 			}()
 		}
@@ -227,6 +230,7 @@ func main() {
 			StaleTaskThreshold:   cfg.Worker.StaleTaskThreshold,
 			EnableAutoScaling:    true, // Default enable auto-scaling
 			ScalingCheckInterval: config.DefaultScalingCheckInterval,
+			Capabilities:         cfg.Worker.Capabilities,
 		}
 
 		workerManager = worker.NewWorkerManager(
@@ -271,21 +275,91 @@ func main() {
 	// Initialize task execution service
 	taskExecutionService := services.NewTaskExecutionService(dbConn, queueManager, log.Logger)
 
+	// Initialize bulk job service, failing the jobs stopped servers left unfinished
+	bulkJobService := services.NewBulkJobService(repos.BulkJobs, log.Logger)
+	startCtx, startCancel := context.WithTimeout(context.Background(), config.DefaultDatabaseTimeout)
+	err = bulkJobService.Start(startCtx)
+	startCancel()
+	if err != nil {
+		log.Error("failed to start bulk job service", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize trash purge service, purging the tasks deleted longer ago than the retention
+	trashPurgeService := services.NewTrashPurgeService(repos.Tasks, outputStore, cfg.Trash.Retention, cfg.Trash.PurgeInterval, log.Logger)
+	trashPurgeService.Start()
+
+	// Initialize execution partition service, creating the monthly partitions of executions ahead of time
+	executionPartitionService := services.NewExecutionPartitionService(repos.ExecutionPartitions, config.DefaultExecutionPartitionsAhead, config.DefaultExecutionPartitionCheckInterval, log.Logger)
+	executionPartitionService.Start()
+
 	// Initialize task executor service
 	taskExecutorService := services.NewTaskExecutorService(
 		taskExecutionService,
 		repos.Tasks,
+		repos.TaskRevisions,
 		taskExecutor,
 		nil, // cleanup manager will be initialized within the executor
 		log.Logger,
 	)
 
+	// Initialize runner service if remote runners are allowed to connect
+	var runnerService *services.RunnerService
+	if cfg.HasRunnerAPI() {
+		runnerService = services.NewRunnerService(queueManager.TaskQueue(), repos, cfg.Runner.LeaseTimeout, log.Logger)
+		log.Info("remote runner API enabled", "max_poll_wait", cfg.Runner.MaxPollWait)
+	}
+
+	// Load task admission policies, if configured
+	var admissionEngine *admission.Engine
+	if cfg.Admission.PolicyFile != "" {
+		scriptAnalyzer := analyzer.NewPipeline(models.ScriptAnalysisMode(cfg.Executor.ScriptAnalysisMode))
+		admissionEngine, err = admission.LoadFile(cfg.Admission.PolicyFile, scriptAnalyzer)
+		if err != nil {
+			log.Error("failed to load admission policies", "error", err)
+			os.Exit(1)
+		}
+		log.Info("admission policies loaded", "policy_file", cfg.Admission.PolicyFile)
+	}
+
+	// Import the shared task templates, if configured
+	if cfg.Templates.Dir != "" {
+		importCtx, importCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		imported, err := templates.Import(importCtx, repos.TaskTemplates, cfg.Templates.Dir)
+		importCancel()
+		if err != nil {
+			log.Error("failed to import task templates", "error", err)
+			os.Exit(1)
+		}
+		log.Info("task templates imported", "template_dir", cfg.Templates.Dir, "count", len(imported))
+	}
+
+	// Log database pool metrics periodically until shutdown, waits for a
+	// connection show a starved pool. They are also served at /debug/vars
+	// when metrics are enabled.
+	dbConn.PublishStats()
+	statsCtx, statsCancel := context.WithCancel(context.Background())
+	defer statsCancel()
+	go func() {
+		ticker := time.NewTicker(config.DefaultMetricsInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-statsCtx.Done():
+				return
+			case <-ticker.C:
+				dbConn.LogStats()
+			}
+		}
+	}()
+
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
-	routes.Setup(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager)
+	routes.Setup(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager, runnerService, bulkJobService, trashPurgeService, admissionEngine)
 
 	srv := &http.Server{
 		Addr:              fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
@@ -314,6 +388,7 @@ func main() {
 	<-quit
 
 	log.Info("shutting down server...")
+	statsCancel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), config.DefaultShutdownTimeout)
 	defer cancel()
@@ -323,5 +398,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := bulkJobService.Stop(ctx); err != nil {
+		log.Error("failed to stop bulk job service", "error", err)
+	}
+
+	if err := trashPurgeService.Stop(ctx); err != nil {
+		log.Error("failed to stop trash purge service", "error", err)
+	}
+
+	if err := executionPartitionService.Stop(ctx); err != nil {
+		log.Error("failed to stop execution partition service", "error", err)
+	}
+
 	log.Info("server exited")
 }
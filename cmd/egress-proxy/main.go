@@ -0,0 +1,78 @@
+// Package main VoidRunner Egress Proxy
+//
+// The egress proxy runs as a sidecar next to executions that have network
+// access. It is the only route out of the execution's internal network and:
+// - Accepts HTTP and CONNECT proxy requests from the execution
+// - Checks every destination against the task's network policy
+// - Writes each connection attempt to stdout as a JSON line
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/egress"
+	"github.com/voidrunnerhq/voidrunner/pkg/logger"
+)
+
+func main() {
+	// Diagnostics go to stderr so stdout only carries connection events
+	log := logger.NewWithWriter(os.Getenv("LOG_LEVEL"), "text", os.Stderr)
+
+	var policy egress.Policy
+	if err := json.Unmarshal([]byte(os.Getenv("EGRESS_POLICY")), &policy); err != nil {
+		log.Error("EGRESS_POLICY must be a JSON network policy", "error", err)
+		os.Exit(1)
+	}
+
+	proxy, err := egress.NewProxy(policy, os.Stdout, log.Logger)
+	if err != nil {
+		log.Error("invalid network policy", "error", err)
+		os.Exit(1)
+	}
+
+	addr := os.Getenv("EGRESS_LISTEN_ADDR")
+	if addr == "" {
+		addr = ":" + strconv.Itoa(egress.DefaultPort)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Error("failed to listen", "addr", addr, "error", err)
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Handler:           proxy,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("egress proxy failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// The executor waits for this line before starting the execution
+	fmt.Println(egress.ReadyMessage)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("failed to shut down egress proxy", "error", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package main VoidRunner Runner Agent
+//
+// The runner agent executes tasks on hosts that have no database or queue
+// access. It authenticates to the API with a runner token and:
+// - Long-polls the API for jobs matching its capabilities
+// - Executes them with a local executor
+// - Sends heartbeats and output back while the job runs
+// - Submits the final result
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/runner"
+	"github.com/voidrunnerhq/voidrunner/pkg/logger"
+	"github.com/voidrunnerhq/voidrunner/pkg/utils"
+)
+
+func main() {
+	// Sandboxed scripts re-execute this binary to set up their namespaces
+	if executor.SandboxInit() {
+		return
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.Logger.Level, cfg.Logger.Format)
+	log.Info("starting VoidRunner runner agent")
+
+	if cfg.Runner.Token == "" {
+		log.Error("RUNNER_TOKEN is required")
+		os.Exit(1)
+	}
+
+	runnerID := cfg.Runner.ID
+	if runnerID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Error("RUNNER_ID is not set and the hostname is unavailable", "error", err)
+			os.Exit(1)
+		}
+		runnerID = hostname
+	}
+
+	// Initialize executor configuration
+	executorConfig := executor.NewConfig(&cfg.Executor)
+	// Output is not spilled, as the API servers can't read the runner's disk
+	executorConfig.Output.SpillDir = ""
+
+	// Create seccomp profile if enabled
+	if cfg.Executor.EnableSeccomp {
+		if err := setupSeccompProfile(executorConfig, log); err != nil {
+			log.Warn("failed to setup seccomp profile", "error", err)
+		}
+	}
+
+	// Initialize executor
+	taskExecutor := initializeExecutor(executorConfig, log)
+	defer func() {
+		if err := taskExecutor.Cleanup(context.Background()); err != nil {
+			log.Error("failed to cleanup executor", "error", err)
+		}
+	}()
+
+	// Allow the HTTP client to outlive the longest poll the agent requests
+	client := runner.NewClient(cfg.Runner.APIURL, cfg.Runner.Token, runnerID, cfg.Runner.PollWait+30*time.Second)
+	agent := runner.NewAgent(client, taskExecutor, runner.AgentConfig{
+		Capabilities:      cfg.Runner.Capabilities,
+		PollWait:          cfg.Runner.PollWait,
+		HeartbeatInterval: cfg.Runner.HeartbeatInterval,
+		ResourceLimits:    executorConfig.DefaultResourceLimits,
+	}, log.Logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		log.Info("shutdown signal received, stopping runner agent")
+		cancel()
+	}()
+
+	log.Info("runner agent is running",
+		"runner_id", runnerID,
+		"api_url", cfg.Runner.APIURL,
+		"capabilities", cfg.Runner.Capabilities,
+	)
+
+	if err := agent.Run(ctx); err != nil {
+		log.Error("runner agent failed", "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("runner agent exited")
+}
+
+// setupSeccompProfile creates and configures the seccomp profile
+func setupSeccompProfile(executorConfig *executor.Config, log *logger.Logger) error {
+	seccompDir := filepath.Dir(executorConfig.Security.SeccompProfilePath)
+	if err := os.MkdirAll(seccompDir, 0750); err != nil {
+		return fmt.Errorf("failed to create seccomp profile directory: %w", err)
+	}
+
+	// Create security manager to generate the seccomp profile
+	securityManager := executor.NewSecurityManager(executorConfig)
+	seccompProfilePath, err := securityManager.CreateSeccompProfile(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to create seccomp profile: %w", err)
+	}
+
+	// Copy the profile to the configured location if needed
+	if seccompProfilePath != executorConfig.Security.SeccompProfilePath {
+		if err := utils.CopyFile(seccompProfilePath, executorConfig.Security.SeccompProfilePath); err != nil {
+			return fmt.Errorf("failed to copy seccomp profile: %w", err)
+		}
+		// Clean up temporary profile
+		_ = os.Remove(seccompProfilePath)
+	}
+
+	log.Info("seccomp profile created successfully", "path", executorConfig.Security.SeccompProfilePath)
+	return nil
+}
+
+// initializeExecutor initializes the task executor with fallback to mock
+func initializeExecutor(executorConfig *executor.Config, log *logger.Logger) executor.TaskExecutor {
+	backendExecutor, err := executor.NewTaskExecutor(executorConfig, log.Logger)
+	if err != nil {
+		log.Warn("failed to initialize executor, falling back to mock executor", "error", err)
+		return executor.NewMockExecutor(executorConfig, log.Logger)
+	}
+
+	healthCtx, healthCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer healthCancel()
+
+	if err := backendExecutor.IsHealthy(healthCtx); err != nil {
+		log.Warn("executor health check failed, falling back to mock executor", "error", err)
+		_ = backendExecutor.Cleanup(context.Background())
+		return executor.NewMockExecutor(executorConfig, log.Logger)
+	}
+
+	log.Info("container executor initialized successfully", "backend", executorConfig.Backend)
+	return backendExecutor
+}
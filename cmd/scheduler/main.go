@@ -5,10 +5,17 @@
 // - Processing queued tasks
 // - Handling task retries and failures
 // - Monitoring system health and performance
+// - Creating the monthly partitions of executions ahead of time
+// - Applying the retention policy of executions
+//
+// Run with -retention-dry-run to print what the retention policy would
+// delete and archive, without changing anything, and exit.
 package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -16,16 +23,26 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/voidrunnerhq/voidrunner/internal/archive"
 	"github.com/voidrunnerhq/voidrunner/internal/config"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
 	"github.com/voidrunnerhq/voidrunner/internal/executor"
 	"github.com/voidrunnerhq/voidrunner/internal/queue"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
 	"github.com/voidrunnerhq/voidrunner/internal/worker"
 	"github.com/voidrunnerhq/voidrunner/pkg/logger"
 	"github.com/voidrunnerhq/voidrunner/pkg/utils"
 )
 
 func main() {
+	// Sandboxed scripts re-execute this binary to set up their namespaces
+	if executor.SandboxInit() {
+		return
+	}
+
+	retentionDryRun := flag.Bool("retention-dry-run", false, "print what the execution retention policy would do and exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -59,6 +76,37 @@ func main() {
 	// Initialize repositories
 	repos := database.NewRepositories(dbConn)
 
+	// Open the spilled output store, which must be on the API servers' volume
+	storeCtx, storeCancel := context.WithTimeout(context.Background(), config.DefaultDatabaseTimeout)
+	outputStore, err := services.OpenOutputStore(storeCtx, cfg.Executor.OutputSpillDir, repos.OutputVolumes)
+	storeCancel()
+	if err != nil {
+		log.Error("failed to open the output spill directory", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize execution retention
+	retentionService, err := newExecutionRetentionService(cfg, repos, outputStore, log)
+	if err != nil {
+		log.Error("failed to initialize execution retention", "error", err)
+		os.Exit(1)
+	}
+
+	if *retentionDryRun {
+		report, err := retentionService.Run(context.Background(), true)
+		if err != nil {
+			log.Error("execution retention dry run failed", "error", err)
+			os.Exit(1)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Error("failed to print execution retention report", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize queue manager
 	queueManager, err := queue.NewRedisQueueManager(&cfg.Redis, &cfg.Queue, log.Logger)
 	if err != nil {
@@ -84,29 +132,7 @@ func main() {
 	log.Info("queue manager started successfully")
 
 	// Initialize executor configuration
-	executorConfig := &executor.Config{
-		DockerEndpoint: cfg.Executor.DockerEndpoint,
-		DefaultResourceLimits: executor.ResourceLimits{
-			MemoryLimitBytes: int64(cfg.Executor.DefaultMemoryLimitMB) * 1024 * 1024,
-			CPUQuota:         cfg.Executor.DefaultCPUQuota,
-			PidsLimit:        cfg.Executor.DefaultPidsLimit,
-			TimeoutSeconds:   cfg.Executor.DefaultTimeoutSeconds,
-		},
-		DefaultTimeoutSeconds: cfg.Executor.DefaultTimeoutSeconds,
-		Images: executor.ImageConfig{
-			Python:     cfg.Executor.PythonImage,
-			Bash:       cfg.Executor.BashImage,
-			JavaScript: cfg.Executor.JavaScriptImage,
-			Go:         cfg.Executor.GoImage,
-		},
-		Security: executor.SecuritySettings{
-			EnableSeccomp:      cfg.Executor.EnableSeccomp,
-			SeccompProfilePath: cfg.Executor.SeccompProfilePath,
-			EnableAppArmor:     cfg.Executor.EnableAppArmor,
-			AppArmorProfile:    cfg.Executor.AppArmorProfile,
-			ExecutionUser:      cfg.Executor.ExecutionUser,
-		},
-	}
+	executorConfig := executor.NewConfig(&cfg.Executor)
 
 	// Create seccomp profile if enabled
 	if cfg.Executor.EnableSeccomp {
@@ -140,6 +166,7 @@ func main() {
 		StaleTaskThreshold:   cfg.Worker.StaleTaskThreshold,
 		EnableAutoScaling:    true, // Default enable auto-scaling
 		ScalingCheckInterval: config.DefaultScalingCheckInterval,
+		Capabilities:         cfg.Worker.Capabilities,
 	}
 
 	workerManager := worker.NewWorkerManager(
@@ -170,11 +197,46 @@ func main() {
 
 	log.Info("worker manager started successfully")
 
+	// Start creating the monthly partitions of executions ahead of time
+	executionPartitionService := services.NewExecutionPartitionService(repos.ExecutionPartitions, config.DefaultExecutionPartitionsAhead, config.DefaultExecutionPartitionCheckInterval, log.Logger)
+	executionPartitionService.Start()
+	defer func() {
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), config.DefaultShutdownTimeout)
+		defer stopCancel()
+
+		if err := executionPartitionService.Stop(stopCtx); err != nil {
+			log.Error("failed to stop execution partition service", "error", err)
+		}
+	}()
+
+	// Start execution retention
+	if cfg.HasExecutionRetention() {
+		retentionService.Start(cfg.Retention.Interval, cfg.Retention.DryRun)
+		defer func() {
+			log.Info("stopping execution retention")
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), config.DefaultShutdownTimeout)
+			defer stopCancel()
+
+			if err := retentionService.Stop(stopCtx); err != nil {
+				log.Error("failed to stop execution retention", "error", err)
+			}
+		}()
+
+		log.Info("execution retention started",
+			"keep_last", cfg.Retention.KeepLast,
+			"max_age", cfg.Retention.MaxAge,
+			"output_max_age", cfg.Retention.OutputMaxAge,
+			"interval", cfg.Retention.Interval,
+			"dry_run", cfg.Retention.DryRun,
+			"archive_backend", cfg.Archive.Backend,
+		)
+	}
+
 	// Start monitoring and health check routine
 	go startHealthMonitoring(workerManager, queueManager, log)
 
 	// Start metrics collection (if enabled)
-	go startMetricsCollection(workerManager, queueManager, cfg, log)
+	go startMetricsCollection(dbConn, workerManager, queueManager, taskExecutor, cfg, log)
 
 	log.Info("scheduler service is running",
 		"worker_pool_size", workerManager.GetWorkerPool().GetWorkerCount(),
@@ -207,6 +269,22 @@ func main() {
 	log.Info("scheduler service exited")
 }
 
+// newExecutionRetentionService creates the execution retention service from
+// the retention and archive configuration
+func newExecutionRetentionService(cfg *config.Config, repos *database.Repositories, outputStore *executor.OutputStore, log *logger.Logger) (*services.ExecutionRetentionService, error) {
+	archiveStore, err := archive.NewStore(&cfg.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := services.ExecutionRetentionPolicy{
+		KeepLast:     cfg.Retention.KeepLast,
+		MaxAge:       cfg.Retention.MaxAge,
+		OutputMaxAge: cfg.Retention.OutputMaxAge,
+	}
+	return services.NewExecutionRetentionService(repos.TaskExecutions, repos.ExecutionPartitions, archiveStore, outputStore, policy, log.Logger), nil
+}
+
 // setupSeccompProfile creates and configures the seccomp profile
 func setupSeccompProfile(executorConfig *executor.Config, log *logger.Logger) error {
 	seccompDir := filepath.Dir(executorConfig.Security.SeccompProfilePath)
@@ -236,32 +314,32 @@ func setupSeccompProfile(executorConfig *executor.Config, log *logger.Logger) er
 
 // initializeExecutor initializes the task executor with fallback to mock
 func initializeExecutor(executorConfig *executor.Config, log *logger.Logger) (executor.TaskExecutor, error) {
-	// Try to initialize Docker executor first
-	dockerExecutor, err := executor.NewExecutor(executorConfig, log.Logger)
+	// Try to initialize the configured executor backend first
+	backendExecutor, err := executor.NewTaskExecutor(executorConfig, log.Logger)
 	if err != nil {
-		log.Warn("failed to initialize Docker executor, falling back to mock executor", "error", err)
+		log.Warn("failed to initialize executor, falling back to mock executor", "error", err)
 		// Use mock executor for environments without Docker
 		mockExecutor := executor.NewMockExecutor(executorConfig, log.Logger)
 		log.Info("mock executor initialized successfully")
 		return mockExecutor, nil
 	}
 
-	// Check Docker executor health
+	// Check executor health
 	healthCtx, healthCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer healthCancel()
 
-	if err := dockerExecutor.IsHealthy(healthCtx); err != nil {
-		log.Warn("Docker executor health check failed, falling back to mock executor", "error", err)
-		// Cleanup failed Docker executor
-		_ = dockerExecutor.Cleanup(context.Background())
+	if err := backendExecutor.IsHealthy(healthCtx); err != nil {
+		log.Warn("executor health check failed, falling back to mock executor", "error", err)
+		// Cleanup failed executor
+		_ = backendExecutor.Cleanup(context.Background())
 		// Use mock executor instead
 		mockExecutor := executor.NewMockExecutor(executorConfig, log.Logger)
 		log.Info("mock executor initialized successfully")
 		return mockExecutor, nil
 	}
 
-	log.Info("Docker executor initialized successfully")
-	return dockerExecutor, nil
+	log.Info("container executor initialized successfully", "backend", executorConfig.Backend)
+	return backendExecutor, nil
 }
 
 // startHealthMonitoring starts the health monitoring routine
@@ -326,7 +404,7 @@ func performHealthCheck(workerManager worker.WorkerManager, queueManager queue.Q
 }
 
 // startMetricsCollection starts metrics collection if enabled
-func startMetricsCollection(workerManager worker.WorkerManager, queueManager queue.QueueManager, cfg *config.Config, log *logger.Logger) {
+func startMetricsCollection(dbConn *database.Connection, workerManager worker.WorkerManager, queueManager queue.QueueManager, taskExecutor executor.TaskExecutor, cfg *config.Config, log *logger.Logger) {
 	// This is a placeholder for metrics collection
 	// In a production system, you would integrate with Prometheus, StatsD, or other metrics systems
 
@@ -334,15 +412,18 @@ func startMetricsCollection(workerManager worker.WorkerManager, queueManager que
 	defer ticker.Stop()
 
 	for range ticker.C {
-		collectMetrics(workerManager, queueManager, log)
+		collectMetrics(dbConn, workerManager, queueManager, taskExecutor, log)
 	}
 }
 
 // collectMetrics collects and reports system metrics
-func collectMetrics(workerManager worker.WorkerManager, queueManager queue.QueueManager, log *logger.Logger) {
+func collectMetrics(dbConn *database.Connection, workerManager worker.WorkerManager, queueManager queue.QueueManager, taskExecutor executor.TaskExecutor, log *logger.Logger) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Log database pool metrics, waits for a connection show a starved pool
+	dbConn.LogStats()
+
 	// Collect worker metrics
 	workerStats := workerManager.GetStats()
 	concurrencyStats := workerStats.ConcurrencyStats
@@ -380,4 +461,22 @@ func collectMetrics(workerManager worker.WorkerManager, queueManager queue.Queue
 		"dead_letter_messages", queueStats.DeadLetterQueue.ApproximateMessages,
 		"total_throughput", queueStats.TotalThroughput,
 	)
+	// Log warm pool metrics for the container executor
+	if containerExecutor, ok := taskExecutor.(*executor.Executor); ok {
+		for _, pool := range containerExecutor.WarmPoolStats() {
+			log.Info("warm pool metrics",
+				"script_type", pool.ScriptType,
+				"image", pool.Image,
+				"size", pool.Size,
+				"idle", pool.Idle,
+				"hits", pool.Hits,
+				"misses", pool.Misses,
+				"hit_rate", pool.HitRate,
+				"warm_startup_p50_ms", pool.WarmStartupP50.Milliseconds(),
+				"warm_startup_p99_ms", pool.WarmStartupP99.Milliseconds(),
+				"cold_startup_p50_ms", pool.ColdStartupP50.Milliseconds(),
+				"cold_startup_p99_ms", pool.ColdStartupP99.Milliseconds(),
+			)
+		}
+	}
 }
@@ -0,0 +1,191 @@
+// Package main VoidRunner CLI
+//
+// The CLI manages tasks declaratively through the API:
+// - apply reconciles the user's tasks with manifest files
+// - export writes the user's tasks as a manifest
+//
+// It authenticates with the user's access token, read from VOIDRUNNER_TOKEN,
+// against the API at VOIDRUNNER_URL.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/manifest"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const defaultServerURL = "http://localhost:8080"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "apply":
+		err = apply(os.Args[2:])
+	case "export":
+		err = export(os.Args[2:])
+	case "help", "-h", "--help":
+		usage(os.Stdout)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: voidrunner <command> [flags]")
+	_, _ = fmt.Fprintln(w, "Commands:")
+	_, _ = fmt.Fprintln(w, "  apply   - Reconcile tasks with a manifest file or directory")
+	_, _ = fmt.Fprintln(w, "  export  - Write the tasks as a manifest")
+	_, _ = fmt.Fprintln(w, "Run 'voidrunner <command> -h' for the flags of a command.")
+}
+
+// clientFlags registers the flags shared by all commands
+func clientFlags(flags *flag.FlagSet) (server, token *string, timeout *time.Duration) {
+	serverURL := os.Getenv("VOIDRUNNER_URL")
+	if serverURL == "" {
+		serverURL = defaultServerURL
+	}
+
+	server = flags.String("server", serverURL, "API base URL (VOIDRUNNER_URL)")
+	token = flags.String("token", os.Getenv("VOIDRUNNER_TOKEN"), "access token (VOIDRUNNER_TOKEN)")
+	timeout = flags.Duration("timeout", time.Minute, "request timeout")
+	return server, token, timeout
+}
+
+func apply(args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	path := flags.String("f", "", "manifest file or directory (required)")
+	dryRun := flags.Bool("dry-run", false, "only report the changes")
+	diff := flags.Bool("diff", false, "only report the changes, with the changed fields")
+	prune := flags.Bool("prune", false, "delete tasks whose key is no longer in the manifest")
+	server, token, timeout := clientFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return errors.New("a manifest is required (-f)")
+	}
+	if *token == "" {
+		return errors.New("an access token is required (-token or VOIDRUNNER_TOKEN)")
+	}
+
+	mode := models.TaskImportModeApply
+	switch {
+	case *diff:
+		mode = models.TaskImportModeDiff
+	case *dryRun:
+		mode = models.TaskImportModeDryRun
+	}
+
+	taskManifest, err := manifest.Load(*path)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	response, err := manifest.NewClient(*server, *token, *timeout).Import(ctx, taskManifest, mode, *prune)
+	if response != nil {
+		printChanges(os.Stdout, response)
+	}
+	return err
+}
+
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "output file (default stdout)")
+	format := flags.String("format", "yaml", "manifest format, yaml or json")
+	server, token, timeout := clientFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *token == "" {
+		return errors.New("an access token is required (-token or VOIDRUNNER_TOKEN)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	data, err := manifest.NewClient(*server, *token, *timeout).Export(ctx, *format)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0600)
+}
+
+// printChanges writes the changes of an import, skipping unchanged tasks,
+// followed by a summary
+func printChanges(w io.Writer, response *models.TaskImportResponse) {
+	symbols := map[models.TaskManifestAction]string{
+		models.TaskManifestActionCreate: "+",
+		models.TaskManifestActionUpdate: "~",
+		models.TaskManifestActionDelete: "-",
+	}
+
+	for _, change := range response.Changes {
+		if change.Action == models.TaskManifestActionUnchanged && change.Error == "" {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s %s %s (%s)\n", symbols[change.Action], change.Action, change.Key, change.Name)
+		if change.Error != "" {
+			_, _ = fmt.Fprintf(w, "    error: %s\n", change.Error)
+		}
+		for _, field := range change.Fields {
+			if field.Diff != "" {
+				_, _ = fmt.Fprintf(w, "    %s:\n", field.Field)
+				for _, line := range strings.Split(strings.TrimRight(field.Diff, "\n"), "\n") {
+					_, _ = fmt.Fprintf(w, "      %s\n", line)
+				}
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "    %s: %s -> %s\n", field.Field, formatValue(field.From), formatValue(field.To))
+		}
+	}
+
+	summary := response.Summary
+	if response.Applied {
+		_, _ = fmt.Fprintf(w, "%d created, %d updated, %d deleted, %d unchanged\n",
+			summary.Create, summary.Update, summary.Delete, summary.Unchanged)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%d to create, %d to update, %d to delete, %d unchanged\n",
+		summary.Create, summary.Update, summary.Delete, summary.Unchanged)
+}
+
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%v", value)
+}
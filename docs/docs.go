@@ -24,6 +24,127 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/trash": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently deletes the tasks of all users in the trash, or those deleted before the given time, without waiting for the retention to expire. Requires an admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Purge the trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only purge tasks deleted before this RFC 3339 time",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Trash purged successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrashPurgeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid before parameter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/trash/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently deletes a task in the trash, of any user, with its executions and revisions. Requires an admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Purge a task from the trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task purged successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrashPurgeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api": {
             "get": {
                 "description": "Returns an HTML page with links to various API documentation formats",
@@ -321,130 +442,159 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/executions": {
             "get": {
-                "description": "Returns the health status of the API service",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Retrieves the executions of all tasks owned by the authenticated user, newest first, using cursor-based pagination",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Executions"
                 ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "Service is healthy",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.HealthResponse"
-                        }
+                "summary": "List executions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only executions of this task",
+                        "name": "task_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated execution statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Exit code of the script",
+                        "name": "exit_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum execution time in milliseconds",
+                        "name": "duration_min_ms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum execution time in milliseconds",
+                        "name": "duration_max_ms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only executions created at or after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only executions created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector on the executed tasks, e.g. env=prod,team in (data,ml)",
+                        "name": "selector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order, asc or desc",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of executions to return",
+                        "name": "limit",
+                        "in": "query"
                     }
-                }
-            }
-        },
-        "/health/workers": {
-            "get": {
-                "description": "Returns detailed status information about the embedded worker system",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Health"
                 ],
-                "summary": "Worker status",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Executions retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/handlers.WorkerStatusResponse"
+                            "$ref": "#/definitions/models.ExecutionSearchResponse"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "400": {
+                        "description": "Invalid query parameters",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/ready": {
-            "get": {
-                "description": "Returns the readiness status of the API service and its dependencies",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Health"
-                ],
-                "summary": "Readiness check",
-                "responses": {
-                    "200": {
-                        "description": "Service is ready",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "503": {
-                        "description": "Service is not ready",
+                    "429": {
+                        "description": "Rate limit exceeded",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/tasks": {
+        "/executions/{id}/network-events": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a paginated list of tasks owned by the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the connection attempts an execution made through its egress proxy, in the order they were made",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Executions"
                 ],
-                "summary": "List user's tasks",
+                "summary": "List execution network events",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Maximum number of tasks to return",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "default": 0,
-                        "description": "Number of tasks to skip",
-                        "name": "offset",
+                        "default": 100,
+                        "description": "Maximum number of events (1-1000)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tasks retrieved successfully",
+                        "description": "Network events retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskListResponse"
+                            "$ref": "#/definitions/models.NetworkEventListResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid query parameters",
+                        "description": "Invalid execution ID or limit",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -455,51 +605,79 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "429": {
-                        "description": "Rate limit exceeded",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Execution not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/executions/{id}/output": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new task with the specified script content and configuration",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Reads a range of bytes of an output stream of an execution, also while it runs. The range ends before a character cut at its end; the next range starts at next_offset.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Executions"
                 ],
-                "summary": "Create a new task",
+                "summary": "Read execution output",
                 "parameters": [
                     {
-                        "description": "Task creation details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateTaskRequest"
-                        }
+                        "type": "string",
+                        "description": "Execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "stdout",
+                            "stderr"
+                        ],
+                        "type": "string",
+                        "default": "stdout",
+                        "description": "Output stream",
+                        "name": "stream",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Byte offset to read from",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 65536,
+                        "description": "Maximum number of bytes (1-1048576)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Task created successfully",
+                    "200": {
+                        "description": "Output range retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskResponse"
+                            "$ref": "#/definitions/models.ExecutionOutputResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request format or validation error",
+                        "description": "Invalid execution ID, stream, offset or limit",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -510,8 +688,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "429": {
-                        "description": "Rate limit exceeded",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Execution not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Execution output expired",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -519,42 +709,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}": {
+        "/executions/{id}/output/{stream}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves detailed information about a specific task",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Downloads the full output stream of an execution whose output was truncated, when output spilling is enabled. Supports range requests.",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Executions"
                 ],
-                "summary": "Get task details",
+                "summary": "Download execution output",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task ID",
+                        "description": "Execution ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "stdout",
+                            "stderr"
+                        ],
+                        "type": "string",
+                        "description": "Output stream",
+                        "name": "stream",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task retrieved successfully",
+                        "description": "Full output stream",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Invalid task ID",
+                        "description": "Invalid execution ID or stream",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -572,7 +770,90 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Task not found",
+                        "description": "Execution or spilled output not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns the health status of the API service",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "Service is healthy",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/workers": {
+            "get": {
+                "description": "Returns detailed status information about the embedded worker system",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Worker status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WorkerStatusResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/images": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the custom images used by the user's tasks, grouped by the digest they are pinned to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List task images",
+                "responses": {
+                    "200": {
+                        "description": "Image inventory retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImageInventoryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -586,42 +867,98 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{task_id}/executions": {
-            "post": {
+        "/jobs": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Starts execution of the specified task",
-                "consumes": [
+                "description": "Lists the user's bulk jobs, newest first, without the outcomes of their operations",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Bulk Jobs"
+                ],
+                "summary": "List bulk jobs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of jobs to return (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of jobs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jobs retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkJobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the status and progress of a bulk job, with the outcome of each operation performed so far",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Executions"
+                    "Bulk Jobs"
                 ],
-                "summary": "Start task execution",
+                "summary": "Get bulk job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Task ID",
-                        "name": "task_id",
+                        "description": "Job ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Execution started successfully",
+                    "200": {
+                        "description": "Job retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.TaskExecutionResponse"
+                            "$ref": "#/definitions/models.BulkJobResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid task ID",
+                        "description": "Invalid job ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -639,13 +976,44 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Task not found",
+                        "description": "Job not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Task is already running",
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/labels": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the labels on the user's tasks, with the number of tasks carrying each key and value",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List task labels",
+                "responses": {
+                    "200": {
+                        "description": "Label inventory retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.LabelInventoryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -658,326 +1026,3570 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.ConcurrencyStatus": {
-            "type": "object",
-            "properties": {
-                "available_slots": {
-                    "type": "integer"
-                },
-                "slots_acquired_total": {
-                    "type": "integer"
-                },
-                "slots_released_total": {
-                    "type": "integer"
-                },
-                "total_active_slots": {
+        },
+        "/ready": {
+            "get": {
+                "description": "Returns the readiness status of the API service and its dependencies, with details such as the database connection pool statistics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "Service is ready",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is not ready",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runner/jobs": {
+            "post": {
+                "security": [
+                    {
+                        "RunnerAuth": []
+                    }
+                ],
+                "description": "Long-polls for the next queued task matching the runner's capabilities and leases it to the runner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Runners"
+                ],
+                "summary": "Claim a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Runner identifier",
+                        "name": "X-Runner-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Claim parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job leased to the runner",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerJob"
+                        }
+                    },
+                    "204": {
+                        "description": "No job became available before the wait expired"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid runner token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runner/jobs/{id}/heartbeat": {
+            "post": {
+                "security": [
+                    {
+                        "RunnerAuth": []
+                    }
+                ],
+                "description": "Extends the lease on a running job and reports whether the job was cancelled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Runners"
+                ],
+                "summary": "Renew a job lease",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Runner identifier",
+                        "name": "X-Runner-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lease token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerHeartbeatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lease renewed",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerHeartbeatResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid runner token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Lease does not belong to this execution",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Execution not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Lease is no longer held",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runner/jobs/{id}/logs": {
+            "post": {
+                "security": [
+                    {
+                        "RunnerAuth": []
+                    }
+                ],
+                "description": "Appends a chunk of stdout and stderr to a running job and renews its lease",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Runners"
+                ],
+                "summary": "Upload job output",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Runner identifier",
+                        "name": "X-Runner-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Output chunk",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerLogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Output appended"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid runner token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Lease does not belong to this execution",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Execution not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Lease is no longer held",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runner/jobs/{id}/result": {
+            "post": {
+                "security": [
+                    {
+                        "RunnerAuth": []
+                    }
+                ],
+                "description": "Records the outcome of a leased job, finalizes the task status and releases the job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Runners"
+                ],
+                "summary": "Submit a job result",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Runner identifier",
+                        "name": "X-Runner-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Execution result",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RunnerResultRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result recorded",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskExecutionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid runner token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Lease does not belong to this execution",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Execution not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Lease is no longer held",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a paginated list of tasks owned by the authenticated user. Listings that are filtered, sorted or given a cursor use cursor-based pagination and return a models.TaskSearchResponse.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List user's tasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of tasks to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of tasks to skip, without filters, sorting or cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Sort field: created_at, updated_at, priority or name",
+                        "name": "sort_field",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order, asc or desc",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated script types",
+                        "name": "script_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum priority",
+                        "name": "priority_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum priority",
+                        "name": "priority_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created at or after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks updated at or after this RFC 3339 time",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks updated before this RFC 3339 time",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object the task metadata must contain",
+                        "name": "metadata",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search on the task name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector, e.g. env=prod,team in (data,ml),!deprecated",
+                        "name": "selector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tasks retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new task with the specified script content and configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Create a new task",
+                "parameters": [
+                    {
+                        "description": "Task creation details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Task created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Denied by admission policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Image could not be resolved",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exports every task of the user as a task manifest, in YAML or JSON. Tasks without an external key are exported under their ID.",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Export tasks as a manifest",
+                "parameters": [
+                    {
+                        "enum": [
+                            "yaml",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "yaml",
+                        "description": "Manifest format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Manifest of the user's tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskManifest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reconciles the user's tasks with a task manifest in YAML or JSON, matching tasks by their external key. In dry-run mode the action for each task is reported, in diff mode also the changed fields, and in apply mode the changes are made. With prune, tasks whose external key is not in the manifest are deleted. The manifest is rejected, and nothing is changed, when any task fails validation, script analysis or admission.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Import a task manifest",
+                "parameters": [
+                    {
+                        "enum": [
+                            "dry-run",
+                            "diff",
+                            "apply"
+                        ],
+                        "type": "string",
+                        "default": "dry-run",
+                        "description": "Import mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete managed tasks missing from the manifest",
+                        "name": "prune",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Task manifest",
+                        "name": "manifest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskManifest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Changes planned or applied",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid manifest, or tasks rejected",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskImportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Manifest too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the user's deleted tasks, most recently deleted first, until they are restored or purged. Takes the filters and cursor pagination of the task listing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "List tasks in the trash",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of tasks to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "updated_at",
+                        "description": "Sort field: created_at, updated_at, priority or name",
+                        "name": "sort_field",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order, asc or desc",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated script types",
+                        "name": "script_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search on the task name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector, e.g. env=prod,team in (data,ml),!deprecated",
+                        "name": "selector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tasks retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves detailed information about a specific task. The ETag header carries the task's version, to be sent as If-Match when updating or deleting it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get task details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves a deleted task out of the trash, with its executions and revisions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Restore a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task restored successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Another task has the same external key",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a paginated list of the task's script revisions, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List task revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of revisions to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of revisions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revisions retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskRevisionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a unified diff of the script between two task revisions. The diff is taken against the previous revision when from is omitted, and of the current revision when to is omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Diff task revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision to diff from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision to diff to",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Diff computed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskRevisionDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or revision",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/{revision}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the script of a specific task revision",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get task revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revision retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskRevisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or revision",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/{revision}/executions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Starts execution of the script of the specified task revision, which may be older than the task's current revision",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Executions"
+                ],
+                "summary": "Run task revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Execution started successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskExecutionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or revision",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied or denied by admission policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task is already running",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/revisions/{revision}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores the script of an earlier revision. The restored script is saved as a new revision, so the history is kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Roll back task to a revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number to restore",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task rolled back successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or revision, or the script failed analysis",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied or denied by admission policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task is running, or was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match doesn't match the task's version",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/executions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Starts execution of the specified task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Executions"
+                ],
+                "summary": "Start task execution",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Execution started successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskExecutionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied or denied by admission policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task is already running",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks:batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates, updates, deletes or executes up to 1000 tasks in a background job. Each operation is checked and performed as through its own endpoint, and its outcome recorded in the job; failed operations don't stop the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bulk Jobs"
+                ],
+                "summary": "Batch task operations",
+                "parameters": [
+                    {
+                        "description": "Task operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid operations",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks:bulk": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes, executes, or cancels the pending or running execution of, every task matching the filters, in a background job. The filters are those of the task listing; at least one is required, and they may match at most 1000 tasks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bulk Jobs"
+                ],
+                "summary": "Bulk task action",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated script types",
+                        "name": "script_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum priority",
+                        "name": "priority_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum priority",
+                        "name": "priority_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created at or after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks updated at or after this RFC 3339 time",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only tasks updated before this RFC 3339 time",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object the task metadata must contain",
+                        "name": "metadata",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search on the task name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector, e.g. env=prod,team in (data,ml),!deprecated",
+                        "name": "selector",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Action to perform",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTaskActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid action or filters, or too many matching tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a paginated list of the templates visible to the authenticated user: their own templates, the templates of their teams and the global templates, ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "List task templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of templates to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of templates to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Templates retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskTemplateListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a private or team task template. Global templates can only be imported from the template directory.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Create a task template",
+                "parameters": [
+                    {
+                        "description": "Template details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaskTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Template created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Visibility not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Template name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a task template visible to the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get task template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Template retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid template ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates a template owned by the authenticated user. Imported templates can't be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update task template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template updates",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTaskTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Template updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied or visibility not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Template name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a template owned by the authenticated user. Tasks created from the template are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Delete task template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Template deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid template ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}/tasks": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renders the template's script with the given parameters and creates a task owned by the authenticated user. The task takes the template's defaults unless overridden, and is analyzed and admitted like any other task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Create a task from a template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task details and parameter values",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaskFromTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Task created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, parameters or rendered script",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied or denied by admission policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.ConcurrencyStatus": {
+            "type": "object",
+            "properties": {
+                "available_slots": {
+                    "type": "integer"
+                },
+                "slots_acquired_total": {
+                    "type": "integer"
+                },
+                "slots_released_total": {
+                    "type": "integer"
+                },
+                "total_active_slots": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "service": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "uptime": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WorkerManagerStatus": {
+            "type": "object",
+            "properties": {
+                "is_healthy": {
+                    "type": "boolean"
+                },
+                "is_running": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.WorkerPoolStatus": {
+            "type": "object",
+            "properties": {
+                "active_workers": {
+                    "type": "integer"
+                },
+                "average_task_time": {
+                    "type": "string",
+                    "example": "1m30s"
+                },
+                "idle_workers": {
+                    "type": "integer"
+                },
+                "pool_size": {
+                    "type": "integer"
+                },
+                "total_tasks_failed": {
+                    "type": "integer"
+                },
+                "total_tasks_processed": {
+                    "type": "integer"
+                },
+                "total_tasks_successful": {
+                    "type": "integer"
+                },
+                "unhealthy_workers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.WorkerStatusResponse": {
+            "type": "object",
+            "properties": {
+                "concurrency": {
+                    "$ref": "#/definitions/handlers.ConcurrencyStatus"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "worker_manager": {
+                    "$ref": "#/definitions/handlers.WorkerManagerStatus"
+                },
+                "worker_pool": {
+                    "$ref": "#/definitions/handlers.WorkerPoolStatus"
+                }
+            }
+        },
+        "models.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserResponse"
+                }
+            }
+        },
+        "models.BatchTaskRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "operations": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.BulkOperation"
+                    }
+                }
+            }
+        },
+        "models.BulkAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "execute",
+                "cancel"
+            ],
+            "x-enum-varnames": [
+                "BulkActionCreate",
+                "BulkActionUpdate",
+                "BulkActionDelete",
+                "BulkActionExecute",
+                "BulkActionCancel"
+            ]
+        },
+        "models.BulkJobKind": {
+            "type": "string",
+            "enum": [
+                "batch",
+                "bulk"
+            ],
+            "x-enum-varnames": [
+                "BulkJobKindBatch",
+                "BulkJobKindBulk"
+            ]
+        },
+        "models.BulkJobListResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkJobResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkJobResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/models.BulkAction"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "filter": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/models.BulkJobKind"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "description": "Results are the outcomes of the operations processed so far, in the\norder of the operations; they are left out of job listings",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkOperationResult"
+                    }
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.BulkJobStatus"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkJobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "BulkJobStatusPending",
+                "BulkJobStatusRunning",
+                "BulkJobStatusCompleted",
+                "BulkJobStatusFailed"
+            ]
+        },
+        "models.BulkOperation": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "execute"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkAction"
+                        }
+                    ]
+                },
+                "changes": {
+                    "description": "Changes are the fields to update, for the update action",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                        }
+                    ]
+                },
+                "task": {
+                    "description": "Task is the task to create, for the create action",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CreateTaskRequest"
+                        }
+                    ]
+                },
+                "task_id": {
+                    "description": "TaskID is the task the operation applies to; all actions but create\nrequire it",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkOperationResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/models.BulkAction"
+                },
+                "error": {
+                    "description": "Error tells why the operation failed",
+                    "type": "string"
+                },
+                "execution_id": {
+                    "description": "ExecutionID is the execution started or cancelled",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Index is the position of the operation in the job",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.BulkOperationStatus"
+                },
+                "task_id": {
+                    "description": "TaskID is the task operated on, or the created task",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkOperationStatus": {
+            "type": "string",
+            "enum": [
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "BulkOperationStatusSucceeded",
+                "BulkOperationStatusFailed"
+            ]
+        },
+        "models.BulkTaskActionRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "enum": [
+                        "delete",
+                        "execute",
+                        "cancel"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkAction"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.CreateTaskFromTemplateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.JSONB"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "parameters": {
+                    "description": "Parameters are the values substituted into the template's script",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "priority": {
+                    "description": "Overrides of the template's defaults",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.CreateTaskRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "script_content",
+                "script_type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "image": {
+                    "type": "string",
+                    "maxLength": 512
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.JSONB"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "network_allowlist": {
+                    "type": "array",
+                    "maxItems": 32,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "network_mode": {
+                    "$ref": "#/definitions/models.TaskNetworkMode"
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "required_capabilities": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script_content": {
+                    "type": "string",
+                    "maxLength": 65535,
+                    "minLength": 1
+                },
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.CreateTaskTemplateRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "script_template",
+                "script_type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "parameters": {
+                    "type": "array",
+                    "maxItems": 32,
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateParameter"
+                    }
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "required_capabilities": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script_template": {
+                    "type": "string",
+                    "maxLength": 65535,
+                    "minLength": 1
+                },
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "team": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
+                },
+                "visibility": {
+                    "enum": [
+                        "private",
+                        "team",
+                        "global"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TemplateVisibility"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.CursorPagination": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScriptFinding"
+                    }
+                },
+                "policy": {
+                    "type": "string"
+                },
+                "validation_errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ValidationError"
+                    }
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PolicyViolation"
+                    }
+                }
+            }
+        },
+        "models.ExecutionErrorCategory": {
+            "type": "string",
+            "enum": [
+                "script",
+                "out_of_memory",
+                "timeout",
+                "cancelled",
+                "security",
+                "config",
+                "image",
+                "runtime",
+                "resource",
+                "network",
+                "permission",
+                "internal"
+            ],
+            "x-enum-varnames": [
+                "ErrorCategoryScript",
+                "ErrorCategoryOutOfMemory",
+                "ErrorCategoryTimeout",
+                "ErrorCategoryCancelled",
+                "ErrorCategorySecurity",
+                "ErrorCategoryConfig",
+                "ErrorCategoryImage",
+                "ErrorCategoryRuntime",
+                "ErrorCategoryResource",
+                "ErrorCategoryNetwork",
+                "ErrorCategoryPermission",
+                "ErrorCategoryInternal"
+            ]
+        },
+        "models.ExecutionOutputResponse": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "type": "boolean"
+                },
+                "data": {
+                    "type": "string"
+                },
+                "next_offset": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "stream": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExecutionSearchResponse": {
+            "type": "object",
+            "properties": {
+                "executions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskExecutionResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/models.CursorPagination"
+                },
+                "sort_order": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExecutionStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed",
+                "timeout",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "ExecutionStatusPending",
+                "ExecutionStatusRunning",
+                "ExecutionStatusCompleted",
+                "ExecutionStatusFailed",
+                "ExecutionStatusTimeout",
+                "ExecutionStatusCancelled"
+            ]
+        },
+        "models.FindingSeverity": {
+            "type": "string",
+            "enum": [
+                "low",
+                "medium",
+                "high",
+                "critical"
+            ],
+            "x-enum-varnames": [
+                "FindingSeverityLow",
+                "FindingSeverityMedium",
+                "FindingSeverityHigh",
+                "FindingSeverityCritical"
+            ]
+        },
+        "models.ImageInventoryResponse": {
+            "type": "object",
+            "properties": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageUsage"
+                    }
+                }
+            }
+        },
+        "models.ImageUsage": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "task_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.JSONB": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.LabelInventoryResponse": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LabelUsage"
+                    }
+                }
+            }
+        },
+        "models.LabelUsage": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "task_count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NetworkEvent": {
+            "type": "object",
+            "required": [
+                "host",
+                "occurred_at"
+            ],
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "execution_id": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string",
+                    "maxLength": 253
+                },
+                "id": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer",
+                    "maximum": 65535,
+                    "minimum": 0
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "models.NetworkEventListResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NetworkEvent"
+                    }
+                },
+                "total": {
                     "type": "integer"
                 }
             }
         },
-        "handlers.HealthResponse": {
+        "models.PolicyViolation": {
             "type": "object",
             "properties": {
-                "service": {
+                "message": {
                     "type": "string"
                 },
-                "status": {
+                "rule": {
+                    "description": "Rule is the policy rule the task violates, e.g. \"script_types\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
                     "type": "string"
                 },
-                "timestamp": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "models.RunnerHeartbeatRequest": {
+            "type": "object",
+            "required": [
+                "lease_token"
+            ],
+            "properties": {
+                "lease_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RunnerHeartbeatResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RunnerJob": {
+            "type": "object",
+            "properties": {
+                "execution_id": {
                     "type": "string"
                 },
-                "uptime": {
+                "image": {
                     "type": "string"
                 },
-                "version": {
+                "image_digest": {
+                    "type": "string"
+                },
+                "lease_token": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "network_allowlist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "network_mode": {
+                    "$ref": "#/definitions/models.TaskNetworkMode"
+                },
+                "required_capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script_content": {
+                    "type": "string"
+                },
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "task_id": {
                     "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.ReadinessResponse": {
+        "models.RunnerJobRequest": {
             "type": "object",
             "properties": {
-                "checks": {
-                    "type": "object",
-                    "additionalProperties": {
+                "capabilities": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
                         "type": "string"
                     }
                 },
-                "status": {
+                "wait_seconds": {
+                    "type": "integer",
+                    "maximum": 60,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.RunnerLogRequest": {
+            "type": "object",
+            "required": [
+                "lease_token"
+            ],
+            "properties": {
+                "lease_token": {
                     "type": "string"
                 },
-                "timestamp": {
+                "stderr": {
+                    "type": "string"
+                },
+                "stdout": {
                     "type": "string"
                 }
             }
         },
-        "handlers.WorkerManagerStatus": {
+        "models.RunnerResultRequest": {
             "type": "object",
+            "required": [
+                "lease_token",
+                "status"
+            ],
             "properties": {
-                "is_healthy": {
+                "error_category": {
+                    "maxLength": 32,
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ExecutionErrorCategory"
+                        }
+                    ]
+                },
+                "execution_time_ms": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "exit_signal": {
+                    "type": "string",
+                    "maxLength": 16
+                },
+                "lease_token": {
+                    "type": "string"
+                },
+                "memory_usage_bytes": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "network_events": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "$ref": "#/definitions/models.NetworkEvent"
+                    }
+                },
+                "oom_killed": {
                     "type": "boolean"
                 },
-                "is_running": {
+                "return_code": {
+                    "type": "integer"
+                },
+                "runtime": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "status": {
+                    "$ref": "#/definitions/models.ExecutionStatus"
+                },
+                "stderr": {
+                    "type": "string"
+                },
+                "stdout": {
+                    "type": "string"
+                },
+                "timeout_phase": {
+                    "enum": [
+                        "image_pull",
+                        "start",
+                        "run"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TimeoutPhase"
+                        }
+                    ]
+                },
+                "truncated": {
                     "type": "boolean"
                 }
             }
         },
-        "handlers.WorkerPoolStatus": {
+        "models.ScriptFinding": {
             "type": "object",
             "properties": {
-                "active_workers": {
+                "column": {
                     "type": "integer"
                 },
-                "average_task_time": {
-                    "type": "string",
-                    "example": "1m30s"
+                "line": {
+                    "type": "integer"
                 },
-                "idle_workers": {
+                "message": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "string"
+                },
+                "severity": {
+                    "$ref": "#/definitions/models.FindingSeverity"
+                }
+            }
+        },
+        "models.ScriptType": {
+            "type": "string",
+            "enum": [
+                "python",
+                "javascript",
+                "bash",
+                "go"
+            ],
+            "x-enum-varnames": [
+                "ScriptTypePython",
+                "ScriptTypeJavaScript",
+                "ScriptTypeBash",
+                "ScriptTypeGo"
+            ]
+        },
+        "models.TaskExecutionResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error_category": {
+                    "$ref": "#/definitions/models.ExecutionErrorCategory"
+                },
+                "execution_time_ms": {
                     "type": "integer"
                 },
-                "pool_size": {
+                "exit_signal": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "memory_usage_bytes": {
+                    "type": "integer"
+                },
+                "oom_killed": {
+                    "type": "boolean"
+                },
+                "output_expired_at": {
+                    "type": "string"
+                },
+                "return_code": {
+                    "type": "integer"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "runtime": {
+                    "type": "string"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.ExecutionStatus"
+                },
+                "stderr": {
+                    "type": "string"
+                },
+                "stdout": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "timeout_phase": {
+                    "$ref": "#/definitions/models.TimeoutPhase"
+                },
+                "truncated": {
+                    "type": "boolean"
+                },
+                "version": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.TaskImportMode": {
+            "type": "string",
+            "enum": [
+                "dry-run",
+                "diff",
+                "apply"
+            ],
+            "x-enum-varnames": [
+                "TaskImportModeDryRun",
+                "TaskImportModeDiff",
+                "TaskImportModeApply"
+            ]
+        },
+        "models.TaskImportResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskManifestChange"
+                    }
+                },
+                "error": {
+                    "description": "Error is set when the manifest was rejected; the changes that can't\nbe applied carry their own error",
+                    "type": "string"
                 },
-                "total_tasks_failed": {
+                "mode": {
+                    "$ref": "#/definitions/models.TaskImportMode"
+                },
+                "summary": {
+                    "$ref": "#/definitions/models.TaskImportSummary"
+                }
+            }
+        },
+        "models.TaskImportSummary": {
+            "type": "object",
+            "properties": {
+                "create": {
                     "type": "integer"
                 },
-                "total_tasks_processed": {
+                "delete": {
                     "type": "integer"
                 },
-                "total_tasks_successful": {
+                "unchanged": {
                     "type": "integer"
                 },
-                "unhealthy_workers": {
+                "update": {
                     "type": "integer"
                 }
             }
         },
-        "handlers.WorkerStatusResponse": {
+        "models.TaskListResponse": {
             "type": "object",
             "properties": {
-                "concurrency": {
-                    "$ref": "#/definitions/handlers.ConcurrencyStatus"
+                "limit": {
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "offset": {
+                    "type": "integer"
                 },
-                "timestamp": {
-                    "type": "string"
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskResponse"
+                    }
                 },
-                "worker_manager": {
-                    "$ref": "#/definitions/handlers.WorkerManagerStatus"
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TaskManifest": {
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskManifestEntry"
+                    }
                 },
-                "worker_pool": {
-                    "$ref": "#/definitions/handlers.WorkerPoolStatus"
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "models.AuthResponse": {
+        "models.TaskManifestAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "unchanged"
+            ],
+            "x-enum-varnames": [
+                "TaskManifestActionCreate",
+                "TaskManifestActionUpdate",
+                "TaskManifestActionDelete",
+                "TaskManifestActionUnchanged"
+            ]
+        },
+        "models.TaskManifestChange": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "action": {
+                    "$ref": "#/definitions/models.TaskManifestAction"
+                },
+                "error": {
+                    "description": "Error tells why the change can't be applied",
                     "type": "string"
                 },
-                "expires_in": {
-                    "type": "integer"
+                "fields": {
+                    "description": "Fields lists the changed fields of an update, in diff mode only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskManifestFieldChange"
+                    }
                 },
-                "refresh_token": {
+                "key": {
                     "type": "string"
                 },
-                "token_type": {
+                "name": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.UserResponse"
+                "task_id": {
+                    "description": "TaskID is the existing task, or the created one once applied",
+                    "type": "string"
                 }
             }
         },
-        "models.CreateTaskRequest": {
+        "models.TaskManifestEntry": {
             "type": "object",
-            "required": [
-                "name",
-                "script_content",
-                "script_type"
-            ],
             "properties": {
                 "description": {
-                    "type": "string",
-                    "maxLength": 1000
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Key identifies the task across imports; it is stored as the task's\nexternal key",
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
                 "metadata": {
                     "$ref": "#/definitions/models.JSONB"
                 },
                 "name": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                    "type": "string"
+                },
+                "network_allowlist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "network_mode": {
+                    "$ref": "#/definitions/models.TaskNetworkMode"
                 },
                 "priority": {
-                    "type": "integer",
-                    "maximum": 10,
-                    "minimum": 0
+                    "type": "integer"
                 },
-                "script_content": {
-                    "type": "string",
-                    "maxLength": 65535,
-                    "minLength": 1
+                "required_capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script": {
+                    "description": "Script holds the script inline. ScriptFile instead refers to a file\nrelative to the manifest, which must be read into Script before the\nmanifest is imported.",
+                    "type": "string"
+                },
+                "script_file": {
+                    "type": "string"
                 },
                 "script_type": {
                     "$ref": "#/definitions/models.ScriptType"
                 },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
                 "timeout_seconds": {
-                    "type": "integer",
-                    "maximum": 3600,
-                    "minimum": 1
+                    "type": "integer"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.TaskManifestFieldChange": {
             "type": "object",
             "properties": {
-                "details": {
+                "diff": {
+                    "description": "Diff is a unified diff of the script, set instead of From and To",
                     "type": "string"
                 },
-                "error": {
+                "field": {
                     "type": "string"
                 },
-                "validation_errors": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.ValidationError"
-                    }
-                }
+                "from": {},
+                "to": {}
             }
         },
-        "models.ExecutionStatus": {
+        "models.TaskNetworkMode": {
             "type": "string",
             "enum": [
-                "pending",
-                "running",
-                "completed",
-                "failed",
-                "timeout",
-                "cancelled"
+                "none",
+                "internal",
+                "allowlist"
             ],
             "x-enum-varnames": [
-                "ExecutionStatusPending",
-                "ExecutionStatusRunning",
-                "ExecutionStatusCompleted",
-                "ExecutionStatusFailed",
-                "ExecutionStatusTimeout",
-                "ExecutionStatusCancelled"
+                "NetworkModeNone",
+                "NetworkModeInternal",
+                "NetworkModeAllowlist"
             ]
         },
-        "models.JSONB": {
-            "type": "object",
-            "additionalProperties": true
-        },
-        "models.LoginRequest": {
+        "models.TaskResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "created_at": {
                     "type": "string"
                 },
-                "password": {
+                "deleted_at": {
+                    "description": "DeletedAt is set on the tasks listed from the trash",
                     "type": "string"
-                }
-            }
-        },
-        "models.RefreshTokenRequest": {
-            "type": "object",
-            "required": [
-                "refresh_token"
-            ],
-            "properties": {
-                "refresh_token": {
+                },
+                "description": {
                     "type": "string"
-                }
-            }
-        },
-        "models.RegisterRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "name",
-                "password"
-            ],
-            "properties": {
-                "email": {
+                },
+                "external_key": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "image_digest": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.JSONB"
+                },
                 "name": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                    "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
-                }
-            }
-        },
-        "models.ScriptType": {
-            "type": "string",
-            "enum": [
-                "python",
-                "javascript",
-                "bash",
-                "go"
-            ],
-            "x-enum-varnames": [
-                "ScriptTypePython",
-                "ScriptTypeJavaScript",
-                "ScriptTypeBash",
-                "ScriptTypeGo"
-            ]
+                "network_allowlist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "network_mode": {
+                    "$ref": "#/definitions/models.TaskNetworkMode"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "required_capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "script_content": {
+                    "type": "string"
+                },
+                "script_findings": {
+                    "description": "ScriptFindings are the script analysis warnings reported when the task\nis saved with script analysis in warn mode",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScriptFinding"
+                    }
+                },
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.TaskStatus"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
         },
-        "models.TaskExecutionResponse": {
+        "models.TaskRevisionDiffResponse": {
             "type": "object",
             "properties": {
-                "completed_at": {
+                "diff": {
+                    "description": "Diff is a unified diff of the script content, empty when it is unchanged",
                     "type": "string"
                 },
-                "created_at": {
+                "from": {
+                    "type": "integer"
+                },
+                "script_type_changed": {
+                    "type": "boolean"
+                },
+                "task_id": {
                     "type": "string"
                 },
-                "execution_time_ms": {
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TaskRevisionListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
                     "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "offset": {
+                    "type": "integer"
                 },
-                "memory_usage_bytes": {
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskRevisionResponse"
+                    }
+                },
+                "total": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.TaskRevisionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "return_code": {
+                "revision": {
                     "type": "integer"
                 },
-                "started_at": {
+                "script_content": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/models.ExecutionStatus"
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
                 },
-                "stderr": {
+                "task_id": {
                     "type": "string"
+                }
+            }
+        },
+        "models.TaskSearchResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
                 },
-                "stdout": {
+                "pagination": {
+                    "$ref": "#/definitions/models.CursorPagination"
+                },
+                "sort_field": {
                     "type": "string"
                 },
-                "task_id": {
+                "sort_order": {
                     "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskResponse"
+                    }
                 }
             }
         },
-        "models.TaskListResponse": {
+        "models.TaskSecurityLevel": {
+            "type": "string",
+            "enum": [
+                "standard",
+                "sandboxed",
+                "isolated"
+            ],
+            "x-enum-varnames": [
+                "SecurityLevelStandard",
+                "SecurityLevelSandboxed",
+                "SecurityLevelIsolated"
+            ]
+        },
+        "models.TaskStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed",
+                "timeout",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "TaskStatusPending",
+                "TaskStatusRunning",
+                "TaskStatusCompleted",
+                "TaskStatusFailed",
+                "TaskStatusTimeout",
+                "TaskStatusCancelled"
+            ]
+        },
+        "models.TaskTemplateListResponse": {
             "type": "object",
             "properties": {
                 "limit": {
@@ -986,10 +4598,10 @@ const docTemplate = `{
                 "offset": {
                     "type": "integer"
                 },
-                "tasks": {
+                "templates": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.TaskResponse"
+                        "$ref": "#/definitions/models.TaskTemplateResponse"
                     }
                 },
                 "total": {
@@ -997,7 +4609,7 @@ const docTemplate = `{
                 }
             }
         },
-        "models.TaskResponse": {
+        "models.TaskTemplateResponse": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -1009,23 +4621,38 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/models.JSONB"
-                },
                 "name": {
                     "type": "string"
                 },
+                "owner_id": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateParameter"
+                    }
+                },
                 "priority": {
                     "type": "integer"
                 },
-                "script_content": {
+                "required_capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script_template": {
                     "type": "string"
                 },
                 "script_type": {
                     "$ref": "#/definitions/models.ScriptType"
                 },
-                "status": {
-                    "$ref": "#/definitions/models.TaskStatus"
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "team": {
+                    "type": "string"
                 },
                 "timeout_seconds": {
                     "type": "integer"
@@ -1033,30 +4660,211 @@ const docTemplate = `{
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "visibility": {
+                    "$ref": "#/definitions/models.TemplateVisibility"
+                }
+            }
+        },
+        "models.TemplateParameter": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default is used when a task is created without a value for the\nparameter. Optional parameters without a default are the zero value of\ntheir type."
+                },
+                "description": {
                     "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "$ref": "#/definitions/models.TemplateParameterType"
                 }
             }
         },
-        "models.TaskStatus": {
+        "models.TemplateParameterType": {
             "type": "string",
             "enum": [
-                "pending",
-                "running",
-                "completed",
-                "failed",
-                "timeout",
-                "cancelled"
+                "string",
+                "integer",
+                "number",
+                "boolean"
             ],
             "x-enum-varnames": [
-                "TaskStatusPending",
-                "TaskStatusRunning",
-                "TaskStatusCompleted",
-                "TaskStatusFailed",
-                "TaskStatusTimeout",
-                "TaskStatusCancelled"
+                "TemplateParameterTypeString",
+                "TemplateParameterTypeInteger",
+                "TemplateParameterTypeNumber",
+                "TemplateParameterTypeBoolean"
+            ]
+        },
+        "models.TemplateVisibility": {
+            "type": "string",
+            "enum": [
+                "private",
+                "team",
+                "global"
+            ],
+            "x-enum-varnames": [
+                "TemplateVisibilityPrivate",
+                "TemplateVisibilityTeam",
+                "TemplateVisibilityGlobal"
+            ]
+        },
+        "models.TimeoutPhase": {
+            "type": "string",
+            "enum": [
+                "image_pull",
+                "start",
+                "run"
+            ],
+            "x-enum-varnames": [
+                "TimeoutPhaseImagePull",
+                "TimeoutPhaseStart",
+                "TimeoutPhaseRun"
             ]
         },
+        "models.TrashPurgeResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UpdateTaskRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "image": {
+                    "type": "string",
+                    "maxLength": 512
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.JSONB"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "network_allowlist": {
+                    "type": "array",
+                    "maxItems": 32,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "network_mode": {
+                    "$ref": "#/definitions/models.TaskNetworkMode"
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "required_capabilities": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script_content": {
+                    "type": "string",
+                    "maxLength": 65535,
+                    "minLength": 1
+                },
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.UpdateTaskTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "parameters": {
+                    "type": "array",
+                    "maxItems": 32,
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateParameter"
+                    }
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "required_capabilities": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script_template": {
+                    "type": "string",
+                    "maxLength": 65535,
+                    "minLength": 1
+                },
+                "script_type": {
+                    "$ref": "#/definitions/models.ScriptType"
+                },
+                "security_level": {
+                    "$ref": "#/definitions/models.TaskSecurityLevel"
+                },
+                "team": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "timeout_seconds": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
+                },
+                "visibility": {
+                    "enum": [
+                        "private",
+                        "team",
+                        "global"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TemplateVisibility"
+                        }
+                    ]
+                }
+            }
+        },
         "models.UserResponse": {
             "type": "object",
             "properties": {
@@ -1101,6 +4909,12 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "RunnerAuth": {
+            "description": "Type \"Bearer\" followed by a space and a runner token.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,337 @@
+// Package admission decides whether users may save and execute tasks.
+//
+// Admission policies are loaded from a policy file and apply to users either
+// directly or through the groups they belong to. Each policy restricts the
+// script types, resources, images, network modes and script analysis
+// findings of its users' tasks. Denials list every rule the task violates, so
+// users can tell what to change.
+//
+// The groups are also the teams task templates can be shared with.
+package admission
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the content of a policy file, in YAML or JSON
+type File struct {
+	// Groups maps group names to their members' emails or user IDs
+	Groups map[string][]string `yaml:"groups"`
+
+	// Policies are matched in order; the first policy that applies to a user
+	// decides. Users no policy applies to are only bound by the global
+	// executor settings.
+	Policies []Policy `yaml:"policies"`
+}
+
+// Policy is a set of rules for the users and groups it applies to
+type Policy struct {
+	Name string `yaml:"name"`
+
+	// Users lists emails or user IDs. A policy without users and groups
+	// applies to everyone, which makes it a catch-all at the end of the file.
+	Users  []string `yaml:"users"`
+	Groups []string `yaml:"groups"`
+
+	Rules Rules `yaml:"rules"`
+}
+
+// Rules restrict the tasks of a policy's users. Omitted rules are not
+// restricted, while an empty list allows nothing: `images: []` limits users
+// to the default images.
+type Rules struct {
+	ScriptTypes       []models.ScriptType        `yaml:"script_types"`
+	MaxTimeoutSeconds int                        `yaml:"max_timeout_seconds"`
+	MaxPriority       *int                       `yaml:"max_priority"`
+	SecurityLevels    []models.TaskSecurityLevel `yaml:"security_levels"`
+	NetworkModes      []models.TaskNetworkMode   `yaml:"network_modes"`
+
+	// Images lists the repositories custom task images may come from, in
+	// the format of the executor's repository allowlist
+	Images []string `yaml:"images"`
+
+	// MaxFindingSeverity denies scripts with script analysis findings above
+	// this severity, whatever the script analysis mode
+	MaxFindingSeverity models.FindingSeverity `yaml:"max_finding_severity"`
+}
+
+// Engine evaluates tasks against the admission policies
+type Engine struct {
+	policies []Policy
+	members  map[string][]string // group name -> normalized members
+	analyzer *analyzer.Pipeline
+}
+
+// LoadFile reads a policy file and creates an engine from it
+func LoadFile(path string, scriptAnalyzer *analyzer.Pipeline) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admission policy file: %w", err)
+	}
+
+	// Unknown fields are rejected, as a misspelled rule would silently leave
+	// the rule unrestricted
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse admission policy file %s: %w", path, err)
+	}
+
+	engine, err := NewEngine(file, scriptAnalyzer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admission policy file %s: %w", path, err)
+	}
+	return engine, nil
+}
+
+// NewEngine validates the policies and creates an engine evaluating them. The
+// script analyzer reports the findings checked against severity thresholds;
+// when nil, a block mode pipeline is used.
+func NewEngine(file File, scriptAnalyzer *analyzer.Pipeline) (*Engine, error) {
+	if scriptAnalyzer == nil {
+		scriptAnalyzer = analyzer.NewPipeline(models.ScriptAnalysisModeBlock)
+	}
+
+	members := make(map[string][]string, len(file.Groups))
+	for group, users := range file.Groups {
+		for _, user := range users {
+			members[group] = append(members[group], normalizeSubject(user))
+		}
+	}
+
+	names := make(map[string]bool, len(file.Policies))
+	for i, policy := range file.Policies {
+		if policy.Name == "" {
+			return nil, fmt.Errorf("policy %d has no name", i+1)
+		}
+		if names[policy.Name] {
+			return nil, fmt.Errorf("duplicate policy %q", policy.Name)
+		}
+		names[policy.Name] = true
+
+		for _, group := range policy.Groups {
+			if _, ok := members[group]; !ok {
+				return nil, fmt.Errorf("policy %q refers to unknown group %q", policy.Name, group)
+			}
+		}
+		if err := policy.Rules.validate(); err != nil {
+			return nil, fmt.Errorf("policy %q: %w", policy.Name, err)
+		}
+	}
+
+	return &Engine{
+		policies: file.Policies,
+		members:  members,
+		analyzer: scriptAnalyzer,
+	}, nil
+}
+
+// validate checks that the rules only name known values
+func (r Rules) validate() error {
+	for _, scriptType := range r.ScriptTypes {
+		if err := models.ValidateScriptType(scriptType); err != nil {
+			return err
+		}
+	}
+	if r.MaxTimeoutSeconds < 0 {
+		return errors.New("max_timeout_seconds must not be negative")
+	}
+	if r.MaxPriority != nil && (*r.MaxPriority < 0 || *r.MaxPriority > 10) {
+		return errors.New("max_priority must be between 0 and 10")
+	}
+	for _, level := range r.SecurityLevels {
+		if err := models.ValidateSecurityLevel(level); err != nil {
+			return err
+		}
+	}
+	for _, mode := range r.NetworkModes {
+		if err := models.ValidateNetworkMode(mode); err != nil {
+			return err
+		}
+	}
+	for _, image := range r.Images {
+		if err := executor.ValidateRepositoryPattern(image); err != nil {
+			return err
+		}
+	}
+	if r.MaxFindingSeverity != "" {
+		if err := models.ValidateFindingSeverity(r.MaxFindingSeverity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PolicyFor returns the policy that applies to the user, or nil when none does
+func (e *Engine) PolicyFor(user *models.User) *Policy {
+	subjects := subjectsOf(user)
+	for i := range e.policies {
+		policy := &e.policies[i]
+		if len(policy.Users) == 0 && len(policy.Groups) == 0 {
+			return policy
+		}
+		for _, user := range policy.Users {
+			if slices.Contains(subjects, normalizeSubject(user)) {
+				return policy
+			}
+		}
+		for _, group := range policy.Groups {
+			for _, member := range e.members[group] {
+				if slices.Contains(subjects, member) {
+					return policy
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GroupsOf returns the names of the groups the user is a member of, sorted
+func (e *Engine) GroupsOf(user *models.User) []string {
+	subjects := subjectsOf(user)
+
+	var groups []string
+	for group, members := range e.members {
+		for _, member := range members {
+			if slices.Contains(subjects, member) {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	slices.Sort(groups)
+	return groups
+}
+
+// Evaluate checks the task against the policy that applies to the user
+func (e *Engine) Evaluate(user *models.User, task *models.Task) *Decision {
+	policy := e.PolicyFor(user)
+	if policy == nil {
+		return &Decision{}
+	}
+
+	decision := &Decision{Policy: policy.Name}
+	deny := func(rule, format string, args ...any) {
+		decision.Violations = append(decision.Violations, models.PolicyViolation{
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	rules := policy.Rules
+	if rules.ScriptTypes != nil && !slices.Contains(rules.ScriptTypes, task.ScriptType) {
+		deny("script_types", "script type %s is not allowed%s", task.ScriptType, allowed(rules.ScriptTypes))
+	}
+	if rules.MaxTimeoutSeconds > 0 && task.TimeoutSeconds > rules.MaxTimeoutSeconds {
+		deny("max_timeout_seconds", "timeout of %d seconds exceeds the maximum of %d", task.TimeoutSeconds, rules.MaxTimeoutSeconds)
+	}
+	if rules.MaxPriority != nil && task.Priority > *rules.MaxPriority {
+		deny("max_priority", "priority %d exceeds the maximum of %d", task.Priority, *rules.MaxPriority)
+	}
+	if rules.SecurityLevels != nil && !slices.Contains(rules.SecurityLevels, task.SecurityLevel) {
+		deny("security_levels", "security level %s is not allowed%s", task.SecurityLevel, allowed(rules.SecurityLevels))
+	}
+	if rules.NetworkModes != nil && !slices.Contains(rules.NetworkModes, task.NetworkMode) {
+		deny("network_modes", "network mode %s is not allowed%s", task.NetworkMode, allowed(rules.NetworkModes))
+	}
+	if rules.Images != nil && task.Image != nil && !executor.MatchImageRepository(rules.Images, *task.Image) {
+		if len(rules.Images) == 0 {
+			deny("images", "custom images are not allowed")
+		} else {
+			deny("images", "image %s is not allowed%s", *task.Image, allowed(rules.Images))
+		}
+	}
+	if rules.MaxFindingSeverity != "" {
+		var exceeding []models.ScriptFinding
+		for _, finding := range e.analyzer.AnalyzeTask(task).Findings {
+			if finding.Severity.Exceeds(rules.MaxFindingSeverity) {
+				exceeding = append(exceeding, finding)
+			}
+		}
+		if len(exceeding) > 0 {
+			msg := fmt.Sprintf("script analysis finding %s (%s) exceeds the maximum severity %s",
+				exceeding[0], exceeding[0].Severity, rules.MaxFindingSeverity)
+			if more := len(exceeding) - 1; more > 0 {
+				msg += fmt.Sprintf(" (and %d more)", more)
+			}
+			deny("max_finding_severity", "%s", msg)
+		}
+	}
+
+	return decision
+}
+
+// Decision is the outcome of evaluating a task
+type Decision struct {
+	// Policy is the name of the policy the task was evaluated against, empty
+	// when no policy applies to the user
+	Policy     string
+	Violations []models.PolicyViolation
+}
+
+// Allowed reports whether the task is admitted
+func (d *Decision) Allowed() bool {
+	return len(d.Violations) == 0
+}
+
+// Err returns a *DeniedError when the task is denied, and nil otherwise
+func (d *Decision) Err() error {
+	if d.Allowed() {
+		return nil
+	}
+	return &DeniedError{Policy: d.Policy, Violations: d.Violations}
+}
+
+// DeniedError is returned for tasks denied by an admission policy
+type DeniedError struct {
+	Policy     string
+	Violations []models.PolicyViolation
+}
+
+// Error implements the error interface
+func (e *DeniedError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		reasons[i] = violation.String()
+	}
+	return fmt.Sprintf("denied by admission policy %s: %s", e.Policy, strings.Join(reasons, "; "))
+}
+
+// subjectsOf returns the normalized subjects policies and groups may name the user by
+func subjectsOf(user *models.User) []string {
+	subjects := []string{normalizeSubject(user.ID.String())}
+	if user.Email != "" {
+		subjects = append(subjects, normalizeSubject(user.Email))
+	}
+	return subjects
+}
+
+// normalizeSubject makes emails match case-insensitively
+func normalizeSubject(subject string) string {
+	return strings.ToLower(strings.TrimSpace(subject))
+}
+
+// allowed formats the allowed values of a rule for a violation message
+func allowed[T ~string](values []T) string {
+	if len(values) == 0 {
+		return ""
+	}
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = string(value)
+	}
+	return "; allowed: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,317 @@
+package admission
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const testPolicyFile = `
+groups:
+  interns:
+    - Intern@Example.com
+  platform:
+    - ops@example.com
+
+policies:
+  - name: platform
+    groups: [platform]
+    rules:
+      max_timeout_seconds: 3600
+
+  - name: interns
+    groups: [interns]
+    rules:
+      script_types: [python, javascript]
+      max_timeout_seconds: 60
+      max_priority: 5
+      security_levels: [sandboxed]
+      network_modes: [none]
+      images: []
+      max_finding_severity: medium
+
+  - name: default
+    rules:
+      images: [python, "ghcr.io/acme/*"]
+`
+
+func loadTestEngine(t *testing.T) *Engine {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicyFile), 0600))
+
+	engine, err := LoadFile(path, nil)
+	require.NoError(t, err)
+	return engine
+}
+
+func testUser(email string) *models.User {
+	return &models.User{BaseModel: models.BaseModel{ID: uuid.New()}, Email: email}
+}
+
+func internTask() *models.Task {
+	return &models.Task{
+		ScriptContent:  "print('hello')",
+		ScriptType:     models.ScriptTypePython,
+		Priority:       5,
+		TimeoutSeconds: 30,
+		SecurityLevel:  models.SecurityLevelSandboxed,
+		NetworkMode:    models.NetworkModeNone,
+	}
+}
+
+func violatedRules(decision *Decision) []string {
+	rules := make([]string, len(decision.Violations))
+	for i, violation := range decision.Violations {
+		rules[i] = violation.Rule
+	}
+	return rules
+}
+
+func TestEngine_PolicyFor(t *testing.T) {
+	engine := loadTestEngine(t)
+
+	tests := []struct {
+		name   string
+		user   *models.User
+		policy string
+	}{
+		{name: "group member", user: testUser("ops@example.com"), policy: "platform"},
+		{name: "emails match case-insensitively", user: testUser("intern@example.com"), policy: "interns"},
+		{name: "catch-all policy", user: testUser("someone@example.com"), policy: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := engine.PolicyFor(tt.user)
+			require.NotNil(t, policy)
+			assert.Equal(t, tt.policy, policy.Name)
+		})
+	}
+
+	t.Run("user ID", func(t *testing.T) {
+		user := testUser("")
+		engine, err := NewEngine(File{Policies: []Policy{{Name: "by-id", Users: []string{user.ID.String()}}}}, nil)
+		require.NoError(t, err)
+
+		require.NotNil(t, engine.PolicyFor(user))
+		assert.Nil(t, engine.PolicyFor(testUser("other@example.com")))
+	})
+}
+
+func TestEngine_GroupsOf(t *testing.T) {
+	user := testUser("ops@example.com")
+	engine, err := NewEngine(File{Groups: map[string][]string{
+		"platform": {"OPS@example.com"},
+		"oncall":   {user.ID.String()},
+		"interns":  {"intern@example.com"},
+	}}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"oncall", "platform"}, engine.GroupsOf(user))
+	assert.Empty(t, engine.GroupsOf(testUser("someone@example.com")))
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	engine := loadTestEngine(t)
+	intern := testUser("intern@example.com")
+
+	tests := []struct {
+		name      string
+		user      *models.User
+		mutate    func(task *models.Task)
+		wantRules []string
+	}{
+		{
+			name:   "compliant task",
+			user:   intern,
+			mutate: func(task *models.Task) {},
+		},
+		{
+			name:      "script type",
+			user:      intern,
+			mutate:    func(task *models.Task) { task.ScriptType = models.ScriptTypeBash; task.ScriptContent = "echo hi" },
+			wantRules: []string{"script_types"},
+		},
+		{
+			name:      "timeout and priority",
+			user:      intern,
+			mutate:    func(task *models.Task) { task.TimeoutSeconds = 600; task.Priority = 9 },
+			wantRules: []string{"max_timeout_seconds", "max_priority"},
+		},
+		{
+			name: "security level and network mode",
+			user: intern,
+			mutate: func(task *models.Task) {
+				task.SecurityLevel = models.SecurityLevelStandard
+				task.NetworkMode = models.NetworkModeInternal
+			},
+			wantRules: []string{"security_levels", "network_modes"},
+		},
+		{
+			name:      "custom image with an empty image list",
+			user:      intern,
+			mutate:    func(task *models.Task) { task.Image = stringPtr("python:3.12") },
+			wantRules: []string{"images"},
+		},
+		{
+			name:      "finding above the severity threshold",
+			user:      intern,
+			mutate:    func(task *models.Task) { task.ScriptContent = "import subprocess" },
+			wantRules: []string{"max_finding_severity"},
+		},
+		{
+			name:   "allowed image repository",
+			user:   testUser("someone@example.com"),
+			mutate: func(task *models.Task) { task.Image = stringPtr("ghcr.io/acme/runner:1") },
+		},
+		{
+			name:      "image repository outside the list",
+			user:      testUser("someone@example.com"),
+			mutate:    func(task *models.Task) { task.Image = stringPtr("node:20") },
+			wantRules: []string{"images"},
+		},
+		{
+			name: "unrestricted rules",
+			user: testUser("ops@example.com"),
+			mutate: func(task *models.Task) {
+				task.ScriptType = models.ScriptTypeBash
+				task.ScriptContent = "sudo id"
+				task.Image = stringPtr("node:20")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := internTask()
+			tt.mutate(task)
+
+			decision := engine.Evaluate(tt.user, task)
+			if len(tt.wantRules) == 0 {
+				assert.True(t, decision.Allowed(), "violations: %v", decision.Violations)
+				assert.NoError(t, decision.Err())
+				return
+			}
+
+			assert.False(t, decision.Allowed())
+			assert.Equal(t, tt.wantRules, violatedRules(decision))
+
+			var denied *DeniedError
+			require.ErrorAs(t, decision.Err(), &denied)
+			assert.Equal(t, engine.PolicyFor(tt.user).Name, denied.Policy)
+		})
+	}
+
+	t.Run("violations explain the denial", func(t *testing.T) {
+		task := internTask()
+		task.ScriptType = models.ScriptTypeGo
+
+		err := engine.Evaluate(intern, task).Err()
+		require.Error(t, err)
+		assert.Equal(t, "denied by admission policy interns: script_types: script type go is not allowed; allowed: python, javascript", err.Error())
+	})
+
+	t.Run("no matching policy admits the task", func(t *testing.T) {
+		engine, err := NewEngine(File{}, nil)
+		require.NoError(t, err)
+
+		decision := engine.Evaluate(intern, internTask())
+		assert.True(t, decision.Allowed())
+		assert.Empty(t, decision.Policy)
+	})
+}
+
+func TestNewEngine_Validation(t *testing.T) {
+	negative := -1
+
+	tests := []struct {
+		name    string
+		file    File
+		wantErr string
+	}{
+		{
+			name:    "policy without a name",
+			file:    File{Policies: []Policy{{}}},
+			wantErr: "policy 1 has no name",
+		},
+		{
+			name:    "duplicate policy",
+			file:    File{Policies: []Policy{{Name: "a"}, {Name: "a"}}},
+			wantErr: "duplicate policy",
+		},
+		{
+			name:    "unknown group",
+			file:    File{Policies: []Policy{{Name: "a", Groups: []string{"interns"}}}},
+			wantErr: "unknown group",
+		},
+		{
+			name:    "unknown script type",
+			file:    File{Policies: []Policy{{Name: "a", Rules: Rules{ScriptTypes: []models.ScriptType{"ruby"}}}}},
+			wantErr: "invalid script type",
+		},
+		{
+			name:    "negative max priority",
+			file:    File{Policies: []Policy{{Name: "a", Rules: Rules{MaxPriority: &negative}}}},
+			wantErr: "max_priority",
+		},
+		{
+			name:    "image with a tag",
+			file:    File{Policies: []Policy{{Name: "a", Rules: Rules{Images: []string{"python:3.12"}}}}},
+			wantErr: "must not include a tag or digest",
+		},
+		{
+			name:    "unknown severity",
+			file:    File{Policies: []Policy{{Name: "a", Rules: Rules{MaxFindingSeverity: "severe"}}}},
+			wantErr: "invalid finding severity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine(tt.file, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policies.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"policies": [{"name": "all", "rules": {"script_types": ["python"]}}]}`), 0600))
+
+		engine, err := LoadFile(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "all", engine.PolicyFor(testUser("a@example.com")).Name)
+	})
+
+	t.Run("example policy file", func(t *testing.T) {
+		engine, err := LoadFile("../../config/admission-policy.example.yaml", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "interns", engine.PolicyFor(testUser("intern1@example.com")).Name)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown fields are rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policies.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("policies:\n  - name: a\n    rules:\n      script_type: [python]\n"), 0600))
+
+		_, err := LoadFile(path, nil)
+		assert.Error(t, err)
+	})
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
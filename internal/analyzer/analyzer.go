@@ -0,0 +1,229 @@
+// Package analyzer statically analyzes task scripts for dangerous operations.
+//
+// Each script type has its own analyzers: bash scripts are parsed into a
+// syntax tree, Python, JavaScript and Go scripts are tokenized. Analyzers
+// report structured findings, and the pipeline's mode decides whether
+// findings block a script or are only reported.
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// maxFindings caps the number of findings reported for a single script
+const maxFindings = 100
+
+// Rule describes a check performed by an analyzer
+type Rule struct {
+	ID       string
+	Severity models.FindingSeverity
+
+	// Network marks rules that only guard against network access. They are
+	// skipped for tasks whose network policy grants access.
+	Network bool
+}
+
+// rules holds every rule known to the analyzers, by ID
+var rules = map[string]Rule{}
+
+// newRule registers a rule
+func newRule(id string, severity models.FindingSeverity, network bool) Rule {
+	rule := Rule{ID: id, Severity: severity, Network: network}
+	rules[id] = rule
+	return rule
+}
+
+// at returns a finding of the rule at the given position
+func (r Rule) at(line, column int, format string, args ...any) models.ScriptFinding {
+	return models.ScriptFinding{
+		RuleID:   r.ID,
+		Severity: r.Severity,
+		Line:     line,
+		Column:   column,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Rules shared by all script types
+var (
+	ruleSensitivePath   = newRule("script.sensitive_path", models.FindingSeverityHigh, false)
+	ruleContainerSocket = newRule("script.container_socket", models.FindingSeverityCritical, false)
+	ruleMiner           = newRule("script.miner", models.FindingSeverityCritical, false)
+)
+
+// sensitivePaths are host paths scripts have no business touching
+var sensitivePaths = []string{
+	"/etc/passwd",
+	"/etc/shadow",
+	"/etc/group",
+	"/etc/sudoers",
+	"/proc/",
+	"/sys/",
+	"/root/",
+	"/boot/",
+	"/dev/mem",
+	"/dev/kmem",
+}
+
+// containerSockets are the control sockets of container runtimes
+var containerSockets = []string{
+	"/var/run/docker.sock",
+	"/run/docker.sock",
+	"/run/containerd/",
+	"/var/run/crio/",
+	"/run/podman/",
+}
+
+// checkLiteral reports string literals naming sensitive paths, container
+// runtime sockets or mining pools
+func checkLiteral(value string, line, column int) []models.ScriptFinding {
+	for _, socket := range containerSockets {
+		if strings.Contains(value, socket) {
+			return []models.ScriptFinding{ruleContainerSocket.at(line, column, "access to container runtime socket %s", socket)}
+		}
+	}
+
+	lower := strings.ToLower(value)
+	if strings.Contains(lower, "stratum+tcp://") || strings.Contains(lower, "stratum+ssl://") || strings.Contains(lower, "stratum2+tcp://") {
+		return []models.ScriptFinding{ruleMiner.at(line, column, "mining pool address")}
+	}
+
+	for _, path := range sensitivePaths {
+		if strings.Contains(value, path) || value == strings.TrimSuffix(path, "/") {
+			return []models.ScriptFinding{ruleSensitivePath.at(line, column, "access to sensitive path %s", strings.TrimSuffix(path, "/"))}
+		}
+	}
+
+	return nil
+}
+
+// Analyzer inspects a script and reports its findings
+type Analyzer interface {
+	Analyze(script string) []models.ScriptFinding
+}
+
+// AnalyzerFunc adapts a function to the Analyzer interface
+type AnalyzerFunc func(script string) []models.ScriptFinding
+
+// Analyze calls f(script)
+func (f AnalyzerFunc) Analyze(script string) []models.ScriptFinding {
+	return f(script)
+}
+
+// Options adjust which rules apply to a script
+type Options struct {
+	// AllowNetwork skips the rules that only guard against network access
+	AllowNetwork bool
+}
+
+// Pipeline runs the analyzers registered for a script's type
+type Pipeline struct {
+	mode      models.ScriptAnalysisMode
+	analyzers map[models.ScriptType][]Analyzer
+}
+
+// NewPipeline creates a pipeline with the built-in analyzers. An empty mode
+// defaults to block.
+func NewPipeline(mode models.ScriptAnalysisMode) *Pipeline {
+	if mode == "" {
+		mode = models.ScriptAnalysisModeBlock
+	}
+
+	p := &Pipeline{
+		mode:      mode,
+		analyzers: make(map[models.ScriptType][]Analyzer),
+	}
+	p.Register(models.ScriptTypeBash, AnalyzerFunc(AnalyzeBash))
+	p.Register(models.ScriptTypePython, AnalyzerFunc(AnalyzePython))
+	p.Register(models.ScriptTypeJavaScript, AnalyzerFunc(AnalyzeJavaScript))
+	p.Register(models.ScriptTypeGo, AnalyzerFunc(AnalyzeGo))
+	return p
+}
+
+// Register adds an analyzer for scripts of the given type. It must not be
+// called concurrently with Analyze.
+func (p *Pipeline) Register(scriptType models.ScriptType, analyzer Analyzer) {
+	p.analyzers[scriptType] = append(p.analyzers[scriptType], analyzer)
+}
+
+// Mode returns the pipeline's policy mode
+func (p *Pipeline) Mode() models.ScriptAnalysisMode {
+	return p.mode
+}
+
+// Analyze runs the analyzers registered for the script type
+func (p *Pipeline) Analyze(scriptType models.ScriptType, script string, opts Options) *Report {
+	var findings []models.ScriptFinding
+	seen := make(map[models.ScriptFinding]bool)
+
+	for _, analyzer := range p.analyzers[scriptType] {
+		for _, finding := range analyzer.Analyze(script) {
+			if opts.AllowNetwork && rules[finding.RuleID].Network {
+				continue
+			}
+			if seen[finding] {
+				continue
+			}
+			seen[finding] = true
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+	if len(findings) > maxFindings {
+		findings = findings[:maxFindings]
+	}
+
+	return &Report{Mode: p.mode, Findings: findings}
+}
+
+// AnalyzeTask analyzes a task's script, taking its network policy into account
+func (p *Pipeline) AnalyzeTask(task *models.Task) *Report {
+	return p.Analyze(task.ScriptType, task.ScriptContent, Options{AllowNetwork: task.HasNetworkAccess()})
+}
+
+// Report holds the findings of analyzing a script
+type Report struct {
+	Mode     models.ScriptAnalysisMode
+	Findings []models.ScriptFinding
+}
+
+// Blocked reports whether the script must be rejected
+func (r *Report) Blocked() bool {
+	return r.Mode == models.ScriptAnalysisModeBlock && len(r.Findings) > 0
+}
+
+// Err returns an *Error when the script must be rejected, and nil otherwise
+func (r *Report) Err() error {
+	if !r.Blocked() {
+		return nil
+	}
+	return &Error{Findings: r.Findings}
+}
+
+// Error is returned for scripts rejected by script analysis
+type Error struct {
+	Findings []models.ScriptFinding
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if len(e.Findings) == 0 {
+		return "script failed security analysis"
+	}
+
+	msg := "script failed security analysis: " + e.Findings[0].String()
+	if more := len(e.Findings) - 1; more > 0 {
+		msg += fmt.Sprintf(" (and %d more)", more)
+	}
+	return msg
+}
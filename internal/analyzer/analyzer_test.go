@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// ruleIDs returns the rule IDs of the findings, in order
+func ruleIDs(findings []models.ScriptFinding) []string {
+	var ids []string
+	for _, finding := range findings {
+		ids = append(ids, finding.RuleID)
+	}
+	return ids
+}
+
+func TestPipeline_Modes(t *testing.T) {
+	script := "import subprocess"
+
+	tests := []struct {
+		mode    models.ScriptAnalysisMode
+		blocked bool
+	}{
+		{"", true},
+		{models.ScriptAnalysisModeBlock, true},
+		{models.ScriptAnalysisModeWarn, false},
+		{models.ScriptAnalysisModeAudit, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			report := NewPipeline(tt.mode).Analyze(models.ScriptTypePython, script, Options{})
+			require.Len(t, report.Findings, 1)
+			assert.Equal(t, tt.blocked, report.Blocked())
+
+			err := report.Err()
+			if !tt.blocked {
+				assert.NoError(t, err)
+				return
+			}
+
+			var analysisErr *Error
+			require.True(t, errors.As(err, &analysisErr))
+			assert.Equal(t, report.Findings, analysisErr.Findings)
+			assert.Equal(t, "script failed security analysis: python.import.system at line 1: import of subprocess", err.Error())
+		})
+	}
+}
+
+func TestPipeline_AllowNetwork(t *testing.T) {
+	pipeline := NewPipeline(models.ScriptAnalysisModeBlock)
+	script := "curl https://pypi.org/simple/\necho hi > /dev/tcp/10.0.0.1/80"
+
+	report := pipeline.Analyze(models.ScriptTypeBash, script, Options{})
+	assert.Equal(t, []string{"bash.command.network", "bash.redirect.socket"}, ruleIDs(report.Findings))
+
+	// Raw sockets bypass the egress proxy, so they stay blocked
+	report = pipeline.Analyze(models.ScriptTypeBash, script, Options{AllowNetwork: true})
+	assert.Equal(t, []string{"bash.redirect.socket"}, ruleIDs(report.Findings))
+
+	task := &models.Task{
+		ScriptType:    models.ScriptTypePython,
+		ScriptContent: "import urllib.request",
+		NetworkMode:   models.NetworkModeInternal,
+	}
+	assert.Empty(t, pipeline.AnalyzeTask(task).Findings)
+
+	task.NetworkMode = models.NetworkModeNone
+	assert.Equal(t, []string{"python.import.network"}, ruleIDs(pipeline.AnalyzeTask(task).Findings))
+}
+
+func TestPipeline_Register(t *testing.T) {
+	rule := newRule("test.todo", models.FindingSeverityLow, false)
+	pipeline := NewPipeline(models.ScriptAnalysisModeWarn)
+	pipeline.Register(models.ScriptTypePython, AnalyzerFunc(func(script string) []models.ScriptFinding {
+		if strings.Contains(script, "TODO") {
+			return []models.ScriptFinding{rule.at(2, 1, "unfinished script")}
+		}
+		return nil
+	}))
+
+	report := pipeline.Analyze(models.ScriptTypePython, "import os\n# TODO", Options{})
+	assert.Equal(t, []string{"python.import.system", "test.todo"}, ruleIDs(report.Findings))
+	assert.Equal(t, models.ScriptAnalysisModeWarn, report.Mode)
+}
+
+func TestPipeline_CapsFindings(t *testing.T) {
+	script := strings.Repeat("sudo true\n", maxFindings+10)
+
+	report := NewPipeline(models.ScriptAnalysisModeBlock).Analyze(models.ScriptTypeBash, script, Options{})
+	require.Len(t, report.Findings, maxFindings)
+	assert.Equal(t, 1, report.Findings[0].Line)
+	assert.Equal(t, maxFindings, report.Findings[maxFindings-1].Line)
+}
+
+func TestAnalyzeGo(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "standard library",
+			script: "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hello\") }",
+		},
+		{
+			name:   "grouped imports",
+			script: "package main\n\nimport (\n\t\"fmt\"\n\tx \"os/exec\"\n\t\"net/http\"\n)\n",
+			want:   []string{"go.import.system", "go.import.network"},
+		},
+		{
+			name:   "string literal naming an import",
+			script: "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"os/exec\") }",
+		},
+		{
+			name:   "sensitive path",
+			script: "package main\n\nimport \"os\"\n\nfunc main() { os.ReadFile(\"/etc/shadow\") }",
+			want:   []string{"script.sensitive_path"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ruleIDs(AnalyzeGo(tt.script)))
+		})
+	}
+}
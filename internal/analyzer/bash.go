@@ -0,0 +1,345 @@
+package analyzer
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Bash rules
+var (
+	ruleBashSyntax      = newRule("bash.syntax", models.FindingSeverityHigh, false)
+	ruleBashPrivilege   = newRule("bash.command.privilege", models.FindingSeverityCritical, false)
+	ruleBashContainer   = newRule("bash.command.container", models.FindingSeverityCritical, false)
+	ruleBashNetwork     = newRule("bash.command.network", models.FindingSeverityHigh, true)
+	ruleBashRemote      = newRule("bash.command.remote", models.FindingSeverityHigh, false)
+	ruleBashDestructive = newRule("bash.command.destructive", models.FindingSeverityHigh, false)
+	ruleBashProcess     = newRule("bash.command.process", models.FindingSeverityMedium, false)
+	ruleBashPackage     = newRule("bash.command.package", models.FindingSeverityMedium, false)
+	ruleBashDynamic     = newRule("bash.command.dynamic", models.FindingSeverityMedium, false)
+	ruleBashEval        = newRule("bash.eval", models.FindingSeverityHigh, false)
+	ruleBashNestedShell = newRule("bash.nested_shell", models.FindingSeverityHigh, false)
+	ruleBashRecursiveRm = newRule("bash.rm.recursive", models.FindingSeverityCritical, false)
+	ruleBashSocket      = newRule("bash.redirect.socket", models.FindingSeverityCritical, false)
+	ruleBashRedirect    = newRule("bash.redirect.path", models.FindingSeverityMedium, false)
+	ruleBashLoaderEnv   = newRule("bash.env.loader", models.FindingSeverityHigh, false)
+	ruleBashForkBomb    = newRule("bash.fork_bomb", models.FindingSeverityCritical, false)
+)
+
+// bashCommands maps commands to the rule they violate
+var bashCommands = map[string]Rule{}
+
+func init() {
+	for rule, commands := range map[Rule][]string{
+		ruleBashPrivilege: {
+			"sudo", "su", "doas", "chroot", "nsenter", "unshare", "setpriv", "capsh",
+			"pivot_root", "mount", "umount", "passwd", "chpasswd", "chsh", "chfn",
+			"newgrp", "sg", "useradd", "userdel", "usermod", "groupadd", "visudo",
+			"insmod", "rmmod", "modprobe", "sysctl", "iptables", "ip6tables", "nft",
+			"reboot", "shutdown", "halt", "poweroff",
+		},
+		ruleBashContainer: {"docker", "kubectl", "podman", "containerd", "ctr", "runc", "crictl", "nerdctl"},
+		ruleBashNetwork:   {"curl", "wget"},
+		ruleBashRemote: {
+			"nc", "ncat", "netcat", "socat", "telnet", "ssh", "scp", "sftp", "rsync",
+			"ftp", "tftp", "nslookup", "dig", "host", "ping", "traceroute", "nmap",
+		},
+		ruleBashDestructive: {"mkfs", "fdisk", "sfdisk", "parted", "dd", "shred", "wipefs"},
+		ruleBashProcess:     {"kill", "killall", "pkill", "disown", "crontab", "at"},
+		ruleBashPackage: {
+			"apt", "apt-get", "yum", "dnf", "rpm", "dpkg", "apk", "snap", "flatpak",
+			"brew", "pacman", "zypper", "emerge",
+		},
+		ruleMiner: {"xmrig", "cpuminer", "ccminer", "minerd"},
+	} {
+		for _, command := range commands {
+			bashCommands[command] = rule
+		}
+	}
+}
+
+// bashWrappers run the command given in their arguments
+var bashWrappers = map[string]bool{
+	"command": true, "builtin": true, "exec": true, "env": true, "nice": true,
+	"nohup": true, "setsid": true, "timeout": true, "time": true, "xargs": true,
+	"stdbuf": true, "ionice": true,
+}
+
+// bashNumberPattern matches numbers and durations passed to wrappers
+var bashNumberPattern = regexp.MustCompile(`^[0-9][0-9.]*[smhd]?$`)
+
+// bashShells run code given to them that can't be analyzed
+var bashShells = map[string]bool{
+	"bash": true, "sh": true, "dash": true, "zsh": true, "ksh": true, "busybox": true,
+}
+
+// bashInterpreters run code given to them with an inline code flag
+var bashInterpreters = map[string]string{
+	"python": "-c", "python3": "-c", "perl": "-e", "ruby": "-e", "php": "-r", "node": "-e",
+}
+
+// bashWritablePaths are where scripts may redirect output to
+var bashWritablePaths = []string{"/tmp/", "/workspace/", "/dev/null", "/dev/stdout", "/dev/stderr", "/dev/fd/"}
+
+// AnalyzeBash parses a bash script and reports dangerous commands,
+// redirections and assignments
+func AnalyzeBash(script string) []models.ScriptFinding {
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(script), "")
+	if err != nil {
+		line, column := 1, 0
+		var parseErr syntax.ParseError
+		var langErr syntax.LangError
+		if errors.As(err, &parseErr) {
+			line, column = bashPos(parseErr.Pos)
+		} else if errors.As(err, &langErr) {
+			line, column = bashPos(langErr.Pos)
+		}
+		return []models.ScriptFinding{ruleBashSyntax.at(line, column, "script could not be parsed: %v", err)}
+	}
+
+	var findings []models.ScriptFinding
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.CallExpr:
+			findings = append(findings, checkBashCall(n.Args)...)
+		case *syntax.Redirect:
+			findings = append(findings, checkBashRedirect(n)...)
+		case *syntax.Assign:
+			if n.Name != nil && isLoaderVariable(n.Name.Value) {
+				line, column := bashPos(n.Pos())
+				findings = append(findings, ruleBashLoaderEnv.at(line, column, "assignment to dynamic loader variable %s", n.Name.Value))
+			}
+		case *syntax.FuncDecl:
+			findings = append(findings, checkBashForkBomb(n)...)
+		case *syntax.Lit:
+			line, column := bashPos(n.Pos())
+			findings = append(findings, checkLiteral(n.Value, line, column)...)
+		case *syntax.SglQuoted:
+			line, column := bashPos(n.Pos())
+			findings = append(findings, checkLiteral(n.Value, line, column)...)
+		}
+		return true
+	})
+
+	return findings
+}
+
+// checkBashCall checks the command a call runs, looking through wrappers
+// such as env and xargs
+func checkBashCall(args []*syntax.Word) []models.ScriptFinding {
+	for len(args) > 0 {
+		word := args[0]
+		line, column := bashPos(word.Pos())
+		name, ok := bashWordLiteral(word)
+		if !ok {
+			return []models.ScriptFinding{ruleBashDynamic.at(line, column, "command name is computed at run time")}
+		}
+		name = path.Base(name)
+
+		if bashWrappers[name] {
+			args = args[1:]
+			// Skip the wrapper's options, their numeric values (nice -n 5,
+			// timeout 10s) and, for env, its assignments
+			for len(args) > 0 {
+				arg, ok := bashWordLiteral(args[0])
+				if !ok || !strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") && !bashNumberPattern.MatchString(arg) {
+					break
+				}
+				args = args[1:]
+			}
+			continue
+		}
+
+		switch {
+		case name == "eval":
+			return []models.ScriptFinding{ruleBashEval.at(line, column, "eval runs code that can't be analyzed")}
+		case name == "source" || name == ".":
+			return []models.ScriptFinding{ruleBashEval.at(line, column, "%s runs code that can't be analyzed", name)}
+		case bashShells[name]:
+			return []models.ScriptFinding{ruleBashNestedShell.at(line, column, "nested %s runs code that can't be analyzed", name)}
+		case bashInterpreters[name] != "":
+			if bashHasFlag(args[1:], bashInterpreters[name]) {
+				return []models.ScriptFinding{ruleBashNestedShell.at(line, column, "inline %s code can't be analyzed", name)}
+			}
+			return nil
+		case name == "rm":
+			return checkBashRm(args[1:], line, column)
+		}
+
+		if rule, ok := bashCommands[name]; ok {
+			return []models.ScriptFinding{rule.at(line, column, "use of %s", name)}
+		}
+		if strings.HasPrefix(name, "mkfs.") {
+			return []models.ScriptFinding{ruleBashDestructive.at(line, column, "use of %s", name)}
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// checkBashRm reports recursive removals outside the writable directories
+func checkBashRm(args []*syntax.Word, line, column int) []models.ScriptFinding {
+	recursive := false
+	var targets []string
+	for _, word := range args {
+		arg, ok := bashWordLiteral(word)
+		if !ok {
+			continue
+		}
+		switch {
+		case arg == "--recursive":
+			recursive = true
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--"):
+			if strings.ContainsAny(arg, "rR") {
+				recursive = true
+			}
+		default:
+			targets = append(targets, arg)
+		}
+	}
+
+	if !recursive {
+		return nil
+	}
+
+	for _, target := range targets {
+		if (strings.HasPrefix(target, "/") || strings.HasPrefix(target, "~")) && !isBashWritablePath(target) {
+			return []models.ScriptFinding{ruleBashRecursiveRm.at(line, column, "recursive removal of %s", target)}
+		}
+	}
+	return nil
+}
+
+// checkBashRedirect reports redirections to network sockets and to files
+// outside the writable directories
+func checkBashRedirect(redirect *syntax.Redirect) []models.ScriptFinding {
+	if redirect.Word == nil {
+		return nil
+	}
+
+	target, ok := bashWordLiteral(redirect.Word)
+	if !ok {
+		return nil
+	}
+
+	line, column := bashPos(redirect.Pos())
+	if strings.HasPrefix(target, "/dev/tcp/") || strings.HasPrefix(target, "/dev/udp/") {
+		return []models.ScriptFinding{ruleBashSocket.at(line, column, "network connection through %s", target)}
+	}
+
+	switch redirect.Op {
+	case syntax.RdrOut, syntax.AppOut, syntax.RdrAll, syntax.AppAll, syntax.ClbOut, syntax.RdrInOut:
+		if strings.HasPrefix(target, "/") && !isBashWritablePath(target) {
+			return []models.ScriptFinding{ruleBashRedirect.at(line, column, "write to %s outside the working directories", target)}
+		}
+	}
+	return nil
+}
+
+// checkBashForkBomb reports functions that call themselves in a pipeline or
+// in the background, multiplying their processes
+func checkBashForkBomb(decl *syntax.FuncDecl) []models.ScriptFinding {
+	if decl.Name == nil || decl.Body == nil {
+		return nil
+	}
+
+	name := decl.Name.Value
+	callsSelf := func(stmt *syntax.Stmt) bool {
+		found := false
+		syntax.Walk(stmt, func(node syntax.Node) bool {
+			if call, ok := node.(*syntax.CallExpr); ok && len(call.Args) > 0 {
+				if lit, ok := bashWordLiteral(call.Args[0]); ok && lit == name {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	}
+
+	forkBomb := false
+	syntax.Walk(decl.Body, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.BinaryCmd:
+			if (n.Op == syntax.Pipe || n.Op == syntax.PipeAll) && (callsSelf(n.X) || callsSelf(n.Y)) {
+				forkBomb = true
+			}
+		case *syntax.Stmt:
+			if n.Background && callsSelf(n) {
+				forkBomb = true
+			}
+		}
+		return !forkBomb
+	})
+
+	if !forkBomb {
+		return nil
+	}
+	line, column := bashPos(decl.Pos())
+	return []models.ScriptFinding{ruleBashForkBomb.at(line, column, "function %s spawns copies of itself", name)}
+}
+
+// bashWordLiteral returns the value of a word made of literal and quoted
+// parts only
+func bashWordLiteral(word *syntax.Word) (string, bool) {
+	var b strings.Builder
+	for _, part := range word.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			b.WriteString(p.Value)
+		case *syntax.SglQuoted:
+			b.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range p.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return "", false
+				}
+				b.WriteString(lit.Value)
+			}
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// bashHasFlag reports whether the arguments contain the given flag, alone or
+// combined with other short flags
+func bashHasFlag(args []*syntax.Word, flag string) bool {
+	for _, word := range args {
+		arg, ok := bashWordLiteral(word)
+		if !ok || !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+			continue
+		}
+		if strings.Contains(arg[1:], strings.TrimPrefix(flag, "-")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBashWritablePath reports whether a path is in one of the writable directories
+func isBashWritablePath(target string) bool {
+	for _, prefix := range bashWritablePaths {
+		if strings.HasPrefix(target, prefix) && !strings.Contains(target, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoaderVariable reports whether a variable changes how binaries are loaded
+func isLoaderVariable(name string) bool {
+	return strings.HasPrefix(name, "LD_") || strings.HasPrefix(name, "DYLD_")
+}
+
+// bashPos returns the line and column of a position
+func bashPos(pos syntax.Pos) (int, int) {
+	return int(pos.Line()), int(pos.Col())
+}
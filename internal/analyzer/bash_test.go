@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeBash(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "comment", script: "# rm -rf /"},
+		{name: "pipeline", script: "echo 'hello' | grep 'hello'"},
+		{name: "redirection to working directory", script: "echo 'test' > output.txt\necho more >> /tmp/out.txt"},
+		{name: "logical operators", script: "test -f file.txt && echo 'file exists' || echo 'file not found'"},
+		{name: "dangerous words in strings", script: "echo 'do not run sudo or curl here'"},
+		{name: "recursive function", script: "count() { [ \"$1\" -gt 0 ] && count $(($1 - 1)); }\ncount 3"},
+		{name: "recursive removal in tmp", script: "rm -rf /tmp/build"},
+		{name: "recursive removal of root", script: "rm -rf /", want: []string{"bash.rm.recursive"}},
+		{name: "recursive removal with long flag", script: "rm --recursive --force ~/", want: []string{"bash.rm.recursive"}},
+		{name: "recursive removal escaping tmp", script: "rm -r /tmp/../etc", want: []string{"bash.rm.recursive"}},
+		{name: "privilege escalation", script: "sudo id", want: []string{"bash.command.privilege"}},
+		{name: "quoted command name", script: "'su''do' id", want: []string{"bash.command.privilege"}},
+		{name: "command by path", script: "/usr/bin/docker ps", want: []string{"bash.command.container"}},
+		{name: "command behind wrapper", script: "env FOO=1 nice -n 5 curl https://example.com", want: []string{"bash.command.network"}},
+		{name: "command substitution", script: "echo $(wget -qO- https://example.com)", want: []string{"bash.command.network"}},
+		{name: "backtick substitution", script: "echo `nc -l 4444`", want: []string{"bash.command.remote"}},
+		{name: "command in function", script: "f() {\n  ssh host\n}", want: []string{"bash.command.remote"}},
+		{name: "computed command", script: "cmd=sudo\n$cmd id", want: []string{"bash.command.dynamic"}},
+		{name: "eval", script: "eval \"$payload\"", want: []string{"bash.eval"}},
+		{name: "nested shell", script: "bash -c 'sudo id'", want: []string{"bash.nested_shell"}},
+		{name: "inline interpreter code", script: "python3 -c 'import os'", want: []string{"bash.nested_shell"}},
+		{name: "interpreter running a file", script: "python3 script.py"},
+		{name: "tcp redirection", script: "cat < /dev/tcp/10.0.0.1/80", want: []string{"bash.redirect.socket"}},
+		{name: "write outside working directories", script: "echo 'test' > /etc/hosts", want: []string{"bash.redirect.path"}},
+		{name: "sensitive path", script: "cat /etc/passwd | grep root", want: []string{"script.sensitive_path"}},
+		{name: "container socket", script: "ls -l \"/var/run/docker.sock\"", want: []string{"script.container_socket"}},
+		{name: "loader variable", script: "export LD_PRELOAD=/tmp/evil.so", want: []string{"bash.env.loader"}},
+		{name: "fork bomb", script: ":(){ :|:& };:", want: []string{"bash.fork_bomb"}},
+		{name: "miner", script: "./xmrig -o stratum+tcp://pool.example.com:3333", want: []string{"script.miner", "script.miner"}},
+		{name: "syntax error", script: "if then fi", want: []string{"bash.syntax"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ruleIDs(AnalyzeBash(tt.script)))
+		})
+	}
+}
+
+func TestAnalyzeBash_Position(t *testing.T) {
+	findings := AnalyzeBash("echo start\n\n  sudo id\n")
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, 3, findings[0].Line)
+		assert.Equal(t, 3, findings[0].Column)
+		assert.Equal(t, "use of sudo", findings[0].Message)
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"go/scanner"
+	"go/token"
+	"strconv"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// Go rules
+var (
+	ruleGoSystemImport  = newRule("go.import.system", models.FindingSeverityHigh, false)
+	ruleGoNetworkImport = newRule("go.import.network", models.FindingSeverityHigh, true)
+)
+
+// goPackages maps import paths to the rule importing them violates
+var goPackages = map[string]Rule{}
+
+func init() {
+	for rule, packages := range map[Rule][]string{
+		ruleGoSystemImport: {
+			"os/exec", "os/signal", "os/user", "syscall", "unsafe", "plugin", "C",
+			"golang.org/x/sys/unix", "runtime/debug",
+		},
+		ruleGoNetworkImport: {
+			"net", "net/http", "net/http/httputil", "net/rpc", "net/smtp", "net/mail",
+			"crypto/tls",
+		},
+	} {
+		for _, pkg := range packages {
+			goPackages[pkg] = rule
+		}
+	}
+}
+
+// AnalyzeGo tokenizes a Go program and reports dangerous imports
+func AnalyzeGo(script string) []models.ScriptFinding {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(script))
+
+	var s scanner.Scanner
+	// Syntax errors are left to the compiler
+	s.Init(file, []byte(script), nil, 0)
+
+	var findings []models.ScriptFinding
+	inImport, inImportGroup := false, false
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		switch tok {
+		case token.IMPORT:
+			inImport = true
+			continue
+		case token.LPAREN:
+			if inImport {
+				inImportGroup = true
+			}
+		case token.RPAREN:
+			inImport, inImportGroup = false, false
+		case token.STRING:
+			value, err := strconv.Unquote(lit)
+			if err != nil {
+				break
+			}
+			position := fset.Position(pos)
+			if rule, ok := goPackages[value]; ok && inImport {
+				findings = append(findings, rule.at(position.Line, position.Column, "import of %s", value))
+			}
+			findings = append(findings, checkLiteral(value, position.Line, position.Column)...)
+			if !inImportGroup {
+				inImport = false
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,418 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// JavaScript rules
+var (
+	ruleJSSystemModule     = newRule("js.module.system", models.FindingSeverityHigh, false)
+	ruleJSNetworkModule    = newRule("js.module.network", models.FindingSeverityHigh, true)
+	ruleJSRawNetworkModule = newRule("js.module.raw_network", models.FindingSeverityHigh, false)
+	ruleJSUnlistedModule   = newRule("js.module.unlisted", models.FindingSeverityMedium, false)
+	ruleJSDynamicRequire   = newRule("js.require.dynamic", models.FindingSeverityHigh, false)
+	ruleJSDynamicCode      = newRule("js.dynamic_code", models.FindingSeverityCritical, false)
+	ruleJSProcess          = newRule("js.process", models.FindingSeverityHigh, false)
+	ruleJSGlobal           = newRule("js.global", models.FindingSeverityMedium, false)
+	ruleJSPrototype        = newRule("js.prototype", models.FindingSeverityHigh, false)
+)
+
+// jsSafeModules are the Node.js modules scripts may load
+var jsSafeModules = map[string]bool{
+	"crypto": true, "util": true, "path": true, "url": true, "querystring": true,
+	"string_decoder": true, "buffer": true, "events": true, "stream": true,
+	"assert": true, "console": true, "timers": true,
+}
+
+// jsModules maps Node.js modules to the rule loading them violates
+var jsModules = map[string]Rule{}
+
+func init() {
+	for rule, modules := range map[Rule][]string{
+		ruleJSSystemModule: {
+			"fs", "child_process", "os", "process", "cluster", "worker_threads", "vm",
+			"module", "repl", "readline", "tty", "v8", "inspector", "wasi",
+		},
+		ruleJSNetworkModule:    {"http", "https", "http2", "net", "tls"},
+		ruleJSRawNetworkModule: {"dgram", "dns"},
+	} {
+		for _, module := range modules {
+			jsModules[module] = rule
+		}
+	}
+}
+
+// jsProcessMembers are the members of process that reach outside the script
+var jsProcessMembers = map[string]bool{
+	"env": true, "kill": true, "binding": true, "_linkedBinding": true, "dlopen": true,
+	"chdir": true, "setuid": true, "setgid": true, "setgroups": true, "mainModule": true,
+	"abort": true,
+}
+
+// jsTimers run a string argument as code
+var jsTimers = map[string]bool{"setTimeout": true, "setInterval": true, "setImmediate": true}
+
+// jsRegexKeywords are keywords after which a slash starts a regular expression
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true,
+	"instanceof": true, "new": true, "delete": true, "void": true, "throw": true,
+	"yield": true, "await": true,
+}
+
+// AnalyzeJavaScript tokenizes a JavaScript script and reports dangerous
+// modules, dynamic code execution and access to the process and globals
+func AnalyzeJavaScript(script string) []models.ScriptFinding {
+	tokens := tokenizeJavaScript(script)
+
+	var findings []models.ScriptFinding
+	for i, tok := range tokens {
+		if tok.kind == jsString {
+			findings = append(findings, checkLiteral(tok.value, tok.line, tok.column)...)
+			continue
+		}
+		if tok.kind != jsName {
+			continue
+		}
+
+		prev := jsTokenAt(tokens, i-1)
+		next := jsTokenAt(tokens, i+1)
+		afterDot := prev.is(jsPunct, ".") || prev.is(jsPunct, "?.")
+		if tok.value == "__proto__" || afterDot && tok.value == "constructor" {
+			findings = append(findings, ruleJSPrototype.at(tok.line, tok.column, "access to %s", tok.value))
+			continue
+		}
+		if afterDot {
+			continue
+		}
+
+		switch {
+		case (tok.value == "require" || tok.value == "import") && next.is(jsPunct, "("):
+			arg := jsTokenAt(tokens, i+2)
+			if arg.kind == jsString && !arg.dynamic && jsTokenAt(tokens, i+3).is(jsPunct, ")") {
+				findings = append(findings, checkJSModule(arg.value, arg)...)
+			} else {
+				findings = append(findings, ruleJSDynamicRequire.at(tok.line, tok.column, "module name is computed at run time"))
+			}
+
+		case tok.value == "import" && !next.is(jsPunct, "."), tok.value == "export":
+			if module, ok := jsStaticImport(tokens, i); ok {
+				findings = append(findings, checkJSModule(module.value, module)...)
+			}
+
+		case (tok.value == "eval" || tok.value == "Function") && next.is(jsPunct, "("):
+			findings = append(findings, ruleJSDynamicCode.at(tok.line, tok.column, "call to %s()", tok.value))
+
+		case jsTimers[tok.value] && next.is(jsPunct, "(") && jsTokenAt(tokens, i+2).kind == jsString:
+			findings = append(findings, ruleJSDynamicCode.at(tok.line, tok.column, "%s() with a string of code", tok.value))
+
+		case tok.value == "process" && next.is(jsPunct, "["):
+			findings = append(findings, ruleJSProcess.at(tok.line, tok.column, "computed access to process"))
+
+		case tok.value == "process" && next.is(jsPunct, ".") && jsProcessMembers[jsTokenAt(tokens, i+2).value]:
+			findings = append(findings, ruleJSProcess.at(tok.line, tok.column, "access to process.%s", jsTokenAt(tokens, i+2).value))
+
+		case tok.value == "global" || tok.value == "globalThis":
+			findings = append(findings, ruleJSGlobal.at(tok.line, tok.column, "access to %s", tok.value))
+		}
+	}
+
+	return findings
+}
+
+// jsStaticImport finds the module of an import or export statement starting
+// at tokens[i], if it names one
+func jsStaticImport(tokens []jsToken, i int) (jsToken, bool) {
+	for j := i + 1; j < len(tokens) && j <= i+64; j++ {
+		tok := tokens[j]
+		switch {
+		case tok.is(jsPunct, ";"):
+			return jsToken{}, false
+		case tok.kind == jsString:
+			// import "module" or ... from "module"
+			if j == i+1 && tokens[i].value == "import" || jsTokenAt(tokens, j-1).is(jsName, "from") {
+				return tok, true
+			}
+			return jsToken{}, false
+		}
+	}
+	return jsToken{}, false
+}
+
+// checkJSModule reports modules scripts may not load
+func checkJSModule(module string, tok jsToken) []models.ScriptFinding {
+	name := strings.TrimPrefix(module, "node:")
+	if !strings.HasPrefix(name, "@") {
+		name, _, _ = strings.Cut(name, "/")
+	}
+
+	if rule, ok := jsModules[name]; ok {
+		return []models.ScriptFinding{rule.at(tok.line, tok.column, "use of module %s", module)}
+	}
+	if !jsSafeModules[name] {
+		return []models.ScriptFinding{ruleJSUnlistedModule.at(tok.line, tok.column, "module %s is not in the list of allowed modules", module)}
+	}
+	return nil
+}
+
+type jsTokenKind int
+
+const (
+	jsName jsTokenKind = iota + 1
+	jsString
+	jsNumber
+	jsPunct
+	jsRegex
+)
+
+type jsToken struct {
+	kind   jsTokenKind
+	value  string
+	line   int
+	column int
+
+	// dynamic marks template literals with substitutions
+	dynamic bool
+}
+
+func (t jsToken) is(kind jsTokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+// jsTokenAt returns the token at index i, or the zero token when out of range
+func jsTokenAt(tokens []jsToken, i int) jsToken {
+	if i < 0 || i >= len(tokens) {
+		return jsToken{}
+	}
+	return tokens[i]
+}
+
+// jsScanner splits JavaScript source into tokens. Substitutions in template
+// literals are tokenized as code.
+type jsScanner struct {
+	src    string
+	pos    int
+	line   int
+	column int
+	tokens []jsToken
+}
+
+func tokenizeJavaScript(src string) []jsToken {
+	s := &jsScanner{src: src, line: 1, column: 1}
+	for s.pos < len(s.src) {
+		s.scan()
+	}
+	return s.tokens
+}
+
+func (s *jsScanner) peek(offset int) byte {
+	if s.pos+offset >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos+offset]
+}
+
+func (s *jsScanner) advance() rune {
+	r, size := utf8.DecodeRuneInString(s.src[s.pos:])
+	s.pos += size
+	if r == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column += size
+	}
+	return r
+}
+
+func (s *jsScanner) emit(tok jsToken) {
+	s.tokens = append(s.tokens, tok)
+}
+
+// scan scans the next token, skipping whitespace and comments
+func (s *jsScanner) scan() {
+	line, column := s.line, s.column
+	c := s.peek(0)
+
+	switch {
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+		s.advance()
+	case c == '/' && s.peek(1) == '/':
+		for s.pos < len(s.src) && s.peek(0) != '\n' {
+			s.advance()
+		}
+	case c == '/' && s.peek(1) == '*':
+		s.advance()
+		s.advance()
+		for s.pos < len(s.src) && !(s.peek(0) == '*' && s.peek(1) == '/') {
+			s.advance()
+		}
+		s.advance()
+		s.advance()
+	case c == '/' && s.regexAllowed():
+		s.scanRegex(line, column)
+	case c == '\'' || c == '"':
+		s.scanString(line, column)
+	case c == '`':
+		s.scanTemplate(line, column)
+	case c >= '0' && c <= '9':
+		start := s.pos
+		for s.pos < len(s.src) && (isJSIdentRune(rune(s.peek(0))) || s.peek(0) == '.') {
+			s.advance()
+		}
+		s.emit(jsToken{kind: jsNumber, value: s.src[start:s.pos], line: line, column: column})
+	default:
+		r, _ := utf8.DecodeRuneInString(s.src[s.pos:])
+		if !isJSIdentRune(r) {
+			s.advance()
+			value := string(r)
+			if r == '?' && s.peek(0) == '.' {
+				s.advance()
+				value = "?."
+			}
+			s.emit(jsToken{kind: jsPunct, value: value, line: line, column: column})
+			return
+		}
+
+		start := s.pos
+		for s.pos < len(s.src) {
+			r, _ := utf8.DecodeRuneInString(s.src[s.pos:])
+			if !isJSIdentRune(r) {
+				break
+			}
+			s.advance()
+		}
+		s.emit(jsToken{kind: jsName, value: s.src[start:s.pos], line: line, column: column})
+	}
+}
+
+// regexAllowed reports whether a slash at the current position starts a
+// regular expression rather than a division
+func (s *jsScanner) regexAllowed() bool {
+	if len(s.tokens) == 0 {
+		return true
+	}
+	prev := s.tokens[len(s.tokens)-1]
+	switch prev.kind {
+	case jsName:
+		return jsRegexKeywords[prev.value]
+	case jsPunct:
+		return prev.value != ")" && prev.value != "]" && prev.value != "}"
+	default:
+		return false
+	}
+}
+
+func (s *jsScanner) scanRegex(line, column int) {
+	start := s.pos
+	s.advance()
+	inClass := false
+	for s.pos < len(s.src) {
+		c := s.peek(0)
+		if c == '\n' {
+			break
+		}
+		s.advance()
+		switch {
+		case c == '\\':
+			if s.pos < len(s.src) {
+				s.advance()
+			}
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			for s.pos < len(s.src) && isJSIdentRune(rune(s.peek(0))) {
+				s.advance()
+			}
+			s.emit(jsToken{kind: jsRegex, value: s.src[start:s.pos], line: line, column: column})
+			return
+		}
+	}
+	s.emit(jsToken{kind: jsRegex, value: s.src[start:s.pos], line: line, column: column})
+}
+
+func (s *jsScanner) scanString(line, column int) {
+	quote := s.peek(0)
+	s.advance()
+
+	var b strings.Builder
+	for s.pos < len(s.src) {
+		c := s.peek(0)
+		if c == quote || c == '\n' {
+			break
+		}
+		if c == '\\' {
+			s.advance()
+			if s.pos >= len(s.src) {
+				break
+			}
+		}
+		b.WriteRune(s.advance())
+	}
+	if s.peek(0) == quote {
+		s.advance()
+	}
+	s.emit(jsToken{kind: jsString, value: b.String(), line: line, column: column})
+}
+
+// scanTemplate scans a template literal. Its substitutions are tokenized in
+// place; the literal itself is emitted after them, marked as dynamic when it
+// has any.
+func (s *jsScanner) scanTemplate(line, column int) {
+	s.advance()
+
+	var b strings.Builder
+	dynamic := false
+	for s.pos < len(s.src) {
+		c := s.peek(0)
+		switch {
+		case c == '`':
+			s.advance()
+			s.emit(jsToken{kind: jsString, value: b.String(), line: line, column: column, dynamic: dynamic})
+			return
+		case c == '\\':
+			s.advance()
+			if s.pos < len(s.src) {
+				b.WriteRune(s.advance())
+			}
+		case c == '$' && s.peek(1) == '{':
+			dynamic = true
+			s.advance()
+			s.advance()
+			s.scanSubstitution()
+		default:
+			b.WriteRune(s.advance())
+		}
+	}
+	s.emit(jsToken{kind: jsString, value: b.String(), line: line, column: column, dynamic: dynamic})
+}
+
+// scanSubstitution tokenizes a template substitution up to its closing brace
+func (s *jsScanner) scanSubstitution() {
+	depth := 0
+	for s.pos < len(s.src) {
+		if s.peek(0) == '}' && depth == 0 {
+			s.advance()
+			return
+		}
+
+		count := len(s.tokens)
+		s.scan()
+		if len(s.tokens) > count {
+			switch last := s.tokens[len(s.tokens)-1]; {
+			case last.is(jsPunct, "{"):
+				depth++
+			case last.is(jsPunct, "}"):
+				depth--
+			}
+		}
+	}
+}
+
+func isJSIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeJavaScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "console.log", script: "console.log('Hello, World!');"},
+		{name: "safe require", script: "const crypto = require('crypto');\nconst { join } = require(\"node:path\");"},
+		{name: "dangerous words in strings", script: "console.log(\"require('fs') and eval(x)\");"},
+		{name: "dangerous words in comments", script: "// require('fs')\n/* eval(x) */\nconsole.log(1);"},
+		{name: "division and regex", script: "const x = a / b / c;\nconst re = /require\\('fs'\\)/g;"},
+		{name: "class constructor", script: "class A {\n  constructor() { this.x = 1; }\n}"},
+		{name: "timer with function", script: "setTimeout(() => console.log(1), 10);"},
+		{name: "process exit", script: "process.exit(1);"},
+		{name: "property named process", script: "job.process.env = {};"},
+		{name: "require fs", script: "const fs = require('fs');", want: []string{"js.module.system"}},
+		{name: "require with node prefix", script: "const cp = require('node:child_process');", want: []string{"js.module.system"}},
+		{name: "require submodule", script: "const fsp = require('fs/promises');", want: []string{"js.module.system"}},
+		{name: "require template literal", script: "const fs = require(`fs`);", want: []string{"js.module.system"}},
+		{name: "static import", script: "import fs from 'fs';", want: []string{"js.module.system"}},
+		{name: "side effect import", script: "import 'child_process';", want: []string{"js.module.system"}},
+		{name: "re-export", script: "export { readFile } from \"fs\";", want: []string{"js.module.system"}},
+		{name: "dynamic import", script: "const os = await import('os');", want: []string{"js.module.system"}},
+		{name: "network module", script: "const https = require('https');", want: []string{"js.module.network"}},
+		{name: "raw network module", script: "const dns = require('dns');", want: []string{"js.module.raw_network"}},
+		{name: "unlisted module", script: "const _ = require('lodash');", want: []string{"js.module.unlisted"}},
+		{name: "computed require", script: "const m = require('f' + 's');", want: []string{"js.require.dynamic"}},
+		{name: "template substitution require", script: "const m = require(`${name}`);", want: []string{"js.require.dynamic"}},
+		{name: "eval", script: "eval('1 + 1');", want: []string{"js.dynamic_code"}},
+		{name: "function constructor", script: "new Function('return 1')();", want: []string{"js.dynamic_code"}},
+		{name: "eval in template substitution", script: "console.log(`${eval('1')}`);", want: []string{"js.dynamic_code"}},
+		{name: "timer with string", script: "setInterval('tick()', 10);", want: []string{"js.dynamic_code"}},
+		{name: "process env", script: "console.log(process.env.HOME);", want: []string{"js.process"}},
+		{name: "computed process access", script: "process['env'];", want: []string{"js.process"}},
+		{name: "global", script: "console.log(global.process);", want: []string{"js.global"}},
+		{name: "constructor escape", script: "this.constructor.constructor('return this')();", want: []string{"js.prototype", "js.prototype"}},
+		{name: "proto", script: "obj.__proto__.polluted = true;", want: []string{"js.prototype"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ruleIDs(AnalyzeJavaScript(tt.script)))
+		})
+	}
+}
+
+func TestAnalyzeJavaScript_Position(t *testing.T) {
+	findings := AnalyzeJavaScript("const a = `line\nbreak`;\n  const fs = require('fs');")
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, 3, findings[0].Line)
+		assert.Equal(t, 22, findings[0].Column)
+		assert.Equal(t, "use of module fs", findings[0].Message)
+	}
+}
@@ -0,0 +1,376 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// Python rules
+var (
+	rulePythonSystemImport  = newRule("python.import.system", models.FindingSeverityHigh, false)
+	rulePythonNetworkImport = newRule("python.import.network", models.FindingSeverityHigh, true)
+	rulePythonDynamicImport = newRule("python.import.dynamic", models.FindingSeverityHigh, false)
+	rulePythonDynamicCode   = newRule("python.dynamic_code", models.FindingSeverityCritical, false)
+	rulePythonReflection    = newRule("python.reflection", models.FindingSeverityHigh, false)
+	rulePythonIntrospection = newRule("python.introspection", models.FindingSeverityHigh, false)
+)
+
+// pythonModules maps top-level modules to the rule importing them violates
+var pythonModules = map[string]Rule{}
+
+// pythonCalls maps builtins to the rule calling them violates
+var pythonCalls = map[string]Rule{}
+
+func init() {
+	for rule, modules := range map[Rule][]string{
+		rulePythonSystemImport: {
+			"os", "subprocess", "sys", "shutil", "pty", "ctypes", "cffi", "multiprocessing",
+			"signal", "resource", "posix", "pwd", "grp", "fcntl",
+		},
+		rulePythonNetworkImport: {
+			"socket", "ssl", "urllib", "urllib3", "requests", "http", "httpx", "aiohttp",
+			"smtplib", "ftplib", "poplib", "imaplib", "telnetlib", "xmlrpc",
+		},
+		rulePythonDynamicImport: {"importlib", "builtins", "__builtin__", "marshal", "runpy", "imp", "code", "codeop"},
+	} {
+		for _, module := range modules {
+			pythonModules[module] = rule
+		}
+	}
+
+	for rule, calls := range map[Rule][]string{
+		rulePythonDynamicCode: {"eval", "exec", "compile", "__import__"},
+		rulePythonReflection:  {"getattr", "setattr", "delattr", "globals", "locals", "vars"},
+	} {
+		for _, call := range calls {
+			pythonCalls[call] = rule
+		}
+	}
+}
+
+// pythonDunders are attributes used to escape restricted namespaces
+var pythonDunders = map[string]bool{
+	"__builtins__": true, "__subclasses__": true, "__globals__": true, "__code__": true,
+	"__bases__": true, "__mro__": true, "__closure__": true, "__getattribute__": true,
+}
+
+// AnalyzePython tokenizes a Python script and reports dangerous imports and
+// calls. String literals and comments are not mistaken for code.
+func AnalyzePython(script string) []models.ScriptFinding {
+	tokens := tokenizePython(script, 1, 1)
+
+	var findings []models.ScriptFinding
+	skipImport := make(map[int]bool)
+
+	for i, tok := range tokens {
+		if tok.kind == pyString {
+			findings = append(findings, checkLiteral(tok.value, tok.line, tok.column)...)
+			continue
+		}
+		if tok.kind != pyName {
+			continue
+		}
+
+		prev := tokenAt(tokens, i-1)
+		next := tokenAt(tokens, i+1)
+		afterDot := prev.is(pyOp, ".")
+
+		switch {
+		case tok.value == "from" && !afterDot:
+			module, end := pythonDottedName(tokens, i+1)
+			if module != "" && tokenAt(tokens, end).is(pyName, "import") {
+				skipImport[end] = true
+				findings = append(findings, checkPythonImport(module, tok)...)
+			}
+
+		case tok.value == "import" && !afterDot && !skipImport[i]:
+			for j := i + 1; ; {
+				module, end := pythonDottedName(tokens, j)
+				if module == "" {
+					break
+				}
+				findings = append(findings, checkPythonImport(module, tokens[j])...)
+				if tokenAt(tokens, end).is(pyName, "as") {
+					end += 2
+				}
+				if !tokenAt(tokens, end).is(pyOp, ",") {
+					break
+				}
+				j = end + 1
+			}
+
+		case pythonDunders[tok.value]:
+			findings = append(findings, rulePythonIntrospection.at(tok.line, tok.column, "access to %s", tok.value))
+
+		case next.is(pyOp, "(") && !afterDot && !prev.is(pyName, "def") && !prev.is(pyName, "class"):
+			if rule, ok := pythonCalls[tok.value]; ok {
+				findings = append(findings, rule.at(tok.line, tok.column, "call to %s()", tok.value))
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkPythonImport reports imports of blocked modules
+func checkPythonImport(module string, tok pyToken) []models.ScriptFinding {
+	// Relative imports can only refer to the script's own modules
+	if strings.HasPrefix(module, ".") {
+		return nil
+	}
+
+	root, _, _ := strings.Cut(module, ".")
+	if rule, ok := pythonModules[root]; ok {
+		return []models.ScriptFinding{rule.at(tok.line, tok.column, "import of %s", module)}
+	}
+	return nil
+}
+
+// pythonDottedName reads a (possibly relative) dotted module name starting at
+// tokens[i] and returns it with the index of the token following it
+func pythonDottedName(tokens []pyToken, i int) (string, int) {
+	var b strings.Builder
+	for ; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.is(pyOp, "."):
+			b.WriteString(".")
+		case tok.kind == pyName && tok.value != "import" && (b.Len() == 0 || strings.HasSuffix(b.String(), ".")):
+			b.WriteString(tok.value)
+		default:
+			return b.String(), i
+		}
+	}
+	return b.String(), i
+}
+
+type pyTokenKind int
+
+const (
+	pyName pyTokenKind = iota + 1
+	pyString
+	pyNumber
+	pyOp
+	pyNewline
+)
+
+type pyToken struct {
+	kind   pyTokenKind
+	value  string
+	line   int
+	column int
+}
+
+func (t pyToken) is(kind pyTokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+// tokenAt returns the token at index i, or the zero token when out of range
+func tokenAt(tokens []pyToken, i int) pyToken {
+	if i < 0 || i >= len(tokens) {
+		return pyToken{}
+	}
+	return tokens[i]
+}
+
+// pyScanner splits Python source into tokens. It only distinguishes what the
+// analyzer needs: names, string literals, numbers, operators and logical
+// line ends.
+type pyScanner struct {
+	src    string
+	pos    int
+	line   int
+	column int
+	depth  int
+	tokens []pyToken
+}
+
+// tokenizePython tokenizes src, numbering lines and columns from the given
+// position so f-string expressions can be tokenized in place
+func tokenizePython(src string, line, column int) []pyToken {
+	s := &pyScanner{src: src, line: line, column: column}
+	for s.pos < len(s.src) {
+		s.scan()
+	}
+	return s.tokens
+}
+
+func (s *pyScanner) peek(offset int) byte {
+	if s.pos+offset >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos+offset]
+}
+
+func (s *pyScanner) advance() rune {
+	r, size := utf8.DecodeRuneInString(s.src[s.pos:])
+	s.pos += size
+	if r == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column += size
+	}
+	return r
+}
+
+func (s *pyScanner) emit(kind pyTokenKind, value string, line, column int) {
+	s.tokens = append(s.tokens, pyToken{kind: kind, value: value, line: line, column: column})
+}
+
+func (s *pyScanner) scan() {
+	line, column := s.line, s.column
+	c := s.peek(0)
+
+	switch {
+	case c == '\n':
+		s.advance()
+		if s.depth == 0 {
+			s.emit(pyNewline, "", line, column)
+		}
+	case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+		s.advance()
+	case c == '\\' && (s.peek(1) == '\n' || s.peek(1) == '\r'):
+		// Explicit line continuation
+		s.advance()
+		s.advance()
+	case c == '#':
+		for s.pos < len(s.src) && s.peek(0) != '\n' {
+			s.advance()
+		}
+	case c == '\'' || c == '"':
+		s.scanString("", line, column)
+	case c >= '0' && c <= '9' || c == '.' && s.peek(1) >= '0' && s.peek(1) <= '9':
+		start := s.pos
+		for s.pos < len(s.src) && (isPyIdentRune(rune(s.peek(0))) || s.peek(0) == '.') {
+			s.advance()
+		}
+		s.emit(pyNumber, s.src[start:s.pos], line, column)
+	default:
+		r, _ := utf8.DecodeRuneInString(s.src[s.pos:])
+		if !isPyIdentRune(r) {
+			s.advance()
+			switch r {
+			case '(', '[', '{':
+				s.depth++
+			case ')', ']', '}':
+				if s.depth > 0 {
+					s.depth--
+				}
+			}
+			s.emit(pyOp, string(r), line, column)
+			return
+		}
+
+		start := s.pos
+		for s.pos < len(s.src) {
+			r, _ := utf8.DecodeRuneInString(s.src[s.pos:])
+			if !isPyIdentRune(r) {
+				break
+			}
+			s.advance()
+		}
+		name := s.src[start:s.pos]
+		if (s.peek(0) == '\'' || s.peek(0) == '"') && isPyStringPrefix(name) {
+			s.scanString(strings.ToLower(name), line, column)
+			return
+		}
+		s.emit(pyName, name, line, column)
+	}
+}
+
+// scanString scans a string literal starting at its opening quote
+func (s *pyScanner) scanString(prefix string, line, column int) {
+	quote := s.peek(0)
+	triple := s.peek(1) == quote && s.peek(2) == quote
+	delimiter := 1
+	if triple {
+		delimiter = 3
+	}
+	for i := 0; i < delimiter; i++ {
+		s.advance()
+	}
+
+	formatted := strings.Contains(prefix, "f")
+	var b strings.Builder
+	for s.pos < len(s.src) {
+		c := s.peek(0)
+		switch {
+		case c == quote && (!triple || s.peek(1) == quote && s.peek(2) == quote):
+			for i := 0; i < delimiter; i++ {
+				s.advance()
+			}
+			s.emit(pyString, b.String(), line, column)
+			return
+		case c == '\n' && !triple:
+			// Unterminated string
+			s.emit(pyString, b.String(), line, column)
+			return
+		case c == '\\':
+			s.advance()
+			if s.pos < len(s.src) {
+				b.WriteRune(s.advance())
+			}
+		case formatted && c == '{' && s.peek(1) == '{':
+			s.advance()
+			s.advance()
+			b.WriteByte('{')
+		case formatted && c == '{':
+			s.scanFormatExpression(quote)
+		default:
+			b.WriteRune(s.advance())
+		}
+	}
+	s.emit(pyString, b.String(), line, column)
+}
+
+// scanFormatExpression tokenizes a replacement field of an f-string, which
+// holds code like any other expression
+func (s *pyScanner) scanFormatExpression(quote byte) {
+	s.advance()
+	line, column := s.line, s.column
+	start := s.pos
+
+	depth := 0
+	for s.pos < len(s.src) {
+		c := s.peek(0)
+		if c == '}' && depth == 0 || c == quote {
+			break
+		}
+		switch c {
+		case '{', '(', '[':
+			depth++
+		case '}', ')', ']':
+			depth--
+		}
+		s.advance()
+	}
+
+	for _, tok := range tokenizePython(s.src[start:s.pos], line, column) {
+		if tok.kind != pyNewline {
+			s.tokens = append(s.tokens, tok)
+		}
+	}
+	if s.peek(0) == '}' {
+		s.advance()
+	}
+}
+
+func isPyIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isPyStringPrefix(name string) bool {
+	if len(name) > 2 {
+		return false
+	}
+	for _, r := range strings.ToLower(name) {
+		if !strings.ContainsRune("rbuf", r) {
+			return false
+		}
+	}
+	return true
+}
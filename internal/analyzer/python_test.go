@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzePython(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "print", script: "print('Hello, World!')"},
+		{name: "safe imports", script: "import math\nfrom datetime import datetime\nimport json as j, re"},
+		{name: "dangerous words in strings", script: "print('import os; eval(x)')\ns = \"\"\"\nimport subprocess\n\"\"\""},
+		{name: "dangerous words in comments", script: "# import os\nx = 1  # eval(x)"},
+		{name: "method named like a builtin", script: "import re\npattern = re.compile('a+')"},
+		{name: "function named like a builtin", script: "def compile(source):\n    return source"},
+		{name: "main guard", script: "if __name__ == '__main__':\n    print(__file__)"},
+		{name: "relative import", script: "from . import helpers"},
+		{name: "raise from", script: "try:\n    pass\nexcept ValueError as e:\n    raise RuntimeError() from e"},
+		{name: "import os", script: "import os\nos.system('ls')", want: []string{"python.import.system"}},
+		{name: "submodule import", script: "import os.path", want: []string{"python.import.system"}},
+		{name: "from import", script: "from subprocess import run", want: []string{"python.import.system"}},
+		{name: "import list", script: "import json, sys as s", want: []string{"python.import.system"}},
+		{name: "indented import", script: "def f():\n    import ctypes", want: []string{"python.import.system"}},
+		{name: "import after semicolon", script: "x = 1; import shutil", want: []string{"python.import.system"}},
+		{name: "network import", script: "import urllib.request", want: []string{"python.import.network"}},
+		{name: "dynamic import", script: "import importlib", want: []string{"python.import.dynamic"}},
+		{name: "eval", script: "eval('1 + 1')", want: []string{"python.dynamic_code"}},
+		{name: "exec with spacing", script: "exec ('print(1)')", want: []string{"python.dynamic_code"}},
+		{name: "__import__", script: "__import__('os')", want: []string{"python.dynamic_code"}},
+		{name: "eval in f-string", script: "print(f\"{eval('1')}\")", want: []string{"python.dynamic_code"}},
+		{name: "escaped braces in f-string", script: "print(f\"{{eval('1')}}\")"},
+		{name: "reflection", script: "print(globals())\ngetattr(obj, 'x')", want: []string{"python.reflection", "python.reflection"}},
+		{name: "subclass walk", script: "().__class__.__bases__[0].__subclasses__()", want: []string{"python.introspection", "python.introspection"}},
+		{name: "sensitive path", script: "open('/etc/shadow').read()", want: []string{"script.sensitive_path"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ruleIDs(AnalyzePython(tt.script)))
+		})
+	}
+}
+
+func TestAnalyzePython_Position(t *testing.T) {
+	findings := AnalyzePython("x = '''\nmultiline\n'''\nif x:\n    import os\n")
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, 5, findings[0].Line)
+		assert.Equal(t, 12, findings[0].Column)
+		assert.Equal(t, "import of os", findings[0].Message)
+	}
+}
@@ -0,0 +1,543 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
+	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
+)
+
+// MaxBatchRequestBytes is the maximum size of a batch of task operations
+const MaxBatchRequestBytes = 8 * 1024 * 1024
+
+// BulkJobServiceInterface defines the interface for the bulk job service
+type BulkJobServiceInterface interface {
+	Submit(ctx context.Context, job *models.BulkJob, operations []models.BulkOperation, perform func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult) error
+}
+
+// BulkHandler handles bulk operations on tasks and the bulk jobs performing them
+type BulkHandler struct {
+	jobRepo          database.BulkJobRepository
+	jobService       BulkJobServiceInterface
+	taskRepo         database.TaskRepository
+	taskService      TaskServiceInterface
+	executionRepo    database.TaskExecutionRepository
+	executionService TaskExecutionServiceInterface
+	logger           *slog.Logger
+}
+
+// NewBulkHandler creates a new bulk handler. Operations are performed by the
+// task and execution services, the way they are performed one by one.
+func NewBulkHandler(jobRepo database.BulkJobRepository, jobService BulkJobServiceInterface, taskRepo database.TaskRepository, taskService TaskServiceInterface, executionRepo database.TaskExecutionRepository, executionService TaskExecutionServiceInterface, logger *slog.Logger) *BulkHandler {
+	return &BulkHandler{
+		jobRepo:          jobRepo,
+		jobService:       jobService,
+		taskRepo:         taskRepo,
+		taskService:      taskService,
+		executionRepo:    executionRepo,
+		executionService: executionService,
+		logger:           logger,
+	}
+}
+
+// Batch handles performing a list of task operations
+//
+//	@Summary		Batch task operations
+//	@Description	Creates, updates, deletes or executes up to 1000 tasks in a background job. Each operation is checked and performed as through its own endpoint, and its outcome recorded in the job; failed operations don't stop the others.
+//	@Tags			Bulk Jobs
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.BatchTaskRequest	true	"Task operations"
+//	@Success		202		{object}	models.BulkJobResponse	"Job accepted"
+//	@Failure		400		{object}	models.ErrorResponse	"Invalid operations"
+//	@Failure		401		{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		413		{object}	models.ErrorResponse	"Request too large"
+//	@Failure		429		{object}	models.ErrorResponse	"Rate limit exceeded"
+//	@Router			/tasks:batch [post]
+func (h *BulkHandler) Batch(c *gin.Context) {
+	// Get validated request from middleware
+	validatedBody, exists := c.Get("validated_body")
+	if !exists {
+		// Fallback to manual validation if middleware wasn't used
+		var req models.BatchTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn("invalid batch request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request format",
+				"details": err.Error(),
+			})
+			return
+		}
+		validatedBody = &req
+	}
+
+	req := *validatedBody.(*models.BatchTaskRequest)
+
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.logger.Warn("batch validation failed", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	job := &models.BulkJob{
+		UserID: user.ID,
+		Kind:   models.BulkJobKindBatch,
+	}
+	h.submit(c, user, job, req.Operations)
+}
+
+// Bulk handles performing an action on every task matching a filter
+//
+//	@Summary		Bulk task action
+//	@Description	Deletes, executes, or cancels the pending or running execution of, every task matching the filters, in a background job. The filters are those of the task listing; at least one is required, and they may match at most 1000 tasks.
+//	@Tags			Bulk Jobs
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status			query		string							false	"Comma-separated task statuses"
+//	@Param			script_type		query		string							false	"Comma-separated script types"
+//	@Param			priority_min	query		int								false	"Minimum priority"
+//	@Param			priority_max	query		int								false	"Maximum priority"
+//	@Param			created_after	query		string							false	"Only tasks created at or after this RFC 3339 time"
+//	@Param			created_before	query		string							false	"Only tasks created before this RFC 3339 time"
+//	@Param			updated_after	query		string							false	"Only tasks updated at or after this RFC 3339 time"
+//	@Param			updated_before	query		string							false	"Only tasks updated before this RFC 3339 time"
+//	@Param			metadata		query		string							false	"JSON object the task metadata must contain"
+//	@Param			q				query		string							false	"Full-text search on the task name"
+//	@Param			selector		query		string							false	"Label selector, e.g. env=prod,team in (data,ml),!deprecated"
+//	@Param			request			body		models.BulkTaskActionRequest	true	"Action to perform"
+//	@Success		202				{object}	models.BulkJobResponse			"Job accepted"
+//	@Failure		400				{object}	models.ErrorResponse			"Invalid action or filters, or too many matching tasks"
+//	@Failure		401				{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		429				{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/tasks:bulk [post]
+func (h *BulkHandler) Bulk(c *gin.Context) {
+	// Get validated request from middleware
+	validatedBody, exists := c.Get("validated_body")
+	if !exists {
+		// Fallback to manual validation if middleware wasn't used
+		var req models.BulkTaskActionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn("invalid bulk action request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request format",
+				"details": err.Error(),
+			})
+			return
+		}
+		validatedBody = &req
+	}
+
+	req := *validatedBody.(*models.BulkTaskActionRequest)
+
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	switch req.Action {
+	case models.BulkActionDelete, models.BulkActionExecute, models.BulkActionCancel:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid action: must be delete, execute or cancel",
+		})
+		return
+	}
+
+	filter, filtered, err := parseTaskFilter(c)
+	if err != nil {
+		h.logger.Warn("invalid bulk action filter", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	// An action on every task of the user is too easy to trigger by mistake
+	if !filtered {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "At least one filter is required",
+		})
+		return
+	}
+
+	filter.UserID = &user.ID
+	taskIDs, err := h.matchTasks(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to match tasks for bulk action", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to match tasks",
+		})
+		return
+	}
+	if len(taskIDs) > models.MaxBulkJobItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "The filters match more than 1000 tasks",
+		})
+		return
+	}
+
+	operations := make([]models.BulkOperation, len(taskIDs))
+	for i := range taskIDs {
+		operations[i] = models.BulkOperation{Action: req.Action, TaskID: &taskIDs[i]}
+	}
+
+	action := req.Action
+	query := c.Request.URL.RawQuery
+	job := &models.BulkJob{
+		UserID: user.ID,
+		Kind:   models.BulkJobKindBulk,
+		Action: &action,
+		Filter: &query,
+	}
+	h.submit(c, user, job, operations)
+}
+
+// matchTasks returns the IDs of the tasks matching the filter, oldest first.
+// It stops after one more than the tasks a job may operate on.
+func (h *BulkHandler) matchTasks(ctx context.Context, filter database.TaskFilter) ([]uuid.UUID, error) {
+	req := database.CursorPaginationRequest{Limit: 100, SortOrder: "asc", SortField: "created_at"}
+
+	var taskIDs []uuid.UUID
+	for len(taskIDs) <= models.MaxBulkJobItems {
+		tasks, page, err := h.taskRepo.Search(ctx, filter, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		if !page.HasMore || page.NextCursor == nil {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+	return taskIDs, nil
+}
+
+// submit submits the job and writes the accepted job as the response
+func (h *BulkHandler) submit(c *gin.Context, user *models.User, job *models.BulkJob, operations []models.BulkOperation) {
+	perform := func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult {
+		return h.perform(ctx, user, operation)
+	}
+
+	if err := h.jobService.Submit(c.Request.Context(), job, operations, perform); err != nil {
+		h.logger.Error("failed to submit bulk job", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to start bulk job",
+		})
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+job.ID.String())
+	c.JSON(http.StatusAccepted, job.ToResponse())
+}
+
+// GetJob handles retrieving a bulk job with the results so far
+//
+//	@Summary		Get bulk job
+//	@Description	Retrieves the status and progress of a bulk job, with the outcome of each operation performed so far
+//	@Tags			Bulk Jobs
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string					true	"Job ID"
+//	@Success		200	{object}	models.BulkJobResponse	"Job retrieved successfully"
+//	@Failure		400	{object}	models.ErrorResponse	"Invalid job ID"
+//	@Failure		401	{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		403	{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404	{object}	models.ErrorResponse	"Job not found"
+//	@Failure		429	{object}	models.ErrorResponse	"Rate limit exceeded"
+//	@Router			/jobs/{id} [get]
+func (h *BulkHandler) GetJob(c *gin.Context) {
+	jobIDStr := c.Param("id")
+	jobID, err := uuid.Parse(jobIDStr)
+	if err != nil {
+		h.logger.Warn("invalid job ID", "job_id", jobIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid job ID format",
+		})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(c.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, database.ErrBulkJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Job not found",
+			})
+			return
+		}
+		h.logger.Error("failed to get bulk job", "error", err, "job_id", jobID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve job",
+		})
+		return
+	}
+
+	if job.UserID != user.ID {
+		h.logger.Warn("user attempted to access another user's bulk job",
+			"user_id", user.ID, "job_id", jobID, "job_owner_id", job.UserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job.ToResponse())
+}
+
+// ListJobs handles listing the user's bulk jobs
+//
+//	@Summary		List bulk jobs
+//	@Description	Lists the user's bulk jobs, newest first, without the outcomes of their operations
+//	@Tags			Bulk Jobs
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit	query		int							false	"Number of jobs to return (max 100)"	default(20)
+//	@Param			offset	query		int							false	"Number of jobs to skip"				default(0)
+//	@Success		200		{object}	models.BulkJobListResponse	"Jobs retrieved successfully"
+//	@Failure		400		{object}	models.ErrorResponse		"Invalid pagination parameters"
+//	@Failure		401		{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		429		{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Router			/jobs [get]
+func (h *BulkHandler) ListJobs(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	jobs, err := h.jobRepo.GetByUserID(c.Request.Context(), user.ID, limit, offset)
+	if err != nil {
+		h.logger.Error("failed to get bulk jobs", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve jobs",
+		})
+		return
+	}
+
+	total, err := h.jobRepo.CountByUserID(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to count bulk jobs", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve jobs",
+		})
+		return
+	}
+
+	responses := make([]models.BulkJobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = job.ToResponse()
+		responses[i].Results = nil
+	}
+
+	c.JSON(http.StatusOK, models.BulkJobListResponse{
+		Jobs:   responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// perform performs a single operation of a bulk job on behalf of the user
+func (h *BulkHandler) perform(ctx context.Context, user *models.User, operation models.BulkOperation) models.BulkOperationResult {
+	var result models.BulkOperationResult
+	var err error
+	switch operation.Action {
+	case models.BulkActionCreate:
+		result.TaskID, err = h.createTask(ctx, user, *operation.Task)
+	case models.BulkActionUpdate:
+		err = h.updateTask(ctx, user, *operation.TaskID, *operation.Changes)
+	case models.BulkActionDelete:
+		err = h.deleteTask(ctx, user, *operation.TaskID)
+	case models.BulkActionExecute:
+		result.ExecutionID, err = h.executeTask(ctx, user, *operation.TaskID)
+	case models.BulkActionCancel:
+		result.ExecutionID, err = h.cancelExecution(ctx, user, *operation.TaskID)
+	default:
+		err = errors.New("invalid action")
+	}
+
+	if err != nil {
+		result.Status = models.BulkOperationStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = models.BulkOperationStatusSucceeded
+	return result
+}
+
+// createTask creates a task through the task service, as the create
+// endpoint does
+func (h *BulkHandler) createTask(ctx context.Context, user *models.User, req models.CreateTaskRequest) (*uuid.UUID, error) {
+	task, _, err := h.taskService.CreateTask(ctx, user, req)
+	if err != nil {
+		return nil, h.taskError(err, "failed to create task")
+	}
+	return &task.ID, nil
+}
+
+// updateTask updates a task through the task service, as the update endpoint
+// does
+func (h *BulkHandler) updateTask(ctx context.Context, user *models.User, taskID uuid.UUID, req models.UpdateTaskRequest) error {
+	task, err := h.ownedTask(ctx, user, taskID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.taskService.UpdateTask(ctx, user, task, req); err != nil {
+		return h.taskError(err, "failed to update task")
+	}
+	return nil
+}
+
+// deleteTask deletes a task through the task service, as the delete endpoint
+// does. The task is only deleted if it hasn't changed since it was read.
+func (h *BulkHandler) deleteTask(ctx context.Context, user *models.User, taskID uuid.UUID) error {
+	task, err := h.ownedTask(ctx, user, taskID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.taskService.DeleteTask(ctx, user, task); err != nil {
+		return h.taskError(err, "failed to delete task")
+	}
+	return nil
+}
+
+// executeTask admits the task and starts an execution through the execution
+// service, as the execution endpoint does
+func (h *BulkHandler) executeTask(ctx context.Context, user *models.User, taskID uuid.UUID) (*uuid.UUID, error) {
+	task, err := h.ownedTask(ctx, user, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.taskService.AdmitTask(user, task); err != nil {
+		return nil, err
+	}
+
+	execution, err := h.executionService.CreateExecutionAndUpdateTaskStatus(ctx, taskID, user.ID)
+	if err != nil {
+		return nil, h.serviceError(err, "failed to create task execution", "cannot execute task with status:")
+	}
+
+	h.logger.Info("task execution created successfully", "execution_id", execution.ID, "task_id", taskID, "user_id", user.ID)
+	return &execution.ID, nil
+}
+
+// cancelExecution cancels the task's latest execution through the execution
+// service, when it is pending or running
+func (h *BulkHandler) cancelExecution(ctx context.Context, user *models.User, taskID uuid.UUID) (*uuid.UUID, error) {
+	if _, err := h.ownedTask(ctx, user, taskID); err != nil {
+		return nil, err
+	}
+
+	execution, err := h.executionRepo.GetLatestByTaskID(ctx, taskID)
+	if err != nil && !errors.Is(err, database.ErrExecutionNotFound) {
+		h.logger.Error("failed to get latest execution", "error", err, "task_id", taskID)
+		return nil, errors.New("failed to cancel execution")
+	}
+	if execution == nil || (execution.Status != models.ExecutionStatusPending && execution.Status != models.ExecutionStatusRunning) {
+		return nil, errors.New("task has no pending or running execution")
+	}
+
+	if err := h.executionService.CancelExecutionAndResetTaskStatus(ctx, execution.ID, user.ID); err != nil {
+		return &execution.ID, h.serviceError(err, "failed to cancel execution", "cannot cancel execution with status:")
+	}
+
+	h.logger.Info("execution cancelled successfully", "execution_id", execution.ID, "task_id", taskID, "user_id", user.ID)
+	return &execution.ID, nil
+}
+
+// ownedTask gets a task the user owns
+func (h *BulkHandler) ownedTask(ctx context.Context, user *models.User, taskID uuid.UUID) (*models.Task, error) {
+	task, err := h.taskService.GetOwnedTask(ctx, user, taskID)
+	if err != nil {
+		return nil, h.taskError(err, "failed to retrieve task")
+	}
+	return task, nil
+}
+
+// taskError keeps the errors of the task service that tell users why their
+// task was rejected, and hides the others behind fallback
+func (h *BulkHandler) taskError(err error, fallback string) error {
+	var validationErr *services.TaskValidationError
+	var analysisErr *analyzer.Error
+	var deniedErr *admission.DeniedError
+	var imageErr *services.TaskImageError
+
+	switch {
+	case errors.As(err, &validationErr), errors.As(err, &analysisErr), errors.As(err, &deniedErr), errors.As(err, &imageErr):
+		return err
+	case errors.Is(err, services.ErrTaskAccessDenied),
+		errors.Is(err, services.ErrCannotUpdateRunningTask),
+		errors.Is(err, services.ErrCannotDeleteRunningTask),
+		errors.Is(err, database.ErrTaskNotFound),
+		errors.Is(err, database.ErrTaskVersionConflict):
+		return err
+	}
+
+	h.logger.Error("bulk operation failed", "error", err)
+	return errors.New(fallback)
+}
+
+// serviceError keeps the errors the execution service reports to users, such
+// as a task that is already running, and hides the others behind fallback
+func (h *BulkHandler) serviceError(err error, fallback string, statusPrefix string) error {
+	switch message := err.Error(); {
+	case message == "task not found", message == "execution not found", message == "task is already running":
+		return err
+	case message == "access denied: task does not belong to user":
+		return errors.New("access denied")
+	case strings.HasPrefix(message, statusPrefix):
+		return err
+	}
+
+	h.logger.Error("bulk operation failed", "error", err)
+	return errors.New(fallback)
+}
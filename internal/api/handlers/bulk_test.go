@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
+)
+
+// MockBulkJobRepository is a mock implementation of BulkJobRepository
+type MockBulkJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockBulkJobRepository) Create(ctx context.Context, job *models.BulkJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockBulkJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BulkJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BulkJob), args.Error(1)
+}
+
+func (m *MockBulkJobRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.BulkJob, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BulkJob), args.Error(1)
+}
+
+func (m *MockBulkJobRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBulkJobRepository) UpdateProgress(ctx context.Context, job *models.BulkJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockBulkJobRepository) Heartbeat(ctx context.Context, ids []uuid.UUID) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockBulkJobRepository) FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	args := m.Called(ctx, heartbeatBefore, reason)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeBulkJobService performs the operations of a job as it is submitted
+type fakeBulkJobService struct {
+	job        *models.BulkJob
+	operations []models.BulkOperation
+	results    []models.BulkOperationResult
+	err        error
+}
+
+func (s *fakeBulkJobService) Submit(ctx context.Context, job *models.BulkJob, operations []models.BulkOperation, perform func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult) error {
+	if s.err != nil {
+		return s.err
+	}
+	job.ID = uuid.New()
+	job.Status = models.BulkJobStatusPending
+	job.Total = len(operations)
+	s.job = job
+	s.operations = operations
+	for _, operation := range operations {
+		s.results = append(s.results, perform(ctx, operation))
+	}
+	return nil
+}
+
+type bulkHandlerTest struct {
+	router           *gin.Engine
+	user             *models.User
+	jobRepo          *MockBulkJobRepository
+	jobService       *fakeBulkJobService
+	taskRepo         *MockTaskRepository
+	executionRepo    *MockTaskExecutionRepository
+	executionService *MockTaskExecutionService
+}
+
+func setupBulkHandlerTest() *bulkHandlerTest {
+	gin.SetMode(gin.TestMode)
+
+	test := &bulkHandlerTest{
+		user:             &models.User{BaseModel: models.BaseModel{ID: uuid.New()}, Email: "ops@example.com"},
+		jobRepo:          new(MockBulkJobRepository),
+		jobService:       &fakeBulkJobService{},
+		taskRepo:         new(MockTaskRepository),
+		executionRepo:    new(MockTaskExecutionRepository),
+		executionService: new(MockTaskExecutionService),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	taskService := services.NewTaskService(test.taskRepo, nil, nil, nil, nil, logger)
+	handler := NewBulkHandler(test.jobRepo, test.jobService, test.taskRepo, taskService, test.executionRepo, test.executionService, logger)
+
+	test.router = gin.New()
+	test.router.Use(func(c *gin.Context) {
+		c.Set("user", test.user)
+		c.Next()
+	})
+	test.router.POST("/tasks/batch", handler.Batch)
+	test.router.POST("/tasks/bulk", handler.Bulk)
+	test.router.GET("/jobs", handler.ListJobs)
+	test.router.GET("/jobs/:id", handler.GetJob)
+
+	return test
+}
+
+func TestBulkHandler_Batch(t *testing.T) {
+	test := setupBulkHandlerTest()
+
+	updated := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Name: "Old", ScriptType: models.ScriptTypeBash, ScriptContent: "echo old", Status: models.TaskStatusPending}
+	running := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusRunning}
+	executed := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusPending}
+	changed := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusPending, Version: 4}
+	missing := uuid.New()
+	executionID := uuid.New()
+
+	test.taskRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil)
+	test.taskRepo.On("GetByID", mock.Anything, updated.ID).Return(updated, nil)
+	test.taskRepo.On("Update", mock.Anything, updated).Return(nil)
+	test.taskRepo.On("GetByID", mock.Anything, running.ID).Return(running, nil)
+	test.taskRepo.On("GetByID", mock.Anything, executed.ID).Return(executed, nil)
+	test.taskRepo.On("GetByID", mock.Anything, missing).Return(nil, database.ErrTaskNotFound)
+	test.taskRepo.On("GetByID", mock.Anything, changed.ID).Return(changed, nil)
+	test.taskRepo.On("Delete", mock.Anything, changed.ID, 4).Return(database.ErrTaskVersionConflict)
+	test.executionService.On("CreateExecutionAndUpdateTaskStatus", mock.Anything, executed.ID, test.user.ID).
+		Return(&models.TaskExecution{ID: executionID, TaskID: executed.ID}, nil)
+
+	w := httptest.NewRecorder()
+	test.router.ServeHTTP(w, templateRequest(http.MethodPost, "/tasks/batch", map[string]any{
+		"operations": []map[string]any{
+			{"action": "create", "task": map[string]any{"name": "New", "script_type": "bash", "script_content": "echo new"}},
+			{"action": "update", "task_id": updated.ID, "changes": map[string]any{"name": "Renamed"}},
+			{"action": "delete", "task_id": running.ID},
+			{"action": "execute", "task_id": executed.ID},
+			{"action": "delete", "task_id": missing},
+			{"action": "delete", "task_id": changed.ID},
+		},
+	}))
+
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var response models.BulkJobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.BulkJobKindBatch, response.Kind)
+	assert.Equal(t, models.BulkJobStatusPending, response.Status)
+	assert.Equal(t, 6, response.Total)
+	assert.Equal(t, "/api/v1/jobs/"+response.ID.String(), w.Header().Get("Location"))
+
+	results := test.jobService.results
+	require.Len(t, results, 6)
+	assert.Equal(t, models.BulkOperationStatusSucceeded, results[0].Status)
+	assert.NotNil(t, results[0].TaskID)
+	assert.Equal(t, models.BulkOperationStatusSucceeded, results[1].Status)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Equal(t, models.BulkOperationStatusFailed, results[2].Status)
+	assert.Equal(t, "cannot delete running task", results[2].Error)
+	assert.Equal(t, models.BulkOperationStatusSucceeded, results[3].Status)
+	assert.Equal(t, &executionID, results[3].ExecutionID)
+	assert.Equal(t, models.BulkOperationStatusFailed, results[4].Status)
+	assert.Equal(t, "task not found", results[4].Error)
+	assert.Equal(t, models.BulkOperationStatusFailed, results[5].Status)
+	assert.Equal(t, database.ErrTaskVersionConflict.Error(), results[5].Error)
+	test.taskRepo.AssertNotCalled(t, "Delete", mock.Anything, running.ID, mock.Anything)
+}
+
+func TestBulkHandler_BatchValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []map[string]any
+	}{
+		{name: "no operations", operations: []map[string]any{}},
+		{name: "create without task", operations: []map[string]any{{"action": "create"}}},
+		{name: "delete without task ID", operations: []map[string]any{{"action": "delete"}}},
+		{name: "cancel", operations: []map[string]any{{"action": "cancel", "task_id": uuid.New()}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupBulkHandlerTest()
+
+			w := httptest.NewRecorder()
+			test.router.ServeHTTP(w, templateRequest(http.MethodPost, "/tasks/batch", map[string]any{"operations": tt.operations}))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			assert.Nil(t, test.jobService.job)
+		})
+	}
+}
+
+func TestBulkHandler_Bulk(t *testing.T) {
+	t.Run("cancels the executions of matching tasks", func(t *testing.T) {
+		test := setupBulkHandlerTest()
+
+		active := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusRunning}
+		idle := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusCompleted}
+		execution := &models.TaskExecution{ID: uuid.New(), TaskID: active.ID, Status: models.ExecutionStatusRunning}
+
+		cursor := "next"
+		test.taskRepo.On("Search", mock.Anything, mock.MatchedBy(func(filter database.TaskFilter) bool {
+			return filter.UserID != nil && *filter.UserID == test.user.ID && len(filter.Selector) == 1
+		}), mock.MatchedBy(func(req database.CursorPaginationRequest) bool { return req.Cursor == nil })).
+			Return([]*models.Task{active}, database.CursorPaginationResponse{HasMore: true, NextCursor: &cursor}, nil)
+		test.taskRepo.On("Search", mock.Anything, mock.Anything, mock.MatchedBy(func(req database.CursorPaginationRequest) bool { return req.Cursor != nil })).
+			Return([]*models.Task{idle}, database.CursorPaginationResponse{}, nil)
+		test.taskRepo.On("GetByID", mock.Anything, active.ID).Return(active, nil)
+		test.taskRepo.On("GetByID", mock.Anything, idle.ID).Return(idle, nil)
+		test.executionRepo.On("GetLatestByTaskID", mock.Anything, active.ID).Return(execution, nil)
+		test.executionRepo.On("GetLatestByTaskID", mock.Anything, idle.ID).Return(nil, database.ErrExecutionNotFound)
+		test.executionService.On("CancelExecutionAndResetTaskStatus", mock.Anything, execution.ID, test.user.ID).Return(nil)
+
+		w := httptest.NewRecorder()
+		test.router.ServeHTTP(w, templateRequest(http.MethodPost, "/tasks/bulk?selector=env%3Dprod", map[string]any{"action": "cancel"}))
+
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		var response models.BulkJobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.BulkJobKindBulk, response.Kind)
+		require.NotNil(t, response.Action)
+		assert.Equal(t, models.BulkActionCancel, *response.Action)
+		require.NotNil(t, response.Filter)
+		assert.Equal(t, "selector=env%3Dprod", *response.Filter)
+
+		results := test.jobService.results
+		require.Len(t, results, 2)
+		assert.Equal(t, models.BulkOperationStatusSucceeded, results[0].Status)
+		assert.Equal(t, &execution.ID, results[0].ExecutionID)
+		assert.Equal(t, models.BulkOperationStatusFailed, results[1].Status)
+		assert.Equal(t, "task has no pending or running execution", results[1].Error)
+	})
+
+	t.Run("requires a filter", func(t *testing.T) {
+		test := setupBulkHandlerTest()
+
+		w := httptest.NewRecorder()
+		test.router.ServeHTTP(w, templateRequest(http.MethodPost, "/tasks/bulk", map[string]any{"action": "delete"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		test.taskRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects batch-only actions", func(t *testing.T) {
+		test := setupBulkHandlerTest()
+
+		w := httptest.NewRecorder()
+		test.router.ServeHTTP(w, templateRequest(http.MethodPost, "/tasks/bulk?status=pending", map[string]any{"action": "create"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("passes on execution service errors users can act on", func(t *testing.T) {
+		test := setupBulkHandlerTest()
+
+		busy := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusPending}
+		broken := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: test.user.ID, Status: models.TaskStatusPending}
+		test.taskRepo.On("Search", mock.Anything, mock.Anything, mock.Anything).
+			Return([]*models.Task{busy, broken}, database.CursorPaginationResponse{}, nil)
+		test.taskRepo.On("GetByID", mock.Anything, busy.ID).Return(busy, nil)
+		test.taskRepo.On("GetByID", mock.Anything, broken.ID).Return(broken, nil)
+		test.executionService.On("CreateExecutionAndUpdateTaskStatus", mock.Anything, busy.ID, test.user.ID).
+			Return(nil, errors.New("task is already running"))
+		test.executionService.On("CreateExecutionAndUpdateTaskStatus", mock.Anything, broken.ID, test.user.ID).
+			Return(nil, errors.New("failed to begin transaction: connection refused"))
+
+		w := httptest.NewRecorder()
+		test.router.ServeHTTP(w, templateRequest(http.MethodPost, "/tasks/bulk?status=pending", map[string]any{"action": "execute"}))
+
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		results := test.jobService.results
+		require.Len(t, results, 2)
+		assert.Equal(t, "task is already running", results[0].Error)
+		assert.Equal(t, "failed to create task execution", results[1].Error)
+	})
+}
+
+func TestBulkHandler_GetJob(t *testing.T) {
+	test := setupBulkHandlerTest()
+
+	taskID := uuid.New()
+	own := &models.BulkJob{
+		BaseModel: models.BaseModel{ID: uuid.New()},
+		UserID:    test.user.ID,
+		Kind:      models.BulkJobKindBatch,
+		Status:    models.BulkJobStatusRunning,
+		Total:     2,
+		Processed: 1,
+		Succeeded: 1,
+		Results: []models.BulkOperationResult{
+			{Index: 0, Action: models.BulkActionDelete, Status: models.BulkOperationStatusSucceeded, TaskID: &taskID},
+		},
+	}
+	other := &models.BulkJob{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: uuid.New()}
+	missing := uuid.New()
+	test.jobRepo.On("GetByID", mock.Anything, own.ID).Return(own, nil)
+	test.jobRepo.On("GetByID", mock.Anything, other.ID).Return(other, nil)
+	test.jobRepo.On("GetByID", mock.Anything, missing).Return(nil, database.ErrBulkJobNotFound)
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{name: "own job", id: own.ID.String(), wantStatus: http.StatusOK},
+		{name: "another user's job", id: other.ID.String(), wantStatus: http.StatusForbidden},
+		{name: "missing job", id: missing.String(), wantStatus: http.StatusNotFound},
+		{name: "invalid ID", id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			test.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+tt.id, nil))
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var response models.BulkJobResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 1, response.Processed)
+				require.Len(t, response.Results, 1)
+				assert.Equal(t, &taskID, response.Results[0].TaskID)
+			}
+		})
+	}
+}
+
+func TestBulkHandler_ListJobs(t *testing.T) {
+	test := setupBulkHandlerTest()
+
+	job := &models.BulkJob{
+		BaseModel: models.BaseModel{ID: uuid.New()},
+		UserID:    test.user.ID,
+		Kind:      models.BulkJobKindBatch,
+		Status:    models.BulkJobStatusCompleted,
+		Results:   []models.BulkOperationResult{{Index: 0, Status: models.BulkOperationStatusSucceeded}},
+	}
+	test.jobRepo.On("GetByUserID", mock.Anything, test.user.ID, 20, 0).Return([]*models.BulkJob{job}, nil)
+	test.jobRepo.On("CountByUserID", mock.Anything, test.user.ID).Return(int64(1), nil)
+
+	w := httptest.NewRecorder()
+	test.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response models.BulkJobListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(1), response.Total)
+	require.Len(t, response.Jobs, 1)
+	assert.Equal(t, job.ID, response.Jobs[0].ID)
+	assert.Empty(t, response.Jobs[0].Results)
+}
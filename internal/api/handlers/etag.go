@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag returns the entity tag of a task or execution at the given version
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the ETag header to the given version of the resource
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", etag(version))
+}
+
+// checkIfMatch checks the If-Match header of the request, if any, against the
+// current version of the resource. When it doesn't match, 412 is written with
+// the current ETag and false is returned.
+func checkIfMatch(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || ifMatches(header, version) {
+		return true
+	}
+
+	setETag(c, version)
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": "Precondition failed: the resource has been modified",
+	})
+	return false
+}
+
+// ifMatches reports whether an If-Match header matches the version. Entity
+// tags are compared strongly, so weak tags never match.
+func ifMatches(header string, version int) bool {
+	current := etag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// respondVersionConflict writes the response to a write that lost a race with
+// another write of the resource: 412 when the request was conditional, as the
+// version it matched is gone, and 409 otherwise
+func respondVersionConflict(c *gin.Context, resource string) {
+	if c.GetHeader("If-Match") != "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": "Precondition failed: the resource has been modified",
+		})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error": resource + " was modified by another request, retry with its current version",
+	})
+}
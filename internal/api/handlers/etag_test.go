@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func TestIfMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"3"`, true},
+		{`"2"`, false},
+		{`*`, true},
+		{`"1", "3"`, true},
+		{`W/"3"`, false},
+		{`3`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, ifMatches(tt.header, 3))
+		})
+	}
+}
+
+func TestTaskHandler_IfMatch(t *testing.T) {
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	newTask := func() *models.Task {
+		return &models.Task{
+			BaseModel:     models.BaseModel{ID: taskID},
+			UserID:        userID,
+			Name:          "Test Task",
+			ScriptContent: "print('hello world')",
+			ScriptType:    models.ScriptTypePython,
+			Status:        models.TaskStatusPending,
+			Version:       3,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		ifMatch    string
+		mockSetup  func(*MockTaskRepository)
+		wantStatus int
+		wantETag   string
+	}{
+		{
+			name:   "get sets the etag",
+			method: http.MethodGet,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"3"`,
+		},
+		{
+			name:    "update with current version",
+			method:  http.MethodPut,
+			ifMatch: `"3"`,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*models.Task")).
+					Run(func(args mock.Arguments) { args.Get(1).(*models.Task).Version++ }).
+					Return(nil)
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"4"`,
+		},
+		{
+			name:    "update with stale version",
+			method:  http.MethodPut,
+			ifMatch: `"2"`,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+			},
+			wantStatus: http.StatusPreconditionFailed,
+			wantETag:   `"3"`,
+		},
+		{
+			name:    "update racing another write",
+			method:  http.MethodPut,
+			ifMatch: `"3"`,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*models.Task")).Return(database.ErrTaskVersionConflict)
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "unconditional update racing another write",
+			method: http.MethodPut,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*models.Task")).Return(database.ErrTaskVersionConflict)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:    "delete with stale version",
+			method:  http.MethodDelete,
+			ifMatch: `"2"`,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "delete with any version",
+			method:  http.MethodDelete,
+			ifMatch: "*",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+				m.On("Delete", mock.Anything, taskID, 3).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "delete racing another write",
+			method:  http.MethodDelete,
+			ifMatch: `"3"`,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+				m.On("Delete", mock.Anything, taskID, 3).Return(database.ErrTaskVersionConflict)
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "unconditional delete racing another write",
+			method: http.MethodDelete,
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetByID", mock.Anything, taskID).Return(newTask(), nil)
+				m.On("Delete", mock.Anything, taskID, 3).Return(database.ErrTaskVersionConflict)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo, handler := setupTaskHandlerTest()
+			tt.mockSetup(mockRepo)
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+				c.Next()
+			})
+			router.GET("/tasks/:id", handler.GetByID)
+			router.PUT("/tasks/:id", handler.Update)
+			router.DELETE("/tasks/:id", handler.Delete)
+
+			var body *bytes.Buffer
+			if tt.method == http.MethodPut {
+				reqBody, _ := json.Marshal(models.UpdateTaskRequest{Name: stringPtr("Updated Task")})
+				body = bytes.NewBuffer(reqBody)
+			} else {
+				body = &bytes.Buffer{}
+			}
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("/tasks/%s", taskID), body)
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantETag != "" {
+				assert.Equal(t, tt.wantETag, w.Header().Get("ETag"))
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskExecutionHandler_IfMatch(t *testing.T) {
+	executionID := uuid.New()
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	execution := &models.TaskExecution{
+		ID:      executionID,
+		TaskID:  taskID,
+		Status:  models.ExecutionStatusRunning,
+		Version: 5,
+	}
+	task := &models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID}
+
+	tests := []struct {
+		name       string
+		method     string
+		ifMatch    string
+		mockSetup  func(*MockTaskExecutionService)
+		wantStatus int
+		wantETag   string
+	}{
+		{
+			name:       "get sets the etag",
+			method:     http.MethodGet,
+			mockSetup:  func(ms *MockTaskExecutionService) {},
+			wantStatus: http.StatusOK,
+			wantETag:   `"5"`,
+		},
+		{
+			name:       "cancel with stale version",
+			method:     http.MethodDelete,
+			ifMatch:    `"4"`,
+			mockSetup:  func(ms *MockTaskExecutionService) {},
+			wantStatus: http.StatusPreconditionFailed,
+			wantETag:   `"5"`,
+		},
+		{
+			name:    "cancel with current version",
+			method:  http.MethodDelete,
+			ifMatch: `"5"`,
+			mockSetup: func(ms *MockTaskExecutionService) {
+				ms.On("CancelExecutionAndResetTaskStatus", mock.Anything, executionID, userID).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockTaskRepo, mockExecutionRepo, mockExecutionService, handler := setupTaskExecutionHandlerTest()
+			mockExecutionRepo.On("GetByID", mock.Anything, executionID).Return(execution, nil)
+			mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(task, nil)
+			tt.mockSetup(mockExecutionService)
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+				c.Next()
+			})
+			router.GET("/executions/:id", handler.GetByID)
+			router.DELETE("/executions/:id", handler.Cancel)
+
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("/executions/%s", executionID), nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantETag != "" {
+				assert.Equal(t, tt.wantETag, w.Header().Get("ETag"))
+			}
+			mockExecutionService.AssertExpectations(t)
+		})
+	}
+}
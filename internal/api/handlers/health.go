@@ -12,6 +12,12 @@ type HealthChecker interface {
 	CheckHealth() (status string, err error)
 }
 
+// HealthDetailer is implemented by health checks that report details, such as
+// connection pool statistics, next to their status in the readiness response
+type HealthDetailer interface {
+	HealthDetails() any
+}
+
 type HealthHandler struct {
 	startTime    time.Time
 	healthChecks map[string]HealthChecker
@@ -40,6 +46,7 @@ type HealthResponse struct {
 type ReadinessResponse struct {
 	Status    string            `json:"status"`
 	Checks    map[string]string `json:"checks"`
+	Details   map[string]any    `json:"details,omitempty"`
 	Timestamp time.Time         `json:"timestamp"`
 }
 
@@ -69,7 +76,7 @@ func (h *HealthHandler) Health(c *gin.Context) {
 // Readiness performs a readiness check
 //
 //	@Summary		Readiness check
-//	@Description	Returns the readiness status of the API service and its dependencies
+//	@Description	Returns the readiness status of the API service and its dependencies, with details such as the database connection pool statistics
 //	@Tags			Health
 //	@Accept			json
 //	@Produce		json
@@ -78,6 +85,7 @@ func (h *HealthHandler) Health(c *gin.Context) {
 //	@Router			/ready [get]
 func (h *HealthHandler) Readiness(c *gin.Context) {
 	checks := make(map[string]string)
+	details := make(map[string]any)
 
 	// Always include basic server check
 	checks["server"] = "ready"
@@ -92,6 +100,12 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 		} else {
 			checks[name] = status
 		}
+
+		if detailer, ok := checker.(HealthDetailer); ok {
+			if detail := detailer.HealthDetails(); detail != nil {
+				details[name] = detail
+			}
+		}
 	}
 
 	// Check if all components are healthy
@@ -105,6 +119,7 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 	response := ReadinessResponse{
 		Status:    "ready",
 		Checks:    checks,
+		Details:   details,
 		Timestamp: time.Now(),
 	}
 
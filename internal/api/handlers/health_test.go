@@ -22,6 +22,16 @@ func (m *MockHealthChecker) CheckHealth() (string, error) {
 	return m.status, m.err
 }
 
+// MockDetailedHealthChecker is a mock HealthChecker that reports details
+type MockDetailedHealthChecker struct {
+	MockHealthChecker
+	details any
+}
+
+func (m *MockDetailedHealthChecker) HealthDetails() any {
+	return m.details
+}
+
 func TestHealthHandler_Health(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -167,3 +177,33 @@ func TestHealthHandler_Readiness(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthHandler_ReadinessDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	handler := NewHealthHandler()
+	handler.AddHealthCheck("database", &MockDetailedHealthChecker{
+		MockHealthChecker: MockHealthChecker{status: "", err: errors.New("db connection lost")},
+		details:           map[string]int{"acquired_conns": 25, "wait_count": 3},
+	})
+	handler.AddHealthCheck("redis", &MockDetailedHealthChecker{
+		MockHealthChecker: MockHealthChecker{status: "ready"},
+	})
+	handler.AddHealthCheck("queue", &MockHealthChecker{status: "ready"})
+	router.GET("/ready", handler.Readiness)
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response struct {
+		Details map[string]map[string]int `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]map[string]int{
+		"database": {"acquired_conns": 25, "wait_count": 3},
+	}, response.Details, "details are reported for unhealthy checks too, and omitted when empty")
+}
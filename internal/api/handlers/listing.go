@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/labels"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// maxSearchQueryLength caps the full-text search query of task listings
+const maxSearchQueryLength = 200
+
+// taskSortFields are the fields task listings can be sorted by
+var taskSortFields = []string{"created_at", "updated_at", "priority", "name"}
+
+// executionSortFields are the fields execution listings can be sorted by
+var executionSortFields = []string{"created_at"}
+
+// parsePagination parses the offset pagination parameters from query string
+func parsePagination(c *gin.Context) (limit, offset int, err error) {
+	// Default values
+	limit = 20
+	offset = 0
+
+	// Parse limit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid limit parameter: %w", err)
+		}
+		if limit < 1 || limit > 100 {
+			return 0, 0, fmt.Errorf("limit must be between 1 and 100")
+		}
+	}
+
+	// Parse offset
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid offset parameter: %w", err)
+		}
+		if offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be non-negative")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// parseListPagination parses the cursor pagination and sort parameters of a
+// filtered listing. The cursor is optional, so that the first page can be
+// requested with the same parameters.
+func parseListPagination(c *gin.Context, sortFields []string) (database.CursorPaginationRequest, error) {
+	req := database.CursorPaginationRequest{
+		Limit:     20,
+		SortOrder: "desc",
+		SortField: "created_at",
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		req.Cursor = &cursor
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return req, fmt.Errorf("invalid limit parameter: %w", err)
+		}
+		req.Limit = limit
+	}
+
+	if sortOrder := c.Query("sort_order"); sortOrder != "" {
+		req.SortOrder = sortOrder
+	}
+
+	if sortField := c.Query("sort_field"); sortField != "" {
+		valid := false
+		for _, field := range sortFields {
+			valid = valid || field == sortField
+		}
+		if !valid {
+			return req, fmt.Errorf("invalid sort_field parameter: must be one of %s", strings.Join(sortFields, ", "))
+		}
+		req.SortField = sortField
+	}
+
+	database.ValidatePaginationRequest(&req)
+
+	return req, nil
+}
+
+// parseTaskFilter parses the filters of a task listing
+func parseTaskFilter(c *gin.Context) (database.TaskFilter, bool, error) {
+	var filter database.TaskFilter
+
+	for _, value := range queryList(c, "status") {
+		status := models.TaskStatus(value)
+		if err := models.ValidateTaskStatus(status); err != nil {
+			return filter, false, fmt.Errorf("invalid status parameter: %w", err)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	for _, value := range queryList(c, "script_type") {
+		scriptType := models.ScriptType(value)
+		if err := models.ValidateScriptType(scriptType); err != nil {
+			return filter, false, fmt.Errorf("invalid script_type parameter: %w", err)
+		}
+		filter.ScriptTypes = append(filter.ScriptTypes, scriptType)
+	}
+
+	var err error
+	if filter.MinPriority, err = queryInt(c, "priority_min"); err != nil {
+		return filter, false, err
+	}
+	if filter.MaxPriority, err = queryInt(c, "priority_max"); err != nil {
+		return filter, false, err
+	}
+	for name, priority := range map[string]*int{"priority_min": filter.MinPriority, "priority_max": filter.MaxPriority} {
+		if priority != nil && models.ValidatePriority(*priority) != nil {
+			return filter, false, fmt.Errorf("%s must be between 0 and 10", name)
+		}
+	}
+	if filter.MinPriority != nil && filter.MaxPriority != nil && *filter.MinPriority > *filter.MaxPriority {
+		return filter, false, fmt.Errorf("priority_min must not be greater than priority_max")
+	}
+
+	if filter.CreatedAfter, filter.CreatedBefore, err = queryTimeRange(c, "created_after", "created_before"); err != nil {
+		return filter, false, err
+	}
+	if filter.UpdatedAfter, filter.UpdatedBefore, err = queryTimeRange(c, "updated_after", "updated_before"); err != nil {
+		return filter, false, err
+	}
+
+	if metadata := c.Query("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &filter.Metadata); err != nil || filter.Metadata == nil {
+			return filter, false, fmt.Errorf("invalid metadata parameter: must be a JSON object")
+		}
+	}
+
+	filter.Query = strings.TrimSpace(c.Query("q"))
+	if len(filter.Query) > maxSearchQueryLength {
+		return filter, false, fmt.Errorf("q must be at most %d characters", maxSearchQueryLength)
+	}
+	if filter.Query != "" && database.NameSearchQuery(filter.Query) == "" {
+		return filter, false, fmt.Errorf("q must contain a letter or digit")
+	}
+
+	if filter.Selector, err = labels.Parse(c.Query("selector")); err != nil {
+		return filter, false, err
+	}
+
+	filtered := len(filter.Statuses) > 0 || len(filter.ScriptTypes) > 0 ||
+		filter.MinPriority != nil || filter.MaxPriority != nil ||
+		filter.CreatedAfter != nil || filter.CreatedBefore != nil ||
+		filter.UpdatedAfter != nil || filter.UpdatedBefore != nil ||
+		len(filter.Metadata) > 0 || filter.Query != "" || len(filter.Selector) > 0
+
+	return filter, filtered, nil
+}
+
+// parseExecutionFilter parses the filters of an execution listing
+func parseExecutionFilter(c *gin.Context) (database.ExecutionFilter, bool, error) {
+	var filter database.ExecutionFilter
+
+	for _, value := range queryList(c, "status") {
+		status := models.ExecutionStatus(value)
+		if err := models.ValidateExecutionStatus(status); err != nil {
+			return filter, false, fmt.Errorf("invalid status parameter: %w", err)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	var err error
+	if filter.ReturnCode, err = queryInt(c, "exit_code"); err != nil {
+		return filter, false, err
+	}
+	if filter.MinDurationMs, err = queryInt(c, "duration_min_ms"); err != nil {
+		return filter, false, err
+	}
+	if filter.MaxDurationMs, err = queryInt(c, "duration_max_ms"); err != nil {
+		return filter, false, err
+	}
+	for name, duration := range map[string]*int{"duration_min_ms": filter.MinDurationMs, "duration_max_ms": filter.MaxDurationMs} {
+		if duration != nil && *duration < 0 {
+			return filter, false, fmt.Errorf("%s must be non-negative", name)
+		}
+	}
+	if filter.MinDurationMs != nil && filter.MaxDurationMs != nil && *filter.MinDurationMs > *filter.MaxDurationMs {
+		return filter, false, fmt.Errorf("duration_min_ms must not be greater than duration_max_ms")
+	}
+
+	if filter.CreatedAfter, filter.CreatedBefore, err = queryTimeRange(c, "created_after", "created_before"); err != nil {
+		return filter, false, err
+	}
+
+	if filter.Selector, err = labels.Parse(c.Query("selector")); err != nil {
+		return filter, false, err
+	}
+
+	filtered := len(filter.Statuses) > 0 || filter.ReturnCode != nil ||
+		filter.MinDurationMs != nil || filter.MaxDurationMs != nil ||
+		filter.CreatedAfter != nil || filter.CreatedBefore != nil ||
+		len(filter.Selector) > 0
+
+	return filter, filtered, nil
+}
+
+// queryUUID parses an optional UUID query parameter
+func queryUUID(c *gin.Context, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: must be a UUID", name)
+	}
+	return &id, nil
+}
+
+// queryList returns the values of a query parameter that may be repeated or
+// comma-separated
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, param := range c.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// queryInt parses an optional integer query parameter
+func queryInt(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: must be an integer", name)
+	}
+	return &n, nil
+}
+
+// queryTimeRange parses an optional RFC 3339 time range from two query
+// parameters
+func queryTimeRange(c *gin.Context, afterName, beforeName string) (after, before *time.Time, err error) {
+	parse := func(name string) (*time.Time, error) {
+		value := c.Query(name)
+		if value == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: must be an RFC 3339 timestamp", name)
+		}
+		return &t, nil
+	}
+
+	if after, err = parse(afterName); err != nil {
+		return nil, nil, err
+	}
+	if before, err = parse(beforeName); err != nil {
+		return nil, nil, err
+	}
+	if after != nil && before != nil && !after.Before(*before) {
+		return nil, nil, fmt.Errorf("%s must be before %s", afterName, beforeName)
+	}
+	return after, before, nil
+}
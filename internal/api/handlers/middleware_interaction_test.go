@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
 	"github.com/voidrunnerhq/voidrunner/pkg/logger"
 )
 
@@ -28,8 +29,8 @@ func TestHandlerIntegration_TaskWithValidation(t *testing.T) {
 
 	// Setup handlers
 	mockExecutionService := new(MockTaskExecutionService)
-	taskHandler := NewTaskHandler(mockTaskRepo, logger.Logger)
-	executionHandler := NewTaskExecutionHandler(mockTaskRepo, mockExecutionRepo, mockExecutionService, logger.Logger)
+	taskHandler := NewTaskHandler(mockTaskRepo, nil, services.NewTaskService(mockTaskRepo, nil, nil, nil, nil, logger.Logger), logger.Logger)
+	executionHandler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, nil, mockExecutionService, nil, nil, logger.Logger)
 	validationMiddleware := middleware.TaskValidation(logger.Logger)
 
 	// Setup router with middleware
@@ -99,7 +100,7 @@ func TestHandlerIntegration_TaskWithValidation(t *testing.T) {
 		req := models.CreateTaskRequest{
 			Name:          "Dangerous Task",
 			ScriptContent: "rm -rf /",
-			ScriptType:    models.ScriptTypePython,
+			ScriptType:    models.ScriptTypeBash,
 		}
 
 		reqBody, _ := json.Marshal(req)
@@ -111,10 +112,13 @@ func TestHandlerIntegration_TaskWithValidation(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 
-		var errorResponse map[string]interface{}
+		var errorResponse models.ErrorResponse
 		err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
 		require.NoError(t, err)
-		assert.Contains(t, errorResponse["error"], "Validation failed")
+		assert.Contains(t, errorResponse.Error, "Validation failed")
+		require.NotEmpty(t, errorResponse.Findings)
+		assert.Equal(t, "bash.rm.recursive", errorResponse.Findings[0].RuleID)
+		assert.Equal(t, 1, errorResponse.Findings[0].Line)
 	})
 
 	t.Run("Invalid Task Creation - Empty Name", func(t *testing.T) {
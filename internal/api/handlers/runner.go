@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// RunnerServiceInterface defines the interface for the remote runner service
+type RunnerServiceInterface interface {
+	ClaimJob(ctx context.Context, runnerID string, capabilities []string, wait time.Duration) (*models.RunnerJob, error)
+	Heartbeat(ctx context.Context, executionID uuid.UUID, leaseToken string) (*models.RunnerHeartbeatResponse, error)
+	AppendLogs(ctx context.Context, executionID uuid.UUID, req models.RunnerLogRequest) error
+	CompleteJob(ctx context.Context, executionID uuid.UUID, req models.RunnerResultRequest) (*models.TaskExecution, error)
+}
+
+// RunnerHandler handles the job API used by remote runner agents
+type RunnerHandler struct {
+	runnerService RunnerServiceInterface
+	maxPollWait   time.Duration
+	logger        *slog.Logger
+}
+
+// NewRunnerHandler creates a new runner handler
+func NewRunnerHandler(runnerService RunnerServiceInterface, maxPollWait time.Duration, logger *slog.Logger) *RunnerHandler {
+	return &RunnerHandler{
+		runnerService: runnerService,
+		maxPollWait:   maxPollWait,
+		logger:        logger,
+	}
+}
+
+// ClaimJob handles long-polling for the next job a runner can execute
+//
+//	@Summary		Claim a job
+//	@Description	Long-polls for the next queued task matching the runner's capabilities and leases it to the runner
+//	@Tags			Runners
+//	@Accept			json
+//	@Produce		json
+//	@Security		RunnerAuth
+//	@Param			X-Runner-ID	header		string					true	"Runner identifier"
+//	@Param			request		body		models.RunnerJobRequest	true	"Claim parameters"
+//	@Success		200			{object}	models.RunnerJob		"Job leased to the runner"
+//	@Success		204			"No job became available before the wait expired"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid request"
+//	@Failure		401			{object}	models.ErrorResponse	"Invalid runner token"
+//	@Router			/runner/jobs [post]
+func (h *RunnerHandler) ClaimJob(c *gin.Context) {
+	var req models.RunnerJobRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	wait := time.Duration(req.WaitSeconds) * time.Second
+	if wait <= 0 || wait > h.maxPollWait {
+		wait = h.maxPollWait
+	}
+
+	runnerID := middleware.GetRunnerIDFromContext(c)
+	job, err := h.runnerService.ClaimJob(c.Request.Context(), runnerID, req.Capabilities, wait)
+	if err != nil {
+		h.logger.Error("failed to claim job", "error", err, "runner_id", runnerID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to claim job",
+		})
+		return
+	}
+
+	if job == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Heartbeat handles lease renewal for a running job
+//
+//	@Summary		Renew a job lease
+//	@Description	Extends the lease on a running job and reports whether the job was cancelled
+//	@Tags			Runners
+//	@Accept			json
+//	@Produce		json
+//	@Security		RunnerAuth
+//	@Param			X-Runner-ID	header		string							true	"Runner identifier"
+//	@Param			id			path		string							true	"Execution ID"
+//	@Param			request		body		models.RunnerHeartbeatRequest	true	"Lease token"
+//	@Success		200			{object}	models.RunnerHeartbeatResponse	"Lease renewed"
+//	@Failure		400			{object}	models.ErrorResponse			"Invalid request"
+//	@Failure		401			{object}	models.ErrorResponse			"Invalid runner token"
+//	@Failure		403			{object}	models.ErrorResponse			"Lease does not belong to this execution"
+//	@Failure		404			{object}	models.ErrorResponse			"Execution not found"
+//	@Failure		409			{object}	models.ErrorResponse			"Lease is no longer held"
+//	@Router			/runner/jobs/{id}/heartbeat [post]
+func (h *RunnerHandler) Heartbeat(c *gin.Context) {
+	executionID, ok := h.parseExecutionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RunnerHeartbeatRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	response, err := h.runnerService.Heartbeat(c.Request.Context(), executionID, req.LeaseToken)
+	if err != nil {
+		h.respondWithError(c, "failed to renew job lease", executionID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AppendLogs handles streamed output from a running job
+//
+//	@Summary		Upload job output
+//	@Description	Appends a chunk of stdout and stderr to a running job and renews its lease
+//	@Tags			Runners
+//	@Accept			json
+//	@Produce		json
+//	@Security		RunnerAuth
+//	@Param			X-Runner-ID	header	string					true	"Runner identifier"
+//	@Param			id			path	string					true	"Execution ID"
+//	@Param			request		body	models.RunnerLogRequest	true	"Output chunk"
+//	@Success		204			"Output appended"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid request"
+//	@Failure		401			{object}	models.ErrorResponse	"Invalid runner token"
+//	@Failure		403			{object}	models.ErrorResponse	"Lease does not belong to this execution"
+//	@Failure		404			{object}	models.ErrorResponse	"Execution not found"
+//	@Failure		409			{object}	models.ErrorResponse	"Lease is no longer held"
+//	@Router			/runner/jobs/{id}/logs [post]
+func (h *RunnerHandler) AppendLogs(c *gin.Context) {
+	executionID, ok := h.parseExecutionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RunnerLogRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	if err := h.runnerService.AppendLogs(c.Request.Context(), executionID, req); err != nil {
+		h.respondWithError(c, "failed to append job logs", executionID, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SubmitResult handles the final result of a job
+//
+//	@Summary		Submit a job result
+//	@Description	Records the outcome of a leased job, finalizes the task status and releases the job
+//	@Tags			Runners
+//	@Accept			json
+//	@Produce		json
+//	@Security		RunnerAuth
+//	@Param			X-Runner-ID	header		string						true	"Runner identifier"
+//	@Param			id			path		string						true	"Execution ID"
+//	@Param			request		body		models.RunnerResultRequest	true	"Execution result"
+//	@Success		200			{object}	models.TaskExecutionResponse	"Result recorded"
+//	@Failure		400			{object}	models.ErrorResponse			"Invalid request"
+//	@Failure		401			{object}	models.ErrorResponse			"Invalid runner token"
+//	@Failure		403			{object}	models.ErrorResponse			"Lease does not belong to this execution"
+//	@Failure		404			{object}	models.ErrorResponse			"Execution not found"
+//	@Failure		409			{object}	models.ErrorResponse			"Lease is no longer held"
+//	@Router			/runner/jobs/{id}/result [post]
+func (h *RunnerHandler) SubmitResult(c *gin.Context) {
+	executionID, ok := h.parseExecutionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RunnerResultRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	execution, err := h.runnerService.CompleteJob(c.Request.Context(), executionID, req)
+	if err != nil {
+		h.respondWithError(c, "failed to submit job result", executionID, err)
+		return
+	}
+
+	h.logger.Info("runner job completed",
+		"execution_id", executionID,
+		"runner_id", middleware.GetRunnerIDFromContext(c),
+		"status", execution.Status,
+	)
+	c.JSON(http.StatusOK, execution.ToResponse())
+}
+
+// parseExecutionID parses the execution ID path parameter
+func (h *RunnerHandler) parseExecutionID(c *gin.Context) (uuid.UUID, bool) {
+	executionIDStr := c.Param("id")
+	executionID, err := uuid.Parse(executionIDStr)
+	if err != nil {
+		h.logger.Warn("invalid execution ID", "execution_id", executionIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid execution ID format",
+		})
+		return uuid.Nil, false
+	}
+	return executionID, true
+}
+
+// bindRequest loads the request validated by middleware, falling back to
+// binding the body directly
+func (h *RunnerHandler) bindRequest(c *gin.Context, req interface{}) bool {
+	if validatedBody, exists := c.Get("validated_body"); exists {
+		switch body := validatedBody.(type) {
+		case *models.RunnerJobRequest:
+			*req.(*models.RunnerJobRequest) = *body
+		case *models.RunnerHeartbeatRequest:
+			*req.(*models.RunnerHeartbeatRequest) = *body
+		case *models.RunnerLogRequest:
+			*req.(*models.RunnerLogRequest) = *body
+		case *models.RunnerResultRequest:
+			*req.(*models.RunnerResultRequest) = *body
+		}
+		return true
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		h.logger.Warn("invalid runner request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondWithError maps runner service errors to HTTP status codes
+func (h *RunnerHandler) respondWithError(c *gin.Context, message string, executionID uuid.UUID, err error) {
+	h.logger.Warn(message, "error", err, "execution_id", executionID, "runner_id", middleware.GetRunnerIDFromContext(c))
+
+	switch {
+	case err.Error() == "execution not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Execution not found",
+		})
+	case err.Error() == "lease does not belong to this execution":
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Lease does not belong to this execution",
+		})
+	case err.Error() == "invalid or expired lease":
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Lease is no longer held by this runner",
+		})
+	case strings.HasPrefix(err.Error(), "cannot "):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case strings.HasPrefix(err.Error(), "invalid result status:"),
+		strings.HasPrefix(err.Error(), "log chunk exceeds"):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Internal server error",
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// MockRunnerService is a mock implementation of RunnerServiceInterface
+type MockRunnerService struct {
+	mock.Mock
+}
+
+func (m *MockRunnerService) ClaimJob(ctx context.Context, runnerID string, capabilities []string, wait time.Duration) (*models.RunnerJob, error) {
+	args := m.Called(ctx, runnerID, capabilities, wait)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RunnerJob), args.Error(1)
+}
+
+func (m *MockRunnerService) Heartbeat(ctx context.Context, executionID uuid.UUID, leaseToken string) (*models.RunnerHeartbeatResponse, error) {
+	args := m.Called(ctx, executionID, leaseToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RunnerHeartbeatResponse), args.Error(1)
+}
+
+func (m *MockRunnerService) AppendLogs(ctx context.Context, executionID uuid.UUID, req models.RunnerLogRequest) error {
+	args := m.Called(ctx, executionID, req)
+	return args.Error(0)
+}
+
+func (m *MockRunnerService) CompleteJob(ctx context.Context, executionID uuid.UUID, req models.RunnerResultRequest) (*models.TaskExecution, error) {
+	args := m.Called(ctx, executionID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskExecution), args.Error(1)
+}
+
+func setupRunnerHandlerTest() (*gin.Engine, *MockRunnerService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRunnerService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewRunnerHandler(mockService, 20*time.Second, logger)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("runner_id", "runner-01")
+		c.Next()
+	})
+	router.POST("/runner/jobs", handler.ClaimJob)
+	router.POST("/runner/jobs/:id/heartbeat", handler.Heartbeat)
+	router.POST("/runner/jobs/:id/logs", handler.AppendLogs)
+	router.POST("/runner/jobs/:id/result", handler.SubmitResult)
+
+	return router, mockService
+}
+
+func postJSON(router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRunnerHandler_ClaimJob(t *testing.T) {
+	executionID := uuid.New()
+
+	tests := []struct {
+		name       string
+		body       models.RunnerJobRequest
+		mockSetup  func(*MockRunnerService)
+		wantStatus int
+	}{
+		{
+			name: "job leased",
+			body: models.RunnerJobRequest{Capabilities: []string{"gpu"}, WaitSeconds: 5},
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("ClaimJob", mock.Anything, "runner-01", []string{"gpu"}, 5*time.Second).Return(&models.RunnerJob{
+					ExecutionID: executionID,
+					LeaseToken:  "lease",
+					ScriptType:  models.ScriptTypePython,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "wait is capped at the server maximum",
+			body: models.RunnerJobRequest{WaitSeconds: 60},
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("ClaimJob", mock.Anything, "runner-01", []string(nil), 20*time.Second).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "service error",
+			body: models.RunnerJobRequest{},
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("ClaimJob", mock.Anything, "runner-01", []string(nil), 20*time.Second).Return(nil, errors.New("failed to dequeue task"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService := setupRunnerHandlerTest()
+			tt.mockSetup(mockService)
+
+			w := postJSON(router, "/runner/jobs", tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var job models.RunnerJob
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+				assert.Equal(t, executionID, job.ExecutionID)
+				assert.Equal(t, "lease", job.LeaseToken)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunnerHandler_Heartbeat(t *testing.T) {
+	executionID := uuid.New()
+
+	tests := []struct {
+		name        string
+		executionID string
+		mockSetup   func(*MockRunnerService)
+		wantStatus  int
+	}{
+		{
+			name:        "lease renewed",
+			executionID: executionID.String(),
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("Heartbeat", mock.Anything, executionID, "lease").Return(&models.RunnerHeartbeatResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "invalid execution ID",
+			executionID: "invalid-uuid",
+			mockSetup:   func(ms *MockRunnerService) {},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "execution not found",
+			executionID: executionID.String(),
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("Heartbeat", mock.Anything, executionID, "lease").Return(nil, errors.New("execution not found"))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "lease lost",
+			executionID: executionID.String(),
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("Heartbeat", mock.Anything, executionID, "lease").Return(nil, errors.New("invalid or expired lease"))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:        "lease of another execution",
+			executionID: executionID.String(),
+			mockSetup: func(ms *MockRunnerService) {
+				ms.On("Heartbeat", mock.Anything, executionID, "lease").Return(nil, errors.New("lease does not belong to this execution"))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService := setupRunnerHandlerTest()
+			tt.mockSetup(mockService)
+
+			w := postJSON(router, fmt.Sprintf("/runner/jobs/%s/heartbeat", tt.executionID), models.RunnerHeartbeatRequest{LeaseToken: "lease"})
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunnerHandler_AppendLogs(t *testing.T) {
+	executionID := uuid.New()
+	body := models.RunnerLogRequest{LeaseToken: "lease", Stdout: "hello\n"}
+
+	t.Run("output appended", func(t *testing.T) {
+		router, mockService := setupRunnerHandlerTest()
+		mockService.On("AppendLogs", mock.Anything, executionID, body).Return(nil)
+
+		w := postJSON(router, fmt.Sprintf("/runner/jobs/%s/logs", executionID), body)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("execution no longer running", func(t *testing.T) {
+		router, mockService := setupRunnerHandlerTest()
+		mockService.On("AppendLogs", mock.Anything, executionID, body).Return(errors.New("cannot append logs to execution with status: completed"))
+
+		w := postJSON(router, fmt.Sprintf("/runner/jobs/%s/logs", executionID), body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestRunnerHandler_SubmitResult(t *testing.T) {
+	executionID := uuid.New()
+	returnCode := 0
+	body := models.RunnerResultRequest{
+		LeaseToken: "lease",
+		Status:     models.ExecutionStatusCompleted,
+		ReturnCode: &returnCode,
+	}
+
+	t.Run("result recorded", func(t *testing.T) {
+		router, mockService := setupRunnerHandlerTest()
+		mockService.On("CompleteJob", mock.Anything, executionID, body).Return(&models.TaskExecution{
+			ID:         executionID,
+			Status:     models.ExecutionStatusCompleted,
+			ReturnCode: &returnCode,
+		}, nil)
+
+		w := postJSON(router, fmt.Sprintf("/runner/jobs/%s/result", executionID), body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.TaskExecutionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.ExecutionStatusCompleted, response.Status)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		router, mockService := setupRunnerHandlerTest()
+		invalid := body
+		invalid.Status = models.ExecutionStatusRunning
+		mockService.On("CompleteJob", mock.Anything, executionID, invalid).Return(nil, errors.New("invalid result status: running"))
+
+		w := postJSON(router, fmt.Sprintf("/runner/jobs/%s/result", executionID), invalid)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("execution cancelled", func(t *testing.T) {
+		router, mockService := setupRunnerHandlerTest()
+		mockService.On("CompleteJob", mock.Anything, executionID, body).Return(nil, errors.New("cannot complete execution with status: cancelled"))
+
+		w := postJSON(router, fmt.Sprintf("/runner/jobs/%s/result", executionID), body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
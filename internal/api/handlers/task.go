@@ -1,30 +1,52 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
 	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
-	"github.com/voidrunnerhq/voidrunner/internal/config"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
 )
 
+// TaskServiceInterface defines the interface for the task service
+type TaskServiceInterface interface {
+	CreateTask(ctx context.Context, user *models.User, req models.CreateTaskRequest) (*models.Task, []models.ScriptFinding, error)
+	SaveNewTask(ctx context.Context, user *models.User, task *models.Task) ([]models.ScriptFinding, error)
+	GetOwnedTask(ctx context.Context, user *models.User, taskID uuid.UUID) (*models.Task, error)
+	UpdateTask(ctx context.Context, user *models.User, task *models.Task, req models.UpdateTaskRequest) ([]models.ScriptFinding, error)
+	SaveTask(ctx context.Context, user *models.User, task *models.Task) ([]models.ScriptFinding, error)
+	DeleteTask(ctx context.Context, user *models.User, task *models.Task) error
+	CheckTask(ctx context.Context, user *models.User, task *models.Task) ([]models.ScriptFinding, error)
+	AdmitTask(user *models.User, task *models.Task) error
+	WithTransaction(ctx context.Context, fn func(tasks *services.TaskService) error) error
+}
+
 // TaskHandler handles task-related API endpoints
 type TaskHandler struct {
-	taskRepo database.TaskRepository
-	logger   *slog.Logger
+	taskRepo     database.TaskRepository
+	revisionRepo database.TaskRevisionRepository
+	taskService  TaskServiceInterface
+	logger       *slog.Logger
 }
 
-// NewTaskHandler creates a new task handler
-func NewTaskHandler(taskRepo database.TaskRepository, logger *slog.Logger) *TaskHandler {
+// NewTaskHandler creates a new task handler. The revision repository serves
+// the task revision endpoints. Tasks are created, updated and deleted
+// through the task service, which checks them before writing them.
+func NewTaskHandler(taskRepo database.TaskRepository, revisionRepo database.TaskRevisionRepository, taskService TaskServiceInterface, logger *slog.Logger) *TaskHandler {
 	return &TaskHandler{
-		taskRepo: taskRepo,
-		logger:   logger,
+		taskRepo:     taskRepo,
+		revisionRepo: revisionRepo,
+		taskService:  taskService,
+		logger:       logger,
 	}
 }
 
@@ -40,7 +62,9 @@ func NewTaskHandler(taskRepo database.TaskRepository, logger *slog.Logger) *Task
 //	@Success		201		{object}	models.TaskResponse			"Task created successfully"
 //	@Failure		400		{object}	models.ErrorResponse		"Invalid request format or validation error"
 //	@Failure		401		{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse		"Denied by admission policy"
 //	@Failure		429		{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Failure		502		{object}	models.ErrorResponse		"Image could not be resolved"
 //	@Router			/tasks [post]
 func (h *TaskHandler) Create(c *gin.Context) {
 	// Get validated request from middleware
@@ -71,62 +95,46 @@ func (h *TaskHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Validate request
-	if err := h.validateCreateRequest(req); err != nil {
-		h.logger.Warn("task creation validation failed", "error", err, "user_id", user.ID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+	task, findings, err := h.taskService.CreateTask(c.Request.Context(), user, req)
+	if err != nil {
+		h.respondTaskError(c, err, user, "create")
 		return
 	}
 
-	// Create task model
-	task := &models.Task{
-		BaseModel: models.BaseModel{
-			ID: uuid.New(),
-		},
-		UserID:         user.ID,
-		Name:           req.Name,
-		Description:    req.Description,
-		ScriptContent:  req.ScriptContent,
-		ScriptType:     req.ScriptType,
-		Status:         models.TaskStatusPending,
-		Priority:       5, // Default priority
-		TimeoutSeconds: config.DefaultTaskTimeout,
-		Metadata:       req.Metadata,
-	}
-
-	// Set optional fields
-	if req.Priority != nil {
-		task.Priority = *req.Priority
-	}
-	if req.TimeoutSeconds != nil {
-		task.TimeoutSeconds = *req.TimeoutSeconds
-	}
+	h.respondCreated(c, task, findings)
+}
 
-	// Create task in database
-	if err := h.taskRepo.Create(c.Request.Context(), task); err != nil {
-		h.logger.Error("failed to create task", "error", err, "user_id", user.ID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create task",
-		})
+// createTask checks and saves a new task built by the caller. The response,
+// or the error response, has been written when it returns.
+func (h *TaskHandler) createTask(c *gin.Context, user *models.User, task *models.Task) {
+	findings, err := h.taskService.SaveNewTask(c.Request.Context(), user, task)
+	if err != nil {
+		h.respondTaskError(c, err, user, "create")
 		return
 	}
 
-	h.logger.Info("task created successfully", "task_id", task.ID, "user_id", user.ID)
-	c.JSON(http.StatusCreated, task.ToResponse())
+	h.respondCreated(c, task, findings)
+}
+
+// respondCreated writes the response for a created task
+func (h *TaskHandler) respondCreated(c *gin.Context, task *models.Task, findings []models.ScriptFinding) {
+	response := task.ToResponse()
+	response.ScriptFindings = findings
+	setETag(c, task.Version)
+	c.JSON(http.StatusCreated, response)
 }
 
 // GetByID handles retrieving a task by ID
 //
 //	@Summary		Get task details
-//	@Description	Retrieves detailed information about a specific task
+//	@Description	Retrieves detailed information about a specific task. The ETag header carries the task's version, to be sent as If-Match when updating or deleting it.
 //	@Tags			Tasks
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			id	path		string	true	"Task ID"
 //	@Success		200	{object}	models.TaskResponse		"Task retrieved successfully"
+//	@Header			200	{string}	ETag					"Version of the task"
 //	@Failure		400	{object}	models.ErrorResponse	"Invalid task ID"
 //	@Failure		401	{object}	models.ErrorResponse	"Unauthorized"
 //	@Failure		403	{object}	models.ErrorResponse	"Forbidden"
@@ -182,23 +190,38 @@ func (h *TaskHandler) GetByID(c *gin.Context) {
 	}
 
 	h.logger.Debug("task retrieved successfully", "task_id", taskID, "user_id", user.ID)
+	setETag(c, task.Version)
 	c.JSON(http.StatusOK, task.ToResponse())
 }
 
 // List handles listing user's tasks with pagination
 //
 //	@Summary		List user's tasks
-//	@Description	Retrieves a paginated list of tasks owned by the authenticated user
+//	@Description	Retrieves a paginated list of tasks owned by the authenticated user. Listings that are filtered, sorted or given a cursor use cursor-based pagination and return a models.TaskSearchResponse.
 //	@Tags			Tasks
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			limit	query	int	false	"Maximum number of tasks to return"	default(20)
-//	@Param			offset	query	int	false	"Number of tasks to skip"	default(0)
-//	@Success		200		{object}	models.TaskListResponse	"Tasks retrieved successfully"
-//	@Failure		400		{object}	models.ErrorResponse	"Invalid query parameters"
-//	@Failure		401		{object}	models.ErrorResponse	"Unauthorized"
-//	@Failure		429		{object}	models.ErrorResponse	"Rate limit exceeded"
+//	@Param			limit			query	int		false	"Maximum number of tasks to return"	default(20)
+//	@Param			offset			query	int		false	"Number of tasks to skip, without filters, sorting or cursor"	default(0)
+//	@Param			cursor			query	string	false	"Cursor of the page to return"
+//	@Param			sort_field		query	string	false	"Sort field: created_at, updated_at, priority or name"	default(created_at)
+//	@Param			sort_order		query	string	false	"Sort order, asc or desc"	default(desc)
+//	@Param			status			query	string	false	"Comma-separated task statuses"
+//	@Param			script_type		query	string	false	"Comma-separated script types"
+//	@Param			priority_min	query	int		false	"Minimum priority"
+//	@Param			priority_max	query	int		false	"Maximum priority"
+//	@Param			created_after	query	string	false	"Only tasks created at or after this RFC 3339 time"
+//	@Param			created_before	query	string	false	"Only tasks created before this RFC 3339 time"
+//	@Param			updated_after	query	string	false	"Only tasks updated at or after this RFC 3339 time"
+//	@Param			updated_before	query	string	false	"Only tasks updated before this RFC 3339 time"
+//	@Param			metadata		query	string	false	"JSON object the task metadata must contain"
+//	@Param			q				query	string	false	"Full-text search on the task name"
+//	@Param			selector		query	string	false	"Label selector, e.g. env=prod,team in (data,ml),!deprecated"
+//	@Success		200				{object}	models.TaskListResponse	"Tasks retrieved successfully"
+//	@Failure		400				{object}	models.ErrorResponse	"Invalid query parameters"
+//	@Failure		401				{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		429				{object}	models.ErrorResponse	"Rate limit exceeded"
 //	@Router			/tasks [get]
 func (h *TaskHandler) List(c *gin.Context) {
 	// Get user from context
@@ -221,11 +244,34 @@ func (h *TaskHandler) List(c *gin.Context) {
 		return
 	}
 
-	if useCursor {
-		// Use cursor-based pagination
-		tasks, paginationResp, err := h.taskRepo.GetByUserIDCursor(c.Request.Context(), user.ID, cursorReq)
+	filter, filtered, err := parseTaskFilter(c)
+	if err != nil {
+		h.logger.Warn("invalid task filter parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if useCursor || filtered {
+		// Filtered listings use cursor-based pagination
+		if c.Query("offset") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "offset can't be combined with cursor, sorting or filters",
+			})
+			return
+		}
+
+		filter.UserID = &user.ID
+		tasks, paginationResp, err := h.taskRepo.Search(c.Request.Context(), filter, cursorReq)
 		if err != nil {
-			h.logger.Error("failed to get user tasks with cursor", "error", err, "user_id", user.ID)
+			if errors.Is(err, database.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid cursor",
+				})
+				return
+			}
+			h.logger.Error("failed to search user tasks", "error", err, "user_id", user.ID)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to retrieve tasks",
 			})
@@ -248,7 +294,7 @@ func (h *TaskHandler) List(c *gin.Context) {
 		})
 	} else {
 		// Use offset-based pagination (legacy)
-		limit, offset, err := h.parsePagination(c)
+		limit, offset, err := parsePagination(c)
 		if err != nil {
 			h.logger.Warn("invalid offset pagination parameters", "error", err)
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -360,38 +406,23 @@ func (h *TaskHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Check if task is running (cannot update running tasks)
-	if task.Status == models.TaskStatusRunning {
-		h.logger.Warn("attempted to update running task", "task_id", taskID, "user_id", user.ID)
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "Cannot update running task",
-		})
+	if !checkIfMatch(c, task.Version) {
 		return
 	}
 
-	// Apply updates
-	if err := h.applyTaskUpdates(task, req); err != nil {
-		h.logger.Warn("task update validation failed", "error", err, "task_id", taskID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	// Update task in database
-	if err := h.taskRepo.Update(c.Request.Context(), task); err != nil {
-		h.logger.Error("failed to update task", "error", err, "task_id", taskID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update task",
-		})
+	findings, err := h.taskService.UpdateTask(c.Request.Context(), user, task, req)
+	if err != nil {
+		h.respondTaskError(c, err, user, "update")
 		return
 	}
 
-	h.logger.Info("task updated successfully", "task_id", taskID, "user_id", user.ID)
-	c.JSON(http.StatusOK, task.ToResponse())
+	response := task.ToResponse()
+	response.ScriptFindings = findings
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, response)
 }
 
-// Delete handles deleting a task
+// Delete handles deleting a task, which moves it to the trash
 func (h *TaskHandler) Delete(c *gin.Context) {
 	taskIDStr := c.Param("id")
 	taskID, err := uuid.Parse(taskIDStr)
@@ -440,190 +471,199 @@ func (h *TaskHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	// Check if task is running (cannot delete running tasks)
-	if task.Status == models.TaskStatusRunning {
-		h.logger.Warn("attempted to delete running task", "task_id", taskID, "user_id", user.ID)
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "Cannot delete running task",
-		})
+	if !checkIfMatch(c, task.Version) {
 		return
 	}
 
-	// Delete task from database
-	if err := h.taskRepo.Delete(c.Request.Context(), taskID); err != nil {
-		h.logger.Error("failed to delete task", "error", err, "task_id", taskID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete task",
-		})
+	if err := h.taskService.DeleteTask(c.Request.Context(), user, task); err != nil {
+		h.respondTaskError(c, err, user, "delete")
 		return
 	}
 
-	h.logger.Info("task deleted successfully", "task_id", taskID, "user_id", user.ID)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Task deleted successfully",
 	})
 }
 
-// validateCreateRequest validates the create task request
-func (h *TaskHandler) validateCreateRequest(req models.CreateTaskRequest) error {
-	if err := models.ValidateTaskName(req.Name); err != nil {
-		return err
-	}
-
-	if err := models.ValidateScriptType(req.ScriptType); err != nil {
-		return err
-	}
-
-	if err := models.ValidateScriptContent(req.ScriptContent); err != nil {
-		return err
-	}
-
-	if req.Priority != nil {
-		if err := models.ValidatePriority(*req.Priority); err != nil {
-			return err
-		}
+// ListImages handles the custom image inventory
+//
+//	@Summary		List task images
+//	@Description	Lists the custom images used by the user's tasks, grouped by the digest they are pinned to
+//	@Tags			Tasks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.ImageInventoryResponse	"Image inventory retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		429	{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/images [get]
+func (h *TaskHandler) ListImages(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
 	}
 
-	if req.TimeoutSeconds != nil {
-		if err := models.ValidateTimeout(*req.TimeoutSeconds); err != nil {
-			return err
-		}
+	images, err := h.taskRepo.GetImageInventory(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to get image inventory", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve image inventory",
+		})
+		return
 	}
 
-	return nil
+	c.JSON(http.StatusOK, models.ImageInventoryResponse{Images: images})
 }
 
-// applyTaskUpdates applies the update request to the task
-func (h *TaskHandler) applyTaskUpdates(task *models.Task, req models.UpdateTaskRequest) error {
-	if req.Name != nil {
-		if err := models.ValidateTaskName(*req.Name); err != nil {
-			return err
-		}
-		task.Name = *req.Name
+// ListLabels handles the label inventory
+//
+//	@Summary		List task labels
+//	@Description	Lists the labels on the user's tasks, with the number of tasks carrying each key and value
+//	@Tags			Tasks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.LabelInventoryResponse	"Label inventory retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		429	{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/labels [get]
+func (h *TaskHandler) ListLabels(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
 	}
 
-	if req.Description != nil {
-		task.Description = req.Description
+	labels, err := h.taskRepo.GetLabelInventory(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to get label inventory", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve label inventory",
+		})
+		return
 	}
 
-	if req.ScriptContent != nil {
-		if err := models.ValidateScriptContent(*req.ScriptContent); err != nil {
-			return err
-		}
-		task.ScriptContent = *req.ScriptContent
-	}
+	c.JSON(http.StatusOK, models.LabelInventoryResponse{Labels: labels})
+}
 
-	if req.ScriptType != nil {
-		if err := models.ValidateScriptType(*req.ScriptType); err != nil {
-			return err
-		}
-		task.ScriptType = *req.ScriptType
+// respondTaskError writes the response for a task the task service didn't
+// save. The action, such as "update", names the operation in the response
+// to unexpected errors.
+func (h *TaskHandler) respondTaskError(c *gin.Context, err error, user *models.User, action string) {
+	var validationErr *services.TaskValidationError
+	var analysisErr *analyzer.Error
+	var deniedErr *admission.DeniedError
+	var imageErr *services.TaskImageError
+
+	switch {
+	case errors.As(err, &validationErr):
+		h.logger.Warn("task "+action+" validation failed", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.As(err, &analysisErr):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:    "Validation failed",
+			Details:  "script failed security analysis",
+			Findings: analysisErr.Findings,
+		})
+	case errors.As(err, &deniedErr):
+		respondAdmissionDenied(c, deniedErr)
+	case errors.As(err, &imageErr):
+		h.respondImageError(c, imageErr.Err, user.ID)
+	case errors.Is(err, services.ErrTaskAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+	case errors.Is(err, services.ErrCannotUpdateRunningTask):
+		h.logger.Warn("attempted to update running task", "user_id", user.ID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Cannot update running task",
+		})
+	case errors.Is(err, services.ErrCannotDeleteRunningTask):
+		h.logger.Warn("attempted to delete running task", "user_id", user.ID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Cannot delete running task",
+		})
+	case errors.Is(err, database.ErrTaskVersionConflict):
+		h.logger.Warn("task modified concurrently", "user_id", user.ID)
+		respondVersionConflict(c, "Task")
+	case errors.Is(err, database.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Task not found",
+		})
+	default:
+		h.logger.Error("failed to "+action+" task", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to " + action + " task",
+		})
 	}
+}
 
-	if req.Priority != nil {
-		if err := models.ValidatePriority(*req.Priority); err != nil {
-			return err
-		}
-		task.Priority = *req.Priority
+// respondImageError writes the response for an image that could not be pinned
+func (h *TaskHandler) respondImageError(c *gin.Context, err error, userID uuid.UUID) {
+	switch {
+	case errors.Is(err, executor.ErrInvalidImage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image reference"})
+	case errors.Is(err, executor.ErrImageNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image repository is not allowed"})
+	case errors.Is(err, executor.ErrImageNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image not found in registry"})
+	case errors.Is(err, executor.ErrCustomImagesUnsupported):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Custom images are not supported"})
+	default:
+		h.logger.Error("failed to resolve task image", "error", err, "user_id", userID)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resolve image"})
+		return
 	}
+	h.logger.Warn("task image rejected", "error", err, "user_id", userID)
+}
 
-	if req.TimeoutSeconds != nil {
-		if err := models.ValidateTimeout(*req.TimeoutSeconds); err != nil {
-			return err
-		}
-		task.TimeoutSeconds = *req.TimeoutSeconds
+// admitTask evaluates the task against the admission policy that applies to
+// the user. Denied tasks are rejected with the policy's violations and false
+// is returned. Without an admission engine every task is admitted.
+func admitTask(c *gin.Context, engine *admission.Engine, logger *slog.Logger, user *models.User, task *models.Task) bool {
+	if engine == nil {
+		return true
 	}
 
-	if req.Metadata != nil {
-		task.Metadata = req.Metadata
+	decision := engine.Evaluate(user, task)
+	err := decision.Err()
+	if err == nil {
+		return true
 	}
 
-	return nil
+	logger.Warn("task denied by admission policy",
+		"task_id", task.ID, "user_id", user.ID, "policy", decision.Policy, "error", err)
+	respondAdmissionDenied(c, err.(*admission.DeniedError))
+	return false
 }
 
-// parsePagination parses pagination parameters from query string
-func (h *TaskHandler) parsePagination(c *gin.Context) (limit, offset int, err error) {
-	// Default values
-	limit = 20
-	offset = 0
-
-	// Parse limit
-	if limitStr := c.Query("limit"); limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid limit parameter: %w", err)
-		}
-		if limit < 1 || limit > 100 {
-			return 0, 0, fmt.Errorf("limit must be between 1 and 100")
-		}
-	}
-
-	// Parse offset
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid offset parameter: %w", err)
-		}
-		if offset < 0 {
-			return 0, 0, fmt.Errorf("offset must be non-negative")
-		}
-	}
-
-	return limit, offset, nil
+// respondAdmissionDenied writes the response for a task denied by its
+// admission policy, with the policy's violations
+func respondAdmissionDenied(c *gin.Context, err *admission.DeniedError) {
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Error:      "Denied by admission policy",
+		Details:    err.Error(),
+		Policy:     err.Policy,
+		Violations: err.Violations,
+	})
 }
 
-// parseCursorPagination parses cursor pagination parameters from query string
+// parseCursorPagination parses cursor pagination parameters from query string.
+// Cursor pagination is used when a cursor is given, and for sorted listings
+// so that the first page is sorted like the ones after it.
 func (h *TaskHandler) parseCursorPagination(c *gin.Context) (database.CursorPaginationRequest, bool, error) {
-	cursor := c.Query("cursor")
-	limitStr := c.Query("limit")
-	sortOrder := c.Query("sort_order")
-	sortField := c.Query("sort_field")
-
-	// Only use cursor pagination if a cursor is actually provided
-	if cursor == "" {
-		return database.CursorPaginationRequest{}, false, nil
-	}
-
-	req := database.CursorPaginationRequest{
-		Limit:     20,           // default
-		SortOrder: "desc",       // default
-		SortField: "created_at", // default
-	}
-
-	// Parse limit
-	if limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil {
-			return req, false, fmt.Errorf("invalid limit parameter: %w", err)
-		}
-		req.Limit = limit
-	}
-
-	// Parse cursor (already validated to be non-empty above)
-	req.Cursor = &cursor
-
-	// Parse sort order
-	if sortOrder != "" {
-		req.SortOrder = sortOrder
-	}
-
-	// Parse sort field
-	if sortField != "" {
-		// Validate sort field
-		validSortFields := map[string]bool{
-			"created_at": true,
-			"updated_at": true,
-			"priority":   true,
-			"name":       true,
-		}
-		if !validSortFields[sortField] {
-			return req, false, fmt.Errorf("invalid sort_field parameter: must be one of created_at, updated_at, priority, name")
-		}
-		req.SortField = sortField
+	req, err := parseListPagination(c, taskSortFields)
+	if err != nil {
+		return req, false, err
 	}
 
-	// Validate the request
-	database.ValidatePaginationRequest(&req)
-
-	return req, true, nil
+	used := req.Cursor != nil || c.Query("sort_field") != "" || c.Query("sort_order") != ""
+	return req, used, nil
 }
@@ -2,22 +2,27 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
 	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 // TaskExecutionServiceInterface defines the interface for task execution services
 type TaskExecutionServiceInterface interface {
 	CreateExecutionAndUpdateTaskStatus(ctx context.Context, taskID uuid.UUID, userID uuid.UUID) (*models.TaskExecution, error)
+	CreateRevisionExecution(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision int) (*models.TaskExecution, error)
 	CancelExecutionAndResetTaskStatus(ctx context.Context, executionID uuid.UUID, userID uuid.UUID) error
 	CompleteExecutionAndFinalizeTaskStatus(ctx context.Context, execution *models.TaskExecution, taskStatus models.TaskStatus, userID uuid.UUID) error
 }
@@ -25,17 +30,29 @@ type TaskExecutionServiceInterface interface {
 // TaskExecutionHandler handles task execution-related API endpoints
 type TaskExecutionHandler struct {
 	taskRepo         database.TaskRepository
+	revisionRepo     database.TaskRevisionRepository
 	executionRepo    database.TaskExecutionRepository
+	networkEventRepo database.NetworkEventRepository
 	executionService TaskExecutionServiceInterface
+	admission        *admission.Engine
+	outputStore      *executor.OutputStore
 	logger           *slog.Logger
 }
 
-// NewTaskExecutionHandler creates a new task execution handler
-func NewTaskExecutionHandler(taskRepo database.TaskRepository, executionRepo database.TaskExecutionRepository, executionService TaskExecutionServiceInterface, logger *slog.Logger) *TaskExecutionHandler {
+// NewTaskExecutionHandler creates a new task execution handler. The revision
+// repository provides the script of earlier task revisions that are run. The
+// admission engine re-evaluates tasks against the admission policies before
+// they are executed; when nil, every task is admitted. The output store serves
+// the full output of truncated executions; when nil, it can't be downloaded.
+func NewTaskExecutionHandler(taskRepo database.TaskRepository, revisionRepo database.TaskRevisionRepository, executionRepo database.TaskExecutionRepository, networkEventRepo database.NetworkEventRepository, executionService TaskExecutionServiceInterface, admissionEngine *admission.Engine, outputStore *executor.OutputStore, logger *slog.Logger) *TaskExecutionHandler {
 	return &TaskExecutionHandler{
 		taskRepo:         taskRepo,
+		revisionRepo:     revisionRepo,
 		executionRepo:    executionRepo,
+		networkEventRepo: networkEventRepo,
 		executionService: executionService,
+		admission:        admissionEngine,
+		outputStore:      outputStore,
 		logger:           logger,
 	}
 }
@@ -52,12 +69,48 @@ func NewTaskExecutionHandler(taskRepo database.TaskRepository, executionRepo dat
 //	@Success		201		{object}	models.TaskExecutionResponse	"Execution started successfully"
 //	@Failure		400		{object}	models.ErrorResponse			"Invalid task ID"
 //	@Failure		401		{object}	models.ErrorResponse			"Unauthorized"
-//	@Failure		403		{object}	models.ErrorResponse			"Forbidden"
+//	@Failure		403		{object}	models.ErrorResponse			"Access denied or denied by admission policy"
 //	@Failure		404		{object}	models.ErrorResponse			"Task not found"
 //	@Failure		409		{object}	models.ErrorResponse			"Task is already running"
 //	@Failure		429		{object}	models.ErrorResponse			"Rate limit exceeded"
 //	@Router			/tasks/{task_id}/executions [post]
 func (h *TaskExecutionHandler) Create(c *gin.Context) {
+	h.createExecution(c, nil)
+}
+
+// CreateForRevision handles running a specific revision of a task
+//
+//	@Summary		Run task revision
+//	@Description	Starts execution of the script of the specified task revision, which may be older than the task's current revision
+//	@Tags			Executions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string	true	"Task ID"
+//	@Param			revision	path		int		true	"Revision number"
+//	@Success		201			{object}	models.TaskExecutionResponse	"Execution started successfully"
+//	@Failure		400			{object}	models.ErrorResponse			"Invalid task ID or revision"
+//	@Failure		401			{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		403			{object}	models.ErrorResponse			"Access denied or denied by admission policy"
+//	@Failure		404			{object}	models.ErrorResponse			"Task or revision not found"
+//	@Failure		409			{object}	models.ErrorResponse			"Task is already running"
+//	@Failure		429			{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/tasks/{id}/revisions/{revision}/executions [post]
+func (h *TaskExecutionHandler) CreateForRevision(c *gin.Context) {
+	revision, err := parseRevision(c.Param("revision"))
+	if err != nil {
+		h.logger.Warn("invalid revision", "revision", c.Param("revision"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.createExecution(c, &revision)
+}
+
+// createExecution starts an execution of the task's current revision, or of
+// the given revision when it is not nil
+func (h *TaskExecutionHandler) createExecution(c *gin.Context, revision *int) {
 	taskIDStr := c.Param("id")
 	taskID, err := uuid.Parse(taskIDStr)
 	if err != nil {
@@ -78,8 +131,28 @@ func (h *TaskExecutionHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// Policies may have changed since the task was saved. Lookup failures and
+	// foreign tasks are left to the service, which reports them.
+	if h.admission != nil {
+		task, err := h.taskRepo.GetByID(c.Request.Context(), taskID)
+		if err == nil && task.UserID == user.ID {
+			// Admit the script type of the revision that will actually run
+			if atRevision, err := database.TaskAtRevision(c.Request.Context(), h.revisionRepo, task, revision); err == nil {
+				task = atRevision
+			}
+			if !admitTask(c, h.admission, h.logger, user, task) {
+				return
+			}
+		}
+	}
+
 	// Use service layer to atomically create execution and update task status
-	execution, err := h.executionService.CreateExecutionAndUpdateTaskStatus(c.Request.Context(), taskID, user.ID)
+	var execution *models.TaskExecution
+	if revision != nil {
+		execution, err = h.executionService.CreateRevisionExecution(c.Request.Context(), taskID, user.ID, *revision)
+	} else {
+		execution, err = h.executionService.CreateExecutionAndUpdateTaskStatus(c.Request.Context(), taskID, user.ID)
+	}
 	if err != nil {
 		h.logger.Error("failed to create execution and update task status", "error", err, "task_id", taskID, "user_id", user.ID)
 
@@ -89,6 +162,10 @@ func (h *TaskExecutionHandler) Create(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Task not found",
 			})
+		case "revision not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Revision not found",
+			})
 		case "access denied: task does not belong to user":
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Access denied",
@@ -121,10 +198,12 @@ func (h *TaskExecutionHandler) Create(c *gin.Context) {
 	}
 
 	h.logger.Info("task execution created successfully", "execution_id", execution.ID, "task_id", taskID, "user_id", user.ID)
+	setETag(c, execution.Version)
 	c.JSON(http.StatusCreated, execution.ToResponse())
 }
 
-// GetByID handles retrieving a task execution by ID
+// GetByID handles retrieving a task execution by ID. Its output is only
+// returned with include=output.
 func (h *TaskExecutionHandler) GetByID(c *gin.Context) {
 	executionIDStr := c.Param("id")
 	executionID, err := uuid.Parse(executionIDStr)
@@ -136,6 +215,19 @@ func (h *TaskExecutionHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	includeOutput := false
+	if include := c.Query("include"); include != "" {
+		for _, field := range strings.Split(include, ",") {
+			if strings.TrimSpace(field) != "output" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "include must be output",
+				})
+				return
+			}
+			includeOutput = true
+		}
+	}
+
 	// Get user from context
 	user := middleware.GetUserFromContext(c)
 	if user == nil {
@@ -166,10 +258,7 @@ func (h *TaskExecutionHandler) GetByID(c *gin.Context) {
 	// Get task to verify ownership
 	task, err := h.taskRepo.GetByID(c.Request.Context(), execution.TaskID)
 	if err != nil {
-		h.logger.Error("failed to get task for execution", "error", err, "task_id", execution.TaskID)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve task",
-		})
+		h.respondExecutionTaskError(c, err, execution)
 		return
 	}
 
@@ -183,10 +272,358 @@ func (h *TaskExecutionHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if includeOutput {
+		if err := h.executionRepo.LoadOutputs(c.Request.Context(), []*models.TaskExecution{execution}); err != nil {
+			h.logger.Error("failed to load execution output", "error", err, "execution_id", executionID)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve execution",
+			})
+			return
+		}
+	}
+
 	h.logger.Debug("execution retrieved successfully", "execution_id", executionID, "user_id", user.ID)
+	setETag(c, execution.Version)
 	c.JSON(http.StatusOK, execution.ToResponse())
 }
 
+// GetOutput handles reading a range of the output of an execution
+//
+//	@Summary		Read execution output
+//	@Description	Reads a range of bytes of an output stream of an execution, also while it runs. The range ends before a character cut at its end; the next range starts at next_offset.
+//	@Tags			Executions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Execution ID"
+//	@Param			stream	query		string	false	"Output stream"					Enums(stdout, stderr)	default(stdout)
+//	@Param			offset	query		int		false	"Byte offset to read from"		default(0)
+//	@Param			limit	query		int		false	"Maximum number of bytes (1-1048576)"	default(65536)
+//	@Success		200		{object}	models.ExecutionOutputResponse	"Output range retrieved successfully"
+//	@Failure		400		{object}	models.ErrorResponse			"Invalid execution ID, stream, offset or limit"
+//	@Failure		401		{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse			"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse			"Execution not found"
+//	@Failure		410		{object}	models.ErrorResponse			"Execution output expired"
+//	@Router			/executions/{id}/output [get]
+func (h *TaskExecutionHandler) GetOutput(c *gin.Context) {
+	executionIDStr := c.Param("id")
+	executionID, err := uuid.Parse(executionIDStr)
+	if err != nil {
+		h.logger.Warn("invalid execution ID", "execution_id", executionIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid execution ID format",
+		})
+		return
+	}
+
+	stream := c.DefaultQuery("stream", executor.OutputStreamStdout)
+	if !executor.IsOutputStream(stream) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "stream must be stdout or stderr",
+		})
+		return
+	}
+
+	var offset int64
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err = strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "offset must be a non-negative integer",
+			})
+			return
+		}
+	}
+
+	limit := int64(models.DefaultExecutionOutputLimit)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 1 || limit > models.MaxExecutionOutputLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("limit must be between 1 and %d", models.MaxExecutionOutputLimit),
+			})
+			return
+		}
+	}
+
+	// Get user from context
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	// Get execution from database
+	execution, err := h.executionRepo.GetByID(c.Request.Context(), executionID)
+	if err != nil {
+		if err == database.ErrExecutionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Execution not found",
+			})
+			return
+		}
+		h.logger.Error("failed to get execution", "error", err, "execution_id", executionID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve execution",
+		})
+		return
+	}
+
+	// Get task to verify ownership
+	task, err := h.taskRepo.GetByID(c.Request.Context(), execution.TaskID)
+	if err != nil {
+		h.respondExecutionTaskError(c, err, execution)
+		return
+	}
+
+	if task.UserID != user.ID {
+		h.logger.Warn("user attempted to access another user's execution output",
+			"user_id", user.ID, "execution_id", executionID, "task_owner_id", task.UserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	if execution.OutputExpiredAt != nil {
+		c.JSON(http.StatusGone, gin.H{
+			"error": "Execution output expired",
+		})
+		return
+	}
+
+	output, err := h.executionRepo.GetOutput(c.Request.Context(), executionID, stream, offset, limit)
+	if err != nil {
+		h.logger.Error("failed to get execution output", "error", err, "execution_id", executionID, "stream", stream)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve output",
+		})
+		return
+	}
+
+	nextOffset := output.Offset + int64(len(output.Data))
+	c.JSON(http.StatusOK, models.ExecutionOutputResponse{
+		Stream:     output.Stream,
+		Offset:     output.Offset,
+		NextOffset: nextOffset,
+		Size:       output.Size,
+		Data:       string(output.Data),
+		// More output may still be appended while the execution runs
+		Complete: execution.IsTerminal() && nextOffset >= output.Size,
+	})
+}
+
+// NetworkEvents handles listing the connection attempts of an execution
+//
+//	@Summary		List execution network events
+//	@Description	Lists the connection attempts an execution made through its egress proxy, in the order they were made
+//	@Tags			Executions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Execution ID"
+//	@Param			limit	query		int		false	"Maximum number of events (1-1000)"	default(100)
+//	@Success		200		{object}	models.NetworkEventListResponse	"Network events retrieved successfully"
+//	@Failure		400		{object}	models.ErrorResponse			"Invalid execution ID or limit"
+//	@Failure		401		{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse			"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse			"Execution not found"
+//	@Router			/executions/{id}/network-events [get]
+func (h *TaskExecutionHandler) NetworkEvents(c *gin.Context) {
+	executionIDStr := c.Param("id")
+	executionID, err := uuid.Parse(executionIDStr)
+	if err != nil {
+		h.logger.Warn("invalid execution ID", "execution_id", executionIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid execution ID format",
+		})
+		return
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+	}
+
+	// Get user from context
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	// Get execution from database
+	execution, err := h.executionRepo.GetByID(c.Request.Context(), executionID)
+	if err != nil {
+		if err == database.ErrExecutionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Execution not found",
+			})
+			return
+		}
+		h.logger.Error("failed to get execution", "error", err, "execution_id", executionID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve execution",
+		})
+		return
+	}
+
+	// Get task to verify ownership
+	task, err := h.taskRepo.GetByID(c.Request.Context(), execution.TaskID)
+	if err != nil {
+		h.respondExecutionTaskError(c, err, execution)
+		return
+	}
+
+	if task.UserID != user.ID {
+		h.logger.Warn("user attempted to access another user's network events",
+			"user_id", user.ID, "execution_id", executionID, "task_owner_id", task.UserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	events, err := h.networkEventRepo.GetByExecutionID(c.Request.Context(), executionID, limit)
+	if err != nil {
+		h.logger.Error("failed to get network events", "error", err, "execution_id", executionID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve network events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NetworkEventListResponse{
+		Events: events,
+		Total:  len(events),
+	})
+}
+
+// Output handles downloading the full output of a truncated execution
+//
+//	@Summary		Download execution output
+//	@Description	Downloads the full output stream of an execution whose output was truncated, when output spilling is enabled. Supports range requests.
+//	@Tags			Executions
+//	@Produce		plain
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Execution ID"
+//	@Param			stream	path		string	true	"Output stream"	Enums(stdout, stderr)
+//	@Success		200		{string}	string					"Full output stream"
+//	@Failure		400		{object}	models.ErrorResponse	"Invalid execution ID or stream"
+//	@Failure		401		{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse	"Execution or spilled output not found"
+//	@Router			/executions/{id}/output/{stream} [get]
+func (h *TaskExecutionHandler) Output(c *gin.Context) {
+	executionIDStr := c.Param("id")
+	executionID, err := uuid.Parse(executionIDStr)
+	if err != nil {
+		h.logger.Warn("invalid execution ID", "execution_id", executionIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid execution ID format",
+		})
+		return
+	}
+
+	stream := c.Param("stream")
+	if !executor.IsOutputStream(stream) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "stream must be stdout or stderr",
+		})
+		return
+	}
+
+	// Get user from context
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	// Get execution from database
+	execution, err := h.executionRepo.GetByID(c.Request.Context(), executionID)
+	if err != nil {
+		if err == database.ErrExecutionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Execution not found",
+			})
+			return
+		}
+		h.logger.Error("failed to get execution", "error", err, "execution_id", executionID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve execution",
+		})
+		return
+	}
+
+	// Get task to verify ownership
+	task, err := h.taskRepo.GetByID(c.Request.Context(), execution.TaskID)
+	if err != nil {
+		h.respondExecutionTaskError(c, err, execution)
+		return
+	}
+
+	if task.UserID != user.ID {
+		h.logger.Warn("user attempted to access another user's execution output",
+			"user_id", user.ID, "execution_id", executionID, "task_owner_id", task.UserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	if h.outputStore == nil || !execution.Truncated {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Spilled output not found",
+		})
+		return
+	}
+
+	file, err := h.outputStore.Open(executionID, stream)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Spilled output not found",
+			})
+			return
+		}
+		h.logger.Error("failed to open spilled output", "error", err, "execution_id", executionID, "stream", stream)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve output",
+		})
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		h.logger.Error("failed to stat spilled output", "error", err, "execution_id", executionID, "stream", stream)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve output",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.log", executionID, stream)
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	http.ServeContent(c.Writer, c.Request, filename, info.ModTime(), file)
+}
+
 // ListByTaskID handles listing executions for a specific task
 func (h *TaskExecutionHandler) ListByTaskID(c *gin.Context) {
 	taskIDStr := c.Param("id")
@@ -236,8 +673,31 @@ func (h *TaskExecutionHandler) ListByTaskID(c *gin.Context) {
 		return
 	}
 
+	filter, filtered, err := parseExecutionFilter(c)
+	if err != nil {
+		h.logger.Warn("invalid execution filter parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if filtered || c.Query("cursor") != "" || c.Query("sort_field") != "" || c.Query("sort_order") != "" {
+		// Filtered listings use cursor-based pagination
+		if c.Query("offset") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "offset can't be combined with cursor, sorting or filters",
+			})
+			return
+		}
+
+		filter.TaskID = &taskID
+		h.searchExecutions(c, user, filter)
+		return
+	}
+
 	// Parse pagination parameters
-	limit, offset, err := h.parsePagination(c)
+	limit, offset, err := parsePagination(c)
 	if err != nil {
 		h.logger.Warn("invalid pagination parameters", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -281,6 +741,97 @@ func (h *TaskExecutionHandler) ListByTaskID(c *gin.Context) {
 	})
 }
 
+// List handles listing the executions of all the user's tasks
+//
+//	@Summary		List executions
+//	@Description	Retrieves the executions of all tasks owned by the authenticated user, newest first, using cursor-based pagination
+//	@Tags			Executions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			task_id			query		string	false	"Only executions of this task"
+//	@Param			status			query		string	false	"Comma-separated execution statuses"
+//	@Param			exit_code		query		int		false	"Exit code of the script"
+//	@Param			duration_min_ms	query		int		false	"Minimum execution time in milliseconds"
+//	@Param			duration_max_ms	query		int		false	"Maximum execution time in milliseconds"
+//	@Param			created_after	query		string	false	"Only executions created at or after this RFC 3339 time"
+//	@Param			created_before	query		string	false	"Only executions created before this RFC 3339 time"
+//	@Param			selector		query		string	false	"Label selector on the executed tasks, e.g. env=prod,team in (data,ml)"
+//	@Param			sort_order		query		string	false	"Sort order, asc or desc"	default(desc)
+//	@Param			cursor			query		string	false	"Cursor of the page to return"
+//	@Param			limit			query		int		false	"Maximum number of executions to return"	default(20)
+//	@Success		200				{object}	models.ExecutionSearchResponse	"Executions retrieved successfully"
+//	@Failure		400				{object}	models.ErrorResponse			"Invalid query parameters"
+//	@Failure		401				{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		429				{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/executions [get]
+func (h *TaskExecutionHandler) List(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	filter, _, err := parseExecutionFilter(c)
+	if err == nil {
+		filter.TaskID, err = queryUUID(c, "task_id")
+	}
+	if err != nil {
+		h.logger.Warn("invalid execution filter parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Tasks of other users are filtered out by the user ID, so the task ID
+	// needs no ownership check
+	filter.UserID = &user.ID
+	h.searchExecutions(c, user, filter)
+}
+
+// searchExecutions responds with a page of the executions matching the filter
+func (h *TaskExecutionHandler) searchExecutions(c *gin.Context, user *models.User, filter database.ExecutionFilter) {
+	cursorReq, err := parseListPagination(c, executionSortFields)
+	if err != nil {
+		h.logger.Warn("invalid cursor pagination parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	executions, paginationResp, err := h.executionRepo.Search(c.Request.Context(), filter, cursorReq)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid cursor",
+			})
+			return
+		}
+		h.logger.Error("failed to search executions", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve executions",
+		})
+		return
+	}
+
+	executionResponses := make([]models.TaskExecutionResponse, len(executions))
+	for i, execution := range executions {
+		executionResponses[i] = execution.ToResponse()
+	}
+
+	h.logger.Debug("executions retrieved successfully with cursor", "user_id", user.ID, "count", len(executions))
+	c.JSON(http.StatusOK, gin.H{
+		"executions": executionResponses,
+		"pagination": paginationResp,
+		"limit":      cursorReq.Limit,
+		"sort_order": cursorReq.SortOrder,
+	})
+}
+
 // Cancel handles canceling a task execution
 func (h *TaskExecutionHandler) Cancel(c *gin.Context) {
 	executionIDStr := c.Param("id")
@@ -303,6 +854,10 @@ func (h *TaskExecutionHandler) Cancel(c *gin.Context) {
 		return
 	}
 
+	if c.GetHeader("If-Match") != "" && !h.checkExecutionIfMatch(c, executionID, user.ID) {
+		return
+	}
+
 	// Use service layer to atomically cancel execution and reset task status
 	err = h.executionService.CancelExecutionAndResetTaskStatus(c.Request.Context(), executionID, user.ID)
 	if err != nil {
@@ -395,6 +950,10 @@ func (h *TaskExecutionHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, execution.Version) {
+		return
+	}
+
 	// Apply updates to execution
 	if err := h.applyExecutionUpdates(execution, req); err != nil {
 		h.logger.Warn("execution update validation failed", "error", err, "execution_id", executionID)
@@ -425,6 +984,11 @@ func (h *TaskExecutionHandler) Update(c *gin.Context) {
 
 		err = h.executionService.CompleteExecutionAndFinalizeTaskStatus(c.Request.Context(), execution, taskStatus, user.ID)
 		if err != nil {
+			if errors.Is(err, database.ErrExecutionVersionConflict) {
+				h.logger.Warn("execution modified concurrently", "execution_id", executionID, "user_id", user.ID)
+				respondVersionConflict(c, "Execution")
+				return
+			}
 			h.logger.Error("failed to complete execution and finalize task status", "error", err, "execution_id", executionID, "user_id", user.ID)
 
 			// Map service errors to appropriate HTTP status codes
@@ -455,10 +1019,7 @@ func (h *TaskExecutionHandler) Update(c *gin.Context) {
 		// First verify user has access to this execution
 		task, err := h.taskRepo.GetByID(c.Request.Context(), execution.TaskID)
 		if err != nil {
-			h.logger.Error("failed to get task for execution", "error", err, "task_id", execution.TaskID)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to retrieve task",
-			})
+			h.respondExecutionTaskError(c, err, execution)
 			return
 		}
 
@@ -473,6 +1034,11 @@ func (h *TaskExecutionHandler) Update(c *gin.Context) {
 
 		// Simple execution update without task status change
 		if err := h.executionRepo.Update(c.Request.Context(), execution); err != nil {
+			if errors.Is(err, database.ErrExecutionVersionConflict) {
+				h.logger.Warn("execution modified concurrently", "execution_id", executionID, "user_id", user.ID)
+				respondVersionConflict(c, "Execution")
+				return
+			}
 			h.logger.Error("failed to update execution", "error", err, "execution_id", executionID)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to update execution",
@@ -482,9 +1048,40 @@ func (h *TaskExecutionHandler) Update(c *gin.Context) {
 	}
 
 	h.logger.Info("execution updated successfully", "execution_id", executionID, "user_id", user.ID)
+	setETag(c, execution.Version)
 	c.JSON(http.StatusOK, execution.ToResponse())
 }
 
+// checkExecutionIfMatch checks the If-Match header of a cancellation against
+// the execution's version. Executions that are missing or not the user's are
+// left to the service, so that they get the same response as without the header.
+func (h *TaskExecutionHandler) checkExecutionIfMatch(c *gin.Context, executionID, userID uuid.UUID) bool {
+	execution, err := h.executionRepo.GetByID(c.Request.Context(), executionID)
+	if err != nil {
+		return true
+	}
+	task, err := h.taskRepo.GetByID(c.Request.Context(), execution.TaskID)
+	if err != nil || task.UserID != userID {
+		return true
+	}
+	return checkIfMatch(c, execution.Version)
+}
+
+// respondExecutionTaskError responds to a failure to get the task of an
+// execution. The executions of a task in the trash are hidden with it.
+func (h *TaskExecutionHandler) respondExecutionTaskError(c *gin.Context, err error, execution *models.TaskExecution) {
+	if errors.Is(err, database.ErrTaskNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Execution not found",
+		})
+		return
+	}
+	h.logger.Error("failed to get task for execution", "error", err, "task_id", execution.TaskID)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to retrieve task",
+	})
+}
+
 // applyExecutionUpdates applies the update request to the execution
 func (h *TaskExecutionHandler) applyExecutionUpdates(execution *models.TaskExecution, req models.UpdateTaskExecutionRequest) error {
 	if req.Status != nil {
@@ -524,34 +1121,3 @@ func (h *TaskExecutionHandler) applyExecutionUpdates(execution *models.TaskExecu
 
 	return nil
 }
-
-// parsePagination parses pagination parameters from query string
-func (h *TaskExecutionHandler) parsePagination(c *gin.Context) (limit, offset int, err error) {
-	// Default values
-	limit = 20
-	offset = 0
-
-	// Parse limit
-	if limitStr := c.Query("limit"); limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid limit parameter: %w", err)
-		}
-		if limit < 1 || limit > 100 {
-			return 0, 0, fmt.Errorf("limit must be between 1 and 100")
-		}
-	}
-
-	// Parse offset
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid offset parameter: %w", err)
-		}
-		if offset < 0 {
-			return 0, 0, fmt.Errorf("offset must be non-negative")
-		}
-	}
-
-	return limit, offset, nil
-}
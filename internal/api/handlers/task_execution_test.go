@@ -18,7 +18,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -74,6 +76,34 @@ func (m *MockTaskExecutionRepository) UpdateStatus(ctx context.Context, id uuid.
 	return args.Error(0)
 }
 
+func (m *MockTaskExecutionRepository) AppendOutput(ctx context.Context, id uuid.UUID, stdout, stderr string) error {
+	args := m.Called(ctx, id, stdout, stderr)
+	return args.Error(0)
+}
+
+func (m *MockTaskExecutionRepository) SetLease(ctx context.Context, id uuid.UUID, leaseToken string) error {
+	args := m.Called(ctx, id, leaseToken)
+	return args.Error(0)
+}
+
+func (m *MockTaskExecutionRepository) HoldsLease(ctx context.Context, id uuid.UUID, leaseToken string) (bool, error) {
+	args := m.Called(ctx, id, leaseToken)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) LoadOutputs(ctx context.Context, executions []*models.TaskExecution) error {
+	args := m.Called(ctx, executions)
+	return args.Error(0)
+}
+
+func (m *MockTaskExecutionRepository) GetOutput(ctx context.Context, id uuid.UUID, stream string, offset, limit int64) (*database.ExecutionOutput, error) {
+	args := m.Called(ctx, id, stream, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ExecutionOutput), args.Error(1)
+}
+
 func (m *MockTaskExecutionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -102,6 +132,42 @@ func (m *MockTaskExecutionRepository) CountByStatus(ctx context.Context, status
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockTaskExecutionRepository) GetBeyondKeepLast(ctx context.Context, keepLast, limit int) ([]*models.TaskExecution, error) {
+	args := m.Called(ctx, keepLast, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) CountBeyondKeepLast(ctx context.Context, keepLast int) (database.RetentionCount, error) {
+	args := m.Called(ctx, keepLast)
+	return args.Get(0).(database.RetentionCount), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) GetOutputBefore(ctx context.Context, before time.Time, limit int) ([]*models.TaskExecution, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) CountOutputBefore(ctx context.Context, before time.Time) (database.RetentionCount, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(database.RetentionCount), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) ExpireOutput(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Cursor-based pagination methods
 func (m *MockTaskExecutionRepository) GetByTaskIDCursor(ctx context.Context, taskID uuid.UUID, req database.CursorPaginationRequest) ([]*models.TaskExecution, database.CursorPaginationResponse, error) {
 	args := m.Called(ctx, taskID, req)
@@ -127,6 +193,14 @@ func (m *MockTaskExecutionRepository) ListCursor(ctx context.Context, req databa
 	return args.Get(0).([]*models.TaskExecution), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
 }
 
+func (m *MockTaskExecutionRepository) Search(ctx context.Context, filter database.ExecutionFilter, req database.CursorPaginationRequest) ([]*models.TaskExecution, database.CursorPaginationResponse, error) {
+	args := m.Called(ctx, filter, req)
+	if args.Get(0) == nil {
+		return nil, database.CursorPaginationResponse{}, args.Error(2)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
+}
+
 // MockTaskExecutionService is a mock implementation of TaskExecutionServiceInterface
 type MockTaskExecutionService struct {
 	mock.Mock
@@ -140,6 +214,14 @@ func (m *MockTaskExecutionService) CreateExecutionAndUpdateTaskStatus(ctx contex
 	return args.Get(0).(*models.TaskExecution), args.Error(1)
 }
 
+func (m *MockTaskExecutionService) CreateRevisionExecution(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision int) (*models.TaskExecution, error) {
+	args := m.Called(ctx, taskID, userID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskExecution), args.Error(1)
+}
+
 func (m *MockTaskExecutionService) CancelExecutionAndResetTaskStatus(ctx context.Context, executionID uuid.UUID, userID uuid.UUID) error {
 	args := m.Called(ctx, executionID, userID)
 	return args.Error(0)
@@ -150,6 +232,161 @@ func (m *MockTaskExecutionService) CompleteExecutionAndFinalizeTaskStatus(ctx co
 	return args.Error(0)
 }
 
+// MockNetworkEventRepository is a mock implementation of NetworkEventRepository
+type MockNetworkEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockNetworkEventRepository) CreateBatch(ctx context.Context, executionID uuid.UUID, events []models.NetworkEvent) error {
+	args := m.Called(ctx, executionID, events)
+	return args.Error(0)
+}
+
+func (m *MockNetworkEventRepository) GetByExecutionID(ctx context.Context, executionID uuid.UUID, limit int) ([]models.NetworkEvent, error) {
+	args := m.Called(ctx, executionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NetworkEvent), args.Error(1)
+}
+
+func TestTaskExecutionHandler_List(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+
+	tests := []struct {
+		name       string
+		query      string
+		mockSetup  func(*MockTaskExecutionRepository)
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:  "executions of all the user's tasks",
+			query: "",
+			mockSetup: func(me *MockTaskExecutionRepository) {
+				next := "next-page"
+				executions := []*models.TaskExecution{
+					{
+						ID:        uuid.New(),
+						TaskID:    taskID,
+						Status:    models.ExecutionStatusCompleted,
+						CreatedAt: time.Now(),
+					},
+				}
+				me.On("Search", mock.Anything, database.ExecutionFilter{UserID: &userID},
+					database.CursorPaginationRequest{Limit: 20, SortOrder: "desc", SortField: "created_at"}).
+					Return(executions, database.CursorPaginationResponse{HasMore: true, NextCursor: &next}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "filtered by task and status",
+			query: "?task_id=" + taskID.String() + "&status=failed&created_after=2026-01-01T00:00:00Z&sort_order=asc&cursor=abc",
+			mockSetup: func(me *MockTaskExecutionRepository) {
+				cursor := "abc"
+				after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+				me.On("Search", mock.Anything, database.ExecutionFilter{
+					TaskID:       &taskID,
+					UserID:       &userID,
+					Statuses:     []models.ExecutionStatus{models.ExecutionStatusFailed},
+					CreatedAfter: &after,
+				}, database.CursorPaginationRequest{Limit: 20, Cursor: &cursor, SortOrder: "asc", SortField: "created_at"}).
+					Return([]*models.TaskExecution{}, database.CursorPaginationResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid task ID",
+			query:      "?task_id=invalid",
+			mockSetup:  func(me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid task_id parameter",
+		},
+		{
+			name:       "invalid sort field",
+			query:      "?sort_field=priority",
+			mockSetup:  func(me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid sort_field parameter: must be one of created_at",
+		},
+		{
+			name:  "label selector",
+			query: "?selector=team%20notin%20(web)",
+			mockSetup: func(me *MockTaskExecutionRepository) {
+				me.On("Search", mock.Anything, mock.MatchedBy(func(filter database.ExecutionFilter) bool {
+					return *filter.UserID == userID && filter.Selector.String() == "team notin (web)"
+				}), mock.Anything).Return([]*models.TaskExecution{}, database.CursorPaginationResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid label selector",
+			query:      "?selector=env%3E1",
+			mockSetup:  func(me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid label selector",
+		},
+		{
+			name:       "invalid exit code",
+			query:      "?exit_code=one",
+			mockSetup:  func(me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid exit_code parameter",
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=invalid",
+			mockSetup: func(me *MockTaskExecutionRepository) {
+				me.On("Search", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, database.CursorPaginationResponse{}, fmt.Errorf("%w: bad encoding", database.ErrInvalidCursor))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Invalid cursor",
+		},
+		{
+			name:  "database error",
+			query: "",
+			mockSetup: func(me *MockTaskExecutionRepository) {
+				me.On("Search", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, database.CursorPaginationResponse{}, errors.New("database query failed"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "Failed to retrieve executions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockTaskRepo, mockExecutionRepo, _, handler := setupTaskExecutionHandlerTest()
+			tt.mockSetup(mockExecutionRepo)
+
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}, Email: "test@example.com"})
+				c.Next()
+			})
+			router.GET("/executions", handler.List)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/executions"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.wantError != "" {
+				assert.Contains(t, response["error"], tt.wantError)
+			} else {
+				assert.Contains(t, response, "executions")
+				assert.Contains(t, response, "pagination")
+			}
+
+			mockTaskRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+			mockExecutionRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func setupTaskExecutionHandlerTest() (*gin.Engine, *MockTaskRepository, *MockTaskExecutionRepository, *MockTaskExecutionService, *TaskExecutionHandler) {
 	gin.SetMode(gin.TestMode)
 
@@ -157,7 +394,7 @@ func setupTaskExecutionHandlerTest() (*gin.Engine, *MockTaskRepository, *MockTas
 	mockExecutionRepo := new(MockTaskExecutionRepository)
 	mockExecutionService := new(MockTaskExecutionService)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	handler := NewTaskExecutionHandler(mockTaskRepo, mockExecutionRepo, mockExecutionService, logger)
+	handler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, nil, mockExecutionService, nil, nil, logger)
 
 	router := gin.New()
 	// Add middleware to set user context
@@ -284,6 +521,145 @@ func TestTaskExecutionHandler_Create(t *testing.T) {
 	}
 }
 
+func TestTaskExecutionHandler_CreateForRevision(t *testing.T) {
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		revision   string
+		mockSetup  func(*MockTaskExecutionService)
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:     "successful execution creation",
+			revision: "2",
+			mockSetup: func(ms *MockTaskExecutionService) {
+				revision := 2
+				ms.On("CreateRevisionExecution", mock.Anything, taskID, userID, 2).Return(&models.TaskExecution{
+					ID:       uuid.New(),
+					TaskID:   taskID,
+					Status:   models.ExecutionStatusPending,
+					Revision: &revision,
+				}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid revision",
+			revision:   "latest",
+			mockSetup:  func(ms *MockTaskExecutionService) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid revision",
+		},
+		{
+			name:     "revision not found",
+			revision: "9",
+			mockSetup: func(ms *MockTaskExecutionService) {
+				ms.On("CreateRevisionExecution", mock.Anything, taskID, userID, 9).Return(nil, fmt.Errorf("revision not found"))
+			},
+			wantStatus: http.StatusNotFound,
+			wantError:  "Revision not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, _, mockExecutionService, handler := setupTaskExecutionHandlerTest()
+			tt.mockSetup(mockExecutionService)
+
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+				c.Next()
+			})
+			router.POST("/tasks/:id/revisions/:revision/executions", handler.CreateForRevision)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%s/revisions/%s/executions", taskID, tt.revision), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantError != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Contains(t, response["error"], tt.wantError)
+			} else {
+				var response models.TaskExecutionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				require.NotNil(t, response.Revision)
+				assert.Equal(t, 2, *response.Revision)
+			}
+
+			mockExecutionService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskExecutionHandler_CreateWithAdmission(t *testing.T) {
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	engine, err := admission.NewEngine(admission.File{
+		Policies: []admission.Policy{{
+			Name:  "python-only",
+			Rules: admission.Rules{ScriptTypes: []models.ScriptType{models.ScriptTypePython}},
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		scriptType models.ScriptType
+		wantStatus int
+	}{
+		{name: "admitted task is executed", scriptType: models.ScriptTypePython, wantStatus: http.StatusCreated},
+		{name: "denied task is not executed", scriptType: models.ScriptTypeBash, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockTaskRepo, _, mockExecutionService, handler := setupTaskExecutionHandlerTest()
+			handler.admission = engine
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+				c.Next()
+			})
+
+			mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(&models.Task{
+				BaseModel:     models.BaseModel{ID: taskID},
+				UserID:        userID,
+				ScriptContent: "echo hello",
+				ScriptType:    tt.scriptType,
+			}, nil)
+			if tt.wantStatus == http.StatusCreated {
+				mockExecutionService.On("CreateExecutionAndUpdateTaskStatus", mock.Anything, taskID, userID).
+					Return(&models.TaskExecution{ID: uuid.New(), TaskID: taskID, Status: models.ExecutionStatusPending}, nil)
+			}
+
+			router.POST("/tasks/:id/executions", handler.Create)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%s/executions", taskID), nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockExecutionService.AssertExpectations(t)
+
+			if tt.wantStatus == http.StatusForbidden {
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "Denied by admission policy", response.Error)
+				assert.Equal(t, "python-only", response.Policy)
+				require.Len(t, response.Violations, 1)
+				assert.Equal(t, "script_types", response.Violations[0].Rule)
+			}
+		})
+	}
+}
+
 func TestTaskExecutionHandler_GetByID(t *testing.T) {
 	executionID := uuid.New()
 	taskID := uuid.New()
@@ -316,6 +692,26 @@ func TestTaskExecutionHandler_GetByID(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:        "execution with output",
+			executionID: executionID.String() + "?include=output",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository, ms *MockTaskExecutionService) {
+				execution := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCompleted}
+				me.On("GetByID", mock.Anything, executionID).Return(execution, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID}, nil)
+				me.On("LoadOutputs", mock.Anything, []*models.TaskExecution{execution}).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "unknown include",
+			executionID: executionID.String() + "?include=logs",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository, ms *MockTaskExecutionService) {
+				// No mock calls expected
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "include must be output",
+		},
 		{
 			name:        "invalid execution ID",
 			executionID: "invalid-uuid",
@@ -376,6 +772,306 @@ func TestTaskExecutionHandler_GetByID(t *testing.T) {
 	}
 }
 
+func TestTaskExecutionHandler_NetworkEvents(t *testing.T) {
+	executionID := uuid.New()
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	execution := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCompleted}
+	events := []models.NetworkEvent{
+		{ID: uuid.New(), ExecutionID: executionID, Host: "pypi.org", Port: 443, Allowed: true, Reason: "host allowed", OccurredAt: time.Now()},
+		{ID: uuid.New(), ExecutionID: executionID, Host: "169.254.169.254", Port: 80, Allowed: false, Reason: "address not allowed", OccurredAt: time.Now()},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		mockSetup  func(*MockTaskRepository, *MockTaskExecutionRepository, *MockNetworkEventRepository)
+		wantStatus int
+		wantEvents int
+	}{
+		{
+			name:  "lists events of own execution",
+			query: "?limit=50",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository, mn *MockNetworkEventRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(execution, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID}, nil)
+				mn.On("GetByExecutionID", mock.Anything, executionID, 50).Return(events, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantEvents: 2,
+		},
+		{
+			name: "rejects another user's execution",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository, mn *MockNetworkEventRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(execution, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: uuid.New()}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "rejects invalid limit",
+			query:      "?limit=5000",
+			mockSetup:  func(mt *MockTaskRepository, me *MockTaskExecutionRepository, mn *MockNetworkEventRepository) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockTaskRepo := new(MockTaskRepository)
+			mockExecutionRepo := new(MockTaskExecutionRepository)
+			mockNetworkEventRepo := new(MockNetworkEventRepository)
+			tt.mockSetup(mockTaskRepo, mockExecutionRepo, mockNetworkEventRepo)
+
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			handler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, mockNetworkEventRepo, new(MockTaskExecutionService), nil, nil, logger)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}, Email: "test@example.com"})
+				c.Next()
+			})
+			router.GET("/executions/:id/network-events", handler.NetworkEvents)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/executions/%s/network-events%s", executionID, tt.query), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var response models.NetworkEventListResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Len(t, response.Events, tt.wantEvents)
+				assert.Equal(t, tt.wantEvents, response.Total)
+				assert.False(t, response.Events[1].Allowed)
+			}
+
+			mockTaskRepo.AssertExpectations(t)
+			mockExecutionRepo.AssertExpectations(t)
+			mockNetworkEventRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskExecutionHandler_Output(t *testing.T) {
+	executionID := uuid.New()
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	outputStore := executor.NewOutputStore(t.TempDir())
+	file, err := outputStore.Create(executionID, executor.OutputStreamStdout)
+	require.NoError(t, err)
+	_, err = file.WriteString("the full output\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	truncated := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCompleted, Truncated: true}
+	ownTask := &models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID}
+
+	tests := []struct {
+		name        string
+		stream      string
+		outputStore *executor.OutputStore
+		mockSetup   func(*MockTaskRepository, *MockTaskExecutionRepository)
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:        "downloads spilled output",
+			stream:      "stdout",
+			outputStore: outputStore,
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(truncated, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(ownTask, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "the full output\n",
+		},
+		{
+			name:        "stream without spilled output",
+			stream:      "stderr",
+			outputStore: outputStore,
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(truncated, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(ownTask, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "spilling disabled",
+			stream: "stdout",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(truncated, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(ownTask, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "rejects another user's execution",
+			stream:      "stdout",
+			outputStore: outputStore,
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(truncated, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: uuid.New()}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:        "rejects unknown stream",
+			stream:      "stdin",
+			outputStore: outputStore,
+			mockSetup:   func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockTaskRepo := new(MockTaskRepository)
+			mockExecutionRepo := new(MockTaskExecutionRepository)
+			tt.mockSetup(mockTaskRepo, mockExecutionRepo)
+
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			handler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, nil, new(MockTaskExecutionService), nil, tt.outputStore, logger)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}, Email: "test@example.com"})
+				c.Next()
+			})
+			router.GET("/executions/:id/output/:stream", handler.Output)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/executions/%s/output/%s", executionID, tt.stream), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+			}
+
+			mockTaskRepo.AssertExpectations(t)
+			mockExecutionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskExecutionHandler_GetOutput(t *testing.T) {
+	executionID := uuid.New()
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	running := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusRunning}
+	completed := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCompleted}
+	expiredAt := time.Now()
+	expired := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCompleted, OutputExpiredAt: &expiredAt}
+	ownTask := &models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID}
+
+	tests := []struct {
+		name         string
+		query        string
+		mockSetup    func(*MockTaskRepository, *MockTaskExecutionRepository)
+		wantStatus   int
+		wantResponse models.ExecutionOutputResponse
+	}{
+		{
+			name:  "reads a range of stdout by default",
+			query: "?offset=6&limit=5",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(running, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(ownTask, nil)
+				me.On("GetOutput", mock.Anything, executionID, "stdout", int64(6), int64(5)).
+					Return(&database.ExecutionOutput{Stream: "stdout", Offset: 6, Data: []byte("world"), Size: 11}, nil)
+			},
+			wantStatus:   http.StatusOK,
+			wantResponse: models.ExecutionOutputResponse{Stream: "stdout", Offset: 6, NextOffset: 11, Size: 11, Data: "world"},
+		},
+		{
+			name:  "completes at the end of a finished execution",
+			query: "?stream=stderr",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(completed, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(ownTask, nil)
+				me.On("GetOutput", mock.Anything, executionID, "stderr", int64(0), int64(models.DefaultExecutionOutputLimit)).
+					Return(&database.ExecutionOutput{Stream: "stderr", Data: []byte("error\n"), Size: 6}, nil)
+			},
+			wantStatus:   http.StatusOK,
+			wantResponse: models.ExecutionOutputResponse{Stream: "stderr", NextOffset: 6, Size: 6, Data: "error\n", Complete: true},
+		},
+		{
+			name: "expired output",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(expired, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(ownTask, nil)
+			},
+			wantStatus: http.StatusGone,
+		},
+		{
+			name: "rejects another user's execution",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {
+				me.On("GetByID", mock.Anything, executionID).Return(running, nil)
+				mt.On("GetByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: uuid.New()}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "rejects unknown stream",
+			query:      "?stream=stdin",
+			mockSetup:  func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects negative offset",
+			query:      "?offset=-1",
+			mockSetup:  func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects too large limit",
+			query:      fmt.Sprintf("?limit=%d", models.MaxExecutionOutputLimit+1),
+			mockSetup:  func(mt *MockTaskRepository, me *MockTaskExecutionRepository) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockTaskRepo := new(MockTaskRepository)
+			mockExecutionRepo := new(MockTaskExecutionRepository)
+			tt.mockSetup(mockTaskRepo, mockExecutionRepo)
+
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			handler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, nil, new(MockTaskExecutionService), nil, nil, logger)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}, Email: "test@example.com"})
+				c.Next()
+			})
+			router.GET("/executions/:id/output", handler.GetOutput)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/executions/%s/output%s", executionID, tt.query), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var response models.ExecutionOutputResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantResponse, response)
+			}
+
+			mockTaskRepo.AssertExpectations(t)
+			mockExecutionRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestTaskExecutionHandler_ListByTaskID(t *testing.T) {
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -566,6 +1262,45 @@ func TestTaskExecutionHandler_ListByTaskID(t *testing.T) {
 			wantStatus: http.StatusInternalServerError,
 			wantError:  "Failed to count executions",
 		},
+		{
+			name:   "filtered execution listing",
+			taskID: taskID.String(),
+			query:  "?status=failed,timeout&exit_code=137&duration_min_ms=1000&limit=10",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository, ms *MockTaskExecutionService) {
+				task := &models.Task{
+					BaseModel: models.BaseModel{
+						ID: taskID,
+					},
+					UserID: userID,
+				}
+				returnCode, minDuration := 137, 1000
+				mt.On("GetByID", mock.Anything, taskID).Return(task, nil)
+				me.On("Search", mock.Anything, database.ExecutionFilter{
+					TaskID:        &taskID,
+					Statuses:      []models.ExecutionStatus{models.ExecutionStatusFailed, models.ExecutionStatusTimeout},
+					ReturnCode:    &returnCode,
+					MinDurationMs: &minDuration,
+				}, database.CursorPaginationRequest{Limit: 10, SortOrder: "desc", SortField: "created_at"}).
+					Return([]*models.TaskExecution{}, database.CursorPaginationResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "invalid filter - duration range",
+			taskID: taskID.String(),
+			query:  "?duration_min_ms=5000&duration_max_ms=10",
+			mockSetup: func(mt *MockTaskRepository, me *MockTaskExecutionRepository, ms *MockTaskExecutionService) {
+				task := &models.Task{
+					BaseModel: models.BaseModel{
+						ID: taskID,
+					},
+					UserID: userID,
+				}
+				mt.On("GetByID", mock.Anything, taskID).Return(task, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "duration_min_ms must not be greater than duration_max_ms",
+		},
 	}
 
 	for _, tt := range tests {
@@ -618,7 +1353,7 @@ func TestTaskExecutionHandler_ListByTaskID_NoUserContext(t *testing.T) {
 	mockExecutionRepo := new(MockTaskExecutionRepository)
 	mockExecutionService := new(MockTaskExecutionService)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	handler := NewTaskExecutionHandler(mockTaskRepo, mockExecutionRepo, mockExecutionService, logger)
+	handler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, nil, mockExecutionService, nil, nil, logger)
 
 	router := gin.New()
 	// Deliberately NOT setting user context to test unauthorized scenario
@@ -760,7 +1495,7 @@ func TestTaskExecutionHandler_Cancel_NoUserContext(t *testing.T) {
 	mockExecutionRepo := new(MockTaskExecutionRepository)
 	mockExecutionService := new(MockTaskExecutionService)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	handler := NewTaskExecutionHandler(mockTaskRepo, mockExecutionRepo, mockExecutionService, logger)
+	handler := NewTaskExecutionHandler(mockTaskRepo, nil, mockExecutionRepo, nil, mockExecutionService, nil, nil, logger)
 
 	router := gin.New()
 	// Deliberately NOT setting user context to test unauthorized scenario
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/manifest"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxManifestBytes is the maximum size of a task manifest accepted for import
+const MaxManifestBytes = 8 * 1024 * 1024
+
+// Export handles exporting the user's tasks as a manifest
+//
+//	@Summary		Export tasks as a manifest
+//	@Description	Exports every task of the user as a task manifest, in YAML or JSON. Tasks without an external key are exported under their ID.
+//	@Tags			Tasks
+//	@Produce		json
+//	@Produce		application/yaml
+//	@Security		BearerAuth
+//	@Param			format	query		string					false	"Manifest format"	Enums(yaml, json)	default(yaml)
+//	@Success		200		{object}	models.TaskManifest		"Manifest of the user's tasks"
+//	@Failure		400		{object}	models.ErrorResponse	"Invalid format"
+//	@Failure		401		{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		429		{object}	models.ErrorResponse	"Rate limit exceeded"
+//	@Router			/tasks/export [get]
+func (h *TaskHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "yaml")
+	if format != "yaml" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid format: must be yaml or json",
+		})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	tasks, err := h.taskRepo.GetAllByUserID(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to get tasks for export", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export tasks",
+		})
+		return
+	}
+
+	exported := manifest.Export(tasks)
+	h.logger.Debug("tasks exported", "user_id", user.ID, "count", len(tasks))
+
+	if format == "json" {
+		c.JSON(http.StatusOK, exported)
+		return
+	}
+
+	data, err := yaml.Marshal(exported)
+	if err != nil {
+		h.logger.Error("failed to encode manifest", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export tasks",
+		})
+		return
+	}
+	c.Data(http.StatusOK, "application/yaml", data)
+}
+
+// Import handles reconciling the user's tasks with a manifest
+//
+//	@Summary		Import a task manifest
+//	@Description	Reconciles the user's tasks with a task manifest in YAML or JSON, matching tasks by their external key. In dry-run mode the action for each task is reported, in diff mode also the changed fields, and in apply mode the changes are made. With prune, tasks whose external key is not in the manifest are deleted. The manifest is rejected, and nothing is changed, when any task fails validation, script analysis or admission.
+//	@Tags			Tasks
+//	@Accept			json
+//	@Accept			application/yaml
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			mode		query		string						false	"Import mode"	Enums(dry-run, diff, apply)	default(dry-run)
+//	@Param			prune		query		bool						false	"Delete managed tasks missing from the manifest"
+//	@Param			manifest	body		models.TaskManifest			true	"Task manifest"
+//	@Success		200			{object}	models.TaskImportResponse	"Changes planned or applied"
+//	@Failure		400			{object}	models.TaskImportResponse	"Invalid manifest, or tasks rejected"
+//	@Failure		401			{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		413			{object}	models.ErrorResponse		"Manifest too large"
+//	@Failure		429			{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Router			/tasks/import [post]
+func (h *TaskHandler) Import(c *gin.Context) {
+	mode := models.TaskImportMode(c.DefaultQuery("mode", string(models.TaskImportModeDryRun)))
+	if err := models.ValidateTaskImportMode(mode); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	prune, err := strconv.ParseBool(c.DefaultQuery("prune", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid prune parameter: must be true or false",
+		})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Manifest too large",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	taskManifest, err := manifest.Parse(data)
+	if err == nil {
+		err = taskManifest.Validate()
+	}
+	if err != nil {
+		h.logger.Warn("invalid task manifest", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid manifest",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	existing, err := h.taskRepo.GetAllByUserID(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed to get tasks for import", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to import manifest",
+		})
+		return
+	}
+
+	changes := manifest.Plan(taskManifest, user.ID, existing, prune)
+	rejected := h.checkManifestChanges(c.Request.Context(), user, changes)
+
+	response := models.TaskImportResponse{
+		Mode:    mode,
+		Summary: manifest.Summarize(changes),
+		Changes: changes,
+	}
+	if mode != models.TaskImportModeDiff {
+		for i := range response.Changes {
+			response.Changes[i].Fields = nil
+		}
+	}
+
+	if rejected > 0 {
+		h.logger.Warn("task manifest rejected", "user_id", user.ID, "rejected", rejected, "mode", mode)
+		response.Error = "Manifest rejected"
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if mode == models.TaskImportModeApply {
+		if err := h.applyManifestChanges(c.Request.Context(), user, changes); err != nil {
+			h.logger.Error("failed to apply task manifest", "error", err, "user_id", user.ID)
+			response.Error = "Failed to apply manifest"
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		response.Applied = true
+	}
+
+	h.logger.Info("task manifest imported", "user_id", user.ID, "mode", mode, "prune", prune,
+		"create", response.Summary.Create, "update", response.Summary.Update, "delete", response.Summary.Delete)
+	c.JSON(http.StatusOK, response)
+}
+
+// checkManifestChanges checks the planned changes the way creating, updating
+// and deleting the tasks one by one would, and pins the images of created
+// and updated tasks. The reason a change can't be applied is set as its
+// error; the number of such changes is returned.
+func (h *TaskHandler) checkManifestChanges(ctx context.Context, user *models.User, changes []models.TaskManifestChange) int {
+	rejected := 0
+	for i := range changes {
+		change := &changes[i]
+
+		var err error
+		switch change.Action {
+		case models.TaskManifestActionCreate, models.TaskManifestActionUpdate:
+			err = h.checkManifestTask(ctx, user, change)
+		case models.TaskManifestActionDelete:
+			if change.Current.Status == models.TaskStatusRunning {
+				err = errors.New("cannot delete running task")
+			}
+		}
+
+		if err != nil {
+			change.Error = err.Error()
+			rejected++
+		}
+	}
+	return rejected
+}
+
+// checkManifestTask checks a task about to be created or updated from a manifest
+func (h *TaskHandler) checkManifestTask(ctx context.Context, user *models.User, change *models.TaskManifestChange) error {
+	if change.Current != nil && change.Current.Status == models.TaskStatusRunning {
+		return errors.New("cannot update running task")
+	}
+	_, err := h.taskService.CheckTask(ctx, user, change.Desired)
+	return err
+}
+
+// applyManifestChanges saves the planned changes through the task service, in
+// a single transaction. It stops at the first change that fails, which is
+// given the error, and none of the changes is made.
+func (h *TaskHandler) applyManifestChanges(ctx context.Context, user *models.User, changes []models.TaskManifestChange) error {
+	err := h.taskService.WithTransaction(ctx, func(tasks *services.TaskService) error {
+		for i := range changes {
+			change := &changes[i]
+
+			var err error
+			switch change.Action {
+			case models.TaskManifestActionCreate:
+				_, err = tasks.SaveNewTask(ctx, user, change.Desired)
+			case models.TaskManifestActionUpdate:
+				_, err = tasks.SaveTask(ctx, user, change.Desired)
+			case models.TaskManifestActionDelete:
+				err = tasks.DeleteTask(ctx, user, change.Current)
+			}
+
+			if err != nil {
+				change.Error = manifestChangeError(err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range changes {
+		if change := &changes[i]; change.Action == models.TaskManifestActionCreate {
+			id := change.Desired.ID
+			change.TaskID = &id
+		}
+	}
+	return nil
+}
+
+// manifestChangeError returns the error reported for a change that failed to
+// be saved. The reasons a task can't be saved are reported as is; any other
+// error is not.
+func manifestChangeError(err error) string {
+	for _, reason := range []error{
+		database.ErrTaskExternalKeyExists,
+		database.ErrTaskNotFound,
+		database.ErrTaskVersionConflict,
+		services.ErrCannotUpdateRunningTask,
+		services.ErrCannotDeleteRunningTask,
+	} {
+		if errors.Is(err, reason) {
+			return reason.Error()
+		}
+	}
+	return "failed to save task"
+}
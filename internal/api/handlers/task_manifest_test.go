@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+// fakeTaskTransaction is a transaction whose task repository is a mock
+type fakeTaskTransaction struct {
+	pgx.Tx
+	taskRepo database.TaskRepository
+}
+
+func (tx *fakeTaskTransaction) Repositories() database.TransactionalRepositories {
+	return database.TransactionalRepositories{Tasks: tx.taskRepo}
+}
+
+// fakeTaskTransactor runs functions in a fake transaction and records
+// whether it was committed
+type fakeTaskTransactor struct {
+	taskRepo  database.TaskRepository
+	committed bool
+}
+
+func (f *fakeTaskTransactor) WithTransaction(ctx context.Context, fn func(tx database.Transaction) error) error {
+	if err := fn(&fakeTaskTransaction{taskRepo: f.taskRepo}); err != nil {
+		return err
+	}
+	f.committed = true
+	return nil
+}
+
+func setupTaskManifestTest(t *testing.T) (*gin.Engine, *MockTaskRepository, *models.User) {
+	router, mockRepo, _, user := setupTaskManifestTransactionTest(t)
+	return router, mockRepo, user
+}
+
+// setupTaskManifestTransactionTest sets up the manifest handlers, with the
+// writes of an import made in a separate transaction repository
+func setupTaskManifestTransactionTest(t *testing.T) (*gin.Engine, *MockTaskRepository, *fakeTaskTransactor, *models.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}, Email: "test@example.com"}
+	mockRepo := new(MockTaskRepository)
+	transactor := &fakeTaskTransactor{taskRepo: mockRepo}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewTaskHandler(mockRepo, nil, services.NewTaskService(mockRepo, transactor, nil, nil, nil, logger), logger)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", user)
+		c.Next()
+	})
+	router.GET("/tasks/export", handler.Export)
+	router.POST("/tasks/import", handler.Import)
+
+	return router, mockRepo, transactor, user
+}
+
+func managedTask(userID uuid.UUID, key, script string) *models.Task {
+	return &models.Task{
+		BaseModel:      models.BaseModel{ID: uuid.New()},
+		UserID:         userID,
+		Name:           "Task " + key,
+		ScriptContent:  script,
+		ScriptType:     models.ScriptTypePython,
+		Status:         models.TaskStatusCompleted,
+		Priority:       5,
+		TimeoutSeconds: config.DefaultTaskTimeout,
+		SecurityLevel:  models.SecurityLevelStandard,
+		NetworkMode:    models.NetworkModeNone,
+		ExternalKey:    &key,
+	}
+}
+
+const testImportManifest = `
+tasks:
+  - key: existing
+    name: Task existing
+    script: print('new')
+    script_type: python
+  - key: fresh
+    name: Task fresh
+    script: print('fresh')
+    script_type: python
+`
+
+func importRequest(query, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/tasks/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/yaml")
+	return req
+}
+
+func TestTaskHandler_Import(t *testing.T) {
+	t.Run("dry run reports the changes", func(t *testing.T) {
+		router, mockRepo, user := setupTaskManifestTest(t)
+		mockRepo.On("GetAllByUserID", mock.Anything, user.ID).Return([]*models.Task{
+			managedTask(user.ID, "existing", "print('old')"),
+			managedTask(user.ID, "stale", "print('stale')"),
+		}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, importRequest("?prune=true", testImportManifest))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.TaskImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.TaskImportModeDryRun, response.Mode)
+		assert.False(t, response.Applied)
+		assert.Equal(t, models.TaskImportSummary{Create: 1, Update: 1, Delete: 1}, response.Summary)
+		require.Len(t, response.Changes, 3)
+		assert.Empty(t, response.Changes[0].Fields, "fields are only reported in diff mode")
+
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("diff reports the changed fields", func(t *testing.T) {
+		router, mockRepo, user := setupTaskManifestTest(t)
+		mockRepo.On("GetAllByUserID", mock.Anything, user.ID).Return([]*models.Task{
+			managedTask(user.ID, "existing", "print('old')"),
+		}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, importRequest("?mode=diff", testImportManifest))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.TaskImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Changes[0].Fields, 1)
+		assert.Equal(t, "script", response.Changes[0].Fields[0].Field)
+		assert.Contains(t, response.Changes[0].Fields[0].Diff, "+print('new')")
+	})
+
+	t.Run("apply makes the changes", func(t *testing.T) {
+		router, mockRepo, transactor, user := setupTaskManifestTransactionTest(t)
+		existing := managedTask(user.ID, "existing", "print('old')")
+		stale := managedTask(user.ID, "stale", "print('stale')")
+		mockRepo.On("GetAllByUserID", mock.Anything, user.ID).Return([]*models.Task{existing, stale}, nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+			return task.ID == existing.ID && task.ScriptContent == "print('new')"
+		})).Return(nil)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+			return task.UserID == user.ID && *task.ExternalKey == "fresh" && task.Status == models.TaskStatusPending
+		})).Return(nil)
+		mockRepo.On("Delete", mock.Anything, stale.ID, stale.Version).Return(nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, importRequest("?mode=apply&prune=true", testImportManifest))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.TaskImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Applied)
+		assert.True(t, transactor.committed)
+		require.NotNil(t, response.Changes[1].TaskID, "created tasks report their ID")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("a failing change rolls back the whole apply", func(t *testing.T) {
+		router, mockRepo, transactor, user := setupTaskManifestTransactionTest(t)
+		txRepo := new(MockTaskRepository)
+		transactor.taskRepo = txRepo
+		existing := managedTask(user.ID, "existing", "print('old')")
+		stale := managedTask(user.ID, "stale", "print('stale')")
+		mockRepo.On("GetAllByUserID", mock.Anything, user.ID).Return([]*models.Task{existing, stale}, nil)
+		txRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		txRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		txRepo.On("Delete", mock.Anything, stale.ID, stale.Version).Return(database.ErrTaskVersionConflict)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, importRequest("?mode=apply&prune=true", testImportManifest))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+
+		var response models.TaskImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Applied)
+		assert.False(t, transactor.committed, "the changes made before the failure are rolled back")
+		assert.Nil(t, response.Changes[1].TaskID, "rolled back tasks report no ID")
+		assert.Equal(t, database.ErrTaskVersionConflict.Error(), response.Changes[2].Error)
+
+		// Every write goes through the transaction
+		txRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected tasks block the whole import", func(t *testing.T) {
+		router, mockRepo, user := setupTaskManifestTest(t)
+		running := managedTask(user.ID, "existing", "print('old')")
+		running.Status = models.TaskStatusRunning
+		mockRepo.On("GetAllByUserID", mock.Anything, user.ID).Return([]*models.Task{running}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, importRequest("?mode=apply", testImportManifest+`
+  - key: dangerous
+    name: Dangerous
+    script: "import os\nos.system('id')"
+    script_type: python
+`))
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		var response models.TaskImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Manifest rejected", response.Error)
+		assert.False(t, response.Applied)
+		assert.Equal(t, "cannot update running task", response.Changes[0].Error)
+		assert.Empty(t, response.Changes[1].Error)
+		assert.Contains(t, response.Changes[2].Error, "script failed security analysis")
+
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			query string
+			body  string
+		}{
+			{name: "invalid mode", query: "?mode=force", body: testImportManifest},
+			{name: "invalid prune", query: "?prune=maybe", body: testImportManifest},
+			{name: "unknown field", body: "tasks:\n  - key: a\n    schedule: '@daily'\n"},
+			{name: "unresolved script file", body: "tasks:\n  - key: a\n    name: A\n    script_file: a.py\n    script_type: python\n"},
+			{name: "duplicate key", body: testImportManifest + "  - key: fresh\n    name: Again\n    script: print(1)\n    script_type: python\n"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				router, mockRepo, _ := setupTaskManifestTest(t)
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, importRequest(tt.query, tt.body))
+
+				assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+				mockRepo.AssertNotCalled(t, "GetAllByUserID", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestTaskHandler_Export(t *testing.T) {
+	router, mockRepo, user := setupTaskManifestTest(t)
+	unmanaged := managedTask(user.ID, "", "print('hello')")
+	unmanaged.ExternalKey = nil
+	mockRepo.On("GetAllByUserID", mock.Anything, user.ID).Return([]*models.Task{
+		managedTask(user.ID, "managed", "print('managed')\nprint('done')\n"),
+		unmanaged,
+	}, nil)
+
+	t.Run("yaml", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/export", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+
+		var manifest models.TaskManifest
+		require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &manifest))
+		require.Len(t, manifest.Tasks, 2)
+		assert.Equal(t, "managed", manifest.Tasks[0].Key)
+		assert.Equal(t, "print('managed')\nprint('done')\n", manifest.Tasks[0].Script)
+		assert.Equal(t, unmanaged.ID.String(), manifest.Tasks[1].Key)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/export?format=json", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var manifest models.TaskManifest
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &manifest))
+		assert.Len(t, manifest.Tasks, 2)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/export?format=xml", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// ListRevisions handles listing the revisions of a task
+//
+//	@Summary		List task revisions
+//	@Description	Retrieves a paginated list of the task's script revisions, newest first
+//	@Tags			Tasks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Task ID"
+//	@Param			limit	query		int		false	"Maximum number of revisions to return"	default(20)
+//	@Param			offset	query		int		false	"Number of revisions to skip"				default(0)
+//	@Success		200		{object}	models.TaskRevisionListResponse	"Revisions retrieved successfully"
+//	@Failure		400		{object}	models.ErrorResponse			"Invalid task ID or query parameters"
+//	@Failure		401		{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse			"Access denied"
+//	@Failure		404		{object}	models.ErrorResponse			"Task not found"
+//	@Failure		429		{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/tasks/{id}/revisions [get]
+func (h *TaskHandler) ListRevisions(c *gin.Context) {
+	task, _, ok := h.getOwnedTask(c)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		h.logger.Warn("invalid pagination parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	revisions, err := h.revisionRepo.GetByTaskID(c.Request.Context(), task.ID, limit, offset)
+	if err != nil {
+		h.logger.Error("failed to get task revisions", "error", err, "task_id", task.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve revisions",
+		})
+		return
+	}
+
+	total, err := h.revisionRepo.CountByTaskID(c.Request.Context(), task.ID)
+	if err != nil {
+		h.logger.Error("failed to count task revisions", "error", err, "task_id", task.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to count revisions",
+		})
+		return
+	}
+
+	responses := make([]models.TaskRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = revision.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, models.TaskRevisionListResponse{
+		Revisions: responses,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	})
+}
+
+// GetRevision handles retrieving a single revision of a task
+//
+//	@Summary		Get task revision
+//	@Description	Retrieves the script of a specific task revision
+//	@Tags			Tasks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string	true	"Task ID"
+//	@Param			revision	path		int		true	"Revision number"
+//	@Success		200			{object}	models.TaskRevisionResponse	"Revision retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse		"Invalid task ID or revision"
+//	@Failure		401			{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		403			{object}	models.ErrorResponse		"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse		"Task or revision not found"
+//	@Failure		429			{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Router			/tasks/{id}/revisions/{revision} [get]
+func (h *TaskHandler) GetRevision(c *gin.Context) {
+	task, _, ok := h.getOwnedTask(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.getRevision(c, task.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, revision.ToResponse())
+}
+
+// DiffRevisions handles comparing two revisions of a task
+//
+//	@Summary		Diff task revisions
+//	@Description	Returns a unified diff of the script between two task revisions. The diff is taken against the previous revision when from is omitted, and of the current revision when to is omitted.
+//	@Tags			Tasks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Task ID"
+//	@Param			from	query		int		false	"Revision to diff from"
+//	@Param			to		query		int		false	"Revision to diff to"
+//	@Success		200		{object}	models.TaskRevisionDiffResponse	"Diff computed successfully"
+//	@Failure		400		{object}	models.ErrorResponse			"Invalid task ID or revision"
+//	@Failure		401		{object}	models.ErrorResponse			"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse			"Access denied"
+//	@Failure		404		{object}	models.ErrorResponse			"Task or revision not found"
+//	@Failure		429		{object}	models.ErrorResponse			"Rate limit exceeded"
+//	@Router			/tasks/{id}/revisions/diff [get]
+func (h *TaskHandler) DiffRevisions(c *gin.Context) {
+	task, _, ok := h.getOwnedTask(c)
+	if !ok {
+		return
+	}
+
+	toParam := c.DefaultQuery("to", strconv.Itoa(task.Revision))
+	to, ok := h.getRevision(c, task.ID, toParam)
+	if !ok {
+		return
+	}
+
+	fromParam := c.Query("from")
+	if fromParam == "" {
+		fromParam = strconv.Itoa(max(to.Revision-1, 1))
+	}
+	from, ok := h.getRevision(c, task.ID, fromParam)
+	if !ok {
+		return
+	}
+
+	diff, err := models.DiffTaskRevisions(from, to)
+	if err != nil {
+		h.logger.Error("failed to diff task revisions", "error", err, "task_id", task.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to diff revisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RollbackRevision handles restoring the script of an earlier revision
+//
+//	@Summary		Roll back task to a revision
+//	@Description	Restores the script of an earlier revision. The restored script is saved as a new revision, so the history is kept.
+//	@Tags			Tasks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string	true	"Task ID"
+//	@Param			revision	path		int		true	"Revision number to restore"
+//	@Success		200			{object}	models.TaskResponse		"Task rolled back successfully"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid task ID or revision, or the script failed analysis"
+//	@Failure		401			{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		403			{object}	models.ErrorResponse	"Access denied or denied by admission policy"
+//	@Failure		404			{object}	models.ErrorResponse	"Task or revision not found"
+//	@Failure		409			{object}	models.ErrorResponse	"Task is running, or was modified concurrently"
+//	@Failure		412			{object}	models.ErrorResponse	"If-Match doesn't match the task's version"
+//	@Failure		429			{object}	models.ErrorResponse	"Rate limit exceeded"
+//	@Router			/tasks/{id}/revisions/{revision}/rollback [post]
+func (h *TaskHandler) RollbackRevision(c *gin.Context) {
+	task, user, ok := h.getOwnedTask(c)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, task.Version) {
+		return
+	}
+
+	if task.Status == models.TaskStatusRunning {
+		h.logger.Warn("attempted to roll back running task", "task_id", task.ID, "user_id", user.ID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Cannot roll back running task",
+		})
+		return
+	}
+
+	revision, ok := h.getRevision(c, task.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	// Policies and analysis rules may have changed since the revision was saved
+	task.ScriptContent = revision.ScriptContent
+	task.ScriptType = revision.ScriptType
+
+	findings, err := h.taskService.CheckTask(c.Request.Context(), user, task)
+	if err != nil {
+		h.respondTaskError(c, err, user, "roll back")
+		return
+	}
+
+	if err := h.taskRepo.Update(c.Request.Context(), task); err != nil {
+		if errors.Is(err, database.ErrTaskVersionConflict) {
+			h.logger.Warn("task modified concurrently", "task_id", task.ID, "user_id", user.ID)
+			respondVersionConflict(c, "Task")
+			return
+		}
+		h.logger.Error("failed to roll back task", "error", err, "task_id", task.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to roll back task",
+		})
+		return
+	}
+
+	h.logger.Info("task rolled back",
+		"task_id", task.ID, "user_id", user.ID, "restored_revision", revision.Revision, "revision", task.Revision)
+	response := task.ToResponse()
+	response.ScriptFindings = findings
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, response)
+}
+
+// getOwnedTask loads the task named by the id path parameter and checks that
+// it belongs to the user. On failure the error response has been written and
+// ok is false.
+func (h *TaskHandler) getOwnedTask(c *gin.Context) (task *models.Task, user *models.User, ok bool) {
+	taskIDStr := c.Param("id")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		h.logger.Warn("invalid task ID", "task_id", taskIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID format",
+		})
+		return nil, nil, false
+	}
+
+	user = middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return nil, nil, false
+	}
+
+	task, err = h.taskRepo.GetByID(c.Request.Context(), taskID)
+	if err != nil {
+		if err == database.ErrTaskNotFound {
+			h.logger.Warn("task not found", "task_id", taskID, "user_id", user.ID)
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found",
+			})
+			return nil, nil, false
+		}
+		h.logger.Error("failed to get task", "error", err, "task_id", taskID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task",
+		})
+		return nil, nil, false
+	}
+
+	if task.UserID != user.ID {
+		h.logger.Warn("user attempted to access another user's task",
+			"user_id", user.ID, "task_id", taskID, "task_owner_id", task.UserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return nil, nil, false
+	}
+
+	return task, user, true
+}
+
+// getRevision loads a revision of the task by its number as given in the
+// request. On failure the error response has been written and ok is false.
+func (h *TaskHandler) getRevision(c *gin.Context, taskID uuid.UUID, number string) (*models.TaskRevision, bool) {
+	revisionNumber, err := parseRevision(number)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	revision, err := h.revisionRepo.GetByRevision(c.Request.Context(), taskID, revisionNumber)
+	if err != nil {
+		if err == database.ErrTaskRevisionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Revision not found",
+			})
+			return nil, false
+		}
+		h.logger.Error("failed to get task revision", "error", err, "task_id", taskID, "revision", revisionNumber)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve revision",
+		})
+		return nil, false
+	}
+
+	return revision, true
+}
+
+// parseRevision parses a revision number
+func parseRevision(number string) (int, error) {
+	revision, err := strconv.Atoi(number)
+	if err != nil || revision < 1 {
+		return 0, fmt.Errorf("invalid revision: %s", number)
+	}
+	return revision, nil
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// MockTaskRevisionRepository is a mock implementation of TaskRevisionRepository
+type MockTaskRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaskRevisionRepository) GetByRevision(ctx context.Context, taskID uuid.UUID, revision int) (*models.TaskRevision, error) {
+	args := m.Called(ctx, taskID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskRevision), args.Error(1)
+}
+
+func (m *MockTaskRevisionRepository) GetByTaskID(ctx context.Context, taskID uuid.UUID, limit, offset int) ([]*models.TaskRevision, error) {
+	args := m.Called(ctx, taskID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskRevision), args.Error(1)
+}
+
+func (m *MockTaskRevisionRepository) CountByTaskID(ctx context.Context, taskID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// setupTaskRevisionTest returns a router whose requests are made by the owner
+// of a task at revision 3
+func setupTaskRevisionTest() (*gin.Engine, *MockTaskRepository, *MockTaskRevisionRepository, *models.Task) {
+	router, mockRepo, handler := setupTaskHandlerTest()
+	revisionRepo := new(MockTaskRevisionRepository)
+	handler.revisionRepo = revisionRepo
+
+	task := &models.Task{
+		BaseModel:     models.BaseModel{ID: uuid.New()},
+		UserID:        uuid.New(),
+		Name:          "Test Task",
+		ScriptContent: "print('three')",
+		ScriptType:    models.ScriptTypePython,
+		Status:        models.TaskStatusCompleted,
+		Revision:      3,
+	}
+	mockRepo.On("GetByID", mock.Anything, task.ID).Return(task, nil)
+
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{BaseModel: models.BaseModel{ID: task.UserID}})
+		c.Next()
+	})
+	router.GET("/tasks/:id/revisions", handler.ListRevisions)
+	router.GET("/tasks/:id/revisions/diff", handler.DiffRevisions)
+	router.GET("/tasks/:id/revisions/:revision", handler.GetRevision)
+	router.POST("/tasks/:id/revisions/:revision/rollback", handler.RollbackRevision)
+
+	return router, mockRepo, revisionRepo, task
+}
+
+func testRevision(taskID uuid.UUID, revision int, content string) *models.TaskRevision {
+	return &models.TaskRevision{
+		TaskID:        taskID,
+		Revision:      revision,
+		ScriptContent: content,
+		ScriptType:    models.ScriptTypePython,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestTaskHandler_ListRevisions(t *testing.T) {
+	router, _, revisionRepo, task := setupTaskRevisionTest()
+
+	revisionRepo.On("GetByTaskID", mock.Anything, task.ID, 20, 0).Return([]*models.TaskRevision{
+		testRevision(task.ID, 3, "print('three')"),
+		testRevision(task.ID, 2, "print('two')"),
+	}, nil)
+	revisionRepo.On("CountByTaskID", mock.Anything, task.ID).Return(int64(3), nil)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%s/revisions", task.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskRevisionListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Revisions, 2)
+	assert.Equal(t, 3, response.Revisions[0].Revision)
+	assert.Equal(t, int64(3), response.Total)
+	revisionRepo.AssertExpectations(t)
+}
+
+func TestTaskHandler_GetRevision(t *testing.T) {
+	router, _, revisionRepo, task := setupTaskRevisionTest()
+
+	revisionRepo.On("GetByRevision", mock.Anything, task.ID, 1).Return(testRevision(task.ID, 1, "print('one')"), nil)
+	revisionRepo.On("GetByRevision", mock.Anything, task.ID, 9).Return(nil, database.ErrTaskRevisionNotFound)
+
+	tests := []struct {
+		name           string
+		revision       string
+		expectedStatus int
+	}{
+		{name: "existing revision", revision: "1", expectedStatus: http.StatusOK},
+		{name: "unknown revision", revision: "9", expectedStatus: http.StatusNotFound},
+		{name: "invalid revision", revision: "0", expectedStatus: http.StatusBadRequest},
+		{name: "non-numeric revision", revision: "latest", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%s/revisions/%s", task.ID, tt.revision), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestTaskHandler_DiffRevisions(t *testing.T) {
+	router, _, revisionRepo, task := setupTaskRevisionTest()
+
+	revisionRepo.On("GetByRevision", mock.Anything, task.ID, 1).Return(testRevision(task.ID, 1, "print('one')\n"), nil)
+	revisionRepo.On("GetByRevision", mock.Anything, task.ID, 2).Return(testRevision(task.ID, 2, "print('two')\n"), nil)
+	revisionRepo.On("GetByRevision", mock.Anything, task.ID, 3).Return(testRevision(task.ID, 3, "print('three')\n"), nil)
+
+	t.Run("defaults to the current and previous revision", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%s/revisions/diff", task.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.TaskRevisionDiffResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.From)
+		assert.Equal(t, 3, response.To)
+		assert.Contains(t, response.Diff, "-print('two')")
+		assert.Contains(t, response.Diff, "+print('three')")
+	})
+
+	t.Run("explicit revisions", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%s/revisions/diff?from=1&to=2", task.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.TaskRevisionDiffResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.From)
+		assert.Equal(t, 2, response.To)
+		assert.Contains(t, response.Diff, "--- revision 1")
+		assert.Contains(t, response.Diff, "+++ revision 2")
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%s/revisions/diff?from=abc", task.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTaskHandler_RollbackRevision(t *testing.T) {
+	t.Run("restores the script as a new revision", func(t *testing.T) {
+		router, mockRepo, revisionRepo, task := setupTaskRevisionTest()
+
+		revisionRepo.On("GetByRevision", mock.Anything, task.ID, 1).Return(testRevision(task.ID, 1, "print('one')"), nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updated *models.Task) bool {
+			return updated.ScriptContent == "print('one')"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Task).Revision = 4
+		}).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%s/revisions/1/rollback", task.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.TaskResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "print('one')", response.ScriptContent)
+		assert.Equal(t, 4, response.Revision)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects running tasks", func(t *testing.T) {
+		router, mockRepo, _, task := setupTaskRevisionTest()
+		task.Status = models.TaskStatusRunning
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%s/revisions/1/rollback", task.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("analyzes the restored script", func(t *testing.T) {
+		router, mockRepo, revisionRepo, task := setupTaskRevisionTest()
+
+		revisionRepo.On("GetByRevision", mock.Anything, task.ID, 1).Return(testRevision(task.ID, 1, "import os\nos.system('id')"), nil)
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%s/revisions/1/rollback", task.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
@@ -19,8 +19,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
 )
 
 // MockTaskRepository is a mock implementation of TaskRepository
@@ -67,11 +71,36 @@ func (m *MockTaskRepository) UpdateStatus(ctx context.Context, id uuid.UUID, sta
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockTaskRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Restore(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) Purge(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	executionIDs, _ := args.Get(0).([]uuid.UUID)
+	return executionIDs, args.Error(1)
+}
+
+func (m *MockTaskRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, []uuid.UUID, error) {
+	args := m.Called(ctx, before, limit)
+	executionIDs, _ := args.Get(1).([]uuid.UUID)
+	return args.Get(0).(int64), executionIDs, args.Error(2)
+}
+
 func (m *MockTaskRepository) List(ctx context.Context, limit, offset int) ([]*models.Task, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -128,6 +157,14 @@ func (m *MockTaskRepository) ListCursor(ctx context.Context, req database.Cursor
 	return args.Get(0).([]*models.Task), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
 }
 
+func (m *MockTaskRepository) Search(ctx context.Context, filter database.TaskFilter, req database.CursorPaginationRequest) ([]*models.Task, database.CursorPaginationResponse, error) {
+	args := m.Called(ctx, filter, req)
+	if args.Get(0) == nil {
+		return nil, database.CursorPaginationResponse{}, args.Error(2)
+	}
+	return args.Get(0).([]*models.Task), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
+}
+
 // Optimized bulk operations
 func (m *MockTaskRepository) GetTasksWithExecutionCount(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Task, error) {
 	args := m.Called(ctx, userID, limit, offset)
@@ -145,12 +182,36 @@ func (m *MockTaskRepository) GetTasksWithLatestExecution(ctx context.Context, us
 	return args.Get(0).([]*models.Task), args.Error(1)
 }
 
+func (m *MockTaskRepository) GetImageInventory(ctx context.Context, userID uuid.UUID) ([]models.ImageUsage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ImageUsage), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetLabelInventory(ctx context.Context, userID uuid.UUID) ([]models.LabelUsage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LabelUsage), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Task, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
 func setupTaskHandlerTest() (*gin.Engine, *MockTaskRepository, *TaskHandler) {
 	gin.SetMode(gin.TestMode)
 
 	mockRepo := new(MockTaskRepository)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	handler := NewTaskHandler(mockRepo, logger)
+	handler := NewTaskHandler(mockRepo, nil, services.NewTaskService(mockRepo, nil, nil, nil, nil, logger), logger)
 
 	router := gin.New()
 	// Add middleware to set user context
@@ -188,6 +249,33 @@ func TestTaskHandler_Create(t *testing.T) {
 			},
 			wantStatus: http.StatusCreated,
 		},
+		{
+			name: "successful task creation with labels",
+			request: models.CreateTaskRequest{
+				Name:          "Test Task",
+				ScriptContent: "print('hello world')",
+				ScriptType:    models.ScriptTypePython,
+				Labels:        map[string]string{"env": "prod", "example.com/team": "data"},
+			},
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					return task.Labels["env"] == "prod" && task.Labels["example.com/team"] == "data"
+				})).Return(nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "invalid request - invalid label",
+			request: models.CreateTaskRequest{
+				Name:          "Test Task",
+				ScriptContent: "print('hello world')",
+				ScriptType:    models.ScriptTypePython,
+				Labels:        map[string]string{"env": "prod eu"},
+			},
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `label value "prod eu"`,
+		},
 		{
 			name: "invalid request - empty name",
 			request: models.CreateTaskRequest{
@@ -427,6 +515,100 @@ func TestTaskHandler_List(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantError:  "limit must be between 1 and 100",
 		},
+		{
+			name:  "filtered listing uses cursor pagination",
+			query: "?status=pending,failed&script_type=python&priority_min=3&metadata=%7B%22team%22%3A%22data%22%7D&q=nightly&sort_field=name&sort_order=asc&limit=5",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("Search", mock.Anything, mock.MatchedBy(func(filter database.TaskFilter) bool {
+					return *filter.UserID == userID &&
+						assert.ObjectsAreEqual([]models.TaskStatus{models.TaskStatusPending, models.TaskStatusFailed}, filter.Statuses) &&
+						assert.ObjectsAreEqual([]models.ScriptType{models.ScriptTypePython}, filter.ScriptTypes) &&
+						*filter.MinPriority == 3 && filter.MaxPriority == nil &&
+						filter.Metadata["team"] == "data" && filter.Query == "nightly"
+				}), database.CursorPaginationRequest{Limit: 5, SortOrder: "asc", SortField: "name"}).
+					Return([]*models.Task{}, database.CursorPaginationResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "sorted listing uses cursor pagination",
+			query: "?sort_field=priority",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("Search", mock.Anything, database.TaskFilter{UserID: &userID}, database.CursorPaginationRequest{Limit: 20, SortOrder: "desc", SortField: "priority"}).
+					Return([]*models.Task{}, database.CursorPaginationResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=invalid",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("Search", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, database.CursorPaginationResponse{}, fmt.Errorf("%w: bad encoding", database.ErrInvalidCursor))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Invalid cursor",
+		},
+		{
+			name:       "invalid filter - status",
+			query:      "?status=sleeping",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid status parameter",
+		},
+		{
+			name:       "invalid filter - priority range",
+			query:      "?priority_min=8&priority_max=2",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "priority_min must not be greater than priority_max",
+		},
+		{
+			name:       "invalid filter - time range",
+			query:      "?created_after=yesterday",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid created_after parameter",
+		},
+		{
+			name:       "invalid filter - metadata",
+			query:      "?metadata=%5B1%5D",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid metadata parameter",
+		},
+		{
+			name:       "invalid filter - search without words",
+			query:      "?q=%21%21",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "q must contain a letter or digit",
+		},
+		{
+			name:  "label selector uses cursor pagination",
+			query: "?selector=env%3Dprod%2Cteam%20in%20(data%2Cml)%2C%21deprecated",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("Search", mock.Anything, mock.MatchedBy(func(filter database.TaskFilter) bool {
+					return *filter.UserID == userID &&
+						filter.Selector.String() == "env=prod,team in (data,ml),!deprecated"
+				}), mock.Anything).Return([]*models.Task{}, database.CursorPaginationResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid filter - label selector",
+			query:      "?selector=team%20in%20(data",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `invalid label selector "team in (data" at position 13: expected ")" to close the values of "in"`,
+		},
+		{
+			name:       "filters with offset",
+			query:      "?status=pending&offset=20",
+			mockSetup:  func(m *MockTaskRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "offset can't be combined",
+		},
 	}
 
 	for _, tt := range tests {
@@ -573,6 +755,421 @@ func TestTaskHandler_Update(t *testing.T) {
 	}
 }
 
+// MockImageResolver is a mock implementation of executor.ImageResolver
+type MockImageResolver struct {
+	mock.Mock
+}
+
+func (m *MockImageResolver) ResolveImage(ctx context.Context, image string) (*executor.ResolvedImage, error) {
+	args := m.Called(ctx, image)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*executor.ResolvedImage), args.Error(1)
+}
+
+func TestTaskHandler_CreateWithImage(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		resolver   func() *MockImageResolver
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name: "image is pinned to its digest",
+			resolver: func() *MockImageResolver {
+				m := new(MockImageResolver)
+				m.On("ResolveImage", mock.Anything, "python:3.12").
+					Return(&executor.ResolvedImage{Image: "python:3.12", Digest: digest}, nil)
+				return m
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "repository not allowed",
+			resolver: func() *MockImageResolver {
+				m := new(MockImageResolver)
+				m.On("ResolveImage", mock.Anything, "python:3.12").
+					Return(nil, fmt.Errorf("resolve: %w", executor.ErrImageNotAllowed))
+				return m
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Image repository is not allowed",
+		},
+		{
+			name: "registry unavailable",
+			resolver: func() *MockImageResolver {
+				m := new(MockImageResolver)
+				m.On("ResolveImage", mock.Anything, "python:3.12").Return(nil, errors.New("connection refused"))
+				return m
+			},
+			wantStatus: http.StatusBadGateway,
+			wantError:  "Failed to resolve image",
+		},
+		{
+			name:       "no resolver configured",
+			resolver:   func() *MockImageResolver { return nil },
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Custom images are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo, handler := setupTaskHandlerTest()
+			if resolver := tt.resolver(); resolver != nil {
+				handler.taskService = services.NewTaskService(mockRepo, nil, resolver, nil, nil, handler.logger)
+			}
+			if tt.wantStatus == http.StatusCreated {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					return task.Image != nil && *task.Image == "python:3.12" &&
+						task.ImageDigest != nil && *task.ImageDigest == digest
+				})).Return(nil)
+			}
+
+			router.POST("/tasks", handler.Create)
+
+			reqBody, _ := json.Marshal(models.CreateTaskRequest{
+				Name:          "Test Task",
+				ScriptContent: "print('hello world')",
+				ScriptType:    models.ScriptTypePython,
+				Image:         stringPtr("python:3.12"),
+			})
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.wantError != "" {
+				assert.Contains(t, response["error"], tt.wantError)
+			} else {
+				assert.Equal(t, digest, response["image_digest"])
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskHandler_UpdateClearsImage(t *testing.T) {
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	router, mockRepo, handler := setupTaskHandlerTest()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+		c.Next()
+	})
+
+	image := "python:3.12"
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	task := &models.Task{
+		BaseModel:     models.BaseModel{ID: taskID},
+		UserID:        userID,
+		Name:          "Test Task",
+		ScriptContent: "print('hello world')",
+		ScriptType:    models.ScriptTypePython,
+		Status:        models.TaskStatusPending,
+		Image:         &image,
+		ImageDigest:   &digest,
+	}
+	mockRepo.On("GetByID", mock.Anything, taskID).Return(task, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.Image == nil && task.ImageDigest == nil
+	})).Return(nil)
+
+	router.PUT("/tasks/:id", handler.Update)
+
+	reqBody, _ := json.Marshal(models.UpdateTaskRequest{Image: stringPtr("")})
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskHandler_CreateWithNetworkPolicy(t *testing.T) {
+	allowlistMode := models.NetworkModeAllowlist
+	internalMode := models.NetworkModeInternal
+
+	tests := []struct {
+		name       string
+		mode       *models.TaskNetworkMode
+		allowlist  []string
+		wantStatus int
+	}{
+		{
+			name:       "allowlist is normalized",
+			mode:       &allowlistMode,
+			allowlist:  []string{"PyPI.org", "10.0.0.0/8", "pypi.org"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "internal mode without allowlist",
+			mode:       &internalMode,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "allowlist without allowlist mode",
+			allowlist:  []string{"pypi.org"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "allowlist mode without destinations",
+			mode:       &allowlistMode,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo, handler := setupTaskHandlerTest()
+			if tt.wantStatus == http.StatusCreated {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+					if task.NetworkMode != *tt.mode {
+						return false
+					}
+					return tt.allowlist == nil ||
+						assert.ObjectsAreEqual([]string{"10.0.0.0/8", "pypi.org"}, task.NetworkAllowlist)
+				})).Return(nil)
+			}
+
+			router.POST("/tasks", handler.Create)
+
+			reqBody, _ := json.Marshal(models.CreateTaskRequest{
+				Name:             "Test Task",
+				ScriptContent:    "print('hello world')",
+				ScriptType:       models.ScriptTypePython,
+				NetworkMode:      tt.mode,
+				NetworkAllowlist: tt.allowlist,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskHandler_CreateWithScriptAnalysis(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         models.ScriptAnalysisMode
+		wantStatus   int
+		wantFindings bool
+	}{
+		{
+			name:         "block mode rejects the script with its findings",
+			mode:         models.ScriptAnalysisModeBlock,
+			wantStatus:   http.StatusBadRequest,
+			wantFindings: true,
+		},
+		{
+			name:         "warn mode saves the task and returns its findings",
+			mode:         models.ScriptAnalysisModeWarn,
+			wantStatus:   http.StatusCreated,
+			wantFindings: true,
+		},
+		{
+			name:       "audit mode saves the task without findings",
+			mode:       models.ScriptAnalysisModeAudit,
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo, handler := setupTaskHandlerTest()
+			handler.taskService = services.NewTaskService(mockRepo, nil, nil, analyzer.NewPipeline(tt.mode), nil, handler.logger)
+			if tt.wantStatus == http.StatusCreated {
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil)
+			}
+
+			router.POST("/tasks", handler.Create)
+
+			reqBody, _ := json.Marshal(models.CreateTaskRequest{
+				Name:          "Test Task",
+				ScriptContent: "print('start')\nimport subprocess",
+				ScriptType:    models.ScriptTypePython,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+
+			var findings []models.ScriptFinding
+			if tt.wantStatus == http.StatusBadRequest {
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "Validation failed", response.Error)
+				findings = response.Findings
+			} else {
+				var response models.TaskResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				findings = response.ScriptFindings
+			}
+
+			if !tt.wantFindings {
+				assert.Empty(t, findings)
+				return
+			}
+			require.Len(t, findings, 1)
+			assert.Equal(t, "python.import.system", findings[0].RuleID)
+			assert.Equal(t, models.FindingSeverityHigh, findings[0].Severity)
+			assert.Equal(t, 2, findings[0].Line)
+		})
+	}
+}
+
+func TestTaskHandler_UpdateWithScriptAnalysis(t *testing.T) {
+	taskID := uuid.New()
+	userID := uuid.New()
+
+	router, mockRepo, handler := setupTaskHandlerTest()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+		c.Next()
+	})
+
+	task := &models.Task{
+		BaseModel:     models.BaseModel{ID: taskID},
+		UserID:        userID,
+		Name:          "Test Task",
+		ScriptContent: "echo hello",
+		ScriptType:    models.ScriptTypeBash,
+		Status:        models.TaskStatusPending,
+	}
+	mockRepo.On("GetByID", mock.Anything, taskID).Return(task, nil)
+
+	router.PUT("/tasks/:id", handler.Update)
+
+	reqBody, _ := json.Marshal(models.UpdateTaskRequest{ScriptContent: stringPtr("echo hello\nsudo id")})
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Findings, 1)
+	assert.Equal(t, "bash.command.privilege", response.Findings[0].RuleID)
+	assert.Equal(t, 2, response.Findings[0].Line)
+}
+
+func TestTaskHandler_CreateWithAdmission(t *testing.T) {
+	maxPriority := 5
+	engine, err := admission.NewEngine(admission.File{
+		Policies: []admission.Policy{{
+			Name: "interns",
+			Rules: admission.Rules{
+				MaxTimeoutSeconds: 60,
+				MaxPriority:       &maxPriority,
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	router, mockRepo, handler := setupTaskHandlerTest()
+	handler.taskService = services.NewTaskService(mockRepo, nil, nil, nil, engine, handler.logger)
+	router.POST("/tasks", handler.Create)
+
+	priority := 9
+	timeout := 600
+	reqBody, _ := json.Marshal(models.CreateTaskRequest{
+		Name:           "Test Task",
+		ScriptContent:  "print('hello world')",
+		ScriptType:     models.ScriptTypePython,
+		Priority:       &priority,
+		TimeoutSeconds: &timeout,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Denied by admission policy", response.Error)
+	assert.Equal(t, "interns", response.Policy)
+	require.Len(t, response.Violations, 2)
+	assert.Equal(t, "max_timeout_seconds", response.Violations[0].Rule)
+	assert.Equal(t, "max_priority", response.Violations[1].Rule)
+}
+
+func TestTaskHandler_ListLabels(t *testing.T) {
+	router, mockRepo, handler := setupTaskHandlerTest()
+
+	inventory := []models.LabelUsage{
+		{Key: "env", Value: "prod", TaskCount: 3},
+		{Key: "team", Value: "data", TaskCount: 1},
+	}
+	mockRepo.On("GetLabelInventory", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(inventory, nil)
+
+	router.GET("/labels", handler.ListLabels)
+
+	req := httptest.NewRequest(http.MethodGet, "/labels", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.LabelInventoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, inventory, response.Labels)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskHandler_ListImages(t *testing.T) {
+	router, mockRepo, handler := setupTaskHandlerTest()
+
+	taskIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	inventory := []models.ImageUsage{
+		{Image: "python:3.12", Digest: "sha256:aaaa", TaskIDs: taskIDs},
+	}
+	mockRepo.On("GetImageInventory", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(inventory, nil)
+
+	router.GET("/images", handler.ListImages)
+
+	req := httptest.NewRequest(http.MethodGet, "/images", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ImageInventoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Images, 1)
+	assert.Equal(t, "sha256:aaaa", response.Images[0].Digest)
+	assert.Equal(t, taskIDs, response.Images[0].TaskIDs)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestTaskHandler_Delete(t *testing.T) {
 	taskID := uuid.New()
 	userID := uuid.New()
@@ -603,7 +1200,7 @@ func TestTaskHandler_Delete(t *testing.T) {
 					TimeoutSeconds: 30,
 				}
 				m.On("GetByID", mock.Anything, taskID).Return(task, nil)
-				m.On("Delete", mock.Anything, taskID).Return(nil)
+				m.On("Delete", mock.Anything, taskID, task.Version).Return(nil)
 			},
 			wantStatus: http.StatusOK,
 		},
@@ -861,253 +1458,6 @@ func TestTaskHandler_parseCursorPagination(t *testing.T) {
 	}
 }
 
-// Test applyTaskUpdates error scenarios
-func TestTaskHandler_applyTaskUpdates(t *testing.T) {
-	_, _, handler := setupTaskHandlerTest()
-
-	// Create a base task for testing
-	baseTask := &models.Task{
-		BaseModel: models.BaseModel{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		},
-		UserID:         uuid.New(),
-		Name:           "Original Task",
-		Description:    stringPtr("Original description"),
-		ScriptContent:  "print('original')",
-		ScriptType:     models.ScriptTypePython,
-		Priority:       1,
-		TimeoutSeconds: 30,
-		Metadata:       models.JSONB{"original": "value"},
-	}
-
-	tests := []struct {
-		name          string
-		updateReq     models.UpdateTaskRequest
-		expectedError string
-	}{
-		{
-			name: "valid name update",
-			updateReq: models.UpdateTaskRequest{
-				Name: stringPtr("Updated Task Name"),
-			},
-		},
-		{
-			name: "invalid name - empty",
-			updateReq: models.UpdateTaskRequest{
-				Name: stringPtr(""),
-			},
-			expectedError: "task name is required",
-		},
-		{
-			name: "invalid name - too long",
-			updateReq: models.UpdateTaskRequest{
-				Name: stringPtr(string(make([]byte, 256))), // > 255 characters
-			},
-			expectedError: "task name is too long",
-		},
-		{
-			name: "invalid name - whitespace only",
-			updateReq: models.UpdateTaskRequest{
-				Name: stringPtr("   \t\n  "),
-			},
-			expectedError: "task name cannot be empty",
-		},
-		{
-			name: "valid description update",
-			updateReq: models.UpdateTaskRequest{
-				Description: stringPtr("Updated description"),
-			},
-		},
-		{
-			name: "null description update",
-			updateReq: models.UpdateTaskRequest{
-				Description: nil,
-			},
-		},
-		{
-			name: "valid script content update",
-			updateReq: models.UpdateTaskRequest{
-				ScriptContent: stringPtr("print('updated')"),
-			},
-		},
-		{
-			name: "invalid script content - empty",
-			updateReq: models.UpdateTaskRequest{
-				ScriptContent: stringPtr(""),
-			},
-			expectedError: "script content is required",
-		},
-		{
-			name: "invalid script content - too large",
-			updateReq: models.UpdateTaskRequest{
-				ScriptContent: stringPtr(string(make([]byte, 65536))), // > 65535 characters
-			},
-			expectedError: "script content is too long",
-		},
-		{
-			name: "invalid script content - whitespace only",
-			updateReq: models.UpdateTaskRequest{
-				ScriptContent: stringPtr("   \t\n  "),
-			},
-			expectedError: "script content cannot be empty",
-		},
-		{
-			name: "valid script type update",
-			updateReq: models.UpdateTaskRequest{
-				ScriptType: func() *models.ScriptType { st := models.ScriptTypeJavaScript; return &st }(),
-			},
-		},
-		{
-			name: "invalid script type",
-			updateReq: models.UpdateTaskRequest{
-				ScriptType: func() *models.ScriptType { st := models.ScriptType("invalid"); return &st }(),
-			},
-			expectedError: "invalid script type",
-		},
-		{
-			name: "valid priority update",
-			updateReq: models.UpdateTaskRequest{
-				Priority: func() *int { p := 5; return &p }(),
-			},
-		},
-		{
-			name: "invalid priority - negative",
-			updateReq: models.UpdateTaskRequest{
-				Priority: func() *int { p := -1; return &p }(),
-			},
-			expectedError: "priority must be between 0 and 10",
-		},
-		{
-			name: "valid priority - zero",
-			updateReq: models.UpdateTaskRequest{
-				Priority: func() *int { p := 0; return &p }(),
-			},
-		},
-		{
-			name: "invalid priority - too high",
-			updateReq: models.UpdateTaskRequest{
-				Priority: func() *int { p := 11; return &p }(),
-			},
-			expectedError: "priority must be between 0 and 10",
-		},
-		{
-			name: "valid timeout update",
-			updateReq: models.UpdateTaskRequest{
-				TimeoutSeconds: func() *int { t := 60; return &t }(),
-			},
-		},
-		{
-			name: "invalid timeout - negative",
-			updateReq: models.UpdateTaskRequest{
-				TimeoutSeconds: func() *int { t := -1; return &t }(),
-			},
-			expectedError: "timeout must be greater than 0",
-		},
-		{
-			name: "invalid timeout - zero",
-			updateReq: models.UpdateTaskRequest{
-				TimeoutSeconds: func() *int { t := 0; return &t }(),
-			},
-			expectedError: "timeout must be greater than 0",
-		},
-		{
-			name: "invalid timeout - too high",
-			updateReq: models.UpdateTaskRequest{
-				TimeoutSeconds: func() *int { t := 3601; return &t }(),
-			},
-			expectedError: "timeout cannot exceed 3600 seconds",
-		},
-		{
-			name: "valid metadata update",
-			updateReq: models.UpdateTaskRequest{
-				Metadata: models.JSONB{"updated": "metadata"},
-			},
-		},
-		{
-			name: "null metadata update",
-			updateReq: models.UpdateTaskRequest{
-				Metadata: nil,
-			},
-		},
-		{
-			name: "multiple valid updates",
-			updateReq: models.UpdateTaskRequest{
-				Name:           stringPtr("Multi Update Task"),
-				Description:    stringPtr("Multi update description"),
-				ScriptContent:  stringPtr("print('multi update')"),
-				ScriptType:     func() *models.ScriptType { st := models.ScriptTypeBash; return &st }(),
-				Priority:       func() *int { p := 3; return &p }(),
-				TimeoutSeconds: func() *int { t := 120; return &t }(),
-				Metadata:       models.JSONB{"multi": "update"},
-			},
-		},
-		{
-			name: "multiple updates with one invalid",
-			updateReq: models.UpdateTaskRequest{
-				Name:          stringPtr("Valid Name"),
-				Description:   stringPtr("Valid description"),
-				ScriptContent: stringPtr(""), // Invalid
-				Priority:      func() *int { p := 3; return &p }(),
-			},
-			expectedError: "script content is required",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Create a copy of the base task for each test
-			task := &models.Task{
-				BaseModel:      baseTask.BaseModel,
-				UserID:         baseTask.UserID,
-				Name:           baseTask.Name,
-				Description:    baseTask.Description,
-				ScriptContent:  baseTask.ScriptContent,
-				ScriptType:     baseTask.ScriptType,
-				Priority:       baseTask.Priority,
-				TimeoutSeconds: baseTask.TimeoutSeconds,
-				Metadata:       baseTask.Metadata,
-			}
-
-			err := handler.applyTaskUpdates(task, tt.updateReq)
-
-			if tt.expectedError != "" {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedError)
-
-				// Note: Task may be partially modified before error occurs
-				// This is expected behavior as validation happens per field
-			} else {
-				assert.NoError(t, err)
-
-				// Verify updates were applied correctly
-				if tt.updateReq.Name != nil {
-					assert.Equal(t, *tt.updateReq.Name, task.Name)
-				}
-				if tt.updateReq.Description != nil {
-					assert.Equal(t, tt.updateReq.Description, task.Description)
-				}
-				if tt.updateReq.ScriptContent != nil {
-					assert.Equal(t, *tt.updateReq.ScriptContent, task.ScriptContent)
-				}
-				if tt.updateReq.ScriptType != nil {
-					assert.Equal(t, *tt.updateReq.ScriptType, task.ScriptType)
-				}
-				if tt.updateReq.Priority != nil {
-					assert.Equal(t, *tt.updateReq.Priority, task.Priority)
-				}
-				if tt.updateReq.TimeoutSeconds != nil {
-					assert.Equal(t, *tt.updateReq.TimeoutSeconds, task.TimeoutSeconds)
-				}
-				if tt.updateReq.Metadata != nil {
-					assert.Equal(t, tt.updateReq.Metadata, task.Metadata)
-				}
-			}
-		})
-	}
-}
-
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
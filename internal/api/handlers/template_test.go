@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
+)
+
+// MockTaskTemplateRepository is a mock implementation of TaskTemplateRepository
+type MockTaskTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaskTemplateRepository) Create(ctx context.Context, template *models.TaskTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockTaskTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TaskTemplate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskTemplate), args.Error(1)
+}
+
+func (m *MockTaskTemplateRepository) Update(ctx context.Context, template *models.TaskTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockTaskTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskTemplateRepository) GetVisible(ctx context.Context, userID uuid.UUID, teams []string, limit, offset int) ([]*models.TaskTemplate, error) {
+	args := m.Called(ctx, userID, teams, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskTemplate), args.Error(1)
+}
+
+func (m *MockTaskTemplateRepository) CountVisible(ctx context.Context, userID uuid.UUID, teams []string) (int64, error) {
+	args := m.Called(ctx, userID, teams)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskTemplateRepository) Import(ctx context.Context, template *models.TaskTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+// setupTemplateHandlerTest returns a router whose requests are made by a
+// member of the platform team
+func setupTemplateHandlerTest(t *testing.T) (*gin.Engine, *MockTaskTemplateRepository, *MockTaskRepository, *models.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}, Email: "ops@example.com"}
+	engine, err := admission.NewEngine(admission.File{Groups: map[string][]string{
+		"platform": {user.Email},
+		"data":     {"analyst@example.com"},
+	}}, nil)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	templateRepo := new(MockTaskTemplateRepository)
+	taskRepo := new(MockTaskRepository)
+	taskHandler := NewTaskHandler(taskRepo, nil, services.NewTaskService(taskRepo, nil, nil, nil, engine, logger), logger)
+	handler := NewTemplateHandler(templateRepo, taskHandler, engine, logger)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", user)
+		c.Next()
+	})
+	router.POST("/templates", handler.Create)
+	router.GET("/templates", handler.List)
+	router.GET("/templates/:id", handler.GetByID)
+	router.PUT("/templates/:id", handler.Update)
+	router.DELETE("/templates/:id", handler.Delete)
+	router.POST("/templates/:id/tasks", handler.CreateTask)
+
+	return router, templateRepo, taskRepo, user
+}
+
+func templateRequest(method, path string, body any) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func testTemplate(ownerID *uuid.UUID, visibility models.TemplateVisibility, team *string) *models.TaskTemplate {
+	return &models.TaskTemplate{
+		BaseModel:      models.BaseModel{ID: uuid.New()},
+		OwnerID:        ownerID,
+		Name:           "Greeting",
+		ScriptType:     models.ScriptTypeBash,
+		ScriptTemplate: "echo {{shellquote .name}}",
+		Parameters: []models.TemplateParameter{
+			{Name: "name", Type: models.TemplateParameterTypeString, Required: true},
+		},
+		Visibility:     visibility,
+		Team:           team,
+		Priority:       3,
+		TimeoutSeconds: 60,
+		SecurityLevel:  models.SecurityLevelSandboxed,
+	}
+}
+
+func TestTemplateHandler_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		team       string
+		wantStatus int
+	}{
+		{name: "private template", visibility: "private", wantStatus: http.StatusCreated},
+		{name: "default visibility", wantStatus: http.StatusCreated},
+		{name: "own team", visibility: "team", team: "platform", wantStatus: http.StatusCreated},
+		{name: "other team", visibility: "team", team: "data", wantStatus: http.StatusForbidden},
+		{name: "global template", visibility: "global", wantStatus: http.StatusForbidden},
+		{name: "team without visibility", team: "platform", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, templateRepo, _, user := setupTemplateHandlerTest(t)
+			templateRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.TaskTemplate")).Return(nil)
+
+			body := map[string]any{
+				"name":            "Greeting",
+				"script_type":     "bash",
+				"script_template": "echo {{.name}}",
+				"parameters":      []map[string]any{{"name": "name", "type": "string", "default": "world"}},
+			}
+			if tt.visibility != "" {
+				body["visibility"] = tt.visibility
+			}
+			if tt.team != "" {
+				body["team"] = tt.team
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, templateRequest(http.MethodPost, "/templates", body))
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusCreated {
+				var response models.TaskTemplateResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				require.NotNil(t, response.OwnerID)
+				assert.Equal(t, user.ID, *response.OwnerID)
+				assert.Len(t, response.Parameters, 1)
+			} else {
+				templateRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("undeclared parameter", func(t *testing.T) {
+		router, templateRepo, _, _ := setupTemplateHandlerTest(t)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPost, "/templates", map[string]any{
+			"name":            "Greeting",
+			"script_type":     "bash",
+			"script_template": "echo {{.name}}",
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		templateRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		router, templateRepo, _, _ := setupTemplateHandlerTest(t)
+		templateRepo.On("Create", mock.Anything, mock.Anything).Return(database.ErrTaskTemplateExists)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPost, "/templates", map[string]any{
+			"name":            "Greeting",
+			"script_type":     "bash",
+			"script_template": "echo hello",
+		}))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestTemplateHandler_List(t *testing.T) {
+	router, templateRepo, _, user := setupTemplateHandlerTest(t)
+
+	team := "platform"
+	templateRepo.On("GetVisible", mock.Anything, user.ID, []string{"platform"}, 20, 0).
+		Return([]*models.TaskTemplate{testTemplate(nil, models.TemplateVisibilityTeam, &team)}, nil)
+	templateRepo.On("CountVisible", mock.Anything, user.ID, []string{"platform"}).Return(int64(1), nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, templateRequest(http.MethodGet, "/templates", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskTemplateListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Templates, 1)
+	assert.Equal(t, int64(1), response.Total)
+	templateRepo.AssertExpectations(t)
+}
+
+func TestTemplateHandler_GetByID(t *testing.T) {
+	platform := "platform"
+	data := "data"
+	other := uuid.New()
+
+	tests := []struct {
+		name       string
+		template   func(user *models.User) *models.TaskTemplate
+		wantStatus int
+	}{
+		{
+			name: "own private template",
+			template: func(user *models.User) *models.TaskTemplate {
+				return testTemplate(&user.ID, models.TemplateVisibilityPrivate, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "global template",
+			template: func(*models.User) *models.TaskTemplate {
+				return testTemplate(nil, models.TemplateVisibilityGlobal, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "template of own team",
+			template: func(*models.User) *models.TaskTemplate {
+				return testTemplate(&other, models.TemplateVisibilityTeam, &platform)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "template of other team",
+			template: func(*models.User) *models.TaskTemplate {
+				return testTemplate(&other, models.TemplateVisibilityTeam, &data)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "private template of another user",
+			template: func(*models.User) *models.TaskTemplate {
+				return testTemplate(&other, models.TemplateVisibilityPrivate, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, templateRepo, _, user := setupTemplateHandlerTest(t)
+			template := tt.template(user)
+			templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, templateRequest(http.MethodGet, fmt.Sprintf("/templates/%s", template.ID), nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	t.Run("template not found", func(t *testing.T) {
+		router, templateRepo, _, _ := setupTemplateHandlerTest(t)
+		templateRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, database.ErrTaskTemplateNotFound)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodGet, fmt.Sprintf("/templates/%s", uuid.New()), nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestTemplateHandler_Update(t *testing.T) {
+	t.Run("own template", func(t *testing.T) {
+		router, templateRepo, _, user := setupTemplateHandlerTest(t)
+		template := testTemplate(&user.ID, models.TemplateVisibilityTeam, stringPtr("platform"))
+		templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+		templateRepo.On("Update", mock.Anything, template).Return(nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPut, fmt.Sprintf("/templates/%s", template.ID), map[string]any{
+			"visibility": "private",
+		}))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, models.TemplateVisibilityPrivate, template.Visibility)
+		assert.Nil(t, template.Team, "leaving the team visibility drops the team")
+	})
+
+	t.Run("imported template", func(t *testing.T) {
+		router, templateRepo, _, _ := setupTemplateHandlerTest(t)
+		template := testTemplate(nil, models.TemplateVisibilityGlobal, nil)
+		templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPut, fmt.Sprintf("/templates/%s", template.ID), map[string]any{
+			"name": "Renamed",
+		}))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		templateRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete team template of another member", func(t *testing.T) {
+		router, templateRepo, _, _ := setupTemplateHandlerTest(t)
+		other := uuid.New()
+		template := testTemplate(&other, models.TemplateVisibilityTeam, stringPtr("platform"))
+		templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodDelete, fmt.Sprintf("/templates/%s", template.ID), nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		templateRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTemplateHandler_CreateTask(t *testing.T) {
+	t.Run("renders the script with the template defaults", func(t *testing.T) {
+		router, templateRepo, taskRepo, user := setupTemplateHandlerTest(t)
+		template := testTemplate(nil, models.TemplateVisibilityGlobal, nil)
+		templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+		taskRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+			return task.UserID == user.ID &&
+				task.ScriptContent == "echo 'it'\\''s me'" &&
+				task.ScriptType == models.ScriptTypeBash &&
+				task.SecurityLevel == models.SecurityLevelSandboxed &&
+				task.TimeoutSeconds == 60 &&
+				task.Priority == 8
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPost, fmt.Sprintf("/templates/%s/tasks", template.ID), map[string]any{
+			"name":       "Greet me",
+			"parameters": map[string]any{"name": "it's me"},
+			"priority":   8,
+		}))
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing parameter", func(t *testing.T) {
+		router, templateRepo, taskRepo, _ := setupTemplateHandlerTest(t)
+		template := testTemplate(nil, models.TemplateVisibilityGlobal, nil)
+		templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPost, fmt.Sprintf("/templates/%s/tasks", template.ID), map[string]any{
+			"name": "Greet me",
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "parameter name is required")
+		taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rendered script is analyzed", func(t *testing.T) {
+		router, templateRepo, taskRepo, _ := setupTemplateHandlerTest(t)
+		template := testTemplate(nil, models.TemplateVisibilityGlobal, nil)
+		template.ScriptType = models.ScriptTypePython
+		template.ScriptTemplate = "import {{.name}}\n{{.name}}.system('id')"
+		templateRepo.On("GetByID", mock.Anything, template.ID).Return(template, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, templateRequest(http.MethodPost, fmt.Sprintf("/templates/%s/tasks", template.ID), map[string]any{
+			"name":       "Run it",
+			"parameters": map[string]any{"name": "os"},
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// TrashPurgerInterface defines the interface for the trash purge service
+type TrashPurgerInterface interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	PurgeTask(ctx context.Context, id uuid.UUID) error
+}
+
+// TrashHandler handles the tasks in the trash
+type TrashHandler struct {
+	tasks  *TaskHandler
+	purger TrashPurgerInterface
+	logger *slog.Logger
+}
+
+// NewTrashHandler creates a new trash handler. Tasks are listed with the
+// filters of the task handler.
+func NewTrashHandler(taskHandler *TaskHandler, purger TrashPurgerInterface, logger *slog.Logger) *TrashHandler {
+	return &TrashHandler{
+		tasks:  taskHandler,
+		purger: purger,
+		logger: logger,
+	}
+}
+
+// List handles listing the user's tasks in the trash
+//
+//	@Summary		List tasks in the trash
+//	@Description	Retrieves the user's deleted tasks, most recently deleted first, until they are restored or purged. Takes the filters and cursor pagination of the task listing.
+//	@Tags			Trash
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query	int		false	"Maximum number of tasks to return"	default(20)
+//	@Param			cursor			query	string	false	"Cursor of the page to return"
+//	@Param			sort_field		query	string	false	"Sort field: created_at, updated_at, priority or name"	default(updated_at)
+//	@Param			sort_order		query	string	false	"Sort order, asc or desc"	default(desc)
+//	@Param			status			query	string	false	"Comma-separated task statuses"
+//	@Param			script_type		query	string	false	"Comma-separated script types"
+//	@Param			q				query	string	false	"Full-text search on the task name"
+//	@Param			selector		query	string	false	"Label selector, e.g. env=prod,team in (data,ml),!deprecated"
+//	@Success		200				{object}	models.TaskSearchResponse	"Tasks retrieved successfully"
+//	@Failure		400				{object}	models.ErrorResponse		"Invalid query parameters"
+//	@Failure		401				{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		429				{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Router			/tasks/trash [get]
+func (h *TrashHandler) List(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	pagination, err := parseListPagination(c, taskSortFields)
+	if err != nil {
+		h.logger.Warn("invalid cursor pagination parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	// Deleting a task updates it, so the trash lists the most recently
+	// deleted tasks first unless asked otherwise
+	if c.Query("sort_field") == "" {
+		pagination.SortField = "updated_at"
+	}
+
+	filter, _, err := parseTaskFilter(c)
+	if err != nil {
+		h.logger.Warn("invalid task filter parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	filter.UserID = &user.ID
+	filter.Deleted = true
+
+	tasks, paginationResp, err := h.tasks.taskRepo.Search(c.Request.Context(), filter, pagination)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid cursor",
+			})
+			return
+		}
+		h.logger.Error("failed to list the trash", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve tasks",
+		})
+		return
+	}
+
+	taskResponses := make([]models.TaskResponse, len(tasks))
+	for i, task := range tasks {
+		taskResponses[i] = task.ToResponse()
+	}
+
+	h.logger.Debug("trash retrieved successfully", "user_id", user.ID, "count", len(tasks))
+	c.JSON(http.StatusOK, gin.H{
+		"tasks":      taskResponses,
+		"pagination": paginationResp,
+		"limit":      pagination.Limit,
+		"sort_order": pagination.SortOrder,
+		"sort_field": pagination.SortField,
+	})
+}
+
+// Restore handles restoring a task from the trash
+//
+//	@Summary		Restore a task
+//	@Description	Moves a deleted task out of the trash, with its executions and revisions
+//	@Tags			Trash
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Task ID"
+//	@Success		200	{object}	models.TaskResponse		"Task restored successfully"
+//	@Header			200	{string}	ETag					"Version of the task"
+//	@Failure		400	{object}	models.ErrorResponse	"Invalid task ID"
+//	@Failure		401	{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		403	{object}	models.ErrorResponse	"Access denied"
+//	@Failure		404	{object}	models.ErrorResponse	"Task not found in the trash"
+//	@Failure		409	{object}	models.ErrorResponse	"Another task has the same external key"
+//	@Failure		429	{object}	models.ErrorResponse	"Rate limit exceeded"
+//	@Router			/tasks/{id}/restore [post]
+func (h *TrashHandler) Restore(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		h.logger.Warn("invalid task ID", "task_id", taskIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID format",
+		})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	task, err := h.tasks.taskRepo.GetDeletedByID(c.Request.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, database.ErrTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found in the trash",
+			})
+			return
+		}
+		h.logger.Error("failed to get deleted task", "error", err, "task_id", taskID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task",
+		})
+		return
+	}
+
+	if task.UserID != user.ID {
+		h.logger.Warn("user attempted to restore another user's task",
+			"user_id", user.ID, "task_id", taskID, "task_owner_id", task.UserID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Access denied",
+		})
+		return
+	}
+
+	if err := h.tasks.taskRepo.Restore(c.Request.Context(), taskID); err != nil {
+		switch {
+		case errors.Is(err, database.ErrTaskNotFound):
+			// Restored or purged concurrently
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found in the trash",
+			})
+		case errors.Is(err, database.ErrTaskExternalKeyExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Another task has the same external key",
+			})
+		default:
+			h.logger.Error("failed to restore task", "error", err, "task_id", taskID)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to restore task",
+			})
+		}
+		return
+	}
+
+	task, err = h.tasks.taskRepo.GetByID(c.Request.Context(), taskID)
+	if err != nil {
+		h.logger.Error("failed to get restored task", "error", err, "task_id", taskID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve task",
+		})
+		return
+	}
+
+	h.logger.Info("task restored successfully", "task_id", taskID, "user_id", user.ID)
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, task.ToResponse())
+}
+
+// PurgeTask handles permanently deleting a task in the trash
+//
+//	@Summary		Purge a task from the trash
+//	@Description	Permanently deletes a task in the trash, of any user, with its executions and revisions. Requires an admin.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Task ID"
+//	@Success		200	{object}	models.TrashPurgeResponse	"Task purged successfully"
+//	@Failure		400	{object}	models.ErrorResponse		"Invalid task ID"
+//	@Failure		401	{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		403	{object}	models.ErrorResponse		"Admin access required"
+//	@Failure		404	{object}	models.ErrorResponse		"Task not found in the trash"
+//	@Failure		429	{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Router			/admin/trash/{id} [delete]
+func (h *TrashHandler) PurgeTask(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		h.logger.Warn("invalid task ID", "task_id", taskIDStr)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID format",
+		})
+		return
+	}
+
+	if err := h.purger.PurgeTask(c.Request.Context(), taskID); err != nil {
+		if errors.Is(err, database.ErrTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found in the trash",
+			})
+			return
+		}
+		h.logger.Error("failed to purge task", "error", err, "task_id", taskID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to purge task",
+		})
+		return
+	}
+
+	h.logger.Info("task purged from the trash", "task_id", taskID, "admin_id", adminID(c))
+	c.JSON(http.StatusOK, models.TrashPurgeResponse{Purged: 1})
+}
+
+// PurgeAll handles permanently deleting the tasks in the trash
+//
+//	@Summary		Purge the trash
+//	@Description	Permanently deletes the tasks of all users in the trash, or those deleted before the given time, without waiting for the retention to expire. Requires an admin.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			before	query		string	false	"Only purge tasks deleted before this RFC 3339 time"
+//	@Success		200		{object}	models.TrashPurgeResponse	"Trash purged successfully"
+//	@Failure		400		{object}	models.ErrorResponse		"Invalid before parameter"
+//	@Failure		401		{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse		"Admin access required"
+//	@Failure		429		{object}	models.ErrorResponse		"Rate limit exceeded"
+//	@Router			/admin/trash [delete]
+func (h *TrashHandler) PurgeAll(c *gin.Context) {
+	before := time.Now()
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		parsed, err := time.Parse(time.RFC3339, beforeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid before parameter: must be an RFC 3339 time",
+			})
+			return
+		}
+		before = parsed
+	}
+
+	purged, err := h.purger.Purge(c.Request.Context(), before)
+	if err != nil {
+		h.logger.Error("failed to purge the trash", "error", err, "purged", purged)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to purge the trash",
+		})
+		return
+	}
+
+	h.logger.Info("trash purged", "count", purged, "before", before, "admin_id", adminID(c))
+	c.JSON(http.StatusOK, models.TrashPurgeResponse{Purged: purged})
+}
+
+// adminID returns the ID of the admin performing a request, for the logs
+func adminID(c *gin.Context) uuid.UUID {
+	if user := middleware.GetUserFromContext(c); user != nil {
+		return user.ID
+	}
+	return uuid.Nil
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/services"
+)
+
+// MockTrashPurger is a mock implementation of TrashPurgerInterface
+type MockTrashPurger struct {
+	mock.Mock
+}
+
+func (m *MockTrashPurger) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTrashPurger) PurgeTask(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func setupTrashHandlerTest(userID uuid.UUID) (*gin.Engine, *MockTaskRepository, *MockTrashPurger, *TrashHandler) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(MockTaskRepository)
+	mockPurger := new(MockTrashPurger)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewTrashHandler(NewTaskHandler(mockRepo, nil, services.NewTaskService(mockRepo, nil, nil, nil, nil, logger), logger), mockPurger, logger)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{BaseModel: models.BaseModel{ID: userID}})
+		c.Next()
+	})
+	router.GET("/tasks/trash", handler.List)
+	router.POST("/tasks/:id/restore", handler.Restore)
+	router.DELETE("/admin/trash", handler.PurgeAll)
+	router.DELETE("/admin/trash/:id", handler.PurgeTask)
+
+	return router, mockRepo, mockPurger, handler
+}
+
+func TestTrashHandler_List(t *testing.T) {
+	userID := uuid.New()
+	router, mockRepo, _, _ := setupTrashHandlerTest(userID)
+
+	deletedAt := time.Now()
+	task := &models.Task{
+		BaseModel: models.BaseModel{ID: uuid.New()},
+		UserID:    userID,
+		Name:      "Deleted Task",
+		DeletedAt: &deletedAt,
+	}
+	mockRepo.On("Search", mock.Anything, mock.MatchedBy(func(filter database.TaskFilter) bool {
+		return filter.Deleted && filter.UserID != nil && *filter.UserID == userID && len(filter.Statuses) == 1
+	}), mock.MatchedBy(func(req database.CursorPaginationRequest) bool {
+		return req.SortField == "updated_at"
+	})).Return([]*models.Task{task}, database.CursorPaginationResponse{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/trash?status=failed", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskSearchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Tasks, 1)
+	assert.NotNil(t, response.Tasks[0].DeletedAt)
+	assert.Equal(t, "updated_at", response.SortField)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	userID := uuid.New()
+	taskID := uuid.New()
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name       string
+		mockSetup  func(*MockTaskRepository)
+		wantStatus int
+	}{
+		{
+			name: "restores the task",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetDeletedByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID, DeletedAt: &deletedAt}, nil)
+				m.On("Restore", mock.Anything, taskID).Return(nil)
+				m.On("GetByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID, Version: 3}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "not in the trash",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetDeletedByID", mock.Anything, taskID).Return(nil, database.ErrTaskNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "another user's task",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetDeletedByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: uuid.New(), DeletedAt: &deletedAt}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "external key taken",
+			mockSetup: func(m *MockTaskRepository) {
+				m.On("GetDeletedByID", mock.Anything, taskID).Return(&models.Task{BaseModel: models.BaseModel{ID: taskID}, UserID: userID, DeletedAt: &deletedAt}, nil)
+				m.On("Restore", mock.Anything, taskID).Return(database.ErrTaskExternalKeyExists)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo, _, _ := setupTrashHandlerTest(userID)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%s/restore", taskID), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTrashHandler_Purge(t *testing.T) {
+	taskID := uuid.New()
+
+	t.Run("purges a task", func(t *testing.T) {
+		router, _, mockPurger, _ := setupTrashHandlerTest(uuid.New())
+		mockPurger.On("PurgeTask", mock.Anything, taskID).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/admin/trash/%s", taskID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockPurger.AssertExpectations(t)
+	})
+
+	t.Run("task not in the trash", func(t *testing.T) {
+		router, _, mockPurger, _ := setupTrashHandlerTest(uuid.New())
+		mockPurger.On("PurgeTask", mock.Anything, taskID).Return(database.ErrTaskNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/admin/trash/%s", taskID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("purges the trash deleted before a time", func(t *testing.T) {
+		router, _, mockPurger, _ := setupTrashHandlerTest(uuid.New())
+		before := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		mockPurger.On("Purge", mock.Anything, mock.MatchedBy(before.Equal)).Return(int64(7), nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/admin/trash?before=2026-01-02T03:04:05Z", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.TrashPurgeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(7), response.Purged)
+		mockPurger.AssertExpectations(t)
+	})
+
+	t.Run("invalid before", func(t *testing.T) {
+		router, _, _, _ := setupTrashHandlerTest(uuid.New())
+
+		req := httptest.NewRequest(http.MethodDelete, "/admin/trash?before=yesterday", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -175,3 +175,32 @@ func (m *AuthMiddleware) RequireUserID() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireAdmin middleware that ensures the authenticated user is an administrator
+func (m *AuthMiddleware) RequireAdmin(isAdmin func(email string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetUserFromContext(c)
+		if user == nil {
+			m.logger.Warn("user not found in context")
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized",
+			})
+			c.Abort()
+			return
+		}
+
+		if !isAdmin(user.Email) {
+			m.logger.Warn("non-admin user attempting to access an admin endpoint",
+				"user_id", user.ID,
+				"path", c.Request.URL.Path,
+			)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin access required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -546,3 +546,52 @@ func TestAuthMiddleware_Integration(t *testing.T) {
 	// It's more of an integration test but validates the middleware works with real tokens
 	t.Skip("Integration test - requires full JWT service setup")
 }
+
+func TestRequireAdmin(t *testing.T) {
+	logger := logger.New("test", "error")
+	middleware := NewAuthMiddleware(nil, logger.Logger)
+	isAdmin := func(email string) bool { return email == "admin@example.com" }
+
+	tests := []struct {
+		name           string
+		user           *models.User
+		expectedStatus int
+	}{
+		{
+			name:           "admin",
+			user:           &models.User{BaseModel: models.BaseModel{ID: uuid.New()}, Email: "admin@example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not an admin",
+			user:           &models.User{BaseModel: models.BaseModel{ID: uuid.New()}, Email: "test@example.com"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unauthenticated",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set("user", tt.user)
+				}
+				c.Next()
+			})
+			router.Use(middleware.RequireAdmin(isAdmin))
+			router.GET("/admin", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req := httptest.NewRequest("GET", "/admin", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
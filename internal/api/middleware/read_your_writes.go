@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+)
+
+const (
+	// ReadPrimaryHeader makes the reads of a request go to the primary when
+	// set to true, for clients that can't wait for the replicas to catch up
+	ReadPrimaryHeader = "X-Read-Primary"
+
+	// ReadPrimaryCookie holds the time, in Unix milliseconds, until which
+	// the client's reads go to the primary. It is set by mutating requests.
+	ReadPrimaryCookie = "read_primary_until"
+)
+
+// ReadYourWrites makes reads go to the primary database rather than a read
+// replica when they must see writes the replicas may not have yet. The reads
+// of mutating requests see the request's own writes, and for the window
+// after a mutation the client's reads see it too, e.g. the task fetched
+// right after creating it. A client can also ask for the primary with the
+// X-Read-Primary header.
+//
+// The window is tracked with a cookie and, for authenticated users, by user
+// ID in memory, so that clients which don't keep cookies, such as API
+// clients using a bearer token, read their writes too. As the users' windows
+// are kept per server, they only apply when a user's requests reach the
+// server that handled the write; cookies and the header apply on every one.
+type ReadYourWrites struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	users     map[uuid.UUID]time.Time
+	nextPrune time.Time
+}
+
+// NewReadYourWrites creates the read-your-writes middleware, sending a
+// client's reads to the primary for window after its writes. A zero window
+// only sends the reads of mutating requests to the primary.
+func NewReadYourWrites(window time.Duration) *ReadYourWrites {
+	return &ReadYourWrites{
+		window: window,
+		users:  make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Handler returns the middleware for all requests. It opens the window of
+// the user authenticated by a mutating request once the request is handled.
+func (r *ReadYourWrites) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mutation := !isReadMethod(c.Request.Method)
+		if mutation && r.window > 0 {
+			until := time.Now().Add(r.window)
+			maxAge := int((r.window + time.Second - 1) / time.Second)
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(ReadPrimaryCookie, strconv.FormatInt(until.UnixMilli(), 10), maxAge, "/", "", c.Request.TLS != nil, true)
+		}
+
+		if mutation || readPrimaryRequested(c) {
+			c.Request = c.Request.WithContext(database.WithPrimary(c.Request.Context()))
+		}
+		c.Next()
+
+		if mutation && r.window > 0 {
+			if userID, ok := c.Get("user_id"); ok {
+				if id, ok := userID.(uuid.UUID); ok {
+					r.wrote(id, time.Now())
+				}
+			}
+		}
+	}
+}
+
+// AuthenticatedReads returns the middleware sending the reads of a user to
+// the primary within the window after the user's writes. It must follow the
+// authentication middleware, which sets the user.
+func (r *ReadYourWrites) AuthenticatedReads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r.window > 0 && isReadMethod(c.Request.Method) && !database.ReadsPrimary(c.Request.Context()) {
+			if userID, ok := c.Get("user_id"); ok {
+				if id, ok := userID.(uuid.UUID); ok && r.readsPrimary(id, time.Now()) {
+					c.Request = c.Request.WithContext(database.WithPrimary(c.Request.Context()))
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// wrote opens the window of a user, pruning the expired windows at most once
+// per window
+func (r *ReadYourWrites) wrote(userID uuid.UUID, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.After(r.nextPrune) {
+		for id, until := range r.users {
+			if !now.Before(until) {
+				delete(r.users, id)
+			}
+		}
+		r.nextPrune = now.Add(r.window)
+	}
+	r.users[userID] = now.Add(r.window)
+}
+
+// readsPrimary reports whether the window of a user is open
+func (r *ReadYourWrites) readsPrimary(userID uuid.UUID, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.users[userID]
+	return ok && now.Before(until)
+}
+
+// isReadMethod reports whether a request method only reads
+func isReadMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// readPrimaryRequested reports whether the request asks for the primary, with
+// the header or a read-after-write cookie that hasn't expired
+func readPrimaryRequested(c *gin.Context) bool {
+	if requested, err := strconv.ParseBool(c.GetHeader(ReadPrimaryHeader)); err == nil && requested {
+		return true
+	}
+
+	cookie, err := c.Cookie(ReadPrimaryCookie)
+	if err != nil {
+		return false
+	}
+	until, err := strconv.ParseInt(cookie, 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().UnixMilli() < until
+}
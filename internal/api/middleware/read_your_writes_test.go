@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+)
+
+func setupReadYourWritesTest(window time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewReadYourWrites(window).Handler())
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"primary": database.ReadsPrimary(c.Request.Context())})
+	}
+	router.GET("/test", handler)
+	router.POST("/test", handler)
+	router.DELETE("/test", handler)
+	return router
+}
+
+func TestReadYourWrites(t *testing.T) {
+	router := setupReadYourWritesTest(5 * time.Second)
+
+	tests := []struct {
+		method  string
+		header  string
+		primary bool
+	}{
+		{method: http.MethodGet, primary: false},
+		{method: http.MethodGet, header: "true", primary: true},
+		{method: http.MethodGet, header: "false", primary: false},
+		{method: http.MethodPost, primary: true},
+		{method: http.MethodDelete, primary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(ReadPrimaryHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"primary": %t}`, tt.primary), w.Body.String())
+		})
+	}
+}
+
+func TestReadYourWrites_AfterMutation(t *testing.T) {
+	t.Run("reads right after a write go to the primary", func(t *testing.T) {
+		router := setupReadYourWritesTest(5 * time.Second)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/test", nil))
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, ReadPrimaryCookie, cookies[0].Name)
+		assert.Equal(t, 5, cookies[0].MaxAge)
+		assert.True(t, cookies[0].HttpOnly)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(cookies[0])
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.JSONEq(t, `{"primary": true}`, w.Body.String())
+	})
+
+	t.Run("reads after the window go to the replicas", func(t *testing.T) {
+		router := setupReadYourWritesTest(5 * time.Second)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: ReadPrimaryCookie, Value: strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10)})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.JSONEq(t, `{"primary": false}`, w.Body.String())
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		router := setupReadYourWritesTest(0)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/test", nil))
+		assert.JSONEq(t, `{"primary": true}`, w.Body.String())
+		assert.Empty(t, w.Result().Cookies())
+	})
+}
+
+func TestReadYourWrites_AuthenticatedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The user is authenticated by the ID in the Authorization header,
+	// standing in for a bearer token
+	setup := func(window time.Duration) *gin.Engine {
+		readYourWrites := NewReadYourWrites(window)
+		router := gin.New()
+		router.Use(readYourWrites.Handler())
+		authenticate := func(c *gin.Context) {
+			if userID, err := uuid.Parse(c.GetHeader("Authorization")); err == nil {
+				c.Set("user_id", userID)
+			}
+		}
+		handler := func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"primary": database.ReadsPrimary(c.Request.Context())})
+		}
+		protected := router.Group("", authenticate, readYourWrites.AuthenticatedReads())
+		protected.GET("/test", handler)
+		protected.POST("/test", handler)
+		return router
+	}
+	request := func(router *gin.Engine, method string, userID uuid.UUID) string {
+		req := httptest.NewRequest(method, "/test", nil)
+		req.Header.Set("Authorization", userID.String())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	t.Run("reads of the user go to the primary without the cookie", func(t *testing.T) {
+		router := setup(5 * time.Second)
+		userID := uuid.New()
+
+		assert.JSONEq(t, `{"primary": false}`, request(router, http.MethodGet, userID))
+		request(router, http.MethodPost, userID)
+		assert.JSONEq(t, `{"primary": true}`, request(router, http.MethodGet, userID))
+		assert.JSONEq(t, `{"primary": false}`, request(router, http.MethodGet, uuid.New()), "other users read from the replicas")
+	})
+
+	t.Run("reads after the window go to the replicas", func(t *testing.T) {
+		router := setup(50 * time.Millisecond)
+		userID := uuid.New()
+
+		request(router, http.MethodPost, userID)
+		time.Sleep(60 * time.Millisecond)
+		assert.JSONEq(t, `{"primary": false}`, request(router, http.MethodGet, userID))
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		router := setup(0)
+		userID := uuid.New()
+
+		request(router, http.MethodPost, userID)
+		assert.JSONEq(t, `{"primary": false}`, request(router, http.MethodGet, userID))
+	})
+}
+
+func TestReadYourWrites_PrunesExpiredWindows(t *testing.T) {
+	readYourWrites := NewReadYourWrites(time.Second)
+	now := time.Now()
+	expired := uuid.New()
+
+	readYourWrites.wrote(expired, now)
+	readYourWrites.wrote(uuid.New(), now.Add(2*time.Second))
+	assert.Len(t, readYourWrites.users, 1)
+	assert.False(t, readYourWrites.readsPrimary(expired, now.Add(2*time.Second)))
+}
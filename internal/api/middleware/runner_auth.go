@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunnerIDHeader identifies the remote runner making a request
+const RunnerIDHeader = "X-Runner-ID"
+
+var runnerIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// RunnerAuthMiddleware authenticates remote runner agents using pre-shared tokens
+type RunnerAuthMiddleware struct {
+	tokens [][]byte
+	logger *slog.Logger
+}
+
+// NewRunnerAuthMiddleware creates a new runner auth middleware
+func NewRunnerAuthMiddleware(tokens []string, logger *slog.Logger) *RunnerAuthMiddleware {
+	m := &RunnerAuthMiddleware{logger: logger}
+	for _, token := range tokens {
+		if token != "" {
+			m.tokens = append(m.tokens, []byte(token))
+		}
+	}
+	return m
+}
+
+// RequireRunner middleware that requires a valid runner token and runner ID
+func (m *RunnerAuthMiddleware) RequireRunner() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := extractBearerToken(c)
+		if token == "" || !m.validToken(token) {
+			m.logger.Warn("invalid runner token", "client_ip", c.ClientIP())
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid runner token",
+			})
+			c.Abort()
+			return
+		}
+
+		runnerID := c.GetHeader(RunnerIDHeader)
+		if !runnerIDPattern.MatchString(runnerID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Valid X-Runner-ID header required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("runner_id", runnerID)
+
+		c.Next()
+	}
+}
+
+// validToken compares the token against every configured token in constant time
+func (m *RunnerAuthMiddleware) validToken(token string) bool {
+	valid := 0
+	for _, candidate := range m.tokens {
+		valid |= subtle.ConstantTimeCompare([]byte(token), candidate)
+	}
+	return valid == 1
+}
+
+// GetRunnerIDFromContext extracts the authenticated runner ID from gin context
+func GetRunnerIDFromContext(c *gin.Context) string {
+	return c.GetString("runner_id")
+}
+
+// extractBearerToken extracts a bearer token from the Authorization header
+func extractBearerToken(c *gin.Context) string {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
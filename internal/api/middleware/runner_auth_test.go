@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/voidrunnerhq/voidrunner/pkg/logger"
+)
+
+const testRunnerToken = "runner-token-0123456789abcdef0123"
+
+func setupRunnerAuthRouter() *gin.Engine {
+	log := logger.New("debug", "console")
+	middleware := NewRunnerAuthMiddleware([]string{"other-token-0123456789abcdef01234", testRunnerToken}, log.Logger)
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireRunner())
+	router.POST("/runner/jobs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"runner_id": GetRunnerIDFromContext(c)})
+	})
+	return router
+}
+
+func TestRunnerAuthMiddleware_RequireRunner(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorization  string
+		runnerID       string
+		expectedStatus int
+	}{
+		{
+			name:           "valid token and runner ID",
+			authorization:  "Bearer " + testRunnerToken,
+			runnerID:       "runner-01.eu",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			runnerID:       "runner-01",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown token",
+			authorization:  "Bearer not-a-runner-token",
+			runnerID:       "runner-01",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "token without bearer scheme",
+			authorization:  testRunnerToken,
+			runnerID:       "runner-01",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing runner ID",
+			authorization:  "Bearer " + testRunnerToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid runner ID",
+			authorization:  "Bearer " + testRunnerToken,
+			runnerID:       "runner 01/../x",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRunnerAuthRouter()
+
+			req := httptest.NewRequest("POST", "/runner/jobs", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.runnerID != "" {
+				req.Header.Set(RunnerIDHeader, tt.runnerID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), tt.runnerID)
+			}
+		})
+	}
+}
+
+func TestRunnerAuthMiddleware_NoTokensConfigured(t *testing.T) {
+	log := logger.New("debug", "console")
+	middleware := NewRunnerAuthMiddleware([]string{""}, log.Logger)
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireRunner())
+	router.POST("/runner/jobs", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("POST", "/runner/jobs", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	req.Header.Set(RunnerIDHeader, "runner-01")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
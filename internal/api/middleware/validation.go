@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/voidrunnerhq/voidrunner/internal/labels"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -26,6 +27,12 @@ func NewValidationMiddleware(logger *slog.Logger) *ValidationMiddleware {
 	_ = v.RegisterValidation("script_content", validateScriptContent)
 	_ = v.RegisterValidation("script_type", validateScriptType)
 	_ = v.RegisterValidation("task_name", validateTaskName)
+	_ = v.RegisterValidation("capability", validateCapability)
+	_ = v.RegisterValidation("security_level", validateSecurityLevel)
+	_ = v.RegisterValidation("network_mode", validateNetworkMode)
+	_ = v.RegisterValidation("network_destination", validateNetworkDestination)
+	_ = v.RegisterValidation("label_key", validateLabelKey)
+	_ = v.RegisterValidation("label_value", validateLabelValue)
 
 	return &ValidationMiddleware{
 		validator: v,
@@ -80,11 +87,56 @@ func (vm *ValidationMiddleware) ValidateTaskUpdate() gin.HandlerFunc {
 	return vm.ValidateJSON(models.UpdateTaskRequest{})
 }
 
+// ValidateTemplateCreation validates task template creation requests
+func (vm *ValidationMiddleware) ValidateTemplateCreation() gin.HandlerFunc {
+	return vm.ValidateJSON(models.CreateTaskTemplateRequest{})
+}
+
+// ValidateTemplateUpdate validates task template update requests
+func (vm *ValidationMiddleware) ValidateTemplateUpdate() gin.HandlerFunc {
+	return vm.ValidateJSON(models.UpdateTaskTemplateRequest{})
+}
+
+// ValidateTaskFromTemplate validates requests creating a task from a template
+func (vm *ValidationMiddleware) ValidateTaskFromTemplate() gin.HandlerFunc {
+	return vm.ValidateJSON(models.CreateTaskFromTemplateRequest{})
+}
+
+// ValidateBatchTasks validates batches of task operations
+func (vm *ValidationMiddleware) ValidateBatchTasks() gin.HandlerFunc {
+	return vm.ValidateJSON(models.BatchTaskRequest{})
+}
+
+// ValidateBulkTaskAction validates bulk task action requests
+func (vm *ValidationMiddleware) ValidateBulkTaskAction() gin.HandlerFunc {
+	return vm.ValidateJSON(models.BulkTaskActionRequest{})
+}
+
 // ValidateTaskExecutionUpdate validates task execution update requests
 func (vm *ValidationMiddleware) ValidateTaskExecutionUpdate() gin.HandlerFunc {
 	return vm.ValidateJSON(models.UpdateTaskExecutionRequest{})
 }
 
+// ValidateRunnerJobRequest validates runner job claim requests
+func (vm *ValidationMiddleware) ValidateRunnerJobRequest() gin.HandlerFunc {
+	return vm.ValidateJSON(models.RunnerJobRequest{})
+}
+
+// ValidateRunnerHeartbeat validates runner heartbeat requests
+func (vm *ValidationMiddleware) ValidateRunnerHeartbeat() gin.HandlerFunc {
+	return vm.ValidateJSON(models.RunnerHeartbeatRequest{})
+}
+
+// ValidateRunnerLogs validates runner log upload requests
+func (vm *ValidationMiddleware) ValidateRunnerLogs() gin.HandlerFunc {
+	return vm.ValidateJSON(models.RunnerLogRequest{})
+}
+
+// ValidateRunnerResult validates runner result submissions
+func (vm *ValidationMiddleware) ValidateRunnerResult() gin.HandlerFunc {
+	return vm.ValidateJSON(models.RunnerResultRequest{})
+}
+
 // ValidateRequestSize validates request body size
 func (vm *ValidationMiddleware) ValidateRequestSize(maxSize int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -139,11 +191,23 @@ func (vm *ValidationMiddleware) getValidationMessage(err validator.FieldError) s
 	case "oneof":
 		return fmt.Sprintf("%s must be one of: %s", err.Field(), err.Param())
 	case "script_content":
-		return "Script content contains potentially dangerous patterns"
+		return "Script content must not be empty"
 	case "script_type":
 		return "Invalid script type. Supported types: python, javascript, bash, go"
 	case "task_name":
 		return "Task name contains invalid characters or is too long"
+	case "capability":
+		return "Capability must be lowercase alphanumeric with an optional value, e.g. script:python"
+	case "security_level":
+		return "Invalid security level. Supported levels: standard, sandboxed, isolated"
+	case "network_mode":
+		return "Invalid network mode. Supported modes: none, internal, allowlist"
+	case "network_destination":
+		return "Network destination must be a hostname, *.domain wildcard, IP address or CIDR"
+	case "label_key":
+		return "Label key must be a name of at most 63 letters, digits, '-', '_' or '.', with an optional DNS subdomain prefix, e.g. example.com/team"
+	case "label_value":
+		return "Label value must be empty or at most 63 letters, digits, '-', '_' or '.', starting and ending with a letter or digit"
 	default:
 		return fmt.Sprintf("%s failed validation: %s", err.Field(), err.Tag())
 	}
@@ -151,136 +215,11 @@ func (vm *ValidationMiddleware) getValidationMessage(err validator.FieldError) s
 
 // Custom validation functions
 
-// validateScriptContent validates script content for security
+// validateScriptContent validates that script content is not blank. Scripts
+// are checked for dangerous operations by script analysis in the handlers,
+// which knows the script's type.
 func validateScriptContent(fl validator.FieldLevel) bool {
-	content := fl.Field().String()
-	content = strings.ToLower(strings.TrimSpace(content))
-
-	if content == "" {
-		return false
-	}
-
-	// List of dangerous patterns
-	dangerousPatterns := []string{
-		"rm -rf",
-		"rm -r",
-		"rm -f",
-		"rmdir",
-		"del /f",
-		"del /s",
-		"format c:",
-		"mkfs",
-		"dd if=",
-		":(){ :|:& };:", // Fork bomb
-		"chmod 777",
-		"chmod +x",
-		"/etc/passwd",
-		"/etc/shadow",
-		"sudo",
-		"su -",
-		"passwd",
-		"useradd",
-		"userdel",
-		"curl",
-		"wget",
-		"nc -",
-		"netcat",
-		"telnet",
-		"ssh",
-		"scp",
-		"rsync",
-		"ping -f",
-		"iptables",
-		"firewall",
-		"kill -9",
-		"killall",
-		"pkill",
-		"reboot",
-		"shutdown",
-		"halt",
-		"poweroff",
-		"mount",
-		"umount",
-		"fdisk",
-		"crontab",
-		"at ",
-		"batch",
-		"nohup",
-		"disown",
-		"exec(",
-		"eval(",
-		"system(",
-		"shell_exec",
-		"passthru",
-		"proc_open",
-		"popen",
-		"file_get_contents",
-		"file_put_contents",
-		"fopen",
-		"fwrite",
-		"include(",
-		"require(",
-		"import os",
-		"import subprocess",
-		"import sys",
-		"__import__",
-		"exec(",
-		"eval(",
-		"compile(",
-		"open(",
-		"input(",
-		"raw_input(",
-		"execfile(",
-		"reload(",
-		"exit(",
-		"quit(",
-	}
-
-	// Check for dangerous patterns
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(content, pattern) {
-			return false
-		}
-	}
-
-	// Check for suspicious file paths
-	suspiciousPaths := []string{
-		"/bin/",
-		"/sbin/",
-		"/usr/bin/",
-		"/usr/sbin/",
-		"/etc/",
-		"/var/",
-		"/tmp/",
-		"/proc/",
-		"/sys/",
-		"/dev/",
-		"/root/",
-		"/home/",
-		"c:\\",
-		"c:/",
-		"../",
-		"./",
-		"~/",
-	}
-
-	for _, path := range suspiciousPaths {
-		if strings.Contains(content, path) {
-			return false
-		}
-	}
-
-	// Check for base64 encoded content that might hide malicious code
-	if strings.Contains(content, "base64") || strings.Contains(content, "b64decode") {
-		return false
-	}
-
-	// Check for hex encoded content
-	if strings.Contains(content, "\\x") || strings.Contains(content, "0x") {
-		return false
-	}
-
-	return true
+	return strings.TrimSpace(fl.Field().String()) != ""
 }
 
 // validateScriptType validates script type
@@ -320,6 +259,38 @@ func validateTaskName(fl validator.FieldLevel) bool {
 	return true
 }
 
+// validateCapability validates a required worker capability
+func validateCapability(fl validator.FieldLevel) bool {
+	capability := strings.ToLower(strings.TrimSpace(fl.Field().String()))
+	return models.ValidateCapability(capability) == nil
+}
+
+// validateSecurityLevel validates a task security level
+func validateSecurityLevel(fl validator.FieldLevel) bool {
+	return models.ValidateSecurityLevel(models.TaskSecurityLevel(fl.Field().String())) == nil
+}
+
+// validateNetworkMode validates a task network mode
+func validateNetworkMode(fl validator.FieldLevel) bool {
+	return models.ValidateNetworkMode(models.TaskNetworkMode(fl.Field().String())) == nil
+}
+
+// validateNetworkDestination validates a network allowlist entry
+func validateNetworkDestination(fl validator.FieldLevel) bool {
+	destination := strings.ToLower(strings.TrimSpace(fl.Field().String()))
+	return models.ValidateNetworkDestination(destination) == nil
+}
+
+// validateLabelKey validates a task label key
+func validateLabelKey(fl validator.FieldLevel) bool {
+	return labels.ValidateKey(fl.Field().String()) == nil
+}
+
+// validateLabelValue validates a task label value
+func validateLabelValue(fl validator.FieldLevel) bool {
+	return labels.ValidateValue(fl.Field().String()) == nil
+}
+
 // Common validation middleware factories
 
 // TaskValidation returns validation middleware for task endpoints
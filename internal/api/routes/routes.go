@@ -2,35 +2,43 @@ package routes
 
 import (
 	"context"
+	"expvar"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
 	"github.com/voidrunnerhq/voidrunner/internal/api/handlers"
 	"github.com/voidrunnerhq/voidrunner/internal/api/middleware"
 	"github.com/voidrunnerhq/voidrunner/internal/auth"
 	"github.com/voidrunnerhq/voidrunner/internal/config"
 	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 	"github.com/voidrunnerhq/voidrunner/internal/services"
 	"github.com/voidrunnerhq/voidrunner/internal/worker"
 	"github.com/voidrunnerhq/voidrunner/pkg/logger"
 )
 
-func Setup(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbConn *database.Connection, repos *database.Repositories, authService *auth.Service, taskExecutionService *services.TaskExecutionService, taskExecutorService *services.TaskExecutorService, workerManager worker.WorkerManager) {
-	setupMiddleware(router, cfg, log)
-	setupRoutes(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager)
+func Setup(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbConn *database.Connection, repos *database.Repositories, authService *auth.Service, taskExecutionService *services.TaskExecutionService, taskExecutorService *services.TaskExecutorService, workerManager worker.WorkerManager, runnerService *services.RunnerService, bulkJobService *services.BulkJobService, trashPurgeService *services.TrashPurgeService, admissionEngine *admission.Engine) {
+	readYourWrites := middleware.NewReadYourWrites(cfg.Database.ReadAfterWriteWindow)
+	setupMiddleware(router, cfg, log, readYourWrites)
+	setupRoutes(router, cfg, log, readYourWrites, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager, runnerService, bulkJobService, trashPurgeService, admissionEngine)
 }
 
-func setupMiddleware(router *gin.Engine, cfg *config.Config, log *logger.Logger) {
+func setupMiddleware(router *gin.Engine, cfg *config.Config, log *logger.Logger, readYourWrites *middleware.ReadYourWrites) {
 	router.Use(middleware.SecurityHeaders())
 	router.Use(middleware.RequestID())
+	router.Use(readYourWrites.Handler())
 	router.Use(middleware.CORS(cfg.CORS.AllowedOrigins, cfg.CORS.AllowedMethods, cfg.CORS.AllowedHeaders))
 	router.Use(log.GinLogger())
 	router.Use(log.GinRecovery())
 	router.Use(middleware.ErrorHandler())
 }
 
-func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbConn *database.Connection, repos *database.Repositories, authService *auth.Service, taskExecutionService *services.TaskExecutionService, taskExecutorService *services.TaskExecutorService, workerManager worker.WorkerManager) {
+func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, readYourWrites *middleware.ReadYourWrites, dbConn *database.Connection, repos *database.Repositories, authService *auth.Service, taskExecutionService *services.TaskExecutionService, taskExecutorService *services.TaskExecutorService, workerManager worker.WorkerManager, runnerService *services.RunnerService, bulkJobService *services.BulkJobService, trashPurgeService *services.TrashPurgeService, admissionEngine *admission.Engine) {
 	healthHandler := handlers.NewHealthHandler()
 
 	// Add health checks for different components
@@ -48,6 +56,9 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbC
 
 	router.GET("/health", healthHandler.Health)
 	router.GET("/ready", healthHandler.Readiness)
+	if cfg.Server.MetricsEnabled {
+		router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	}
 
 	// Worker-specific health endpoint (only available when embedded workers are enabled)
 	if cfg.HasEmbeddedWorkers() && workerManager != nil {
@@ -102,14 +113,24 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbC
 
 		// Protected endpoints
 		protected := v1.Group("")
-		protected.Use(authMiddleware.RequireAuth())
+		protected.Use(authMiddleware.RequireAuth(), readYourWrites.AuthenticatedReads())
 		{
 			protected.GET("/auth/me", authHandler.Me)
 		}
 
 		// Task management endpoints
-		taskHandler := handlers.NewTaskHandler(repos.Tasks, log.Logger)
-		executionHandler := handlers.NewTaskExecutionHandler(repos.Tasks, repos.TaskExecutions, taskExecutionService, log.Logger)
+		var imageResolver executor.ImageResolver
+		if taskExecutorService != nil {
+			imageResolver = taskExecutorService
+		}
+		scriptAnalyzer := analyzer.NewPipeline(models.ScriptAnalysisMode(cfg.Executor.ScriptAnalysisMode))
+		taskService := services.NewTaskService(repos.Tasks, dbConn, imageResolver, scriptAnalyzer, admissionEngine, log.Logger)
+		taskHandler := handlers.NewTaskHandler(repos.Tasks, repos.TaskRevisions, taskService, log.Logger)
+		var outputStore *executor.OutputStore
+		if cfg.Executor.OutputSpillDir != "" {
+			outputStore = executor.NewOutputStore(cfg.Executor.OutputSpillDir)
+		}
+		executionHandler := handlers.NewTaskExecutionHandler(repos.Tasks, repos.TaskRevisions, repos.TaskExecutions, repos.NetworkEvents, taskExecutionService, admissionEngine, outputStore, log.Logger)
 		taskValidation := middleware.TaskValidation(log.Logger)
 
 		// Use different rate limits for test vs production
@@ -137,6 +158,15 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbC
 			taskRateLimit,
 			taskHandler.List,
 		)
+		protected.GET("/tasks/export",
+			taskRateLimit,
+			taskHandler.Export,
+		)
+		protected.POST("/tasks/import",
+			taskValidation.ValidateRequestSize(handlers.MaxManifestBytes),
+			taskCreationRateLimit,
+			taskHandler.Import,
+		)
 		protected.GET("/tasks/:id",
 			taskRateLimit,
 			taskHandler.GetByID,
@@ -152,6 +182,88 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbC
 			taskHandler.Delete,
 		)
 
+		// Trash of deleted tasks
+		var trashPurger handlers.TrashPurgerInterface
+		if trashPurgeService != nil {
+			trashPurger = trashPurgeService
+		}
+		trashHandler := handlers.NewTrashHandler(taskHandler, trashPurger, log.Logger)
+		protected.GET("/tasks/trash",
+			taskRateLimit,
+			trashHandler.List,
+		)
+		protected.POST("/tasks/:id/restore",
+			taskRateLimit,
+			trashHandler.Restore,
+		)
+
+		// Task revisions
+		protected.GET("/tasks/:id/revisions",
+			taskRateLimit,
+			taskHandler.ListRevisions,
+		)
+		protected.GET("/tasks/:id/revisions/diff",
+			taskRateLimit,
+			taskHandler.DiffRevisions,
+		)
+		protected.GET("/tasks/:id/revisions/:revision",
+			taskRateLimit,
+			taskHandler.GetRevision,
+		)
+		protected.POST("/tasks/:id/revisions/:revision/rollback",
+			taskRateLimit,
+			taskHandler.RollbackRevision,
+		)
+		protected.POST("/tasks/:id/revisions/:revision/executions",
+			executionCreationRateLimit,
+			executionHandler.CreateForRevision,
+		)
+
+		// Task templates
+		templateHandler := handlers.NewTemplateHandler(repos.TaskTemplates, taskHandler, admissionEngine, log.Logger)
+		protected.POST("/templates",
+			middleware.RequestSizeLimit(log.Logger),
+			taskRateLimit,
+			taskValidation.ValidateTemplateCreation(),
+			templateHandler.Create,
+		)
+		protected.GET("/templates",
+			taskRateLimit,
+			templateHandler.List,
+		)
+		protected.GET("/templates/:id",
+			taskRateLimit,
+			templateHandler.GetByID,
+		)
+		protected.PUT("/templates/:id",
+			middleware.RequestSizeLimit(log.Logger),
+			taskRateLimit,
+			taskValidation.ValidateTemplateUpdate(),
+			templateHandler.Update,
+		)
+		protected.DELETE("/templates/:id",
+			taskRateLimit,
+			templateHandler.Delete,
+		)
+		protected.POST("/templates/:id/tasks",
+			middleware.RequestSizeLimit(log.Logger),
+			taskCreationRateLimit,
+			taskValidation.ValidateTaskFromTemplate(),
+			templateHandler.CreateTask,
+		)
+
+		// Custom image inventory
+		protected.GET("/images",
+			taskRateLimit,
+			taskHandler.ListImages,
+		)
+
+		// Label inventory
+		protected.GET("/labels",
+			taskRateLimit,
+			taskHandler.ListLabels,
+		)
+
 		// Task execution operations
 		protected.POST("/tasks/:id/executions",
 			executionCreationRateLimit,
@@ -161,10 +273,26 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbC
 			taskExecutionRateLimit,
 			executionHandler.ListByTaskID,
 		)
+		protected.GET("/executions",
+			taskExecutionRateLimit,
+			executionHandler.List,
+		)
 		protected.GET("/executions/:id",
 			taskExecutionRateLimit,
 			executionHandler.GetByID,
 		)
+		protected.GET("/executions/:id/network-events",
+			taskExecutionRateLimit,
+			executionHandler.NetworkEvents,
+		)
+		protected.GET("/executions/:id/output",
+			taskExecutionRateLimit,
+			executionHandler.GetOutput,
+		)
+		protected.GET("/executions/:id/output/:stream",
+			taskExecutionRateLimit,
+			executionHandler.Output,
+		)
 		protected.PUT("/executions/:id",
 			middleware.RequestSizeLimit(log.Logger),
 			taskExecutionRateLimit,
@@ -175,6 +303,102 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, log *logger.Logger, dbC
 			taskExecutionRateLimit,
 			executionHandler.Cancel,
 		)
+
+		// Bulk operations, performed as background jobs
+		if bulkJobService != nil {
+			bulkHandler := handlers.NewBulkHandler(repos.BulkJobs, bulkJobService, repos.Tasks, taskService, repos.TaskExecutions, taskExecutionService, log.Logger)
+			protected.POST("/tasks:action",
+				taskCreationRateLimit,
+				customMethod(map[string]gin.HandlersChain{
+					":batch": {
+						taskValidation.ValidateRequestSize(handlers.MaxBatchRequestBytes),
+						taskValidation.ValidateBatchTasks(),
+						bulkHandler.Batch,
+					},
+					":bulk": {
+						middleware.RequestSizeLimit(log.Logger),
+						taskValidation.ValidateBulkTaskAction(),
+						bulkHandler.Bulk,
+					},
+				}),
+			)
+			protected.GET("/jobs",
+				taskRateLimit,
+				bulkHandler.ListJobs,
+			)
+			protected.GET("/jobs/:id",
+				taskRateLimit,
+				bulkHandler.GetJob,
+			)
+		}
+
+		// Admin endpoints (only available to the users listed in ADMIN_EMAILS)
+		admin := protected.Group("/admin")
+		admin.Use(authMiddleware.RequireAdmin(cfg.IsAdmin))
+		{
+			if trashPurgeService != nil {
+				admin.DELETE("/trash/:id",
+					taskRateLimit,
+					trashHandler.PurgeTask,
+				)
+				admin.DELETE("/trash",
+					taskRateLimit,
+					trashHandler.PurgeAll,
+				)
+			}
+		}
+
+		// Remote runner endpoints (only available when runner tokens are configured)
+		if cfg.HasRunnerAPI() && runnerService != nil {
+			runnerHandler := handlers.NewRunnerHandler(runnerService, cfg.Runner.MaxPollWait, log.Logger)
+			runnerAuth := middleware.NewRunnerAuthMiddleware(cfg.Runner.Tokens, log.Logger)
+			runnerValidation := middleware.NewValidationMiddleware(log.Logger)
+
+			runner := v1.Group("/runner")
+			runner.Use(runnerAuth.RequireRunner())
+			runner.Use(middleware.RequestSizeLimit(log.Logger))
+			{
+				runner.POST("/jobs",
+					runnerValidation.ValidateRunnerJobRequest(),
+					runnerHandler.ClaimJob,
+				)
+				runner.POST("/jobs/:id/heartbeat",
+					runnerValidation.ValidateRunnerHeartbeat(),
+					runnerHandler.Heartbeat,
+				)
+				runner.POST("/jobs/:id/logs",
+					runnerValidation.ValidateRunnerLogs(),
+					runnerHandler.AppendLogs,
+				)
+				runner.POST("/jobs/:id/result",
+					runnerValidation.ValidateRunnerResult(),
+					runnerHandler.SubmitResult,
+				)
+			}
+		}
+	}
+}
+
+// customMethod routes custom methods of a collection, such as /tasks:batch,
+// to their handlers. Gin can't match the colon of such paths literally, so
+// the route is registered with a parameter and the method dispatched here.
+func customMethod(methods map[string]gin.HandlersChain) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chain, ok := methods[c.Param("action")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Not found",
+			})
+			c.Abort()
+			return
+		}
+
+		for _, handler := range chain {
+			handler(c)
+			if c.IsAborted() {
+				return
+			}
+		}
 	}
 }
 
@@ -197,6 +421,14 @@ func (d *DatabaseHealthChecker) CheckHealth() (status string, err error) {
 	return "ready", nil
 }
 
+// HealthDetails reports the connection pool statistics, to spot a starved pool
+func (d *DatabaseHealthChecker) HealthDetails() any {
+	if d.conn == nil {
+		return nil
+	}
+	return d.conn.PoolStats()
+}
+
 // ExecutorHealthChecker implements health checking for Docker executor
 type ExecutorHealthChecker struct {
 	service *services.TaskExecutorService
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -47,7 +48,7 @@ func setupTestRouter(t *testing.T) *gin.Engine {
 	var workerManager worker.WorkerManager                  // nil is fine for route testing
 
 	// Setup routes
-	Setup(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager)
+	Setup(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager, nil, nil, nil, nil)
 
 	return router
 }
@@ -485,6 +486,164 @@ func TestRateLimitingMiddleware(t *testing.T) {
 	}
 }
 
+func TestRunnerRoutes(t *testing.T) {
+	t.Run("runner routes are absent without runner tokens", func(t *testing.T) {
+		router := setupTestRouter(t)
+
+		req := httptest.NewRequest("POST", "/api/v1/runner/jobs", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("runner routes require a runner token", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+
+		cfg := &config.Config{
+			CORS: config.CORSConfig{
+				AllowedOrigins: []string{"http://localhost:3000"},
+				AllowedMethods: []string{"POST"},
+				AllowedHeaders: []string{"Content-Type", "Authorization"},
+			},
+			Runner: config.RunnerConfig{
+				Tokens:      []string{"runner-token-0123456789abcdef0123"},
+				MaxPollWait: 20 * time.Second,
+			},
+		}
+		var buf bytes.Buffer
+		log := logger.NewWithWriter("info", "json", &buf)
+		runnerService := services.NewRunnerService(nil, &database.Repositories{}, time.Minute, log.Logger)
+
+		Setup(router, cfg, log, nil, &database.Repositories{}, &auth.Service{}, nil, nil, nil, runnerService, nil, nil, nil)
+
+		for _, path := range []string{
+			"/api/v1/runner/jobs",
+			"/api/v1/runner/jobs/123e4567-e89b-12d3-a456-426614174001/heartbeat",
+			"/api/v1/runner/jobs/123e4567-e89b-12d3-a456-426614174001/logs",
+			"/api/v1/runner/jobs/123e4567-e89b-12d3-a456-426614174001/result",
+		} {
+			req := httptest.NewRequest("POST", path, nil)
+			req.Header.Set("Authorization", "Bearer user-jwt")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+		}
+	})
+}
+
+func TestBulkRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	cfg := &config.Config{
+		CORS: config.CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+			AllowedMethods: []string{"GET", "POST"},
+			AllowedHeaders: []string{"Content-Type", "Authorization"},
+		},
+	}
+	var buf bytes.Buffer
+	log := logger.NewWithWriter("info", "json", &buf)
+	bulkJobService := services.NewBulkJobService(nil, log.Logger)
+
+	Setup(router, cfg, log, nil, &database.Repositories{}, &auth.Service{}, nil, nil, nil, nil, bulkJobService, nil, nil)
+
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/tasks:batch"},
+		{"POST", "/api/v1/tasks:bulk"},
+		{"GET", "/api/v1/jobs"},
+		{"GET", "/api/v1/jobs/123e4567-e89b-12d3-a456-426614174001"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, route.path)
+	}
+}
+
+func TestTrashRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	cfg := &config.Config{
+		CORS: config.CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+			AllowedMethods: []string{"GET", "POST"},
+			AllowedHeaders: []string{"Content-Type", "Authorization"},
+		},
+	}
+	var buf bytes.Buffer
+	log := logger.NewWithWriter("info", "json", &buf)
+	trashPurgeService := services.NewTrashPurgeService(nil, nil, time.Hour, time.Hour, log.Logger)
+
+	Setup(router, cfg, log, nil, &database.Repositories{}, &auth.Service{}, nil, nil, nil, nil, nil, trashPurgeService, nil)
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/api/v1/tasks/trash"},
+		{"POST", "/api/v1/tasks/123e4567-e89b-12d3-a456-426614174001/restore"},
+		{"DELETE", "/api/v1/admin/trash"},
+		{"DELETE", "/api/v1/admin/trash/123e4567-e89b-12d3-a456-426614174001"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, route.path)
+	}
+}
+
+func TestCustomMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var called []string
+	record := func(name string, abort bool) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			called = append(called, name)
+			if abort {
+				c.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			c.Next()
+		}
+	}
+
+	router := gin.New()
+	router.POST("/tasks:action", customMethod(map[string]gin.HandlersChain{
+		":batch": {record("validate", false), record("batch", false)},
+		":bulk":  {record("validate", true), record("bulk", false)},
+	}))
+
+	tests := []struct {
+		path       string
+		wantCode   int
+		wantCalled []string
+	}{
+		{"/tasks:batch", http.StatusOK, []string{"validate", "batch"}},
+		{"/tasks:bulk", http.StatusBadRequest, []string{"validate"}},
+		{"/tasks:unknown", http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			called = nil
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, httptest.NewRequest("POST", tt.path, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
+
 // Benchmark test for route setup performance
 func BenchmarkSetup(b *testing.B) {
 	gin.SetMode(gin.TestMode)
@@ -509,7 +668,7 @@ func BenchmarkSetup(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		router := gin.New()
-		Setup(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager)
+		Setup(router, cfg, log, dbConn, repos, authService, taskExecutionService, taskExecutorService, workerManager, nil, nil, nil, nil)
 	}
 }
 
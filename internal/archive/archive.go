@@ -0,0 +1,59 @@
+// Package archive writes archives of records, as gzip-compressed NDJSON, to
+// a directory on the local filesystem or to an S3-compatible bucket.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+)
+
+// Store stores archives by key. Keys are slash-separated paths, which stores
+// may prefix.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// NewStore creates the store of the configured archive backend. It returns
+// nil when archiving is disabled.
+func NewStore(cfg *config.ArchiveConfig) (Store, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "filesystem":
+		return NewFileStore(cfg.Dir), nil
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			Prefix:          cfg.S3Prefix,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("invalid archive backend: %s", cfg.Backend)
+	}
+}
+
+// EncodeNDJSON encodes records as gzip-compressed NDJSON, one JSON object
+// per line
+func EncodeNDJSON[T any](records []T) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, fmt.Errorf("failed to encode archive record: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
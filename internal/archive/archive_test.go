@@ -0,0 +1,73 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+)
+
+func TestEncodeNDJSON(t *testing.T) {
+	type record struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	data, err := EncodeNDJSON([]record{{1, "first"}, {2, "second\nline"}})
+	require.NoError(t, err)
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(gz)
+
+	var decoded []record
+	for scanner.Scan() {
+		var r record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		decoded = append(decoded, r)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []record{{1, "first"}, {2, "second\nline"}}, decoded)
+}
+
+func TestFileStore_Put(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+
+	require.NoError(t, store.Put(context.Background(), "executions/2026/01/02/batch.ndjson.gz", []byte("archive")))
+
+	data, err := os.ReadFile(filepath.Join(dir, "executions", "2026", "01", "02", "batch.ndjson.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(data))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "executions", "2026", "01", "02"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+
+	assert.Error(t, store.Put(context.Background(), "../outside.ndjson.gz", []byte("archive")))
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore(&config.ArchiveConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = NewStore(&config.ArchiveConfig{Backend: "filesystem", Dir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &FileStore{}, store)
+
+	store, err = NewStore(&config.ArchiveConfig{Backend: "s3", S3Endpoint: "http://localhost:9000", S3Bucket: "archive"})
+	require.NoError(t, err)
+	assert.IsType(t, &S3Store{}, store)
+
+	_, err = NewStore(&config.ArchiveConfig{Backend: "ftp"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+func TestURIEncode(t *testing.T) {
+	assert.Equal(t, "/bucket/a-b_c.d~e/f%20g%2Bh", uriEncode("/bucket/a-b_c.d~e/f g+h"))
+}
+
+func TestS3Store_Put(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "archive",
+		Prefix:          "voidrunner/",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	require.NoError(t, store.Put(context.Background(), "executions/batch 1.ndjson.gz", []byte("archive")))
+
+	require.NotNil(t, got)
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, "/archive/voidrunner/executions/batch%201.ndjson.gz", got.URL.EscapedPath())
+	assert.Equal(t, "archive", string(body))
+	assert.Equal(t, "20260102T030405Z", got.Header.Get("X-Amz-Date"))
+	assert.Equal(t, sha256Hex([]byte("archive")), got.Header.Get("X-Amz-Content-Sha256"))
+	assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260102/eu-west-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`, got.Header.Get("Authorization"))
+}
+
+func TestS3Store_PutError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>SignatureDoesNotMatch</Code></Error>"))
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "archive", PathStyle: true})
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), "batch.ndjson.gz", []byte("archive"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SignatureDoesNotMatch")
+}
+
+func TestNewS3Store_InvalidEndpoint(t *testing.T) {
+	_, err := NewS3Store(S3Config{Endpoint: "s3.amazonaws.com", Bucket: "archive"})
+	assert.Error(t, err)
+}
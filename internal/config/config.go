@@ -2,12 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 type Config struct {
@@ -20,6 +23,13 @@ type Config struct {
 	Redis           RedisConfig
 	Queue           QueueConfig
 	Worker          WorkerConfig
+	Runner          RunnerConfig
+	Admission       AdmissionConfig
+	Templates       TemplatesConfig
+	Trash           TrashConfig
+	Admin           AdminConfig
+	Retention       RetentionConfig
+	Archive         ArchiveConfig
 	EmbeddedWorkers bool // Enable worker pool in API server process
 }
 
@@ -27,15 +37,48 @@ type ServerConfig struct {
 	Port string
 	Host string
 	Env  string
+
+	// MetricsEnabled serves the process metrics, such as the database pool
+	// statistics, as expvar JSON at /debug/vars
+	MetricsEnabled bool
 }
 
 type DatabaseConfig struct {
+	// URL, when set, is the connection URL of the primary and replaces the
+	// individual connection settings below
+	URL string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Database string
 	SSLMode  string
+
+	// Pool and statement tunables, shared by the primary and the replicas.
+	// Zero leaves the value set by the connection string, such as its
+	// pool_max_conns parameter, and otherwise the DefaultDatabase* value.
+	MaxConns                 int
+	MinConns                 int
+	MaxConnLifetime          time.Duration
+	MaxConnIdleTime          time.Duration
+	HealthCheckPeriod        time.Duration
+	ConnectTimeout           time.Duration
+	StatementTimeout         time.Duration
+	IdleInTransactionTimeout time.Duration
+
+	// Read replicas, as connection URLs, that listing and count queries are
+	// spread over. Replicas failing their health check are skipped until
+	// they pass it again; without healthy replicas reads go to the primary.
+	// After a mutation, the client's reads go to the primary for the
+	// read-after-write window, which should exceed the replicas' lag.
+	ReplicaURLs                []string
+	ReplicaHealthCheckInterval time.Duration
+	ReadAfterWriteWindow       time.Duration
+
+	// LogPoolStats logs the pool statistics every metrics interval at info
+	// level instead of debug level
+	LogPoolStats bool
 }
 
 type LoggerConfig struct {
@@ -58,7 +101,10 @@ type JWTConfig struct {
 }
 
 type ExecutorConfig struct {
+	Backend               string
 	DockerEndpoint        string
+	PodmanEndpoint        string
+	SandboxCgroupParent   string
 	DefaultMemoryLimitMB  int
 	DefaultCPUQuota       int64
 	DefaultPidsLimit      int64
@@ -67,11 +113,46 @@ type ExecutorConfig struct {
 	BashImage             string
 	JavaScriptImage       string
 	GoImage               string
+	PythonPoolSize        int
+	BashPoolSize          int
+	JavaScriptPoolSize    int
+	GoPoolSize            int
 	EnableSeccomp         bool
 	SeccompProfilePath    string
 	EnableAppArmor        bool
 	AppArmorProfile       string
 	ExecutionUser         string
+
+	// Run containers without the resource limits the runtime cannot enforce
+	// instead of refusing them
+	AllowUnenforcedLimits bool
+
+	// OCI runtime selection; empty runtime names use the daemon default
+	DefaultRuntime          string
+	RuntimesByScriptType    map[string]string
+	RuntimesBySecurityLevel map[string]string
+
+	// Repositories tasks may use as their custom image, e.g. "python" or
+	// "ghcr.io/acme/*"; empty disables custom task images
+	AllowedImageRepositories []string
+
+	// Egress proxy sidecar image and the address ranges the internal network
+	// mode allows (empty for the private ranges)
+	EgressProxyImage string
+	InternalCIDRs    []string
+
+	// Script analysis policy: "block" rejects scripts with findings, "warn"
+	// returns them with the saved task and "audit" only logs them
+	ScriptAnalysisMode string
+
+	// Output kept per stream (stdout and stderr) of an execution; the full
+	// output of truncated executions is spilled to OutputSpillDir, when set,
+	// for download through the API. The directory must be on a volume shared
+	// by the API servers and schedulers; remote runners never spill.
+	OutputMaxBytes      int
+	OutputMaxLines      int
+	OutputSpillDir      string
+	OutputMaxSpillBytes int64
 }
 
 type RedisConfig struct {
@@ -112,6 +193,75 @@ type WorkerConfig struct {
 	CleanupInterval        time.Duration
 	StaleTaskThreshold     time.Duration
 	WorkerIDPrefix         string
+	Capabilities           []string
+}
+
+// RunnerConfig configures remote runner agents. The server side uses Tokens and
+// MaxPollWait; the agent side (cmd/runner) uses the remaining fields.
+type RunnerConfig struct {
+	Tokens            []string
+	MaxPollWait       time.Duration
+	LeaseTimeout      time.Duration
+	APIURL            string
+	Token             string
+	ID                string
+	Capabilities      []string
+	PollWait          time.Duration
+	HeartbeatInterval time.Duration
+}
+
+// AdmissionConfig configures task admission policies. Without a policy file,
+// tasks are only bound by the executor settings.
+type AdmissionConfig struct {
+	PolicyFile string
+}
+
+// TemplatesConfig configures the task templates imported when the API starts
+type TemplatesConfig struct {
+	Dir string
+}
+
+// TrashConfig configures the trash of deleted tasks. Tasks are purged with
+// their executions once they have been in the trash for Retention; the
+// trash is checked every PurgeInterval.
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// AdminConfig configures the users with access to the admin endpoints,
+// identified by their email
+type AdminConfig struct {
+	Emails []string
+}
+
+// RetentionConfig configures the execution retention job of the scheduler.
+// Executions beyond the newest KeepLast of their task are deleted, the
+// monthly partitions of executions that all are older than MaxAge are
+// dropped, and the output of executions older than OutputMaxAge is dropped
+// while their summary is kept; zero disables a rule. With DryRun, runs only
+// report what they would do.
+type RetentionConfig struct {
+	KeepLast     int
+	MaxAge       time.Duration
+	OutputMaxAge time.Duration
+	Interval     time.Duration
+	DryRun       bool
+}
+
+// ArchiveConfig configures where executions are archived before retention
+// deletes them or drops their output. Backend is "filesystem", writing to
+// Dir, or "s3", writing to an S3-compatible bucket; empty disables archiving.
+type ArchiveConfig struct {
+	Backend           string
+	Dir               string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3Prefix          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
 }
 
 func Load() (*Config, error) {
@@ -122,14 +272,32 @@ func Load() (*Config, error) {
 			Port: getEnv("SERVER_PORT", "8080"),
 			Host: getEnv("SERVER_HOST", "localhost"),
 			Env:  getEnv("SERVER_ENV", "development"),
+
+			MetricsEnabled: getEnvBool("SERVER_METRICS_ENABLED", false),
 		},
 		Database: DatabaseConfig{
+			URL:      getEnv("DATABASE_URL", ""),
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
 			User:     getEnv("DB_USER", "postgres"),
 			Password: getEnv("DB_PASSWORD", ""),
 			Database: getEnv("DB_NAME", "voidrunner"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			MaxConns:                 getEnvInt("DB_MAX_CONNS", 0),
+			MinConns:                 getEnvInt("DB_MIN_CONNS", 0),
+			MaxConnLifetime:          getEnvDuration("DB_MAX_CONN_LIFETIME", 0),
+			MaxConnIdleTime:          getEnvDuration("DB_MAX_CONN_IDLE_TIME", 0),
+			HealthCheckPeriod:        getEnvDuration("DB_HEALTH_CHECK_PERIOD", 0),
+			ConnectTimeout:           getEnvDuration("DB_CONNECT_TIMEOUT", 0),
+			StatementTimeout:         getEnvDuration("DB_STATEMENT_TIMEOUT", 0),
+			IdleInTransactionTimeout: getEnvDuration("DB_IDLE_IN_TRANSACTION_TIMEOUT", 0),
+
+			ReplicaURLs:                getEnvSlice("DB_REPLICA_URLS", nil),
+			ReplicaHealthCheckInterval: getEnvDuration("DB_REPLICA_HEALTH_CHECK_INTERVAL", 10*time.Second),
+			ReadAfterWriteWindow:       getEnvDuration("DB_READ_AFTER_WRITE_WINDOW", DefaultDatabaseReadAfterWriteWindow),
+
+			LogPoolStats: getEnvBool("DB_LOG_POOL_STATS", false),
 		},
 		Logger: LoggerConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -138,7 +306,7 @@ func Load() (*Config, error) {
 		CORS: CORSConfig{
 			AllowedOrigins: getEnvSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:5173"}),
 			AllowedMethods: getEnvSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-			AllowedHeaders: getEnvSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Request-ID"}),
+			AllowedHeaders: getEnvSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Request-ID", "X-Read-Primary"}),
 		},
 		JWT: JWTConfig{
 			SecretKey:            getEnv("JWT_SECRET_KEY", "your-secret-key-change-in-production"),
@@ -148,7 +316,10 @@ func Load() (*Config, error) {
 			Audience:             getEnv("JWT_AUDIENCE", "voidrunner-api"),
 		},
 		Executor: ExecutorConfig{
+			Backend:               getEnv("EXECUTOR_BACKEND", "docker"),
 			DockerEndpoint:        getEnv("DOCKER_ENDPOINT", "unix:///var/run/docker.sock"),
+			PodmanEndpoint:        getEnv("PODMAN_ENDPOINT", ""),
+			SandboxCgroupParent:   getEnv("EXECUTOR_SANDBOX_CGROUP_PARENT", ""),
 			DefaultMemoryLimitMB:  getEnvInt("EXECUTOR_DEFAULT_MEMORY_LIMIT_MB", 128),
 			DefaultCPUQuota:       getEnvInt64("EXECUTOR_DEFAULT_CPU_QUOTA", 50000),
 			DefaultPidsLimit:      getEnvInt64("EXECUTOR_DEFAULT_PIDS_LIMIT", 128),
@@ -157,11 +328,33 @@ func Load() (*Config, error) {
 			BashImage:             getEnv("EXECUTOR_BASH_IMAGE", "alpine:latest"),
 			JavaScriptImage:       getEnv("EXECUTOR_JAVASCRIPT_IMAGE", "node:18-alpine"),
 			GoImage:               getEnv("EXECUTOR_GO_IMAGE", "golang:1.21-alpine"),
+			PythonPoolSize:        getEnvInt("EXECUTOR_PYTHON_POOL_SIZE", 0),
+			BashPoolSize:          getEnvInt("EXECUTOR_BASH_POOL_SIZE", 0),
+			JavaScriptPoolSize:    getEnvInt("EXECUTOR_JAVASCRIPT_POOL_SIZE", 0),
+			GoPoolSize:            getEnvInt("EXECUTOR_GO_POOL_SIZE", 0),
 			EnableSeccomp:         getEnvBool("EXECUTOR_ENABLE_SECCOMP", true),
 			SeccompProfilePath:    getEnv("EXECUTOR_SECCOMP_PROFILE_PATH", "/opt/voidrunner/seccomp-profile.json"),
 			EnableAppArmor:        getEnvBool("EXECUTOR_ENABLE_APPARMOR", false),
 			AppArmorProfile:       getEnv("EXECUTOR_APPARMOR_PROFILE", "voidrunner-executor"),
 			ExecutionUser:         getEnv("EXECUTOR_EXECUTION_USER", "1000:1000"),
+
+			AllowUnenforcedLimits: getEnvBool("EXECUTOR_ALLOW_UNENFORCED_LIMITS", false),
+
+			DefaultRuntime:          getEnv("EXECUTOR_DEFAULT_RUNTIME", ""),
+			RuntimesByScriptType:    getEnvMap("EXECUTOR_RUNTIMES_BY_SCRIPT_TYPE"),
+			RuntimesBySecurityLevel: getEnvMap("EXECUTOR_RUNTIMES_BY_SECURITY_LEVEL"),
+
+			AllowedImageRepositories: getEnvSlice("EXECUTOR_ALLOWED_IMAGE_REPOSITORIES", nil),
+
+			EgressProxyImage: getEnv("EXECUTOR_EGRESS_PROXY_IMAGE", "voidrunner/egress-proxy:latest"),
+			InternalCIDRs:    getEnvSlice("EXECUTOR_INTERNAL_CIDRS", nil),
+
+			ScriptAnalysisMode: getEnv("EXECUTOR_SCRIPT_ANALYSIS_MODE", "block"),
+
+			OutputMaxBytes:      getEnvInt("EXECUTOR_OUTPUT_MAX_BYTES", 1024*1024),
+			OutputMaxLines:      getEnvInt("EXECUTOR_OUTPUT_MAX_LINES", 10000),
+			OutputSpillDir:      getEnv("EXECUTOR_OUTPUT_SPILL_DIR", ""),
+			OutputMaxSpillBytes: getEnvInt64("EXECUTOR_OUTPUT_MAX_SPILL_BYTES", 100*1024*1024),
 		},
 		Redis: RedisConfig{
 			Host:               getEnv("REDIS_HOST", "localhost"),
@@ -199,6 +392,49 @@ func Load() (*Config, error) {
 			CleanupInterval:        getEnvDuration("WORKER_CLEANUP_INTERVAL", 5*time.Minute),
 			StaleTaskThreshold:     getEnvDuration("WORKER_STALE_TASK_THRESHOLD", 2*time.Hour),
 			WorkerIDPrefix:         getEnv("WORKER_ID_PREFIX", "voidrunner-worker"),
+			Capabilities:           getEnvSlice("WORKER_CAPABILITIES", nil),
+		},
+		Runner: RunnerConfig{
+			Tokens:            getEnvSlice("RUNNER_TOKENS", nil),
+			MaxPollWait:       getEnvDuration("RUNNER_MAX_POLL_WAIT", 20*time.Second),
+			LeaseTimeout:      getEnvDuration("RUNNER_LEASE_TIMEOUT", 2*time.Minute),
+			APIURL:            getEnv("RUNNER_API_URL", "http://localhost:8080"),
+			Token:             getEnv("RUNNER_TOKEN", ""),
+			ID:                getEnv("RUNNER_ID", ""),
+			Capabilities:      getEnvSlice("RUNNER_CAPABILITIES", nil),
+			PollWait:          getEnvDuration("RUNNER_POLL_WAIT", 20*time.Second),
+			HeartbeatInterval: getEnvDuration("RUNNER_HEARTBEAT_INTERVAL", 15*time.Second),
+		},
+		Admission: AdmissionConfig{
+			PolicyFile: getEnv("ADMISSION_POLICY_FILE", ""),
+		},
+		Templates: TemplatesConfig{
+			Dir: getEnv("TEMPLATE_DIR", ""),
+		},
+		Trash: TrashConfig{
+			Retention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", 1*time.Hour),
+		},
+		Admin: AdminConfig{
+			Emails: getEnvSlice("ADMIN_EMAILS", nil),
+		},
+		Retention: RetentionConfig{
+			KeepLast:     getEnvInt("EXECUTION_RETENTION_KEEP_LAST", 0),
+			MaxAge:       getEnvDuration("EXECUTION_RETENTION_MAX_AGE", 0),
+			OutputMaxAge: getEnvDuration("EXECUTION_RETENTION_OUTPUT_MAX_AGE", 0),
+			Interval:     getEnvDuration("EXECUTION_RETENTION_INTERVAL", 24*time.Hour),
+			DryRun:       getEnvBool("EXECUTION_RETENTION_DRY_RUN", false),
+		},
+		Archive: ArchiveConfig{
+			Backend:           getEnv("ARCHIVE_BACKEND", ""),
+			Dir:               getEnv("ARCHIVE_DIR", ""),
+			S3Endpoint:        getEnv("ARCHIVE_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			S3Region:          getEnv("ARCHIVE_S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("ARCHIVE_S3_BUCKET", ""),
+			S3Prefix:          getEnv("ARCHIVE_S3_PREFIX", ""),
+			S3AccessKeyID:     getEnv("ARCHIVE_S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("ARCHIVE_S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:       getEnvBool("ARCHIVE_S3_PATH_STYLE", false),
 		},
 		EmbeddedWorkers: getEnvBool("EMBEDDED_WORKERS", true), // Default true for development simplicity
 	}
@@ -219,16 +455,49 @@ func (c *Config) validate() error {
 		return fmt.Errorf("invalid server port: %s", c.Server.Port)
 	}
 
-	if c.Database.Host == "" {
-		return fmt.Errorf("database host is required")
+	if c.Database.URL != "" {
+		if !isPostgresURL(c.Database.URL) {
+			return fmt.Errorf("invalid DATABASE_URL: must be a postgres:// URL with a host")
+		}
+	} else {
+		if c.Database.Host == "" {
+			return fmt.Errorf("database host is required")
+		}
+
+		if c.Database.User == "" {
+			return fmt.Errorf("database user is required")
+		}
+
+		if c.Database.Database == "" {
+			return fmt.Errorf("database name is required")
+		}
+	}
+
+	if c.Database.MaxConns < 0 || c.Database.MinConns < 0 {
+		return fmt.Errorf("database max and min connections must not be negative")
 	}
 
-	if c.Database.User == "" {
-		return fmt.Errorf("database user is required")
+	if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		return fmt.Errorf("database min connections must not exceed max connections")
 	}
 
-	if c.Database.Database == "" {
-		return fmt.Errorf("database name is required")
+	if c.Database.MaxConnLifetime < 0 || c.Database.MaxConnIdleTime < 0 || c.Database.HealthCheckPeriod < 0 ||
+		c.Database.ConnectTimeout < 0 || c.Database.StatementTimeout < 0 || c.Database.IdleInTransactionTimeout < 0 {
+		return fmt.Errorf("database pool durations and timeouts must not be negative")
+	}
+
+	for _, replicaURL := range c.Database.ReplicaURLs {
+		if !isPostgresURL(replicaURL) {
+			return fmt.Errorf("invalid database replica URL: must be a postgres:// URL with a host")
+		}
+	}
+
+	if len(c.Database.ReplicaURLs) > 0 && c.Database.ReplicaHealthCheckInterval <= 0 {
+		return fmt.Errorf("database replica health check interval must be positive")
+	}
+
+	if c.Database.ReadAfterWriteWindow < 0 {
+		return fmt.Errorf("database read-after-write window must not be negative")
 	}
 
 	if c.JWT.SecretKey == "" {
@@ -243,6 +512,12 @@ func (c *Config) validate() error {
 		return fmt.Errorf("JWT refresh token duration must be positive")
 	}
 
+	switch c.Executor.Backend {
+	case "docker", "podman", "process":
+	default:
+		return fmt.Errorf("executor backend must be docker, podman or process, got: %s", c.Executor.Backend)
+	}
+
 	if c.Executor.DefaultMemoryLimitMB <= 0 {
 		return fmt.Errorf("executor default memory limit must be positive")
 	}
@@ -267,6 +542,76 @@ func (c *Config) validate() error {
 		return fmt.Errorf("executor Bash image must be specified")
 	}
 
+	poolSizes := []int{
+		c.Executor.PythonPoolSize,
+		c.Executor.BashPoolSize,
+		c.Executor.JavaScriptPoolSize,
+		c.Executor.GoPoolSize,
+	}
+	for _, size := range poolSizes {
+		if size < 0 || size > MaxExecutorWarmPoolSize {
+			return fmt.Errorf("executor warm pool sizes must be between 0 and %d", MaxExecutorWarmPoolSize)
+		}
+	}
+
+	for scriptType, runtime := range c.Executor.RuntimesByScriptType {
+		if err := models.ValidateScriptType(models.ScriptType(scriptType)); err != nil {
+			return fmt.Errorf("executor runtime mapping: %w", err)
+		}
+		if runtime == "" {
+			return fmt.Errorf("executor runtime for script type %s must not be empty", scriptType)
+		}
+	}
+
+	for level, runtime := range c.Executor.RuntimesBySecurityLevel {
+		if err := models.ValidateSecurityLevel(models.TaskSecurityLevel(level)); err != nil {
+			return fmt.Errorf("executor runtime mapping: %w", err)
+		}
+		if runtime == "" {
+			return fmt.Errorf("executor runtime for security level %s must not be empty", level)
+		}
+	}
+
+	hasRuntimes := c.Executor.DefaultRuntime != "" ||
+		len(c.Executor.RuntimesByScriptType) > 0 ||
+		len(c.Executor.RuntimesBySecurityLevel) > 0
+	if c.Executor.Backend == "process" && hasRuntimes {
+		return fmt.Errorf("executor runtimes cannot be configured for the process backend")
+	}
+
+	for _, repository := range c.Executor.AllowedImageRepositories {
+		name := strings.TrimSuffix(repository, "/*")
+		if name == "" || strings.ContainsAny(name, "*@ ") {
+			return fmt.Errorf("invalid executor allowed image repository: %q", repository)
+		}
+	}
+	if c.Executor.Backend == "process" && len(c.Executor.AllowedImageRepositories) > 0 {
+		return fmt.Errorf("executor allowed image repositories cannot be configured for the process backend")
+	}
+
+	if c.Executor.EgressProxyImage == "" {
+		return fmt.Errorf("executor egress proxy image is required")
+	}
+	for _, cidr := range c.Executor.InternalCIDRs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			return fmt.Errorf("invalid executor internal CIDR: %q", cidr)
+		}
+	}
+
+	if err := models.ValidateScriptAnalysisMode(models.ScriptAnalysisMode(c.Executor.ScriptAnalysisMode)); err != nil {
+		return fmt.Errorf("executor script analysis mode: %w", err)
+	}
+
+	if c.Executor.OutputMaxBytes <= 0 {
+		return fmt.Errorf("executor output max bytes must be positive")
+	}
+	if c.Executor.OutputMaxLines < 0 {
+		return fmt.Errorf("executor output max lines must not be negative")
+	}
+	if c.Executor.OutputSpillDir != "" && c.Executor.OutputMaxSpillBytes < int64(c.Executor.OutputMaxBytes) {
+		return fmt.Errorf("executor output max spill bytes must be at least the output max bytes")
+	}
+
 	// Redis validation
 	if c.Redis.Host == "" {
 		return fmt.Errorf("Redis host is required")
@@ -354,6 +699,85 @@ func (c *Config) validate() error {
 		return fmt.Errorf("worker ID prefix is required")
 	}
 
+	if err := models.ValidateCapabilities(c.Worker.Capabilities); err != nil {
+		return fmt.Errorf("worker capabilities are invalid: %w", err)
+	}
+
+	// Runner validation
+	for _, token := range c.Runner.Tokens {
+		if len(token) < MinRunnerTokenLength {
+			return fmt.Errorf("runner tokens must be at least %d characters", MinRunnerTokenLength)
+		}
+	}
+
+	if c.Runner.MaxPollWait <= 0 || c.Runner.MaxPollWait >= DefaultServerWriteTimeout {
+		return fmt.Errorf("runner max poll wait must be positive and less than the server write timeout")
+	}
+
+	if c.Runner.LeaseTimeout <= 0 {
+		return fmt.Errorf("runner lease timeout must be positive")
+	}
+
+	if c.Runner.PollWait <= 0 {
+		return fmt.Errorf("runner poll wait must be positive")
+	}
+
+	if c.Runner.HeartbeatInterval <= 0 {
+		return fmt.Errorf("runner heartbeat interval must be positive")
+	}
+
+	if err := models.ValidateCapabilities(c.Runner.Capabilities); err != nil {
+		return fmt.Errorf("runner capabilities are invalid: %w", err)
+	}
+
+	if c.Trash.Retention <= 0 {
+		return fmt.Errorf("trash retention must be positive")
+	}
+
+	if c.Trash.PurgeInterval <= 0 {
+		return fmt.Errorf("trash purge interval must be positive")
+	}
+
+	for _, email := range c.Admin.Emails {
+		if !strings.Contains(email, "@") {
+			return fmt.Errorf("admin emails are invalid: %q is not an email address", email)
+		}
+	}
+
+	if c.Retention.KeepLast < 0 {
+		return fmt.Errorf("execution retention keep last must not be negative")
+	}
+
+	if c.Retention.MaxAge < 0 {
+		return fmt.Errorf("execution retention max age must not be negative")
+	}
+
+	if c.Retention.OutputMaxAge < 0 {
+		return fmt.Errorf("execution retention output max age must not be negative")
+	}
+
+	if c.Retention.Interval <= 0 {
+		return fmt.Errorf("execution retention interval must be positive")
+	}
+
+	switch c.Archive.Backend {
+	case "":
+	case "filesystem":
+		if c.Archive.Dir == "" {
+			return fmt.Errorf("archive dir is required for the filesystem archive backend")
+		}
+	case "s3":
+		if c.Archive.S3Bucket == "" || c.Archive.S3AccessKeyID == "" || c.Archive.S3SecretAccessKey == "" {
+			return fmt.Errorf("archive S3 bucket and credentials are required for the s3 archive backend")
+		}
+		endpoint, err := url.Parse(c.Archive.S3Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("archive S3 endpoint must be an http or https URL: %q", c.Archive.S3Endpoint)
+		}
+	default:
+		return fmt.Errorf("invalid archive backend: %s (must be filesystem or s3)", c.Archive.Backend)
+	}
+
 	// Embedded workers validation
 	if c.EmbeddedWorkers {
 		// When embedded workers are enabled, Redis and Queue must be properly configured
@@ -385,6 +809,50 @@ func (c *Config) HasEmbeddedWorkers() bool {
 	return c.EmbeddedWorkers
 }
 
+// HasRunnerAPI reports whether remote runner agents may connect to this server
+func (c *Config) HasRunnerAPI() bool {
+	return len(c.Runner.Tokens) > 0
+}
+
+// HasExecutionRetention reports whether a retention rule is configured
+func (c *Config) HasExecutionRetention() bool {
+	return c.Retention.KeepLast > 0 || c.Retention.MaxAge > 0 || c.Retention.OutputMaxAge > 0
+}
+
+// IsAdmin reports whether the user with the given email is an admin
+func (c *Config) IsAdmin(email string) bool {
+	for _, admin := range c.Admin.Emails {
+		if strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnectionString returns the connection URL of the primary database,
+// DATABASE_URL if set and otherwise built from the individual settings
+func (c *DatabaseConfig) ConnectionString() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
+	return fmt.Sprintf(
+		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		c.User,
+		c.Password,
+		c.Host,
+		c.Port,
+		c.Database,
+		c.SSLMode,
+	)
+}
+
+// isPostgresURL reports whether rawURL is a postgres:// URL with a host
+func isPostgresURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") && u.Host != ""
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -403,6 +871,26 @@ func getEnvSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvMap parses a comma-separated list of key=value pairs. Entries without
+// a value are kept with an empty value so validation can reject them.
+func getEnvMap(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(entry, "=")
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		duration, err := time.ParseDuration(value)
@@ -21,6 +21,7 @@ const (
 	DefaultExecutorMemoryLimit   = DefaultExecutorMemoryLimitMB * 1024 * 1024 // 512MB in bytes
 	DefaultExecutorCPUQuota      = 100000                                     // 1 CPU core
 	DefaultExecutorPidsLimit     = 128                                        // Max processes
+	MaxExecutorWarmPoolSize      = 64                                         // Idle containers per image
 
 	// Queue and processing defaults
 	DefaultRetryDelay           = 30 * time.Second
@@ -28,11 +29,43 @@ const (
 	DefaultHealthCheckInterval2 = 30 * time.Second // For queue/retry processors
 	DefaultCleanupTimeout       = 30 * time.Second
 
+	// Runner defaults
+	MinRunnerTokenLength      = 32
+	DefaultRunnerPollInterval = 1 * time.Second
+
 	// Task defaults
 	DefaultTaskTimeout = 300 // 5 minutes in seconds
 
+	// Bulk job defaults
+	DefaultBulkJobConcurrency      = 4                // Jobs processed at once
+	DefaultBulkJobProgressInterval = 1 * time.Second  // How often progress is saved
+	DefaultBulkOperationTimeout    = 30 * time.Second // Per operation of a job
+	DefaultBulkJobHeartbeat        = 15 * time.Second // How often running jobs report alive
+	DefaultBulkJobHeartbeatTimeout = 1 * time.Minute  // Jobs not reported alive since are failed
+
+	// Trash defaults
+	DefaultTrashPurgeBatchSize = 500 // Tasks purged per statement
+
+	// Execution retention defaults
+	DefaultRetentionBatchSize     = 100             // Executions archived per archive file
+	DefaultArchiveUploadTimeout   = 5 * time.Minute // Per archive file
+	MaxArchiveErrorResponseLength = 4096            // Bytes of an S3 error response kept
+
+	// Execution partition defaults
+	DefaultExecutionPartitionsAhead        = 3             // Monthly partitions created ahead of the current one
+	DefaultExecutionPartitionCheckInterval = 6 * time.Hour // How often future partitions are created
+
 	// Database defaults
-	DefaultDatabaseTimeout = 30 * time.Second
+	DefaultDatabaseTimeout                  = 30 * time.Second
+	DefaultDatabaseMaxConns                 = 25
+	DefaultDatabaseMinConns                 = 5
+	DefaultDatabaseMaxConnLifetime          = 1 * time.Hour
+	DefaultDatabaseMaxConnIdleTime          = 30 * time.Minute
+	DefaultDatabaseHealthCheckPeriod        = 5 * time.Minute
+	DefaultDatabaseConnectTimeout           = 10 * time.Second
+	DefaultDatabaseStatementTimeout         = 30 * time.Second
+	DefaultDatabaseIdleInTransactionTimeout = 60 * time.Second
+	DefaultDatabaseReadAfterWriteWindow     = 5 * time.Second // Reads go to the primary this long after a mutation
 
 	// Monitoring intervals
 	DefaultMetricsInterval = 1 * time.Minute
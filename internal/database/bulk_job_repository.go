@@ -0,0 +1,249 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// bulkJobColumns are the columns selected for a bulk job, in the order
+// scanBulkJob expects them. Listings select no results.
+const (
+	bulkJobColumns        = `id, user_id, kind, action, filter, status, total, processed, succeeded, failed, results, error, started_at, completed_at, created_at, updated_at`
+	bulkJobSummaryColumns = `id, user_id, kind, action, filter, status, total, processed, succeeded, failed, '[]'::jsonb, error, started_at, completed_at, created_at, updated_at`
+)
+
+// bulkJobRepository implements BulkJobRepository interface
+type bulkJobRepository struct {
+	querier Querier
+}
+
+// NewBulkJobRepository creates a new bulk job repository
+func NewBulkJobRepository(conn *Connection) BulkJobRepository {
+	return &bulkJobRepository{
+		querier: conn.Pool,
+	}
+}
+
+// NewBulkJobRepositoryWithTx creates a new bulk job repository with transaction
+func NewBulkJobRepositoryWithTx(tx pgx.Tx) BulkJobRepository {
+	return &bulkJobRepository{
+		querier: tx,
+	}
+}
+
+// Create creates a new bulk job
+func (r *bulkJobRepository) Create(ctx context.Context, job *models.BulkJob) error {
+	if job == nil {
+		return fmt.Errorf("bulk job cannot be nil")
+	}
+
+	if job.ID == uuid.Nil {
+		job.ID = models.NewID()
+	}
+	if job.Status == "" {
+		job.Status = models.BulkJobStatusPending
+	}
+	if job.Results == nil {
+		job.Results = []models.BulkOperationResult{}
+	}
+
+	query := `
+		INSERT INTO bulk_jobs (id, user_id, kind, action, filter, status, total, processed, succeeded, failed, results, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+
+	err := r.querier.QueryRow(ctx, query,
+		job.ID,
+		job.UserID,
+		job.Kind,
+		job.Action,
+		job.Filter,
+		job.Status,
+		job.Total,
+		job.Processed,
+		job.Succeeded,
+		job.Failed,
+		job.Results,
+		job.Error,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create bulk job: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a bulk job by ID, with its results
+func (r *bulkJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BulkJob, error) {
+	query := `SELECT ` + bulkJobColumns + ` FROM bulk_jobs WHERE id = $1`
+
+	job, err := scanBulkJob(r.querier.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBulkJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get bulk job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetByUserID retrieves the bulk jobs of a user, newest first, without their results
+func (r *bulkJobRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.BulkJob, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT ` + bulkJobSummaryColumns + `
+		FROM bulk_jobs
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.querier.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk jobs by user ID: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.BulkJob
+	for rows.Next() {
+		job, err := scanBulkJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bulk job row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bulk job rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// CountByUserID returns the number of bulk jobs of a user
+func (r *bulkJobRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `SELECT COUNT(*) FROM bulk_jobs WHERE user_id = $1`
+
+	var count int64
+	err := r.querier.QueryRow(ctx, query, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count bulk jobs by user ID: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateProgress saves the status, progress and results of a bulk job
+func (r *bulkJobRepository) UpdateProgress(ctx context.Context, job *models.BulkJob) error {
+	if job == nil {
+		return fmt.Errorf("bulk job cannot be nil")
+	}
+
+	query := `
+		UPDATE bulk_jobs
+		SET status = $2, processed = $3, succeeded = $4, failed = $5, results = $6, error = $7,
+			started_at = $8, completed_at = $9, heartbeat_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.querier.QueryRow(ctx, query,
+		job.ID,
+		job.Status,
+		job.Processed,
+		job.Succeeded,
+		job.Failed,
+		job.Results,
+		job.Error,
+		job.StartedAt,
+		job.CompletedAt,
+	).Scan(&job.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrBulkJobNotFound
+		}
+		return fmt.Errorf("failed to update bulk job progress: %w", err)
+	}
+
+	return nil
+}
+
+// Heartbeat records that the jobs, performed by this server, are alive
+func (r *bulkJobRepository) Heartbeat(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE bulk_jobs
+		SET heartbeat_at = NOW()
+		WHERE id = ANY($1) AND status IN ('pending', 'running')
+	`
+
+	if _, err := r.querier.Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("failed to record bulk job heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// FailStale fails the pending and running jobs whose last heartbeat is older
+// than the given time, which were left behind by a server that stopped, and
+// returns how many there were
+func (r *bulkJobRepository) FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	query := `
+		UPDATE bulk_jobs
+		SET status = 'failed', error = $2, completed_at = NOW(), updated_at = NOW()
+		WHERE status IN ('pending', 'running') AND heartbeat_at < $1
+	`
+
+	result, err := r.querier.Exec(ctx, query, heartbeatBefore, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale bulk jobs: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// scanBulkJob scans a row of bulkJobColumns
+func scanBulkJob(row pgx.Row) (*models.BulkJob, error) {
+	var job models.BulkJob
+	err := row.Scan(
+		&job.ID,
+		&job.UserID,
+		&job.Kind,
+		&job.Action,
+		&job.Filter,
+		&job.Status,
+		&job.Total,
+		&job.Processed,
+		&job.Succeeded,
+		&job.Failed,
+		&job.Results,
+		&job.Error,
+		&job.StartedAt,
+		&job.CompletedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func TestBulkJobRepository_Create(t *testing.T) {
+	t.Run("sets defaults", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		now := time.Now()
+		row := &MockRow{data: []interface{}{now, now}}
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+		job := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch, Total: 3}
+		err := repo.Create(context.Background(), job)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, job.ID)
+		assert.Equal(t, models.BulkJobStatusPending, job.Status)
+		assert.NotNil(t, job.Results)
+		assert.Equal(t, now, job.CreatedAt)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("nil job", func(t *testing.T) {
+		repo := &bulkJobRepository{}
+		err := repo.Create(context.Background(), nil)
+		assert.EqualError(t, err, "bulk job cannot be nil")
+	})
+}
+
+func TestBulkJobRepository_GetByID(t *testing.T) {
+	t.Run("existing job", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		id := uuid.New()
+		userID := uuid.New()
+		row := &MockRow{data: []interface{}{id, userID}}
+		mockQuerier.On("QueryRow", mock.Anything, mock.MatchedBy(func(sql string) bool {
+			return assert.Contains(t, sql, "FROM bulk_jobs WHERE id = $1")
+		}), mock.Anything).Return(row)
+
+		job, err := repo.GetByID(context.Background(), id)
+
+		require.NoError(t, err)
+		assert.Equal(t, id, job.ID)
+		assert.Equal(t, userID, job.UserID)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{err: pgx.ErrNoRows})
+
+		job, err := repo.GetByID(context.Background(), uuid.New())
+
+		assert.ErrorIs(t, err, ErrBulkJobNotFound)
+		assert.Nil(t, job)
+	})
+}
+
+func TestBulkJobRepository_UpdateProgress(t *testing.T) {
+	t.Run("job not found", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{err: pgx.ErrNoRows})
+
+		err := repo.UpdateProgress(context.Background(), &models.BulkJob{BaseModel: models.BaseModel{ID: uuid.New()}})
+		assert.ErrorIs(t, err, ErrBulkJobNotFound)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{err: errors.New("connection lost")})
+
+		err := repo.UpdateProgress(context.Background(), &models.BulkJob{BaseModel: models.BaseModel{ID: uuid.New()}})
+		assert.ErrorContains(t, err, "failed to update bulk job progress")
+	})
+}
+
+func TestBulkJobRepository_GetByUserID(t *testing.T) {
+	mockQuerier := new(MockQuerier)
+	repo := &bulkJobRepository{querier: mockQuerier}
+
+	userID := uuid.New()
+	rows := &MockRows{rows: [][]interface{}{{uuid.New(), userID}, {uuid.New(), userID}}}
+	mockQuerier.On("Query", mock.Anything, mock.MatchedBy(func(sql string) bool {
+		return assert.Contains(t, sql, "'[]'::jsonb")
+	}), []interface{}{userID, 10, 0}).Return(rows, nil)
+
+	jobs, err := repo.GetByUserID(context.Background(), userID, 0, -1)
+
+	require.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestBulkJobRepository_Heartbeat(t *testing.T) {
+	t.Run("updates the unfinished jobs", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		ids := []uuid.UUID{uuid.New(), uuid.New()}
+		mockQuerier.On("Exec", mock.Anything, mock.MatchedBy(func(sql string) bool {
+			return assert.Contains(t, sql, "SET heartbeat_at = NOW()") &&
+				assert.Contains(t, sql, "status IN ('pending', 'running')")
+		}), []interface{}{ids}).Return(pgconn.NewCommandTag("UPDATE 2"), nil)
+
+		require.NoError(t, repo.Heartbeat(context.Background(), ids))
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("no jobs", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &bulkJobRepository{querier: mockQuerier}
+
+		require.NoError(t, repo.Heartbeat(context.Background(), nil))
+		mockQuerier.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBulkJobRepository_FailStale(t *testing.T) {
+	mockQuerier := new(MockQuerier)
+	repo := &bulkJobRepository{querier: mockQuerier}
+
+	before := time.Now().Add(-time.Minute)
+	mockQuerier.On("Exec", mock.Anything, mock.MatchedBy(func(sql string) bool {
+		return assert.Contains(t, sql, "WHERE status IN ('pending', 'running') AND heartbeat_at < $1")
+	}), []interface{}{before, "server stopped"}).Return(pgconn.NewCommandTag("UPDATE 2"), nil)
+
+	failed, err := repo.FailStale(context.Background(), before, "server stopped")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), failed)
+	mockQuerier.AssertExpectations(t)
+}
@@ -2,19 +2,33 @@ package database
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/voidrunnerhq/voidrunner/internal/config"
 )
 
-// Connection represents a database connection pool
+// Connection represents a database connection pool, with the pools of the
+// read replicas, if any
 type Connection struct {
 	Pool   *pgxpool.Pool
 	logger *slog.Logger
+
+	// logPoolStats logs pool statistics at info level instead of debug level
+	logPoolStats bool
+
+	replicas          []*replica
+	nextReplica       atomic.Uint64
+	stopReplicaChecks context.CancelFunc
+	replicaChecks     sync.WaitGroup
 }
 
 // NewConnection creates a new database connection pool
@@ -27,33 +41,11 @@ func NewConnection(cfg *config.DatabaseConfig, logger *slog.Logger) (*Connection
 		logger = slog.Default()
 	}
 
-	connStr := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-		cfg.SSLMode,
-	)
-
-	poolConfig, err := pgxpool.ParseConfig(connStr)
+	poolConfig, err := newPoolConfig(cfg.ConnectionString(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database connection string: %w", err)
 	}
 
-	// Configure connection pool settings for optimal performance
-	poolConfig.MaxConns = 25                       // Maximum number of connections
-	poolConfig.MinConns = 5                        // Minimum number of connections
-	poolConfig.MaxConnLifetime = time.Hour * 1     // Maximum connection lifetime
-	poolConfig.MaxConnIdleTime = time.Minute * 30  // Maximum connection idle time
-	poolConfig.HealthCheckPeriod = time.Minute * 5 // Health check frequency
-
-	// Connection timeout settings
-	poolConfig.ConnConfig.ConnectTimeout = time.Second * 10
-	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = "30s"
-	poolConfig.ConnConfig.RuntimeParams["idle_in_transaction_session_timeout"] = "60s"
-
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
@@ -69,17 +61,92 @@ func NewConnection(cfg *config.DatabaseConfig, logger *slog.Logger) (*Connection
 	}
 
 	logger.Info("database connection pool created successfully",
-		"host", cfg.Host,
-		"port", cfg.Port,
-		"database", cfg.Database,
+		"host", poolConfig.ConnConfig.Host,
+		"port", poolConfig.ConnConfig.Port,
+		"database", poolConfig.ConnConfig.Database,
 		"max_conns", poolConfig.MaxConns,
 		"min_conns", poolConfig.MinConns,
 	)
 
-	return &Connection{
-		Pool:   pool,
-		logger: logger,
-	}, nil
+	conn := &Connection{
+		Pool:         pool,
+		logger:       logger,
+		logPoolStats: cfg.LogPoolStats,
+	}
+
+	if err := conn.connectReplicas(cfg); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	if total, healthy := conn.ReplicaCount(); total > 0 {
+		logger.Info("database read replicas configured", "replicas", total, "healthy", healthy)
+	}
+
+	return conn, nil
+}
+
+// newPoolConfig parses a connection string into the configuration of a
+// connection pool, the primary's or a replica's, and applies the configured
+// tunables. A tunable that is neither configured nor set by a parameter of
+// the connection string, such as pool_max_conns, gets its default.
+func newPoolConfig(connStr string, cfg *config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// pgxpool consumes the pool parameters, so the connection string's own
+	// parameters are read from its connection settings. Parsing them handles
+	// URLs and keyword/value strings alike.
+	connConfig, err := pgconn.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+	params := connConfig.RuntimeParams
+	hasParam := func(param string) bool {
+		_, ok := params[param]
+		return ok
+	}
+
+	// Connection pool settings
+	if maxConns := tunable(cfg.MaxConns, hasParam("pool_max_conns"), config.DefaultDatabaseMaxConns); maxConns > 0 {
+		poolConfig.MaxConns = int32(maxConns)
+	}
+	if minConns := tunable(cfg.MinConns, hasParam("pool_min_conns"), config.DefaultDatabaseMinConns); minConns > 0 {
+		poolConfig.MinConns = int32(minConns)
+	}
+	if lifetime := tunable(cfg.MaxConnLifetime, hasParam("pool_max_conn_lifetime"), config.DefaultDatabaseMaxConnLifetime); lifetime > 0 {
+		poolConfig.MaxConnLifetime = lifetime
+	}
+	if idleTime := tunable(cfg.MaxConnIdleTime, hasParam("pool_max_conn_idle_time"), config.DefaultDatabaseMaxConnIdleTime); idleTime > 0 {
+		poolConfig.MaxConnIdleTime = idleTime
+	}
+	if period := tunable(cfg.HealthCheckPeriod, hasParam("pool_health_check_period"), config.DefaultDatabaseHealthCheckPeriod); period > 0 {
+		poolConfig.HealthCheckPeriod = period
+	}
+
+	// Connection and statement timeout settings, in milliseconds for the server
+	if timeout := tunable(cfg.ConnectTimeout, connConfig.ConnectTimeout > 0, config.DefaultDatabaseConnectTimeout); timeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = timeout
+	}
+	if timeout := tunable(cfg.StatementTimeout, hasParam("statement_timeout"), config.DefaultDatabaseStatementTimeout); timeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+	if timeout := tunable(cfg.IdleInTransactionTimeout, hasParam("idle_in_transaction_session_timeout"), config.DefaultDatabaseIdleInTransactionTimeout); timeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["idle_in_transaction_session_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+// tunable returns the configured value of a tunable. An unset tunable is left
+// to the connection string when it sets the tunable, and otherwise gets the
+// default.
+func tunable[T int | time.Duration](configured T, setByConnStr bool, defaultValue T) T {
+	if configured > 0 || setByConnStr {
+		return configured
+	}
+	return defaultValue
 }
 
 // NewConnectionWithRetry creates a new database connection pool with retry logic
@@ -95,9 +162,6 @@ func NewConnectionWithRetry(cfg *config.DatabaseConfig, logger *slog.Logger) (*C
 		logger.Info("attempting database connection",
 			"attempt", attempt,
 			"max_retries", maxRetries,
-			"host", cfg.Host,
-			"port", cfg.Port,
-			"database", cfg.Database,
 		)
 
 		conn, err := NewConnection(cfg, logger)
@@ -122,8 +186,9 @@ func NewConnectionWithRetry(cfg *config.DatabaseConfig, logger *slog.Logger) (*C
 	return nil, fmt.Errorf("failed to establish database connection after %d attempts", maxRetries)
 }
 
-// Close closes the database connection pool
+// Close closes the database connection pool and the replicas' pools
 func (c *Connection) Close() {
+	c.closeReplicas()
 	if c.Pool != nil {
 		c.logger.Info("closing database connection pool")
 		c.Pool.Close()
@@ -140,24 +205,72 @@ func (c *Connection) Stats() *pgxpool.Stat {
 	return c.Pool.Stat()
 }
 
-// LogStats logs connection pool statistics
-func (c *Connection) LogStats() {
+// PoolStats is a snapshot of the primary's connection pool statistics.
+// WaitCount and WaitDuration count the acquires that had to wait for a
+// connection because none was idle, the sign of a starved pool.
+type PoolStats struct {
+	MaxConns             int32 `json:"max_conns"`
+	TotalConns           int32 `json:"total_conns"`
+	AcquiredConns        int32 `json:"acquired_conns"`
+	IdleConns            int32 `json:"idle_conns"`
+	AcquireCount         int64 `json:"acquire_count"`
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+	WaitCount            int64 `json:"wait_count"`
+	WaitDurationMs       int64 `json:"wait_duration_ms"`
+}
+
+// PoolStats returns a snapshot of the connection pool statistics
+func (c *Connection) PoolStats() PoolStats {
 	stats := c.Stats()
-	c.logger.Info("database connection pool stats",
-		"total_conns", stats.TotalConns(),
-		"idle_conns", stats.IdleConns(),
-		"acquired_conns", stats.AcquiredConns(),
-		"constructing_conns", stats.ConstructingConns(),
-		"acquire_count", stats.AcquireCount(),
-		"acquire_duration", stats.AcquireDuration(),
-		"acquired_conns_duration", stats.AcquiredConns(),
-		"canceled_acquire_count", stats.CanceledAcquireCount(),
-		"empty_acquire_count", stats.EmptyAcquireCount(),
-		"max_conns", stats.MaxConns(),
-		"new_conns_count", stats.NewConnsCount(),
+	return PoolStats{
+		MaxConns:             stats.MaxConns(),
+		TotalConns:           stats.TotalConns(),
+		AcquiredConns:        stats.AcquiredConns(),
+		IdleConns:            stats.IdleConns(),
+		AcquireCount:         stats.AcquireCount(),
+		CanceledAcquireCount: stats.CanceledAcquireCount(),
+		WaitCount:            stats.EmptyAcquireCount(),
+		WaitDurationMs:       stats.EmptyAcquireWaitTime().Milliseconds(),
+	}
+}
+
+// LogStats logs connection pool statistics, at debug level unless
+// configured otherwise; they are also reported by the readiness check
+func (c *Connection) LogStats() {
+	level := slog.LevelDebug
+	if c.logPoolStats {
+		level = slog.LevelInfo
+	}
+
+	stats := c.PoolStats()
+	c.logger.Log(context.Background(), level, "database connection pool stats",
+		"max_conns", stats.MaxConns,
+		"total_conns", stats.TotalConns,
+		"acquired_conns", stats.AcquiredConns,
+		"idle_conns", stats.IdleConns,
+		"acquire_count", stats.AcquireCount,
+		"canceled_acquire_count", stats.CanceledAcquireCount,
+		"wait_count", stats.WaitCount,
+		"wait_duration_ms", stats.WaitDurationMs,
 	)
 }
 
+// PoolStatsVar is the expvar name the connection pool statistics are
+// published under
+const PoolStatsVar = "database_pool"
+
+// PublishStats publishes the connection pool statistics through expvar, read
+// whenever the variables are served. Only the first connection of a process
+// is published.
+func (c *Connection) PublishStats() {
+	if expvar.Get(PoolStatsVar) != nil {
+		return
+	}
+	expvar.Publish(PoolStatsVar, expvar.Func(func() any {
+		return c.PoolStats()
+	}))
+}
+
 // HealthCheck performs a comprehensive health check of the database connection
 func (c *Connection) HealthCheck(ctx context.Context) error {
 	// Check if pool is available
@@ -202,6 +315,13 @@ type TransactionalRepositories struct {
 	Tasks          TaskRepository
 	TaskExecutions TaskExecutionRepository
 	Users          UserRepository
+	NetworkEvents  NetworkEventRepository
+	TaskRevisions  TaskRevisionRepository
+	TaskTemplates  TaskTemplateRepository
+	BulkJobs       BulkJobRepository
+
+	ExecutionPartitions ExecutionPartitionRepository
+	OutputVolumes       OutputVolumeRepository
 }
 
 // transaction implements the Transaction interface
@@ -216,6 +336,13 @@ func (t *transaction) Repositories() TransactionalRepositories {
 		Tasks:          NewTaskRepositoryWithTx(t.Tx),
 		TaskExecutions: NewTaskExecutionRepositoryWithTx(t.Tx),
 		Users:          NewUserRepositoryWithTx(t.Tx),
+		NetworkEvents:  NewNetworkEventRepositoryWithTx(t.Tx),
+		TaskRevisions:  NewTaskRevisionRepositoryWithTx(t.Tx),
+		TaskTemplates:  NewTaskTemplateRepositoryWithTx(t.Tx),
+		BulkJobs:       NewBulkJobRepositoryWithTx(t.Tx),
+
+		ExecutionPartitions: NewExecutionPartitionRepositoryWithTx(t.Tx),
+		OutputVolumes:       NewOutputVolumeRepositoryWithTx(t.Tx),
 	}
 }
 
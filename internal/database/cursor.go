@@ -4,9 +4,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 // CursorEncoder handles encoding and decoding of cursors
@@ -92,6 +94,21 @@ func CreateTaskCursor(id uuid.UUID, createdAt time.Time, priority *int) TaskCurs
 	}
 }
 
+// CreateTaskCursorFromTask creates a task cursor holding the values of every
+// sort field, so that the next page continues in any sort order
+func CreateTaskCursorFromTask(task *models.Task) TaskCursor {
+	priority := task.Priority
+	updatedAt := task.UpdatedAt
+	name := task.Name
+	return TaskCursor{
+		ID:        task.ID,
+		CreatedAt: task.CreatedAt,
+		Priority:  &priority,
+		UpdatedAt: &updatedAt,
+		Name:      &name,
+	}
+}
+
 // CreateExecutionCursor creates an execution cursor from an execution
 func CreateExecutionCursor(id uuid.UUID, createdAt time.Time) ExecutionCursor {
 	return ExecutionCursor{
@@ -141,7 +158,8 @@ func ValidatePaginationRequest(req *CursorPaginationRequest) {
 
 // BuildTaskCursorWhere builds WHERE clause for cursor-based pagination
 func BuildTaskCursorWhere(cursor *TaskCursor, sortOrder string, sortField string, userID *uuid.UUID, status *string) (string, []interface{}) {
-	var conditions []string
+	// Tasks in the trash are never listed
+	conditions := []string{"deleted_at IS NULL"}
 	var args []interface{}
 	argIndex := 1
 
@@ -169,10 +187,6 @@ func BuildTaskCursorWhere(cursor *TaskCursor, sortOrder string, sortField string
 		}
 	}
 
-	if len(conditions) == 0 {
-		return "", args
-	}
-
 	whereClause := "WHERE " + conditions[0]
 	for i := 1; i < len(conditions); i++ {
 		whereClause += " AND " + conditions[i]
@@ -180,65 +194,57 @@ func BuildTaskCursorWhere(cursor *TaskCursor, sortOrder string, sortField string
 	return whereClause, args
 }
 
-// buildCursorCondition builds the cursor comparison condition based on sort field
+// buildCursorCondition builds the cursor comparison condition based on sort field.
+// Every placeholder takes its own argument, numbered from startArgIndex.
 func buildCursorCondition(cursor *TaskCursor, sortOrder string, sortField string, startArgIndex int) (string, []interface{}) {
-	var args []interface{}
-	var condition string
-
-	// Determine comparison operators based on sort order
-	primaryOp, secondaryOp := "<", "<"
+	// Determine comparison operator based on sort order
+	op := "<"
 	if sortOrder == "asc" {
-		primaryOp, secondaryOp = ">", ">"
+		op = ">"
 	}
 
-	argIndex := startArgIndex
+	// keyset compares the cursor values column by column: the first column
+	// past the cursor, or equal to it and the next column past it, and so on
+	keyset := func(columns []string, values []interface{}) (string, []interface{}) {
+		var args []interface{}
+		var alternatives []string
+		for i := range columns {
+			var parts []string
+			for j := 0; j < i; j++ {
+				args = append(args, values[j])
+				parts = append(parts, fmt.Sprintf("%s = $%d", columns[j], startArgIndex+len(args)-1))
+			}
+			args = append(args, values[i])
+			parts = append(parts, fmt.Sprintf("%s %s $%d", columns[i], op, startArgIndex+len(args)-1))
+			alternatives = append(alternatives, strings.Join(parts, " AND "))
+		}
+		return "(" + strings.Join(alternatives, " OR ") + ")", args
+	}
 
 	switch sortField {
 	case "priority":
-		if cursor.Priority == nil {
-			// Cannot use priority cursor without priority value, fallback to created_at
-			condition = fmt.Sprintf("(created_at %s $%d OR (created_at = $%d AND id %s $%d))",
-				primaryOp, argIndex, argIndex, secondaryOp, argIndex+1)
-			args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
-		} else {
+		if cursor.Priority != nil {
 			// Priority-based cursor: priority, then created_at, then id
-			condition = fmt.Sprintf(`(priority %s $%d OR 
-				(priority = $%d AND created_at %s $%d) OR 
-				(priority = $%d AND created_at = $%d AND id %s $%d))`,
-				primaryOp, argIndex, // priority comparison
-				argIndex, primaryOp, argIndex+1, // priority = and created_at comparison
-				argIndex, argIndex+1, secondaryOp, argIndex+2) // priority = and created_at = and id comparison
-			args = append(args, *cursor.Priority, *cursor.Priority, cursor.CreatedAt,
-				*cursor.Priority, cursor.CreatedAt, cursor.ID)
+			return keyset([]string{"priority", "created_at", "id"}, []interface{}{*cursor.Priority, cursor.CreatedAt, cursor.ID})
 		}
-
-	case "created_at":
-		// Created_at-based cursor: created_at, then id
-		condition = fmt.Sprintf("(created_at %s $%d OR (created_at = $%d AND id %s $%d))",
-			primaryOp, argIndex, argIndex, secondaryOp, argIndex+1)
-		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
-
 	case "updated_at":
-		// Updated_at-based cursor: updated_at, then id (using created_at as proxy for updated_at in cursor)
-		condition = fmt.Sprintf("(updated_at %s $%d OR (updated_at = $%d AND id %s $%d))",
-			primaryOp, argIndex, argIndex, secondaryOp, argIndex+1)
-		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
-
+		// Updated_at-based cursor: updated_at, then id. Cursors without
+		// updated_at use created_at as a proxy.
+		updatedAt := cursor.CreatedAt
+		if cursor.UpdatedAt != nil {
+			updatedAt = *cursor.UpdatedAt
+		}
+		return keyset([]string{"updated_at", "id"}, []interface{}{updatedAt, cursor.ID})
 	case "name":
-		// Note: For name sorting, we'd need to store the name in the cursor too
-		// For now, fallback to created_at-based sorting
-		condition = fmt.Sprintf("(created_at %s $%d OR (created_at = $%d AND id %s $%d))",
-			primaryOp, argIndex, argIndex, secondaryOp, argIndex+1)
-		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
-
-	default:
-		// Default to created_at-based cursor
-		condition = fmt.Sprintf("(created_at %s $%d OR (created_at = $%d AND id %s $%d))",
-			primaryOp, argIndex, argIndex, secondaryOp, argIndex+1)
-		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+		if cursor.Name != nil {
+			// Name-based cursor: name, then created_at, then id
+			return keyset([]string{"name", "created_at", "id"}, []interface{}{*cursor.Name, cursor.CreatedAt, cursor.ID})
+		}
 	}
 
-	return condition, args
+	// Created_at-based cursor: created_at, then id. Also the fallback for
+	// cursors missing the value of the sort field.
+	return keyset([]string{"created_at", "id"}, []interface{}{cursor.CreatedAt, cursor.ID})
 }
 
 // BuildExecutionCursorWhere builds WHERE clause for execution cursor-based pagination
@@ -264,9 +270,9 @@ func BuildExecutionCursorWhere(cursor *ExecutionCursor, sortOrder string, taskID
 	// Add cursor condition if provided
 	if cursor != nil {
 		if sortOrder == "asc" {
-			conditions = append(conditions, fmt.Sprintf("(created_at > $%d OR (created_at = $%d AND id > $%d))", argIndex, argIndex, argIndex+1))
+			conditions = append(conditions, fmt.Sprintf("(created_at > $%d OR (created_at = $%d AND id > $%d))", argIndex, argIndex+1, argIndex+2))
 		} else {
-			conditions = append(conditions, fmt.Sprintf("(created_at < $%d OR (created_at = $%d AND id < $%d))", argIndex, argIndex, argIndex+1))
+			conditions = append(conditions, fmt.Sprintf("(created_at < $%d OR (created_at = $%d AND id < $%d))", argIndex, argIndex+1, argIndex+2))
 		}
 		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
 		// argIndex is not used after this point, so no need to update it
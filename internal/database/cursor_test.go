@@ -162,4 +162,25 @@ func TestBuildTaskCursorWhere(t *testing.T) {
 		}
 		assert.True(t, found, "Priority value should be included in query arguments")
 	})
+
+	t.Run("Placeholders Match Arguments", func(t *testing.T) {
+		name := "report"
+		updatedAt := time.Now()
+		fullCursor := &TaskCursor{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			Priority:  intPtr(5),
+			UpdatedAt: &updatedAt,
+			Name:      &name,
+		}
+
+		for _, sortField := range []string{"created_at", "updated_at", "priority", "name"} {
+			whereClause, args := BuildTaskCursorWhere(fullCursor, "desc", sortField, &userID, &status)
+			assert.Len(t, placeholders(whereClause), len(args), sortField)
+		}
+
+		whereClause, args := BuildTaskCursorWhere(fullCursor, "desc", "updated_at", nil, nil)
+		assert.Contains(t, whereClause, "updated_at <")
+		assert.Equal(t, updatedAt, args[0], "updated_at cursors continue from the task's update time")
+	})
 }
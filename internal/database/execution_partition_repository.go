@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// executionPartitionPrefix prefixes the names of the monthly partitions of
+// task executions, followed by their year and month
+const executionPartitionPrefix = "task_executions_p"
+
+// executionPartitionLock names the advisory lock serializing the maintenance
+// of the partitions across processes
+const executionPartitionLock = "task_executions_partitions"
+
+// txBeginner begins transactions, either on the pool or, as a savepoint,
+// within a transaction
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// executionPartitionRepository implements ExecutionPartitionRepository interface
+type executionPartitionRepository struct {
+	querier Querier
+}
+
+// NewExecutionPartitionRepository creates a new execution partition repository
+func NewExecutionPartitionRepository(conn *Connection) ExecutionPartitionRepository {
+	return &executionPartitionRepository{
+		querier: conn.Pool,
+	}
+}
+
+// NewExecutionPartitionRepositoryWithTx creates a new execution partition repository with transaction
+func NewExecutionPartitionRepositoryWithTx(tx pgx.Tx) ExecutionPartitionRepository {
+	return &executionPartitionRepository{
+		querier: tx,
+	}
+}
+
+// Create creates the partition of the month of the given time, in UTC, and
+// reports whether it didn't exist yet. Executions of the month already in the
+// default partition are moved into it in the same transaction.
+func (r *executionPartitionRepository) Create(ctx context.Context, month time.Time) (bool, error) {
+	month = month.UTC()
+	day := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var created bool
+	if err := r.querier.QueryRow(ctx, `SELECT create_task_executions_partition($1::date)`, day).Scan(&created); err != nil {
+		return false, fmt.Errorf("failed to create task executions partition: %w", err)
+	}
+
+	return created, nil
+}
+
+// List lists the monthly partitions, oldest first. The default partition is
+// left out.
+func (r *executionPartitionRepository) List(ctx context.Context) ([]ExecutionPartition, error) {
+	query := `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'task_executions'::regclass
+		ORDER BY c.relname
+	`
+
+	rows, err := r.querier.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task executions partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := []ExecutionPartition{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan task executions partition: %w", err)
+		}
+		if partition, ok := parseExecutionPartition(name); ok {
+			partitions = append(partitions, partition)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task executions partition rows: %w", err)
+	}
+
+	return partitions, nil
+}
+
+// Count counts the executions of a partition, with the size of their output
+func (r *executionPartitionRepository) Count(ctx context.Context, partition ExecutionPartition) (RetentionCount, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM task_executions WHERE created_at >= $1 AND created_at < $2),
+			(SELECT COALESCE(SUM(octet_length(data)), 0) FROM execution_outputs WHERE execution_created_at >= $1 AND execution_created_at < $2)
+	`
+
+	var count RetentionCount
+	err := r.querier.QueryRow(ctx, query, partition.From, partition.To).Scan(&count.Executions, &count.OutputBytes)
+	if err != nil {
+		return RetentionCount{}, fmt.Errorf("failed to count task executions of partition %s: %w", partition.Name, err)
+	}
+
+	return count, nil
+}
+
+// GetExecutions retrieves the executions of a partition in creation order,
+// starting after the given creation time and ID
+func (r *executionPartitionRepository) GetExecutions(ctx context.Context, partition ExecutionPartition, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*models.TaskExecution, error) {
+	query := `
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
+		FROM task_executions
+		WHERE created_at >= $1 AND created_at < $2 AND (created_at, id) > ($3, $4)
+		ORDER BY created_at, id
+		LIMIT $5
+	`
+
+	rows, err := r.querier.Query(ctx, query, partition.From, partition.To, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task executions of partition %s: %w", partition.Name, err)
+	}
+	defer rows.Close()
+
+	return (&taskExecutionRepository{querier: r.querier}).scanTaskExecutions(rows)
+}
+
+// Drop detaches and drops a partition with its executions, and the output
+// and network events referencing them, in a single transaction. The IDs of
+// the dropped executions with truncated output are returned so their spilled
+// output can be removed.
+func (r *executionPartitionRepository) Drop(ctx context.Context, partition ExecutionPartition) ([]uuid.UUID, error) {
+	if _, ok := parseExecutionPartition(partition.Name); !ok {
+		return nil, fmt.Errorf("invalid task executions partition: %q", partition.Name)
+	}
+
+	rows, err := r.querier.Query(ctx, `SELECT drop_task_executions_partition($1::date)`, partition.From.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to drop task executions partition %s: %w", partition.Name, err)
+	}
+	defer rows.Close()
+
+	var executionIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan dropped task execution: %w", err)
+		}
+		executionIDs = append(executionIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to drop task executions partition %s: %w", partition.Name, err)
+	}
+
+	return executionIDs, nil
+}
+
+// WithLock calls fn with a repository whose calls are made in a transaction
+// holding the partition maintenance lock, so that creating, filling and
+// dropping partitions never race between processes. The transaction is
+// committed when fn returns nil and rolled back otherwise.
+func (r *executionPartitionRepository) WithLock(ctx context.Context, fn func(partitions ExecutionPartitionRepository) error) error {
+	beginner, ok := r.querier.(txBeginner)
+	if !ok {
+		return fmt.Errorf("task executions partitions can't be locked without transactions")
+	}
+
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, executionPartitionLock); err != nil {
+		return fmt.Errorf("failed to lock task executions partitions: %w", err)
+	}
+
+	if err := fn(&executionPartitionRepository{querier: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// parseExecutionPartition parses the name of a monthly partition into its
+// bounds
+func parseExecutionPartition(name string) (ExecutionPartition, bool) {
+	month, ok := strings.CutPrefix(name, executionPartitionPrefix)
+	if !ok || len(month) != 6 {
+		return ExecutionPartition{}, false
+	}
+
+	from, err := time.Parse("200601", month)
+	if err != nil {
+		return ExecutionPartition{}, false
+	}
+
+	return ExecutionPartition{Name: name, From: from, To: from.AddDate(0, 1, 0)}, true
+}
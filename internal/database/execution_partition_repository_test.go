@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExecutionPartition(t *testing.T) {
+	partition, ok := parseExecutionPartition("task_executions_p202612")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), partition.From)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), partition.To)
+
+	for _, name := range []string{"task_executions_default", "task_executions_p2026", "task_executions_p202613", "tasks"} {
+		_, ok := parseExecutionPartition(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestExecutionPartitionRepository_Drop(t *testing.T) {
+	partition, _ := parseExecutionPartition("task_executions_p202601")
+
+	t.Run("drops the partition and returns its truncated executions", func(t *testing.T) {
+		executionID := uuid.New()
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Query", mock.Anything, "SELECT drop_task_executions_partition($1::date)", []interface{}{partition.From}).
+			Return(&MockRows{rows: [][]interface{}{{executionID}}}, nil)
+
+		repo := &executionPartitionRepository{querier: mockQuerier}
+		executionIDs, err := repo.Drop(context.Background(), partition)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{executionID}, executionIDs)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("rejects other tables", func(t *testing.T) {
+		repo := &executionPartitionRepository{querier: new(MockQuerier)}
+		_, err := repo.Drop(context.Background(), ExecutionPartition{Name: "task_executions_default"})
+		assert.Error(t, err)
+		_, err = repo.Drop(context.Background(), ExecutionPartition{Name: "tasks"})
+		assert.Error(t, err)
+	})
+}
+
+func TestExecutionPartitionRepository_WithLock(t *testing.T) {
+	t.Run("needs a querier that begins transactions", func(t *testing.T) {
+		repo := &executionPartitionRepository{querier: new(MockQuerier)}
+		called := false
+		err := repo.WithLock(context.Background(), func(partitions ExecutionPartitionRepository) error {
+			called = true
+			return nil
+		})
+		assert.Error(t, err)
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/labels"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// TaskFilter narrows a task listing. Fields left empty don't filter.
+type TaskFilter struct {
+	UserID        *uuid.UUID
+	Statuses      []models.TaskStatus
+	ScriptTypes   []models.ScriptType
+	MinPriority   *int
+	MaxPriority   *int
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	// Metadata matches tasks whose metadata contains this object
+	Metadata models.JSONB
+
+	// Query matches task names by full-text search, each word also matching
+	// as a prefix
+	Query string
+
+	// Selector matches tasks by their labels
+	Selector labels.Selector
+
+	// Deleted lists the tasks in the trash instead of the others
+	Deleted bool
+}
+
+// ExecutionFilter narrows an execution listing. Fields left empty don't filter.
+type ExecutionFilter struct {
+	TaskID *uuid.UUID
+
+	// UserID matches the executions of all tasks owned by the user, except
+	// the tasks in the trash
+	UserID        *uuid.UUID
+	Statuses      []models.ExecutionStatus
+	ReturnCode    *int
+	MinDurationMs *int
+	MaxDurationMs *int
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	// Selector matches the executions of tasks selected by their labels
+	Selector labels.Selector
+}
+
+// whereBuilder collects the conditions of a WHERE clause and their numbered
+// arguments
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends a condition whose %d verbs are replaced by the placeholders of
+// the given arguments, in order
+func (b *whereBuilder) add(condition string, args ...interface{}) {
+	placeholders := make([]interface{}, len(args))
+	for i, arg := range args {
+		b.args = append(b.args, arg)
+		placeholders[i] = len(b.args)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+// addSelector adds a condition per selector requirement, matching the labels
+// of the task identified by the taskID column
+func (b *whereBuilder) addSelector(selector labels.Selector, taskID string) {
+	label := "SELECT 1 FROM task_labels l WHERE l.task_id = " + taskID + " AND l.key = $%d"
+	for _, r := range selector {
+		switch r.Operator {
+		case labels.Exists:
+			b.add("EXISTS ("+label+")", r.Key)
+		case labels.DoesNotExist:
+			b.add("NOT EXISTS ("+label+")", r.Key)
+		case labels.Equals, labels.In:
+			b.add("EXISTS ("+label+" AND l.value = ANY($%d))", r.Key, r.Values)
+		case labels.NotEquals, labels.NotIn:
+			b.add("NOT EXISTS ("+label+" AND l.value = ANY($%d))", r.Key, r.Values)
+		}
+	}
+}
+
+func (b *whereBuilder) clause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// BuildTaskFilterWhere builds the WHERE clause for a filtered task listing,
+// continuing after the cursor when one is given
+func BuildTaskFilterWhere(filter TaskFilter, cursor *TaskCursor, sortOrder string, sortField string) (string, []interface{}, error) {
+	var b whereBuilder
+
+	if filter.Deleted {
+		b.add("deleted_at IS NOT NULL")
+	} else {
+		b.add("deleted_at IS NULL")
+	}
+	if filter.UserID != nil {
+		b.add("user_id = $%d", *filter.UserID)
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		b.add("status = ANY($%d)", statuses)
+	}
+	if len(filter.ScriptTypes) > 0 {
+		scriptTypes := make([]string, len(filter.ScriptTypes))
+		for i, scriptType := range filter.ScriptTypes {
+			scriptTypes[i] = string(scriptType)
+		}
+		b.add("script_type = ANY($%d)", scriptTypes)
+	}
+	if filter.MinPriority != nil {
+		b.add("priority >= $%d", *filter.MinPriority)
+	}
+	if filter.MaxPriority != nil {
+		b.add("priority <= $%d", *filter.MaxPriority)
+	}
+	if filter.CreatedAfter != nil {
+		b.add("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		b.add("created_at < $%d", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		b.add("updated_at >= $%d", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		b.add("updated_at < $%d", *filter.UpdatedBefore)
+	}
+	if len(filter.Metadata) > 0 {
+		metadata, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to encode metadata filter: %w", err)
+		}
+		b.add("metadata @> $%d::jsonb", string(metadata))
+	}
+	if query := NameSearchQuery(filter.Query); query != "" {
+		b.add("to_tsvector('simple', name) @@ to_tsquery('simple', $%d)", query)
+	}
+	b.addSelector(filter.Selector, "tasks.id")
+
+	if cursor != nil {
+		condition, args := buildCursorCondition(cursor, sortOrder, sortField, len(b.args)+1)
+		b.conditions = append(b.conditions, condition)
+		b.args = append(b.args, args...)
+	}
+
+	return b.clause(), b.args, nil
+}
+
+// BuildExecutionFilterWhere builds the WHERE clause for a filtered execution
+// listing, continuing after the cursor when one is given
+func BuildExecutionFilterWhere(filter ExecutionFilter, cursor *ExecutionCursor, sortOrder string) (string, []interface{}) {
+	var b whereBuilder
+
+	if filter.TaskID != nil {
+		b.add("task_id = $%d", *filter.TaskID)
+	}
+	if filter.UserID != nil {
+		b.add("task_id IN (SELECT id FROM tasks WHERE user_id = $%d AND deleted_at IS NULL)", *filter.UserID)
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		b.add("status = ANY($%d)", statuses)
+	}
+	if filter.ReturnCode != nil {
+		b.add("return_code = $%d", *filter.ReturnCode)
+	}
+	if filter.MinDurationMs != nil {
+		b.add("execution_time_ms >= $%d", *filter.MinDurationMs)
+	}
+	if filter.MaxDurationMs != nil {
+		b.add("execution_time_ms <= $%d", *filter.MaxDurationMs)
+	}
+	if filter.CreatedAfter != nil {
+		b.add("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		b.add("created_at < $%d", *filter.CreatedBefore)
+	}
+	b.addSelector(filter.Selector, "task_executions.task_id")
+
+	if cursor != nil {
+		condition, args := buildCursorCondition(&TaskCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}, sortOrder, "created_at", len(b.args)+1)
+		b.conditions = append(b.conditions, condition)
+		b.args = append(b.args, args...)
+	}
+
+	return b.clause(), b.args
+}
+
+// NameSearchQuery turns a free-text search into a Postgres tsquery matching
+// names that contain every word, or a word starting with it. Characters
+// other than letters and digits separate words; an empty string is returned
+// when there are none.
+func NameSearchQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
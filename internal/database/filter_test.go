@@ -0,0 +1,153 @@
+package database
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/labels"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// placeholders returns the numbers of the placeholders in a query, in order
+func placeholders(query string) []string {
+	var numbers []string
+	for _, match := range regexp.MustCompile(`\$(\d+)`).FindAllStringSubmatch(query, -1) {
+		numbers = append(numbers, match[1])
+	}
+	return numbers
+}
+
+func TestBuildTaskFilterWhere(t *testing.T) {
+	userID := uuid.New()
+	minPriority, maxPriority := 3, 8
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Empty Filter", func(t *testing.T) {
+		whereClause, args, err := BuildTaskFilterWhere(TaskFilter{}, nil, "desc", "created_at")
+		require.NoError(t, err)
+		assert.Equal(t, "WHERE deleted_at IS NULL", whereClause)
+		assert.Empty(t, args)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		whereClause, args, err := BuildTaskFilterWhere(TaskFilter{UserID: &userID, Deleted: true}, nil, "desc", "created_at")
+		require.NoError(t, err)
+		assert.Equal(t, "WHERE deleted_at IS NOT NULL AND user_id = $1", whereClause)
+		assert.Equal(t, []interface{}{userID}, args)
+	})
+
+	t.Run("All Filters", func(t *testing.T) {
+		filter := TaskFilter{
+			UserID:       &userID,
+			Statuses:     []models.TaskStatus{models.TaskStatusPending, models.TaskStatusFailed},
+			ScriptTypes:  []models.ScriptType{models.ScriptTypePython},
+			MinPriority:  &minPriority,
+			MaxPriority:  &maxPriority,
+			CreatedAfter: &after,
+			UpdatedAfter: &after,
+			Metadata:     models.JSONB{"team": "data"},
+			Query:        "nightly rep",
+		}
+
+		whereClause, args, err := BuildTaskFilterWhere(filter, nil, "desc", "created_at")
+		require.NoError(t, err)
+
+		assert.Contains(t, whereClause, "user_id = $1")
+		assert.Contains(t, whereClause, "status = ANY($2)")
+		assert.Contains(t, whereClause, "script_type = ANY($3)")
+		assert.Contains(t, whereClause, "priority >= $4")
+		assert.Contains(t, whereClause, "priority <= $5")
+		assert.Contains(t, whereClause, "created_at >= $6")
+		assert.Contains(t, whereClause, "updated_at >= $7")
+		assert.Contains(t, whereClause, "metadata @> $8::jsonb")
+		assert.Contains(t, whereClause, "to_tsvector('simple', name) @@ to_tsquery('simple', $9)")
+		require.Len(t, args, 9)
+		assert.Equal(t, []string{"pending", "failed"}, args[1])
+		assert.Equal(t, `{"team":"data"}`, args[7])
+		assert.Equal(t, "nightly:* & rep:*", args[8])
+	})
+
+	t.Run("Label Selector", func(t *testing.T) {
+		selector, err := labels.Parse("env=prod,team notin (web),tier,!deprecated")
+		require.NoError(t, err)
+
+		whereClause, args, err := BuildTaskFilterWhere(TaskFilter{UserID: &userID, Selector: selector}, nil, "desc", "created_at")
+		require.NoError(t, err)
+
+		assert.Contains(t, whereClause, "AND EXISTS (SELECT 1 FROM task_labels l WHERE l.task_id = tasks.id AND l.key = $2 AND l.value = ANY($3))")
+		assert.Contains(t, whereClause, "AND NOT EXISTS (SELECT 1 FROM task_labels l WHERE l.task_id = tasks.id AND l.key = $4 AND l.value = ANY($5))")
+		assert.Contains(t, whereClause, "AND EXISTS (SELECT 1 FROM task_labels l WHERE l.task_id = tasks.id AND l.key = $6)")
+		assert.Contains(t, whereClause, "AND NOT EXISTS (SELECT 1 FROM task_labels l WHERE l.task_id = tasks.id AND l.key = $7)")
+		assert.Equal(t, []interface{}{userID, "env", []string{"prod"}, "team", []string{"web"}, "tier", "deprecated"}, args)
+	})
+
+	t.Run("With Cursor", func(t *testing.T) {
+		name := "Nightly report"
+		cursor := &TaskCursor{ID: uuid.New(), CreatedAt: time.Now(), Name: &name}
+
+		whereClause, args, err := BuildTaskFilterWhere(TaskFilter{UserID: &userID, MinPriority: &minPriority}, cursor, "asc", "name")
+		require.NoError(t, err)
+
+		assert.Contains(t, whereClause, "name > $3")
+		assert.Len(t, args, 8) // userID, priority, name (3x), created_at (2x), id
+		assert.Len(t, placeholders(whereClause), len(args), "every argument has its own placeholder")
+		assert.Equal(t, name, args[2])
+	})
+}
+
+func TestBuildExecutionFilterWhere(t *testing.T) {
+	userID := uuid.New()
+	returnCode, minDuration := 1, 5000
+
+	filter := ExecutionFilter{
+		UserID:        &userID,
+		Statuses:      []models.ExecutionStatus{models.ExecutionStatusFailed},
+		ReturnCode:    &returnCode,
+		MinDurationMs: &minDuration,
+	}
+	cursor := &ExecutionCursor{ID: uuid.New(), CreatedAt: time.Now()}
+
+	whereClause, args := BuildExecutionFilterWhere(filter, cursor, "desc")
+
+	assert.Contains(t, whereClause, "task_id IN (SELECT id FROM tasks WHERE user_id = $1 AND deleted_at IS NULL)")
+	assert.Contains(t, whereClause, "status = ANY($2)")
+	assert.Contains(t, whereClause, "return_code = $3")
+	assert.Contains(t, whereClause, "execution_time_ms >= $4")
+	assert.Contains(t, whereClause, "created_at < $5")
+	assert.Len(t, args, 7)
+	assert.Len(t, placeholders(whereClause), len(args))
+
+	t.Run("Label Selector", func(t *testing.T) {
+		selector, err := labels.Parse("team in (data,ml)")
+		require.NoError(t, err)
+
+		whereClause, args := BuildExecutionFilterWhere(ExecutionFilter{UserID: &userID, Selector: selector}, cursor, "desc")
+
+		assert.Contains(t, whereClause, "EXISTS (SELECT 1 FROM task_labels l WHERE l.task_id = task_executions.task_id AND l.key = $2 AND l.value = ANY($3))")
+		assert.Equal(t, []string{"data", "ml"}, args[2])
+		assert.Len(t, placeholders(whereClause), len(args))
+	})
+}
+
+func TestNameSearchQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{search: "Nightly", want: "nightly:*"},
+		{search: "  nightly   report ", want: "nightly:* & report:*"},
+		{search: "etl-v2 (daily)", want: "etl:* & v2:* & daily:*"},
+		{search: "nightly' | !", want: "nightly:*"},
+		{search: "!&|", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			assert.Equal(t, tt.want, NameSearchQuery(tt.search))
+		})
+	}
+}
@@ -42,6 +42,9 @@ func setupTestDatabase(t *testing.T) (*Connection, *Repositories) {
 		Password: getEnvOrDefault("TEST_DB_PASSWORD", "testpassword"),
 		Database: getEnvOrDefault("TEST_DB_NAME", "voidrunner_test"),
 		SSLMode:  getEnvOrDefault("TEST_DB_SSL_MODE", "disable"),
+
+		MaxConns:         config.DefaultDatabaseMaxConns,
+		StatementTimeout: config.DefaultDatabaseStatementTimeout,
 	}
 
 	// Create database connection - skip test if database is not available
@@ -218,6 +221,17 @@ func TestTaskRepository_Integration(t *testing.T) {
 		updatedTask, err := repos.Tasks.GetByID(ctx, task.ID)
 		require.NoError(t, err)
 		assert.Equal(t, models.TaskStatusRunning, updatedTask.Status)
+		assert.Equal(t, task.Version+1, updatedTask.Version)
+
+		// Test Update only applies to the version the task was read at
+		updatedTask.Name = "Renamed Integration Test Task"
+		err = repos.Tasks.Update(ctx, updatedTask)
+		require.NoError(t, err)
+		assert.Equal(t, task.Version+2, updatedTask.Version)
+
+		task.Name = "Stale Integration Test Task"
+		err = repos.Tasks.Update(ctx, task)
+		assert.ErrorIs(t, err, ErrTaskVersionConflict)
 
 		// Test SearchByMetadata
 		metadataQuery := `{"environment": "test"}`
@@ -239,7 +253,7 @@ func TestTaskRepository_Integration(t *testing.T) {
 		assert.Greater(t, runningTaskCount, int64(0))
 
 		// Test Delete
-		err = repos.Tasks.Delete(ctx, task.ID)
+		err = repos.Tasks.Delete(ctx, task.ID, task.Version)
 		require.NoError(t, err)
 
 		// Verify deletion
@@ -277,7 +291,7 @@ func TestTaskExecutionRepository_Integration(t *testing.T) {
 
 		// Clean up at the end
 		defer func() {
-			if err := repos.Tasks.Delete(ctx, task.ID); err != nil {
+			if err := repos.Tasks.Delete(ctx, task.ID, task.Version); err != nil {
 				t.Logf("Failed to clean up task: %v", err)
 			}
 			if err := repos.Users.Delete(ctx, user.ID); err != nil {
@@ -287,8 +301,9 @@ func TestTaskExecutionRepository_Integration(t *testing.T) {
 
 		// Create a test execution
 		execution := &models.TaskExecution{
-			TaskID: task.ID,
-			Status: models.ExecutionStatusPending,
+			TaskID:   task.ID,
+			Status:   models.ExecutionStatusPending,
+			Revision: intPtr(1),
 		}
 
 		// Test Create
@@ -302,6 +317,8 @@ func TestTaskExecutionRepository_Integration(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, execution.TaskID, retrievedExecution.TaskID)
 		assert.Equal(t, execution.Status, retrievedExecution.Status)
+		require.NotNil(t, retrievedExecution.Revision)
+		assert.Equal(t, 1, *retrievedExecution.Revision)
 
 		// Test GetByTaskID
 		taskExecutions, err := repos.TaskExecutions.GetByTaskID(ctx, task.ID, 10, 0)
@@ -430,7 +447,7 @@ func BenchmarkDatabaseOperations(b *testing.B) {
 				b.Errorf("Failed to create task in benchmark: %v", err)
 				continue
 			}
-			if err := repos.Tasks.Delete(ctx, task.ID); err != nil {
+			if err := repos.Tasks.Delete(ctx, task.ID, task.Version); err != nil {
 				b.Errorf("Failed to delete task in benchmark: %v", err)
 			} // Clean up
 		}
@@ -448,6 +465,9 @@ func setupBenchmarkDatabase(b *testing.B) (*Connection, *Repositories) {
 		Password: getEnvOrDefault("TEST_DB_PASSWORD", "testpassword"),
 		Database: getEnvOrDefault("TEST_DB_NAME", "voidrunner_test"),
 		SSLMode:  getEnvOrDefault("TEST_DB_SSL_MODE", "disable"),
+
+		MaxConns:         config.DefaultDatabaseMaxConns,
+		StatementTimeout: config.DefaultDatabaseStatementTimeout,
 	}
 
 	conn, err := NewConnection(cfg, nil)
@@ -15,6 +15,19 @@ var (
 	ErrTaskNotFound      = errors.New("task not found")
 	ErrExecutionNotFound = errors.New("execution not found")
 	ErrInvalidCursor     = errors.New("invalid cursor")
+
+	ErrTaskRevisionNotFound = errors.New("task revision not found")
+
+	ErrTaskExternalKeyExists = errors.New("task with this external key already exists")
+
+	// Updates of a task or execution that was written since it was read
+	ErrTaskVersionConflict      = errors.New("task was modified concurrently")
+	ErrExecutionVersionConflict = errors.New("execution was modified concurrently")
+
+	ErrTaskTemplateNotFound = errors.New("task template not found")
+	ErrTaskTemplateExists   = errors.New("task template with this name already exists")
+
+	ErrBulkJobNotFound = errors.New("bulk job not found")
 )
 
 // CursorPaginationRequest represents a cursor-based pagination request
@@ -34,9 +47,11 @@ type CursorPaginationResponse struct {
 
 // TaskCursor represents a cursor for task pagination
 type TaskCursor struct {
-	CreatedAt time.Time `json:"created_at"`
-	ID        uuid.UUID `json:"id"`
-	Priority  *int      `json:"priority,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ID        uuid.UUID  `json:"id"`
+	Priority  *int       `json:"priority,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Name      *string    `json:"name,omitempty"`
 }
 
 // ExecutionCursor represents a cursor for execution pagination
@@ -56,14 +71,28 @@ type UserRepository interface {
 	Count(ctx context.Context) (int64, error)
 }
 
-// TaskRepository defines the interface for task data operations
+// TaskRepository defines the interface for task data operations. Tasks in
+// the trash are left out by every method but the trash ones.
 type TaskRepository interface {
-	// Basic CRUD operations
+	// Basic CRUD operations. Delete moves the task to the trash if it still
+	// has the given version, like Update only writes the version it read.
 	Create(ctx context.Context, task *models.Task) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error)
 	Update(ctx context.Context, task *models.Task) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status models.TaskStatus) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID, version int) error
+
+	// Trash operations. Searching with TaskFilter.Deleted lists the trash.
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	// Purge deletes a task in the trash for good, with its executions, and
+	// returns the IDs of the purged executions whose output was truncated,
+	// whose spilled output is left to the caller to remove
+	Purge(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	// PurgeDeleted purges up to limit tasks moved to the trash before the
+	// given time, oldest first, and returns how many it purged and the IDs
+	// of their purged executions whose output was truncated
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, []uuid.UUID, error)
 
 	// Offset-based pagination (legacy)
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Task, error)
@@ -84,6 +113,19 @@ type TaskRepository interface {
 	Count(ctx context.Context) (int64, error)
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	CountByStatus(ctx context.Context, status models.TaskStatus) (int64, error)
+
+	// GetImageInventory returns the custom images used by a user's tasks, grouped by digest
+	GetImageInventory(ctx context.Context, userID uuid.UUID) ([]models.ImageUsage, error)
+
+	// GetLabelInventory returns the labels on a user's tasks, counting the tasks per key and value
+	GetLabelInventory(ctx context.Context, userID uuid.UUID) ([]models.LabelUsage, error)
+
+	// GetAllByUserID returns every task of a user, for exporting and
+	// reconciling manifests
+	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Task, error)
+
+	// Search lists the tasks matching the filter using cursor-based pagination
+	Search(ctx context.Context, filter TaskFilter, req CursorPaginationRequest) ([]*models.Task, CursorPaginationResponse, error)
 }
 
 // TaskExecutionRepository defines the interface for task execution data operations
@@ -94,8 +136,21 @@ type TaskExecutionRepository interface {
 	GetLatestByTaskID(ctx context.Context, taskID uuid.UUID) (*models.TaskExecution, error)
 	Update(ctx context.Context, execution *models.TaskExecution) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status models.ExecutionStatus) error
+	AppendOutput(ctx context.Context, id uuid.UUID, stdout, stderr string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 
+	// Output is stored apart from the executions, which are read without it.
+	// LoadOutputs fills in the full output of the given executions and
+	// GetOutput reads a range of bytes of a stream of an execution.
+	LoadOutputs(ctx context.Context, executions []*models.TaskExecution) error
+	GetOutput(ctx context.Context, id uuid.UUID, stream string, offset, limit int64) (*ExecutionOutput, error)
+
+	// Leases bind the lease token a runner was handed for an execution to
+	// it. SetLease replaces the lease of the execution and HoldsLease reports
+	// whether the token is the execution's current lease.
+	SetLease(ctx context.Context, id uuid.UUID, leaseToken string) error
+	HoldsLease(ctx context.Context, id uuid.UUID, leaseToken string) (bool, error)
+
 	// Offset-based pagination (legacy)
 	GetByTaskID(ctx context.Context, taskID uuid.UUID, limit, offset int) ([]*models.TaskExecution, error)
 	GetByStatus(ctx context.Context, status models.ExecutionStatus, limit, offset int) ([]*models.TaskExecution, error)
@@ -106,10 +161,121 @@ type TaskExecutionRepository interface {
 	GetByStatusCursor(ctx context.Context, status models.ExecutionStatus, req CursorPaginationRequest) ([]*models.TaskExecution, CursorPaginationResponse, error)
 	ListCursor(ctx context.Context, req CursorPaginationRequest) ([]*models.TaskExecution, CursorPaginationResponse, error)
 
+	// Search lists the executions matching the filter using cursor-based
+	// pagination, newest first unless the sort order is asc
+	Search(ctx context.Context, filter ExecutionFilter, req CursorPaginationRequest) ([]*models.TaskExecution, CursorPaginationResponse, error)
+
 	// Count operations
 	Count(ctx context.Context) (int64, error)
 	CountByTaskID(ctx context.Context, taskID uuid.UUID) (int64, error)
 	CountByStatus(ctx context.Context, status models.ExecutionStatus) (int64, error)
+
+	// Retention lists the finished executions beyond the newest keepLast of
+	// their task, oldest first, and those created before a time whose output
+	// hasn't expired. DeleteByIDs deletes executions and ExpireOutput drops
+	// their output, keeping the rest of them.
+	GetBeyondKeepLast(ctx context.Context, keepLast, limit int) ([]*models.TaskExecution, error)
+	CountBeyondKeepLast(ctx context.Context, keepLast int) (RetentionCount, error)
+	GetOutputBefore(ctx context.Context, before time.Time, limit int) ([]*models.TaskExecution, error)
+	CountOutputBefore(ctx context.Context, before time.Time) (RetentionCount, error)
+	DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error)
+	ExpireOutput(ctx context.Context, ids []uuid.UUID) (int64, error)
+}
+
+// RetentionCount counts the executions a retention rule applies to, with
+// the size of their output
+type RetentionCount struct {
+	Executions  int64
+	OutputBytes int64
+}
+
+// ExecutionOutput is a range of a stream of the output of an execution,
+// starting at Offset. Size is the size of the whole stream so far.
+type ExecutionOutput struct {
+	Stream string
+	Offset int64
+	Data   []byte
+	Size   int64
+}
+
+// ExecutionPartitionRepository defines the interface for managing the monthly
+// partitions of task executions. Partitions are bounded in UTC; executions
+// outside all of them are kept in a default partition, which isn't listed.
+type ExecutionPartitionRepository interface {
+	Create(ctx context.Context, month time.Time) (bool, error)
+	List(ctx context.Context) ([]ExecutionPartition, error)
+	Count(ctx context.Context, partition ExecutionPartition) (RetentionCount, error)
+	GetExecutions(ctx context.Context, partition ExecutionPartition, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*models.TaskExecution, error)
+	// Drop drops a partition with its executions and returns the IDs of the
+	// dropped executions with truncated output
+	Drop(ctx context.Context, partition ExecutionPartition) ([]uuid.UUID, error)
+	// WithLock calls fn with the partitions locked against maintenance by
+	// other processes until fn returns
+	WithLock(ctx context.Context, fn func(partitions ExecutionPartitionRepository) error) error
+}
+
+// OutputVolumeRepository records the volume every process spills the full
+// output of truncated executions to
+type OutputVolumeRepository interface {
+	Claim(ctx context.Context, volumeID uuid.UUID) (uuid.UUID, error)
+}
+
+// ExecutionPartition is the partition of the executions created from From
+// until To
+type ExecutionPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// TaskRevisionRepository defines the interface for task revision data operations.
+// Revisions are recorded by the task repository and never modified.
+type TaskRevisionRepository interface {
+	GetByRevision(ctx context.Context, taskID uuid.UUID, revision int) (*models.TaskRevision, error)
+	GetByTaskID(ctx context.Context, taskID uuid.UUID, limit, offset int) ([]*models.TaskRevision, error)
+	CountByTaskID(ctx context.Context, taskID uuid.UUID) (int64, error)
+}
+
+// TaskTemplateRepository defines the interface for task template data operations
+type TaskTemplateRepository interface {
+	Create(ctx context.Context, template *models.TaskTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.TaskTemplate, error)
+	Update(ctx context.Context, template *models.TaskTemplate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetVisible and CountVisible cover the templates a user can see: their
+	// own, the team templates of the given teams and the global templates
+	GetVisible(ctx context.Context, userID uuid.UUID, teams []string, limit, offset int) ([]*models.TaskTemplate, error)
+	CountVisible(ctx context.Context, userID uuid.UUID, teams []string) (int64, error)
+
+	// Import creates or replaces an owner-less template, matched by name
+	Import(ctx context.Context, template *models.TaskTemplate) error
+}
+
+// NetworkEventRepository defines the interface for execution network event data operations
+type NetworkEventRepository interface {
+	CreateBatch(ctx context.Context, executionID uuid.UUID, events []models.NetworkEvent) error
+	GetByExecutionID(ctx context.Context, executionID uuid.UUID, limit int) ([]models.NetworkEvent, error)
+}
+
+// BulkJobRepository defines the interface for bulk job data operations
+type BulkJobRepository interface {
+	Create(ctx context.Context, job *models.BulkJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BulkJob, error)
+
+	// GetByUserID lists a user's jobs, newest first, without their results
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.BulkJob, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// UpdateProgress saves the status, progress and results of a job
+	UpdateProgress(ctx context.Context, job *models.BulkJob) error
+
+	// Heartbeat records that the jobs, performed by this server, are alive
+	Heartbeat(ctx context.Context, ids []uuid.UUID) error
+
+	// FailStale fails the pending and running jobs whose last heartbeat is
+	// older than the given time with the reason
+	FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error)
 }
 
 // Repositories aggregates all repository interfaces
@@ -117,6 +283,13 @@ type Repositories struct {
 	Users          UserRepository
 	Tasks          TaskRepository
 	TaskExecutions TaskExecutionRepository
+	NetworkEvents  NetworkEventRepository
+	TaskRevisions  TaskRevisionRepository
+	TaskTemplates  TaskTemplateRepository
+	BulkJobs       BulkJobRepository
+
+	ExecutionPartitions ExecutionPartitionRepository
+	OutputVolumes       OutputVolumeRepository
 }
 
 // NewRepositories creates a new repositories instance
@@ -125,5 +298,12 @@ func NewRepositories(conn *Connection) *Repositories {
 		Users:          NewUserRepository(conn),
 		Tasks:          NewTaskRepository(conn),
 		TaskExecutions: NewTaskExecutionRepository(conn),
+		NetworkEvents:  NewNetworkEventRepository(conn),
+		TaskRevisions:  NewTaskRevisionRepository(conn),
+		TaskTemplates:  NewTaskTemplateRepository(conn),
+		BulkJobs:       NewBulkJobRepository(conn),
+
+		ExecutionPartitions: NewExecutionPartitionRepository(conn),
+		OutputVolumes:       NewOutputVolumeRepository(conn),
 	}
 }
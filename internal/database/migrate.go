@@ -45,15 +45,7 @@ func NewMigrator(cfg *MigrateConfig) (*Migrator, error) {
 	}
 
 	// Create database connection string for sql.DB
-	connStr := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.DatabaseConfig.User,
-		cfg.DatabaseConfig.Password,
-		cfg.DatabaseConfig.Host,
-		cfg.DatabaseConfig.Port,
-		cfg.DatabaseConfig.Database,
-		cfg.DatabaseConfig.SSLMode,
-	)
+	connStr := cfg.DatabaseConfig.ConnectionString()
 
 	// Open database connection using database/sql
 	db, err := sql.Open("pgx", connStr)
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// networkEventRepository implements NetworkEventRepository interface
+type networkEventRepository struct {
+	querier Querier
+}
+
+// NewNetworkEventRepository creates a new network event repository
+func NewNetworkEventRepository(conn *Connection) NetworkEventRepository {
+	return &networkEventRepository{
+		querier: conn.Pool,
+	}
+}
+
+// NewNetworkEventRepositoryWithTx creates a new network event repository with transaction
+func NewNetworkEventRepositoryWithTx(tx pgx.Tx) NetworkEventRepository {
+	return &networkEventRepository{
+		querier: tx,
+	}
+}
+
+// CreateBatch records the connection attempts of an execution in a single statement
+func (r *networkEventRepository) CreateBatch(ctx context.Context, executionID uuid.UUID, events []models.NetworkEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	hosts := make([]string, len(events))
+	ports := make([]int32, len(events))
+	allowed := make([]bool, len(events))
+	reasons := make([]string, len(events))
+	occurredAt := make([]time.Time, len(events))
+	for i, event := range events {
+		hosts[i] = event.Host
+		ports[i] = int32(event.Port)
+		allowed[i] = event.Allowed
+		reasons[i] = event.Reason
+		occurredAt[i] = event.OccurredAt
+	}
+
+	query := `
+		INSERT INTO execution_network_events (execution_id, execution_created_at, host, port, allowed, reason, occurred_at)
+		SELECT $1, (SELECT created_at FROM task_executions WHERE id = $1), host, port, allowed, NULLIF(reason, ''), occurred_at
+		FROM unnest($2::text[], $3::int[], $4::bool[], $5::text[], $6::timestamptz[]) AS e(host, port, allowed, reason, occurred_at)
+	`
+
+	if _, err := r.querier.Exec(ctx, query, executionID, hosts, ports, allowed, reasons, occurredAt); err != nil {
+		return fmt.Errorf("failed to create network events: %w", err)
+	}
+
+	return nil
+}
+
+// GetByExecutionID retrieves the connection attempts of an execution in the order they were made
+func (r *networkEventRepository) GetByExecutionID(ctx context.Context, executionID uuid.UUID, limit int) ([]models.NetworkEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id, execution_id, host, port, allowed, COALESCE(reason, ''), occurred_at
+		FROM execution_network_events
+		WHERE execution_id = $1
+		ORDER BY occurred_at, id
+		LIMIT $2
+	`
+
+	rows, err := r.querier.Query(ctx, query, executionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.NetworkEvent{}
+	for rows.Next() {
+		var event models.NetworkEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.ExecutionID,
+			&event.Host,
+			&event.Port,
+			&event.Allowed,
+			&event.Reason,
+			&event.OccurredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan network event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating network event rows: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// outputVolumeRepository implements OutputVolumeRepository interface
+type outputVolumeRepository struct {
+	querier Querier
+}
+
+// NewOutputVolumeRepository creates a new output volume repository
+func NewOutputVolumeRepository(conn *Connection) OutputVolumeRepository {
+	return &outputVolumeRepository{
+		querier: conn.Pool,
+	}
+}
+
+// NewOutputVolumeRepositoryWithTx creates a new output volume repository with transaction
+func NewOutputVolumeRepositoryWithTx(tx pgx.Tx) OutputVolumeRepository {
+	return &outputVolumeRepository{
+		querier: tx,
+	}
+}
+
+// Claim records the given volume as the spill volume unless one is recorded
+// already, and returns the recorded volume
+func (r *outputVolumeRepository) Claim(ctx context.Context, volumeID uuid.UUID) (uuid.UUID, error) {
+	// The SELECT doesn't see the row inserted by the same statement, so only
+	// one of the two returns a volume
+	query := `
+		WITH claimed AS (
+			INSERT INTO output_spill_volume (volume_id)
+			VALUES ($1)
+			ON CONFLICT (singleton) DO NOTHING
+			RETURNING volume_id
+		)
+		SELECT volume_id FROM claimed
+		UNION ALL
+		SELECT volume_id FROM output_spill_volume
+	`
+
+	var recorded uuid.UUID
+	if err := r.querier.QueryRow(ctx, query, volumeID).Scan(&recorded); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to claim output spill volume: %w", err)
+	}
+
+	return recorded, nil
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputVolumeRepository_Claim(t *testing.T) {
+	t.Run("returns the recorded volume", func(t *testing.T) {
+		volumeID, recordedID := uuid.New(), uuid.New()
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), []interface{}{volumeID}).
+			Return(&MockRow{data: []interface{}{recordedID}})
+
+		repo := &outputVolumeRepository{querier: mockQuerier}
+		recorded, err := repo.Claim(context.Background(), volumeID)
+		require.NoError(t, err)
+		assert.Equal(t, recordedID, recorded)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("returns database errors", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).
+			Return(&MockRow{err: errors.New("connection refused")})
+
+		repo := &outputVolumeRepository{querier: mockQuerier}
+		_, err := repo.Claim(context.Background(), uuid.New())
+		assert.Error(t, err)
+	})
+}
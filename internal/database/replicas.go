@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+)
+
+// replicaPingTimeout bounds the health check of a read replica
+const replicaPingTimeout = 5 * time.Second
+
+// replica is a read replica with the result of its last health check
+type replica struct {
+	pool    *pgxpool.Pool
+	host    string
+	healthy atomic.Bool
+}
+
+// primaryReadsKey is the context key of reads that must go to the primary
+type primaryReadsKey struct{}
+
+// WithPrimary returns a context whose reads go to the primary, so that they
+// see the writes made before them, e.g. for the rest of a request after a
+// mutation
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// ReadsPrimary reports whether reads in the context go to the primary
+func ReadsPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// Reader returns a querier for read-only queries. Each query goes to the next
+// healthy read replica, or to the primary when there is none or the context
+// reads from the primary.
+func (c *Connection) Reader() Querier {
+	return &replicaQuerier{conn: c}
+}
+
+// ReplicaCount returns the number of read replicas and how many of them
+// passed their last health check
+func (c *Connection) ReplicaCount() (total, healthy int) {
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	return len(c.replicas), healthy
+}
+
+// readPool picks the pool of the next healthy replica, round-robin, or the
+// primary
+func (c *Connection) readPool(ctx context.Context) *pgxpool.Pool {
+	if len(c.replicas) == 0 || ReadsPrimary(ctx) {
+		return c.Pool
+	}
+
+	// Advance the counter for every replica tried so that skipping an
+	// unhealthy replica does not favour the one after it.
+	for range len(c.replicas) {
+		r := c.replicas[c.nextReplica.Add(1)%uint64(len(c.replicas))]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return c.Pool
+}
+
+// connectReplicas creates the pools of the read replicas, checks them once
+// and then every interval until the connection is closed. Replicas are only
+// read from once they passed a check.
+func (c *Connection) connectReplicas(cfg *config.DatabaseConfig) error {
+	for _, replicaURL := range cfg.ReplicaURLs {
+		poolConfig, err := newPoolConfig(replicaURL, cfg)
+		if err != nil {
+			c.closeReplicas()
+			return fmt.Errorf("failed to parse database replica connection string: %w", err)
+		}
+
+		// Replica pools connect lazily, so an unreachable replica doesn't
+		// prevent starting
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			c.closeReplicas()
+			return fmt.Errorf("failed to create database replica pool: %w", err)
+		}
+		c.replicas = append(c.replicas, &replica{pool: pool, host: poolConfig.ConnConfig.Host})
+	}
+
+	if len(c.replicas) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopReplicaChecks = cancel
+	c.checkReplicas(ctx)
+
+	c.replicaChecks.Add(1)
+	go func() {
+		defer c.replicaChecks.Done()
+
+		ticker := time.NewTicker(cfg.ReplicaHealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.checkReplicas(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// checkReplicas pings every replica, logging those changing health
+func (c *Connection) checkReplicas(ctx context.Context) {
+	for _, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := r.pool.Ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			c.logger.Info("database replica is healthy, routing reads to it", "host", r.host)
+		} else {
+			c.logger.Warn("database replica failed its health check, skipping it", "host", r.host, "error", err)
+		}
+	}
+}
+
+// closeReplicas stops checking the replicas and closes their pools
+func (c *Connection) closeReplicas() {
+	if c.stopReplicaChecks != nil {
+		c.stopReplicaChecks()
+		c.replicaChecks.Wait()
+	}
+	for _, r := range c.replicas {
+		r.pool.Close()
+	}
+}
+
+// replicaQuerier runs read-only queries on the pool the connection picks
+// for each of them
+type replicaQuerier struct {
+	conn *Connection
+}
+
+func (q *replicaQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return q.conn.readPool(ctx).Query(ctx, sql, args...)
+}
+
+func (q *replicaQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return q.conn.readPool(ctx).QueryRow(ctx, sql, args...)
+}
+
+func (q *replicaQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return q.conn.readPool(ctx).Exec(ctx, sql, args...)
+}
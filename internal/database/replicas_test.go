@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLazyPool creates a pool that never connects unless it is used
+func newLazyPool(t *testing.T) *pgxpool.Pool {
+	pool, err := pgxpool.New(context.Background(), "postgres://test@127.0.0.1:1/test?connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func newReplicaConnection(t *testing.T, healthy ...bool) *Connection {
+	conn := &Connection{Pool: newLazyPool(t), logger: slog.Default()}
+	for _, h := range healthy {
+		r := &replica{pool: newLazyPool(t), host: "127.0.0.1"}
+		r.healthy.Store(h)
+		conn.replicas = append(conn.replicas, r)
+	}
+	return conn
+}
+
+func TestConnection_ReadPool(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads from the primary without replicas", func(t *testing.T) {
+		conn := newReplicaConnection(t)
+		assert.Same(t, conn.Pool, conn.readPool(ctx))
+	})
+
+	t.Run("spreads reads over healthy replicas", func(t *testing.T) {
+		conn := newReplicaConnection(t, true, false, true)
+
+		seen := map[*pgxpool.Pool]int{}
+		for range 4 {
+			seen[conn.readPool(ctx)]++
+		}
+		assert.Equal(t, 2, seen[conn.replicas[0].pool])
+		assert.Equal(t, 2, seen[conn.replicas[2].pool])
+		assert.Zero(t, seen[conn.replicas[1].pool], "unhealthy replicas are skipped")
+		assert.Zero(t, seen[conn.Pool])
+	})
+
+	t.Run("falls back to the primary without healthy replicas", func(t *testing.T) {
+		conn := newReplicaConnection(t, false, false)
+		assert.Same(t, conn.Pool, conn.readPool(ctx))
+	})
+
+	t.Run("reads from the primary when asked to", func(t *testing.T) {
+		conn := newReplicaConnection(t, true)
+		assert.Same(t, conn.Pool, conn.readPool(WithPrimary(ctx)))
+		assert.True(t, ReadsPrimary(WithPrimary(ctx)))
+		assert.False(t, ReadsPrimary(ctx))
+	})
+}
+
+func TestConnection_CheckReplicas(t *testing.T) {
+	conn := newReplicaConnection(t, true)
+
+	conn.checkReplicas(context.Background())
+
+	total, healthy := conn.ReplicaCount()
+	assert.Equal(t, 1, total)
+	assert.Equal(t, 0, healthy, "unreachable replicas fail their health check")
+	assert.Same(t, conn.Pool, conn.readPool(context.Background()))
+}
@@ -2,9 +2,12 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -12,9 +15,11 @@ import (
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
-// taskExecutionRepository implements TaskExecutionRepository interface
+// taskExecutionRepository implements TaskExecutionRepository interface.
+// Listings and counts run on the read querier, which may be a read replica.
 type taskExecutionRepository struct {
 	querier       Querier
+	readQuerier   Querier
 	cursorEncoder *CursorEncoder
 }
 
@@ -22,10 +27,19 @@ type taskExecutionRepository struct {
 func NewTaskExecutionRepository(conn *Connection) TaskExecutionRepository {
 	return &taskExecutionRepository{
 		querier:       conn.Pool,
+		readQuerier:   conn.Reader(),
 		cursorEncoder: NewCursorEncoder(),
 	}
 }
 
+// reader returns the querier of read-only queries
+func (r *taskExecutionRepository) reader() Querier {
+	if r.readQuerier == nil {
+		return r.querier
+	}
+	return r.readQuerier
+}
+
 // NewTaskExecutionRepositoryWithTx creates a new task execution repository with transaction
 func NewTaskExecutionRepositoryWithTx(tx pgx.Tx) TaskExecutionRepository {
 	return &taskExecutionRepository{
@@ -44,24 +58,50 @@ func (r *taskExecutionRepository) Create(ctx context.Context, execution *models.
 		execution.ID = models.NewID()
 	}
 
+	if execution.SecurityLevel == "" {
+		execution.SecurityLevel = models.SecurityLevelStandard
+	}
+
+	// The execution and its output are inserted by a single statement
 	query := `
-		INSERT INTO task_executions (id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
-		RETURNING created_at
+		WITH execution AS (
+			INSERT INTO task_executions (id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
+			RETURNING id, version, created_at
+		), output AS (
+			INSERT INTO execution_outputs (execution_id, execution_created_at, stream, seq, byte_offset, data)
+			SELECT execution.id, execution.created_at, chunk.stream, chunk.seq, chunk.byte_offset, chunk.data
+			FROM execution, unnest($17::text[], $18::int[], $19::bigint[], $20::bytea[]) AS chunk(stream, seq, byte_offset, data)
+		)
+		SELECT version, created_at FROM execution
 	`
 
+	var chunks outputChunks
+	chunks.add(outputStreamStdout, execution.Stdout)
+	chunks.add(outputStreamStderr, execution.Stderr)
+
 	err := r.querier.QueryRow(ctx, query,
 		execution.ID,
 		execution.TaskID,
 		execution.Status,
 		execution.ReturnCode,
-		execution.Stdout,
-		execution.Stderr,
 		execution.ExecutionTimeMs,
 		execution.MemoryUsageBytes,
 		execution.StartedAt,
 		execution.CompletedAt,
-	).Scan(&execution.CreatedAt)
+		execution.SecurityLevel,
+		execution.Runtime,
+		execution.Truncated,
+		execution.OOMKilled,
+		execution.ExitSignal,
+		execution.TimeoutPhase,
+		execution.ErrorCategory,
+		execution.Revision,
+		chunks.streams,
+		chunks.seqs,
+		chunks.offsets,
+		chunks.data,
+	).Scan(&execution.Version, &execution.CreatedAt)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -86,7 +126,7 @@ func (r *taskExecutionRepository) Create(ctx context.Context, execution *models.
 // GetByID retrieves a task execution by ID
 func (r *taskExecutionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TaskExecution, error) {
 	query := `
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		WHERE id = $1
 	`
@@ -97,18 +137,26 @@ func (r *taskExecutionRepository) GetByID(ctx context.Context, id uuid.UUID) (*m
 		&execution.TaskID,
 		&execution.Status,
 		&execution.ReturnCode,
-		&execution.Stdout,
-		&execution.Stderr,
 		&execution.ExecutionTimeMs,
 		&execution.MemoryUsageBytes,
 		&execution.StartedAt,
 		&execution.CompletedAt,
+		&execution.SecurityLevel,
+		&execution.Runtime,
+		&execution.Truncated,
+		&execution.OOMKilled,
+		&execution.ExitSignal,
+		&execution.TimeoutPhase,
+		&execution.ErrorCategory,
+		&execution.Revision,
+		&execution.Version,
+		&execution.OutputExpiredAt,
 		&execution.CreatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("task execution with ID %s not found", id)
+			return nil, ErrExecutionNotFound
 		}
 		return nil, fmt.Errorf("failed to get task execution by ID: %w", err)
 	}
@@ -126,14 +174,14 @@ func (r *taskExecutionRepository) GetByTaskID(ctx context.Context, taskID uuid.U
 	}
 
 	query := `
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		WHERE task_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, query, taskID, limit, offset)
+	rows, err := r.reader().Query(ctx, query, taskID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task executions by task ID: %w", err)
 	}
@@ -145,7 +193,7 @@ func (r *taskExecutionRepository) GetByTaskID(ctx context.Context, taskID uuid.U
 // GetLatestByTaskID retrieves the latest task execution for a task
 func (r *taskExecutionRepository) GetLatestByTaskID(ctx context.Context, taskID uuid.UUID) (*models.TaskExecution, error) {
 	query := `
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		WHERE task_id = $1
 		ORDER BY created_at DESC
@@ -158,12 +206,20 @@ func (r *taskExecutionRepository) GetLatestByTaskID(ctx context.Context, taskID
 		&execution.TaskID,
 		&execution.Status,
 		&execution.ReturnCode,
-		&execution.Stdout,
-		&execution.Stderr,
 		&execution.ExecutionTimeMs,
 		&execution.MemoryUsageBytes,
 		&execution.StartedAt,
 		&execution.CompletedAt,
+		&execution.SecurityLevel,
+		&execution.Runtime,
+		&execution.Truncated,
+		&execution.OOMKilled,
+		&execution.ExitSignal,
+		&execution.TimeoutPhase,
+		&execution.ErrorCategory,
+		&execution.Revision,
+		&execution.Version,
+		&execution.OutputExpiredAt,
 		&execution.CreatedAt,
 	)
 
@@ -187,14 +243,14 @@ func (r *taskExecutionRepository) GetByStatus(ctx context.Context, status models
 	}
 
 	query := `
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		WHERE status = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, query, status, limit, offset)
+	rows, err := r.reader().Query(ctx, query, status, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task executions by status: %w", err)
 	}
@@ -203,31 +259,75 @@ func (r *taskExecutionRepository) GetByStatus(ctx context.Context, status models
 	return r.scanTaskExecutions(rows)
 }
 
-// Update updates a task execution
+// Update updates a task execution. The update only applies to the version the
+// execution was read at.
 func (r *taskExecutionRepository) Update(ctx context.Context, execution *models.TaskExecution) error {
 	if execution == nil {
 		return fmt.Errorf("task execution cannot be nil")
 	}
 
+	// The streams that are set replace the stored ones in the same statement.
+	// All parts of the statement see the output as it was before it, so the
+	// new chunks are numbered after the old ones they replace.
 	query := `
-		UPDATE task_executions
-		SET status = $2, return_code = $3, stdout = $4, stderr = $5, execution_time_ms = $6, memory_usage_bytes = $7, started_at = $8, completed_at = $9
-		WHERE id = $1
+		WITH execution AS (
+			UPDATE task_executions
+			SET status = $2, return_code = $3, execution_time_ms = $4, memory_usage_bytes = $5, started_at = $6, completed_at = $7, runtime = $8, truncated = $9, oom_killed = $10, exit_signal = $11, timeout_phase = $12, error_category = $13,
+				version = version + 1
+			WHERE id = $1 AND version = $14
+			RETURNING id, version, created_at
+		), replaced AS (
+			DELETE FROM execution_outputs o
+			USING execution
+			WHERE o.execution_id = execution.id AND o.stream = ANY($15::text[])
+		), output AS (
+			INSERT INTO execution_outputs (execution_id, execution_created_at, stream, seq, byte_offset, data)
+			SELECT execution.id, execution.created_at, chunk.stream,
+				COALESCE((SELECT MAX(o.seq) + 1 FROM execution_outputs o WHERE o.execution_id = execution.id AND o.stream = chunk.stream), 0) + chunk.seq,
+				chunk.byte_offset, chunk.data
+			FROM execution, unnest($16::text[], $17::int[], $18::bigint[], $19::bytea[]) AS chunk(stream, seq, byte_offset, data)
+		)
+		SELECT version FROM execution
 	`
 
-	result, err := r.querier.Exec(ctx, query,
+	var chunks outputChunks
+	replaced := []string{}
+	if execution.Stdout != nil {
+		replaced = append(replaced, outputStreamStdout)
+		chunks.add(outputStreamStdout, execution.Stdout)
+	}
+	if execution.Stderr != nil {
+		replaced = append(replaced, outputStreamStderr)
+		chunks.add(outputStreamStderr, execution.Stderr)
+	}
+
+	err := r.querier.QueryRow(ctx, query,
 		execution.ID,
 		execution.Status,
 		execution.ReturnCode,
-		execution.Stdout,
-		execution.Stderr,
 		execution.ExecutionTimeMs,
 		execution.MemoryUsageBytes,
 		execution.StartedAt,
 		execution.CompletedAt,
-	)
+		execution.Runtime,
+		execution.Truncated,
+		execution.OOMKilled,
+		execution.ExitSignal,
+		execution.TimeoutPhase,
+		execution.ErrorCategory,
+		execution.Version,
+		replaced,
+		chunks.streams,
+		chunks.seqs,
+		chunks.offsets,
+		chunks.data,
+	).Scan(&execution.Version)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.versionConflict(ctx, execution.ID)
+		}
+
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
 			return fmt.Errorf("task execution validation failed: %s", pgErr.Detail)
@@ -235,18 +335,27 @@ func (r *taskExecutionRepository) Update(ctx context.Context, execution *models.
 		return fmt.Errorf("failed to update task execution: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task execution with ID %s not found", execution.ID)
-	}
-
 	return nil
 }
 
+// versionConflict tells why an update of the execution matched no row: the
+// execution is gone, or it was written since it was read
+func (r *taskExecutionRepository) versionConflict(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := r.querier.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM task_executions WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check task execution: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("task execution with ID %s not found", id)
+	}
+	return ErrExecutionVersionConflict
+}
+
 // UpdateStatus updates only the status of a task execution
 func (r *taskExecutionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.ExecutionStatus) error {
 	query := `
 		UPDATE task_executions
-		SET status = $2
+		SET status = $2, version = version + 1
 		WHERE id = $1
 	`
 
@@ -266,6 +375,225 @@ func (r *taskExecutionRepository) UpdateStatus(ctx context.Context, id uuid.UUID
 	return nil
 }
 
+// SetLease stores the hash of the lease token the execution was leased with,
+// replacing the lease of a previous runner
+func (r *taskExecutionRepository) SetLease(ctx context.Context, id uuid.UUID, leaseToken string) error {
+	query := `
+		UPDATE task_executions
+		SET lease_token_hash = $2
+		WHERE id = $1
+	`
+
+	result, err := r.querier.Exec(ctx, query, id, leaseTokenHash(leaseToken))
+	if err != nil {
+		return fmt.Errorf("failed to set task execution lease: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrExecutionNotFound
+	}
+
+	return nil
+}
+
+// HoldsLease reports whether the lease token is the current lease of the
+// execution
+func (r *taskExecutionRepository) HoldsLease(ctx context.Context, id uuid.UUID, leaseToken string) (bool, error) {
+	query := `
+		SELECT COALESCE(lease_token_hash = $2, false)
+		FROM task_executions
+		WHERE id = $1
+	`
+
+	var holds bool
+	err := r.querier.QueryRow(ctx, query, id, leaseTokenHash(leaseToken)).Scan(&holds)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrExecutionNotFound
+		}
+		return false, fmt.Errorf("failed to check task execution lease: %w", err)
+	}
+
+	return holds, nil
+}
+
+// leaseTokenHash hashes a lease token, so that the database holds no token
+// that could be presented as a lease
+func leaseTokenHash(leaseToken string) []byte {
+	hash := sha256.Sum256([]byte(leaseToken))
+	return hash[:]
+}
+
+// AppendOutput appends streamed output to a running task execution. The
+// chunks continue the sequence and offsets of each stream; appends of an
+// execution come in order from the runner holding its lease.
+func (r *taskExecutionRepository) AppendOutput(ctx context.Context, id uuid.UUID, stdout, stderr string) error {
+	query := `
+		WITH execution AS (
+			UPDATE task_executions
+			SET version = version + 1
+			WHERE id = $1
+			RETURNING id, created_at
+		), output AS (
+			INSERT INTO execution_outputs (execution_id, execution_created_at, stream, seq, byte_offset, data)
+			SELECT execution.id, execution.created_at, chunk.stream,
+				COALESCE(last.seq + 1, 0) + chunk.seq,
+				COALESCE(last.byte_offset + octet_length(last.data), 0) + chunk.byte_offset,
+				chunk.data
+			FROM execution
+			CROSS JOIN unnest($2::text[], $3::int[], $4::bigint[], $5::bytea[]) AS chunk(stream, seq, byte_offset, data)
+			LEFT JOIN LATERAL (
+				SELECT o.seq, o.byte_offset, o.data
+				FROM execution_outputs o
+				WHERE o.execution_id = execution.id AND o.stream = chunk.stream
+				ORDER BY o.seq DESC
+				LIMIT 1
+			) last ON TRUE
+		)
+		SELECT id FROM execution
+	`
+
+	var chunks outputChunks
+	chunks.add(outputStreamStdout, &stdout)
+	chunks.add(outputStreamStderr, &stderr)
+
+	var appendedID uuid.UUID
+	err := r.querier.QueryRow(ctx, query, id, chunks.streams, chunks.seqs, chunks.offsets, chunks.data).Scan(&appendedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("task execution with ID %s not found", id)
+		}
+		return fmt.Errorf("failed to append task execution output: %w", err)
+	}
+
+	return nil
+}
+
+// LoadOutputs fills in the stdout and stderr of the given executions. Streams
+// without output are left nil.
+func (r *taskExecutionRepository) LoadOutputs(ctx context.Context, executions []*models.TaskExecution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*models.TaskExecution, len(executions))
+	ids := make([]uuid.UUID, 0, len(executions))
+	for _, execution := range executions {
+		byID[execution.ID] = execution
+		ids = append(ids, execution.ID)
+	}
+
+	query := `
+		SELECT execution_id, stream, string_agg(data, ''::bytea ORDER BY seq)
+		FROM execution_outputs
+		WHERE execution_id = ANY($1)
+		GROUP BY execution_id, stream
+	`
+
+	rows, err := r.querier.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load task execution output: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			executionID uuid.UUID
+			stream      string
+			data        []byte
+		)
+		if err := rows.Scan(&executionID, &stream, &data); err != nil {
+			return fmt.Errorf("failed to scan task execution output: %w", err)
+		}
+
+		execution, ok := byID[executionID]
+		if !ok {
+			continue
+		}
+		output := string(data)
+		switch stream {
+		case outputStreamStdout:
+			execution.Stdout = &output
+		case outputStreamStderr:
+			execution.Stderr = &output
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating task execution output rows: %w", err)
+	}
+
+	return nil
+}
+
+// GetOutput reads up to limit bytes of a stream of an execution, starting at
+// offset. The range ends before a character cut at its end, unless the
+// stream ends there.
+func (r *taskExecutionRepository) GetOutput(ctx context.Context, id uuid.UUID, stream string, offset, limit int64) (*ExecutionOutput, error) {
+	if stream != outputStreamStdout && stream != outputStreamStderr {
+		return nil, fmt.Errorf("invalid output stream: %q", stream)
+	}
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("invalid output range: offset %d, limit %d", offset, limit)
+	}
+
+	output := &ExecutionOutput{Stream: stream, Offset: offset}
+
+	sizeQuery := `
+		SELECT COALESCE(MAX(byte_offset + octet_length(data)), 0)
+		FROM execution_outputs
+		WHERE execution_id = $1 AND stream = $2
+	`
+	if err := r.querier.QueryRow(ctx, sizeQuery, id, stream).Scan(&output.Size); err != nil {
+		return nil, fmt.Errorf("failed to get task execution output size: %w", err)
+	}
+
+	if offset >= output.Size {
+		output.Offset = output.Size
+		return output, nil
+	}
+
+	query := `
+		SELECT byte_offset, data
+		FROM execution_outputs
+		WHERE execution_id = $1 AND stream = $2 AND byte_offset < $3 + $4 AND byte_offset + octet_length(data) > $3
+		ORDER BY seq
+	`
+
+	rows, err := r.querier.Query(ctx, query, id, stream, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task execution output: %w", err)
+	}
+	defer rows.Close()
+
+	end := min(offset+limit, output.Size)
+	data := make([]byte, 0, end-offset)
+	for rows.Next() {
+		var (
+			chunkOffset int64
+			chunk       []byte
+		)
+		if err := rows.Scan(&chunkOffset, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to scan task execution output: %w", err)
+		}
+
+		from := max(offset-chunkOffset, 0)
+		to := min(end-chunkOffset, int64(len(chunk)))
+		data = append(data, chunk[from:to]...)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task execution output rows: %w", err)
+	}
+
+	if offset+int64(len(data)) < output.Size {
+		data = trimPartialRune(data)
+	}
+	output.Data = data
+
+	return output, nil
+}
+
 // Delete deletes a task execution
 func (r *taskExecutionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM task_executions WHERE id = $1`
@@ -282,6 +610,131 @@ func (r *taskExecutionRepository) Delete(ctx context.Context, id uuid.UUID) erro
 	return nil
 }
 
+// GetBeyondKeepLast retrieves the oldest finished executions that aren't
+// among the newest keepLast executions of their task
+func (r *taskExecutionRepository) GetBeyondKeepLast(ctx context.Context, keepLast, limit int) ([]*models.TaskExecution, error) {
+	query := `
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY created_at DESC, id DESC) AS position
+			FROM task_executions
+		) e
+		WHERE position > $1 AND status IN ('completed', 'failed', 'timeout', 'cancelled')
+		ORDER BY created_at, id
+		LIMIT $2
+	`
+
+	rows, err := r.querier.Query(ctx, query, keepLast, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task executions beyond retention: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTaskExecutions(rows)
+}
+
+// CountBeyondKeepLast counts the executions GetBeyondKeepLast retrieves
+func (r *taskExecutionRepository) CountBeyondKeepLast(ctx context.Context, keepLast int) (RetentionCount, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(o.bytes), 0)::bigint
+		FROM (
+			SELECT id, status, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY created_at DESC, id DESC) AS position
+			FROM task_executions
+		) e
+		LEFT JOIN (
+			SELECT execution_id, SUM(octet_length(data)) AS bytes
+			FROM execution_outputs
+			GROUP BY execution_id
+		) o ON o.execution_id = e.id
+		WHERE position > $1 AND status IN ('completed', 'failed', 'timeout', 'cancelled')
+	`
+
+	var count RetentionCount
+	err := r.querier.QueryRow(ctx, query, keepLast).Scan(&count.Executions, &count.OutputBytes)
+	if err != nil {
+		return RetentionCount{}, fmt.Errorf("failed to count task executions beyond retention: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetOutputBefore retrieves the oldest finished executions created before the
+// given time whose output hasn't expired
+func (r *taskExecutionRepository) GetOutputBefore(ctx context.Context, before time.Time, limit int) ([]*models.TaskExecution, error) {
+	query := `
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
+		FROM task_executions
+		WHERE created_at < $1 AND output_expired_at IS NULL AND status IN ('completed', 'failed', 'timeout', 'cancelled')
+		ORDER BY created_at, id
+		LIMIT $2
+	`
+
+	rows, err := r.querier.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task executions with expired output: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTaskExecutions(rows)
+}
+
+// CountOutputBefore counts the executions GetOutputBefore retrieves
+func (r *taskExecutionRepository) CountOutputBefore(ctx context.Context, before time.Time) (RetentionCount, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(o.bytes), 0)::bigint
+		FROM task_executions e
+		LEFT JOIN (
+			SELECT execution_id, SUM(octet_length(data)) AS bytes
+			FROM execution_outputs
+			WHERE execution_created_at < $1
+			GROUP BY execution_id
+		) o ON o.execution_id = e.id
+		WHERE e.created_at < $1 AND e.output_expired_at IS NULL AND e.status IN ('completed', 'failed', 'timeout', 'cancelled')
+	`
+
+	var count RetentionCount
+	err := r.querier.QueryRow(ctx, query, before).Scan(&count.Executions, &count.OutputBytes)
+	if err != nil {
+		return RetentionCount{}, fmt.Errorf("failed to count task executions with expired output: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteByIDs deletes the given task executions and returns how many it deleted
+func (r *taskExecutionRepository) DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	result, err := r.querier.Exec(ctx, `DELETE FROM task_executions WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete task executions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ExpireOutput drops the output of the given task executions, keeping the
+// rest of them, and returns how many it updated
+func (r *taskExecutionRepository) ExpireOutput(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	query := `
+		WITH expired AS (
+			UPDATE task_executions
+			SET output_expired_at = NOW(), version = version + 1
+			WHERE id = ANY($1) AND output_expired_at IS NULL
+			RETURNING id
+		), dropped AS (
+			DELETE FROM execution_outputs
+			WHERE execution_id IN (SELECT id FROM expired)
+		)
+		SELECT COUNT(*) FROM expired
+	`
+
+	var expired int64
+	if err := r.querier.QueryRow(ctx, query, ids).Scan(&expired); err != nil {
+		return 0, fmt.Errorf("failed to expire task execution output: %w", err)
+	}
+
+	return expired, nil
+}
+
 // List retrieves task executions with pagination
 func (r *taskExecutionRepository) List(ctx context.Context, limit, offset int) ([]*models.TaskExecution, error) {
 	if limit <= 0 {
@@ -292,13 +745,13 @@ func (r *taskExecutionRepository) List(ctx context.Context, limit, offset int) (
 	}
 
 	query := `
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.querier.Query(ctx, query, limit, offset)
+	rows, err := r.reader().Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list task executions: %w", err)
 	}
@@ -312,7 +765,7 @@ func (r *taskExecutionRepository) Count(ctx context.Context) (int64, error) {
 	query := `SELECT COUNT(*) FROM task_executions`
 
 	var count int64
-	err := r.querier.QueryRow(ctx, query).Scan(&count)
+	err := r.reader().QueryRow(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count task executions: %w", err)
 	}
@@ -325,7 +778,7 @@ func (r *taskExecutionRepository) CountByTaskID(ctx context.Context, taskID uuid
 	query := `SELECT COUNT(*) FROM task_executions WHERE task_id = $1`
 
 	var count int64
-	err := r.querier.QueryRow(ctx, query, taskID).Scan(&count)
+	err := r.reader().QueryRow(ctx, query, taskID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count task executions by task ID: %w", err)
 	}
@@ -338,7 +791,7 @@ func (r *taskExecutionRepository) CountByStatus(ctx context.Context, status mode
 	query := `SELECT COUNT(*) FROM task_executions WHERE status = $1`
 
 	var count int64
-	err := r.querier.QueryRow(ctx, query, status).Scan(&count)
+	err := r.reader().QueryRow(ctx, query, status).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count task executions by status: %w", err)
 	}
@@ -356,12 +809,20 @@ func (r *taskExecutionRepository) scanTaskExecutions(rows pgx.Rows) ([]*models.T
 			&execution.TaskID,
 			&execution.Status,
 			&execution.ReturnCode,
-			&execution.Stdout,
-			&execution.Stderr,
 			&execution.ExecutionTimeMs,
 			&execution.MemoryUsageBytes,
 			&execution.StartedAt,
 			&execution.CompletedAt,
+			&execution.SecurityLevel,
+			&execution.Runtime,
+			&execution.Truncated,
+			&execution.OOMKilled,
+			&execution.ExitSignal,
+			&execution.TimeoutPhase,
+			&execution.ErrorCategory,
+			&execution.Revision,
+			&execution.Version,
+			&execution.OutputExpiredAt,
 			&execution.CreatedAt,
 		)
 		if err != nil {
@@ -402,7 +863,7 @@ func (r *taskExecutionRepository) GetByTaskIDCursor(ctx context.Context, taskID
 	whereClause, args := BuildExecutionCursorWhere(cursor, req.SortOrder, &taskID, nil)
 
 	query := fmt.Sprintf(`
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		%s
 		%s
@@ -411,7 +872,7 @@ func (r *taskExecutionRepository) GetByTaskIDCursor(ctx context.Context, taskID
 
 	args = append(args, req.Limit+1) // Fetch one extra to check if there are more results
 
-	rows, err := r.querier.Query(ctx, query, args...)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to get task executions by task ID with cursor: %w", err)
 	}
@@ -472,7 +933,7 @@ func (r *taskExecutionRepository) GetByStatusCursor(ctx context.Context, status
 	whereClause, args := BuildExecutionCursorWhere(cursor, req.SortOrder, nil, &statusStr)
 
 	query := fmt.Sprintf(`
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		%s
 		%s
@@ -481,7 +942,7 @@ func (r *taskExecutionRepository) GetByStatusCursor(ctx context.Context, status
 
 	args = append(args, req.Limit+1)
 
-	rows, err := r.querier.Query(ctx, query, args...)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to get task executions by status with cursor: %w", err)
 	}
@@ -539,7 +1000,7 @@ func (r *taskExecutionRepository) ListCursor(ctx context.Context, req CursorPagi
 	whereClause, args := BuildExecutionCursorWhere(cursor, req.SortOrder, nil, nil)
 
 	query := fmt.Sprintf(`
-		SELECT id, task_id, status, return_code, stdout, stderr, execution_time_ms, memory_usage_bytes, started_at, completed_at, created_at
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
 		FROM task_executions
 		%s
 		%s
@@ -548,7 +1009,7 @@ func (r *taskExecutionRepository) ListCursor(ctx context.Context, req CursorPagi
 
 	args = append(args, req.Limit+1)
 
-	rows, err := r.querier.Query(ctx, query, args...)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to list task executions with cursor: %w", err)
 	}
@@ -580,3 +1041,126 @@ func (r *taskExecutionRepository) ListCursor(ctx context.Context, req CursorPagi
 
 	return executions, response, nil
 }
+
+// Search retrieves the task executions matching the filter using cursor-based pagination
+func (r *taskExecutionRepository) Search(ctx context.Context, filter ExecutionFilter, req CursorPaginationRequest) ([]*models.TaskExecution, CursorPaginationResponse, error) {
+	ValidatePaginationRequest(&req)
+
+	var cursor *ExecutionCursor
+
+	// Decode cursor if provided
+	if req.Cursor != nil {
+		decodedCursor, err := r.cursorEncoder.DecodeExecutionCursor(*req.Cursor)
+		if err != nil {
+			return nil, CursorPaginationResponse{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		cursor = &decodedCursor
+	}
+
+	orderClause := "ORDER BY created_at DESC, id DESC"
+	if req.SortOrder == "asc" {
+		orderClause = "ORDER BY created_at ASC, id ASC"
+	}
+
+	whereClause, args := BuildExecutionFilterWhere(filter, cursor, req.SortOrder)
+
+	query := fmt.Sprintf(`
+		SELECT id, task_id, status, return_code, execution_time_ms, memory_usage_bytes, started_at, completed_at, security_level, runtime, truncated, oom_killed, exit_signal, timeout_phase, error_category, revision, version, output_expired_at, created_at
+		FROM task_executions
+		%s
+		%s
+		LIMIT $%d
+	`, whereClause, orderClause, len(args)+1)
+
+	args = append(args, req.Limit+1)
+
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to search task executions: %w", err)
+	}
+	defer rows.Close()
+
+	executions, err := r.scanTaskExecutions(rows)
+	if err != nil {
+		return nil, CursorPaginationResponse{}, err
+	}
+
+	response := CursorPaginationResponse{
+		HasMore: len(executions) > req.Limit,
+	}
+
+	if response.HasMore {
+		executions = executions[:req.Limit]
+	}
+
+	if response.HasMore && len(executions) > 0 {
+		lastExecution := executions[len(executions)-1]
+		nextCursor := CreateExecutionCursor(lastExecution.ID, lastExecution.CreatedAt)
+		encoded, err := r.cursorEncoder.EncodeExecutionCursor(nextCursor)
+		if err != nil {
+			return nil, CursorPaginationResponse{}, fmt.Errorf("failed to encode next cursor: %w", err)
+		}
+		response.NextCursor = &encoded
+	}
+
+	return executions, response, nil
+}
+
+// Names of the output streams of an execution
+const (
+	outputStreamStdout = "stdout"
+	outputStreamStderr = "stderr"
+)
+
+// outputChunks holds the chunks of output to write, as parallel arrays
+// passed to unnest. Sequence numbers and offsets are relative to the first
+// chunk written to each stream.
+type outputChunks struct {
+	streams []string
+	seqs    []int32
+	offsets []int64
+	data    [][]byte
+}
+
+// add splits the output of a stream into chunks of at most
+// models.ExecutionOutputChunkBytes, without cutting characters
+func (c *outputChunks) add(stream string, output *string) {
+	if output == nil {
+		return
+	}
+
+	data := []byte(*output)
+	var seq int32
+	var offset int64
+	for len(data) > 0 {
+		size := min(len(data), models.ExecutionOutputChunkBytes)
+		if size < len(data) {
+			if trimmed := len(trimPartialRune(data[:size])); trimmed > 0 {
+				size = trimmed
+			}
+		}
+
+		c.streams = append(c.streams, stream)
+		c.seqs = append(c.seqs, seq)
+		c.offsets = append(c.offsets, offset)
+		c.data = append(c.data, data[:size])
+
+		data = data[size:]
+		seq++
+		offset += int64(size)
+	}
+}
+
+// trimPartialRune drops a character cut at the end of data
+func trimPartialRune(data []byte) []byte {
+	// A character is at most utf8.UTFMax bytes long
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
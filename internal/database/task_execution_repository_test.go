@@ -2,11 +2,17 @@ package database
 
 import (
 	"context"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -298,7 +304,206 @@ func TestTaskExecutionRepository_CountByStatus(t *testing.T) {
 	})
 }
 
+func TestTaskExecutionRepository_Retention(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	t.Run("delete by IDs", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Exec", mock.Anything, mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "DELETE FROM task_executions")
+		}), []interface{}{ids}).Return(pgconn.NewCommandTag("DELETE 2"), nil)
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		deleted, err := repo.DeleteByIDs(context.Background(), ids)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("expire output", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "output_expired_at IS NULL") && strings.Contains(query, "DELETE FROM execution_outputs")
+		}), []interface{}{ids}).Return(&MockRow{data: []interface{}{int64(1)}})
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		expired, err := repo.ExpireOutput(context.Background(), ids)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), expired)
+		mockQuerier.AssertExpectations(t)
+	})
+}
+
+func TestTaskExecutionRepository_Lease(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("stores the hash of the lease token", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Exec", mock.Anything, mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "SET lease_token_hash = $2")
+		}), []interface{}{id, leaseTokenHash("lease")}).Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		require.NoError(t, repo.SetLease(context.Background(), id, "lease"))
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("set lease of missing execution", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Exec", mock.Anything, mock.Anything, mock.Anything).Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		assert.ErrorIs(t, repo.SetLease(context.Background(), id, "lease"), ErrExecutionNotFound)
+	})
+
+	t.Run("checks the lease token against the stored hash", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, mock.Anything, []interface{}{id, leaseTokenHash("other")}).
+			Return(&MockRow{data: []interface{}{false}})
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		holds, err := repo.HoldsLease(context.Background(), id, "other")
+		require.NoError(t, err)
+		assert.False(t, holds)
+	})
+
+	t.Run("tokens hash differently", func(t *testing.T) {
+		assert.Len(t, leaseTokenHash("lease"), 32)
+		assert.NotEqual(t, leaseTokenHash("lease-a"), leaseTokenHash("lease-b"))
+	})
+}
+
+func TestOutputChunks(t *testing.T) {
+	t.Run("splits output into chunks with offsets", func(t *testing.T) {
+		stdout := strings.Repeat("a", models.ExecutionOutputChunkBytes+10)
+		stderr := "error"
+
+		var chunks outputChunks
+		chunks.add(outputStreamStdout, &stdout)
+		chunks.add(outputStreamStderr, &stderr)
+		chunks.add(outputStreamStderr, nil)
+
+		assert.Equal(t, []string{"stdout", "stdout", "stderr"}, chunks.streams)
+		assert.Equal(t, []int32{0, 1, 0}, chunks.seqs)
+		assert.Equal(t, []int64{0, models.ExecutionOutputChunkBytes, 0}, chunks.offsets)
+		assert.Len(t, chunks.data[0], models.ExecutionOutputChunkBytes)
+		assert.Len(t, chunks.data[1], 10)
+		assert.Equal(t, []byte("error"), chunks.data[2])
+	})
+
+	t.Run("doesn't cut characters", func(t *testing.T) {
+		stdout := strings.Repeat("a", models.ExecutionOutputChunkBytes-1) + "é"
+
+		var chunks outputChunks
+		chunks.add(outputStreamStdout, &stdout)
+
+		require.Len(t, chunks.data, 2)
+		assert.Len(t, chunks.data[0], models.ExecutionOutputChunkBytes-1)
+		assert.Equal(t, []byte("é"), chunks.data[1])
+		assert.Equal(t, int64(models.ExecutionOutputChunkBytes-1), chunks.offsets[1])
+	})
+
+	t.Run("empty output has no chunks", func(t *testing.T) {
+		empty := ""
+
+		var chunks outputChunks
+		chunks.add(outputStreamStdout, &empty)
+		assert.Empty(t, chunks.streams)
+	})
+}
+
+func TestTaskExecutionRepository_GetOutput(t *testing.T) {
+	id := uuid.New()
+	sizeQuery := mock.MatchedBy(func(query string) bool { return strings.Contains(query, "MAX(byte_offset + octet_length(data))") })
+	rangeQuery := mock.MatchedBy(func(query string) bool { return strings.Contains(query, "SELECT byte_offset, data") })
+
+	t.Run("reads a range across chunks", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, sizeQuery, []interface{}{id, "stdout"}).Return(&MockRow{data: []interface{}{int64(12)}})
+		mockQuerier.On("Query", mock.Anything, rangeQuery, []interface{}{id, "stdout", int64(2), int64(6)}).Return(&MockRows{rows: [][]interface{}{
+			{int64(0), []byte("hello ")},
+			{int64(6), []byte("world!")},
+		}}, nil)
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		output, err := repo.GetOutput(context.Background(), id, "stdout", 2, 6)
+		require.NoError(t, err)
+		assert.Equal(t, "llo wo", string(output.Data))
+		assert.Equal(t, int64(2), output.Offset)
+		assert.Equal(t, int64(12), output.Size)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("ends before a cut character", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, sizeQuery, []interface{}{id, "stderr"}).Return(&MockRow{data: []interface{}{int64(4)}})
+		mockQuerier.On("Query", mock.Anything, rangeQuery, []interface{}{id, "stderr", int64(0), int64(3)}).Return(&MockRows{rows: [][]interface{}{
+			{int64(0), []byte("ab€")[:4]},
+		}}, nil)
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		output, err := repo.GetOutput(context.Background(), id, "stderr", 0, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "ab", string(output.Data))
+	})
+
+	t.Run("reads nothing past the end", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("QueryRow", mock.Anything, sizeQuery, []interface{}{id, "stdout"}).Return(&MockRow{data: []interface{}{int64(12)}})
+
+		repo := &taskExecutionRepository{querier: mockQuerier}
+		output, err := repo.GetOutput(context.Background(), id, "stdout", 20, 6)
+		require.NoError(t, err)
+		assert.Empty(t, output.Data)
+		assert.Equal(t, int64(12), output.Offset)
+		mockQuerier.AssertNotCalled(t, "Query", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects invalid streams and ranges", func(t *testing.T) {
+		repo := &taskExecutionRepository{querier: new(MockQuerier)}
+		_, err := repo.GetOutput(context.Background(), id, "stdin", 0, 10)
+		assert.Error(t, err)
+		_, err = repo.GetOutput(context.Background(), id, "stdout", -1, 10)
+		assert.Error(t, err)
+		_, err = repo.GetOutput(context.Background(), id, "stdout", 0, 0)
+		assert.Error(t, err)
+	})
+}
+
 // Mock tests for business logic validation
+func TestTaskExecutionRepository_CreateStatement(t *testing.T) {
+	mockQuerier := new(MockQuerier)
+	mockQuerier.On("QueryRow", mock.Anything, mock.Anything, mock.Anything).
+		Return(&MockRow{data: []interface{}{1, time.Now()}})
+
+	repo := &taskExecutionRepository{querier: mockQuerier}
+	err := repo.Create(context.Background(), &models.TaskExecution{
+		TaskID:   uuid.New(),
+		Status:   models.ExecutionStatusPending,
+		Revision: intPtr(3),
+		Stdout:   stringPtr("output"),
+	})
+	require.NoError(t, err)
+
+	query := mockQuerier.Calls[0].Arguments.String(1)
+	args := mockQuerier.Calls[0].Arguments.Get(2).([]interface{})
+
+	// Every inserted column needs a value, and every placeholder an argument
+	insert := regexp.MustCompile(`(?s)INSERT INTO task_executions \((.*?)\)\s*VALUES \((.*?)\)\s*RETURNING`).FindStringSubmatch(query)
+	require.NotNil(t, insert, "execution insert not found in query")
+	assert.Equal(t, len(strings.Split(insert[1], ",")), len(strings.Split(insert[2], ",")),
+		"columns and values of the execution insert differ")
+	assert.Contains(t, insert[1], "revision")
+
+	placeholders := 0
+	for _, match := range regexp.MustCompile(`\$(\d+)`).FindAllStringSubmatch(query, -1) {
+		n, err := strconv.Atoi(match[1])
+		require.NoError(t, err)
+		placeholders = max(placeholders, n)
+	}
+	assert.Equal(t, placeholders, len(args), "placeholders and arguments differ")
+}
+
 func TestTaskExecutionRepository_CreateValidation(t *testing.T) {
 	repo := &taskExecutionRepository{querier: nil} // Mock repository
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,9 +21,11 @@ type Querier interface {
 	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
 }
 
-// taskRepository implements TaskRepository interface
+// taskRepository implements TaskRepository interface. Listings and counts
+// run on the read querier, which may be a read replica.
 type taskRepository struct {
 	querier       Querier
+	readQuerier   Querier
 	cursorEncoder *CursorEncoder
 }
 
@@ -30,10 +33,19 @@ type taskRepository struct {
 func NewTaskRepository(conn *Connection) TaskRepository {
 	return &taskRepository{
 		querier:       conn.Pool,
+		readQuerier:   conn.Reader(),
 		cursorEncoder: NewCursorEncoder(),
 	}
 }
 
+// reader returns the querier of read-only queries
+func (r *taskRepository) reader() Querier {
+	if r.readQuerier == nil {
+		return r.querier
+	}
+	return r.readQuerier
+}
+
 // NewTaskRepositoryWithTx creates a new task repository with transaction
 func NewTaskRepositoryWithTx(tx pgx.Tx) TaskRepository {
 	return &taskRepository{
@@ -52,10 +64,34 @@ func (r *taskRepository) Create(ctx context.Context, task *models.Task) error {
 		task.ID = models.NewID()
 	}
 
+	if task.SecurityLevel == "" {
+		task.SecurityLevel = models.SecurityLevelStandard
+	}
+
+	if task.NetworkMode == "" {
+		task.NetworkMode = models.NetworkModeNone
+	}
+
+	if task.Revision <= 0 {
+		task.Revision = 1
+	}
+
+	// The task's script is recorded as its first revision, and its labels are
+	// saved, in the same statement
+	labelKeys, labelValues := labelArrays(task.Labels)
 	query := `
-		INSERT INTO tasks (id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
-		RETURNING created_at, updated_at
+		WITH created AS (
+			INSERT INTO tasks (id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE($11::text[], '{}'), $12, $13, $14, $15, COALESCE($16::text[], '{}'), $17, $18, NOW(), NOW())
+			RETURNING id, script_content, script_type, revision, version, created_at, updated_at
+		), revised AS (
+			INSERT INTO task_revisions (task_id, revision, script_content, script_type, created_at)
+			SELECT id, revision, script_content, script_type, created_at FROM created
+		), labeled AS (
+			INSERT INTO task_labels (task_id, key, value)
+			SELECT created.id, l.key, l.value FROM created, unnest($19::text[], $20::text[]) AS l(key, value)
+		)
+		SELECT version, created_at, updated_at FROM created
 	`
 
 	err := r.querier.QueryRow(ctx, query,
@@ -69,13 +105,26 @@ func (r *taskRepository) Create(ctx context.Context, task *models.Task) error {
 		task.Priority,
 		task.TimeoutSeconds,
 		task.Metadata,
-	).Scan(&task.CreatedAt, &task.UpdatedAt)
+		task.RequiredCapabilities,
+		task.SecurityLevel,
+		task.Image,
+		task.ImageDigest,
+		task.NetworkMode,
+		task.NetworkAllowlist,
+		task.Revision,
+		task.ExternalKey,
+		labelKeys,
+		labelValues,
+	).Scan(&task.Version, &task.CreatedAt, &task.UpdatedAt)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			switch pgErr.Code {
 			case "23505": // unique_violation
+				if pgErr.ConstraintName == "idx_tasks_user_external_key" {
+					return ErrTaskExternalKeyExists
+				}
 				return fmt.Errorf("task with ID %s already exists", task.ID)
 			case "23503": // foreign_key_violation
 				if strings.Contains(pgErr.Detail, "user_id") {
@@ -94,9 +143,9 @@ func (r *taskRepository) Create(ctx context.Context, task *models.Task) error {
 // GetByID retrieves a task by ID
 func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
 	query := `
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var task models.Task
@@ -113,6 +162,17 @@ func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tas
 		&task.Metadata,
 		&task.CreatedAt,
 		&task.UpdatedAt,
+		&task.RequiredCapabilities,
+		&task.SecurityLevel,
+		&task.Image,
+		&task.ImageDigest,
+		&task.NetworkMode,
+		&task.NetworkAllowlist,
+		&task.Revision,
+		&task.ExternalKey,
+		&task.Version,
+		&task.DeletedAt,
+		&task.Labels,
 	)
 
 	if err != nil {
@@ -135,14 +195,14 @@ func (r *taskRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limi
 	}
 
 	query := `
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY priority DESC, created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, query, userID, limit, offset)
+	rows, err := r.reader().Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks by user ID: %w", err)
 	}
@@ -151,6 +211,24 @@ func (r *taskRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limi
 	return r.scanTasks(rows)
 }
 
+// GetAllByUserID retrieves every task of a user, ordered by creation time
+func (r *taskRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Task, error) {
+	query := `
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
+		FROM tasks
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.querier.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tasks by user ID: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTasks(rows)
+}
+
 // GetByStatus retrieves tasks by status with pagination
 func (r *taskRepository) GetByStatus(ctx context.Context, status models.TaskStatus, limit, offset int) ([]*models.Task, error) {
 	if limit <= 0 {
@@ -161,14 +239,14 @@ func (r *taskRepository) GetByStatus(ctx context.Context, status models.TaskStat
 	}
 
 	query := `
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
-		WHERE status = $1
+		WHERE status = $1 AND deleted_at IS NULL
 		ORDER BY priority DESC, created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, query, status, limit, offset)
+	rows, err := r.reader().Query(ctx, query, status, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks by status: %w", err)
 	}
@@ -183,11 +261,33 @@ func (r *taskRepository) Update(ctx context.Context, task *models.Task) error {
 		return fmt.Errorf("task cannot be nil")
 	}
 
+	// Changing the script content or type starts a new revision, which is
+	// recorded in the same statement. The revision number is derived from the
+	// stored row, so concurrent updates can't both claim the same number. The
+	// task's labels are replaced by its current ones. The update only applies
+	// to the version the task was read at.
+	labelKeys, labelValues := labelArrays(task.Labels)
 	query := `
-		UPDATE tasks
-		SET name = $2, description = $3, script_content = $4, script_type = $5, status = $6, priority = $7, timeout_seconds = $8, metadata = $9, updated_at = NOW()
-		WHERE id = $1
-		RETURNING updated_at
+		WITH updated AS (
+			UPDATE tasks
+			SET name = $2, description = $3, script_content = $4, script_type = $5, status = $6, priority = $7, timeout_seconds = $8, metadata = $9, required_capabilities = COALESCE($10::text[], '{}'), security_level = COALESCE(NULLIF($11, ''), security_level), image = $12, image_digest = $13, network_mode = COALESCE(NULLIF($14, ''), network_mode), network_allowlist = COALESCE($15::text[], '{}'), external_key = $16,
+				revision = CASE WHEN script_content IS DISTINCT FROM $4 OR script_type IS DISTINCT FROM $5 THEN revision + 1 ELSE revision END,
+				version = version + 1, updated_at = NOW()
+			WHERE id = $1 AND version = $19 AND deleted_at IS NULL
+			RETURNING id, script_content, script_type, revision, version, updated_at
+		), revised AS (
+			INSERT INTO task_revisions (task_id, revision, script_content, script_type, created_at)
+			SELECT id, revision, script_content, script_type, updated_at FROM updated
+			ON CONFLICT (task_id, revision) DO NOTHING
+		), unlabeled AS (
+			DELETE FROM task_labels
+			WHERE task_id IN (SELECT id FROM updated) AND key <> ALL($17::text[])
+		), labeled AS (
+			INSERT INTO task_labels (task_id, key, value)
+			SELECT updated.id, l.key, l.value FROM updated, unnest($17::text[], $18::text[]) AS l(key, value)
+			ON CONFLICT (task_id, key) DO UPDATE SET value = EXCLUDED.value
+		)
+		SELECT revision, version, updated_at FROM updated
 	`
 
 	err := r.querier.QueryRow(ctx, query,
@@ -200,16 +300,30 @@ func (r *taskRepository) Update(ctx context.Context, task *models.Task) error {
 		task.Priority,
 		task.TimeoutSeconds,
 		task.Metadata,
-	).Scan(&task.UpdatedAt)
+		task.RequiredCapabilities,
+		string(task.SecurityLevel),
+		task.Image,
+		task.ImageDigest,
+		string(task.NetworkMode),
+		task.NetworkAllowlist,
+		task.ExternalKey,
+		labelKeys,
+		labelValues,
+		task.Version,
+	).Scan(&task.Revision, &task.Version, &task.UpdatedAt)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrTaskNotFound
+			return r.versionConflict(ctx, task.ID)
 		}
 
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			switch pgErr.Code {
+			case "23505": // unique_violation
+				if pgErr.ConstraintName == "idx_tasks_user_external_key" {
+					return ErrTaskExternalKeyExists
+				}
 			case "23514": // check_violation
 				return fmt.Errorf("task validation failed: %s", pgErr.Detail)
 			}
@@ -224,8 +338,8 @@ func (r *taskRepository) Update(ctx context.Context, task *models.Task) error {
 func (r *taskRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.TaskStatus) error {
 	query := `
 		UPDATE tasks
-		SET status = $2, updated_at = NOW()
-		WHERE id = $1
+		SET status = $2, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	result, err := r.querier.Exec(ctx, query, id, status)
@@ -244,15 +358,83 @@ func (r *taskRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 	return nil
 }
 
-// Delete deletes a task
-func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM tasks WHERE id = $1`
+// versionConflict tells why an update of the task matched no row: the task
+// is gone, or it was written since it was read
+func (r *taskRepository) versionConflict(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := r.querier.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check task: %w", err)
+	}
+	if !exists {
+		return ErrTaskNotFound
+	}
+	return ErrTaskVersionConflict
+}
 
-	result, err := r.querier.Exec(ctx, query, id)
+// Delete moves a task to the trash, provided it still has the version the
+// caller read. Its executions are kept until it is purged.
+func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	query := `
+		UPDATE tasks
+		SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.querier.Exec(ctx, query, id, version)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return r.versionConflict(ctx, id)
+	}
+
+	return nil
+}
+
+// GetDeletedByID retrieves a task in the trash by ID
+func (r *taskRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	query := `
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
+		FROM tasks
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	rows, err := r.querier.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted task by ID: %w", err)
+	}
+	defer rows.Close()
+
+	tasks, err := r.scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	return tasks[0], nil
+}
+
+// Restore moves a task out of the trash. It fails with
+// ErrTaskExternalKeyExists when another task took its external key meanwhile.
+func (r *taskRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE tasks
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	result, err := r.querier.Exec(ctx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_tasks_user_external_key" {
+			return ErrTaskExternalKeyExists
+		}
+		return fmt.Errorf("failed to restore task: %w", err)
+	}
+
 	if result.RowsAffected() == 0 {
 		return ErrTaskNotFound
 	}
@@ -260,6 +442,94 @@ func (r *taskRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// Purge deletes a task in the trash, and with it its executions, revisions
+// and labels. The IDs of the executions with truncated output are returned
+// so their spilled output can be removed.
+func (r *taskRepository) Purge(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		WITH purged AS (
+			DELETE FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL
+			RETURNING id
+		)
+		SELECT purged.id, e.id
+		FROM purged
+		LEFT JOIN task_executions e ON e.task_id = purged.id AND e.truncated
+	`
+
+	purged, executionIDs, err := r.queryPurged(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge task: %w", err)
+	}
+
+	if purged == 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	return executionIDs, nil
+}
+
+// PurgeDeleted purges a batch of the tasks moved to the trash before the
+// given time, oldest first
+func (r *taskRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, []uuid.UUID, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		WITH purged AS (
+			DELETE FROM tasks
+			WHERE id IN (
+				SELECT id FROM tasks
+				WHERE deleted_at IS NOT NULL AND deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2
+			)
+			RETURNING id
+		)
+		SELECT purged.id, e.id
+		FROM purged
+		LEFT JOIN task_executions e ON e.task_id = purged.id AND e.truncated
+	`
+
+	purged, executionIDs, err := r.queryPurged(ctx, query, before, limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to purge deleted tasks: %w", err)
+	}
+
+	return purged, executionIDs, nil
+}
+
+// queryPurged runs a purge query returning a row per purged task and
+// truncated execution, with a NULL execution for tasks without any, and
+// returns how many tasks it purged and the executions' IDs. The executions
+// are read from the statement's snapshot, before the cascade removed them.
+func (r *taskRepository) queryPurged(ctx context.Context, query string, args ...any) (int64, []uuid.UUID, error) {
+	rows, err := r.querier.Query(ctx, query, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	tasks := make(map[uuid.UUID]struct{})
+	var executionIDs []uuid.UUID
+	for rows.Next() {
+		var taskID uuid.UUID
+		var executionID *uuid.UUID
+		if err := rows.Scan(&taskID, &executionID); err != nil {
+			return 0, nil, err
+		}
+		tasks[taskID] = struct{}{}
+		if executionID != nil {
+			executionIDs = append(executionIDs, *executionID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	return int64(len(tasks)), executionIDs, nil
+}
+
 // List retrieves tasks with pagination
 func (r *taskRepository) List(ctx context.Context, limit, offset int) ([]*models.Task, error) {
 	if limit <= 0 {
@@ -270,13 +540,14 @@ func (r *taskRepository) List(ctx context.Context, limit, offset int) ([]*models
 	}
 
 	query := `
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
+		WHERE deleted_at IS NULL
 		ORDER BY priority DESC, created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.querier.Query(ctx, query, limit, offset)
+	rows, err := r.reader().Query(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
@@ -287,10 +558,10 @@ func (r *taskRepository) List(ctx context.Context, limit, offset int) ([]*models
 
 // Count returns the total number of tasks
 func (r *taskRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM tasks`
+	query := `SELECT COUNT(*) FROM tasks WHERE deleted_at IS NULL`
 
 	var count int64
-	err := r.querier.QueryRow(ctx, query).Scan(&count)
+	err := r.reader().QueryRow(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count tasks: %w", err)
 	}
@@ -300,10 +571,10 @@ func (r *taskRepository) Count(ctx context.Context) (int64, error) {
 
 // CountByUserID returns the total number of tasks for a user
 func (r *taskRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
-	query := `SELECT COUNT(*) FROM tasks WHERE user_id = $1`
+	query := `SELECT COUNT(*) FROM tasks WHERE user_id = $1 AND deleted_at IS NULL`
 
 	var count int64
-	err := r.querier.QueryRow(ctx, query, userID).Scan(&count)
+	err := r.reader().QueryRow(ctx, query, userID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count tasks by user ID: %w", err)
 	}
@@ -313,10 +584,10 @@ func (r *taskRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (i
 
 // CountByStatus returns the total number of tasks with a specific status
 func (r *taskRepository) CountByStatus(ctx context.Context, status models.TaskStatus) (int64, error) {
-	query := `SELECT COUNT(*) FROM tasks WHERE status = $1`
+	query := `SELECT COUNT(*) FROM tasks WHERE status = $1 AND deleted_at IS NULL`
 
 	var count int64
-	err := r.querier.QueryRow(ctx, query, status).Scan(&count)
+	err := r.reader().QueryRow(ctx, query, status).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count tasks by status: %w", err)
 	}
@@ -324,6 +595,72 @@ func (r *taskRepository) CountByStatus(ctx context.Context, status models.TaskSt
 	return count, nil
 }
 
+// GetImageInventory returns the custom images used by a user's tasks, grouped by digest
+func (r *taskRepository) GetImageInventory(ctx context.Context, userID uuid.UUID) ([]models.ImageUsage, error) {
+	query := `
+		SELECT image, image_digest, array_agg(id ORDER BY created_at)
+		FROM tasks
+		WHERE user_id = $1 AND deleted_at IS NULL AND image IS NOT NULL AND image_digest IS NOT NULL
+		GROUP BY image, image_digest
+		ORDER BY image, image_digest
+	`
+
+	rows, err := r.reader().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image inventory: %w", err)
+	}
+	defer rows.Close()
+
+	images := []models.ImageUsage{}
+	for rows.Next() {
+		var usage models.ImageUsage
+		if err := rows.Scan(&usage.Image, &usage.Digest, &usage.TaskIDs); err != nil {
+			return nil, fmt.Errorf("failed to scan image usage: %w", err)
+		}
+		images = append(images, usage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating image usage rows: %w", err)
+	}
+
+	return images, nil
+}
+
+// GetLabelInventory returns the labels on a user's tasks, with the number of
+// tasks carrying each key and value
+func (r *taskRepository) GetLabelInventory(ctx context.Context, userID uuid.UUID) ([]models.LabelUsage, error) {
+	query := `
+		SELECT l.key, l.value, COUNT(*)
+		FROM task_labels l
+		JOIN tasks t ON t.id = l.task_id
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL
+		GROUP BY l.key, l.value
+		ORDER BY l.key, l.value
+	`
+
+	rows, err := r.reader().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label inventory: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []models.LabelUsage{}
+	for rows.Next() {
+		var usage models.LabelUsage
+		if err := rows.Scan(&usage.Key, &usage.Value, &usage.TaskCount); err != nil {
+			return nil, fmt.Errorf("failed to scan label usage: %w", err)
+		}
+		labels = append(labels, usage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating label usage rows: %w", err)
+	}
+
+	return labels, nil
+}
+
 // SearchByMetadata searches tasks by metadata using JSON operators
 func (r *taskRepository) SearchByMetadata(ctx context.Context, query string, limit, offset int) ([]*models.Task, error) {
 	if limit <= 0 {
@@ -334,14 +671,14 @@ func (r *taskRepository) SearchByMetadata(ctx context.Context, query string, lim
 	}
 
 	sqlQuery := `
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
-		WHERE metadata @> $1
+		WHERE metadata @> $1 AND deleted_at IS NULL
 		ORDER BY priority DESC, created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, sqlQuery, query, limit, offset)
+	rows, err := r.reader().Query(ctx, sqlQuery, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search tasks by metadata: %w", err)
 	}
@@ -368,6 +705,17 @@ func (r *taskRepository) scanTasks(rows pgx.Rows) ([]*models.Task, error) {
 			&task.Metadata,
 			&task.CreatedAt,
 			&task.UpdatedAt,
+			&task.RequiredCapabilities,
+			&task.SecurityLevel,
+			&task.Image,
+			&task.ImageDigest,
+			&task.NetworkMode,
+			&task.NetworkAllowlist,
+			&task.Revision,
+			&task.ExternalKey,
+			&task.Version,
+			&task.DeletedAt,
+			&task.Labels,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan task row: %w", err)
@@ -404,7 +752,7 @@ func (r *taskRepository) GetByUserIDCursor(ctx context.Context, userID uuid.UUID
 	whereClause, args := BuildTaskCursorWhere(cursor, req.SortOrder, req.SortField, &userID, nil)
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
 		%s
 		%s
@@ -413,7 +761,7 @@ func (r *taskRepository) GetByUserIDCursor(ctx context.Context, userID uuid.UUID
 
 	args = append(args, req.Limit+1) // Fetch one extra to check if there are more results
 
-	rows, err := r.querier.Query(ctx, query, args...)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to get tasks by user ID with cursor: %w", err)
 	}
@@ -437,7 +785,7 @@ func (r *taskRepository) GetByUserIDCursor(ctx context.Context, userID uuid.UUID
 	// Generate next cursor if there are more results
 	if response.HasMore && len(tasks) > 0 {
 		lastTask := tasks[len(tasks)-1]
-		nextCursor := CreateTaskCursor(lastTask.ID, lastTask.CreatedAt, &lastTask.Priority)
+		nextCursor := CreateTaskCursorFromTask(lastTask)
 		encoded, err := r.cursorEncoder.EncodeTaskCursor(nextCursor)
 		if err != nil {
 			return nil, CursorPaginationResponse{}, fmt.Errorf("failed to encode next cursor: %w", err)
@@ -471,7 +819,7 @@ func (r *taskRepository) GetByStatusCursor(ctx context.Context, status models.Ta
 	whereClause, args := BuildTaskCursorWhere(cursor, req.SortOrder, req.SortField, nil, &statusStr)
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
 		%s
 		%s
@@ -480,7 +828,7 @@ func (r *taskRepository) GetByStatusCursor(ctx context.Context, status models.Ta
 
 	args = append(args, req.Limit+1)
 
-	rows, err := r.querier.Query(ctx, query, args...)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to get tasks by status with cursor: %w", err)
 	}
@@ -502,7 +850,7 @@ func (r *taskRepository) GetByStatusCursor(ctx context.Context, status models.Ta
 
 	if response.HasMore && len(tasks) > 0 {
 		lastTask := tasks[len(tasks)-1]
-		nextCursor := CreateTaskCursor(lastTask.ID, lastTask.CreatedAt, &lastTask.Priority)
+		nextCursor := CreateTaskCursorFromTask(lastTask)
 		encoded, err := r.cursorEncoder.EncodeTaskCursor(nextCursor)
 		if err != nil {
 			return nil, CursorPaginationResponse{}, fmt.Errorf("failed to encode next cursor: %w", err)
@@ -535,7 +883,7 @@ func (r *taskRepository) ListCursor(ctx context.Context, req CursorPaginationReq
 	whereClause, args := BuildTaskCursorWhere(cursor, req.SortOrder, req.SortField, nil, nil)
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
 		FROM tasks
 		%s
 		%s
@@ -544,7 +892,7 @@ func (r *taskRepository) ListCursor(ctx context.Context, req CursorPaginationReq
 
 	args = append(args, req.Limit+1)
 
-	rows, err := r.querier.Query(ctx, query, args...)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to list tasks with cursor: %w", err)
 	}
@@ -566,7 +914,70 @@ func (r *taskRepository) ListCursor(ctx context.Context, req CursorPaginationReq
 
 	if response.HasMore && len(tasks) > 0 {
 		lastTask := tasks[len(tasks)-1]
-		nextCursor := CreateTaskCursor(lastTask.ID, lastTask.CreatedAt, &lastTask.Priority)
+		nextCursor := CreateTaskCursorFromTask(lastTask)
+		encoded, err := r.cursorEncoder.EncodeTaskCursor(nextCursor)
+		if err != nil {
+			return nil, CursorPaginationResponse{}, fmt.Errorf("failed to encode next cursor: %w", err)
+		}
+		response.NextCursor = &encoded
+	}
+
+	return tasks, response, nil
+}
+
+// Search retrieves the tasks matching the filter using cursor-based pagination
+func (r *taskRepository) Search(ctx context.Context, filter TaskFilter, req CursorPaginationRequest) ([]*models.Task, CursorPaginationResponse, error) {
+	ValidatePaginationRequest(&req)
+
+	var cursor *TaskCursor
+
+	// Decode cursor if provided
+	if req.Cursor != nil {
+		decodedCursor, err := r.cursorEncoder.DecodeTaskCursor(*req.Cursor)
+		if err != nil {
+			return nil, CursorPaginationResponse{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		cursor = &decodedCursor
+	}
+
+	orderClause := buildOrderByClause(req.SortField, req.SortOrder)
+
+	whereClause, args, err := BuildTaskFilterWhere(filter, cursor, req.SortOrder, req.SortField)
+	if err != nil {
+		return nil, CursorPaginationResponse{}, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, description, script_content, script_type, status, priority, timeout_seconds, metadata, created_at, updated_at, required_capabilities, security_level, image, image_digest, network_mode, network_allowlist, revision, external_key, version, deleted_at, (SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = tasks.id) AS labels
+		FROM tasks
+		%s
+		%s
+		LIMIT $%d
+	`, whereClause, orderClause, len(args)+1)
+
+	args = append(args, req.Limit+1)
+
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, CursorPaginationResponse{}, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks, err := r.scanTasks(rows)
+	if err != nil {
+		return nil, CursorPaginationResponse{}, err
+	}
+
+	response := CursorPaginationResponse{
+		HasMore: len(tasks) > req.Limit,
+	}
+
+	if response.HasMore {
+		tasks = tasks[:req.Limit]
+	}
+
+	if response.HasMore && len(tasks) > 0 {
+		nextCursor := CreateTaskCursorFromTask(tasks[len(tasks)-1])
 		encoded, err := r.cursorEncoder.EncodeTaskCursor(nextCursor)
 		if err != nil {
 			return nil, CursorPaginationResponse{}, fmt.Errorf("failed to encode next cursor: %w", err)
@@ -590,17 +1001,20 @@ func (r *taskRepository) GetTasksWithExecutionCount(ctx context.Context, userID
 		SELECT 
 			t.id, t.user_id, t.name, t.description, t.script_content, t.script_type, 
 			t.status, t.priority, t.timeout_seconds, t.metadata, t.created_at, t.updated_at,
+			t.required_capabilities, t.security_level, t.image, t.image_digest, t.network_mode, t.network_allowlist, t.revision, t.external_key, t.version, t.deleted_at,
+			(SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = t.id) AS labels,
 			COALESCE(COUNT(e.id), 0) as execution_count
 		FROM tasks t
 		LEFT JOIN task_executions e ON t.id = e.task_id
-		WHERE t.user_id = $1
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL
 		GROUP BY t.id, t.user_id, t.name, t.description, t.script_content, t.script_type, 
-				 t.status, t.priority, t.timeout_seconds, t.metadata, t.created_at, t.updated_at
+				 t.status, t.priority, t.timeout_seconds, t.metadata, t.created_at, t.updated_at,
+				 t.required_capabilities, t.security_level, t.image, t.image_digest, t.network_mode, t.network_allowlist, t.revision, t.external_key, t.version, t.deleted_at
 		ORDER BY t.priority DESC, t.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, query, userID, limit, offset)
+	rows, err := r.reader().Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks with execution count: %w", err)
 	}
@@ -623,6 +1037,17 @@ func (r *taskRepository) GetTasksWithExecutionCount(ctx context.Context, userID
 			&task.Metadata,
 			&task.CreatedAt,
 			&task.UpdatedAt,
+			&task.RequiredCapabilities,
+			&task.SecurityLevel,
+			&task.Image,
+			&task.ImageDigest,
+			&task.NetworkMode,
+			&task.NetworkAllowlist,
+			&task.Revision,
+			&task.ExternalKey,
+			&task.Version,
+			&task.DeletedAt,
+			&task.Labels,
 			&executionCount,
 		)
 		if err != nil {
@@ -658,6 +1083,8 @@ func (r *taskRepository) GetTasksWithLatestExecution(ctx context.Context, userID
 		SELECT 
 			t.id, t.user_id, t.name, t.description, t.script_content, t.script_type, 
 			t.status, t.priority, t.timeout_seconds, t.metadata, t.created_at, t.updated_at,
+			t.required_capabilities, t.security_level, t.image, t.image_digest, t.network_mode, t.network_allowlist, t.revision, t.external_key, t.version, t.deleted_at,
+			(SELECT jsonb_object_agg(l.key, l.value) FROM task_labels l WHERE l.task_id = t.id) AS labels,
 			e.id as latest_execution_id, e.status as latest_execution_status, 
 			e.created_at as latest_execution_created_at
 		FROM tasks t
@@ -668,12 +1095,12 @@ func (r *taskRepository) GetTasksWithLatestExecution(ctx context.Context, userID
 			ORDER BY created_at DESC
 			LIMIT 1
 		) e ON true
-		WHERE t.user_id = $1
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL
 		ORDER BY t.priority DESC, t.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.querier.Query(ctx, query, userID, limit, offset)
+	rows, err := r.reader().Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks with latest execution: %w", err)
 	}
@@ -699,6 +1126,17 @@ func (r *taskRepository) GetTasksWithLatestExecution(ctx context.Context, userID
 			&task.Metadata,
 			&task.CreatedAt,
 			&task.UpdatedAt,
+			&task.RequiredCapabilities,
+			&task.SecurityLevel,
+			&task.Image,
+			&task.ImageDigest,
+			&task.NetworkMode,
+			&task.NetworkAllowlist,
+			&task.Revision,
+			&task.ExternalKey,
+			&task.Version,
+			&task.DeletedAt,
+			&task.Labels,
 			&latestExecutionID,
 			&latestExecutionStatus,
 			&latestExecutionCreatedAt,
@@ -730,6 +1168,22 @@ func (r *taskRepository) GetTasksWithLatestExecution(ctx context.Context, userID
 	return tasks, nil
 }
 
+// labelArrays splits labels into parallel key and value arrays, ordered by
+// key. The arrays are never nil, so an unlabeled task saves as no labels.
+func labelArrays(labels map[string]string) (keys, values []string) {
+	keys = make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values = make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = labels[key]
+	}
+	return keys, values
+}
+
 // buildOrderByClause creates the ORDER BY clause based on sort field and order
 func buildOrderByClause(sortField string, sortOrder string) string {
 	direction := "DESC"
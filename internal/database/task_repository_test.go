@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -468,27 +470,37 @@ func TestTaskRepository_Delete(t *testing.T) {
 		name      string
 		taskID    uuid.UUID
 		mockSetup func(*MockQuerier)
-		wantError bool
-		errorMsg  string
+		wantError error
 	}{
 		{
-			name:   "successful delete",
+			name:   "moves the task to the trash",
 			taskID: uuid.New(),
 			mockSetup: func(mq *MockQuerier) {
-				cmdTag := pgconn.NewCommandTag("DELETE 1")
-				mq.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(cmdTag, nil)
+				cmdTag := pgconn.NewCommandTag("UPDATE 1")
+				mq.On("Exec", mock.Anything, mock.MatchedBy(func(query string) bool {
+					return strings.Contains(query, "SET deleted_at = NOW()") && strings.Contains(query, "version = $2")
+				}), mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 2 && args[1] == 2
+				})).Return(cmdTag, nil)
 			},
-			wantError: false,
 		},
 		{
 			name:   "task not found",
 			taskID: uuid.New(),
 			mockSetup: func(mq *MockQuerier) {
-				cmdTag := pgconn.NewCommandTag("DELETE 0")
-				mq.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(cmdTag, nil)
+				mq.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{data: []interface{}{false}})
 			},
-			wantError: true,
-			errorMsg:  "not found",
+			wantError: ErrTaskNotFound,
+		},
+		{
+			name:   "version conflict",
+			taskID: uuid.New(),
+			mockSetup: func(mq *MockQuerier) {
+				mq.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{data: []interface{}{true}})
+			},
+			wantError: ErrTaskVersionConflict,
 		},
 	}
 
@@ -502,13 +514,10 @@ func TestTaskRepository_Delete(t *testing.T) {
 
 			tt.mockSetup(mockQuerier)
 
-			err := repo.Delete(context.Background(), tt.taskID)
+			err := repo.Delete(context.Background(), tt.taskID, 2)
 
-			if tt.wantError {
-				assert.Error(t, err)
-				if tt.errorMsg != "" {
-					assert.Contains(t, err.Error(), tt.errorMsg)
-				}
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
 			} else {
 				assert.NoError(t, err)
 			}
@@ -518,6 +527,85 @@ func TestTaskRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_Trash(t *testing.T) {
+	taskID := uuid.New()
+
+	newRepo := func(mq *MockQuerier) *taskRepository {
+		return &taskRepository{
+			querier:       mq,
+			cursorEncoder: NewCursorEncoder(),
+		}
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Exec", mock.Anything, mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "SET deleted_at = NULL")
+		}), []interface{}{taskID}).Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+		assert.NoError(t, newRepo(mockQuerier).Restore(context.Background(), taskID))
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("restore of a task not in the trash", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+		assert.ErrorIs(t, newRepo(mockQuerier).Restore(context.Background(), taskID), ErrTaskNotFound)
+	})
+
+	t.Run("restore with a taken external key", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "idx_tasks_user_external_key"}
+		mockQuerier.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil, pgErr)
+
+		assert.ErrorIs(t, newRepo(mockQuerier).Restore(context.Background(), taskID), ErrTaskExternalKeyExists)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		executionID := uuid.New()
+		mockQuerier := new(MockQuerier)
+		mockRows := &MockRows{rows: [][]interface{}{
+			{taskID, &executionID},
+			{taskID, (*uuid.UUID)(nil)},
+		}}
+		mockQuerier.On("Query", mock.Anything, mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "DELETE FROM tasks") && strings.Contains(query, "deleted_at IS NOT NULL") && strings.Contains(query, "e.truncated")
+		}), []interface{}{taskID}).Return(mockRows, nil)
+
+		executionIDs, err := newRepo(mockQuerier).Purge(context.Background(), taskID)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{executionID}, executionIDs)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("purge of a task not in the trash", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockQuerier.On("Query", mock.Anything, mock.AnythingOfType("string"), []interface{}{taskID}).Return(&MockRows{}, nil)
+
+		_, err := newRepo(mockQuerier).Purge(context.Background(), taskID)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+	})
+
+	t.Run("purge deleted", func(t *testing.T) {
+		otherTaskID := uuid.New()
+		executionID := uuid.New()
+		mockQuerier := new(MockQuerier)
+		before := time.Now()
+		mockRows := &MockRows{rows: [][]interface{}{
+			{taskID, &executionID},
+			{otherTaskID, (*uuid.UUID)(nil)},
+		}}
+		mockQuerier.On("Query", mock.Anything, mock.AnythingOfType("string"), []interface{}{before, 50}).Return(mockRows, nil)
+
+		purged, executionIDs, err := newRepo(mockQuerier).PurgeDeleted(context.Background(), before, 50)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+		assert.Equal(t, []uuid.UUID{executionID}, executionIDs)
+		mockQuerier.AssertExpectations(t)
+	})
+}
+
 func TestTaskRepository_Count(t *testing.T) {
 	t.Run("successful count", func(t *testing.T) {
 		mockQuerier := new(MockQuerier)
@@ -643,6 +731,10 @@ func (m *MockRows) Scan(dest ...interface{}) error {
 				if val, ok := row[i].(uuid.UUID); ok {
 					*v = val
 				}
+			case **uuid.UUID:
+				if val, ok := row[i].(*uuid.UUID); ok {
+					*v = val
+				}
 			case *string:
 				if val, ok := row[i].(string); ok {
 					*v = val
@@ -651,6 +743,14 @@ func (m *MockRows) Scan(dest ...interface{}) error {
 				if val, ok := row[i].(int); ok {
 					*v = val
 				}
+			case *int64:
+				if val, ok := row[i].(int64); ok {
+					*v = val
+				}
+			case *[]byte:
+				if val, ok := row[i].([]byte); ok {
+					*v = val
+				}
 			case *time.Time:
 				if val, ok := row[i].(time.Time); ok {
 					*v = val
@@ -717,6 +817,10 @@ func (m *MockRow) Scan(dest ...interface{}) error {
 				if val, ok := m.data[i].(int64); ok {
 					*v = val
 				}
+			case *bool:
+				if val, ok := m.data[i].(bool); ok {
+					*v = val
+				}
 			case *time.Time:
 				if val, ok := m.data[i].(time.Time); ok {
 					*v = val
@@ -806,6 +910,28 @@ func TestTaskRepository_CreateErrorScenarios(t *testing.T) {
 			},
 			wantError: "already exists",
 		},
+		{
+			name: "unique constraint violation - external key already in use",
+			task: &models.Task{
+				UserID:         uuid.New(),
+				Name:           "Test Task",
+				ScriptContent:  "print('hello')",
+				ScriptType:     models.ScriptTypePython,
+				Status:         models.TaskStatusPending,
+				Priority:       1,
+				TimeoutSeconds: 30,
+				ExternalKey:    stringPtr("reports/nightly"),
+			},
+			mockSetup: func(mq *MockQuerier) {
+				pgErr := &pgconn.PgError{
+					Code:           "23505", // unique_violation
+					ConstraintName: "idx_tasks_user_external_key",
+				}
+				row := &MockRow{err: pgErr}
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+			},
+			wantError: ErrTaskExternalKeyExists.Error(),
+		},
 		{
 			name: "foreign key constraint violation - user does not exist",
 			task: &models.Task{
@@ -990,11 +1116,25 @@ func TestTaskRepository_UpdateErrorScenarios(t *testing.T) {
 				Name:      "Updated Task",
 			},
 			mockSetup: func(mq *MockQuerier) {
-				row := &MockRow{err: pgx.ErrNoRows}
-				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{err: pgx.ErrNoRows}).Once()
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{data: []interface{}{false}}).Once()
 			},
 			wantError: "not found",
 		},
+		{
+			name: "version conflict",
+			task: &models.Task{
+				BaseModel: models.BaseModel{ID: uuid.New()},
+				UserID:    uuid.New(),
+				Name:      "Updated Task",
+				Version:   2,
+			},
+			mockSetup: func(mq *MockQuerier) {
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{err: pgx.ErrNoRows}).Once()
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{data: []interface{}{true}}).Once()
+			},
+			wantError: ErrTaskVersionConflict.Error(),
+		},
 		{
 			name: "check constraint violation",
 			task: &models.Task{
@@ -1119,8 +1259,9 @@ func TestTaskRepository_DeleteErrorScenarios(t *testing.T) {
 			name:   "task not found - no rows affected",
 			taskID: uuid.New(),
 			mockSetup: func(mq *MockQuerier) {
-				cmdTag := pgconn.NewCommandTag("DELETE 0")
+				cmdTag := pgconn.NewCommandTag("UPDATE 0")
 				mq.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(cmdTag, nil)
+				mq.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(&MockRow{data: []interface{}{false}})
 			},
 			wantError: "not found",
 		},
@@ -1153,7 +1294,7 @@ func TestTaskRepository_DeleteErrorScenarios(t *testing.T) {
 
 			tt.mockSetup(mockQuerier)
 
-			err := repo.Delete(context.Background(), tt.taskID)
+			err := repo.Delete(context.Background(), tt.taskID, 1)
 
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), tt.wantError)
@@ -1406,6 +1547,39 @@ func TestTaskRepository_CursorPaginationErrorScenarios(t *testing.T) {
 			},
 			wantError: "failed to get tasks by status with cursor",
 		},
+		{
+			name: "Search - invalid cursor",
+			testFunc: func(repo *taskRepository) error {
+				req := CursorPaginationRequest{
+					Limit:  10,
+					Cursor: stringPtr("invalid-cursor-data"),
+				}
+				_, _, err := repo.Search(context.Background(), TaskFilter{}, req)
+				if !errors.Is(err, ErrInvalidCursor) {
+					return errors.New("expected ErrInvalidCursor")
+				}
+				return err
+			},
+			mockSetup: func(mq *MockQuerier) {
+				// Mock expects no calls since cursor validation fails first
+			},
+			wantError: "invalid cursor",
+		},
+		{
+			name: "Search - database query failure",
+			testFunc: func(repo *taskRepository) error {
+				userID := uuid.New()
+				req := CursorPaginationRequest{Limit: 10}
+				_, _, err := repo.Search(context.Background(), TaskFilter{UserID: &userID, Query: "nightly"}, req)
+				return err
+			},
+			mockSetup: func(mq *MockQuerier) {
+				mq.On("Query", mock.Anything, mock.MatchedBy(func(sql string) bool {
+					return strings.Contains(sql, "to_tsquery('simple', $2)") && strings.Contains(sql, "LIMIT $3")
+				}), mock.Anything).Return(nil, errors.New("database server error"))
+			},
+			wantError: "failed to search tasks",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// taskRevisionRepository implements TaskRevisionRepository interface
+type taskRevisionRepository struct {
+	querier Querier
+}
+
+// NewTaskRevisionRepository creates a new task revision repository
+func NewTaskRevisionRepository(conn *Connection) TaskRevisionRepository {
+	return &taskRevisionRepository{
+		querier: conn.Pool,
+	}
+}
+
+// NewTaskRevisionRepositoryWithTx creates a new task revision repository with transaction
+func NewTaskRevisionRepositoryWithTx(tx pgx.Tx) TaskRevisionRepository {
+	return &taskRevisionRepository{
+		querier: tx,
+	}
+}
+
+// GetByRevision retrieves a single revision of a task
+func (r *taskRevisionRepository) GetByRevision(ctx context.Context, taskID uuid.UUID, revision int) (*models.TaskRevision, error) {
+	query := `
+		SELECT task_id, revision, script_content, script_type, created_at
+		FROM task_revisions
+		WHERE task_id = $1 AND revision = $2
+	`
+
+	var rev models.TaskRevision
+	err := r.querier.QueryRow(ctx, query, taskID, revision).Scan(
+		&rev.TaskID,
+		&rev.Revision,
+		&rev.ScriptContent,
+		&rev.ScriptType,
+		&rev.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get task revision: %w", err)
+	}
+
+	return &rev, nil
+}
+
+// GetByTaskID retrieves the revisions of a task, newest first
+func (r *taskRevisionRepository) GetByTaskID(ctx context.Context, taskID uuid.UUID, limit, offset int) ([]*models.TaskRevision, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT task_id, revision, script_content, script_type, created_at
+		FROM task_revisions
+		WHERE task_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.querier.Query(ctx, query, taskID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*models.TaskRevision
+	for rows.Next() {
+		var rev models.TaskRevision
+		if err := rows.Scan(
+			&rev.TaskID,
+			&rev.Revision,
+			&rev.ScriptContent,
+			&rev.ScriptType,
+			&rev.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan task revision row: %w", err)
+		}
+		revisions = append(revisions, &rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task revision rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// CountByTaskID returns the number of revisions of a task
+func (r *taskRevisionRepository) CountByTaskID(ctx context.Context, taskID uuid.UUID) (int64, error) {
+	query := `SELECT COUNT(*) FROM task_revisions WHERE task_id = $1`
+
+	var count int64
+	err := r.querier.QueryRow(ctx, query, taskID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count task revisions: %w", err)
+	}
+
+	return count, nil
+}
+
+// TaskAtRevision returns the task as it was at the given revision. The task
+// itself is returned when no revision is given or it is the current one.
+func TaskAtRevision(ctx context.Context, revisions TaskRevisionRepository, task *models.Task, revision *int) (*models.Task, error) {
+	if revision == nil || *revision == task.Revision {
+		return task, nil
+	}
+
+	rev, err := revisions.GetByRevision(ctx, task.ID, *revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return task.AtRevision(rev), nil
+}
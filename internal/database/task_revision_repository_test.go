@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func TestTaskRevisionRepository_GetByRevision(t *testing.T) {
+	t.Run("existing revision", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &taskRevisionRepository{querier: mockQuerier}
+
+		taskID := uuid.New()
+		row := &MockRow{
+			data: []interface{}{taskID, 2, "echo two", "bash", time.Now()},
+		}
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+		revision, err := repo.GetByRevision(context.Background(), taskID, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, taskID, revision.TaskID)
+		assert.Equal(t, 2, revision.Revision)
+		assert.Equal(t, "echo two", revision.ScriptContent)
+		assert.Equal(t, models.ScriptTypeBash, revision.ScriptType)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("revision not found", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &taskRevisionRepository{querier: mockQuerier}
+
+		row := &MockRow{err: pgx.ErrNoRows}
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+		revision, err := repo.GetByRevision(context.Background(), uuid.New(), 7)
+
+		assert.ErrorIs(t, err, ErrTaskRevisionNotFound)
+		assert.Nil(t, revision)
+	})
+}
+
+func TestTaskAtRevision(t *testing.T) {
+	task := &models.Task{
+		BaseModel:     models.BaseModel{ID: uuid.New()},
+		Name:          "Test Task",
+		ScriptContent: "echo three",
+		ScriptType:    models.ScriptTypeBash,
+		Revision:      3,
+	}
+
+	t.Run("no revision returns the task", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &taskRevisionRepository{querier: mockQuerier}
+
+		result, err := TaskAtRevision(context.Background(), repo, task, nil)
+
+		require.NoError(t, err)
+		assert.Same(t, task, result)
+		mockQuerier.AssertNotCalled(t, "QueryRow")
+	})
+
+	t.Run("current revision returns the task", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &taskRevisionRepository{querier: mockQuerier}
+		revision := 3
+
+		result, err := TaskAtRevision(context.Background(), repo, task, &revision)
+
+		require.NoError(t, err)
+		assert.Same(t, task, result)
+		mockQuerier.AssertNotCalled(t, "QueryRow")
+	})
+
+	t.Run("earlier revision replaces the script", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		repo := &taskRevisionRepository{querier: mockQuerier}
+		revision := 1
+
+		row := &MockRow{
+			data: []interface{}{task.ID, 1, "print('one')", "python", time.Now()},
+		}
+		mockQuerier.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+		result, err := TaskAtRevision(context.Background(), repo, task, &revision)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Revision)
+		assert.Equal(t, "print('one')", result.ScriptContent)
+		assert.Equal(t, models.ScriptTypePython, result.ScriptType)
+		assert.Equal(t, task.Name, result.Name)
+		assert.Equal(t, 3, task.Revision, "the current task must not be modified")
+	})
+}
@@ -0,0 +1,305 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// taskTemplateColumns are the columns selected for a task template, in the
+// order scanTaskTemplate expects them
+const taskTemplateColumns = `id, owner_id, name, description, script_type, script_template, parameters, visibility, team, priority, timeout_seconds, security_level, required_capabilities, created_at, updated_at`
+
+// taskTemplateRepository implements TaskTemplateRepository interface
+type taskTemplateRepository struct {
+	querier Querier
+}
+
+// NewTaskTemplateRepository creates a new task template repository
+func NewTaskTemplateRepository(conn *Connection) TaskTemplateRepository {
+	return &taskTemplateRepository{
+		querier: conn.Pool,
+	}
+}
+
+// NewTaskTemplateRepositoryWithTx creates a new task template repository with transaction
+func NewTaskTemplateRepositoryWithTx(tx pgx.Tx) TaskTemplateRepository {
+	return &taskTemplateRepository{
+		querier: tx,
+	}
+}
+
+// Create creates a new task template
+func (r *taskTemplateRepository) Create(ctx context.Context, template *models.TaskTemplate) error {
+	if template == nil {
+		return fmt.Errorf("task template cannot be nil")
+	}
+
+	if template.ID == uuid.Nil {
+		template.ID = models.NewID()
+	}
+	setTaskTemplateDefaults(template)
+
+	query := `
+		INSERT INTO task_templates (id, owner_id, name, description, script_type, script_template, parameters, visibility, team, priority, timeout_seconds, security_level, required_capabilities, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::text[], '{}'), NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+
+	err := r.querier.QueryRow(ctx, query,
+		template.ID,
+		template.OwnerID,
+		template.Name,
+		template.Description,
+		template.ScriptType,
+		template.ScriptTemplate,
+		template.Parameters,
+		template.Visibility,
+		template.Team,
+		template.Priority,
+		template.TimeoutSeconds,
+		template.SecurityLevel,
+		template.RequiredCapabilities,
+	).Scan(&template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return taskTemplateError("create", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a task template by ID
+func (r *taskTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TaskTemplate, error) {
+	query := `SELECT ` + taskTemplateColumns + ` FROM task_templates WHERE id = $1`
+
+	template, err := scanTaskTemplate(r.querier.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get task template: %w", err)
+	}
+
+	return template, nil
+}
+
+// GetVisible retrieves the templates visible to a user, ordered by name
+func (r *taskTemplateRepository) GetVisible(ctx context.Context, userID uuid.UUID, teams []string, limit, offset int) ([]*models.TaskTemplate, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT ` + taskTemplateColumns + `
+		FROM task_templates
+		WHERE owner_id = $1
+			OR visibility = 'global'
+			OR (visibility = 'team' AND team = ANY($2::text[]))
+		ORDER BY name ASC, id ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.querier.Query(ctx, query, userID, teams, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visible task templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*models.TaskTemplate
+	for rows.Next() {
+		template, err := scanTaskTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task template row: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task template rows: %w", err)
+	}
+
+	return templates, nil
+}
+
+// CountVisible returns the number of templates visible to a user
+func (r *taskTemplateRepository) CountVisible(ctx context.Context, userID uuid.UUID, teams []string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM task_templates
+		WHERE owner_id = $1
+			OR visibility = 'global'
+			OR (visibility = 'team' AND team = ANY($2::text[]))
+	`
+
+	var count int64
+	err := r.querier.QueryRow(ctx, query, userID, teams).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count visible task templates: %w", err)
+	}
+
+	return count, nil
+}
+
+// Update updates a task template
+func (r *taskTemplateRepository) Update(ctx context.Context, template *models.TaskTemplate) error {
+	if template == nil {
+		return fmt.Errorf("task template cannot be nil")
+	}
+
+	query := `
+		UPDATE task_templates
+		SET name = $2, description = $3, script_type = $4, script_template = $5, parameters = $6, visibility = $7, team = $8,
+			priority = $9, timeout_seconds = $10, security_level = $11, required_capabilities = COALESCE($12::text[], '{}'), updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.querier.QueryRow(ctx, query,
+		template.ID,
+		template.Name,
+		template.Description,
+		template.ScriptType,
+		template.ScriptTemplate,
+		template.Parameters,
+		template.Visibility,
+		template.Team,
+		template.Priority,
+		template.TimeoutSeconds,
+		template.SecurityLevel,
+		template.RequiredCapabilities,
+	).Scan(&template.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaskTemplateNotFound
+		}
+		return taskTemplateError("update", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a task template
+func (r *taskTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM task_templates WHERE id = $1`
+
+	result, err := r.querier.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task template: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTaskTemplateNotFound
+	}
+
+	return nil
+}
+
+// Import creates the owner-less template with the template's name, or
+// replaces its content when it already exists. The ID and creation time of an
+// existing template are kept, so tasks and links referring to it stay valid.
+func (r *taskTemplateRepository) Import(ctx context.Context, template *models.TaskTemplate) error {
+	if template == nil {
+		return fmt.Errorf("task template cannot be nil")
+	}
+
+	if template.ID == uuid.Nil {
+		template.ID = models.NewID()
+	}
+	template.OwnerID = nil
+	setTaskTemplateDefaults(template)
+
+	query := `
+		INSERT INTO task_templates (id, owner_id, name, description, script_type, script_template, parameters, visibility, team, priority, timeout_seconds, security_level, required_capabilities, created_at, updated_at)
+		VALUES ($1, NULL, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12::text[], '{}'), NOW(), NOW())
+		ON CONFLICT (name) WHERE owner_id IS NULL DO UPDATE
+		SET description = EXCLUDED.description, script_type = EXCLUDED.script_type, script_template = EXCLUDED.script_template,
+			parameters = EXCLUDED.parameters, visibility = EXCLUDED.visibility, team = EXCLUDED.team, priority = EXCLUDED.priority,
+			timeout_seconds = EXCLUDED.timeout_seconds, security_level = EXCLUDED.security_level,
+			required_capabilities = EXCLUDED.required_capabilities, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.querier.QueryRow(ctx, query,
+		template.ID,
+		template.Name,
+		template.Description,
+		template.ScriptType,
+		template.ScriptTemplate,
+		template.Parameters,
+		template.Visibility,
+		template.Team,
+		template.Priority,
+		template.TimeoutSeconds,
+		template.SecurityLevel,
+		template.RequiredCapabilities,
+	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return taskTemplateError("import", err)
+	}
+
+	return nil
+}
+
+// setTaskTemplateDefaults fills in the defaults of unset template fields
+func setTaskTemplateDefaults(template *models.TaskTemplate) {
+	if template.Visibility == "" {
+		template.Visibility = models.TemplateVisibilityPrivate
+	}
+	if template.SecurityLevel == "" {
+		template.SecurityLevel = models.SecurityLevelStandard
+	}
+	if template.Parameters == nil {
+		template.Parameters = []models.TemplateParameter{}
+	}
+}
+
+// scanTaskTemplate scans a row of taskTemplateColumns
+func scanTaskTemplate(row pgx.Row) (*models.TaskTemplate, error) {
+	var template models.TaskTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.OwnerID,
+		&template.Name,
+		&template.Description,
+		&template.ScriptType,
+		&template.ScriptTemplate,
+		&template.Parameters,
+		&template.Visibility,
+		&template.Team,
+		&template.Priority,
+		&template.TimeoutSeconds,
+		&template.SecurityLevel,
+		&template.RequiredCapabilities,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// taskTemplateError maps constraint violations of a template write to errors
+func taskTemplateError(operation string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return ErrTaskTemplateExists
+		case "23514": // check_violation
+			return fmt.Errorf("task template validation failed: %s", pgErr.Detail)
+		}
+	}
+	return fmt.Errorf("failed to %s task template: %w", operation, err)
+}
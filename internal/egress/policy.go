@@ -0,0 +1,166 @@
+// Package egress implements the network egress policy of task executions and
+// the forward proxy that enforces it. Executions with network access run on
+// an internal network whose only route out is this proxy, which checks every
+// connection attempt against the policy and logs it as an Event.
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// DefaultInternalCIDRs are the address ranges the internal network mode allows
+var DefaultInternalCIDRs = []string{
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+}
+
+// Policy describes the destinations an execution may connect to
+type Policy struct {
+	Mode          models.TaskNetworkMode `json:"mode"`
+	Allowlist     []string               `json:"allowlist,omitempty"`
+	InternalCIDRs []string               `json:"internal_cidrs,omitempty"`
+}
+
+// Resolver looks up the addresses of a host
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Decision is the outcome of checking a destination against the policy
+type Decision struct {
+	Allowed bool
+	Reason  string
+
+	// Addr is the address to connect to when the destination is allowed. The
+	// proxy dials this exact address, so a host can't be re-resolved to a
+	// destination the policy didn't check.
+	Addr netip.Addr
+}
+
+// Matcher checks destinations against a compiled policy
+type Matcher struct {
+	mode     models.TaskNetworkMode
+	hosts    map[string]struct{}
+	suffixes []string
+	prefixes []netip.Prefix
+}
+
+// NewMatcher compiles a policy
+func NewMatcher(policy Policy) (*Matcher, error) {
+	if err := models.ValidateNetworkMode(policy.Mode); err != nil {
+		return nil, err
+	}
+
+	m := &Matcher{
+		mode:  policy.Mode,
+		hosts: make(map[string]struct{}),
+	}
+
+	switch policy.Mode {
+	case models.NetworkModeInternal:
+		cidrs := policy.InternalCIDRs
+		if len(cidrs) == 0 {
+			cidrs = DefaultInternalCIDRs
+		}
+		for _, cidr := range cidrs {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid internal CIDR %q: %w", cidr, err)
+			}
+			m.prefixes = append(m.prefixes, prefix.Masked())
+		}
+	case models.NetworkModeAllowlist:
+		for _, destination := range models.NormalizeNetworkAllowlist(policy.Allowlist) {
+			if err := models.ValidateNetworkDestination(destination); err != nil {
+				return nil, err
+			}
+			if prefix, err := netip.ParsePrefix(destination); err == nil {
+				m.prefixes = append(m.prefixes, prefix.Masked())
+			} else if addr, err := netip.ParseAddr(destination); err == nil {
+				m.prefixes = append(m.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			} else if suffix, ok := strings.CutPrefix(destination, "*"); ok {
+				m.suffixes = append(m.suffixes, suffix)
+			} else {
+				m.hosts[destination] = struct{}{}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Decide checks whether a connection to host may be made and picks the
+// address to connect to
+func (m *Matcher) Decide(ctx context.Context, host string, resolver Resolver) Decision {
+	if m.mode == models.NetworkModeNone {
+		return Decision{Reason: "network access is disabled"}
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		if m.addrAllowed(addr) {
+			return Decision{Allowed: true, Reason: "address allowed", Addr: addr}
+		}
+		return Decision{Reason: "address not allowed"}
+	}
+
+	// Names that can't be allowed are rejected without a lookup, so the
+	// proxy's resolver can't be used to leak data through DNS queries
+	nameAllowed := m.hostAllowed(host)
+	if !nameAllowed && len(m.prefixes) == 0 {
+		return Decision{Reason: "host not allowed"}
+	}
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return Decision{Reason: "host could not be resolved"}
+	}
+
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if m.addrAllowed(addr) {
+			return Decision{Allowed: true, Reason: "address allowed", Addr: addr}
+		}
+		// An allowlisted name must not be used to reach the host itself or
+		// cloud metadata endpoints
+		if nameAllowed && addr.IsGlobalUnicast() {
+			return Decision{Allowed: true, Reason: "host allowed", Addr: addr}
+		}
+	}
+
+	if nameAllowed {
+		return Decision{Reason: "host resolves to a restricted address"}
+	}
+	return Decision{Reason: "host not allowed"}
+}
+
+// hostAllowed reports whether the host name is allowlisted
+func (m *Matcher) hostAllowed(host string) bool {
+	if _, ok := m.hosts[host]; ok {
+		return true
+	}
+	for _, suffix := range m.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addrAllowed reports whether the address is covered by an allowed prefix
+func (m *Matcher) addrAllowed(addr netip.Addr) bool {
+	for _, prefix := range m.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
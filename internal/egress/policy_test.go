@@ -0,0 +1,145 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// staticResolver resolves host names from a fixed table
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	result := make([]netip.Addr, len(addrs))
+	for i, addr := range addrs {
+		result[i] = netip.MustParseAddr(addr)
+	}
+	return result, nil
+}
+
+func TestMatcher_Decide(t *testing.T) {
+	resolver := staticResolver{
+		"pypi.org":             {"151.101.0.223"},
+		"files.example.com":    {"93.184.215.14"},
+		"metadata.example.com": {"169.254.169.254"},
+		"db.internal":          {"10.1.2.3"},
+		"evil.com":             {"203.0.113.7"},
+	}
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		host    string
+		allowed bool
+		reason  string
+	}{
+		{
+			name:   "no network",
+			policy: Policy{Mode: models.NetworkModeNone},
+			host:   "pypi.org",
+			reason: "network access is disabled",
+		},
+		{
+			name:    "internal allows private address",
+			policy:  Policy{Mode: models.NetworkModeInternal},
+			host:    "db.internal",
+			allowed: true,
+		},
+		{
+			name:   "internal rejects public host",
+			policy: Policy{Mode: models.NetworkModeInternal},
+			host:   "pypi.org",
+			reason: "host not allowed",
+		},
+		{
+			name:   "internal honours configured ranges",
+			policy: Policy{Mode: models.NetworkModeInternal, InternalCIDRs: []string{"192.168.0.0/16"}},
+			host:   "10.1.2.3",
+			reason: "address not allowed",
+		},
+		{
+			name:    "allowlisted host",
+			policy:  Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"pypi.org"}},
+			host:    "PyPI.org.",
+			allowed: true,
+		},
+		{
+			name:    "wildcard matches subdomain",
+			policy:  Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"*.example.com"}},
+			host:    "files.example.com",
+			allowed: true,
+		},
+		{
+			name:   "wildcard does not match bare domain",
+			policy: Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"*.example.com"}},
+			host:   "example.com",
+			reason: "host not allowed",
+		},
+		{
+			name:   "allowlisted name resolving to link-local address",
+			policy: Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"*.example.com"}},
+			host:   "metadata.example.com",
+			reason: "host resolves to a restricted address",
+		},
+		{
+			name:   "allowlisted host that does not resolve",
+			policy: Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"*.example.com"}},
+			host:   "missing.example.com",
+			reason: "host could not be resolved",
+		},
+		{
+			name:    "allowlisted CIDR",
+			policy:  Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"203.0.113.0/24"}},
+			host:    "evil.com",
+			allowed: true,
+		},
+		{
+			name:   "host outside allowlist",
+			policy: Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"pypi.org"}},
+			host:   "evil.com",
+			reason: "host not allowed",
+		},
+		{
+			name:   "IP literal outside allowlist",
+			policy: Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"pypi.org"}},
+			host:   "151.101.0.223",
+			reason: "address not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewMatcher(tt.policy)
+			require.NoError(t, err)
+
+			decision := matcher.Decide(context.Background(), tt.host, resolver)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			if tt.allowed {
+				assert.True(t, decision.Addr.IsValid())
+			} else {
+				assert.Equal(t, tt.reason, decision.Reason)
+			}
+		})
+	}
+}
+
+func TestNewMatcher_InvalidPolicy(t *testing.T) {
+	_, err := NewMatcher(Policy{Mode: "public"})
+	assert.Error(t, err)
+
+	_, err = NewMatcher(Policy{Mode: models.NetworkModeInternal, InternalCIDRs: []string{"10.0.0.0"}})
+	assert.ErrorContains(t, err, "invalid internal CIDR")
+
+	_, err = NewMatcher(Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"https://pypi.org"}})
+	assert.ErrorContains(t, err, "invalid network destination")
+}
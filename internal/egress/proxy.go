@@ -0,0 +1,273 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadyMessage is written to the event stream once the proxy accepts connections
+const ReadyMessage = "egress proxy ready"
+
+// DefaultPort is the port the proxy listens on
+const DefaultPort = 3128
+
+// Event records a single connection attempt
+type Event struct {
+	Time    time.Time `json:"time"`
+	Host    string    `json:"host"`
+	Port    int       `json:"port"`
+	Allowed bool      `json:"allowed"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// hopHeaders are removed before a plain HTTP request is forwarded
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+type dialAddrKey struct{}
+
+// Proxy is a forward proxy for HTTP and CONNECT requests that only lets
+// connections through to destinations allowed by the policy
+type Proxy struct {
+	matcher   *Matcher
+	resolver  Resolver
+	dialer    *net.Dialer
+	transport *http.Transport
+	logger    *slog.Logger
+
+	mu     sync.Mutex
+	events *json.Encoder
+}
+
+// NewProxy creates a proxy enforcing the policy. Every connection attempt is
+// written to events as a JSON line.
+func NewProxy(policy Policy, events io.Writer, logger *slog.Logger) (*Proxy, error) {
+	matcher, err := NewMatcher(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p := &Proxy{
+		matcher:  matcher,
+		resolver: net.DefaultResolver,
+		dialer:   &net.Dialer{Timeout: 10 * time.Second},
+		logger:   logger,
+		events:   json.NewEncoder(events),
+	}
+
+	// Plain HTTP requests are only ever dialed to the address the policy
+	// approved, which is passed along in the request context
+	p.transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			addr, _ := ctx.Value(dialAddrKey{}).(string)
+			return p.dialer.DialContext(ctx, network, addr)
+		},
+		ForceAttemptHTTP2:   false,
+		MaxIdleConns:        16,
+		IdleConnTimeout:     30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	return p, nil
+}
+
+// ServeHTTP handles a proxy request
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.handleConnect(w, r)
+		return
+	}
+
+	if r.URL.Host == "" || r.URL.Scheme != "http" {
+		http.Error(w, "only proxy requests are supported", http.StatusBadRequest)
+		return
+	}
+
+	p.handleHTTP(w, r)
+}
+
+// handleConnect tunnels a connection, typically for HTTPS
+func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	addr, ok := p.authorize(r.Context(), r.Host, 443)
+	if !ok {
+		http.Error(w, "destination not allowed by network policy", http.StatusForbidden)
+		return
+	}
+
+	upstream, err := p.dialer.DialContext(r.Context(), "tcp", addr)
+	if err != nil {
+		p.logger.Warn("failed to connect upstream", "addr", addr, "error", err)
+		http.Error(w, "failed to connect to destination", http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		_ = upstream.Close()
+		http.Error(w, "connection hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+		p.logger.Warn("failed to hijack connection", "error", err)
+		return
+	}
+
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		_ = upstream.Close()
+		return
+	}
+
+	tunnel(client, buffered, upstream)
+}
+
+// handleHTTP forwards a plain HTTP request
+func (p *Proxy) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	addr, ok := p.authorize(r.Context(), r.URL.Host, 80)
+	if !ok {
+		http.Error(w, "destination not allowed by network policy", http.StatusForbidden)
+		return
+	}
+
+	outReq := r.Clone(context.WithValue(r.Context(), dialAddrKey{}, addr))
+	outReq.RequestURI = ""
+	for _, header := range hopHeaders {
+		outReq.Header.Del(header)
+	}
+
+	resp, err := p.transport.RoundTrip(outReq)
+	if err != nil {
+		p.logger.Warn("failed to forward request", "addr", addr, "error", err)
+		http.Error(w, "failed to reach destination", http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	for _, header := range hopHeaders {
+		resp.Header.Del(header)
+	}
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// authorize checks a host[:port] destination, records the attempt and
+// returns the address to dial
+func (p *Proxy) authorize(ctx context.Context, hostport string, defaultPort int) (string, bool) {
+	host, portStr, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.Trim(hostport, "[]")
+		portStr = strconv.Itoa(defaultPort)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		p.record(Event{Host: host, Allowed: false, Reason: "invalid port"})
+		return "", false
+	}
+
+	decision := p.matcher.Decide(ctx, host, p.resolver)
+	p.record(Event{Host: host, Port: port, Allowed: decision.Allowed, Reason: decision.Reason})
+	if !decision.Allowed {
+		return "", false
+	}
+
+	return netip.AddrPortFrom(decision.Addr, uint16(port)).String(), true
+}
+
+// record writes a connection attempt to the event stream
+func (p *Proxy) record(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.events.Encode(event); err != nil {
+		p.logger.Error("failed to record network event", "error", err)
+	}
+}
+
+// tunnel copies data between the client and upstream until either side closes
+func tunnel(client net.Conn, buffered *bufio.ReadWriter, upstream net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		// Forward anything the client sent along with the CONNECT request
+		if n := buffered.Reader.Buffered(); n > 0 {
+			data, _ := buffered.Reader.Peek(n)
+			_, _ = upstream.Write(data)
+		}
+		_, _ = io.Copy(upstream, client)
+		closeWrite(upstream)
+	}()
+
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(client, upstream)
+		closeWrite(client)
+	}()
+
+	wg.Wait()
+	_ = client.Close()
+	_ = upstream.Close()
+}
+
+// closeWrite half-closes a TCP connection so the peer sees EOF
+func closeWrite(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
+
+// ParseEvents extracts the connection attempts from the proxy's event stream,
+// skipping any line that isn't an event
+func ParseEvents(output string) []Event {
+	var events []Event
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event.Host == "" {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,181 @@
+package egress
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestProxy(t *testing.T, policy Policy) (*httptest.Server, *syncBuffer) {
+	t.Helper()
+
+	events := &syncBuffer{}
+	proxy, err := NewProxy(policy, events, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+	return server, events
+}
+
+func TestProxy_HTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Proxy-Authorization"))
+		_, _ = fmt.Fprint(w, "hello from upstream")
+	}))
+	defer upstream.Close()
+
+	t.Run("forwards allowed request", func(t *testing.T) {
+		proxyServer, events := newTestProxy(t, Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"127.0.0.0/8"}})
+		proxyURL, _ := url.Parse(proxyServer.URL)
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+		req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+		req.Header.Set("Proxy-Authorization", "Basic c2VjcmV0")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello from upstream", string(body))
+
+		recorded := ParseEvents(events.String())
+		require.Len(t, recorded, 1)
+		assert.Equal(t, "127.0.0.1", recorded[0].Host)
+		assert.True(t, recorded[0].Allowed)
+	})
+
+	t.Run("rejects destination outside policy", func(t *testing.T) {
+		proxyServer, events := newTestProxy(t, Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"10.0.0.0/8"}})
+		proxyURL, _ := url.Parse(proxyServer.URL)
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+		resp, err := client.Get(upstream.URL)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		recorded := ParseEvents(events.String())
+		require.Len(t, recorded, 1)
+		assert.False(t, recorded[0].Allowed)
+		assert.Equal(t, "address not allowed", recorded[0].Reason)
+	})
+
+	t.Run("rejects direct requests", func(t *testing.T) {
+		proxyServer, _ := newTestProxy(t, Policy{Mode: models.NetworkModeInternal})
+
+		resp, err := http.Get(proxyServer.URL + "/")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestProxy_Connect(t *testing.T) {
+	// Echo server standing in for a TLS endpoint
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	connect := func(t *testing.T, proxyAddr, target string) (net.Conn, *bufio.Reader, *http.Response) {
+		conn, err := net.Dial("tcp", proxyAddr)
+		require.NoError(t, err)
+		_, err = fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", target, target)
+		require.NoError(t, err)
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+		return conn, reader, resp
+	}
+
+	t.Run("tunnels allowed destination", func(t *testing.T) {
+		proxyServer, events := newTestProxy(t, Policy{Mode: models.NetworkModeAllowlist, Allowlist: []string{"127.0.0.1"}})
+
+		conn, reader, resp := connect(t, strings.TrimPrefix(proxyServer.URL, "http://"), listener.Addr().String())
+		defer func() { _ = conn.Close() }()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err := fmt.Fprint(conn, "ping\n")
+		require.NoError(t, err)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "ping\n", line)
+
+		recorded := ParseEvents(events.String())
+		require.Len(t, recorded, 1)
+		assert.True(t, recorded[0].Allowed)
+		assert.Equal(t, listener.Addr().(*net.TCPAddr).Port, recorded[0].Port)
+	})
+
+	t.Run("refuses destination outside policy", func(t *testing.T) {
+		proxyServer, events := newTestProxy(t, Policy{Mode: models.NetworkModeNone})
+
+		conn, _, resp := connect(t, strings.TrimPrefix(proxyServer.URL, "http://"), listener.Addr().String())
+		defer func() { _ = conn.Close() }()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		recorded := ParseEvents(events.String())
+		require.Len(t, recorded, 1)
+		assert.Equal(t, "network access is disabled", recorded[0].Reason)
+	})
+}
+
+func TestParseEvents(t *testing.T) {
+	output := ReadyMessage + "\n" +
+		`{"time":"2026-01-02T03:04:05Z","host":"pypi.org","port":443,"allowed":true,"reason":"host allowed"}` + "\n" +
+		`{"time":"2026-01-02T03:04:06Z","level":"INFO","msg":"not an event"}` + "\n" +
+		"{broken\n"
+
+	events := ParseEvents(output)
+	require.Len(t, events, 1)
+	assert.Equal(t, "pypi.org", events[0].Host)
+	assert.Equal(t, 443, events[0].Port)
+	assert.True(t, events[0].Allowed)
+}
@@ -32,6 +32,19 @@ func (m *MockContainerClientForCleanup) GetDockerVersion(ctx context.Context) (i
 	return args.Get(0), args.Error(1)
 }
 
+func (m *MockContainerClientForCleanup) ListRuntimes(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockContainerClientForCleanup) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	args := m.Called(ctx, image)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockContainerClientForCleanup) GetContainerInfo(ctx context.Context, containerID string) (interface{}, error) {
 	args := m.Called(ctx, containerID)
 	return args.Get(0), args.Error(1)
@@ -1,16 +1,39 @@
 package executor
 
 import (
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
 	"time"
 
+	"github.com/voidrunnerhq/voidrunner/internal/config"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
+// Supported container backends
+const (
+	// BackendDocker runs containers through the Docker daemon
+	BackendDocker = "docker"
+
+	// BackendPodman runs containers through the rootless Podman REST service
+	BackendPodman = "podman"
+
+	// BackendProcess runs scripts on the host inside Linux namespaces
+	BackendProcess = "process"
+)
+
 // Config represents the configuration for the executor
 type Config struct {
+	// Container backend (docker or podman)
+	Backend string
+
 	// Docker daemon endpoint
 	DockerEndpoint string
 
+	// Podman REST service endpoint (defaults to the user socket)
+	PodmanEndpoint string
+
 	// Default resource limits
 	DefaultResourceLimits ResourceLimits
 
@@ -22,6 +45,119 @@ type Config struct {
 
 	// Security settings
 	Security SecuritySettings
+
+	// Process sandbox settings (process backend only)
+	Sandbox SandboxSettings
+
+	// OCI runtime selection (container backends only)
+	Runtimes RuntimeSettings
+
+	// Network egress settings (container backends only)
+	Network NetworkSettings
+
+	// Execution output limits
+	Output OutputSettings
+}
+
+// OutputSettings caps how much of each output stream (stdout and stderr) an
+// execution keeps. Output over the caps is cut in the middle: its start and
+// end are kept around a marker saying how much was left out.
+type OutputSettings struct {
+	// Maximum bytes kept per stream
+	MaxBytes int
+
+	// Maximum lines kept per stream (0 for no line limit)
+	MaxLines int
+
+	// Directory the full output of truncated executions is written to, for
+	// download through the API. It must be shared by the workers and API
+	// servers. Empty disables spilling.
+	SpillDir string
+
+	// Maximum bytes spilled per stream; output beyond it is dropped
+	MaxSpillBytes int64
+}
+
+// NetworkSettings configures how executions with network access are isolated.
+// Such executions run on their own internal network whose only way out is an
+// egress proxy sidecar enforcing the task's network policy.
+type NetworkSettings struct {
+	// Image of the egress proxy sidecar
+	EgressProxyImage string
+
+	// Address ranges the internal network mode allows (defaults to the
+	// private IPv4 ranges and IPv6 unique local addresses)
+	InternalCIDRs []string
+}
+
+// RuntimeSettings selects the OCI runtime containers are created with. Empty
+// runtime names use the daemon's default runtime.
+type RuntimeSettings struct {
+	// Runtime used when no more specific mapping applies
+	Default string
+
+	// Runtime per script type, e.g. python=runsc
+	ByScriptType map[models.ScriptType]string
+
+	// Runtime per task security level, e.g. sandboxed=runsc
+	BySecurityLevel map[models.TaskSecurityLevel]string
+}
+
+// NewRuntimeSettings builds runtime settings from string-keyed mappings, as
+// parsed from the environment
+func NewRuntimeSettings(defaultRuntime string, byScriptType, bySecurityLevel map[string]string) RuntimeSettings {
+	settings := RuntimeSettings{Default: defaultRuntime}
+
+	if len(byScriptType) > 0 {
+		settings.ByScriptType = make(map[models.ScriptType]string, len(byScriptType))
+		for scriptType, runtime := range byScriptType {
+			settings.ByScriptType[models.ScriptType(scriptType)] = runtime
+		}
+	}
+
+	if len(bySecurityLevel) > 0 {
+		settings.BySecurityLevel = make(map[models.TaskSecurityLevel]string, len(bySecurityLevel))
+		for level, runtime := range bySecurityLevel {
+			settings.BySecurityLevel[models.TaskSecurityLevel(level)] = runtime
+		}
+	}
+
+	return settings
+}
+
+// Configured returns the distinct runtime names referenced by the settings
+func (r RuntimeSettings) Configured() []string {
+	seen := make(map[string]bool)
+	var runtimes []string
+
+	add := func(runtime string) {
+		if runtime != "" && !seen[runtime] {
+			seen[runtime] = true
+			runtimes = append(runtimes, runtime)
+		}
+	}
+
+	add(r.Default)
+	for _, runtime := range r.ByScriptType {
+		add(runtime)
+	}
+	for _, runtime := range r.BySecurityLevel {
+		add(runtime)
+	}
+
+	sort.Strings(runtimes)
+	return runtimes
+}
+
+// SandboxSettings defines configuration for the process sandbox backend
+type SandboxSettings struct {
+	// Delegated cgroup v2 directory under which per-execution cgroups are
+	// created. Without it, memory is limited with rlimits only and CPU and
+	// PID limits are not enforced.
+	CgroupParent string
+
+	// Host paths hidden behind an empty read-only tmpfs
+	MaskedPaths []string
 }
 
 // ImageConfig defines container images for different script types
@@ -37,6 +173,18 @@ type ImageConfig struct {
 
 	// Go execution image (for future use)
 	Go string
+
+	// Number of pre-started containers kept warm per image (0 disables the
+	// warm pool for that image)
+	PythonPoolSize     int
+	BashPoolSize       int
+	JavaScriptPoolSize int
+	GoPoolSize         int
+
+	// Repositories tasks may name as their custom image. Entries are either
+	// an exact repository ("python", "ghcr.io/acme/runner") or a namespace
+	// pattern ending in "/*" ("ghcr.io/acme/*"). Empty disables custom images.
+	AllowedRepositories []string
 }
 
 // SecuritySettings defines security configuration
@@ -70,12 +218,23 @@ type SecuritySettings struct {
 
 	// Maximum allowed timeout in seconds (safety cap)
 	MaxTimeoutSeconds int
+
+	// Script analysis policy: block rejects scripts with findings, warn and
+	// audit only report them
+	ScriptAnalysisMode models.ScriptAnalysisMode
+
+	// Run containers without the resource limits the runtime cannot enforce,
+	// e.g. Podman without delegated cgroup controllers, instead of refusing
+	// to create them
+	AllowUnenforcedLimits bool
 }
 
 // NewDefaultConfig returns a default configuration for the executor
 func NewDefaultConfig() *Config {
 	return &Config{
+		Backend:        BackendDocker,
 		DockerEndpoint: "unix:///var/run/docker.sock",
+		PodmanEndpoint: DefaultPodmanEndpoint(),
 		DefaultResourceLimits: ResourceLimits{
 			MemoryLimitBytes: 128 * 1024 * 1024, // 128MB
 			CPUQuota:         50000,             // 0.5 CPU cores
@@ -100,6 +259,7 @@ func NewDefaultConfig() *Config {
 			MaxCPUQuota:         200000,             // 2.0 CPU cores maximum
 			MaxPidsLimit:        1000,               // 1000 processes maximum
 			MaxTimeoutSeconds:   3600,               // 1 hour maximum
+			ScriptAnalysisMode:  models.ScriptAnalysisModeBlock,
 			AllowedSyscalls: []string{
 				"read", "write", "open", "close", "stat", "fstat", "lstat",
 				"poll", "lseek", "mmap", "mprotect", "munmap", "brk",
@@ -118,9 +278,129 @@ func NewDefaultConfig() *Config {
 				"prctl", "getcpu", "exit", "exit_group",
 			},
 		},
+		Sandbox: SandboxSettings{
+			MaskedPaths: []string{"/home", "/root", "/run", "/var/run", "/mnt", "/media"},
+		},
+		Network: NetworkSettings{
+			EgressProxyImage: DefaultEgressProxyImage,
+		},
+		Output: OutputSettings{
+			MaxBytes:      DefaultOutputMaxBytes,
+			MaxLines:      DefaultOutputMaxLines,
+			MaxSpillBytes: DefaultOutputMaxSpillBytes,
+		},
+	}
+}
+
+// NewConfig creates the executor configuration from the application settings,
+// with unset settings filled from the defaults
+func NewConfig(cfg *config.ExecutorConfig) *Config {
+	executorConfig := &Config{
+		Backend:        cfg.Backend,
+		DockerEndpoint: cfg.DockerEndpoint,
+		PodmanEndpoint: cfg.PodmanEndpoint,
+		DefaultResourceLimits: ResourceLimits{
+			MemoryLimitBytes: int64(cfg.DefaultMemoryLimitMB) * 1024 * 1024,
+			CPUQuota:         cfg.DefaultCPUQuota,
+			PidsLimit:        cfg.DefaultPidsLimit,
+			TimeoutSeconds:   cfg.DefaultTimeoutSeconds,
+		},
+		DefaultTimeoutSeconds: cfg.DefaultTimeoutSeconds,
+		Images: ImageConfig{
+			Python:     cfg.PythonImage,
+			Bash:       cfg.BashImage,
+			JavaScript: cfg.JavaScriptImage,
+			Go:         cfg.GoImage,
+
+			PythonPoolSize:     cfg.PythonPoolSize,
+			BashPoolSize:       cfg.BashPoolSize,
+			JavaScriptPoolSize: cfg.JavaScriptPoolSize,
+			GoPoolSize:         cfg.GoPoolSize,
+
+			AllowedRepositories: cfg.AllowedImageRepositories,
+		},
+		Security: SecuritySettings{
+			EnableSeccomp:      cfg.EnableSeccomp,
+			SeccompProfilePath: cfg.SeccompProfilePath,
+			EnableAppArmor:     cfg.EnableAppArmor,
+			AppArmorProfile:    cfg.AppArmorProfile,
+			ExecutionUser:      cfg.ExecutionUser,
+			ScriptAnalysisMode: models.ScriptAnalysisMode(cfg.ScriptAnalysisMode),
+
+			AllowUnenforcedLimits: cfg.AllowUnenforcedLimits,
+		},
+		Sandbox: SandboxSettings{
+			CgroupParent: cfg.SandboxCgroupParent,
+		},
+		Runtimes: NewRuntimeSettings(
+			cfg.DefaultRuntime,
+			cfg.RuntimesByScriptType,
+			cfg.RuntimesBySecurityLevel,
+		),
+		Network: NetworkSettings{
+			EgressProxyImage: cfg.EgressProxyImage,
+			InternalCIDRs:    cfg.InternalCIDRs,
+		},
+		Output: OutputSettings{
+			MaxBytes:      cfg.OutputMaxBytes,
+			MaxLines:      cfg.OutputMaxLines,
+			SpillDir:      cfg.OutputSpillDir,
+			MaxSpillBytes: cfg.OutputMaxSpillBytes,
+		},
+	}
+	executorConfig.ApplyDefaults()
+
+	return executorConfig
+}
+
+// ApplyDefaults fills unset settings, including those that have no
+// environment overrides (security caps, syscall allowlist and masked sandbox
+// paths), from the defaults
+func (c *Config) ApplyDefaults() {
+	defaults := NewDefaultConfig()
+
+	if c.Security.MaxMemoryLimitBytes == 0 {
+		c.Security.MaxMemoryLimitBytes = defaults.Security.MaxMemoryLimitBytes
+	}
+	if c.Security.MaxCPUQuota == 0 {
+		c.Security.MaxCPUQuota = defaults.Security.MaxCPUQuota
+	}
+	if c.Security.MaxPidsLimit == 0 {
+		c.Security.MaxPidsLimit = defaults.Security.MaxPidsLimit
+	}
+	if c.Security.MaxTimeoutSeconds == 0 {
+		c.Security.MaxTimeoutSeconds = defaults.Security.MaxTimeoutSeconds
+	}
+	if c.Security.ScriptAnalysisMode == "" {
+		c.Security.ScriptAnalysisMode = defaults.Security.ScriptAnalysisMode
+	}
+	if len(c.Security.AllowedSyscalls) == 0 {
+		c.Security.AllowedSyscalls = defaults.Security.AllowedSyscalls
+	}
+	if c.Sandbox.MaskedPaths == nil {
+		c.Sandbox.MaskedPaths = defaults.Sandbox.MaskedPaths
+	}
+	if c.Network.EgressProxyImage == "" {
+		c.Network.EgressProxyImage = defaults.Network.EgressProxyImage
+	}
+	if c.Output.MaxBytes == 0 {
+		c.Output.MaxBytes = defaults.Output.MaxBytes
+	}
+	if c.Output.MaxSpillBytes == 0 {
+		c.Output.MaxSpillBytes = defaults.Output.MaxSpillBytes
 	}
 }
 
+// DefaultPodmanEndpoint returns the socket of the rootless Podman service for
+// the current user, as started by `podman system service`
+func DefaultPodmanEndpoint() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return "unix://" + runtimeDir + "/podman/podman.sock"
+}
+
 // GetImageForScriptType returns the appropriate container image for the given script type
 func (c *Config) GetImageForScriptType(scriptType models.ScriptType) string {
 	switch scriptType {
@@ -137,6 +417,47 @@ func (c *Config) GetImageForScriptType(scriptType models.ScriptType) string {
 	}
 }
 
+// GetPoolSizeForScriptType returns the warm pool size for the image of the given script type
+func (c *Config) GetPoolSizeForScriptType(scriptType models.ScriptType) int {
+	switch scriptType {
+	case models.ScriptTypePython:
+		return c.Images.PythonPoolSize
+	case models.ScriptTypeBash:
+		return c.Images.BashPoolSize
+	case models.ScriptTypeJavaScript:
+		return c.Images.JavaScriptPoolSize
+	case models.ScriptTypeGo:
+		return c.Images.GoPoolSize
+	default:
+		return 0
+	}
+}
+
+// GetRuntimeForTask returns the OCI runtime a task must be executed with.
+// The task's security level takes precedence over its script type. Tasks
+// above the standard level fail closed when no runtime is mapped to their
+// level, rather than silently running under the default runtime.
+func (c *Config) GetRuntimeForTask(task *models.Task) (string, error) {
+	level := task.SecurityLevel
+	if level == "" {
+		level = models.SecurityLevelStandard
+	}
+
+	if runtime := c.Runtimes.BySecurityLevel[level]; runtime != "" {
+		return runtime, nil
+	}
+
+	if level != models.SecurityLevelStandard {
+		return "", ErrInvalidConfigField("runtimes", fmt.Sprintf("no runtime configured for security level %q", level))
+	}
+
+	if runtime := c.Runtimes.ByScriptType[task.ScriptType]; runtime != "" {
+		return runtime, nil
+	}
+
+	return c.Runtimes.Default, nil
+}
+
 // GetResourceLimitsForTask returns resource limits for a specific task
 func (c *Config) GetResourceLimitsForTask(task *models.Task) ResourceLimits {
 	limits := c.DefaultResourceLimits
@@ -206,11 +527,15 @@ func (c *Config) GetSecurityConfigForTask(task *models.Task) SecurityConfig {
 		securityOpts = append(securityOpts, "apparmor="+c.Security.AppArmorProfile)
 	}
 
+	// Network access is only ever granted through an egress network, which
+	// the executor attaches once it has set up the task's egress proxy
+	networkDisabled := task == nil || !task.HasNetworkAccess()
+
 	return SecurityConfig{
 		User:            c.Security.ExecutionUser,
 		NoNewPrivileges: true,
 		ReadOnlyRootfs:  true,
-		NetworkDisabled: true,
+		NetworkDisabled: networkDisabled,
 		SecurityOpts:    securityOpts,
 		TmpfsMounts: map[string]string{
 			"/tmp":     "rw,noexec,nosuid,size=100m",
@@ -230,6 +555,24 @@ func (c *Config) GetTimeoutForTask(task *models.Task) time.Duration {
 
 // Validate validates the executor configuration
 func (c *Config) Validate() error {
+	switch c.Backend {
+	case "", BackendDocker, BackendPodman, BackendProcess:
+	default:
+		return ErrInvalidConfigField("backend", fmt.Sprintf("unsupported container backend %q", c.Backend))
+	}
+
+	for scriptType := range c.Runtimes.ByScriptType {
+		if err := models.ValidateScriptType(scriptType); err != nil {
+			return ErrInvalidConfigField("runtimes", err.Error())
+		}
+	}
+
+	for level := range c.Runtimes.BySecurityLevel {
+		if err := models.ValidateSecurityLevel(level); err != nil {
+			return ErrInvalidConfigField("runtimes", err.Error())
+		}
+	}
+
 	if c.DefaultResourceLimits.MemoryLimitBytes <= 0 {
 		return ErrInvalidConfig("memory limit must be positive")
 	}
@@ -254,6 +597,36 @@ func (c *Config) Validate() error {
 		return ErrInvalidConfig("Bash image must be specified")
 	}
 
+	for _, size := range []int{c.Images.PythonPoolSize, c.Images.BashPoolSize, c.Images.JavaScriptPoolSize, c.Images.GoPoolSize} {
+		if size < 0 || size > MaxWarmPoolSize {
+			return ErrInvalidConfigField("images", fmt.Sprintf("warm pool size must be between 0 and %d", MaxWarmPoolSize))
+		}
+	}
+
+	for _, repository := range c.Images.AllowedRepositories {
+		if _, err := parseRepositoryPattern(repository); err != nil {
+			return ErrInvalidConfigField("images", err.Error())
+		}
+	}
+
+	for _, cidr := range c.Network.InternalCIDRs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			return ErrInvalidConfigField("network", fmt.Sprintf("invalid internal CIDR %q", cidr))
+		}
+	}
+
+	if c.Output.MaxBytes <= 0 {
+		return ErrInvalidConfigField("output", "maximum output bytes must be positive")
+	}
+
+	if c.Output.MaxLines < 0 {
+		return ErrInvalidConfigField("output", "maximum output lines must not be negative")
+	}
+
+	if c.Output.SpillDir != "" && c.Output.MaxSpillBytes < int64(c.Output.MaxBytes) {
+		return ErrInvalidConfigField("output", "maximum spilled bytes must be at least the maximum output bytes")
+	}
+
 	// Validate security limits
 	if c.Security.MaxMemoryLimitBytes <= 0 {
 		return ErrInvalidConfig("maximum memory limit must be positive")
@@ -271,6 +644,12 @@ func (c *Config) Validate() error {
 		return ErrInvalidConfig("maximum timeout must be positive")
 	}
 
+	if c.Security.ScriptAnalysisMode != "" {
+		if err := models.ValidateScriptAnalysisMode(c.Security.ScriptAnalysisMode); err != nil {
+			return ErrInvalidConfigField("security", err.Error())
+		}
+	}
+
 	// Validate that default limits don't exceed security caps
 	if c.DefaultResourceLimits.MemoryLimitBytes > c.Security.MaxMemoryLimitBytes {
 		return ErrInvalidConfig("default memory limit exceeds security maximum")
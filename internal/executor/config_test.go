@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -13,6 +14,7 @@ func TestNewDefaultConfig(t *testing.T) {
 	config := NewDefaultConfig()
 
 	assert.NotNil(t, config)
+	assert.Equal(t, BackendDocker, config.Backend)
 	assert.Equal(t, "unix:///var/run/docker.sock", config.DockerEndpoint)
 	assert.Equal(t, int64(128*1024*1024), config.DefaultResourceLimits.MemoryLimitBytes)
 	assert.Equal(t, int64(50000), config.DefaultResourceLimits.CPUQuota)
@@ -24,6 +26,45 @@ func TestNewDefaultConfig(t *testing.T) {
 	assert.Equal(t, "1000:1000", config.Security.ExecutionUser)
 }
 
+func TestNewConfig(t *testing.T) {
+	executorConfig := NewConfig(&config.ExecutorConfig{
+		Backend:                  BackendPodman,
+		DefaultMemoryLimitMB:     256,
+		DefaultCPUQuota:          100000,
+		DefaultPidsLimit:         64,
+		DefaultTimeoutSeconds:    60,
+		PythonImage:              "python:3.12-alpine",
+		PythonPoolSize:           2,
+		ExecutionUser:            "2000:2000",
+		ScriptAnalysisMode:       "warn",
+		DefaultRuntime:           "runsc",
+		AllowedImageRepositories: []string{"python"},
+		OutputMaxBytes:           1024,
+		OutputSpillDir:           "/var/lib/voidrunner/output",
+	})
+
+	assert.Equal(t, BackendPodman, executorConfig.Backend)
+	assert.Equal(t, ResourceLimits{
+		MemoryLimitBytes: 256 * 1024 * 1024,
+		CPUQuota:         100000,
+		PidsLimit:        64,
+		TimeoutSeconds:   60,
+	}, executorConfig.DefaultResourceLimits)
+	assert.Equal(t, 60, executorConfig.DefaultTimeoutSeconds)
+	assert.Equal(t, "python:3.12-alpine", executorConfig.Images.Python)
+	assert.Equal(t, 2, executorConfig.Images.PythonPoolSize)
+	assert.Equal(t, []string{"python"}, executorConfig.Images.AllowedRepositories)
+	assert.Equal(t, "2000:2000", executorConfig.Security.ExecutionUser)
+	assert.Equal(t, models.ScriptAnalysisModeWarn, executorConfig.Security.ScriptAnalysisMode)
+	assert.Equal(t, "runsc", executorConfig.Runtimes.Default)
+	assert.Equal(t, 1024, executorConfig.Output.MaxBytes)
+	assert.Equal(t, "/var/lib/voidrunner/output", executorConfig.Output.SpillDir)
+
+	defaults := NewDefaultConfig()
+	assert.Equal(t, defaults.Security.MaxMemoryLimitBytes, executorConfig.Security.MaxMemoryLimitBytes, "unset settings are defaulted")
+	assert.Equal(t, defaults.Output.MaxSpillBytes, executorConfig.Output.MaxSpillBytes)
+}
+
 func TestConfig_GetImageForScriptType(t *testing.T) {
 	config := NewDefaultConfig()
 
@@ -198,6 +239,12 @@ func TestConfig_Validate(t *testing.T) {
 			config:    NewDefaultConfig(),
 			expectErr: false,
 		},
+		{
+			name:      "Unsupported backend",
+			config:    &Config{Backend: "lxc"},
+			expectErr: true,
+			errMsg:    "unsupported container backend",
+		},
 		{
 			name: "Invalid memory limit",
 			config: &Config{
@@ -300,6 +347,24 @@ func TestConfig_Validate(t *testing.T) {
 			expectErr: true,
 			errMsg:    "Bash image must be specified",
 		},
+		{
+			name: "Warm pool too large",
+			config: &Config{
+				DefaultResourceLimits: ResourceLimits{
+					MemoryLimitBytes: 128 * 1024 * 1024,
+					CPUQuota:         50000,
+					PidsLimit:        128,
+				},
+				DefaultTimeoutSeconds: 300,
+				Images: ImageConfig{
+					Python:         "python:3.11-alpine",
+					Bash:           "alpine:latest",
+					PythonPoolSize: MaxWarmPoolSize + 1,
+				},
+			},
+			expectErr: true,
+			errMsg:    "warm pool size must be between 0 and",
+		},
 	}
 
 	for _, tt := range tests {
@@ -314,3 +379,87 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_GetPoolSizeForScriptType(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Images.PythonPoolSize = 4
+	config.Images.BashPoolSize = 2
+
+	assert.Equal(t, 4, config.GetPoolSizeForScriptType(models.ScriptTypePython))
+	assert.Equal(t, 2, config.GetPoolSizeForScriptType(models.ScriptTypeBash))
+	assert.Zero(t, config.GetPoolSizeForScriptType(models.ScriptTypeJavaScript))
+	assert.Zero(t, config.GetPoolSizeForScriptType(models.ScriptType("unknown")))
+}
+
+func TestConfig_GetRuntimeForTask(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Runtimes = NewRuntimeSettings("runc",
+		map[string]string{"python": "runsc-python"},
+		map[string]string{"sandboxed": "runsc"},
+	)
+
+	tests := []struct {
+		name     string
+		task     *models.Task
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "unset level uses script type mapping",
+			task:     &models.Task{ScriptType: models.ScriptTypePython},
+			expected: "runsc-python",
+		},
+		{
+			name:     "standard level falls back to default",
+			task:     &models.Task{ScriptType: models.ScriptTypeBash, SecurityLevel: models.SecurityLevelStandard},
+			expected: "runc",
+		},
+		{
+			name:     "security level takes precedence over script type",
+			task:     &models.Task{ScriptType: models.ScriptTypePython, SecurityLevel: models.SecurityLevelSandboxed},
+			expected: "runsc",
+		},
+		{
+			name:    "unmapped level fails closed",
+			task:    &models.Task{ScriptType: models.ScriptTypeBash, SecurityLevel: models.SecurityLevelIsolated},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime, err := config.GetRuntimeForTask(tt.task)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "no runtime configured for security level")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, runtime)
+		})
+	}
+
+	assert.Equal(t, []string{"runc", "runsc", "runsc-python"}, config.Runtimes.Configured())
+	assert.Empty(t, NewDefaultConfig().Runtimes.Configured())
+}
+
+func TestDefaultPodmanEndpoint(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	assert.Equal(t, "unix:///run/user/1000/podman/podman.sock", DefaultPodmanEndpoint())
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	assert.Contains(t, DefaultPodmanEndpoint(), "/podman/podman.sock")
+}
+
+func TestConfig_ApplyDefaults(t *testing.T) {
+	config := &Config{
+		Security: SecuritySettings{MaxPidsLimit: 50},
+	}
+	config.ApplyDefaults()
+
+	defaults := NewDefaultConfig()
+	assert.Equal(t, defaults.Security.MaxMemoryLimitBytes, config.Security.MaxMemoryLimitBytes)
+	assert.Equal(t, int64(50), config.Security.MaxPidsLimit, "explicit values are kept")
+	assert.Equal(t, defaults.Security.AllowedSyscalls, config.Security.AllowedSyscalls)
+	assert.Equal(t, defaults.Sandbox.MaskedPaths, config.Sandbox.MaskedPaths)
+}
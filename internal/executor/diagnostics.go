@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// signalNames maps Linux signal numbers to their names. Scripts always run on
+// Linux, whatever platform the executor itself runs on.
+var signalNames = map[int]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	5:  "SIGTRAP",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	10: "SIGUSR1",
+	11: "SIGSEGV",
+	12: "SIGUSR2",
+	13: "SIGPIPE",
+	14: "SIGALRM",
+	15: "SIGTERM",
+	24: "SIGXCPU",
+	25: "SIGXFSZ",
+	31: "SIGSYS",
+}
+
+// maxSignal is the highest Linux signal number
+const maxSignal = 64
+
+// signalName returns the name of a Linux signal, e.g. "SIGKILL"
+func signalName(signal int) string {
+	if name, ok := signalNames[signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("SIG%d", signal)
+}
+
+// exitCodeSignal returns the signal encoded in a shell-style exit code
+// (128 + N for a process killed by signal N), or 0 for a normal exit
+func exitCodeSignal(exitCode int) int {
+	if exitCode > 128 && exitCode <= 128+maxSignal {
+		return exitCode - 128
+	}
+	return 0
+}
+
+// recordExit stores how the script exited in the result. A signal of 0 means
+// the script exited on its own.
+func (r *ExecutionResult) recordExit(exitCode, signal int, oomKilled bool) {
+	r.ReturnCode = &exitCode
+	r.OOMKilled = oomKilled
+	if signal > 0 {
+		r.ExitSignal = stringPtr(signalName(signal))
+	}
+
+	if exitCode == 0 {
+		r.Status = models.ExecutionStatusCompleted
+		return
+	}
+
+	r.Status = models.ExecutionStatusFailed
+	if oomKilled {
+		r.setErrorCategory(models.ErrorCategoryOutOfMemory)
+	} else {
+		r.setErrorCategory(models.ErrorCategoryScript)
+	}
+}
+
+// recordTimeout marks the result as timed out during the given phase
+func (r *ExecutionResult) recordTimeout(phase models.TimeoutPhase) {
+	r.Status = models.ExecutionStatusTimeout
+	r.TimeoutPhase = timeoutPhasePtr(phase)
+	r.setErrorCategory(models.ErrorCategoryTimeout)
+}
+
+// recordSetupFailure marks the result as failed before the script ran.
+// Running out of time while pulling the image or starting the container is
+// reported as a timeout of that phase.
+func (r *ExecutionResult) recordSetupFailure(ctx context.Context, phase models.TimeoutPhase, err error) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		r.recordTimeout(phase)
+		return
+	}
+	r.Status = models.ExecutionStatusFailed
+	r.setErrorCategory(CategorizeError(err))
+}
+
+// configErrorCategory classifies an error building an execution's
+// configuration, which is a configuration error unless known otherwise
+func configErrorCategory(err error) models.ExecutionErrorCategory {
+	if category := CategorizeError(err); category != models.ErrorCategoryInternal {
+		return category
+	}
+	return models.ErrorCategoryConfig
+}
+
+// setErrorCategory sets the reason the execution did not complete
+func (r *ExecutionResult) setErrorCategory(category models.ExecutionErrorCategory) {
+	r.ErrorCategory = categoryPtr(category)
+}
+
+// categoryPtr returns a pointer to the given error category
+func categoryPtr(category models.ExecutionErrorCategory) *models.ExecutionErrorCategory {
+	return &category
+}
+
+// timeoutPhasePtr returns a pointer to the given timeout phase
+func timeoutPhasePtr(phase models.TimeoutPhase) *models.TimeoutPhase {
+	return &phase
+}
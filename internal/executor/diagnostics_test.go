@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func TestExitCodeSignal(t *testing.T) {
+	assert.Equal(t, 0, exitCodeSignal(0))
+	assert.Equal(t, 0, exitCodeSignal(1))
+	assert.Equal(t, 0, exitCodeSignal(128))
+	assert.Equal(t, 9, exitCodeSignal(137))
+	assert.Equal(t, 15, exitCodeSignal(143))
+	assert.Equal(t, 0, exitCodeSignal(255))
+
+	assert.Equal(t, "SIGKILL", signalName(9))
+	assert.Equal(t, "SIG40", signalName(40))
+}
+
+func TestExecutor_ExecuteExitDiagnostics(t *testing.T) {
+	// waitForDeadline makes a mocked call block until the execution times out
+	waitForDeadline := func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}
+
+	tests := []struct {
+		name              string
+		timeout           time.Duration
+		mockSetup         func(*MockContainerClient)
+		expectedStatus    models.ExecutionStatus
+		expectedOOMKilled bool
+		expectedSignal    *string
+		expectedPhase     *models.TimeoutPhase
+		expectedCategory  *models.ExecutionErrorCategory
+	}{
+		{
+			name:    "completed execution has no diagnostics",
+			timeout: 30 * time.Second,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
+				m.On("StartContainer", mock.Anything, "container123").Return(nil)
+				m.On("WaitContainer", mock.Anything, "container123").Return(0, nil)
+				m.On("InspectContainerExit", mock.Anything, "container123").Return(&ContainerExitState{ExitCode: 0}, nil)
+				m.On("StreamContainerLogs", mock.Anything, "container123").Return("", "", nil)
+				m.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+			},
+			expectedStatus: models.ExecutionStatusCompleted,
+		},
+		{
+			name:    "OOM-killed script",
+			timeout: 30 * time.Second,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
+				m.On("StartContainer", mock.Anything, "container123").Return(nil)
+				m.On("WaitContainer", mock.Anything, "container123").Return(137, nil)
+				m.On("InspectContainerExit", mock.Anything, "container123").Return(&ContainerExitState{ExitCode: 137, OOMKilled: true}, nil)
+				m.On("StreamContainerLogs", mock.Anything, "container123").Return("", "", nil)
+				m.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+			},
+			expectedStatus:    models.ExecutionStatusFailed,
+			expectedOOMKilled: true,
+			expectedSignal:    stringPtr("SIGKILL"),
+			expectedCategory:  categoryPtr(models.ErrorCategoryOutOfMemory),
+		},
+		{
+			name:    "script killed by a signal",
+			timeout: 30 * time.Second,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
+				m.On("StartContainer", mock.Anything, "container123").Return(nil)
+				m.On("WaitContainer", mock.Anything, "container123").Return(139, nil)
+				m.On("InspectContainerExit", mock.Anything, "container123").Return(nil, fmt.Errorf("inspect failed"))
+				m.On("StreamContainerLogs", mock.Anything, "container123").Return("", "", nil)
+				m.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+			},
+			expectedStatus:   models.ExecutionStatusFailed,
+			expectedSignal:   stringPtr("SIGSEGV"),
+			expectedCategory: categoryPtr(models.ErrorCategoryScript),
+		},
+		{
+			name:    "timeout while pulling the image",
+			timeout: 50 * time.Millisecond,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).Run(waitForDeadline).
+					Return("", NewContainerError("", "create_container", "failed to pull image",
+						fmt.Errorf("%w: %w", ErrImagePullFailed, context.DeadlineExceeded)))
+			},
+			expectedStatus:   models.ExecutionStatusTimeout,
+			expectedPhase:    timeoutPhasePtr(models.TimeoutPhaseImagePull),
+			expectedCategory: categoryPtr(models.ErrorCategoryTimeout),
+		},
+		{
+			name:    "timeout while starting the container",
+			timeout: 50 * time.Millisecond,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
+				m.On("StartContainer", mock.Anything, "container123").Run(waitForDeadline).Return(context.DeadlineExceeded)
+				m.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+			},
+			expectedStatus:   models.ExecutionStatusTimeout,
+			expectedPhase:    timeoutPhasePtr(models.TimeoutPhaseStart),
+			expectedCategory: categoryPtr(models.ErrorCategoryTimeout),
+		},
+		{
+			name:    "timeout while running",
+			timeout: 50 * time.Millisecond,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
+				m.On("StartContainer", mock.Anything, "container123").Return(nil)
+				m.On("WaitContainer", mock.Anything, "container123").Run(waitForDeadline).Return(-1, context.DeadlineExceeded)
+				m.On("StreamContainerLogs", mock.Anything, "container123").Return("", "", nil)
+				m.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+			},
+			expectedStatus:   models.ExecutionStatusTimeout,
+			expectedPhase:    timeoutPhasePtr(models.TimeoutPhaseRun),
+			expectedCategory: categoryPtr(models.ErrorCategoryTimeout),
+		},
+		{
+			name:    "image that can't be pulled",
+			timeout: 30 * time.Second,
+			mockSetup: func(m *MockContainerClient) {
+				m.On("CreateContainer", mock.Anything, mock.Anything).
+					Return("", NewContainerError("", "create_container", "failed to pull image",
+						fmt.Errorf("%w: %w", ErrImagePullFailed, fmt.Errorf("manifest unknown"))))
+			},
+			expectedStatus:   models.ExecutionStatusFailed,
+			expectedCategory: categoryPtr(models.ErrorCategoryImage),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Security.EnableSeccomp = false
+
+			client := new(MockContainerClient)
+			tt.mockSetup(client)
+
+			executor := &Executor{
+				client:          client,
+				config:          config,
+				securityManager: NewSecurityManager(config),
+				cleanupManager:  NewCleanupManager(client, slog.Default()),
+				logger:          slog.Default(),
+			}
+
+			result, _ := executor.Execute(context.Background(), &ExecutionContext{
+				Task: &models.Task{
+					BaseModel:     models.BaseModel{ID: uuid.New()},
+					ScriptType:    models.ScriptTypePython,
+					ScriptContent: "print('hello')",
+				},
+				Execution: &models.TaskExecution{ID: uuid.New()},
+				Timeout:   tt.timeout,
+			})
+			require.NotNil(t, result)
+
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Equal(t, tt.expectedOOMKilled, result.OOMKilled)
+			assert.Equal(t, tt.expectedSignal, result.ExitSignal)
+			assert.Equal(t, tt.expectedPhase, result.TimeoutPhase)
+			assert.Equal(t, tt.expectedCategory, result.ErrorCategory)
+			client.AssertExpectations(t)
+		})
+	}
+}
@@ -2,17 +2,23 @@ package executor
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/voidrunnerhq/voidrunner/internal/egress"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -117,8 +123,16 @@ func (dc *DockerClient) CreateContainer(ctx context.Context, config *ContainerCo
 		AttachStderr: true,
 	}
 
-	// Set command based on script type
-	containerConfig.Cmd = dc.buildCommand(config.ScriptType, config.ScriptContent)
+	// Set command based on script type. Warm pool containers run a launcher
+	// that waits for the script on stdin instead.
+	if config.ScriptFromStdin {
+		containerConfig.Cmd = buildLauncherCommand(config.ScriptType)
+		containerConfig.AttachStdin = true
+		containerConfig.OpenStdin = true
+		containerConfig.StdinOnce = true
+	} else {
+		containerConfig.Cmd = dc.buildCommand(config.ScriptType, config.ScriptContent)
+	}
 
 	// Build host configuration with security and resource limits
 	hostConfig := &container.HostConfig{
@@ -129,13 +143,17 @@ func (dc *DockerClient) CreateContainer(ctx context.Context, config *ContainerCo
 		},
 		SecurityOpt:    config.SecurityConfig.SecurityOpts,
 		ReadonlyRootfs: config.SecurityConfig.ReadOnlyRootfs,
-		AutoRemove:     true, // Automatically remove container when it exits
+		AutoRemove:     false, // Removed by the executor once its exit state and logs are read
 		Tmpfs:          config.SecurityConfig.TmpfsMounts,
+		Runtime:        config.Runtime,
 	}
 
-	// Disable networking if configured
+	// Disable networking if configured, otherwise join the execution's
+	// egress network
 	if config.SecurityConfig.NetworkDisabled {
 		hostConfig.NetworkMode = "none"
+	} else if config.SecurityConfig.EgressNetwork != "" {
+		hostConfig.NetworkMode = container.NetworkMode(config.SecurityConfig.EgressNetwork)
 	}
 
 	// Drop all capabilities for security
@@ -143,8 +161,16 @@ func (dc *DockerClient) CreateContainer(ctx context.Context, config *ContainerCo
 		hostConfig.CapDrop = []string{"ALL"}
 	}
 
-	// Create the container
+	// Create the container, pulling the image first if it is not present
+	// locally yet (e.g. a task's custom image)
 	resp, err := dc.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil && errdefs.IsNotFound(err) {
+		dc.logger.Info("image not present locally, pulling", "image", config.Image)
+		if pullErr := dc.PullImage(ctx, config.Image); pullErr != nil {
+			return "", NewContainerError("", "create_container", "failed to pull image", fmt.Errorf("%w: %w", ErrImagePullFailed, pullErr))
+		}
+		resp, err = dc.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	}
 	if err != nil {
 		return "", NewContainerError("", "create_container", "failed to create container", err)
 	}
@@ -173,6 +199,32 @@ func (dc *DockerClient) StartContainer(ctx context.Context, containerID string)
 	return nil
 }
 
+// SendStdin writes input to the container's stdin and closes it
+func (dc *DockerClient) SendStdin(ctx context.Context, containerID string, input string) error {
+	if err := dc.validateContainerID(containerID); err != nil {
+		return fmt.Errorf("send_stdin validation failed: %w", err)
+	}
+
+	resp, err := dc.client.ContainerAttach(ctx, containerID, container.AttachOptions{
+		Stream: true,
+		Stdin:  true,
+	})
+	if err != nil {
+		return NewContainerError(containerID, "send_stdin", "failed to attach to container", err)
+	}
+	defer resp.Close()
+
+	if _, err := io.WriteString(resp.Conn, input); err != nil {
+		return NewContainerError(containerID, "send_stdin", "failed to write to container stdin", err)
+	}
+
+	if err := resp.CloseWrite(); err != nil {
+		return NewContainerError(containerID, "send_stdin", "failed to close container stdin", err)
+	}
+
+	return nil
+}
+
 // WaitContainer waits for the container to finish and returns the exit code
 func (dc *DockerClient) WaitContainer(ctx context.Context, containerID string) (int, error) {
 	if err := dc.validateContainerID(containerID); err != nil {
@@ -198,34 +250,51 @@ func (dc *DockerClient) WaitContainer(ctx context.Context, containerID string) (
 
 // GetContainerLogs retrieves logs from the specified container
 func (dc *DockerClient) GetContainerLogs(ctx context.Context, containerID string) (stdout, stderr string, err error) {
+	var stdoutBuilder, stderrBuilder strings.Builder
+	if err := dc.StreamContainerLogs(ctx, containerID, &stdoutBuilder, &stderrBuilder); err != nil {
+		return "", "", err
+	}
+	return stdoutBuilder.String(), stderrBuilder.String(), nil
+}
+
+// StreamContainerLogs copies the logs of the specified container to the given writers
+func (dc *DockerClient) StreamContainerLogs(ctx context.Context, containerID string, stdout, stderr io.Writer) error {
+	return dc.copyContainerLogs(ctx, containerID, false, stdout, stderr)
+}
+
+// FollowContainerLogs copies the logs of the specified container to the given
+// writers as they are written, until the container stops
+func (dc *DockerClient) FollowContainerLogs(ctx context.Context, containerID string, stdout, stderr io.Writer) error {
+	return dc.copyContainerLogs(ctx, containerID, true, stdout, stderr)
+}
+
+// copyContainerLogs copies the logs of the specified container to the given
+// writers, following them until the container stops if follow is set
+func (dc *DockerClient) copyContainerLogs(ctx context.Context, containerID string, follow bool, stdout, stderr io.Writer) error {
 	if err := dc.validateContainerID(containerID); err != nil {
-		return "", "", fmt.Errorf("get_container_logs validation failed: %w", err)
+		return fmt.Errorf("get_container_logs validation failed: %w", err)
 	}
 
 	options := container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Follow:     false,
+		Follow:     follow,
 		Timestamps: false,
 	}
 
 	logs, err := dc.client.ContainerLogs(ctx, containerID, options)
 	if err != nil {
-		return "", "", NewContainerError(containerID, "get_logs", "failed to get container logs", err)
+		return NewContainerError(containerID, "get_logs", "failed to get container logs", err)
 	}
 	defer logs.Close()
 
-	// Read all logs
-	logBytes, err := io.ReadAll(logs)
-	if err != nil {
-		return "", "", NewContainerError(containerID, "get_logs", "failed to read container logs", err)
-	}
-
 	// Docker multiplexes stdout and stderr in a single stream
 	// We need to demultiplex them
-	stdout, stderr = dc.demultiplexLogs(logBytes)
+	if err := dc.demultiplexLogs(logs, stdout, stderr); err != nil {
+		return NewContainerError(containerID, "get_logs", "failed to read container logs", err)
+	}
 
-	return stdout, stderr, nil
+	return nil
 }
 
 // RemoveContainer removes the specified container
@@ -294,6 +363,11 @@ func (dc *DockerClient) Close() error {
 
 // buildCommand builds the appropriate command for the given script type and content
 func (dc *DockerClient) buildCommand(scriptType models.ScriptType, scriptContent string) []string {
+	return buildScriptCommand(scriptType, scriptContent)
+}
+
+// buildScriptCommand builds the interpreter command line for a script
+func buildScriptCommand(scriptType models.ScriptType, scriptContent string) []string {
 	switch scriptType {
 	case models.ScriptTypePython:
 		return []string{"python3", "-c", scriptContent}
@@ -311,46 +385,83 @@ func (dc *DockerClient) buildCommand(scriptType models.ScriptType, scriptContent
 	}
 }
 
-// demultiplexLogs separates stdout and stderr from Docker's multiplexed log stream
-func (dc *DockerClient) demultiplexLogs(logData []byte) (stdout, stderr string) {
-	var stdoutBuilder, stderrBuilder strings.Builder
+// buildLauncherCommand returns the command of a warm pool container: it reads
+// the script from stdin until EOF and then runs it the same way
+// buildScriptCommand would have
+func buildLauncherCommand(scriptType models.ScriptType) []string {
+	switch scriptType {
+	case models.ScriptTypeBash:
+		return []string{"sh", "-c", `script="$(cat)" && exec sh -c "$script"`}
+	case models.ScriptTypeJavaScript:
+		return []string{"sh", "-c", `script="$(cat)" && exec node -e "$script"`}
+	case models.ScriptTypeGo:
+		return []string{"sh", "-c", "cat > main.go && exec go run main.go"}
+	default:
+		return []string{"sh", "-c", `script="$(cat)" && exec python3 -c "$script"`}
+	}
+}
 
-	i := 0
-	for i < len(logData) {
-		if i+8 > len(logData) {
-			break
-		}
+// demultiplexLogs separates stdout and stderr from Docker's multiplexed log
+// stream. A truncated trailing frame is ignored.
+func (dc *DockerClient) demultiplexLogs(logs io.Reader, stdout, stderr io.Writer) error {
+	header := make([]byte, 8)
+	var frame []byte
 
+	for {
 		// Docker log format: [STREAM_TYPE][RESERVED][SIZE][DATA]
 		// STREAM_TYPE: 1 byte (0=stdin, 1=stdout, 2=stderr)
 		// RESERVED: 3 bytes
 		// SIZE: 4 bytes (big-endian)
 		// DATA: SIZE bytes
+		if _, err := io.ReadFull(logs, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
 
-		streamType := logData[i]
-		// Skip reserved bytes (i+1, i+2, i+3)
-		size := int(logData[i+4])<<24 | int(logData[i+5])<<16 | int(logData[i+6])<<8 | int(logData[i+7])
-
-		dataStart := i + 8
-		dataEnd := dataStart + size
+		streamType := header[0]
+		// Skip reserved bytes (1, 2, 3)
+		size := int(binary.BigEndian.Uint32(header[4:8]))
 
-		if dataEnd > len(logData) {
-			break
+		if cap(frame) < size {
+			frame = make([]byte, size)
+		}
+		frame = frame[:size]
+		if _, err := io.ReadFull(logs, frame); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
 		}
 
-		data := string(logData[dataStart:dataEnd])
-
+		var err error
 		switch streamType {
 		case 1: // stdout
-			stdoutBuilder.WriteString(data)
+			_, err = stdout.Write(frame)
 		case 2: // stderr
-			stderrBuilder.WriteString(data)
+			_, err = stderr.Write(frame)
+		}
+		if err != nil {
+			return err
 		}
+	}
+}
 
-		i = dataEnd
+// InspectContainerExit returns how the specified container's process exited
+func (dc *DockerClient) InspectContainerExit(ctx context.Context, containerID string) (*ContainerExitState, error) {
+	info, err := dc.GetContainerInfo(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	if info.State == nil {
+		return nil, NewContainerError(containerID, "inspect_exit", "container has no state", nil)
 	}
 
-	return stdoutBuilder.String(), stderrBuilder.String()
+	return &ContainerExitState{
+		ExitCode:  info.State.ExitCode,
+		OOMKilled: info.State.OOMKilled,
+	}, nil
 }
 
 // GetContainerInfo returns information about a container
@@ -434,3 +545,133 @@ func (dc *DockerClient) GetDockerVersion(ctx context.Context) (interface{}, erro
 
 	return version, nil
 }
+
+// ListRuntimes returns the names of the OCI runtimes registered with the daemon
+func (dc *DockerClient) ListRuntimes(ctx context.Context) ([]string, error) {
+	info, err := dc.client.Info(ctx)
+	if err != nil {
+		return nil, NewExecutorError("list_runtimes", "failed to get Docker info", err)
+	}
+
+	runtimes := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		runtimes = append(runtimes, name)
+	}
+	sort.Strings(runtimes)
+
+	return runtimes, nil
+}
+
+// ResolveImageDigest returns the registry digest an image reference points to
+func (dc *DockerClient) ResolveImageDigest(ctx context.Context, imageRef string) (string, error) {
+	if imageRef == "" {
+		return "", NewExecutorError("resolve_image_digest", "image name is empty", nil)
+	}
+
+	inspect, err := dc.client.DistributionInspect(ctx, imageRef, "")
+	if err != nil {
+		if errdefs.IsNotFound(err) || errdefs.IsUnauthorized(err) {
+			return "", NewExecutorError("resolve_image_digest", fmt.Sprintf("image %s not found in registry", imageRef), ErrImageNotFound)
+		}
+		return "", NewExecutorError("resolve_image_digest", "failed to inspect image in registry", err)
+	}
+
+	return inspect.Descriptor.Digest.String(), nil
+}
+
+// CreateNetwork creates a bridge network and returns its ID
+func (dc *DockerClient) CreateNetwork(ctx context.Context, name string, internal bool) (string, error) {
+	if name == "" {
+		return "", NewExecutorError("create_network", "network name is empty", nil)
+	}
+
+	resp, err := dc.client.NetworkCreate(ctx, name, network.CreateOptions{
+		Driver:   "bridge",
+		Internal: internal,
+		Labels:   map[string]string{"voidrunner.egress": "true"},
+	})
+	if err != nil {
+		return "", NewExecutorError("create_network", "failed to create network", err)
+	}
+
+	if resp.Warning != "" {
+		dc.logger.Warn("network creation warning", "network", name, "warning", resp.Warning)
+	}
+
+	return resp.ID, nil
+}
+
+// RemoveNetwork removes the specified network
+func (dc *DockerClient) RemoveNetwork(ctx context.Context, networkID string) error {
+	if networkID == "" {
+		return NewExecutorError("remove_network", "network ID is empty", nil)
+	}
+
+	if err := dc.client.NetworkRemove(ctx, networkID); err != nil {
+		// Don't fail if network is already removed
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+		return NewExecutorError("remove_network", "failed to remove network", err)
+	}
+
+	return nil
+}
+
+// CreateEgressProxy creates an egress proxy container. The proxy is created on
+// the default bridge network, which gives it a route out, and then connected
+// to the execution's internal network.
+func (dc *DockerClient) CreateEgressProxy(ctx context.Context, config *EgressProxyConfig) (string, error) {
+	if config == nil || config.Image == "" || config.NetworkID == "" {
+		return "", NewExecutorError("create_egress_proxy", "egress proxy config is incomplete", nil)
+	}
+
+	policy, err := json.Marshal(config.Policy)
+	if err != nil {
+		return "", NewExecutorError("create_egress_proxy", "failed to encode network policy", err)
+	}
+
+	containerConfig := &container.Config{
+		Image: config.Image,
+		User:  "65534:65534",
+		Env: []string{
+			"EGRESS_POLICY=" + string(policy),
+			fmt.Sprintf("EGRESS_LISTEN_ADDR=:%d", egress.DefaultPort),
+		},
+	}
+
+	pidsLimit := int64(64)
+	hostConfig := &container.HostConfig{
+		Resources: container.Resources{
+			Memory:    64 * 1024 * 1024,
+			CPUQuota:  25000,
+			PidsLimit: &pidsLimit,
+		},
+		SecurityOpt:    []string{"no-new-privileges"},
+		ReadonlyRootfs: true,
+		CapDrop:        []string{"ALL"},
+		NetworkMode:    "bridge",
+	}
+
+	resp, err := dc.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil && errdefs.IsNotFound(err) {
+		dc.logger.Info("egress proxy image not present locally, pulling", "image", config.Image)
+		if pullErr := dc.PullImage(ctx, config.Image); pullErr != nil {
+			return "", NewContainerError("", "create_egress_proxy", "failed to pull image", pullErr)
+		}
+		resp, err = dc.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	}
+	if err != nil {
+		return "", NewContainerError("", "create_egress_proxy", "failed to create container", err)
+	}
+
+	endpoint := &network.EndpointSettings{Aliases: []string{EgressProxyAlias}}
+	if err := dc.client.NetworkConnect(ctx, config.NetworkID, resp.ID, endpoint); err != nil {
+		if removeErr := dc.RemoveContainer(ctx, resp.ID, true); removeErr != nil {
+			dc.logger.Error("failed to remove egress proxy", "error", removeErr)
+		}
+		return "", NewContainerError(resp.ID, "create_egress_proxy", "failed to connect egress proxy to network", err)
+	}
+
+	return resp.ID, nil
+}
@@ -1,7 +1,10 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
@@ -90,6 +93,47 @@ func TestDockerClient_buildCommand(t *testing.T) {
 	}
 }
 
+func TestBuildLauncherCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		scriptType  models.ScriptType
+		interpreter string
+		script      string
+		expected    string
+	}{
+		{
+			name:        "Bash script",
+			scriptType:  models.ScriptTypeBash,
+			interpreter: "sh",
+			script:      "echo \"$((1 + 2))\" 'quoted \"args\"'\n",
+			expected:    "3 quoted \"args\"\n",
+		},
+		{
+			name:        "Python script",
+			scriptType:  models.ScriptTypePython,
+			interpreter: "python3",
+			script:      "print('a')\nprint(\"b $HOME\")\n",
+			expected:    "a\nb $HOME\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exec.LookPath(tt.interpreter); err != nil {
+				t.Skipf("%s not available", tt.interpreter)
+			}
+
+			command := buildLauncherCommand(tt.scriptType)
+			cmd := exec.Command(command[0], command[1:]...)
+			cmd.Stdin = strings.NewReader(tt.script)
+
+			output, err := cmd.Output()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
 func TestDockerClient_demultiplexLogs(t *testing.T) {
 	config := NewDefaultConfig()
 	client := &DockerClient{
@@ -159,9 +203,11 @@ func TestDockerClient_demultiplexLogs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			stdout, stderr := client.demultiplexLogs(tt.logData)
-			assert.Equal(t, tt.expectedStdout, stdout)
-			assert.Equal(t, tt.expectedStderr, stderr)
+			var stdout, stderr strings.Builder
+			err := client.demultiplexLogs(bytes.NewReader(tt.logData), &stdout, &stderr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStdout, stdout.String())
+			assert.Equal(t, tt.expectedStderr, stderr.String())
 		})
 	}
 }
@@ -0,0 +1,173 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/egress"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const (
+	// DefaultEgressProxyImage is the egress proxy image used when none is configured
+	DefaultEgressProxyImage = "voidrunner/egress-proxy:latest"
+
+	// EgressProxyAlias is the host name of the egress proxy on an execution network
+	EgressProxyAlias = "egress-proxy"
+
+	// egressProxyReadyTimeout bounds how long the proxy may take to start
+	egressProxyReadyTimeout = 10 * time.Second
+
+	// maxNetworkEvents caps the connection attempts recorded per execution
+	maxNetworkEvents = 1000
+)
+
+// egressNetworkName returns the name of an execution's internal network
+func egressNetworkName(executionID uuid.UUID) string {
+	return "voidrunner-exec-" + executionID.String()
+}
+
+// attachEgressNetwork puts a container with network access on the execution's
+// internal network and points it at the egress proxy
+func attachEgressNetwork(config *ContainerConfig, executionID uuid.UUID) {
+	config.SecurityConfig.EgressNetwork = egressNetworkName(executionID)
+
+	proxyURL := fmt.Sprintf("http://%s:%d", EgressProxyAlias, egress.DefaultPort)
+	config.Environment = append(config.Environment,
+		"HTTP_PROXY="+proxyURL,
+		"HTTPS_PROXY="+proxyURL,
+		"http_proxy="+proxyURL,
+		"https_proxy="+proxyURL,
+	)
+}
+
+// egressSession is the internal network and egress proxy of one execution
+type egressSession struct {
+	client    NetworkClient
+	networkID string
+	proxyID   string
+}
+
+// startEgressSession creates the execution's internal network and starts its
+// egress proxy, returning once the proxy accepts connections
+func (e *Executor) startEgressSession(ctx context.Context, config *ContainerConfig, execCtx *ExecutionContext, logger *slog.Logger) (*egressSession, error) {
+	networkClient, ok := e.client.(NetworkClient)
+	if !ok {
+		return nil, NewSecurityError("start_egress_session", "container backend does not support network policies", nil)
+	}
+
+	networkID, err := networkClient.CreateNetwork(ctx, config.SecurityConfig.EgressNetwork, true)
+	if err != nil {
+		return nil, err
+	}
+	session := &egressSession{client: networkClient, networkID: networkID}
+
+	task := execCtx.Task
+	proxyID, err := networkClient.CreateEgressProxy(ctx, &EgressProxyConfig{
+		Image:     e.config.Network.EgressProxyImage,
+		NetworkID: networkID,
+		Policy: egress.Policy{
+			Mode:          task.NetworkMode,
+			Allowlist:     task.NetworkAllowlist,
+			InternalCIDRs: e.config.Network.InternalCIDRs,
+		},
+	})
+	if err != nil {
+		e.stopEgressSession(session, execCtx.Execution.ID, logger)
+		return nil, err
+	}
+	session.proxyID = proxyID
+
+	// Track the proxy so cancelling the execution removes it as well
+	if err := e.cleanupManager.RegisterContainer(proxyID, task.ID, execCtx.Execution.ID, e.config.Network.EgressProxyImage); err != nil {
+		logger.Error("failed to register egress proxy for tracking", "error", err)
+	}
+
+	if err := e.client.StartContainer(ctx, proxyID); err != nil {
+		e.stopEgressSession(session, execCtx.Execution.ID, logger)
+		return nil, err
+	}
+
+	if err := e.waitEgressProxyReady(ctx, proxyID); err != nil {
+		e.stopEgressSession(session, execCtx.Execution.ID, logger)
+		return nil, err
+	}
+
+	logger.Debug("egress proxy ready", "network_mode", task.NetworkMode)
+	return session, nil
+}
+
+// waitEgressProxyReady polls the proxy's output for its ready message
+func (e *Executor) waitEgressProxyReady(ctx context.Context, proxyID string) error {
+	ctx, cancel := context.WithTimeout(ctx, egressProxyReadyTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		stdout, _, err := e.client.GetContainerLogs(ctx, proxyID)
+		if err == nil && strings.Contains(stdout, egress.ReadyMessage) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return NewContainerError(proxyID, "start_egress_session", "egress proxy did not become ready", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopEgressSession collects the connection attempts logged by the proxy and
+// removes the proxy and the network. The execution's container must already
+// be removed, as a network can't be removed while containers are attached.
+func (e *Executor) stopEgressSession(session *egressSession, executionID uuid.UUID, logger *slog.Logger) []models.NetworkEvent {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var events []models.NetworkEvent
+	if session.proxyID != "" {
+		stdout, _, err := e.client.GetContainerLogs(ctx, session.proxyID)
+		if err != nil {
+			logger.Error("failed to get egress proxy logs", "error", err)
+		}
+		events = networkEvents(egress.ParseEvents(stdout), executionID)
+
+		if err := e.client.RemoveContainer(ctx, session.proxyID, true); err != nil {
+			logger.Error("failed to remove egress proxy", "error", err)
+		}
+		e.cleanupManager.UnregisterContainer(session.proxyID)
+	}
+
+	if err := session.client.RemoveNetwork(ctx, session.networkID); err != nil {
+		logger.Error("failed to remove execution network", "error", err)
+	}
+
+	return events
+}
+
+// networkEvents converts proxy events to network event records, keeping the
+// first maxNetworkEvents
+func networkEvents(events []egress.Event, executionID uuid.UUID) []models.NetworkEvent {
+	if len(events) > maxNetworkEvents {
+		events = events[:maxNetworkEvents]
+	}
+
+	records := make([]models.NetworkEvent, 0, len(events))
+	for _, event := range events {
+		records = append(records, models.NetworkEvent{
+			ExecutionID: executionID,
+			Host:        event.Host,
+			Port:        event.Port,
+			Allowed:     event.Allowed,
+			Reason:      event.Reason,
+			OccurredAt:  event.Time,
+		})
+	}
+	return records
+}
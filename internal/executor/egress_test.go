@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/egress"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// MockNetworkContainerClient is a container client that also manages networks
+type MockNetworkContainerClient struct {
+	MockContainerClient
+}
+
+func (m *MockNetworkContainerClient) CreateNetwork(ctx context.Context, name string, internal bool) (string, error) {
+	args := m.Called(ctx, name, internal)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockNetworkContainerClient) RemoveNetwork(ctx context.Context, networkID string) error {
+	args := m.Called(ctx, networkID)
+	return args.Error(0)
+}
+
+func (m *MockNetworkContainerClient) CreateEgressProxy(ctx context.Context, config *EgressProxyConfig) (string, error) {
+	args := m.Called(ctx, config)
+	return args.String(0), args.Error(1)
+}
+
+func newNetworkTestExecutionContext() *ExecutionContext {
+	return &ExecutionContext{
+		Task: &models.Task{
+			BaseModel:        models.BaseModel{ID: uuid.New()},
+			ScriptType:       models.ScriptTypePython,
+			ScriptContent:    "import urllib.request",
+			NetworkMode:      models.NetworkModeAllowlist,
+			NetworkAllowlist: []string{"pypi.org"},
+		},
+		Execution: &models.TaskExecution{ID: uuid.New()},
+		Context:   context.Background(),
+		Timeout:   30 * time.Second,
+	}
+}
+
+func newNetworkTestExecutor(client ContainerClient) *Executor {
+	config := NewDefaultConfig()
+	config.Security.EnableSeccomp = false
+
+	return &Executor{
+		client:          client,
+		config:          config,
+		securityManager: NewSecurityManager(config),
+		cleanupManager:  NewCleanupManager(nil, nil),
+		logger:          slog.Default(),
+	}
+}
+
+func TestExecutor_ExecuteWithEgressPolicy(t *testing.T) {
+	execCtx := newNetworkTestExecutionContext()
+	networkName := egressNetworkName(execCtx.Execution.ID)
+	proxyLogs := egress.ReadyMessage + "\n" +
+		`{"time":"2026-01-02T03:04:05Z","host":"pypi.org","port":443,"allowed":true,"reason":"host allowed"}` + "\n" +
+		`{"time":"2026-01-02T03:04:06Z","host":"169.254.169.254","port":80,"allowed":false,"reason":"address not allowed"}` + "\n"
+
+	client := new(MockNetworkContainerClient)
+	client.On("CreateNetwork", mock.Anything, networkName, true).Return("network00001", nil)
+	client.On("CreateEgressProxy", mock.Anything, mock.MatchedBy(func(config *EgressProxyConfig) bool {
+		return config.NetworkID == "network00001" &&
+			config.Image == DefaultEgressProxyImage &&
+			config.Policy.Mode == models.NetworkModeAllowlist &&
+			assert.ObjectsAreEqual([]string{"pypi.org"}, config.Policy.Allowlist)
+	})).Return("proxy0000001", nil)
+	client.On("StartContainer", mock.Anything, "proxy0000001").Return(nil)
+	client.On("GetContainerLogs", mock.Anything, "proxy0000001").Return(proxyLogs, "", nil)
+	client.On("CreateContainer", mock.Anything, mock.MatchedBy(func(config *ContainerConfig) bool {
+		return !config.SecurityConfig.NetworkDisabled &&
+			config.SecurityConfig.EgressNetwork == networkName &&
+			assert.ObjectsAreEqual(true, containsString(config.Environment, "HTTPS_PROXY=http://egress-proxy:3128"))
+	})).Return("container123", nil)
+	client.On("StartContainer", mock.Anything, "container123").Return(nil)
+	client.On("WaitContainer", mock.Anything, "container123").Return(0, nil)
+	client.On("InspectContainerExit", mock.Anything, "container123").Return(&ContainerExitState{ExitCode: 0}, nil)
+	client.On("StreamContainerLogs", mock.Anything, "container123").Return("ok", "", nil)
+	client.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+	client.On("RemoveContainer", mock.Anything, "proxy0000001", true).Return(nil)
+	client.On("RemoveNetwork", mock.Anything, "network00001").Return(nil)
+
+	executor := newNetworkTestExecutor(client)
+
+	result, err := executor.Execute(context.Background(), execCtx)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, result.Status)
+
+	require.Len(t, result.NetworkEvents, 2)
+	assert.Equal(t, execCtx.Execution.ID, result.NetworkEvents[0].ExecutionID)
+	assert.Equal(t, "pypi.org", result.NetworkEvents[0].Host)
+	assert.True(t, result.NetworkEvents[0].Allowed)
+	assert.False(t, result.NetworkEvents[1].Allowed)
+	client.AssertExpectations(t)
+}
+
+func TestExecutor_ExecuteWithEgressPolicyUnsupported(t *testing.T) {
+	client := new(MockContainerClient)
+	executor := newNetworkTestExecutor(client)
+
+	result, err := executor.Execute(context.Background(), newNetworkTestExecutionContext())
+	require.Error(t, err)
+	assert.Equal(t, models.ExecutionStatusFailed, result.Status)
+	assert.Contains(t, err.Error(), "does not support network policies")
+	client.AssertNotCalled(t, "CreateContainer", mock.Anything, mock.Anything)
+}
+
+func TestExecutor_ExecuteWithEgressProxyFailure(t *testing.T) {
+	execCtx := newNetworkTestExecutionContext()
+
+	client := new(MockNetworkContainerClient)
+	client.On("CreateNetwork", mock.Anything, mock.Anything, true).Return("network00001", nil)
+	client.On("CreateEgressProxy", mock.Anything, mock.Anything).Return("", assert.AnError)
+	client.On("RemoveNetwork", mock.Anything, "network00001").Return(nil)
+
+	executor := newNetworkTestExecutor(client)
+
+	result, err := executor.Execute(context.Background(), execCtx)
+	require.Error(t, err)
+	assert.Equal(t, models.ExecutionStatusFailed, result.Status)
+	client.AssertNotCalled(t, "CreateContainer", mock.Anything, mock.Anything)
+	client.AssertExpectations(t)
+}
+
+func TestNetworkEvents_Capped(t *testing.T) {
+	events := make([]egress.Event, maxNetworkEvents+10)
+	for i := range events {
+		events[i] = egress.Event{Host: "pypi.org", Port: 443, Allowed: true}
+	}
+
+	assert.Len(t, networkEvents(events, uuid.New()), maxNetworkEvents)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,11 @@
 package executor
 
 import (
+	"context"
 	"errors"
 	"fmt"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 // Common executor errors
@@ -31,6 +34,18 @@ var (
 	// ErrImageNotFound indicates that a container image was not found
 	ErrImageNotFound = errors.New("container image not found")
 
+	// ErrImagePullFailed indicates that a container image could not be pulled
+	ErrImagePullFailed = errors.New("container image pull failed")
+
+	// ErrInvalidImage indicates a malformed container image reference
+	ErrInvalidImage = errors.New("invalid container image reference")
+
+	// ErrImageNotAllowed indicates an image outside the allowed repositories
+	ErrImageNotAllowed = errors.New("container image repository is not allowed")
+
+	// ErrCustomImagesUnsupported indicates an executor that can't run task images
+	ErrCustomImagesUnsupported = errors.New("custom images are not supported by this executor")
+
 	// ErrPermissionDenied indicates insufficient permissions
 	ErrPermissionDenied = errors.New("permission denied")
 
@@ -187,3 +202,32 @@ func IsConfigError(err error) bool {
 	var confErr *ConfigError
 	return errors.As(err, &confErr)
 }
+
+// CategorizeError classifies an executor error for the execution record, so
+// that users and retry policies can tell failures apart
+func CategorizeError(err error) models.ExecutionErrorCategory {
+	switch {
+	case IsTimeoutError(err) || errors.Is(err, context.DeadlineExceeded):
+		return models.ErrorCategoryTimeout
+	case IsCancelledError(err) || errors.Is(err, context.Canceled):
+		return models.ErrorCategoryCancelled
+	case IsSecurityError(err):
+		return models.ErrorCategorySecurity
+	case IsConfigError(err) || errors.Is(err, ErrInvalidScriptType):
+		return models.ErrorCategoryConfig
+	case errors.Is(err, ErrImageNotFound) || errors.Is(err, ErrInvalidImage) ||
+		errors.Is(err, ErrImageNotAllowed) || errors.Is(err, ErrImagePullFailed) ||
+		errors.Is(err, ErrCustomImagesUnsupported):
+		return models.ErrorCategoryImage
+	case IsDockerError(err):
+		return models.ErrorCategoryRuntime
+	case IsResourceError(err):
+		return models.ErrorCategoryResource
+	case errors.Is(err, ErrNetworkUnavailable):
+		return models.ErrorCategoryNetwork
+	case errors.Is(err, ErrPermissionDenied):
+		return models.ErrorCategoryPermission
+	default:
+		return models.ErrorCategoryInternal
+	}
+}
@@ -1,10 +1,13 @@
 package executor
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 func TestExecutorError(t *testing.T) {
@@ -211,6 +214,81 @@ func TestErrorTypeCheckers(t *testing.T) {
 	})
 }
 
+func TestCategorizeError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected models.ExecutionErrorCategory
+	}{
+		{
+			name:     "timeout",
+			err:      ErrExecutionTimeout,
+			expected: models.ErrorCategoryTimeout,
+		},
+		{
+			name:     "context deadline",
+			err:      fmt.Errorf("wait: %w", context.DeadlineExceeded),
+			expected: models.ErrorCategoryTimeout,
+		},
+		{
+			name:     "cancelled",
+			err:      ErrExecutionCancelled,
+			expected: models.ErrorCategoryCancelled,
+		},
+		{
+			name:     "security",
+			err:      NewSecurityError("validate", "blocked", nil),
+			expected: models.ErrorCategorySecurity,
+		},
+		{
+			name:     "config",
+			err:      ErrInvalidConfigField("image", "empty"),
+			expected: models.ErrorCategoryConfig,
+		},
+		{
+			name:     "image pull",
+			err:      NewContainerError("", "create_container", "failed to pull image", fmt.Errorf("%w: %w", ErrImagePullFailed, errors.New("denied"))),
+			expected: models.ErrorCategoryImage,
+		},
+		{
+			name:     "image not found",
+			err:      ErrImageNotFound,
+			expected: models.ErrorCategoryImage,
+		},
+		{
+			name:     "docker unavailable",
+			err:      NewExecutorError("execute", "failed", ErrDockerUnavailable),
+			expected: models.ErrorCategoryRuntime,
+		},
+		{
+			name:     "resources exhausted",
+			err:      ErrResourceExhausted,
+			expected: models.ErrorCategoryResource,
+		},
+		{
+			name:     "network",
+			err:      ErrNetworkUnavailable,
+			expected: models.ErrorCategoryNetwork,
+		},
+		{
+			name:     "permission",
+			err:      ErrPermissionDenied,
+			expected: models.ErrorCategoryPermission,
+		},
+		{
+			name:     "unknown",
+			err:      errors.New("other error"),
+			expected: models.ErrorCategoryInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CategorizeError(tt.err))
+		})
+	}
+}
+
 func TestErrorWrapping(t *testing.T) {
 	baseErr := errors.New("base error")
 
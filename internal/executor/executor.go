@@ -2,8 +2,10 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,8 @@ type Executor struct {
 	config          *Config
 	securityManager *SecurityManager
 	cleanupManager  *CleanupManager
+	warmPool        *WarmPool
+	outputStore     *OutputStore
 	logger          *slog.Logger
 }
 
@@ -29,29 +33,113 @@ func NewExecutor(config *Config, logger *slog.Logger) (*Executor, error) {
 		logger = slog.Default()
 	}
 
-	// Create Docker client
-	dockerClient, err := NewDockerClient(config, logger)
+	// Create container client for the configured backend
+	containerClient, err := newContainerClient(config, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+		return nil, err
 	}
 
 	// Create security manager
 	securityManager := NewSecurityManager(config)
 
 	// Create cleanup manager
-	cleanupManager := NewCleanupManager(dockerClient, logger)
+	cleanupManager := NewCleanupManager(containerClient, logger)
 
 	executor := &Executor{
-		client:          dockerClient,
+		client:          containerClient,
 		config:          config,
 		securityManager: securityManager,
 		cleanupManager:  cleanupManager,
 		logger:          logger,
 	}
+	if config.Output.SpillDir != "" {
+		executor.outputStore = NewOutputStore(config.Output.SpillDir)
+	}
+
+	// Start the warm pool for images that have one configured
+	executor.warmPool = executor.newWarmPool()
+	if executor.warmPool != nil {
+		executor.warmPool.Start()
+	}
 
 	return executor, nil
 }
 
+// newWarmPool creates the warm pool from the configured pool sizes. Pooled
+// containers are created with the default resource limits, so only
+// executions using those limits are served from the pool.
+func (e *Executor) newWarmPool() *WarmPool {
+	templates := make(map[models.ScriptType]*ContainerConfig)
+	sizes := make(map[models.ScriptType]int)
+
+	scriptTypes := []models.ScriptType{
+		models.ScriptTypePython,
+		models.ScriptTypeBash,
+		models.ScriptTypeJavaScript,
+		models.ScriptTypeGo,
+	}
+	for _, scriptType := range scriptTypes {
+		size := e.config.GetPoolSizeForScriptType(scriptType)
+		if size <= 0 {
+			continue
+		}
+
+		task := &models.Task{ScriptType: scriptType, SecurityLevel: models.SecurityLevelStandard}
+		template, err := e.buildContainerConfig(task, e.config.DefaultResourceLimits, 0)
+		if err != nil {
+			e.logger.Warn("warm pool disabled for image", "script_type", scriptType, "error", err)
+			continue
+		}
+
+		templates[scriptType] = template
+		sizes[scriptType] = size
+	}
+
+	if len(templates) == 0 {
+		return nil
+	}
+
+	return NewWarmPool(e.client, templates, sizes, e.logger)
+}
+
+// NewTaskExecutor creates the task executor for the configured backend: the
+// process sandbox for the process backend and the container executor otherwise
+func NewTaskExecutor(config *Config, logger *slog.Logger) (TaskExecutor, error) {
+	if config != nil && config.Backend == BackendProcess {
+		processExecutor, err := NewProcessExecutor(config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create process executor: %w", err)
+		}
+		return processExecutor, nil
+	}
+
+	containerExecutor, err := NewExecutor(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return containerExecutor, nil
+}
+
+// newContainerClient creates the container client for the configured backend
+func newContainerClient(config *Config, logger *slog.Logger) (ContainerClient, error) {
+	switch config.Backend {
+	case BackendPodman:
+		podmanClient, err := NewPodmanClient(config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Podman client: %w", err)
+		}
+		return podmanClient, nil
+	case "", BackendDocker:
+		dockerClient, err := NewDockerClient(config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Docker client: %w", err)
+		}
+		return dockerClient, nil
+	default:
+		return nil, ErrInvalidConfigField("backend", fmt.Sprintf("unsupported container backend %q", config.Backend))
+	}
+}
+
 // Execute runs the given task and returns the execution result
 func (e *Executor) Execute(ctx context.Context, execCtx *ExecutionContext) (*ExecutionResult, error) {
 	if execCtx == nil || execCtx.Task == nil {
@@ -68,11 +156,12 @@ func (e *Executor) Execute(ctx context.Context, execCtx *ExecutionContext) (*Exe
 	logger.Info("starting task execution")
 
 	// Validate script content for security
-	if err := e.securityManager.ValidateScriptContent(task.ScriptContent, task.ScriptType); err != nil {
+	if err := e.securityManager.ValidateTaskScript(task); err != nil {
 		logger.Error("script security validation failed", "error", err)
 		return &ExecutionResult{
-			Status: models.ExecutionStatusFailed,
-			Stderr: stringPtr(fmt.Sprintf("Security validation failed: %s", err.Error())),
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Security validation failed: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategorySecurity),
 		}, err
 	}
 
@@ -81,17 +170,24 @@ func (e *Executor) Execute(ctx context.Context, execCtx *ExecutionContext) (*Exe
 	if err != nil {
 		logger.Error("failed to build container configuration", "error", err)
 		return &ExecutionResult{
-			Status: models.ExecutionStatusFailed,
-			Stderr: stringPtr(fmt.Sprintf("Configuration error: %s", err.Error())),
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Configuration error: %s", err.Error())),
+			ErrorCategory: categoryPtr(configErrorCategory(err)),
 		}, err
 	}
 
+	// Executions with network access run on their own internal network
+	if !containerConfig.SecurityConfig.NetworkDisabled && execCtx.Execution != nil {
+		attachEgressNetwork(containerConfig, execCtx.Execution.ID)
+	}
+
 	// Validate container configuration
 	if err := e.securityManager.ValidateContainerConfig(containerConfig); err != nil {
 		logger.Error("container configuration validation failed", "error", err)
 		return &ExecutionResult{
-			Status: models.ExecutionStatusFailed,
-			Stderr: stringPtr(fmt.Sprintf("Security validation failed: %s", err.Error())),
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Security validation failed: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategorySecurity),
 		}, err
 	}
 
@@ -110,8 +206,9 @@ func (e *Executor) Execute(ctx context.Context, execCtx *ExecutionContext) (*Exe
 		logger.Error("container execution failed", "error", err)
 		if result == nil {
 			return &ExecutionResult{
-				Status: models.ExecutionStatusFailed,
-				Stderr: stringPtr(fmt.Sprintf("Execution error: %s", err.Error())),
+				Status:        models.ExecutionStatusFailed,
+				Stderr:        stringPtr(fmt.Sprintf("Execution error: %s", err.Error())),
+				ErrorCategory: categoryPtr(CategorizeError(err)),
 			}, err
 		}
 	}
@@ -132,12 +229,31 @@ func (e *Executor) executeContainer(ctx context.Context, config *ContainerConfig
 		Status:    models.ExecutionStatusRunning,
 		StartedAt: &startTime,
 	}
+	if config.Runtime != "" {
+		result.Runtime = stringPtr(config.Runtime)
+	}
 
-	// Create container
-	logger.Debug("creating container", "image", config.Image)
-	containerID, err := e.client.CreateContainer(ctx, config)
+	// Set up the egress proxy before the container can make any connection.
+	// The teardown is deferred first so it runs after the container is removed.
+	if config.SecurityConfig.EgressNetwork != "" {
+		session, err := e.startEgressSession(ctx, config, execCtx, logger)
+		if err != nil {
+			result.recordSetupFailure(ctx, models.TimeoutPhaseStart, err)
+			return result, NewExecutorError("execute_container", "failed to set up network egress", err)
+		}
+		defer func() {
+			result.NetworkEvents = e.stopEgressSession(session, execCtx.Execution.ID, logger)
+		}()
+	}
+
+	// Lease a warm container, or create one
+	containerID, warm, err := e.acquireContainer(ctx, config, logger)
 	if err != nil {
-		result.Status = models.ExecutionStatusFailed
+		phase := models.TimeoutPhaseStart
+		if errors.Is(err, ErrImagePullFailed) {
+			phase = models.TimeoutPhaseImagePull
+		}
+		result.recordSetupFailure(ctx, phase, err)
 		return result, NewExecutorError("execute_container", "failed to create container", err)
 	}
 
@@ -159,16 +275,36 @@ func (e *Executor) executeContainer(ctx context.Context, config *ContainerConfig
 		}
 	}()
 
-	// Start container
-	logger.Debug("starting container")
-	if err := e.client.StartContainer(ctx, containerID); err != nil {
-		result.Status = models.ExecutionStatusFailed
-		return result, NewExecutorError("execute_container", "failed to start container", err)
+	// Start container (warm containers are already running the script)
+	if !warm {
+		logger.Debug("starting container")
+		if err := e.client.StartContainer(ctx, containerID); err != nil {
+			result.recordSetupFailure(ctx, models.TimeoutPhaseStart, err)
+			return result, NewExecutorError("execute_container", "failed to start container", err)
+		}
+	}
+
+	if e.warmPool != nil {
+		e.warmPool.RecordStartup(config.ScriptType, warm, time.Since(startTime))
 	}
 
 	// Mark container as started
 	e.cleanupManager.MarkContainerStarted(containerID)
 
+	// Follow the logs while the container runs when the output is streamed,
+	// otherwise they are read once it has finished
+	output := newExecutionOutput(e.config.Output, e.outputStore, executionID(execCtx), logger)
+	output.streamTo(execCtx.Stdout, execCtx.Stderr)
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	defer stopFollowing()
+	var followDone chan error
+	if output.streaming() {
+		followDone = make(chan error, 1)
+		go func() {
+			followDone <- e.client.FollowContainerLogs(followCtx, containerID, output.Stdout, output.Stderr)
+		}()
+	}
+
 	// Wait for container to finish
 	logger.Debug("waiting for container to complete")
 	exitCode, err := e.client.WaitContainer(ctx, containerID)
@@ -180,38 +316,42 @@ func (e *Executor) executeContainer(ctx context.Context, config *ContainerConfig
 
 	if err != nil {
 		if IsTimeoutError(err) || ctx.Err() == context.DeadlineExceeded {
-			result.Status = models.ExecutionStatusTimeout
+			result.recordTimeout(models.TimeoutPhaseRun)
 			logger.Warn("container execution timed out")
 		} else if IsCancelledError(err) || ctx.Err() == context.Canceled {
 			result.Status = models.ExecutionStatusCancelled
+			result.setErrorCategory(models.ErrorCategoryCancelled)
 			logger.Info("container execution cancelled")
 		} else {
 			result.Status = models.ExecutionStatusFailed
+			result.setErrorCategory(CategorizeError(err))
 			logger.Error("container execution failed", "error", err)
 		}
 	} else {
-		result.ReturnCode = &exitCode
-		if exitCode == 0 {
-			result.Status = models.ExecutionStatusCompleted
-		} else {
-			result.Status = models.ExecutionStatusFailed
-		}
+		e.recordContainerExit(ctx, result, containerID, exitCode, logger)
 	}
 
 	// Get container logs
-	logger.Debug("retrieving container logs")
-	stdout, stderr, logErr := e.client.GetContainerLogs(ctx, containerID)
+	var logErr error
+	if followDone != nil {
+		// A container that didn't finish is still running, so stop following
+		// it and keep the output read so far
+		if err != nil {
+			stopFollowing()
+		}
+		logErr = <-followDone
+		if followCtx.Err() != nil {
+			logErr = nil
+		}
+	} else {
+		logger.Debug("retrieving container logs")
+		logErr = e.client.StreamContainerLogs(ctx, containerID, output.Stdout, output.Stderr)
+	}
+	output.finish(result, logger)
 	if logErr != nil {
 		logger.Error("failed to get container logs", "error", logErr)
 		// Don't fail the execution just because we couldn't get logs
-		stderr = fmt.Sprintf("Failed to retrieve logs: %s", logErr.Error())
-	}
-
-	if stdout != "" {
-		result.Stdout = &stdout
-	}
-	if stderr != "" {
-		result.Stderr = &stderr
+		result.Stderr = stringPtr(fmt.Sprintf("Failed to retrieve logs: %s", logErr.Error()))
 	}
 
 	// Mark container as completed with final status
@@ -220,10 +360,65 @@ func (e *Executor) executeContainer(ctx context.Context, config *ContainerConfig
 	return result, err
 }
 
+// recordContainerExit stores how the container's script exited in the
+// result. Without the container's exit state, the signal is decoded from
+// the exit code alone.
+func (e *Executor) recordContainerExit(ctx context.Context, result *ExecutionResult, containerID string, exitCode int, logger *slog.Logger) {
+	state, err := e.client.InspectContainerExit(ctx, containerID)
+	if err != nil {
+		logger.Warn("failed to inspect container exit state", "error", err)
+		state = &ContainerExitState{ExitCode: exitCode}
+	}
+
+	if state.OOMKilled {
+		logger.Warn("container was killed for exceeding its memory limit")
+	}
+	result.recordExit(exitCode, exitCodeSignal(exitCode), state.OOMKilled)
+}
+
+// acquireContainer returns a container for the execution. A container leased
+// from the warm pool is already running and has been sent the script, which
+// is reported by warm; otherwise a new container is created but not started.
+func (e *Executor) acquireContainer(ctx context.Context, config *ContainerConfig, logger *slog.Logger) (string, bool, error) {
+	if e.warmPool != nil {
+		if containerID, ok := e.warmPool.Acquire(config); ok {
+			err := e.client.SendStdin(ctx, containerID, config.ScriptContent)
+			if err == nil {
+				logger.Debug("leased warm container", "image", config.Image)
+				return containerID, true, nil
+			}
+			logger.Warn("warm container unusable, creating a new one", "error", err)
+
+			removeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := e.client.RemoveContainer(removeCtx, containerID, true); err != nil {
+				logger.Error("failed to remove unusable warm container", "error", err)
+			}
+			cancel()
+		}
+	}
+
+	logger.Debug("creating container", "image", config.Image, "runtime", config.Runtime)
+	containerID, err := e.client.CreateContainer(ctx, config)
+	return containerID, false, err
+}
+
 // buildContainerConfig creates a container configuration for the given task
 func (e *Executor) buildContainerConfig(task *models.Task, resourceLimits ResourceLimits, timeout time.Duration) (*ContainerConfig, error) {
-	// Get appropriate image for script type
+	// Get appropriate image for script type, unless the task names its own
 	image := e.config.GetImageForScriptType(task.ScriptType)
+	if task.Image != nil {
+		image = task.PinnedImage()
+		if image == "" {
+			return nil, NewExecutorError("build_container_config",
+				fmt.Sprintf("image %s is not pinned to a digest", *task.Image), ErrInvalidImage)
+		}
+	}
+
+	// Resolve the OCI runtime for the task's security level and script type
+	runtime, err := e.config.GetRuntimeForTask(task)
+	if err != nil {
+		return nil, fmt.Errorf("runtime selection failed: %w", err)
+	}
 
 	// Validate image security
 	if err := e.securityManager.CheckImageSecurity(image); err != nil {
@@ -244,12 +439,7 @@ func (e *Executor) buildContainerConfig(task *models.Task, resourceLimits Resour
 	}
 
 	// Sanitize environment variables
-	environment := e.securityManager.SanitizeEnvironment([]string{
-		"PATH=/usr/local/bin:/usr/bin:/bin",
-		"HOME=/tmp",
-		"USER=executor",
-		"PYTHONIOENCODING=utf-8",
-	})
+	environment := e.securityManager.SanitizeEnvironment(baseEnvironment())
 
 	config := &ContainerConfig{
 		Image:          image,
@@ -260,11 +450,22 @@ func (e *Executor) buildContainerConfig(task *models.Task, resourceLimits Resour
 		ResourceLimits: resourceLimits,
 		SecurityConfig: securityConfig,
 		Timeout:        timeout,
+		Runtime:        runtime,
 	}
 
 	return config, nil
 }
 
+// baseEnvironment returns the environment every script is executed with
+func baseEnvironment() []string {
+	return []string{
+		"PATH=/usr/local/bin:/usr/bin:/bin",
+		"HOME=/tmp",
+		"USER=executor",
+		"PYTHONIOENCODING=utf-8",
+	}
+}
+
 // Cancel cancels a running execution
 func (e *Executor) Cancel(ctx context.Context, executionID uuid.UUID) error {
 	logger := e.logger.With("execution_id", executionID.String(), "operation", "cancel")
@@ -282,9 +483,9 @@ func (e *Executor) Cancel(ctx context.Context, executionID uuid.UUID) error {
 
 // IsHealthy checks if the executor is healthy and ready to execute tasks
 func (e *Executor) IsHealthy(ctx context.Context) error {
-	// Check Docker client health
+	// Check container client health
 	if err := e.client.IsHealthy(ctx); err != nil {
-		return fmt.Errorf("Docker client health check failed: %w", err)
+		return fmt.Errorf("container client health check failed: %w", err)
 	}
 
 	// Check if required images are available
@@ -305,6 +506,39 @@ func (e *Executor) IsHealthy(ctx context.Context) error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	// Check that every configured runtime is registered with the daemon
+	if err := e.checkRuntimesAvailable(ctx); err != nil {
+		return fmt.Errorf("runtime check failed: %w", err)
+	}
+
+	return nil
+}
+
+// checkRuntimesAvailable verifies the configured OCI runtimes are registered
+// with the daemon, so tasks don't fail one by one at container creation
+func (e *Executor) checkRuntimesAvailable(ctx context.Context) error {
+	configured := e.config.Runtimes.Configured()
+	if len(configured) == 0 {
+		return nil
+	}
+
+	registered, err := e.client.ListRuntimes(ctx)
+	if err != nil {
+		return err
+	}
+
+	available := make(map[string]bool, len(registered))
+	for _, runtime := range registered {
+		available[runtime] = true
+	}
+
+	for _, runtime := range configured {
+		if !available[runtime] {
+			return fmt.Errorf("runtime %q is not registered with the daemon (available: %s)",
+				runtime, strings.Join(registered, ", "))
+		}
+	}
+
 	return nil
 }
 
@@ -319,6 +553,13 @@ func (e *Executor) ensureImageAvailable(ctx context.Context, image string) error
 func (e *Executor) Cleanup(ctx context.Context) error {
 	e.logger.Info("cleaning up executor resources")
 
+	// Stop refilling the warm pool and remove its idle containers
+	if e.warmPool != nil {
+		if err := e.warmPool.Stop(ctx); err != nil {
+			e.logger.Error("failed to stop warm pool", "error", err)
+		}
+	}
+
 	// Stop cleanup manager and cleanup all containers
 	if err := e.cleanupManager.Stop(ctx); err != nil {
 		e.logger.Error("failed to stop cleanup manager", "error", err)
@@ -349,15 +590,33 @@ func (e *Executor) GetExecutorInfo(ctx context.Context) (*ExecutorInfo, error) {
 		DockerInfo: dockerInfo,
 		Config:     e.config,
 		IsHealthy:  e.IsHealthy(ctx) == nil,
+		WarmPools:  e.WarmPoolStats(),
 	}, nil
 }
 
+// WarmPoolStats returns warm pool statistics per pooled image
+func (e *Executor) WarmPoolStats() []WarmPoolStats {
+	if e.warmPool == nil {
+		return nil
+	}
+	return e.warmPool.Stats()
+}
+
 // ExecutorInfo contains information about the executor
 type ExecutorInfo struct {
-	Version    string      `json:"version"`
-	DockerInfo interface{} `json:"docker_info"`
-	Config     *Config     `json:"config"`
-	IsHealthy  bool        `json:"is_healthy"`
+	Version    string          `json:"version"`
+	DockerInfo interface{}     `json:"docker_info"`
+	Config     *Config         `json:"config"`
+	IsHealthy  bool            `json:"is_healthy"`
+	WarmPools  []WarmPoolStats `json:"warm_pools,omitempty"`
+}
+
+// executionID returns the ID of the execution record, if there is one
+func executionID(execCtx *ExecutionContext) *uuid.UUID {
+	if execCtx.Execution == nil {
+		return nil
+	}
+	return &execCtx.Execution.ID
 }
 
 // Helper function to create string pointer
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
 	"testing"
 	"time"
@@ -29,16 +30,43 @@ func (m *MockContainerClient) StartContainer(ctx context.Context, containerID st
 	return args.Error(0)
 }
 
+func (m *MockContainerClient) SendStdin(ctx context.Context, containerID string, input string) error {
+	args := m.Called(ctx, containerID, input)
+	return args.Error(0)
+}
+
 func (m *MockContainerClient) WaitContainer(ctx context.Context, containerID string) (int, error) {
 	args := m.Called(ctx, containerID)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockContainerClient) InspectContainerExit(ctx context.Context, containerID string) (*ContainerExitState, error) {
+	args := m.Called(ctx, containerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ContainerExitState), args.Error(1)
+}
+
 func (m *MockContainerClient) GetContainerLogs(ctx context.Context, containerID string) (stdout, stderr string, err error) {
 	args := m.Called(ctx, containerID)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
+func (m *MockContainerClient) StreamContainerLogs(ctx context.Context, containerID string, stdout, stderr io.Writer) error {
+	args := m.Called(ctx, containerID)
+	_, _ = io.WriteString(stdout, args.String(0))
+	_, _ = io.WriteString(stderr, args.String(1))
+	return args.Error(2)
+}
+
+func (m *MockContainerClient) FollowContainerLogs(ctx context.Context, containerID string, stdout, stderr io.Writer) error {
+	args := m.Called(ctx, containerID)
+	_, _ = io.WriteString(stdout, args.String(0))
+	_, _ = io.WriteString(stderr, args.String(1))
+	return args.Error(2)
+}
+
 func (m *MockContainerClient) RemoveContainer(ctx context.Context, containerID string, force bool) error {
 	args := m.Called(ctx, containerID, force)
 	return args.Error(0)
@@ -69,6 +97,19 @@ func (m *MockContainerClient) GetDockerVersion(ctx context.Context) (interface{}
 	return args.Get(0), args.Error(1)
 }
 
+func (m *MockContainerClient) ListRuntimes(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockContainerClient) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	args := m.Called(ctx, image)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockContainerClient) GetContainerInfo(ctx context.Context, containerID string) (interface{}, error) {
 	args := m.Called(ctx, containerID)
 	return args.Get(0), args.Error(1)
@@ -161,7 +202,8 @@ func TestExecutor_Execute(t *testing.T) {
 				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
 				m.On("StartContainer", mock.Anything, "container123").Return(nil)
 				m.On("WaitContainer", mock.Anything, "container123").Return(0, nil)
-				m.On("GetContainerLogs", mock.Anything, "container123").Return("Hello, World!", "", nil)
+				m.On("InspectContainerExit", mock.Anything, "container123").Return(&ContainerExitState{ExitCode: 0}, nil)
+				m.On("StreamContainerLogs", mock.Anything, "container123").Return("Hello, World!", "", nil)
 				m.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
 			},
 			expectErr:      false,
@@ -193,7 +235,8 @@ func TestExecutor_Execute(t *testing.T) {
 				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container456", nil)
 				m.On("StartContainer", mock.Anything, "container456").Return(nil)
 				m.On("WaitContainer", mock.Anything, "container456").Return(0, nil)
-				m.On("GetContainerLogs", mock.Anything, "container456").Return("Hello, World!", "", nil)
+				m.On("InspectContainerExit", mock.Anything, "container456").Return(&ContainerExitState{ExitCode: 0}, nil)
+				m.On("StreamContainerLogs", mock.Anything, "container456").Return("Hello, World!", "", nil)
 				m.On("RemoveContainer", mock.Anything, "container456", true).Return(nil)
 			},
 			expectErr:      false,
@@ -225,7 +268,8 @@ func TestExecutor_Execute(t *testing.T) {
 				m.On("CreateContainer", mock.Anything, mock.Anything).Return("container789", nil)
 				m.On("StartContainer", mock.Anything, "container789").Return(nil)
 				m.On("WaitContainer", mock.Anything, "container789").Return(1, nil)
-				m.On("GetContainerLogs", mock.Anything, "container789").Return("", "", nil)
+				m.On("InspectContainerExit", mock.Anything, "container789").Return(&ContainerExitState{ExitCode: 1}, nil)
+				m.On("StreamContainerLogs", mock.Anything, "container789").Return("", "", nil)
 				m.On("RemoveContainer", mock.Anything, "container789", true).Return(nil)
 			},
 			expectErr:      false,
@@ -315,7 +359,7 @@ func TestExecutor_Execute(t *testing.T) {
 				m.On("CreateContainer", mock.Anything, mock.Anything).Return("containerDEF", nil)
 				m.On("StartContainer", mock.Anything, "containerDEF").Return(nil)
 				m.On("WaitContainer", mock.Anything, "containerDEF").Return(-1, context.DeadlineExceeded)
-				m.On("GetContainerLogs", mock.Anything, "containerDEF").Return("", "", nil)
+				m.On("StreamContainerLogs", mock.Anything, "containerDEF").Return("", "", nil)
 				m.On("RemoveContainer", mock.Anything, "containerDEF", true).Return(nil)
 			},
 			expectErr:      true,
@@ -450,6 +494,38 @@ func TestExecutor_IsHealthy(t *testing.T) {
 	mockClient2.AssertExpectations(t)
 }
 
+func TestExecutor_IsHealthyRuntimes(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Runtimes.BySecurityLevel = map[models.TaskSecurityLevel]string{
+		models.SecurityLevelSandboxed: "runsc",
+	}
+	executor := &Executor{
+		config:          config,
+		securityManager: NewSecurityManager(config),
+		cleanupManager:  NewCleanupManager(nil, nil),
+		logger:          slog.Default(),
+	}
+
+	mockClient := new(MockContainerClient)
+	mockClient.On("IsHealthy", mock.Anything).Return(nil)
+	mockClient.On("ListRuntimes", mock.Anything).Return([]string{"runc", "runsc"}, nil)
+	executor.client = mockClient
+
+	assert.NoError(t, executor.IsHealthy(context.Background()))
+	mockClient.AssertExpectations(t)
+
+	// A runtime the daemon doesn't know about fails the health check
+	mockClient2 := new(MockContainerClient)
+	mockClient2.On("IsHealthy", mock.Anything).Return(nil)
+	mockClient2.On("ListRuntimes", mock.Anything).Return([]string{"runc"}, nil)
+	executor.client = mockClient2
+
+	err := executor.IsHealthy(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `runtime "runsc" is not registered`)
+	mockClient2.AssertExpectations(t)
+}
+
 func TestExecutor_Cleanup(t *testing.T) {
 	config := NewDefaultConfig()
 	executor := &Executor{
@@ -533,4 +609,16 @@ func TestExecutor_buildContainerConfig(t *testing.T) {
 	assert.Equal(t, 300*time.Second, containerConfig.Timeout)
 	assert.NotEmpty(t, containerConfig.Environment)
 	assert.Equal(t, "/tmp/workspace", containerConfig.WorkingDir)
+	assert.Empty(t, containerConfig.Runtime, "daemon default runtime when none is configured")
+
+	// Sandboxed tasks get the mapped runtime, and fail closed without one
+	task.SecurityLevel = models.SecurityLevelSandboxed
+	_, err = executor.buildContainerConfig(task, resourceLimits, 300*time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "runtime selection failed")
+
+	config.Runtimes.BySecurityLevel = map[models.TaskSecurityLevel]string{models.SecurityLevelSandboxed: "runsc"}
+	containerConfig, err = executor.buildContainerConfig(task, resourceLimits, 300*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "runsc", containerConfig.Runtime)
 }
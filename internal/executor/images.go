@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// repositoryPattern is a normalized entry of the repository allowlist
+type repositoryPattern struct {
+	// name is the fully qualified repository, e.g. "docker.io/library/python"
+	name string
+
+	// namespace is set for "/*" patterns, which match every repository below name
+	namespace bool
+}
+
+// parseRepositoryPattern normalizes an allowlist entry so that e.g. "python",
+// "library/python" and "docker.io/library/python" all match the same images
+func parseRepositoryPattern(pattern string) (repositoryPattern, error) {
+	pattern = strings.TrimSpace(pattern)
+
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		// Parse a placeholder repository in the namespace so the registry
+		// and Docker Hub defaults are applied exactly as for image names
+		named, err := reference.ParseNormalizedNamed(prefix + "/x")
+		if err != nil {
+			return repositoryPattern{}, fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+		return repositoryPattern{name: strings.TrimSuffix(named.Name(), "/x"), namespace: true}, nil
+	}
+
+	named, err := reference.ParseNormalizedNamed(pattern)
+	if err != nil {
+		return repositoryPattern{}, fmt.Errorf("invalid repository %q: %w", pattern, err)
+	}
+	if !reference.IsNameOnly(named) {
+		return repositoryPattern{}, fmt.Errorf("repository %q must not include a tag or digest", pattern)
+	}
+
+	return repositoryPattern{name: named.Name()}, nil
+}
+
+// matches reports whether the fully qualified repository matches the pattern
+func (p repositoryPattern) matches(repository string) bool {
+	if p.namespace {
+		return strings.HasPrefix(repository, p.name+"/")
+	}
+	return repository == p.name
+}
+
+// IsImageRepositoryAllowed reports whether the image's repository is in the
+// allowlist of repositories tasks may use as their custom image
+func (c *Config) IsImageRepositoryAllowed(image string) bool {
+	return MatchImageRepository(c.Images.AllowedRepositories, image)
+}
+
+// ValidateRepositoryPattern validates a repository allowlist entry: either an
+// exact repository or a namespace pattern ending in "/*"
+func ValidateRepositoryPattern(pattern string) error {
+	_, err := parseRepositoryPattern(pattern)
+	return err
+}
+
+// MatchImageRepository reports whether the image's repository matches one of
+// the repository allowlist entries. Invalid entries match nothing.
+func MatchImageRepository(patterns []string, image string) bool {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range patterns {
+		pattern, err := parseRepositoryPattern(entry)
+		if err != nil {
+			continue
+		}
+		if pattern.matches(named.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveImage checks a task image against the repository allowlist and pins
+// it to the digest it currently resolves to in its registry
+func (e *Executor) ResolveImage(ctx context.Context, image string) (*ResolvedImage, error) {
+	named, err := reference.ParseNormalizedNamed(strings.TrimSpace(image))
+	if err != nil {
+		return nil, NewExecutorError("resolve_image", fmt.Sprintf("invalid image %q", image), ErrInvalidImage)
+	}
+	named = reference.TagNameOnly(named)
+	familiar := reference.FamiliarString(named)
+
+	if !e.config.IsImageRepositoryAllowed(familiar) {
+		return nil, NewSecurityError("resolve_image",
+			fmt.Sprintf("repository %s is not in the allowed list", reference.FamiliarName(named)), ErrImageNotAllowed)
+	}
+
+	digest, err := e.client.ResolveImageDigest(ctx, familiar)
+	if err != nil {
+		return nil, err
+	}
+
+	// An image named by digest must resolve to that same digest
+	if canonical, ok := named.(reference.Canonical); ok && canonical.Digest().String() != digest {
+		return nil, NewExecutorError("resolve_image",
+			fmt.Sprintf("registry returned digest %s for %s", digest, familiar), ErrImageNotFound)
+	}
+
+	return &ResolvedImage{Image: familiar, Digest: digest}, nil
+}
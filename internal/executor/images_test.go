@@ -0,0 +1,145 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const testImageDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestConfig_IsImageRepositoryAllowed(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Images.AllowedRepositories = []string{"python", "ghcr.io/acme/*", "registry.example.com:5000/tools/runner"}
+
+	tests := []struct {
+		image   string
+		allowed bool
+	}{
+		{"python:3.12-slim", true},
+		{"docker.io/library/python:3.12", true},
+		{"library/python@" + testImageDigest, true},
+		{"pythonista:latest", false},
+		{"ghcr.io/acme/runner:v1", true},
+		{"ghcr.io/acme/team/runner", true},
+		{"ghcr.io/acme", false},
+		{"ghcr.io/acme-evil/runner", false},
+		{"registry.example.com:5000/tools/runner:1.0", true},
+		{"registry.example.com/tools/runner:1.0", false},
+		{"node:20", false},
+		{"Invalid Image", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, config.IsImageRepositoryAllowed(tt.image))
+		})
+	}
+}
+
+func TestConfig_ValidateAllowedRepositories(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Images.AllowedRepositories = []string{"python:3.12"}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not include a tag or digest")
+}
+
+func newTestImageExecutor(client *MockContainerClient) *Executor {
+	config := NewDefaultConfig()
+	config.Security.EnableSeccomp = false
+	config.Images.AllowedRepositories = []string{"python", "ghcr.io/acme/*"}
+
+	return &Executor{
+		client:          client,
+		config:          config,
+		securityManager: NewSecurityManager(config),
+		cleanupManager:  NewCleanupManager(nil, nil),
+		logger:          slog.Default(),
+	}
+}
+
+func TestExecutor_ResolveImage(t *testing.T) {
+	t.Run("pins allowed image to its digest", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+		mockClient.On("ResolveImageDigest", mock.Anything, "python:latest").Return(testImageDigest, nil)
+		executor := newTestImageExecutor(mockClient)
+
+		resolved, err := executor.ResolveImage(context.Background(), "docker.io/library/python")
+		require.NoError(t, err)
+		assert.Equal(t, "python:latest", resolved.Image)
+		assert.Equal(t, testImageDigest, resolved.Digest)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("rejects repository outside the allowlist", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+		executor := newTestImageExecutor(mockClient)
+
+		_, err := executor.ResolveImage(context.Background(), "node:20")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrImageNotAllowed)
+		mockClient.AssertNotCalled(t, "ResolveImageDigest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects malformed reference", func(t *testing.T) {
+		executor := newTestImageExecutor(new(MockContainerClient))
+
+		_, err := executor.ResolveImage(context.Background(), "Python:3.12")
+		assert.ErrorIs(t, err, ErrInvalidImage)
+	})
+
+	t.Run("rejects digest the registry does not serve", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+		mockClient.On("ResolveImageDigest", mock.Anything, mock.Anything).
+			Return("sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", nil)
+		executor := newTestImageExecutor(mockClient)
+
+		_, err := executor.ResolveImage(context.Background(), "ghcr.io/acme/runner@"+testImageDigest)
+		assert.ErrorIs(t, err, ErrImageNotFound)
+	})
+
+	t.Run("propagates registry errors", func(t *testing.T) {
+		mockClient := new(MockContainerClient)
+		mockClient.On("ResolveImageDigest", mock.Anything, mock.Anything).Return("", assert.AnError)
+		executor := newTestImageExecutor(mockClient)
+
+		_, err := executor.ResolveImage(context.Background(), "python:3.12")
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestExecutor_BuildContainerConfigWithCustomImage(t *testing.T) {
+	executor := newTestImageExecutor(new(MockContainerClient))
+
+	image := "python:3.12-slim"
+	digest := testImageDigest
+	task := &models.Task{
+		ScriptType:  models.ScriptTypePython,
+		Image:       &image,
+		ImageDigest: &digest,
+	}
+
+	config, err := executor.buildContainerConfig(task, executor.config.DefaultResourceLimits, 30*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "python:3.12-slim@"+testImageDigest, config.Image)
+
+	// Unpinned images are never run
+	task.ImageDigest = nil
+	_, err = executor.buildContainerConfig(task, executor.config.DefaultResourceLimits, 30*time.Second)
+	assert.ErrorIs(t, err, ErrInvalidImage)
+
+	// Images removed from the allowlist after the task was saved are rejected
+	task.ImageDigest = &digest
+	executor.config.Images.AllowedRepositories = nil
+	_, err = executor.buildContainerConfig(task, executor.config.DefaultResourceLimits, 30*time.Second)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "image security check failed")
+}
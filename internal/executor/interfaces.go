@@ -2,9 +2,11 @@ package executor
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/egress"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -22,6 +24,9 @@ type ExecutionResult struct {
 	// Standard error from the execution
 	Stderr *string
 
+	// Whether stdout or stderr exceeded the output limits and was truncated
+	Truncated bool
+
 	// Duration of the execution in milliseconds
 	ExecutionTimeMs *int
 
@@ -33,6 +38,25 @@ type ExecutionResult struct {
 
 	// Time when execution completed
 	CompletedAt *time.Time
+
+	// OCI runtime the execution ran under (nil for the daemon default)
+	Runtime *string
+
+	// Whether the script was killed for exceeding its memory limit
+	OOMKilled bool
+
+	// Signal that terminated the script, e.g. "SIGKILL"
+	ExitSignal *string
+
+	// Phase the execution was in when it timed out (timeouts only)
+	TimeoutPhase *models.TimeoutPhase
+
+	// Why the execution did not complete (nil for completed executions)
+	ErrorCategory *models.ExecutionErrorCategory
+
+	// Connection attempts made through the egress proxy (executions with
+	// network access only)
+	NetworkEvents []models.NetworkEvent
 }
 
 // ExecutionContext represents the context for executing a task
@@ -51,6 +75,13 @@ type ExecutionContext struct {
 
 	// Resource limits
 	ResourceLimits ResourceLimits
+
+	// Stdout and Stderr, when set, receive the output kept in the result
+	// while the task writes it. Only the start of a truncated stream is
+	// written, as the rest is not known until the task ends. Writes must
+	// not block.
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 // ResourceLimits defines resource constraints for execution
@@ -83,6 +114,22 @@ type TaskExecutor interface {
 	Cleanup(ctx context.Context) error
 }
 
+// ImageResolver resolves task images to the digest they currently point to
+type ImageResolver interface {
+	// ResolveImage checks an image against the repository allowlist and pins
+	// it to its current registry digest
+	ResolveImage(ctx context.Context, image string) (*ResolvedImage, error)
+}
+
+// ResolvedImage is a task image pinned to a content digest
+type ResolvedImage struct {
+	// Image is the normalized image reference, e.g. "python:3.12-slim"
+	Image string
+
+	// Digest is the content digest, e.g. "sha256:..."
+	Digest string
+}
+
 // ContainerClient defines the interface for Docker operations
 type ContainerClient interface {
 	// CreateContainer creates a new container with the specified configuration
@@ -91,12 +138,27 @@ type ContainerClient interface {
 	// StartContainer starts the specified container
 	StartContainer(ctx context.Context, containerID string) error
 
+	// SendStdin writes input to the container's stdin and closes it
+	SendStdin(ctx context.Context, containerID string, input string) error
+
 	// WaitContainer waits for the container to finish and returns the exit code
 	WaitContainer(ctx context.Context, containerID string) (int, error)
 
+	// InspectContainerExit returns how the specified container's process
+	// exited, once WaitContainer has returned
+	InspectContainerExit(ctx context.Context, containerID string) (*ContainerExitState, error)
+
 	// GetContainerLogs retrieves logs from the specified container
 	GetContainerLogs(ctx context.Context, containerID string) (stdout, stderr string, err error)
 
+	// StreamContainerLogs copies the logs of the specified container to the
+	// given writers without buffering them in memory
+	StreamContainerLogs(ctx context.Context, containerID string, stdout, stderr io.Writer) error
+
+	// FollowContainerLogs copies the logs of the specified container to the
+	// given writers as they are written, until the container stops
+	FollowContainerLogs(ctx context.Context, containerID string, stdout, stderr io.Writer) error
+
 	// RemoveContainer removes the specified container
 	RemoveContainer(ctx context.Context, containerID string, force bool) error
 
@@ -117,6 +179,48 @@ type ContainerClient interface {
 
 	// GetDockerVersion returns Docker version information
 	GetDockerVersion(ctx context.Context) (interface{}, error)
+
+	// ListRuntimes returns the names of the OCI runtimes registered with the daemon
+	ListRuntimes(ctx context.Context) ([]string, error)
+
+	// ResolveImageDigest returns the registry digest an image reference points to
+	ResolveImageDigest(ctx context.Context, image string) (string, error)
+}
+
+// ContainerExitState describes how a container's process exited
+type ContainerExitState struct {
+	// Exit code of the process; 128 + N when it was killed by signal N
+	ExitCode int
+
+	// Whether the kernel OOM killer killed a process in the container
+	OOMKilled bool
+}
+
+// NetworkClient manages the networks and egress proxies of executions with
+// network access
+type NetworkClient interface {
+	// CreateNetwork creates a bridge network and returns its ID. Internal
+	// networks have no route out of the host.
+	CreateNetwork(ctx context.Context, name string, internal bool) (string, error)
+
+	// RemoveNetwork removes the specified network
+	RemoveNetwork(ctx context.Context, networkID string) error
+
+	// CreateEgressProxy creates an egress proxy container reachable from the
+	// given network as EgressProxyAlias, and returns the container ID
+	CreateEgressProxy(ctx context.Context, config *EgressProxyConfig) (string, error)
+}
+
+// EgressProxyConfig represents the configuration for creating an egress proxy
+type EgressProxyConfig struct {
+	// Egress proxy image
+	Image string
+
+	// Internal network of the execution the proxy serves
+	NetworkID string
+
+	// Network policy the proxy enforces
+	Policy egress.Policy
 }
 
 // ContainerConfig represents the configuration for creating a container
@@ -144,6 +248,13 @@ type ContainerConfig struct {
 
 	// Execution timeout
 	Timeout time.Duration
+
+	// OCI runtime to create the container with (empty for the daemon default)
+	Runtime string
+
+	// Run a launcher that reads the script from stdin instead of passing it
+	// on the command line (warm pool containers)
+	ScriptFromStdin bool
 }
 
 // SecurityConfig represents security settings for container execution
@@ -160,6 +271,10 @@ type SecurityConfig struct {
 	// Disable network access
 	NetworkDisabled bool
 
+	// Internal network the container joins when network access is enabled.
+	// Its only way out is the execution's egress proxy.
+	EgressNetwork string
+
 	// Security options (seccomp, apparmor)
 	SecurityOpts []string
 
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"time"
 
@@ -72,6 +73,12 @@ func (m *MockExecutor) Execute(ctx context.Context, execCtx *ExecutionContext) (
 	case <-time.After(executionTime):
 		// Normal completion
 		result := m.generateMockResult(execCtx)
+		if execCtx.Stdout != nil && result.Stdout != nil {
+			_, _ = io.WriteString(execCtx.Stdout, *result.Stdout)
+		}
+		if execCtx.Stderr != nil && result.Stderr != nil {
+			_, _ = io.WriteString(execCtx.Stderr, *result.Stderr)
+		}
 		mockExec.result = result
 		mockExec.status = result.Status
 		logger.Info("mock task execution completed successfully")
@@ -87,6 +94,7 @@ func (m *MockExecutor) Execute(ctx context.Context, execCtx *ExecutionContext) (
 			ExecutionTimeMs: intPtr(int(time.Since(mockExec.startedAt).Milliseconds())),
 			StartedAt:       &mockExec.startedAt,
 			CompletedAt:     timePtr(time.Now()),
+			ErrorCategory:   categoryPtr(models.ErrorCategoryCancelled),
 		}
 		mockExec.result = result
 		mockExec.status = models.ExecutionStatusCancelled
@@ -123,6 +131,7 @@ func (m *MockExecutor) Cancel(ctx context.Context, executionID uuid.UUID) error
 			ExecutionTimeMs: intPtr(int(time.Since(mockExec.startedAt).Milliseconds())),
 			StartedAt:       &mockExec.startedAt,
 			CompletedAt:     timePtr(time.Now()),
+			ErrorCategory:   categoryPtr(models.ErrorCategoryCancelled),
 		}
 	}
 
@@ -192,13 +201,17 @@ func (m *MockExecutor) generateMockResult(execCtx *ExecutionContext) *ExecutionR
 				ExecutionTimeMs: intPtr(int(execCtx.Timeout.Milliseconds())),
 				StartedAt:       &startedAt,
 				CompletedAt:     &now,
+				TimeoutPhase:    timeoutPhasePtr(models.TimeoutPhaseRun),
+				ErrorCategory:   categoryPtr(models.ErrorCategoryTimeout),
 			}
 		}
 	}
 
 	status := models.ExecutionStatusCompleted
+	var category *models.ExecutionErrorCategory
 	if returnCode != 0 {
 		status = models.ExecutionStatusFailed
+		category = categoryPtr(models.ErrorCategoryScript)
 	}
 
 	return &ExecutionResult{
@@ -210,6 +223,7 @@ func (m *MockExecutor) generateMockResult(execCtx *ExecutionContext) *ExecutionR
 		MemoryUsageBytes: int64Ptr(1024 * 1024), // 1MB mock memory usage
 		StartedAt:        &startedAt,
 		CompletedAt:      &now,
+		ErrorCategory:    category,
 	}
 }
 
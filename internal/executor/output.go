@@ -0,0 +1,400 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultOutputMaxBytes is the default number of bytes kept per output stream
+	DefaultOutputMaxBytes = 1024 * 1024
+
+	// DefaultOutputMaxLines is the default number of lines kept per output stream
+	DefaultOutputMaxLines = 10000
+
+	// DefaultOutputMaxSpillBytes is the default number of bytes spilled per output stream
+	DefaultOutputMaxSpillBytes = 100 * 1024 * 1024
+)
+
+// Output streams of an execution
+const (
+	OutputStreamStdout = "stdout"
+	OutputStreamStderr = "stderr"
+)
+
+// IsOutputStream reports whether stream names an output stream
+func IsOutputStream(stream string) bool {
+	return stream == OutputStreamStdout || stream == OutputStreamStderr
+}
+
+// outputBuffer keeps the start and end of an output stream within the byte
+// and line caps. Memory use is bounded by the caps however much is written.
+type outputBuffer struct {
+	headBytes, tailBytes int
+	headLines, tailLines int
+	lineLimited          bool
+
+	head          []byte
+	headNewlines  int
+	headFull      bool
+	tail          []byte
+	totalBytes    int64
+	totalNewlines int64
+
+	// spill receives the whole stream, when spilling is enabled
+	spill io.Writer
+
+	// live receives the start of the stream kept in the result, when the
+	// output is streamed while the execution runs
+	live io.Writer
+}
+
+// newOutputBuffer creates a buffer splitting the caps evenly between the
+// start and end of the stream
+func newOutputBuffer(settings OutputSettings, spill io.Writer) *outputBuffer {
+	maxBytes := settings.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultOutputMaxBytes
+	}
+
+	b := &outputBuffer{
+		headBytes:   maxBytes / 2,
+		tailBytes:   maxBytes - maxBytes/2,
+		lineLimited: settings.MaxLines > 0,
+		spill:       spill,
+	}
+	if b.lineLimited {
+		b.headLines = settings.MaxLines / 2
+		b.tailLines = settings.MaxLines - b.headLines
+	}
+	return b
+}
+
+// Write implements io.Writer. Writes always succeed, so a script's output
+// can't fail its execution.
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	if b.spill != nil {
+		_, _ = b.spill.Write(p)
+	}
+	b.totalBytes += int64(len(p))
+	b.totalNewlines += int64(bytes.Count(p, []byte{'\n'}))
+
+	rest := p
+	if !b.headFull {
+		n := b.headRoom(rest)
+		b.head = append(b.head, rest[:n]...)
+		if b.live != nil && n > 0 {
+			_, _ = b.live.Write(rest[:n])
+		}
+		b.headNewlines += bytes.Count(rest[:n], []byte{'\n'})
+		rest = rest[n:]
+		b.headFull = len(rest) > 0
+	}
+
+	if len(rest) >= b.tailBytes {
+		b.tail = append(b.tail[:0], rest[len(rest)-b.tailBytes:]...)
+	} else if len(rest) > 0 {
+		b.tail = append(b.tail, rest...)
+		// Trim lazily so the tail is only copied every tailBytes bytes
+		if len(b.tail) > 2*b.tailBytes {
+			b.tail = append(b.tail[:0], b.tail[len(b.tail)-b.tailBytes:]...)
+		}
+	}
+
+	return len(p), nil
+}
+
+// headRoom returns how much of p still fits at the start of the stream
+func (b *outputBuffer) headRoom(p []byte) int {
+	n := min(len(p), b.headBytes-len(b.head))
+	if !b.lineLimited {
+		return n
+	}
+
+	newlines := b.headNewlines
+	for i := 0; i < n; i++ {
+		if newlines >= b.headLines {
+			return i
+		}
+		if p[i] == '\n' {
+			newlines++
+		}
+	}
+	return n
+}
+
+// Result returns the kept output and whether any of it was left out
+func (b *outputBuffer) Result() (string, bool) {
+	tail := b.tail
+	if len(tail) > b.tailBytes {
+		tail = tail[len(tail)-b.tailBytes:]
+	}
+	if b.lineLimited {
+		tail = lastLines(tail, b.tailLines)
+	}
+
+	if int64(len(b.head)+len(tail)) == b.totalBytes {
+		return string(b.head) + string(tail), false
+	}
+
+	// Don't split multi-byte characters at the cuts
+	head := b.head
+	if start := lastRuneStart(head); !utf8.FullRune(head[start:]) {
+		head = head[:start]
+	}
+	for i := 0; i < utf8.UTFMax-1 && len(tail) > 0 && !utf8.RuneStart(tail[0]); i++ {
+		tail = tail[1:]
+	}
+
+	omittedBytes := b.totalBytes - int64(len(head)+len(tail))
+	omittedLines := b.totalNewlines - int64(bytes.Count(head, []byte{'\n'})+bytes.Count(tail, []byte{'\n'}))
+
+	var out bytes.Buffer
+	out.Write(head)
+	if len(head) > 0 && head[len(head)-1] != '\n' {
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(&out, "[... output truncated: %d bytes and %d lines omitted ...]\n", omittedBytes, omittedLines)
+	out.Write(tail)
+
+	return out.String(), true
+}
+
+// lastRuneStart returns the index of the first byte of the last character in p
+func lastRuneStart(p []byte) int {
+	i := max(len(p)-1, 0)
+	for i > 0 && len(p)-i < utf8.UTFMax && !utf8.RuneStart(p[i]) {
+		i--
+	}
+	return i
+}
+
+// lastLines returns the last n lines of p, counting a final unterminated
+// line as a line
+func lastLines(p []byte, n int) []byte {
+	if n <= 0 {
+		return p[len(p):]
+	}
+
+	end := len(p)
+	if end > 0 && p[end-1] == '\n' {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		if p[i] == '\n' {
+			n--
+			if n == 0 {
+				return p[i+1:]
+			}
+		}
+	}
+	return p
+}
+
+// OutputStore keeps the full output of truncated executions in a directory,
+// with one subdirectory per execution holding a file per stream
+type OutputStore struct {
+	dir string
+}
+
+// NewOutputStore creates an output store in the given directory
+func NewOutputStore(dir string) *OutputStore {
+	return &OutputStore{dir: dir}
+}
+
+// Open opens the spilled output stream of an execution. The error satisfies
+// errors.Is(err, os.ErrNotExist) when the execution has no spilled output.
+func (s *OutputStore) Open(executionID uuid.UUID, stream string) (*os.File, error) {
+	if !IsOutputStream(stream) {
+		return nil, fmt.Errorf("invalid output stream: %s", stream)
+	}
+	return os.Open(s.path(executionID, stream))
+}
+
+// Remove deletes the spilled output of an execution
+func (s *OutputStore) Remove(executionID uuid.UUID) error {
+	return os.RemoveAll(filepath.Join(s.dir, executionID.String()))
+}
+
+// Create creates the spill file of an execution's output stream
+func (s *OutputStore) Create(executionID uuid.UUID, stream string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Join(s.dir, executionID.String()), 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(s.path(executionID, stream), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+}
+
+// outputVolumeFile names the file holding the ID of the volume an output
+// store is kept on
+const outputVolumeFile = ".volume-id"
+
+// VolumeID returns the ID of the volume the store is kept on, generating it
+// on first use. Processes spilling and serving output compare it to check
+// they share the volume. The directory isn't created, since a missing one
+// usually means the volume isn't mounted.
+func (s *OutputStore) VolumeID() (uuid.UUID, error) {
+	path := filepath.Join(s.dir, outputVolumeFile)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err == nil {
+		volumeID := uuid.New()
+		_, err = file.WriteString(volumeID.String())
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to write output volume ID: %w", err)
+		}
+		return volumeID, nil
+	}
+	if !errors.Is(err, os.ErrExist) {
+		return uuid.Nil, fmt.Errorf("failed to create output volume ID: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to read output volume ID: %w", err)
+	}
+	volumeID, err := uuid.ParseBytes(bytes.TrimSpace(data))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid output volume ID in %s: %w", path, err)
+	}
+	return volumeID, nil
+}
+
+// path returns the spill file of an execution's output stream
+func (s *OutputStore) path(executionID uuid.UUID, stream string) string {
+	return filepath.Join(s.dir, executionID.String(), stream+".log")
+}
+
+// spillWriter writes a stream to its spill file up to a byte limit. Errors
+// stop spilling instead of failing the execution.
+type spillWriter struct {
+	file     *os.File
+	maxBytes int64
+	written  int64
+	err      error
+}
+
+// Write implements io.Writer
+func (w *spillWriter) Write(p []byte) (int, error) {
+	if w.err != nil || w.written >= w.maxBytes {
+		return len(p), nil
+	}
+	data := p
+	if remaining := w.maxBytes - w.written; int64(len(data)) > remaining {
+		data = data[:remaining]
+	}
+	n, err := w.file.Write(data)
+	w.written += int64(n)
+	w.err = err
+	return len(p), nil
+}
+
+// executionOutput captures the stdout and stderr of an execution within the
+// output limits, spilling the full output to the output store if there is one
+type executionOutput struct {
+	Stdout *outputBuffer
+	Stderr *outputBuffer
+
+	store       *OutputStore
+	executionID uuid.UUID
+	spills      []*spillWriter
+}
+
+// newExecutionOutput creates the output buffers of an execution. Output is
+// only spilled for executions with a record to download it through.
+func newExecutionOutput(settings OutputSettings, store *OutputStore, executionID *uuid.UUID, logger *slog.Logger) *executionOutput {
+	output := &executionOutput{store: store}
+
+	var stdoutSpill, stderrSpill io.Writer
+	if store != nil && executionID != nil {
+		output.executionID = *executionID
+		maxSpillBytes := settings.MaxSpillBytes
+		if maxSpillBytes <= 0 {
+			maxSpillBytes = DefaultOutputMaxSpillBytes
+		}
+
+		for _, stream := range []string{OutputStreamStdout, OutputStreamStderr} {
+			file, err := store.Create(*executionID, stream)
+			if err != nil {
+				logger.Warn("failed to create output spill file, output will not be spilled", "stream", stream, "error", err)
+				output.discardSpills()
+				stdoutSpill, stderrSpill = nil, nil
+				break
+			}
+
+			spill := &spillWriter{file: file, maxBytes: maxSpillBytes}
+			output.spills = append(output.spills, spill)
+			if stream == OutputStreamStdout {
+				stdoutSpill = spill
+			} else {
+				stderrSpill = spill
+			}
+		}
+	}
+
+	output.Stdout = newOutputBuffer(settings, stdoutSpill)
+	output.Stderr = newOutputBuffer(settings, stderrSpill)
+	return output
+}
+
+// streamTo sends the start of each stream kept in the result to the given
+// writers while the execution runs. Nil writers leave a stream unstreamed.
+func (o *executionOutput) streamTo(stdout, stderr io.Writer) {
+	o.Stdout.live = stdout
+	o.Stderr.live = stderr
+}
+
+// streaming reports whether any of the output is streamed while the
+// execution runs
+func (o *executionOutput) streaming() bool {
+	return o.Stdout.live != nil || o.Stderr.live != nil
+}
+
+// finish stores the kept output in the result. The spilled output is only
+// kept when the output was truncated.
+func (o *executionOutput) finish(result *ExecutionResult, logger *slog.Logger) {
+	stdout, stdoutTruncated := o.Stdout.Result()
+	stderr, stderrTruncated := o.Stderr.Result()
+
+	if stdout != "" {
+		result.Stdout = &stdout
+	}
+	if stderr != "" {
+		result.Stderr = &stderr
+	}
+	result.Truncated = stdoutTruncated || stderrTruncated
+
+	if len(o.spills) == 0 {
+		return
+	}
+	if !result.Truncated {
+		o.discardSpills()
+		return
+	}
+
+	var spillErr error
+	for _, spill := range o.spills {
+		spillErr = errors.Join(spillErr, spill.err, spill.file.Close())
+	}
+	if spillErr != nil {
+		logger.Warn("failed to spill execution output, spilled output may be incomplete", "error", spillErr)
+	}
+}
+
+// discardSpills closes and removes the spill files
+func (o *executionOutput) discardSpills() {
+	for _, spill := range o.spills {
+		_ = spill.file.Close()
+	}
+	o.spills = nil
+	_ = o.store.Remove(o.executionID)
+}
@@ -0,0 +1,225 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func TestOutputBuffer(t *testing.T) {
+	tests := []struct {
+		name          string
+		settings      OutputSettings
+		writes        []string
+		expected      string
+		wantTruncated bool
+	}{
+		{
+			name:     "output within the limits",
+			settings: OutputSettings{MaxBytes: 16, MaxLines: 4},
+			writes:   []string{"one\n", "two\n", "three\n"},
+			expected: "one\ntwo\nthree\n",
+		},
+		{
+			name:     "output exactly at the byte limit",
+			settings: OutputSettings{MaxBytes: 10},
+			writes:   []string{"0123456789"},
+			expected: "0123456789",
+		},
+		{
+			name:          "byte limit keeps the start and end",
+			settings:      OutputSettings{MaxBytes: 8},
+			writes:        []string{"abcdefghij", "klmnopqrst"},
+			expected:      "abcd\n[... output truncated: 12 bytes and 0 lines omitted ...]\nqrst",
+			wantTruncated: true,
+		},
+		{
+			name:          "line limit keeps the first and last lines",
+			settings:      OutputSettings{MaxBytes: 1024, MaxLines: 4},
+			writes:        []string{"1\n2\n3\n", "4\n5\n6\n7\n"},
+			expected:      "1\n2\n[... output truncated: 6 bytes and 3 lines omitted ...]\n6\n7\n",
+			wantTruncated: true,
+		},
+		{
+			name:          "single large write",
+			settings:      OutputSettings{MaxBytes: 4},
+			writes:        []string{strings.Repeat("x", 1000) + "end"},
+			expected:      "xx\n[... output truncated: 999 bytes and 0 lines omitted ...]\nnd",
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newOutputBuffer(tt.settings, nil)
+			for _, write := range tt.writes {
+				n, err := buffer.Write([]byte(write))
+				require.NoError(t, err)
+				assert.Equal(t, len(write), n, "writes always report full length")
+			}
+
+			output, truncated := buffer.Result()
+			assert.Equal(t, tt.expected, output)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+
+	t.Run("memory stays bounded", func(t *testing.T) {
+		buffer := newOutputBuffer(OutputSettings{MaxBytes: 64}, nil)
+		for i := 0; i < 10000; i++ {
+			_, _ = buffer.Write([]byte("some output line\n"))
+		}
+		assert.LessOrEqual(t, cap(buffer.head)+len(buffer.tail), 256)
+	})
+
+	t.Run("cuts keep characters whole", func(t *testing.T) {
+		buffer := newOutputBuffer(OutputSettings{MaxBytes: 7}, nil)
+		_, _ = buffer.Write([]byte(strings.Repeat("é", 20)))
+
+		output, truncated := buffer.Result()
+		assert.True(t, truncated)
+		assert.True(t, utf8.ValidString(output), output)
+		assert.True(t, strings.HasPrefix(output, "é\n[..."), output)
+		assert.True(t, strings.HasSuffix(output, "éé"), output)
+	})
+}
+
+func TestLastLines(t *testing.T) {
+	assert.Equal(t, "c\n", string(lastLines([]byte("a\nb\nc\n"), 1)))
+	assert.Equal(t, "b\nc", string(lastLines([]byte("a\nb\nc"), 2)))
+	assert.Equal(t, "a\nb\n", string(lastLines([]byte("a\nb\n"), 5)))
+	assert.Empty(t, lastLines([]byte("a\nb\n"), 0))
+}
+
+func TestExecutor_ExecuteTruncatesOutput(t *testing.T) {
+	newExecutor := func(t *testing.T, spillDir string, logs string) *Executor {
+		config := NewDefaultConfig()
+		config.Security.EnableSeccomp = false
+		config.Output = OutputSettings{MaxBytes: 10, MaxLines: 100, SpillDir: spillDir, MaxSpillBytes: 1024}
+
+		client := new(MockContainerClient)
+		client.On("CreateContainer", mock.Anything, mock.Anything).Return("container123", nil)
+		client.On("StartContainer", mock.Anything, "container123").Return(nil)
+		client.On("WaitContainer", mock.Anything, "container123").Return(0, nil)
+		client.On("InspectContainerExit", mock.Anything, "container123").Return(&ContainerExitState{ExitCode: 0}, nil)
+		client.On("StreamContainerLogs", mock.Anything, "container123").Return(logs, "", nil)
+		client.On("FollowContainerLogs", mock.Anything, "container123").Return(logs, "", nil)
+		client.On("RemoveContainer", mock.Anything, "container123", true).Return(nil)
+
+		return &Executor{
+			client:          client,
+			config:          config,
+			securityManager: NewSecurityManager(config),
+			cleanupManager:  NewCleanupManager(client, slog.Default()),
+			outputStore:     NewOutputStore(spillDir),
+			logger:          slog.Default(),
+		}
+	}
+
+	newExecutionContext := func() *ExecutionContext {
+		return &ExecutionContext{
+			Task: &models.Task{
+				BaseModel:     models.BaseModel{ID: uuid.New()},
+				ScriptType:    models.ScriptTypePython,
+				ScriptContent: "print('hello')",
+			},
+			Execution: &models.TaskExecution{ID: uuid.New()},
+			Timeout:   30 * time.Second,
+		}
+	}
+
+	t.Run("truncated output is spilled", func(t *testing.T) {
+		logs := strings.Repeat("0123456789", 10)
+		executor := newExecutor(t, t.TempDir(), logs)
+		execCtx := newExecutionContext()
+
+		result, err := executor.Execute(context.Background(), execCtx)
+		require.NoError(t, err)
+
+		assert.True(t, result.Truncated)
+		require.NotNil(t, result.Stdout)
+		assert.Equal(t, "01234\n[... output truncated: 90 bytes and 0 lines omitted ...]\n56789", *result.Stdout)
+
+		file, err := executor.outputStore.Open(execCtx.Execution.ID, OutputStreamStdout)
+		require.NoError(t, err)
+		defer file.Close()
+		spilled, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, logs, string(spilled))
+	})
+
+	t.Run("complete output is not spilled", func(t *testing.T) {
+		executor := newExecutor(t, t.TempDir(), "hello\n")
+		execCtx := newExecutionContext()
+
+		result, err := executor.Execute(context.Background(), execCtx)
+		require.NoError(t, err)
+
+		assert.False(t, result.Truncated)
+		require.NotNil(t, result.Stdout)
+		assert.Equal(t, "hello\n", *result.Stdout)
+
+		_, err = executor.outputStore.Open(execCtx.Execution.ID, OutputStreamStdout)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("streamed output is followed while the container runs", func(t *testing.T) {
+		executor := newExecutor(t, t.TempDir(), strings.Repeat("0123456789", 10))
+		execCtx := newExecutionContext()
+		var stdout bytes.Buffer
+		execCtx.Stdout = &stdout
+
+		result, err := executor.Execute(context.Background(), execCtx)
+		require.NoError(t, err)
+
+		// Only the start of the kept output is streamed
+		assert.Equal(t, "01234", stdout.String())
+		require.NotNil(t, result.Stdout)
+		assert.True(t, strings.HasPrefix(*result.Stdout, stdout.String()))
+		assert.True(t, result.Truncated)
+		executor.client.(*MockContainerClient).AssertNotCalled(t, "StreamContainerLogs", mock.Anything, "container123")
+	})
+}
+
+func TestOutputStore_Open(t *testing.T) {
+	store := NewOutputStore(t.TempDir())
+
+	_, err := store.Open(uuid.New(), "../../etc/passwd")
+	assert.Error(t, err)
+
+	_, err = store.Open(uuid.New(), OutputStreamStderr)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestOutputStore_VolumeID(t *testing.T) {
+	dir := t.TempDir()
+
+	volumeID, err := NewOutputStore(dir).VolumeID()
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, volumeID)
+
+	again, err := NewOutputStore(dir).VolumeID()
+	require.NoError(t, err)
+	assert.Equal(t, volumeID, again, "the volume keeps its ID")
+
+	other, err := NewOutputStore(t.TempDir()).VolumeID()
+	require.NoError(t, err)
+	assert.NotEqual(t, volumeID, other)
+
+	_, err = NewOutputStore(filepath.Join(dir, "unmounted")).VolumeID()
+	assert.Error(t, err, "a missing directory isn't created")
+}
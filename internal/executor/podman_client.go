@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+)
+
+// CgroupFeatures describes which resource limits the container runtime can enforce
+type CgroupFeatures struct {
+	// Cgroup version reported by the runtime ("1" or "2")
+	Version string
+
+	// Memory limits are enforced
+	MemoryLimit bool
+
+	// CPU quota limits are enforced
+	CPUQuota bool
+
+	// Process count limits are enforced
+	PidsLimit bool
+}
+
+// SupportsAllLimits reports whether every executor resource limit is enforced
+func (f CgroupFeatures) SupportsAllLimits() bool {
+	return f.MemoryLimit && f.CPUQuota && f.PidsLimit
+}
+
+// detectCgroupFeatures derives the enforceable resource limits from the
+// runtime's system information. Rootless containers can only be limited
+// through delegated cgroup v2 controllers, so cgroup v1 hosts enforce nothing.
+func detectCgroupFeatures(info system.Info) CgroupFeatures {
+	features := CgroupFeatures{Version: info.CgroupVersion}
+	if info.CgroupVersion != "2" {
+		return features
+	}
+
+	features.MemoryLimit = info.MemoryLimit
+	features.CPUQuota = info.CPUCfsQuota
+	features.PidsLimit = info.PidsLimit
+	return features
+}
+
+// PodmanClient implements the ContainerClient interface on top of the rootless
+// Podman REST service. Podman serves the Docker-compatible API, so container
+// operations are shared with DockerClient; only resource limits differ, as they
+// depend on which cgroup v2 controllers are delegated to the user.
+type PodmanClient struct {
+	*DockerClient
+	features CgroupFeatures
+}
+
+// NewPodmanClient creates a new Podman client with the given configuration
+func NewPodmanClient(config *Config, logger *slog.Logger) (*PodmanClient, error) {
+	if config == nil {
+		config = NewDefaultConfig()
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	endpoint := config.PodmanEndpoint
+	if endpoint == "" {
+		endpoint = DefaultPodmanEndpoint()
+	}
+
+	// Create Docker-compatible client for the Podman socket
+	cli, err := client.NewClientWithOpts(
+		client.WithHost(endpoint),
+		client.WithAPIVersionNegotiation(),
+	)
+	if err != nil {
+		return nil, NewExecutorError("podman_client_init", "failed to create Podman client", err)
+	}
+
+	podmanClient := &PodmanClient{
+		DockerClient: &DockerClient{
+			client: cli,
+			config: config,
+			logger: logger,
+		},
+	}
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := podmanClient.IsHealthy(ctx); err != nil {
+		_ = cli.Close()
+		return nil, fmt.Errorf("podman health check failed: %w", err)
+	}
+
+	info, err := cli.Info(ctx)
+	if err != nil {
+		_ = cli.Close()
+		return nil, NewExecutorError("podman_client_init", "failed to get Podman info", err)
+	}
+
+	podmanClient.features = detectCgroupFeatures(info)
+	if !podmanClient.features.SupportsAllLimits() {
+		message := "Podman cannot enforce all resource limits, containers requesting them will be refused"
+		if config.Security.AllowUnenforcedLimits {
+			message = "Podman cannot enforce all resource limits, unsupported limits will not be applied"
+		}
+		logger.Warn(message,
+			"endpoint", endpoint,
+			"cgroup_version", podmanClient.features.Version,
+			"memory_limit", podmanClient.features.MemoryLimit,
+			"cpu_quota", podmanClient.features.CPUQuota,
+			"pids_limit", podmanClient.features.PidsLimit,
+		)
+	}
+
+	return podmanClient, nil
+}
+
+// CgroupFeatures returns the resource limits the Podman service can enforce
+func (pc *PodmanClient) CgroupFeatures() CgroupFeatures {
+	return pc.features
+}
+
+// CreateContainer creates a new container. Containers requesting resource
+// limits the Podman service cannot enforce are refused, unless running without
+// them is allowed by the configuration.
+func (pc *PodmanClient) CreateContainer(ctx context.Context, config *ContainerConfig) (string, error) {
+	if config == nil {
+		return "", NewExecutorError("create_container", "container config is nil", nil)
+	}
+
+	limits, err := pc.applyCgroupFeatures(config.ResourceLimits)
+	if err != nil {
+		return "", err
+	}
+
+	podmanConfig := *config
+	podmanConfig.ResourceLimits = limits
+
+	return pc.DockerClient.CreateContainer(ctx, &podmanConfig)
+}
+
+// applyCgroupFeatures checks that the requested resource limits can be
+// enforced, or clears those that cannot when that is allowed
+func (pc *PodmanClient) applyCgroupFeatures(limits ResourceLimits) (ResourceLimits, error) {
+	var unenforced []string
+	if limits.MemoryLimitBytes > 0 && !pc.features.MemoryLimit {
+		unenforced = append(unenforced, "memory")
+		limits.MemoryLimitBytes = 0
+	}
+	if limits.CPUQuota > 0 && !pc.features.CPUQuota {
+		unenforced = append(unenforced, "cpu")
+		limits.CPUQuota = 0
+	}
+	if limits.PidsLimit > 0 && !pc.features.PidsLimit {
+		unenforced = append(unenforced, "pids")
+		limits.PidsLimit = 0
+	}
+
+	if len(unenforced) > 0 && !pc.config.Security.AllowUnenforcedLimits {
+		return ResourceLimits{}, NewSecurityError("create_container",
+			fmt.Sprintf("Podman cannot enforce the %s limits, the cgroup v2 controllers are not delegated", strings.Join(unenforced, ", ")), nil)
+	}
+
+	return limits, nil
+}
+
+// IsHealthy checks if the Podman service is accessible
+func (pc *PodmanClient) IsHealthy(ctx context.Context) error {
+	_, err := pc.client.Ping(ctx)
+	if err != nil {
+		return NewExecutorError("health_check", "Podman service is not accessible", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPodmanClient_Unavailable(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Backend = BackendPodman
+	config.PodmanEndpoint = "unix://" + t.TempDir() + "/podman.sock"
+
+	client, err := NewPodmanClient(config, nil)
+	require.Error(t, err)
+	assert.Nil(t, client)
+	assert.Contains(t, err.Error(), "podman health check failed")
+}
+
+func TestDetectCgroupFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     system.Info
+		expected CgroupFeatures
+	}{
+		{
+			name: "cgroup v2 with all controllers delegated",
+			info: system.Info{CgroupVersion: "2", MemoryLimit: true, CPUCfsQuota: true, PidsLimit: true},
+			expected: CgroupFeatures{
+				Version:     "2",
+				MemoryLimit: true,
+				CPUQuota:    true,
+				PidsLimit:   true,
+			},
+		},
+		{
+			name: "cgroup v2 without cpu controller",
+			info: system.Info{CgroupVersion: "2", MemoryLimit: true, PidsLimit: true},
+			expected: CgroupFeatures{
+				Version:     "2",
+				MemoryLimit: true,
+				PidsLimit:   true,
+			},
+		},
+		{
+			name:     "cgroup v1 enforces nothing rootless",
+			info:     system.Info{CgroupVersion: "1", MemoryLimit: true, CPUCfsQuota: true, PidsLimit: true},
+			expected: CgroupFeatures{Version: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			features := detectCgroupFeatures(tt.info)
+			assert.Equal(t, tt.expected, features)
+			assert.Equal(t, tt.expected.MemoryLimit && tt.expected.CPUQuota && tt.expected.PidsLimit, features.SupportsAllLimits())
+		})
+	}
+}
+
+func TestPodmanClient_applyCgroupFeatures(t *testing.T) {
+	limits := ResourceLimits{
+		MemoryLimitBytes: 128 * 1024 * 1024,
+		CPUQuota:         50000,
+		PidsLimit:        128,
+		TimeoutSeconds:   60,
+	}
+
+	t.Run("enforces every limit", func(t *testing.T) {
+		client := &PodmanClient{
+			DockerClient: &DockerClient{config: NewDefaultConfig()},
+			features:     CgroupFeatures{Version: "2", MemoryLimit: true, CPUQuota: true, PidsLimit: true},
+		}
+
+		applied, err := client.applyCgroupFeatures(limits)
+		require.NoError(t, err)
+		assert.Equal(t, limits, applied)
+	})
+
+	t.Run("refuses limits it cannot enforce", func(t *testing.T) {
+		client := &PodmanClient{
+			DockerClient: &DockerClient{config: NewDefaultConfig()},
+			features:     CgroupFeatures{Version: "2", MemoryLimit: true},
+		}
+
+		_, err := client.applyCgroupFeatures(limits)
+		require.Error(t, err)
+		assert.True(t, IsSecurityError(err))
+		assert.Contains(t, err.Error(), "cpu, pids")
+
+		// Limits that aren't requested don't need to be enforced
+		applied, err := client.applyCgroupFeatures(ResourceLimits{MemoryLimitBytes: limits.MemoryLimitBytes})
+		require.NoError(t, err)
+		assert.Equal(t, limits.MemoryLimitBytes, applied.MemoryLimitBytes)
+	})
+
+	t.Run("drops limits it cannot enforce when allowed", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.Security.AllowUnenforcedLimits = true
+		client := &PodmanClient{
+			DockerClient: &DockerClient{config: config},
+			features:     CgroupFeatures{Version: "2", MemoryLimit: true},
+		}
+
+		applied, err := client.applyCgroupFeatures(limits)
+		require.NoError(t, err)
+		assert.Equal(t, limits.MemoryLimitBytes, applied.MemoryLimitBytes)
+		assert.Zero(t, applied.CPUQuota)
+		assert.Zero(t, applied.PidsLimit)
+		assert.Equal(t, limits.TimeoutSeconds, applied.TimeoutSeconds)
+	})
+}
+
+func TestPodmanClient_CreateContainerUnenforcedLimits(t *testing.T) {
+	client := &PodmanClient{
+		DockerClient: &DockerClient{config: NewDefaultConfig()},
+		features:     CgroupFeatures{Version: "1"},
+	}
+
+	_, err := client.CreateContainer(context.Background(), &ContainerConfig{
+		ResourceLimits: ResourceLimits{MemoryLimitBytes: 128 * 1024 * 1024},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "memory")
+}
+
+func TestPodmanClient_CreateContainerNilConfig(t *testing.T) {
+	client := &PodmanClient{DockerClient: &DockerClient{config: NewDefaultConfig()}}
+
+	_, err := client.CreateContainer(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "container config is nil")
+}
+
+func TestNewContainerClient_UnsupportedBackend(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Backend = "lxc"
+
+	client, err := newContainerClient(config, nil)
+	require.Error(t, err)
+	assert.Nil(t, client)
+	assert.Contains(t, err.Error(), "unsupported container backend")
+}
@@ -0,0 +1,383 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// sandboxWorkingDir is the working directory of sandboxed scripts
+const sandboxWorkingDir = "/tmp"
+
+// sandboxInitArg is the argv[0] the executor binary is re-executed with to
+// set up the sandbox before running a script
+const sandboxInitArg = "voidrunner-sandbox-init"
+
+// sandboxBaseSyscalls are the syscalls interpreters need to start, allowed in
+// addition to SecuritySettings.AllowedSyscalls. Names unknown on the host
+// architecture are ignored.
+var sandboxBaseSyscalls = []string{
+	"execve", "openat", "newfstatat", "statx", "faccessat", "faccessat2",
+	"pread64", "pwrite64", "readv", "ioctl", "fcntl", "getdents64",
+	"rt_sigaction", "rt_sigprocmask", "rt_sigreturn", "sigaltstack",
+	"futex", "set_tid_address", "set_robust_list", "rseq", "prlimit64",
+	"getrandom", "clone", "clone3", "wait4", "madvise", "mremap",
+	"arch_prctl", "clock_gettime", "clock_nanosleep", "nanosleep", "gettid",
+	"tgkill", "sched_getaffinity", "sched_yield", "getcwd", "pipe2", "dup3",
+	"ppoll", "pselect6", "epoll_create1", "epoll_ctl", "epoll_pwait",
+	"eventfd2", "unlinkat", "mkdirat", "renameat", "readlinkat", "fchmodat",
+	"vfork", "fork",
+}
+
+// sandboxInitCalled records whether the binary calls SandboxInit, without
+// which re-executing it would start the application instead of a sandbox
+var sandboxInitCalled atomic.Bool
+
+// SandboxInit runs the sandbox init process when the binary was re-executed
+// by the process executor and otherwise returns false. Binaries that may use
+// the process backend must call it first thing in main:
+//
+//	if executor.SandboxInit() {
+//		return
+//	}
+func SandboxInit() bool {
+	sandboxInitCalled.Store(true)
+	if len(os.Args) == 0 || os.Args[0] != sandboxInitArg {
+		return false
+	}
+
+	// runSandboxInit only returns if the script could not be executed
+	err := runSandboxInit()
+	fmt.Fprintf(os.Stderr, "sandbox init failed: %v\n", err)
+	os.Exit(sandboxInitFailedCode)
+	return true
+}
+
+// sandboxInitFailedCode is the exit code of a sandbox that failed to start
+const sandboxInitFailedCode = 127
+
+// sandboxSpec describes a single sandboxed process. It is passed from the
+// executor to the sandbox init process, so all fields must be serialisable.
+type sandboxSpec struct {
+	Argv        []string          `json:"argv"`
+	Env         []string          `json:"env"`
+	WorkingDir  string            `json:"working_dir"`
+	TmpfsMounts map[string]string `json:"tmpfs_mounts"`
+	MaskedPaths []string          `json:"masked_paths"`
+	Syscalls    []string          `json:"syscalls"`
+	Rlimits     []sandboxRlimit   `json:"rlimits"`
+}
+
+// sandboxRlimit is a resource limit applied before the script is executed
+type sandboxRlimit struct {
+	Resource int    `json:"resource"`
+	Limit    uint64 `json:"limit"`
+}
+
+// sandboxOutcome is the result of running a sandboxed process
+type sandboxOutcome struct {
+	ExitCode         int
+	MemoryUsageBytes *int64
+
+	// Signal that killed the script, or 0 if it exited on its own
+	Signal int
+
+	// Whether the OOM killer killed a process in the sandbox's cgroup
+	OOMKilled bool
+}
+
+// ProcessExecutor implements the TaskExecutor interface by running scripts
+// directly on the host inside unshared user, mount, PID and network
+// namespaces. It is intended for hosts without a container runtime.
+type ProcessExecutor struct {
+	config          *Config
+	securityManager *SecurityManager
+	outputStore     *OutputStore
+	logger          *slog.Logger
+
+	mu      sync.Mutex
+	running map[uuid.UUID]context.CancelFunc
+}
+
+// NewProcessExecutor creates a new process sandbox executor with the given configuration
+func NewProcessExecutor(config *Config, logger *slog.Logger) (*ProcessExecutor, error) {
+	if config == nil {
+		config = NewDefaultConfig()
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if !sandboxInitCalled.Load() {
+		return nil, NewExecutorError("process_executor_init", "process sandbox is not available",
+			errors.New("executor.SandboxInit must be called at the start of main"))
+	}
+
+	if err := checkSandboxSupport(); err != nil {
+		return nil, NewExecutorError("process_executor_init", "process sandbox is not supported", err)
+	}
+
+	if config.Sandbox.CgroupParent == "" {
+		logger.Warn("no sandbox cgroup parent configured, CPU and PID limits will not be enforced")
+	}
+
+	processExecutor := &ProcessExecutor{
+		config:          config,
+		securityManager: NewSecurityManager(config),
+		logger:          logger,
+		running:         make(map[uuid.UUID]context.CancelFunc),
+	}
+	if config.Output.SpillDir != "" {
+		processExecutor.outputStore = NewOutputStore(config.Output.SpillDir)
+	}
+
+	return processExecutor, nil
+}
+
+// Execute runs the given task and returns the execution result
+func (pe *ProcessExecutor) Execute(ctx context.Context, execCtx *ExecutionContext) (*ExecutionResult, error) {
+	if execCtx == nil || execCtx.Task == nil {
+		return nil, NewExecutorError("execute", "execution context or task is nil", nil)
+	}
+
+	task := execCtx.Task
+	logger := pe.logger.With(
+		"task_id", task.ID.String(),
+		"script_type", string(task.ScriptType),
+		"operation", "execute",
+	)
+
+	logger.Info("starting sandboxed task execution")
+
+	// Validate script content for security
+	if err := pe.securityManager.ValidateScriptContent(task.ScriptContent, task.ScriptType); err != nil {
+		logger.Error("script security validation failed", "error", err)
+		return &ExecutionResult{
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Security validation failed: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategorySecurity),
+		}, err
+	}
+
+	// The process sandbox has no OCI runtime, so it can't honour a request
+	// for a stronger isolation level than its own
+	if task.SecurityLevel != "" && task.SecurityLevel != models.SecurityLevelStandard {
+		err := NewExecutorError("execute", fmt.Sprintf("security level %q requires a container runtime", task.SecurityLevel), nil)
+		logger.Error("unsupported security level", "security_level", task.SecurityLevel)
+		return &ExecutionResult{
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Configuration error: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategoryConfig),
+		}, err
+	}
+
+	// Nor can it run a task in its own image
+	if task.Image != nil {
+		err := NewExecutorError("execute", fmt.Sprintf("custom image %q requires a container runtime", *task.Image), nil)
+		logger.Error("unsupported custom image", "image", *task.Image)
+		return &ExecutionResult{
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Configuration error: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategoryImage),
+		}, err
+	}
+
+	// Nor give it network access, as there is no egress proxy to enforce the
+	// task's network policy
+	if task.HasNetworkAccess() {
+		err := NewExecutorError("execute", fmt.Sprintf("network mode %q requires a container runtime", task.NetworkMode), nil)
+		logger.Error("unsupported network mode", "network_mode", task.NetworkMode)
+		return &ExecutionResult{
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Configuration error: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategoryConfig),
+		}, err
+	}
+
+	limits := execCtx.ResourceLimits
+	if limits.MemoryLimitBytes == 0 {
+		limits = pe.config.GetResourceLimitsForTask(task)
+	}
+	if err := pe.securityManager.validateResourceLimits(&limits); err != nil {
+		logger.Error("resource limit validation failed", "error", err)
+		return &ExecutionResult{
+			Status:        models.ExecutionStatusFailed,
+			Stderr:        stringPtr(fmt.Sprintf("Security validation failed: %s", err.Error())),
+			ErrorCategory: categoryPtr(models.ErrorCategorySecurity),
+		}, err
+	}
+
+	execTimeout := execCtx.Timeout
+	if execTimeout == 0 {
+		execTimeout = pe.config.GetTimeoutForTask(task)
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	if execCtx.Execution != nil {
+		pe.trackExecution(execCtx.Execution.ID, cancel)
+		defer pe.untrackExecution(execCtx.Execution.ID)
+	}
+
+	spec := pe.buildSandboxSpec(task, limits, execTimeout)
+
+	startTime := time.Now()
+	result := &ExecutionResult{
+		Status:    models.ExecutionStatusRunning,
+		StartedAt: &startTime,
+	}
+
+	output := newExecutionOutput(pe.config.Output, pe.outputStore, executionID(execCtx), logger)
+	output.streamTo(execCtx.Stdout, execCtx.Stderr)
+	outcome, err := runSandbox(ctxWithTimeout, spec, limits, pe.config.Sandbox.CgroupParent, output.Stdout, output.Stderr)
+	output.finish(result, logger)
+
+	endTime := time.Now()
+	result.CompletedAt = &endTime
+	duration := int(endTime.Sub(startTime).Milliseconds())
+	result.ExecutionTimeMs = &duration
+
+	switch {
+	case ctxWithTimeout.Err() == context.DeadlineExceeded:
+		result.recordTimeout(models.TimeoutPhaseRun)
+		logger.Warn("sandboxed execution timed out")
+	case ctxWithTimeout.Err() == context.Canceled:
+		result.Status = models.ExecutionStatusCancelled
+		result.setErrorCategory(models.ErrorCategoryCancelled)
+		logger.Info("sandboxed execution cancelled")
+	case err != nil:
+		result.Status = models.ExecutionStatusFailed
+		result.setErrorCategory(models.ErrorCategoryRuntime)
+		logger.Error("sandboxed execution failed", "error", err)
+		result.Stderr = stringPtr(fmt.Sprintf("Execution error: %s", err.Error()))
+		return result, NewExecutorError("execute_process", "failed to run sandboxed process", err)
+	default:
+		if outcome.OOMKilled {
+			logger.Warn("sandboxed process was killed for exceeding its memory limit")
+		}
+		result.recordExit(outcome.ExitCode, outcome.Signal, outcome.OOMKilled)
+	}
+
+	if outcome != nil {
+		result.MemoryUsageBytes = outcome.MemoryUsageBytes
+	}
+
+	logger.Info("sandboxed task execution completed",
+		"status", result.Status,
+		"duration_ms", duration,
+	)
+
+	return result, nil
+}
+
+// buildSandboxSpec creates the sandbox description for the given task
+func (pe *ProcessExecutor) buildSandboxSpec(task *models.Task, limits ResourceLimits, timeout time.Duration) *sandboxSpec {
+	securityConfig := pe.config.GetSecurityConfigForTask(task)
+
+	syscalls := append([]string{}, sandboxBaseSyscalls...)
+	syscalls = append(syscalls, pe.config.Security.AllowedSyscalls...)
+
+	return &sandboxSpec{
+		Argv:        buildScriptCommand(task.ScriptType, task.ScriptContent),
+		Env:         pe.securityManager.SanitizeEnvironment(baseEnvironment()),
+		WorkingDir:  sandboxWorkingDir,
+		TmpfsMounts: securityConfig.TmpfsMounts,
+		MaskedPaths: pe.config.Sandbox.MaskedPaths,
+		Syscalls:    syscalls,
+		Rlimits:     sandboxRlimits(limits, timeout, pe.config.Sandbox.CgroupParent == ""),
+	}
+}
+
+// trackExecution registers the cancel function of a running execution
+func (pe *ProcessExecutor) trackExecution(executionID uuid.UUID, cancel context.CancelFunc) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	pe.running[executionID] = cancel
+}
+
+// untrackExecution removes a finished execution
+func (pe *ProcessExecutor) untrackExecution(executionID uuid.UUID) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	delete(pe.running, executionID)
+}
+
+// Cancel cancels a running execution
+func (pe *ProcessExecutor) Cancel(ctx context.Context, executionID uuid.UUID) error {
+	logger := pe.logger.With("execution_id", executionID.String(), "operation", "cancel")
+	logger.Info("execution cancellation requested")
+
+	pe.mu.Lock()
+	cancel, ok := pe.running[executionID]
+	pe.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	logger.Info("execution cancellation completed", "was_running", ok)
+	return nil
+}
+
+// IsHealthy checks if the executor is healthy and ready to execute tasks
+func (pe *ProcessExecutor) IsHealthy(ctx context.Context) error {
+	if err := pe.config.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	// Run a trivial script to verify namespaces, mounts and seccomp all work
+	probe := &sandboxSpec{
+		Argv:        []string{"sh", "-c", "exit 0"},
+		Env:         baseEnvironment(),
+		WorkingDir:  sandboxWorkingDir,
+		TmpfsMounts: pe.config.GetSecurityConfigForTask(&models.Task{}).TmpfsMounts,
+		MaskedPaths: pe.config.Sandbox.MaskedPaths,
+		Syscalls:    append(append([]string{}, sandboxBaseSyscalls...), pe.config.Security.AllowedSyscalls...),
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stderr := newOutputBuffer(pe.config.Output, nil)
+	outcome, err := runSandbox(probeCtx, probe, pe.config.DefaultResourceLimits, pe.config.Sandbox.CgroupParent, io.Discard, stderr)
+	if err != nil {
+		return NewExecutorError("health_check", "process sandbox is not usable", err)
+	}
+	if outcome.ExitCode != 0 {
+		probeStderr, _ := stderr.Result()
+		return NewExecutorError("health_check",
+			fmt.Sprintf("process sandbox probe exited with code %d: %s", outcome.ExitCode, probeStderr), nil)
+	}
+
+	return nil
+}
+
+// Cleanup performs any necessary cleanup of resources
+func (pe *ProcessExecutor) Cleanup(ctx context.Context) error {
+	pe.logger.Info("cleaning up process executor")
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	for executionID, cancel := range pe.running {
+		cancel()
+		delete(pe.running, executionID)
+	}
+
+	return nil
+}
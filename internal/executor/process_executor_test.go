@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func TestMain(m *testing.M) {
+	// The process sandbox re-executes the test binary as its init process
+	if SandboxInit() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// newTestProcessExecutor returns a process executor, skipping the test when
+// the host cannot run sandboxes
+func newTestProcessExecutor(t *testing.T) *ProcessExecutor {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.Backend = BackendProcess
+
+	processExecutor, err := NewProcessExecutor(config, nil)
+	if err != nil {
+		t.Skipf("process sandbox not supported: %v", err)
+	}
+	if err := processExecutor.IsHealthy(context.Background()); err != nil {
+		t.Skipf("process sandbox not usable: %v", err)
+	}
+
+	return processExecutor
+}
+
+func newProcessExecutionContext(script string, scriptType models.ScriptType, timeout time.Duration) *ExecutionContext {
+	return &ExecutionContext{
+		Task: &models.Task{
+			BaseModel:     models.BaseModel{ID: uuid.New()},
+			ScriptContent: script,
+			ScriptType:    scriptType,
+			Priority:      5,
+		},
+		Execution: &models.TaskExecution{ID: uuid.New()},
+		Timeout:   timeout,
+	}
+}
+
+func TestProcessExecutor_Execute(t *testing.T) {
+	processExecutor := newTestProcessExecutor(t)
+	ctx := context.Background()
+
+	t.Run("captures output and exit code", func(t *testing.T) {
+		result, err := processExecutor.Execute(ctx, newProcessExecutionContext("echo hello; echo oops >&2; exit 3", models.ScriptTypeBash, 10*time.Second))
+		require.NoError(t, err)
+
+		assert.Equal(t, models.ExecutionStatusFailed, result.Status)
+		require.NotNil(t, result.ReturnCode)
+		assert.Equal(t, 3, *result.ReturnCode)
+		require.NotNil(t, result.Stdout)
+		assert.Equal(t, "hello\n", *result.Stdout)
+		require.NotNil(t, result.Stderr)
+		assert.Equal(t, "oops\n", *result.Stderr)
+	})
+
+	t.Run("truncates output over the limits", func(t *testing.T) {
+		result, err := processExecutor.Execute(ctx, newProcessExecutionContext("seq 1 50000", models.ScriptTypeBash, 10*time.Second))
+		require.NoError(t, err)
+
+		assert.Equal(t, models.ExecutionStatusCompleted, result.Status)
+		assert.True(t, result.Truncated)
+		require.NotNil(t, result.Stdout)
+		assert.True(t, strings.HasPrefix(*result.Stdout, "1\n2\n"))
+		assert.Contains(t, *result.Stdout, "[... output truncated:")
+		assert.True(t, strings.HasSuffix(*result.Stdout, "\n50000\n"))
+		assert.Equal(t, DefaultOutputMaxLines+1, strings.Count(*result.Stdout, "\n"))
+	})
+
+	t.Run("isolates the host", func(t *testing.T) {
+		// Run through the sandbox directly, as script validation rejects these probes
+		task := &models.Task{
+			ScriptContent: "uname -n; echo $$; touch /voidrunner-escape 2>/dev/null || echo readonly; ls /home | wc -l; cat /proc/1/cmdline",
+			ScriptType:    models.ScriptTypeBash,
+		}
+		limits := processExecutor.config.GetResourceLimitsForTask(task)
+		spec := processExecutor.buildSandboxSpec(task, limits, 10*time.Second)
+
+		var stdout, stderr strings.Builder
+		outcome, err := runSandbox(ctx, spec, limits, "", &stdout, &stderr)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, outcome.ExitCode, stderr.String())
+		assert.Equal(t, []string{"voidrunner", "1", "readonly", "0"}, strings.Fields(stdout.String())[:4])
+		assert.NotContains(t, stdout.String(), "go-build", "host processes must not be visible")
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		result, err := processExecutor.Execute(ctx, newProcessExecutionContext("sleep 30", models.ScriptTypeBash, 500*time.Millisecond))
+		require.NoError(t, err)
+
+		assert.Equal(t, models.ExecutionStatusTimeout, result.Status)
+		assert.Less(t, *result.ExecutionTimeMs, 10000)
+	})
+}
+
+func TestProcessExecutor_ExecuteRejectsUnsafeScripts(t *testing.T) {
+	processExecutor := &ProcessExecutor{
+		config:          NewDefaultConfig(),
+		securityManager: NewSecurityManager(NewDefaultConfig()),
+		logger:          slog.Default(),
+		running:         make(map[uuid.UUID]context.CancelFunc),
+	}
+
+	result, err := processExecutor.Execute(context.Background(), newProcessExecutionContext("import os\nos.system('id')", models.ScriptTypePython, time.Second))
+	require.Error(t, err)
+	assert.Equal(t, models.ExecutionStatusFailed, result.Status)
+	require.NotNil(t, result.Stderr)
+	assert.Contains(t, *result.Stderr, "Security validation failed")
+}
+
+func TestProcessExecutor_ExecuteRejectsRuntimeSecurityLevels(t *testing.T) {
+	processExecutor := &ProcessExecutor{
+		config:          NewDefaultConfig(),
+		securityManager: NewSecurityManager(NewDefaultConfig()),
+		logger:          slog.Default(),
+		running:         make(map[uuid.UUID]context.CancelFunc),
+	}
+
+	execCtx := newProcessExecutionContext("echo hello", models.ScriptTypeBash, time.Second)
+	execCtx.Task.SecurityLevel = models.SecurityLevelIsolated
+
+	result, err := processExecutor.Execute(context.Background(), execCtx)
+	require.Error(t, err)
+	assert.Equal(t, models.ExecutionStatusFailed, result.Status)
+	assert.Contains(t, err.Error(), "requires a container runtime")
+}
+
+func TestProcessExecutor_Cancel(t *testing.T) {
+	processExecutor := &ProcessExecutor{
+		logger:  slog.Default(),
+		running: make(map[uuid.UUID]context.CancelFunc),
+	}
+
+	executionID := uuid.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	processExecutor.trackExecution(executionID, cancel)
+
+	require.NoError(t, processExecutor.Cancel(context.Background(), executionID))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	// Unknown executions are ignored
+	require.NoError(t, processExecutor.Cancel(context.Background(), uuid.New()))
+
+	processExecutor.untrackExecution(executionID)
+	assert.Empty(t, processExecutor.running)
+}
+
+func TestNewTaskExecutor_ProcessBackend(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Backend = BackendProcess
+
+	taskExecutor, err := NewTaskExecutor(config, nil)
+	if err != nil {
+		assert.Contains(t, err.Error(), "failed to create process executor")
+		return
+	}
+	assert.IsType(t, &ProcessExecutor{}, taskExecutor)
+}
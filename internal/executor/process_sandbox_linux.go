@@ -0,0 +1,532 @@
+//go:build linux && (amd64 || arm64)
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
+)
+
+// sandboxCloneFlags are the namespaces every sandboxed process is started in
+const sandboxCloneFlags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+	syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS | syscall.CLONE_NEWCGROUP
+
+// Fixed rlimits applied to every sandboxed process
+const (
+	sandboxMaxFileSizeBytes = 100 * 1024 * 1024
+	sandboxMaxOpenFiles     = 256
+)
+
+// checkSandboxSupport verifies that unprivileged user namespaces are available
+func checkSandboxSupport() error {
+	for _, path := range []string{"/proc/sys/kernel/unprivileged_userns_clone", "/proc/sys/user/max_user_namespaces"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(data)) == "0" {
+			return fmt.Errorf("user namespaces are disabled (%s is 0)", path)
+		}
+	}
+
+	if _, err := os.Stat("/proc/self/exe"); err != nil {
+		return fmt.Errorf("cannot locate executable for re-execution: %w", err)
+	}
+
+	return nil
+}
+
+// sandboxRlimits returns the rlimits for a sandboxed process. Memory is only
+// limited through the address space when no cgroup enforces it.
+func sandboxRlimits(limits ResourceLimits, timeout time.Duration, limitMemory bool) []sandboxRlimit {
+	cpuSeconds := uint64(timeout.Seconds()) + 1
+	rlimits := []sandboxRlimit{
+		{Resource: unix.RLIMIT_CORE, Limit: 0},
+		{Resource: unix.RLIMIT_CPU, Limit: cpuSeconds},
+		{Resource: unix.RLIMIT_FSIZE, Limit: sandboxMaxFileSizeBytes},
+		{Resource: unix.RLIMIT_NOFILE, Limit: sandboxMaxOpenFiles},
+	}
+	if limitMemory && limits.MemoryLimitBytes > 0 {
+		rlimits = append(rlimits, sandboxRlimit{Resource: unix.RLIMIT_AS, Limit: uint64(limits.MemoryLimitBytes)})
+	}
+	return rlimits
+}
+
+// runSandbox re-executes the current binary as the sandbox init process,
+// which sets up the namespaces and execs the script
+func runSandbox(ctx context.Context, spec *sandboxSpec, limits ResourceLimits, cgroupParent string, stdout, stderr io.Writer) (*sandboxOutcome, error) {
+	specReader, specWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spec pipe: %w", err)
+	}
+	defer specReader.Close()
+	defer specWriter.Close()
+
+	cmd := &exec.Cmd{
+		Path:       "/proc/self/exe",
+		Args:       []string{sandboxInitArg},
+		Env:        []string{},
+		Stdout:     stdout,
+		Stderr:     stderr,
+		ExtraFiles: []*os.File{specReader},
+		SysProcAttr: &syscall.SysProcAttr{
+			Cloneflags: sandboxCloneFlags,
+			UidMappings: []syscall.SysProcIDMap{
+				{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+			},
+			GidMappings: []syscall.SysProcIDMap{
+				{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+			},
+			GidMappingsEnableSetgroups: false,
+			Pdeathsig:                  syscall.SIGKILL,
+		},
+	}
+
+	var cgroup *sandboxCgroup
+	if cgroupParent != "" {
+		cgroup, err = createSandboxCgroup(cgroupParent, "voidrunner-"+uuid.New().String(), limits)
+		if err != nil {
+			return nil, err
+		}
+		defer cgroup.destroy()
+
+		// Start the process directly inside the cgroup so it is never unconstrained
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = cgroup.fd
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start sandbox: %w", err)
+	}
+	_ = specReader.Close()
+
+	if err := json.NewEncoder(specWriter).Encode(spec); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("failed to send sandbox spec: %w", err)
+	}
+	_ = specWriter.Close()
+
+	waitDone := make(chan error, 1)
+	go func() {
+		waitDone <- cmd.Wait()
+	}()
+
+	var waitErr error
+	select {
+	case waitErr = <-waitDone:
+	case <-ctx.Done():
+		// Killing the PID namespace init kills every process in the sandbox
+		if cgroup != nil {
+			cgroup.kill()
+		}
+		_ = cmd.Process.Kill()
+		waitErr = <-waitDone
+	}
+
+	outcome := &sandboxOutcome{}
+	if cgroup != nil {
+		outcome.MemoryUsageBytes = cgroup.memoryPeak()
+		outcome.OOMKilled = cgroup.oomKilled()
+	}
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return outcome, fmt.Errorf("failed to wait for sandbox: %w", waitErr)
+	}
+
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	switch {
+	case ok && status.Signaled():
+		outcome.Signal = int(status.Signal())
+		outcome.ExitCode = 128 + outcome.Signal
+	default:
+		outcome.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	return outcome, nil
+}
+
+// sandboxCgroup is a cgroup v2 directory holding a single sandboxed process
+type sandboxCgroup struct {
+	path string
+	fd   int
+}
+
+// createSandboxCgroup creates a cgroup below parent with the given limits
+func createSandboxCgroup(parent, name string, limits ResourceLimits) (*sandboxCgroup, error) {
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("sandbox cgroup parent %s is not a cgroup v2 directory: %w", parent, err)
+	}
+
+	// Enabling controllers fails if they are already enabled by the delegating manager
+	_ = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu +pids"), 0)
+
+	path := filepath.Join(parent, name)
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sandbox cgroup: %w", err)
+	}
+
+	cgroup := &sandboxCgroup{path: path, fd: -1}
+
+	settings := map[string]string{}
+	if limits.MemoryLimitBytes > 0 {
+		settings["memory.max"] = strconv.FormatInt(limits.MemoryLimitBytes, 10)
+		settings["memory.swap.max"] = "0"
+	}
+	if limits.CPUQuota > 0 {
+		settings["cpu.max"] = fmt.Sprintf("%d 100000", limits.CPUQuota)
+	}
+	if limits.PidsLimit > 0 {
+		settings["pids.max"] = strconv.FormatInt(limits.PidsLimit, 10)
+	}
+
+	for file, value := range settings {
+		err := os.WriteFile(filepath.Join(path, file), []byte(value), 0)
+		if err != nil && !(file == "memory.swap.max" && errors.Is(err, os.ErrNotExist)) {
+			cgroup.destroy()
+			return nil, fmt.Errorf("failed to set %s on sandbox cgroup: %w", file, err)
+		}
+	}
+
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		cgroup.destroy()
+		return nil, fmt.Errorf("failed to open sandbox cgroup: %w", err)
+	}
+	cgroup.fd = fd
+
+	return cgroup, nil
+}
+
+// kill terminates every process in the cgroup
+func (c *sandboxCgroup) kill() {
+	_ = os.WriteFile(filepath.Join(c.path, "cgroup.kill"), []byte("1"), 0)
+}
+
+// memoryPeak returns the peak memory usage of the cgroup, if the kernel reports it
+func (c *sandboxCgroup) memoryPeak() *int64 {
+	data, err := os.ReadFile(filepath.Join(c.path, "memory.peak"))
+	if err != nil {
+		return nil
+	}
+	peak, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &peak
+}
+
+// oomKilled reports whether the OOM killer killed a process in the cgroup
+func (c *sandboxCgroup) oomKilled() bool {
+	data, err := os.ReadFile(filepath.Join(c.path, "memory.events"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if count, ok := strings.CutPrefix(line, "oom_kill "); ok {
+			return strings.TrimSpace(count) != "0"
+		}
+	}
+	return false
+}
+
+// destroy removes the cgroup once its processes have exited
+func (c *sandboxCgroup) destroy() {
+	if c.fd >= 0 {
+		_ = unix.Close(c.fd)
+		c.fd = -1
+	}
+
+	// The kernel releases the cgroup shortly after the last process is reaped
+	for attempt := 0; attempt < 10; attempt++ {
+		if err := os.Remove(c.path); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// runSandboxInit runs inside the new namespaces as PID 1. It hardens the
+// mount namespace, applies rlimits, drops capabilities, installs the seccomp
+// filter and finally replaces itself with the script interpreter.
+func runSandboxInit() error {
+	// Thread-scoped state (no_new_privs, capabilities) must be set on the
+	// thread that calls execve
+	runtime.LockOSThread()
+
+	var spec sandboxSpec
+	specFile := os.NewFile(3, "spec")
+	err := json.NewDecoder(specFile).Decode(&spec)
+	_ = specFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read sandbox spec: %w", err)
+	}
+	if len(spec.Argv) == 0 {
+		return errors.New("sandbox spec has no command")
+	}
+
+	if err := setupSandboxMounts(&spec); err != nil {
+		return err
+	}
+
+	if err := unix.Sethostname([]byte("voidrunner")); err != nil {
+		return fmt.Errorf("failed to set hostname: %w", err)
+	}
+
+	if err := unix.Chdir(spec.WorkingDir); err != nil {
+		return fmt.Errorf("failed to change to working directory: %w", err)
+	}
+
+	binary, err := lookPathInEnv(spec.Argv[0], spec.Env)
+	if err != nil {
+		return err
+	}
+
+	// Prepare everything execve needs up front: once the address space is
+	// limited, the Go runtime may be unable to grow its heap
+	binaryPtr, err := unix.BytePtrFromString(binary)
+	if err != nil {
+		return fmt.Errorf("invalid command: %w", err)
+	}
+	argv, err := syscall.SlicePtrFromStrings(spec.Argv)
+	if err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	envv, err := syscall.SlicePtrFromStrings(spec.Env)
+	if err != nil {
+		return fmt.Errorf("invalid environment: %w", err)
+	}
+	rlimits := make([]unix.Rlimit, len(spec.Rlimits))
+	for i, rlimit := range spec.Rlimits {
+		rlimits[i] = unix.Rlimit{Cur: rlimit.Limit, Max: rlimit.Limit}
+	}
+
+	if err := dropCapabilities(); err != nil {
+		return err
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+
+	if err := installSeccompFilter(spec.Syscalls); err != nil {
+		return err
+	}
+
+	for i, rlimit := range spec.Rlimits {
+		if err := unix.Setrlimit(rlimit.Resource, &rlimits[i]); err != nil {
+			return fmt.Errorf("failed to set rlimit %d: %w", rlimit.Resource, err)
+		}
+	}
+
+	_, _, errno := unix.RawSyscall(unix.SYS_EXECVE,
+		uintptr(unsafe.Pointer(binaryPtr)),
+		uintptr(unsafe.Pointer(&argv[0])),
+		uintptr(unsafe.Pointer(&envv[0])))
+	return fmt.Errorf("failed to execute %s: %w", binary, errno)
+}
+
+// setupSandboxMounts makes the host filesystem read-only, mounts a private
+// /proc, masks sensitive host paths and mounts the writable tmpfs directories
+func setupSandboxMounts(spec *sandboxSpec) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+
+	attr := &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY | unix.MOUNT_ATTR_NOSUID | unix.MOUNT_ATTR_NODEV}
+	if err := unix.MountSetattr(unix.AT_FDCWD, "/", unix.AT_RECURSIVE, attr); err != nil {
+		return fmt.Errorf("failed to make host filesystem read-only: %w", err)
+	}
+
+	if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("failed to mount /proc: %w", err)
+	}
+
+	for _, path := range spec.MaskedPaths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := unix.Mount("tmpfs", path, "tmpfs", unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "size=0"); err != nil {
+			return fmt.Errorf("failed to mask %s: %w", path, err)
+		}
+	}
+
+	// Mount parents before children
+	paths := make([]string, 0, len(spec.TmpfsMounts))
+	for path := range spec.TmpfsMounts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		flags, data := parseTmpfsOptions(spec.TmpfsMounts[path])
+		if err := unix.Mount("tmpfs", path, "tmpfs", flags|unix.MS_NODEV, data); err != nil {
+			return fmt.Errorf("failed to mount tmpfs on %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// parseTmpfsOptions splits Docker-style tmpfs options into mount flags and data
+func parseTmpfsOptions(options string) (uintptr, string) {
+	var flags uintptr
+	var data []string
+
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "", "rw":
+		case "ro":
+			flags |= unix.MS_RDONLY
+		case "noexec":
+			flags |= unix.MS_NOEXEC
+		case "nosuid":
+			flags |= unix.MS_NOSUID
+		case "nodev":
+			flags |= unix.MS_NODEV
+		default:
+			data = append(data, option)
+		}
+	}
+
+	return flags, strings.Join(data, ",")
+}
+
+// lookPathInEnv resolves a command against the PATH of the script environment
+func lookPathInEnv(command string, env []string) (string, error) {
+	if strings.Contains(command, "/") {
+		return command, nil
+	}
+
+	path := ""
+	for _, entry := range env {
+		if value, ok := strings.CutPrefix(entry, "PATH="); ok {
+			path = value
+		}
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		candidate := filepath.Join(dir, command)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("command %q not found in sandbox PATH", command)
+}
+
+// dropCapabilities clears the bounding set and all capability sets, so the
+// script runs without privileges even as root inside the user namespace
+func dropCapabilities() error {
+	lastCap := unix.CAP_LAST_CAP
+	if data, err := os.ReadFile("/proc/sys/kernel/cap_last_cap"); err == nil {
+		if value, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			lastCap = value
+		}
+	}
+
+	for capability := 0; capability <= lastCap; capability++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0); err != nil && !errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("failed to drop capability %d: %w", capability, err)
+		}
+	}
+
+	header := &unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	data := [2]unix.CapUserData{}
+	if err := unix.Capset(header, &data[0]); err != nil {
+		return fmt.Errorf("failed to clear capabilities: %w", err)
+	}
+
+	return nil
+}
+
+// installSeccompFilter installs an allowlist filter for all threads. Syscalls
+// outside the allowlist fail with EPERM.
+func installSeccompFilter(syscallNames []string) error {
+	filter := buildSeccompFilter(resolveSyscalls(syscallNames))
+	program := &unix.SockFprog{
+		Len:    uint16(len(filter)),
+		Filter: &filter[0],
+	}
+
+	_, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER,
+		unix.SECCOMP_FILTER_FLAG_TSYNC, uintptr(unsafe.Pointer(program)))
+	if errno != 0 {
+		return fmt.Errorf("failed to install seccomp filter: %w", errno)
+	}
+
+	return nil
+}
+
+// resolveSyscalls maps syscall names to numbers for the host architecture,
+// skipping names the architecture does not have
+func resolveSyscalls(names []string) []uint32 {
+	seen := make(map[uint32]bool, len(names))
+	numbers := make([]uint32, 0, len(names))
+	for _, name := range names {
+		number, ok := seccompSyscallNumbers[name]
+		if !ok || seen[number] {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
+// Offsets into struct seccomp_data
+const (
+	seccompDataNrOffset   = 0
+	seccompDataArchOffset = 4
+)
+
+// buildSeccompFilter assembles a BPF program that kills processes using a
+// foreign architecture, allows the given syscalls and denies everything else
+func buildSeccompFilter(syscalls []uint32) []unix.SockFilter {
+	filter := []unix.SockFilter{
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArchOffset),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, seccompAuditArch, 1, 0),
+		bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_PROCESS),
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataNrOffset),
+	}
+
+	for _, number := range syscalls {
+		filter = append(filter,
+			bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, number, 0, 1),
+			bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ALLOW),
+		)
+	}
+
+	return append(filter, bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ERRNO|uint32(unix.EPERM)))
+}
+
+// bpfStmt builds a BPF statement
+func bpfStmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+// bpfJump builds a BPF conditional jump
+func bpfJump(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
@@ -0,0 +1,69 @@
+//go:build linux && (amd64 || arm64)
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestResolveSyscalls(t *testing.T) {
+	numbers := resolveSyscalls([]string{"write", "read", "write", "not_a_syscall"})
+
+	assert.Equal(t, []uint32{uint32(unix.SYS_READ), uint32(unix.SYS_WRITE)}, numbers)
+}
+
+func TestBuildSeccompFilter(t *testing.T) {
+	filter := buildSeccompFilter([]uint32{uint32(unix.SYS_READ), uint32(unix.SYS_WRITE)})
+
+	// Architecture check, syscall load, two allow rules and the default deny
+	assert.Len(t, filter, 4+2*2+1)
+	assert.Equal(t, uint32(seccompAuditArch), filter[1].K)
+	assert.Equal(t, uint32(unix.SECCOMP_RET_KILL_PROCESS), filter[2].K)
+	assert.Equal(t, uint32(unix.SYS_READ), filter[4].K)
+	assert.Equal(t, uint32(unix.SECCOMP_RET_ALLOW), filter[5].K)
+
+	last := filter[len(filter)-1]
+	assert.Equal(t, uint32(unix.SECCOMP_RET_ERRNO|uint32(unix.EPERM)), last.K)
+}
+
+func TestDefaultSyscallsResolve(t *testing.T) {
+	config := NewDefaultConfig()
+	names := append(append([]string{}, sandboxBaseSyscalls...), config.Security.AllowedSyscalls...)
+
+	numbers := resolveSyscalls(names)
+	assert.Contains(t, numbers, uint32(unix.SYS_EXECVE))
+	assert.Contains(t, numbers, uint32(unix.SYS_EXIT_GROUP))
+	assert.NotContains(t, numbers, uint32(unix.SYS_MOUNT))
+	assert.NotContains(t, numbers, uint32(unix.SYS_PTRACE))
+}
+
+func TestParseTmpfsOptions(t *testing.T) {
+	flags, data := parseTmpfsOptions("rw,noexec,nosuid,size=100m")
+
+	assert.Equal(t, uintptr(unix.MS_NOEXEC|unix.MS_NOSUID), flags)
+	assert.Equal(t, "size=100m", data)
+}
+
+func TestSandboxRlimits(t *testing.T) {
+	limits := ResourceLimits{MemoryLimitBytes: 64 * 1024 * 1024}
+
+	rlimits := sandboxRlimits(limits, 10*time.Second, false)
+	assert.Contains(t, rlimits, sandboxRlimit{Resource: unix.RLIMIT_CPU, Limit: 11})
+	assert.NotContains(t, rlimits, sandboxRlimit{Resource: unix.RLIMIT_AS, Limit: 64 * 1024 * 1024})
+
+	rlimits = sandboxRlimits(limits, 10*time.Second, true)
+	assert.Contains(t, rlimits, sandboxRlimit{Resource: unix.RLIMIT_AS, Limit: 64 * 1024 * 1024})
+}
+
+func TestLookPathInEnv(t *testing.T) {
+	path, err := lookPathInEnv("sh", []string{"PATH=/usr/bin:/bin"})
+	assert.NoError(t, err)
+	assert.Contains(t, path, "/sh")
+
+	_, err = lookPathInEnv("definitely-not-a-command", []string{"PATH=/usr/bin:/bin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+//go:build !linux || !(amd64 || arm64)
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// errSandboxUnsupported is returned on platforms without the process sandbox
+var errSandboxUnsupported = errors.New("process sandbox requires Linux on amd64 or arm64")
+
+// checkSandboxSupport reports that the process sandbox is unavailable
+func checkSandboxSupport() error {
+	return errSandboxUnsupported
+}
+
+// sandboxRlimits is not used on unsupported platforms
+func sandboxRlimits(limits ResourceLimits, timeout time.Duration, limitMemory bool) []sandboxRlimit {
+	return nil
+}
+
+// runSandbox reports that the process sandbox is unavailable
+func runSandbox(ctx context.Context, spec *sandboxSpec, limits ResourceLimits, cgroupParent string, stdout, stderr io.Writer) (*sandboxOutcome, error) {
+	return nil, errSandboxUnsupported
+}
+
+// runSandboxInit reports that the process sandbox is unavailable
+func runSandboxInit() error {
+	return errSandboxUnsupported
+}
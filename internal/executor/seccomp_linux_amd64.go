@@ -0,0 +1,173 @@
+package executor
+
+import "golang.org/x/sys/unix"
+
+// seccompAuditArch is the seccomp architecture of x86-64 processes
+const seccompAuditArch = unix.AUDIT_ARCH_X86_64
+
+// seccompSyscallNumbers maps syscall names to their x86-64 numbers
+var seccompSyscallNumbers = map[string]uint32{
+	"accept":            unix.SYS_ACCEPT,
+	"accept4":           unix.SYS_ACCEPT4,
+	"access":            unix.SYS_ACCESS,
+	"alarm":             unix.SYS_ALARM,
+	"arch_prctl":        unix.SYS_ARCH_PRCTL,
+	"bind":              unix.SYS_BIND,
+	"brk":               unix.SYS_BRK,
+	"chdir":             unix.SYS_CHDIR,
+	"chmod":             unix.SYS_CHMOD,
+	"chown":             unix.SYS_CHOWN,
+	"clock_gettime":     unix.SYS_CLOCK_GETTIME,
+	"clock_nanosleep":   unix.SYS_CLOCK_NANOSLEEP,
+	"clone":             unix.SYS_CLONE,
+	"clone3":            unix.SYS_CLONE3,
+	"close":             unix.SYS_CLOSE,
+	"connect":           unix.SYS_CONNECT,
+	"creat":             unix.SYS_CREAT,
+	"dup":               unix.SYS_DUP,
+	"dup2":              unix.SYS_DUP2,
+	"dup3":              unix.SYS_DUP3,
+	"epoll_create":      unix.SYS_EPOLL_CREATE,
+	"epoll_create1":     unix.SYS_EPOLL_CREATE1,
+	"epoll_ctl":         unix.SYS_EPOLL_CTL,
+	"epoll_pwait":       unix.SYS_EPOLL_PWAIT,
+	"epoll_wait":        unix.SYS_EPOLL_WAIT,
+	"eventfd2":          unix.SYS_EVENTFD2,
+	"execve":            unix.SYS_EXECVE,
+	"exit":              unix.SYS_EXIT,
+	"exit_group":        unix.SYS_EXIT_GROUP,
+	"faccessat":         unix.SYS_FACCESSAT,
+	"faccessat2":        unix.SYS_FACCESSAT2,
+	"fchdir":            unix.SYS_FCHDIR,
+	"fchmod":            unix.SYS_FCHMOD,
+	"fchmodat":          unix.SYS_FCHMODAT,
+	"fchown":            unix.SYS_FCHOWN,
+	"fchownat":          unix.SYS_FCHOWNAT,
+	"fcntl":             unix.SYS_FCNTL,
+	"fdatasync":         unix.SYS_FDATASYNC,
+	"flock":             unix.SYS_FLOCK,
+	"fork":              unix.SYS_FORK,
+	"fstat":             unix.SYS_FSTAT,
+	"fstatfs":           unix.SYS_FSTATFS,
+	"fsync":             unix.SYS_FSYNC,
+	"ftruncate":         unix.SYS_FTRUNCATE,
+	"futex":             unix.SYS_FUTEX,
+	"getcpu":            unix.SYS_GETCPU,
+	"getcwd":            unix.SYS_GETCWD,
+	"getdents":          unix.SYS_GETDENTS,
+	"getdents64":        unix.SYS_GETDENTS64,
+	"getegid":           unix.SYS_GETEGID,
+	"geteuid":           unix.SYS_GETEUID,
+	"getgid":            unix.SYS_GETGID,
+	"getgroups":         unix.SYS_GETGROUPS,
+	"getitimer":         unix.SYS_GETITIMER,
+	"getpeername":       unix.SYS_GETPEERNAME,
+	"getpgid":           unix.SYS_GETPGID,
+	"getpgrp":           unix.SYS_GETPGRP,
+	"getpid":            unix.SYS_GETPID,
+	"getppid":           unix.SYS_GETPPID,
+	"getpriority":       unix.SYS_GETPRIORITY,
+	"getrandom":         unix.SYS_GETRANDOM,
+	"getresgid":         unix.SYS_GETRESGID,
+	"getresuid":         unix.SYS_GETRESUID,
+	"getrlimit":         unix.SYS_GETRLIMIT,
+	"getrusage":         unix.SYS_GETRUSAGE,
+	"getsid":            unix.SYS_GETSID,
+	"getsockname":       unix.SYS_GETSOCKNAME,
+	"getsockopt":        unix.SYS_GETSOCKOPT,
+	"gettid":            unix.SYS_GETTID,
+	"gettimeofday":      unix.SYS_GETTIMEOFDAY,
+	"getuid":            unix.SYS_GETUID,
+	"ioctl":             unix.SYS_IOCTL,
+	"kill":              unix.SYS_KILL,
+	"linkat":            unix.SYS_LINKAT,
+	"listen":            unix.SYS_LISTEN,
+	"lseek":             unix.SYS_LSEEK,
+	"lstat":             unix.SYS_LSTAT,
+	"madvise":           unix.SYS_MADVISE,
+	"memfd_create":      unix.SYS_MEMFD_CREATE,
+	"mkdir":             unix.SYS_MKDIR,
+	"mkdirat":           unix.SYS_MKDIRAT,
+	"mmap":              unix.SYS_MMAP,
+	"mprotect":          unix.SYS_MPROTECT,
+	"mremap":            unix.SYS_MREMAP,
+	"munmap":            unix.SYS_MUNMAP,
+	"nanosleep":         unix.SYS_NANOSLEEP,
+	"newfstatat":        unix.SYS_NEWFSTATAT,
+	"open":              unix.SYS_OPEN,
+	"openat":            unix.SYS_OPENAT,
+	"pause":             unix.SYS_PAUSE,
+	"pipe":              unix.SYS_PIPE,
+	"pipe2":             unix.SYS_PIPE2,
+	"poll":              unix.SYS_POLL,
+	"ppoll":             unix.SYS_PPOLL,
+	"prctl":             unix.SYS_PRCTL,
+	"pread64":           unix.SYS_PREAD64,
+	"preadv":            unix.SYS_PREADV,
+	"prlimit64":         unix.SYS_PRLIMIT64,
+	"pselect6":          unix.SYS_PSELECT6,
+	"pwrite64":          unix.SYS_PWRITE64,
+	"pwritev":           unix.SYS_PWRITEV,
+	"read":              unix.SYS_READ,
+	"readlink":          unix.SYS_READLINK,
+	"readlinkat":        unix.SYS_READLINKAT,
+	"readv":             unix.SYS_READV,
+	"recvfrom":          unix.SYS_RECVFROM,
+	"recvmsg":           unix.SYS_RECVMSG,
+	"rename":            unix.SYS_RENAME,
+	"renameat":          unix.SYS_RENAMEAT,
+	"rmdir":             unix.SYS_RMDIR,
+	"rseq":              unix.SYS_RSEQ,
+	"rt_sigaction":      unix.SYS_RT_SIGACTION,
+	"rt_sigprocmask":    unix.SYS_RT_SIGPROCMASK,
+	"rt_sigreturn":      unix.SYS_RT_SIGRETURN,
+	"rt_sigsuspend":     unix.SYS_RT_SIGSUSPEND,
+	"rt_sigtimedwait":   unix.SYS_RT_SIGTIMEDWAIT,
+	"sched_getaffinity": unix.SYS_SCHED_GETAFFINITY,
+	"sched_getparam":    unix.SYS_SCHED_GETPARAM,
+	"sched_setaffinity": unix.SYS_SCHED_SETAFFINITY,
+	"sched_yield":       unix.SYS_SCHED_YIELD,
+	"select":            unix.SYS_SELECT,
+	"sendmsg":           unix.SYS_SENDMSG,
+	"sendto":            unix.SYS_SENDTO,
+	"set_robust_list":   unix.SYS_SET_ROBUST_LIST,
+	"set_tid_address":   unix.SYS_SET_TID_ADDRESS,
+	"setgid":            unix.SYS_SETGID,
+	"setgroups":         unix.SYS_SETGROUPS,
+	"sethostname":       unix.SYS_SETHOSTNAME,
+	"setitimer":         unix.SYS_SETITIMER,
+	"setpgid":           unix.SYS_SETPGID,
+	"setpriority":       unix.SYS_SETPRIORITY,
+	"setregid":          unix.SYS_SETREGID,
+	"setresgid":         unix.SYS_SETRESGID,
+	"setresuid":         unix.SYS_SETRESUID,
+	"setreuid":          unix.SYS_SETREUID,
+	"setrlimit":         unix.SYS_SETRLIMIT,
+	"setsid":            unix.SYS_SETSID,
+	"setsockopt":        unix.SYS_SETSOCKOPT,
+	"setuid":            unix.SYS_SETUID,
+	"shutdown":          unix.SYS_SHUTDOWN,
+	"sigaltstack":       unix.SYS_SIGALTSTACK,
+	"socket":            unix.SYS_SOCKET,
+	"socketpair":        unix.SYS_SOCKETPAIR,
+	"stat":              unix.SYS_STAT,
+	"statfs":            unix.SYS_STATFS,
+	"statx":             unix.SYS_STATX,
+	"symlinkat":         unix.SYS_SYMLINKAT,
+	"sysinfo":           unix.SYS_SYSINFO,
+	"tgkill":            unix.SYS_TGKILL,
+	"timerfd_create":    unix.SYS_TIMERFD_CREATE,
+	"timerfd_settime":   unix.SYS_TIMERFD_SETTIME,
+	"times":             unix.SYS_TIMES,
+	"truncate":          unix.SYS_TRUNCATE,
+	"umask":             unix.SYS_UMASK,
+	"uname":             unix.SYS_UNAME,
+	"unlink":            unix.SYS_UNLINK,
+	"unlinkat":          unix.SYS_UNLINKAT,
+	"utimensat":         unix.SYS_UTIMENSAT,
+	"vfork":             unix.SYS_VFORK,
+	"wait4":             unix.SYS_WAIT4,
+	"waitid":            unix.SYS_WAITID,
+	"write":             unix.SYS_WRITE,
+	"writev":            unix.SYS_WRITEV,
+}
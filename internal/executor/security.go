@@ -5,21 +5,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 // SecurityManager handles security configuration and validation
 type SecurityManager struct {
-	config *Config
+	config   *Config
+	analyzer *analyzer.Pipeline
 }
 
 // NewSecurityManager creates a new security manager
 func NewSecurityManager(config *Config) *SecurityManager {
 	return &SecurityManager{
-		config: config,
+		config:   config,
+		analyzer: analyzer.NewPipeline(config.Security.ScriptAnalysisMode),
 	}
 }
 
@@ -30,7 +32,7 @@ func (sm *SecurityManager) BuildSecurityConfig(task *models.Task) (*SecurityConf
 	}
 
 	// Validate script content for security issues
-	if err := sm.ValidateScriptContent(task.ScriptContent, task.ScriptType); err != nil {
+	if err := sm.ValidateTaskScript(task); err != nil {
 		return nil, fmt.Errorf("script security validation failed: %w", err)
 	}
 
@@ -75,579 +77,26 @@ func (sm *SecurityManager) BuildSecurityConfig(task *models.Task) (*SecurityConf
 
 // ValidateScriptContent performs security validation on script content
 func (sm *SecurityManager) ValidateScriptContent(content string, scriptType models.ScriptType) error {
-	if content == "" {
-		return NewSecurityError("validate_script", "script content is empty", nil)
-	}
-
-	// Convert to lowercase for case-insensitive checks
-	lowerContent := strings.ToLower(content)
-
-	// Check for script-specific dangerous patterns first
-	switch scriptType {
-	case models.ScriptTypePython:
-		if err := sm.validatePythonScript(lowerContent); err != nil {
-			return err
-		}
-	case models.ScriptTypeBash:
-		if err := sm.validateBashScript(lowerContent); err != nil {
-			return err
-		}
-	case models.ScriptTypeJavaScript:
-		if err := sm.validateJavaScriptScript(lowerContent); err != nil {
-			return err
-		}
-	}
-
-	// Check for general dangerous patterns that are not script-specific
-	dangerousPatterns := []string{
-		// File system operations
-		"rm -rf",
-		"rm -r",
-		"rmdir",
-		"deltree",
-		"format",
-		"fdisk",
-		"mkfs",
-		"dd if=",
-
-		// Network operations
-		"wget",
-		"curl",
-
-		// Docker/container escape attempts
-		"docker",
-		"kubectl",
-		"podman",
-		"containerd",
-		"runc",
-		"/var/run/docker.sock",
-
-		// Crypto mining (common patterns)
-		"xmrig",
-		"cpuminer",
-		"ccminer",
-		"minerd",
-		"stratum",
-
-		// Privilege escalation
-		"setuid",
-		"setgid",
-		"ptrace",
-		"chroot",
-	}
-
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(lowerContent, pattern) {
-			return NewSecurityError("validate_script",
-				fmt.Sprintf("potentially dangerous pattern detected: %s", pattern), nil)
-		}
-	}
-
-	return nil
+	return sm.validateScript(content, scriptType, analyzer.Options{})
 }
 
-// validatePythonScript performs Python-specific security validation
-func (sm *SecurityManager) validatePythonScript(content string) error {
-	// Define safe imports that are allowed
-	safeImports := map[string]bool{
-		"import math":             true,
-		"import json":             true,
-		"import datetime":         true,
-		"import random":           true,
-		"import time":             true,
-		"import re":               true,
-		"import collections":      true,
-		"import itertools":        true,
-		"import functools":        true,
-		"import decimal":          true,
-		"import fractions":        true,
-		"import statistics":       true,
-		"import string":           true,
-		"import textwrap":         true,
-		"import unicodedata":      true,
-		"import base64":           true,
-		"import binascii":         true,
-		"import hashlib":          true,
-		"import hmac":             true,
-		"import uuid":             true,
-		"from math import":        true,
-		"from json import":        true,
-		"from datetime import":    true,
-		"from random import":      true,
-		"from time import":        true,
-		"from re import":          true,
-		"from collections import": true,
-		"from itertools import":   true,
-		"from functools import":   true,
-		"from decimal import":     true,
-		"from fractions import":   true,
-		"from statistics import":  true,
-		"from string import":      true,
-		"from textwrap import":    true,
-		"from unicodedata import": true,
-		"from base64 import":      true,
-		"from binascii import":    true,
-		"from hashlib import":     true,
-		"from hmac import":        true,
-		"from uuid import":        true,
-	}
-
-	// Check for dangerous patterns
-	dangerousPythonPatterns := []string{
-		// System and process manipulation
-		"import os",
-		"import subprocess",
-		"import sys",
-		"import shutil",
-		"import tempfile",
-		"import multiprocessing",
-		"import threading",
-		"import signal",
-		"import atexit",
-		"from os import",
-		"from subprocess import",
-		"from sys import",
-		"from shutil import",
-		"from tempfile import",
-		"from multiprocessing import",
-		"from threading import",
-		"from signal import",
-		"from atexit import",
-
-		// Network and external communication
-		"import socket",
-		"import urllib",
-		"import requests",
-		"import http",
-		"import smtplib",
-		"import ftplib",
-		"import poplib",
-		"import imaplib",
-		"import telnetlib",
-		"from socket import",
-		"from urllib import",
-		"from requests import",
-		"from http import",
-		"from smtplib import",
-		"from ftplib import",
-		"from poplib import",
-		"from imaplib import",
-		"from telnetlib import",
-
-		// Dynamic code execution
-		"__import__",
-		"eval(",
-		"exec(",
-		"compile(",
-		"globals()",
-		"locals()",
-		"vars()",
-
-		// Dangerous reflection and attribute access
-		"getattr(",
-		"setattr(",
-		"delattr(",
-		"hasattr(",
-
-		// Dangerous file system access patterns (allow basic file operations within container)
-		// Note: Container filesystem is read-only except for /tmp, so file access is limited
-
-		// User input (can be dangerous in containers)
-		"input(",
-		"raw_input(",
-
-		// Import manipulation
-		"importlib",
-		"__builtins__",
-		"__name__",
-		"__file__",
-		"__doc__",
-		"__package__",
-		"__loader__",
-		"__spec__",
-		"__path__",
-		"__cached__",
-	}
-
-	// Split content into lines and check each import statement
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Check if this line contains an import statement
-		if strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "from ") {
-			// Check if it's a safe import
-			isSafe := false
-			for safeImport := range safeImports {
-				if strings.HasPrefix(line, safeImport) {
-					isSafe = true
-					break
-				}
-			}
-
-			if !isSafe {
-				// Check if it's a dangerous import
-				for _, pattern := range dangerousPythonPatterns {
-					if strings.HasPrefix(line, pattern) {
-						return NewSecurityError("validate_python_script",
-							fmt.Sprintf("dangerous Python import detected: %s", pattern), nil)
-					}
-				}
-			}
-		}
-	}
-
-	// Check for dangerous patterns in the entire content
-	for _, pattern := range dangerousPythonPatterns {
-		// Skip import patterns as they're handled above
-		if strings.HasPrefix(pattern, "import ") || strings.HasPrefix(pattern, "from ") {
-			continue
-		}
-
-		if strings.Contains(content, pattern) {
-			return NewSecurityError("validate_python_script",
-				fmt.Sprintf("dangerous Python pattern detected: %s", pattern), nil)
-		}
-	}
-
-	return nil
-}
-
-// validateBashScript performs Bash-specific security validation
-func (sm *SecurityManager) validateBashScript(content string) error {
-	// Define truly dangerous patterns that should be blocked
-	dangerousBashPatterns := []string{
-		// Network access
-		"/dev/tcp/", // TCP redirection
-		"/dev/udp/", // UDP redirection
-
-		// System access and privilege escalation
-		"source /",
-		". /",
-		"export HOME=",
-		"export PATH=",
-		"export LD_",
-		"export DYLD_",
-		"history -c",
-		"history -d",
-		"fc -s",
-
-		// Dangerous background processes
-		"nohup ",
-		"disown ",
-		"setsid ",
-		"daemon ",
-
-		// Process control
-		"trap ",
-		"kill -",
-		"killall ",
-		"pkill ",
-
-		// File system manipulation
-		"mount ",
-		"umount ",
-		"chroot ",
-		"pivot_root ",
-
-		// Dangerous commands
-		"sudo ",
-		"su ",
-		"passwd ",
-		"chsh ",
-		"chfn ",
-		"newgrp ",
-		"sg ",
-
-		// System information gathering (allow basic commands like whoami, id for educational use)
-		"uname -a",
-		"hostname ",
-		"last ",
-		"w ",
-		"finger ",
-
-		// Network commands
-		"ping ",
-		"traceroute ",
-		"nslookup ",
-		"dig ",
-		"host ",
-		"netstat ",
-		"ss ",
-		"lsof ",
-		"fuser ",
-
-		// Package management
-		"apt ",
-		"yum ",
-		"rpm ",
-		"dpkg ",
-		"snap ",
-		"flatpak ",
-		"brew ",
-		"port ",
-		"emerge ",
-		"pacman ",
-		"zypper ",
-
-		// Dangerous files and directories (allow /tmp/ as containers need temp space)
-		"/etc/passwd",
-		"/etc/shadow",
-		"/etc/group",
-		"/etc/sudoers",
-		"/root/",
-		"/home/",
-		"/Users/",
-		"/var/log/",
-		"/var/run/",
-		"/proc/",
-		"/sys/",
-		"/dev/",
-		"/var/tmp/",
-
-		// Dangerous redirection patterns
-		"> /",
-		">> /",
-		"< /",
-		"2> /",
-		"2>> /",
-		"&> /",
-		"&>> /",
-
-		// Remote access
-		"ssh ",
-		"scp ",
-		"rsync ",
-		"rcp ",
-		"telnet ",
-		"ftp ",
-		"sftp ",
-		"nc ",
-		"netcat ",
-		"socat ",
-
-		// Archive and compression (can be used for data exfiltration)
-		"tar ",
-		"gzip ",
-		"gunzip ",
-		"zip ",
-		"unzip ",
-		"7z ",
-		"rar ",
-		"unrar ",
-
-		// Dangerous environment variables
-		"$HOME",
-		"$PATH",
-		"$USER",
-		"$SHELL",
-		"$PWD",
-		"$OLDPWD",
-		"$PS1",
-		"$PS2",
-		"$IFS",
-		"$0",
-		"$$",
-		"$!",
-		"$?",
-	}
-
-	// Check for dangerous patterns
-	for _, pattern := range dangerousBashPatterns {
-		if strings.Contains(content, pattern) {
-			return NewSecurityError("validate_bash_script",
-				fmt.Sprintf("dangerous Bash pattern detected: %s", pattern), nil)
-		}
-	}
-
-	// Check for command substitution in dangerous contexts
-	if strings.Contains(content, "$(") {
-		// Allow simple command substitution for safe operations
-		// Block if it contains dangerous commands within $()
-		for _, pattern := range dangerousBashPatterns {
-			if strings.Contains(content, "$("+pattern) {
-				return NewSecurityError("validate_bash_script",
-					fmt.Sprintf("dangerous command substitution detected: $(%s", pattern), nil)
-			}
-		}
-	}
-
-	// Check for backtick command substitution
-	if strings.Contains(content, "`") {
-		// Backticks are generally more dangerous, allow only very simple cases
-		backtickPattern := "`[^`]*`"
-		if matched, _ := regexp.MatchString(backtickPattern, content); matched {
-			return NewSecurityError("validate_bash_script",
-				"backtick command substitution detected (use $() instead)", nil)
-		}
-	}
-
-	return nil
+// ValidateTaskScript validates a task's script for security issues. Network
+// client checks are skipped for tasks whose network policy grants access, as
+// the egress proxy then decides which connections are allowed.
+func (sm *SecurityManager) ValidateTaskScript(task *models.Task) error {
+	return sm.validateScript(task.ScriptContent, task.ScriptType, analyzer.Options{AllowNetwork: task.HasNetworkAccess()})
 }
 
-// validateJavaScriptScript performs JavaScript-specific security validation
-func (sm *SecurityManager) validateJavaScriptScript(content string) error {
-	// Define safe require patterns that are allowed
-	safeRequirePatterns := []string{
-		"require('crypto')",
-		"require('util')",
-		"require('path')",
-		"require('url')",
-		"require('querystring')",
-		"require('string_decoder')",
-		"require('buffer')",
-		"require('events')",
-		"require('stream')",
-		"require('assert')",
-		"require('console')",
-		"require('timers')",
-		"require(\"crypto\")",
-		"require(\"util\")",
-		"require(\"path\")",
-		"require(\"url\")",
-		"require(\"querystring\")",
-		"require(\"string_decoder\")",
-		"require(\"buffer\")",
-		"require(\"events\")",
-		"require(\"stream\")",
-		"require(\"assert\")",
-		"require(\"console\")",
-		"require(\"timers\")",
-	}
-
-	// Define truly dangerous patterns that should be blocked
-	dangerousJSPatterns := []string{
-		// Node.js system access
-		"require('fs')",
-		"require('child_process')",
-		"require('os')",
-		"require('process')",
-		"require('cluster')",
-		"require('worker_threads')",
-		"require('vm')",
-		"require('module')",
-		"require('repl')",
-		"require('readline')",
-		"require('tty')",
-		"require(\"fs\")",
-		"require(\"child_process\")",
-		"require(\"os\")",
-		"require(\"process\")",
-		"require(\"cluster\")",
-		"require(\"worker_threads\")",
-		"require(\"vm\")",
-		"require(\"module\")",
-		"require(\"repl\")",
-		"require(\"readline\")",
-		"require(\"tty\")",
-
-		// Network access
-		"require('http')",
-		"require('https')",
-		"require('net')",
-		"require('dgram')",
-		"require('dns')",
-		"require('tls')",
-		"require(\"http\")",
-		"require(\"https\")",
-		"require(\"net\")",
-		"require(\"dgram\")",
-		"require(\"dns\")",
-		"require(\"tls\")",
-
-		// Dynamic code execution
-		"eval(",
-		"new Function(",
-		"Function(",
-		"setTimeout(",
-		"setInterval(",
-		"setImmediate(",
-
-		// Process and environment access
-		"process.exit(",
-		"process.env",
-		"process.argv",
-		"process.cwd(",
-		"process.chdir(",
-		"process.kill(",
-		"process.abort(",
-		"process.platform",
-		"process.version",
-		"process.versions",
-
-		// Global object access
-		"global.",
-		"globalThis.",
-		"window.",
-		"document.",
-		"navigator.",
-		"location.",
-		"history.",
-		"localStorage.",
-		"sessionStorage.",
-
-		// Dangerous constructor access
-		"constructor(",
-		"__proto__",
-		"prototype",
-
-		// Import statements (ES6 modules)
-		"import ",
-		"export ",
-		"import(", // Dynamic imports
-
-		// Dangerous eval-like functions
-		"execScript(",
-		"msWriteProfilerMark(",
-		"webkitRequestFileSystem(",
-		"webkitResolveLocalFileSystemURL(",
-	}
-
-	// Check for dangerous patterns
-	for _, pattern := range dangerousJSPatterns {
-		if strings.Contains(content, pattern) {
-			// Check if it's a safe require pattern first
-			isSafeRequire := false
-			for _, safePattern := range safeRequirePatterns {
-				if strings.Contains(content, safePattern) {
-					isSafeRequire = true
-					break
-				}
-			}
-
-			if !isSafeRequire {
-				return NewSecurityError("validate_javascript_script",
-					fmt.Sprintf("dangerous JavaScript pattern detected: %s", pattern), nil)
-			}
-		}
+// validateScript runs script analysis on the content. Findings only reject
+// the script when the analysis mode is block.
+func (sm *SecurityManager) validateScript(content string, scriptType models.ScriptType, opts analyzer.Options) error {
+	if content == "" {
+		return NewSecurityError("validate_script", "script content is empty", nil)
 	}
 
-	// Check for require patterns that are not in the safe list
-	requirePattern := `require\s*\(\s*['"](.*?)['"]\s*\)`
-	re := regexp.MustCompile(requirePattern)
-	matches := re.FindAllStringSubmatch(content, -1)
-
-	for _, match := range matches {
-		if len(match) > 1 {
-			moduleName := match[1]
-			// Check if this module is in our safe list
-			isSafe := false
-			safeModules := []string{"crypto", "util", "path", "url", "querystring", "string_decoder", "buffer", "events", "stream", "assert", "console", "timers"}
-			for _, safeModule := range safeModules {
-				if moduleName == safeModule {
-					isSafe = true
-					break
-				}
-			}
-
-			if !isSafe {
-				return NewSecurityError("validate_javascript_script",
-					fmt.Sprintf("unsafe require module detected: %s", moduleName), nil)
-			}
-		}
+	report := sm.analyzer.Analyze(scriptType, content, opts)
+	if err := report.Err(); err != nil {
+		return NewSecurityError("validate_script", "script blocked by script analysis", err)
 	}
 
 	return nil
@@ -874,9 +323,10 @@ func (sm *SecurityManager) validateSecurityConfig(config *SecurityConfig) error
 		return NewSecurityError("validate_security_config", "read-only root filesystem must be enabled", nil)
 	}
 
-	// Ensure network is disabled
-	if !config.NetworkDisabled {
-		return NewSecurityError("validate_security_config", "network must be disabled for security", nil)
+	// Ensure network is disabled, unless it is restricted to an egress network
+	if !config.NetworkDisabled && config.EgressNetwork == "" {
+		return NewSecurityError("validate_security_config",
+			"network must be disabled for security unless restricted to an egress network", nil)
 	}
 
 	// Ensure no new privileges
@@ -1182,7 +632,8 @@ func (sm *SecurityManager) CheckImageSecurity(image string) error {
 		"golang:1.20-alpine": true,
 	}
 
-	if !allowedImages[image] {
+	// Task images are checked against the configured repository allowlist
+	if !allowedImages[image] && !sm.config.IsImageRepositoryAllowed(image) {
 		return NewSecurityError("check_image_security",
 			fmt.Sprintf("image %s is not in the allowed list", image), nil)
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
@@ -107,7 +108,7 @@ func TestSecurityManager_ValidateScriptContent(t *testing.T) {
 			content:    "rm -rf /",
 			scriptType: models.ScriptTypeBash,
 			expectErr:  true,
-			errPattern: "rm -rf",
+			errPattern: "bash.rm.recursive",
 		},
 		{
 			name:       "Network access attempt",
@@ -128,112 +129,112 @@ func TestSecurityManager_ValidateScriptContent(t *testing.T) {
 			content:    "import os\nos.system('rm -rf /')",
 			scriptType: models.ScriptTypePython,
 			expectErr:  true,
-			errPattern: "dangerous Python import detected: import os",
+			errPattern: "python.import.system",
 		},
 		{
 			name:       "Python subprocess import",
 			content:    "import subprocess\nsubprocess.run(['ls'])",
 			scriptType: models.ScriptTypePython,
 			expectErr:  true,
-			errPattern: "dangerous Python import detected: import subprocess",
+			errPattern: "python.import.system",
 		},
 		{
 			name:       "Python sys import",
 			content:    "import sys\nprint(sys.version)",
 			scriptType: models.ScriptTypePython,
 			expectErr:  true,
-			errPattern: "dangerous Python import detected: import sys",
+			errPattern: "python.import.system",
 		},
 		{
 			name:       "Python eval function",
 			content:    "eval('print(\"hello\")')",
 			scriptType: models.ScriptTypePython,
 			expectErr:  true,
-			errPattern: "dangerous Python pattern detected: eval(",
+			errPattern: "python.dynamic_code",
 		},
 		{
 			name:       "Python exec function",
 			content:    "exec('print(\"hello\")')",
 			scriptType: models.ScriptTypePython,
 			expectErr:  true,
-			errPattern: "dangerous Python pattern detected: exec(",
+			errPattern: "python.dynamic_code",
 		},
 		{
 			name:       "Python globals function",
 			content:    "print(globals())",
 			scriptType: models.ScriptTypePython,
 			expectErr:  true,
-			errPattern: "dangerous Python pattern detected: globals()",
+			errPattern: "python.reflection",
 		},
 		{
 			name:       "Bash dangerous file access",
 			content:    "cat /etc/passwd | grep root",
 			scriptType: models.ScriptTypeBash,
 			expectErr:  true,
-			errPattern: "dangerous Bash pattern detected: passwd",
+			errPattern: "script.sensitive_path",
 		},
 		{
 			name:       "Bash dangerous redirection",
 			content:    "echo 'test' > /etc/hosts",
 			scriptType: models.ScriptTypeBash,
 			expectErr:  true,
-			errPattern: "dangerous Bash pattern detected: > /",
+			errPattern: "bash.redirect.path",
 		},
 		{
 			name:       "Bash sudo attempt",
 			content:    "sudo rm -rf /",
 			scriptType: models.ScriptTypeBash,
 			expectErr:  true,
-			errPattern: "dangerous Bash pattern detected: sudo ",
+			errPattern: "bash.command.privilege",
 		},
 		{
 			name:       "Bash network command",
 			content:    "ping google.com",
 			scriptType: models.ScriptTypeBash,
 			expectErr:  true,
-			errPattern: "dangerous Bash pattern detected: ping ",
+			errPattern: "bash.command.remote",
 		},
 		{
-			name:       "Bash backtick command substitution",
-			content:    "echo `whoami`",
+			name:       "Bash backtick command substitution is analyzed",
+			content:    "echo `sudo id`",
 			scriptType: models.ScriptTypeBash,
 			expectErr:  true,
-			errPattern: "backtick command substitution detected",
+			errPattern: "bash.command.privilege",
 		},
 		{
 			name:       "JavaScript dangerous require fs",
 			content:    "const fs = require('fs');",
 			scriptType: models.ScriptTypeJavaScript,
 			expectErr:  true,
-			errPattern: "dangerous JavaScript pattern detected: require('fs')",
+			errPattern: "js.module.system",
 		},
 		{
 			name:       "JavaScript dangerous require child_process",
 			content:    "const cp = require('child_process');",
 			scriptType: models.ScriptTypeJavaScript,
 			expectErr:  true,
-			errPattern: "dangerous JavaScript pattern detected: require('child_process')",
+			errPattern: "js.module.system",
 		},
 		{
 			name:       "JavaScript eval function",
 			content:    "eval('console.log(\"hello\")')",
 			scriptType: models.ScriptTypeJavaScript,
 			expectErr:  true,
-			errPattern: "dangerous JavaScript pattern detected: eval(",
+			errPattern: "js.dynamic_code",
 		},
 		{
 			name:       "JavaScript process access",
 			content:    "console.log(process.env.HOME)",
 			scriptType: models.ScriptTypeJavaScript,
 			expectErr:  true,
-			errPattern: "dangerous JavaScript pattern detected: process.env",
+			errPattern: "js.process",
 		},
 		{
 			name:       "JavaScript global object access",
 			content:    "console.log(global.process)",
 			scriptType: models.ScriptTypeJavaScript,
 			expectErr:  true,
-			errPattern: "dangerous JavaScript pattern detected: global.",
+			errPattern: "js.global",
 		},
 	}
 
@@ -252,6 +253,93 @@ func TestSecurityManager_ValidateScriptContent(t *testing.T) {
 	}
 }
 
+func TestSecurityManager_ValidateTaskScript(t *testing.T) {
+	config := NewDefaultConfig()
+	sm := NewSecurityManager(config)
+
+	tests := []struct {
+		name      string
+		task      *models.Task
+		expectErr bool
+	}{
+		{
+			name:      "Python network import without network access",
+			task:      &models.Task{ScriptType: models.ScriptTypePython, ScriptContent: "import urllib.request"},
+			expectErr: true,
+		},
+		{
+			name: "Python network import with network access",
+			task: &models.Task{
+				ScriptType:       models.ScriptTypePython,
+				ScriptContent:    "import urllib.request",
+				NetworkMode:      models.NetworkModeAllowlist,
+				NetworkAllowlist: []string{"pypi.org"},
+			},
+		},
+		{
+			name: "Bash curl with network access",
+			task: &models.Task{ScriptType: models.ScriptTypeBash, ScriptContent: "curl https://pypi.org", NetworkMode: models.NetworkModeInternal},
+		},
+		{
+			name: "JavaScript https module with network access",
+			task: &models.Task{ScriptType: models.ScriptTypeJavaScript, ScriptContent: "const https = require('https')", NetworkMode: models.NetworkModeInternal},
+		},
+		{
+			name:      "Raw sockets stay blocked with network access",
+			task:      &models.Task{ScriptType: models.ScriptTypeBash, ScriptContent: "echo hi > /dev/tcp/10.0.0.1/80", NetworkMode: models.NetworkModeInternal},
+			expectErr: true,
+		},
+		{
+			name:      "Other dangerous imports stay blocked with network access",
+			task:      &models.Task{ScriptType: models.ScriptTypePython, ScriptContent: "import subprocess", NetworkMode: models.NetworkModeInternal},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sm.ValidateTaskScript(tt.task)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSecurityManager_ScriptAnalysisMode(t *testing.T) {
+	task := &models.Task{ScriptType: models.ScriptTypePython, ScriptContent: "import subprocess"}
+
+	for _, mode := range []models.ScriptAnalysisMode{models.ScriptAnalysisModeWarn, models.ScriptAnalysisModeAudit} {
+		t.Run(string(mode), func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Security.ScriptAnalysisMode = mode
+			sm := NewSecurityManager(config)
+
+			assert.NoError(t, sm.ValidateTaskScript(task))
+		})
+	}
+
+	t.Run("block", func(t *testing.T) {
+		sm := NewSecurityManager(NewDefaultConfig())
+
+		err := sm.ValidateTaskScript(task)
+		require.Error(t, err)
+
+		var analysisErr *analyzer.Error
+		require.ErrorAs(t, err, &analysisErr)
+		assert.Equal(t, "python.import.system", analysisErr.Findings[0].RuleID)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.Security.ScriptAnalysisMode = "ignore"
+
+		assert.Error(t, config.Validate())
+	})
+}
+
 func TestSecurityManager_BuildSecurityConfig(t *testing.T) {
 	config := NewDefaultConfig()
 	sm := NewSecurityManager(config)
@@ -0,0 +1,350 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const (
+	// MaxWarmPoolSize caps the number of idle containers kept per image
+	MaxWarmPoolSize = 64
+
+	// warmPoolRetryInterval is how often the pool retries filling after a
+	// container could not be created
+	warmPoolRetryInterval = 30 * time.Second
+
+	// warmPoolLatencySamples is the number of startup times kept for percentiles
+	warmPoolLatencySamples = 1024
+)
+
+// WarmPool keeps pre-started containers per image so short executions skip
+// container creation and start-up. Each container runs a launcher that waits
+// for the script on stdin; it is leased to exactly one execution, removed
+// afterwards and replaced in the background.
+type WarmPool struct {
+	client ContainerClient
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pools   map[models.ScriptType]*scriptPool
+	stopped bool
+
+	refillCh chan struct{}
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// scriptPool holds the idle containers of a single image
+type scriptPool struct {
+	template *ContainerConfig
+	size     int
+	idle     []string
+	creating int
+
+	hits   int64
+	misses int64
+	warm   latencySamples
+	cold   latencySamples
+}
+
+// WarmPoolStats contains warm pool statistics for a single image
+type WarmPoolStats struct {
+	ScriptType     models.ScriptType `json:"script_type"`
+	Image          string            `json:"image"`
+	Size           int               `json:"size"`
+	Idle           int               `json:"idle"`
+	Hits           int64             `json:"hits"`
+	Misses         int64             `json:"misses"`
+	HitRate        float64           `json:"hit_rate"`
+	WarmStartupP50 time.Duration     `json:"warm_startup_p50"`
+	WarmStartupP99 time.Duration     `json:"warm_startup_p99"`
+	ColdStartupP50 time.Duration     `json:"cold_startup_p50"`
+	ColdStartupP99 time.Duration     `json:"cold_startup_p99"`
+}
+
+// NewWarmPool creates a warm pool holding size containers per template. The
+// pool is filled in the background once Start is called.
+func NewWarmPool(client ContainerClient, templates map[models.ScriptType]*ContainerConfig, sizes map[models.ScriptType]int, logger *slog.Logger) *WarmPool {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	wp := &WarmPool{
+		client:   client,
+		logger:   logger.With("component", "warm_pool"),
+		pools:    make(map[models.ScriptType]*scriptPool),
+		refillCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+
+	for scriptType, template := range templates {
+		if sizes[scriptType] <= 0 {
+			continue
+		}
+
+		template := *template
+		template.ScriptContent = ""
+		template.ScriptFromStdin = true
+
+		wp.pools[scriptType] = &scriptPool{
+			template: &template,
+			size:     sizes[scriptType],
+		}
+	}
+
+	return wp
+}
+
+// Start fills the pool and keeps it filled until Stop is called
+func (wp *WarmPool) Start() {
+	wp.wg.Add(1)
+	go wp.run()
+}
+
+// run refills the pool whenever a container is leased, retrying periodically
+// when the daemon could not create containers
+func (wp *WarmPool) run() {
+	defer wp.wg.Done()
+
+	ticker := time.NewTicker(warmPoolRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		wp.fill()
+
+		select {
+		case <-wp.stopCh:
+			return
+		case <-wp.refillCh:
+		case <-ticker.C:
+		}
+	}
+}
+
+// fill creates containers until every pool is at its configured size
+func (wp *WarmPool) fill() {
+	scriptTypes := make([]models.ScriptType, 0, len(wp.pools))
+	for scriptType := range wp.pools {
+		scriptTypes = append(scriptTypes, scriptType)
+	}
+	sort.Slice(scriptTypes, func(i, j int) bool { return scriptTypes[i] < scriptTypes[j] })
+
+	for _, scriptType := range scriptTypes {
+		for {
+			wp.mu.Lock()
+			pool := wp.pools[scriptType]
+			if wp.stopped || len(pool.idle)+pool.creating >= pool.size {
+				wp.mu.Unlock()
+				break
+			}
+			pool.creating++
+			wp.mu.Unlock()
+
+			containerID, err := wp.startContainer(pool.template)
+
+			wp.mu.Lock()
+			pool.creating--
+			if err == nil && !wp.stopped {
+				pool.idle = append(pool.idle, containerID)
+				wp.mu.Unlock()
+				continue
+			}
+			wp.mu.Unlock()
+
+			if err != nil {
+				wp.logger.Warn("failed to start warm container",
+					"script_type", scriptType, "image", pool.template.Image, "error", err)
+			} else {
+				// Stopped while the container was starting
+				wp.removeContainer(containerID)
+			}
+			break
+		}
+	}
+}
+
+// startContainer creates and starts a container that waits for its script
+func (wp *WarmPool) startContainer(template *ContainerConfig) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	containerID, err := wp.client.CreateContainer(ctx, template)
+	if err != nil {
+		return "", err
+	}
+
+	if err := wp.client.StartContainer(ctx, containerID); err != nil {
+		wp.removeContainer(containerID)
+		return "", err
+	}
+
+	return containerID, nil
+}
+
+// removeContainer force-removes a container that will never be leased
+func (wp *WarmPool) removeContainer(containerID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := wp.client.RemoveContainer(ctx, containerID, true); err != nil {
+		wp.logger.Error("failed to remove warm container", "container_id", containerID, "error", err)
+	}
+}
+
+// Acquire leases an idle container for the given configuration. It returns
+// false when the image has no pool, the pool is empty or the configuration
+// differs from the one the pooled containers were created with.
+func (wp *WarmPool) Acquire(config *ContainerConfig) (string, bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	pool, ok := wp.pools[config.ScriptType]
+	if !ok || wp.stopped || len(pool.idle) == 0 || !pool.compatible(config) {
+		return "", false
+	}
+
+	containerID := pool.idle[0]
+	pool.idle = pool.idle[1:]
+
+	// Wake the refill loop without blocking if it is already pending
+	select {
+	case wp.refillCh <- struct{}{}:
+	default:
+	}
+
+	return containerID, true
+}
+
+// RecordStartup records how long it took to get the script of an execution
+// running, and whether a warm container was used
+func (wp *WarmPool) RecordStartup(scriptType models.ScriptType, warm bool, duration time.Duration) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	pool, ok := wp.pools[scriptType]
+	if !ok {
+		return
+	}
+
+	if warm {
+		pool.hits++
+		pool.warm.add(duration)
+	} else {
+		pool.misses++
+		pool.cold.add(duration)
+	}
+}
+
+// Stats returns statistics for every pooled image
+func (wp *WarmPool) Stats() []WarmPoolStats {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	stats := make([]WarmPoolStats, 0, len(wp.pools))
+	for scriptType, pool := range wp.pools {
+		poolStats := WarmPoolStats{
+			ScriptType:     scriptType,
+			Image:          pool.template.Image,
+			Size:           pool.size,
+			Idle:           len(pool.idle),
+			Hits:           pool.hits,
+			Misses:         pool.misses,
+			WarmStartupP50: pool.warm.percentile(50),
+			WarmStartupP99: pool.warm.percentile(99),
+			ColdStartupP50: pool.cold.percentile(50),
+			ColdStartupP99: pool.cold.percentile(99),
+		}
+		if total := pool.hits + pool.misses; total > 0 {
+			poolStats.HitRate = float64(pool.hits) / float64(total)
+		}
+		stats = append(stats, poolStats)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ScriptType < stats[j].ScriptType })
+	return stats
+}
+
+// Stop stops refilling and removes all idle containers
+func (wp *WarmPool) Stop(ctx context.Context) error {
+	wp.mu.Lock()
+	if wp.stopped {
+		wp.mu.Unlock()
+		return nil
+	}
+	wp.stopped = true
+	close(wp.stopCh)
+
+	var idle []string
+	for _, pool := range wp.pools {
+		idle = append(idle, pool.idle...)
+		pool.idle = nil
+	}
+	wp.mu.Unlock()
+
+	wp.wg.Wait()
+
+	var lastErr error
+	for _, containerID := range idle {
+		if err := wp.client.RemoveContainer(ctx, containerID, true); err != nil {
+			lastErr = err
+			wp.logger.Error("failed to remove warm container", "container_id", containerID, "error", err)
+		}
+	}
+
+	return lastErr
+}
+
+// compatible reports whether an execution with the given configuration can
+// run in a container created from the pool's template. Only the script and
+// timeout may differ, as everything else is fixed at container creation.
+func (p *scriptPool) compatible(config *ContainerConfig) bool {
+	template := p.template
+
+	limits := config.ResourceLimits
+	limits.TimeoutSeconds = template.ResourceLimits.TimeoutSeconds
+
+	return config.Image == template.Image &&
+		config.Runtime == template.Runtime &&
+		config.WorkingDir == template.WorkingDir &&
+		limits == template.ResourceLimits &&
+		slices.Equal(config.Environment, template.Environment) &&
+		reflect.DeepEqual(config.SecurityConfig, template.SecurityConfig)
+}
+
+// latencySamples keeps the most recent durations in a ring buffer
+type latencySamples struct {
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencySamples) add(d time.Duration) {
+	if len(l.samples) < warmPoolLatencySamples {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % warmPoolLatencySamples
+}
+
+// percentile returns the p-th percentile using the nearest-rank method
+func (l *latencySamples) percentile(p int) time.Duration {
+	if len(l.samples) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(l.samples)
+	slices.Sort(sorted)
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,178 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func newTestWarmPoolExecutor(t *testing.T, client *MockContainerClient) *Executor {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.Security.EnableSeccomp = false
+	config.Images.PythonPoolSize = 2
+
+	executor := &Executor{
+		client:          client,
+		config:          config,
+		securityManager: NewSecurityManager(config),
+		cleanupManager:  NewCleanupManager(nil, nil),
+		logger:          slog.Default(),
+	}
+	executor.warmPool = executor.newWarmPool()
+	require.NotNil(t, executor.warmPool)
+
+	return executor
+}
+
+func TestWarmPool_FillAndAcquire(t *testing.T) {
+	mockClient := new(MockContainerClient)
+	mockClient.On("CreateContainer", mock.Anything, mock.MatchedBy(func(config *ContainerConfig) bool {
+		return config.ScriptFromStdin && config.ScriptContent == ""
+	})).Return("warmcontainer1", nil).Once()
+	mockClient.On("CreateContainer", mock.Anything, mock.Anything).Return("warmcontainer2", nil).Once()
+	mockClient.On("StartContainer", mock.Anything, mock.Anything).Return(nil)
+
+	executor := newTestWarmPoolExecutor(t, mockClient)
+	pool := executor.warmPool
+
+	pool.fill()
+	mockClient.AssertNumberOfCalls(t, "CreateContainer", 2)
+
+	task := &models.Task{ScriptType: models.ScriptTypePython, ScriptContent: "print(1)"}
+	config, err := executor.buildContainerConfig(task, executor.config.DefaultResourceLimits, 30*time.Second)
+	require.NoError(t, err)
+
+	containerID, ok := pool.Acquire(config)
+	require.True(t, ok)
+	assert.Equal(t, "warmcontainer1", containerID)
+
+	// Different resource limits can't be served from the pool
+	limits := executor.config.DefaultResourceLimits
+	limits.MemoryLimitBytes *= 2
+	bigger, err := executor.buildContainerConfig(task, limits, 30*time.Second)
+	require.NoError(t, err)
+	_, ok = pool.Acquire(bigger)
+	assert.False(t, ok)
+
+	// Script types without a pool are never served
+	bashConfig, err := executor.buildContainerConfig(&models.Task{ScriptType: models.ScriptTypeBash}, executor.config.DefaultResourceLimits, 0)
+	require.NoError(t, err)
+	_, ok = pool.Acquire(bashConfig)
+	assert.False(t, ok)
+
+	// Stopping removes the remaining idle container
+	mockClient.On("RemoveContainer", mock.Anything, "warmcontainer2", true).Return(nil)
+	require.NoError(t, pool.Stop(context.Background()))
+	mockClient.AssertExpectations(t)
+
+	_, ok = pool.Acquire(config)
+	assert.False(t, ok)
+}
+
+func TestExecutor_ExecuteWithWarmContainer(t *testing.T) {
+	mockClient := new(MockContainerClient)
+	executor := newTestWarmPoolExecutor(t, mockClient)
+	executor.warmPool.pools[models.ScriptTypePython].idle = []string{"warmcontainer1"}
+
+	script := "print('warm')"
+	mockClient.On("SendStdin", mock.Anything, "warmcontainer1", script).Return(nil)
+	mockClient.On("WaitContainer", mock.Anything, "warmcontainer1").Return(0, nil)
+	mockClient.On("InspectContainerExit", mock.Anything, "warmcontainer1").Return(&ContainerExitState{ExitCode: 0}, nil)
+	mockClient.On("StreamContainerLogs", mock.Anything, "warmcontainer1").Return("warm\n", "", nil)
+	mockClient.On("RemoveContainer", mock.Anything, "warmcontainer1", true).Return(nil)
+
+	execCtx := &ExecutionContext{
+		Task: &models.Task{
+			BaseModel:     models.BaseModel{ID: uuid.New()},
+			ScriptType:    models.ScriptTypePython,
+			ScriptContent: script,
+		},
+		Execution:      &models.TaskExecution{ID: uuid.New()},
+		Timeout:        30 * time.Second,
+		ResourceLimits: executor.config.DefaultResourceLimits,
+	}
+
+	result, err := executor.Execute(context.Background(), execCtx)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, result.Status)
+	mockClient.AssertNotCalled(t, "CreateContainer", mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "StartContainer", mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+
+	// The pool is empty now, so the next execution starts a new container
+	mockClient.On("CreateContainer", mock.Anything, mock.Anything).Return("coldcontainer1", nil)
+	mockClient.On("StartContainer", mock.Anything, "coldcontainer1").Return(nil)
+	mockClient.On("WaitContainer", mock.Anything, "coldcontainer1").Return(0, nil)
+	mockClient.On("InspectContainerExit", mock.Anything, "coldcontainer1").Return(&ContainerExitState{ExitCode: 0}, nil)
+	mockClient.On("StreamContainerLogs", mock.Anything, "coldcontainer1").Return("warm\n", "", nil)
+	mockClient.On("RemoveContainer", mock.Anything, "coldcontainer1", true).Return(nil)
+
+	execCtx.Execution = &models.TaskExecution{ID: uuid.New()}
+	_, err = executor.Execute(context.Background(), execCtx)
+	require.NoError(t, err)
+
+	stats := executor.WarmPoolStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, models.ScriptTypePython, stats[0].ScriptType)
+	assert.Equal(t, int64(1), stats[0].Hits)
+	assert.Equal(t, int64(1), stats[0].Misses)
+	assert.Equal(t, 0.5, stats[0].HitRate)
+}
+
+func TestExecutor_ExecuteFallsBackFromUnusableWarmContainer(t *testing.T) {
+	mockClient := new(MockContainerClient)
+	executor := newTestWarmPoolExecutor(t, mockClient)
+	executor.warmPool.pools[models.ScriptTypePython].idle = []string{"deadcontainer1"}
+
+	mockClient.On("SendStdin", mock.Anything, "deadcontainer1", mock.Anything).Return(assert.AnError)
+	mockClient.On("RemoveContainer", mock.Anything, "deadcontainer1", true).Return(nil)
+	mockClient.On("CreateContainer", mock.Anything, mock.Anything).Return("coldcontainer1", nil)
+	mockClient.On("StartContainer", mock.Anything, "coldcontainer1").Return(nil)
+	mockClient.On("WaitContainer", mock.Anything, "coldcontainer1").Return(0, nil)
+	mockClient.On("InspectContainerExit", mock.Anything, "coldcontainer1").Return(&ContainerExitState{ExitCode: 0}, nil)
+	mockClient.On("StreamContainerLogs", mock.Anything, "coldcontainer1").Return("", "", nil)
+	mockClient.On("RemoveContainer", mock.Anything, "coldcontainer1", true).Return(nil)
+
+	execCtx := &ExecutionContext{
+		Task: &models.Task{
+			BaseModel:     models.BaseModel{ID: uuid.New()},
+			ScriptType:    models.ScriptTypePython,
+			ScriptContent: "print(1)",
+		},
+		Execution:      &models.TaskExecution{ID: uuid.New()},
+		Timeout:        30 * time.Second,
+		ResourceLimits: executor.config.DefaultResourceLimits,
+	}
+
+	result, err := executor.Execute(context.Background(), execCtx)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, result.Status)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLatencySamples(t *testing.T) {
+	var samples latencySamples
+	assert.Zero(t, samples.percentile(50))
+
+	for i := 1; i <= 100; i++ {
+		samples.add(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, samples.percentile(50))
+	assert.Equal(t, 99*time.Millisecond, samples.percentile(99))
+
+	// Old samples are overwritten once the buffer is full
+	for i := 0; i < warmPoolLatencySamples; i++ {
+		samples.add(time.Second)
+	}
+	assert.Len(t, samples.samples, warmPoolLatencySamples)
+	assert.Equal(t, time.Second, samples.percentile(50))
+}
@@ -0,0 +1,77 @@
+// Package labels validates task labels and parses the label selectors that
+// select tasks by them.
+//
+// Labels are key/value pairs following the Kubernetes conventions: a key is
+// a name of up to 63 characters, optionally prefixed by a DNS subdomain and
+// a slash ("example.com/team"), and a value is empty or a name. Selectors are
+// comma-separated requirements that must all hold:
+//
+//	env=prod            the label env is prod (also env==prod)
+//	env!=prod           the label env is missing or not prod
+//	team in (data,ml)   the label team is data or ml
+//	team notin (web)    the label team is missing or not web
+//	deprecated          the label deprecated is set, to any value
+//	!deprecated         the label deprecated is not set
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxNameLength is the maximum length of a label value and of the name
+	// part of a key
+	MaxNameLength = 63
+	// MaxPrefixLength is the maximum length of the prefix of a key
+	MaxPrefixLength = 253
+)
+
+var (
+	namePattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	prefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateKey validates a label key
+func ValidateKey(key string) error {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if prefix == "" {
+			return fmt.Errorf("label key %q has an empty prefix", key)
+		}
+		if len(prefix) > MaxPrefixLength {
+			return fmt.Errorf("label key %q has a prefix longer than %d characters", key, MaxPrefixLength)
+		}
+		if !prefixPattern.MatchString(prefix) {
+			return fmt.Errorf("label key %q must have a lowercase DNS subdomain as prefix", key)
+		}
+	}
+
+	if name == "" {
+		return fmt.Errorf("label key %q has an empty name", key)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("label key %q has a name longer than %d characters", key, MaxNameLength)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("label key %q must consist of letters, digits, '-', '_' or '.', and start and end with a letter or digit", key)
+	}
+	return nil
+}
+
+// ValidateValue validates a label value
+func ValidateValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > MaxNameLength {
+		return fmt.Errorf("label value %q is longer than %d characters", value, MaxNameLength)
+	}
+	if !namePattern.MatchString(value) {
+		return fmt.Errorf("label value %q must consist of letters, digits, '-', '_' or '.', and start and end with a letter or digit", value)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package labels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{"env", "team", "app.kubernetes.io/name", "example.com/tier", "a", "Build_Number", strings.Repeat("a", 63)}
+	for _, key := range valid {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+
+	invalid := []string{"", "-env", "env-", "en v", "/env", "example.com/", "Example.com/env", strings.Repeat("a", 64), strings.Repeat("a", 254) + "/env"}
+	for _, key := range invalid {
+		assert.Error(t, ValidateKey(key), key)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	for _, value := range []string{"", "prod", "v1.2.3", "A_b-c"} {
+		assert.NoError(t, ValidateValue(value), value)
+	}
+	for _, value := range []string{"-prod", "prod ", "a/b", strings.Repeat("a", 64)} {
+		assert.Error(t, ValidateValue(value), value)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     Selector
+	}{
+		{selector: "", want: nil},
+		{selector: "  ", want: nil},
+		{selector: "env=prod", want: Selector{{Key: "env", Operator: Equals, Values: []string{"prod"}}}},
+		{selector: "env==prod", want: Selector{{Key: "env", Operator: Equals, Values: []string{"prod"}}}},
+		{selector: "env!=prod", want: Selector{{Key: "env", Operator: NotEquals, Values: []string{"prod"}}}},
+		{selector: "env=", want: Selector{{Key: "env", Operator: Equals, Values: []string{""}}}},
+		{selector: "deprecated", want: Selector{{Key: "deprecated", Operator: Exists}}},
+		{selector: "!deprecated", want: Selector{{Key: "deprecated", Operator: DoesNotExist}}},
+		{selector: "team in (ml, data,ml)", want: Selector{{Key: "team", Operator: In, Values: []string{"data", "ml"}}}},
+		{selector: "team notin (web)", want: Selector{{Key: "team", Operator: NotIn, Values: []string{"web"}}}},
+		{
+			selector: " env = prod , team in (data,ml), !deprecated ",
+			want: Selector{
+				{Key: "env", Operator: Equals, Values: []string{"prod"}},
+				{Key: "team", Operator: In, Values: []string{"data", "ml"}},
+				{Key: "deprecated", Operator: DoesNotExist},
+			},
+		},
+		{selector: "example.com/tier=gold", want: Selector{{Key: "example.com/tier", Operator: Equals, Values: []string{"gold"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := Parse(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		selector string
+		position int
+		message  string
+	}{
+		{selector: "env=prod,", position: 9, message: `expected a requirement after ","`},
+		{selector: ",env", position: 0, message: "expected a label key"},
+		{selector: "env=prod team=ml", position: 9, message: `expected "," or end of selector`},
+		{selector: "env>prod", position: 3, message: `expected "=", "==", "!=", "in" or "notin"`},
+		{selector: "team in data", position: 8, message: `expected "(" after "in"`},
+		{selector: "team in (data", position: 13, message: `expected ")" to close the values of "in"`},
+		{selector: "team in (data ml)", position: 14, message: `expected "," or ")"`},
+		{selector: "env=-prod", position: 4, message: `label value "-prod"`},
+		{selector: "-env=prod", position: 0, message: `label key "-env"`},
+		{selector: "!", position: 1, message: "expected a label key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			_, err := Parse(tt.selector)
+			require.Error(t, err)
+
+			var syntaxErr *SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.position, syntaxErr.Position)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	t.Run("too many requirements", func(t *testing.T) {
+		parts := make([]string, MaxRequirements+1)
+		for i := range parts {
+			parts[i] = "env"
+		}
+		_, err := Parse(strings.Join(parts, ","))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "more than")
+	})
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "data"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env=dev", want: false},
+		{selector: "env!=dev", want: true},
+		{selector: "tier!=gold", want: true},
+		{selector: "team in (data,ml)", want: true},
+		{selector: "team notin (data)", want: false},
+		{selector: "tier notin (gold)", want: true},
+		{selector: "tier in (gold)", want: false},
+		{selector: "env", want: true},
+		{selector: "!deprecated", want: true},
+		{selector: "!env", want: false},
+		{selector: "env=prod,team in (ml)", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := Parse(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, selector.Matches(labels))
+		})
+	}
+}
+
+func TestSelector_String(t *testing.T) {
+	selector, err := Parse("env == prod, team in (ml,data), !deprecated, tier, zone!=eu")
+	require.NoError(t, err)
+	assert.Equal(t, "env=prod,team in (data,ml),!deprecated,tier,zone!=eu", selector.String())
+
+	reparsed, err := Parse(selector.String())
+	require.NoError(t, err)
+	assert.Equal(t, selector, reparsed)
+}
@@ -0,0 +1,294 @@
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxRequirements caps the number of requirements in a selector
+const MaxRequirements = 20
+
+// Operator is the comparison a requirement makes
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Requirement is a condition on a single label
+type Requirement struct {
+	Key      string
+	Operator Operator
+	// Values holds the value of Equals and NotEquals, and the set of In
+	// and NotIn
+	Values []string
+}
+
+// Matches reports whether the labels satisfy the requirement
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	case Equals, In:
+		return ok && r.hasValue(value)
+	case NotEquals, NotIn:
+		return !ok || !r.hasValue(value)
+	default:
+		return false
+	}
+}
+
+func (r Requirement) hasValue(value string) bool {
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the requirement in selector syntax
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case Equals, NotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	default:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	}
+}
+
+// Selector selects labels satisfying all of its requirements. The empty
+// selector selects everything.
+type Selector []Requirement
+
+// Matches reports whether the labels satisfy every requirement
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the selector in selector syntax
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// SyntaxError reports where a selector is malformed
+type SyntaxError struct {
+	Selector string
+	// Position is the byte offset of the error in the selector
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid label selector %q at position %d: %s", e.Selector, e.Position, e.Message)
+}
+
+// Parse parses a label selector. A blank selector parses as nil, which
+// selects everything.
+func Parse(selector string) (Selector, error) {
+	p := &parser{input: selector}
+
+	p.skipSpace()
+	if p.done() {
+		return nil, nil
+	}
+
+	var s Selector
+	for {
+		r, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, r)
+		if len(s) > MaxRequirements {
+			return nil, p.errorf("more than %d requirements", MaxRequirements)
+		}
+
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		if !p.consume(",") {
+			return nil, p.errorf(`expected "," or end of selector, found %q`, p.rest())
+		}
+		p.skipSpace()
+		if p.done() {
+			return nil, p.errorf(`expected a requirement after ","`)
+		}
+	}
+	return s, nil
+}
+
+// parser reads a selector from left to right
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) rest() string {
+	rest := p.input[p.pos:]
+	if len(rest) > 10 {
+		rest = rest[:10] + "..."
+	}
+	return rest
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+func (p *parser) errorAt(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Selector: p.input, Position: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// word reads a key or value: a run of the characters labels are made of
+func (p *parser) word() string {
+	start := p.pos
+	for !p.done() && isWordChar(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '/'
+}
+
+func (p *parser) key() (string, error) {
+	start := p.pos
+	key := p.word()
+	if key == "" {
+		if p.done() {
+			return "", p.errorf("expected a label key")
+		}
+		return "", p.errorf("expected a label key, found %q", p.rest())
+	}
+	if err := ValidateKey(key); err != nil {
+		return "", p.errorAt(start, "%v", err)
+	}
+	return key, nil
+}
+
+func (p *parser) value() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	value := p.word()
+	if err := ValidateValue(value); err != nil {
+		return "", p.errorAt(start, "%v", err)
+	}
+	return value, nil
+}
+
+func (p *parser) requirement() (Requirement, error) {
+	if p.consume("!") {
+		p.skipSpace()
+		key, err := p.key()
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: DoesNotExist}, nil
+	}
+
+	key, err := p.key()
+	if err != nil {
+		return Requirement{}, err
+	}
+
+	p.skipSpace()
+	switch {
+	case p.done() || strings.HasPrefix(p.input[p.pos:], ","):
+		return Requirement{Key: key, Operator: Exists}, nil
+	case p.consume("!="):
+		value, err := p.value()
+		return Requirement{Key: key, Operator: NotEquals, Values: []string{value}}, err
+	case p.consume("=="), p.consume("="):
+		value, err := p.value()
+		return Requirement{Key: key, Operator: Equals, Values: []string{value}}, err
+	}
+
+	start := p.pos
+	switch operator := Operator(p.word()); operator {
+	case In, NotIn:
+		values, err := p.set(operator)
+		return Requirement{Key: key, Operator: operator, Values: values}, err
+	default:
+		p.pos = start
+		return Requirement{}, p.errorf(`expected "=", "==", "!=", "in" or "notin" after %q, found %q`, key, p.rest())
+	}
+}
+
+// set reads the parenthesized values of in and notin
+func (p *parser) set(operator Operator) ([]string, error) {
+	p.skipSpace()
+	if !p.consume("(") {
+		return nil, p.errorf(`expected "(" after %q`, operator)
+	}
+
+	seen := make(map[string]bool)
+	var values []string
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			if p.done() {
+				return nil, p.errorf(`expected ")" to close the values of %q`, operator)
+			}
+			return nil, p.errorf(`expected "," or ")", found %q`, p.rest())
+		}
+	}
+
+	sort.Strings(values)
+	return values, nil
+}
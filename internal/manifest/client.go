@@ -0,0 +1,135 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// APIError represents a non-successful response from the task API
+type APIError struct {
+	StatusCode int
+	Message    string
+	Details    string
+}
+
+func (e *APIError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("API returned %d: %s: %s", e.StatusCode, e.Message, e.Details)
+	}
+	return fmt.Sprintf("API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Client imports and exports task manifests through the task API
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new manifest API client authenticating with the
+// user's access token
+func NewClient(baseURL, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/") + "/api/v1",
+		token:   token,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Import sends the manifest to be reconciled with the user's tasks. When the
+// manifest is rejected, the response listing the rejected changes is
+// returned along with the error.
+func (c *Client) Import(ctx context.Context, manifest *models.TaskManifest, mode models.TaskImportMode, prune bool) (*models.TaskImportResponse, error) {
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("mode", string(mode))
+	query.Set("prune", strconv.FormatBool(prune))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/tasks/import?"+query.Encode(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.TaskImportResponse
+	decodeErr := json.Unmarshal(body, &response)
+	if status >= http.StatusBadRequest {
+		apiErr := newAPIError(status, body)
+		if decodeErr == nil && response.Changes != nil {
+			return &response, apiErr
+		}
+		return nil, apiErr
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+	return &response, nil
+}
+
+// Export returns the manifest of the user's tasks, encoded in the format,
+// yaml or json
+func (c *Client) Export(ctx context.Context, format string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/tasks/export?format="+url.QueryEscape(format), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status >= http.StatusBadRequest {
+		return nil, newAPIError(status, body)
+	}
+	return body, nil
+}
+
+// do sends an authenticated request and reads the response body
+func (c *Client) do(req *http.Request) ([]byte, int, error) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024*1024))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// newAPIError builds the error for a non-successful response
+func newAPIError(status int, body []byte) *APIError {
+	var errorBody struct {
+		Error   string `json:"error"`
+		Details string `json:"details"`
+	}
+	if json.Unmarshal(body, &errorBody) != nil || errorBody.Error == "" {
+		errorBody.Error = http.StatusText(status)
+	}
+	return &APIError{StatusCode: status, Message: errorBody.Error, Details: errorBody.Details}
+}
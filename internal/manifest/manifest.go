@@ -0,0 +1,140 @@
+// Package manifest reads task manifests and plans the changes that
+// reconcile a user's tasks with them.
+//
+// A manifest declares tasks by a stable key, which is stored as the task's
+// external key. Importing a manifest creates the tasks whose key is new,
+// updates those that differ from their entry and, when pruning, deletes the
+// tasks that carry a key no longer in the manifest. Tasks created without a
+// manifest have no key; they are exported under their ID and adopted by the
+// entry with that key on the next import.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Parse decodes a manifest in YAML or JSON. It neither resolves script
+// files nor validates the manifest.
+func Parse(data []byte) (*models.TaskManifest, error) {
+	// Unknown fields are rejected, as a misspelled field would silently
+	// reset the task to its default
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var manifest models.TaskManifest
+	if err := decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// Load reads and validates the manifest at path. When path is a directory,
+// every .yaml, .yml or .json file in it is read, sorted by file name, and
+// their tasks are combined into one manifest.
+func Load(path string) (*models.TaskManifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		if paths, err = manifestFiles(path); err != nil {
+			return nil, err
+		}
+	}
+
+	combined := &models.TaskManifest{Version: models.TaskManifestVersion}
+	files := make(map[string]string)
+	for _, file := range paths {
+		manifest, err := LoadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if manifest.Version != 0 && manifest.Version != models.TaskManifestVersion {
+			return nil, fmt.Errorf("unsupported manifest version in %s: %d", file, manifest.Version)
+		}
+		for _, entry := range manifest.Tasks {
+			if other, ok := files[entry.Key]; ok && entry.Key != "" {
+				return nil, fmt.Errorf("task %q is defined in both %s and %s", entry.Key, other, file)
+			}
+			files[entry.Key] = file
+		}
+		combined.Tasks = append(combined.Tasks, manifest.Tasks...)
+	}
+
+	if err := combined.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return combined, nil
+}
+
+// LoadFile reads a manifest file and inlines the script files it refers to,
+// which are relative to the manifest file
+func LoadFile(path string) (*models.TaskManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+
+	manifest, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range manifest.Tasks {
+		entry := &manifest.Tasks[i]
+		if entry.ScriptFile == "" || entry.Script != "" {
+			continue
+		}
+
+		scriptPath := entry.ScriptFile
+		if !filepath.IsAbs(scriptPath) {
+			scriptPath = filepath.Join(dir, scriptPath)
+		}
+		script, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: task %s: failed to read script file: %w", path, entry.Key, err)
+		}
+		entry.Script = string(script)
+		entry.ScriptFile = ""
+	}
+
+	return manifest, nil
+}
+
+// manifestFiles lists the manifest files in a directory, sorted by name
+func manifestFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no manifest files found in %s", dir)
+	}
+	return paths, nil
+}
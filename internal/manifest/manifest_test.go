@@ -0,0 +1,358 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const testManifestFile = `
+version: 1
+tasks:
+  - key: reports/nightly
+    name: Nightly report
+    script_file: scripts/report.py
+    script_type: python
+    priority: 7
+    metadata:
+      owner: data
+      retries: 2
+  - key: cleanup
+    name: Cleanup
+    script: echo cleanup
+    script_type: bash
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml":            testManifestFile,
+		"b.json":            `{"tasks": [{"key": "hello", "name": "Hello", "script": "console.log(1)", "script_type": "javascript"}]}`,
+		"scripts/report.py": "print('report')\n",
+		"README.md":         "not a manifest",
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		manifest, err := Load(dir)
+		require.NoError(t, err)
+		require.Len(t, manifest.Tasks, 3)
+
+		report := manifest.Tasks[0]
+		assert.Equal(t, "reports/nightly", report.Key)
+		assert.Equal(t, "print('report')\n", report.Script)
+		assert.Empty(t, report.ScriptFile)
+		assert.Equal(t, "hello", manifest.Tasks[2].Key)
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		manifest, err := Load(filepath.Join(dir, "a.yaml"))
+		require.NoError(t, err)
+		assert.Len(t, manifest.Tasks, 2)
+	})
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		errMsg string
+	}{
+		{
+			name:   "unknown field",
+			files:  map[string]string{"a.yaml": "tasks:\n  - key: a\n    name: A\n    script: echo\n    script_type: bash\n    schedule: '@daily'\n"},
+			errMsg: "field schedule not found",
+		},
+		{
+			name:   "missing script file",
+			files:  map[string]string{"a.yaml": "tasks:\n  - key: a\n    name: A\n    script_file: missing.sh\n    script_type: bash\n"},
+			errMsg: "failed to read script file",
+		},
+		{
+			name: "key defined twice",
+			files: map[string]string{
+				"a.yaml": "tasks:\n  - key: a\n    name: A\n    script: echo\n    script_type: bash\n",
+				"b.yaml": "tasks:\n  - key: a\n    name: B\n    script: echo\n    script_type: bash\n",
+			},
+			errMsg: `task "a" is defined in both`,
+		},
+		{
+			name:   "invalid task",
+			files:  map[string]string{"a.yaml": "tasks:\n  - key: a\n    name: A\n    script: echo\n    script_type: cobol\n"},
+			errMsg: "task a: invalid script type",
+		},
+		{
+			name:   "unsupported version",
+			files:  map[string]string{"a.yaml": "version: 2\ntasks: []\n"},
+			errMsg: "unsupported manifest version",
+		},
+		{
+			name:   "no manifest files",
+			files:  map[string]string{"README.md": "nothing here"},
+			errMsg: "no manifest files found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFiles(t, tt.files))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func existingTask(userID uuid.UUID, key *string, name, script string) *models.Task {
+	return &models.Task{
+		BaseModel:      models.BaseModel{ID: uuid.New()},
+		UserID:         userID,
+		Name:           name,
+		ScriptContent:  script,
+		ScriptType:     models.ScriptTypeBash,
+		Status:         models.TaskStatusCompleted,
+		Priority:       5,
+		TimeoutSeconds: config.DefaultTaskTimeout,
+		Metadata:       models.JSONB{},
+		SecurityLevel:  models.SecurityLevelStandard,
+		NetworkMode:    models.NetworkModeNone,
+		ExternalKey:    key,
+		Revision:       3,
+	}
+}
+
+func TestPlan(t *testing.T) {
+	userID := uuid.New()
+	unchanged := existingTask(userID, stringPtr("unchanged"), "Unchanged", "echo same")
+	changed := existingTask(userID, stringPtr("changed"), "Changed", "echo old\n")
+	removed := existingTask(userID, stringPtr("removed"), "Removed", "echo gone")
+	unmanaged := existingTask(userID, nil, "Unmanaged", "echo mine")
+	adopted := existingTask(userID, nil, "Adopted", "echo adopt")
+
+	manifest := &models.TaskManifest{Tasks: []models.TaskManifestEntry{
+		{Key: "unchanged", Name: "Unchanged", Script: "echo same", ScriptType: models.ScriptTypeBash},
+		{Key: "changed", Name: "Changed", Script: "echo new\n", ScriptType: models.ScriptTypeBash, Priority: intPtr(9)},
+		{Key: "new", Name: "New", Script: "echo new", ScriptType: models.ScriptTypeBash},
+		{Key: adopted.ID.String(), Name: "Adopted", Script: "echo adopt", ScriptType: models.ScriptTypeBash},
+	}}
+	existing := []*models.Task{unchanged, changed, removed, unmanaged, adopted}
+
+	t.Run("without prune", func(t *testing.T) {
+		changes := Plan(manifest, userID, existing, false)
+		require.Len(t, changes, 4)
+
+		assert.Equal(t, models.TaskManifestActionUnchanged, changes[0].Action)
+		assert.Empty(t, changes[0].Fields)
+
+		update := changes[1]
+		assert.Equal(t, models.TaskManifestActionUpdate, update.Action)
+		assert.Equal(t, changed.ID, *update.TaskID)
+		require.Len(t, update.Fields, 2)
+		assert.Equal(t, "script", update.Fields[0].Field)
+		assert.Contains(t, update.Fields[0].Diff, "-echo old")
+		assert.Contains(t, update.Fields[0].Diff, "+echo new")
+		assert.Equal(t, models.TaskManifestFieldChange{Field: "priority", From: 5, To: 9}, update.Fields[1])
+		assert.Equal(t, 3, update.Desired.Revision, "updates keep the task's state")
+		assert.Equal(t, models.TaskStatusCompleted, update.Desired.Status)
+
+		create := changes[2]
+		assert.Equal(t, models.TaskManifestActionCreate, create.Action)
+		assert.Nil(t, create.TaskID)
+		assert.Equal(t, userID, create.Desired.UserID)
+		assert.Equal(t, "new", *create.Desired.ExternalKey)
+		assert.Equal(t, models.TaskStatusPending, create.Desired.Status)
+
+		adopt := changes[3]
+		assert.Equal(t, models.TaskManifestActionUpdate, adopt.Action)
+		assert.Equal(t, adopted.ID, *adopt.TaskID)
+		require.Len(t, adopt.Fields, 1)
+		assert.Equal(t, "key", adopt.Fields[0].Field)
+
+		assert.Equal(t, models.TaskImportSummary{Create: 1, Update: 2, Unchanged: 1}, Summarize(changes))
+	})
+
+	t.Run("with prune", func(t *testing.T) {
+		changes := Plan(manifest, userID, existing, true)
+		require.Len(t, changes, 5)
+
+		deleted := changes[4]
+		assert.Equal(t, models.TaskManifestActionDelete, deleted.Action)
+		assert.Equal(t, "removed", deleted.Key)
+		assert.Equal(t, removed.ID, *deleted.TaskID)
+	})
+}
+
+func TestPlan_Image(t *testing.T) {
+	userID := uuid.New()
+	task := existingTask(userID, stringPtr("image"), "Image", "echo")
+	task.Image = stringPtr("python:3.12")
+	task.ImageDigest = stringPtr("sha256:abc")
+
+	entry := models.TaskManifestEntry{Key: "image", Name: "Image", Script: "echo", ScriptType: models.ScriptTypeBash}
+
+	t.Run("same image keeps its digest", func(t *testing.T) {
+		entry := entry
+		entry.Image = stringPtr("python:3.12")
+		desired := Desired(&entry, task, userID)
+		assert.Equal(t, "sha256:abc", *desired.ImageDigest)
+	})
+
+	t.Run("changed image must be pinned", func(t *testing.T) {
+		entry := entry
+		entry.Image = stringPtr("python:3.13")
+		desired := Desired(&entry, task, userID)
+		assert.Equal(t, "python:3.13", *desired.Image)
+		assert.Nil(t, desired.ImageDigest)
+		assert.Equal(t, "sha256:abc", *task.ImageDigest, "the current task is not modified")
+	})
+
+	t.Run("no image reverts to the default image", func(t *testing.T) {
+		desired := Desired(&entry, task, userID)
+		assert.Nil(t, desired.Image)
+		assert.Nil(t, desired.ImageDigest)
+	})
+}
+
+func TestPlan_Labels(t *testing.T) {
+	userID := uuid.New()
+	task := existingTask(userID, stringPtr("labeled"), "Labeled", "echo")
+	task.Labels = map[string]string{"env": "dev"}
+
+	entry := models.TaskManifestEntry{Key: "labeled", Name: "Labeled", Script: "echo", ScriptType: models.ScriptTypeBash}
+
+	t.Run("changed labels", func(t *testing.T) {
+		entry := entry
+		entry.Labels = map[string]string{"env": "prod"}
+		fields := diffTasks(task, Desired(&entry, task, userID))
+		require.Len(t, fields, 1)
+		assert.Equal(t, models.TaskManifestFieldChange{Field: "labels", From: task.Labels, To: entry.Labels}, fields[0])
+	})
+
+	t.Run("no labels removes them", func(t *testing.T) {
+		desired := Desired(&entry, task, userID)
+		assert.Empty(t, desired.Labels)
+		require.Len(t, diffTasks(task, desired), 1)
+	})
+
+	t.Run("invalid labels", func(t *testing.T) {
+		entry := entry
+		entry.Labels = map[string]string{"env": "prod eu"}
+		assert.ErrorContains(t, entry.Validate(), `label value "prod eu"`)
+	})
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	userID := uuid.New()
+	managed := existingTask(userID, stringPtr("managed"), "Managed", "echo managed")
+	managed.Description = stringPtr("A managed task")
+	managed.Metadata = models.JSONB{"retries": float64(2), "tags": []interface{}{"a", "b"}}
+	managed.RequiredCapabilities = []string{"memory:large"}
+	managed.NetworkMode = models.NetworkModeAllowlist
+	managed.NetworkAllowlist = []string{"api.example.com"}
+	managed.Labels = map[string]string{"env": "prod", "deprecated": ""}
+	unmanaged := existingTask(userID, nil, "Unmanaged", "echo unmanaged")
+	existing := []*models.Task{managed, unmanaged}
+
+	exported := Export(existing)
+	require.Len(t, exported.Tasks, 2)
+	assert.Equal(t, unmanaged.ID.String(), exported.Tasks[1].Key)
+	require.NoError(t, exported.Validate())
+
+	// Importing the export through YAML changes nothing but adopting the
+	// unmanaged task
+	data, err := json.Marshal(exported)
+	require.NoError(t, err)
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+
+	changes := Plan(parsed, userID, existing, true)
+	require.Len(t, changes, 2)
+	assert.Equal(t, models.TaskManifestActionUnchanged, changes[0].Action, "%+v", changes[0].Fields)
+	assert.Equal(t, models.TaskManifestActionUpdate, changes[1].Action)
+	require.Len(t, changes[1].Fields, 1)
+	assert.Equal(t, "key", changes[1].Fields[0].Field)
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/api/v1/tasks/import":
+			var manifest models.TaskManifest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&manifest))
+
+			status := http.StatusOK
+			response := models.TaskImportResponse{
+				Mode:    models.TaskImportMode(r.URL.Query().Get("mode")),
+				Applied: true,
+				Changes: []models.TaskManifestChange{{Action: models.TaskManifestActionCreate, Key: manifest.Tasks[0].Key}},
+			}
+			if r.URL.Query().Get("prune") == "true" {
+				status = http.StatusBadRequest
+				response.Applied = false
+				response.Error = "Manifest rejected"
+				response.Changes[0].Error = "denied"
+			}
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(response)
+		case "/api/v1/tasks/export":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "Invalid format: must be yaml or json"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "token", 5*time.Second)
+	manifest := &models.TaskManifest{Tasks: []models.TaskManifestEntry{{Key: "a"}}}
+
+	t.Run("import", func(t *testing.T) {
+		response, err := client.Import(context.Background(), manifest, models.TaskImportModeApply, false)
+		require.NoError(t, err)
+		assert.True(t, response.Applied)
+		assert.Equal(t, models.TaskImportModeApply, response.Mode)
+	})
+
+	t.Run("rejected import returns the changes", func(t *testing.T) {
+		response, err := client.Import(context.Background(), manifest, models.TaskImportModeApply, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Manifest rejected")
+		require.NotNil(t, response)
+		assert.Equal(t, "denied", response.Changes[0].Error)
+	})
+
+	t.Run("export error", func(t *testing.T) {
+		_, err := client.Export(context.Background(), "xml")
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	})
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,326 @@
+package manifest
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// defaultPriority is the priority of tasks that don't set one, as for tasks
+// created through the API
+const defaultPriority = 5
+
+// Export returns a manifest declaring the tasks as they are. Tasks without
+// an external key are exported under their ID.
+func Export(tasks []*models.Task) *models.TaskManifest {
+	manifest := &models.TaskManifest{
+		Version: models.TaskManifestVersion,
+		Tasks:   make([]models.TaskManifestEntry, 0, len(tasks)),
+	}
+
+	for _, task := range tasks {
+		priority := task.Priority
+		timeout := task.TimeoutSeconds
+
+		entry := models.TaskManifestEntry{
+			Key:            keyOf(task),
+			Name:           task.Name,
+			Description:    task.Description,
+			Script:         task.ScriptContent,
+			ScriptType:     task.ScriptType,
+			Priority:       &priority,
+			TimeoutSeconds: &timeout,
+			SecurityLevel:  task.SecurityLevel,
+			Image:          task.Image,
+			NetworkMode:    task.NetworkMode,
+
+			RequiredCapabilities: task.RequiredCapabilities,
+			NetworkAllowlist:     task.NetworkAllowlist,
+			Labels:               task.Labels,
+		}
+		if len(task.Metadata) > 0 {
+			entry.Metadata = task.Metadata
+		}
+		manifest.Tasks = append(manifest.Tasks, entry)
+	}
+
+	return manifest
+}
+
+// Desired returns the task as the manifest entry declares it. Without a
+// current task a new task of the user is returned; otherwise a copy of the
+// current task with the fields the manifest covers replaced. A changed image
+// has no digest yet and must be pinned before the task is saved.
+func Desired(entry *models.TaskManifestEntry, current *models.Task, userID uuid.UUID) *models.Task {
+	var task models.Task
+	if current != nil {
+		task = *current
+	} else {
+		task = models.Task{
+			BaseModel: models.BaseModel{ID: uuid.New()},
+			UserID:    userID,
+			Status:    models.TaskStatusPending,
+		}
+	}
+
+	key := entry.Key
+	task.ExternalKey = &key
+	task.Name = entry.Name
+	task.Description = entry.Description
+	task.ScriptContent = entry.Script
+	task.ScriptType = entry.ScriptType
+	task.Metadata = entry.Metadata
+
+	task.Priority = defaultPriority
+	if entry.Priority != nil {
+		task.Priority = *entry.Priority
+	}
+	task.TimeoutSeconds = config.DefaultTaskTimeout
+	if entry.TimeoutSeconds != nil {
+		task.TimeoutSeconds = *entry.TimeoutSeconds
+	}
+
+	task.RequiredCapabilities = models.NormalizeCapabilities(entry.RequiredCapabilities)
+	task.SecurityLevel = entry.SecurityLevel
+	if task.SecurityLevel == "" {
+		task.SecurityLevel = models.SecurityLevelStandard
+	}
+	task.NetworkMode = entry.NetworkMode
+	if task.NetworkMode == "" {
+		task.NetworkMode = models.NetworkModeNone
+	}
+	task.NetworkAllowlist = models.NormalizeNetworkAllowlist(entry.NetworkAllowlist)
+	task.Labels = entry.Labels
+
+	// The digest is kept as long as the image is named the same way
+	switch {
+	case entry.Image == nil || *entry.Image == "":
+		task.Image = nil
+		task.ImageDigest = nil
+	case current == nil || current.Image == nil || *current.Image != *entry.Image:
+		image := *entry.Image
+		task.Image = &image
+		task.ImageDigest = nil
+	}
+
+	return &task
+}
+
+// Plan matches the manifest entries with the user's existing tasks and
+// returns the change each entry makes, in manifest order. With prune, the
+// tasks whose external key is not in the manifest are deleted; tasks
+// without an external key are never deleted.
+func Plan(manifest *models.TaskManifest, userID uuid.UUID, existing []*models.Task, prune bool) []models.TaskManifestChange {
+	byKey := make(map[string]*models.Task, len(existing))
+	byID := make(map[string]*models.Task, len(existing))
+	for _, task := range existing {
+		if task.ExternalKey != nil {
+			byKey[*task.ExternalKey] = task
+		} else {
+			byID[task.ID.String()] = task
+		}
+	}
+
+	changes := make([]models.TaskManifestChange, 0, len(manifest.Tasks))
+	declared := make(map[string]bool, len(manifest.Tasks))
+	for i := range manifest.Tasks {
+		entry := &manifest.Tasks[i]
+		declared[entry.Key] = true
+
+		current, ok := byKey[entry.Key]
+		if !ok {
+			current = byID[entry.Key]
+		}
+
+		change := models.TaskManifestChange{
+			Key:     entry.Key,
+			Name:    entry.Name,
+			Current: current,
+			Desired: Desired(entry, current, userID),
+		}
+		if current == nil {
+			change.Action = models.TaskManifestActionCreate
+		} else {
+			id := current.ID
+			change.TaskID = &id
+			change.Fields = diffTasks(current, change.Desired)
+			change.Action = models.TaskManifestActionUnchanged
+			if len(change.Fields) > 0 {
+				change.Action = models.TaskManifestActionUpdate
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	if prune {
+		for _, task := range existing {
+			if task.ExternalKey == nil || declared[*task.ExternalKey] {
+				continue
+			}
+			id := task.ID
+			changes = append(changes, models.TaskManifestChange{
+				Action:  models.TaskManifestActionDelete,
+				Key:     *task.ExternalKey,
+				Name:    task.Name,
+				TaskID:  &id,
+				Current: task,
+			})
+		}
+	}
+
+	return changes
+}
+
+// Summarize counts the changes by action
+func Summarize(changes []models.TaskManifestChange) models.TaskImportSummary {
+	var summary models.TaskImportSummary
+	for _, change := range changes {
+		switch change.Action {
+		case models.TaskManifestActionCreate:
+			summary.Create++
+		case models.TaskManifestActionUpdate:
+			summary.Update++
+		case models.TaskManifestActionDelete:
+			summary.Delete++
+		case models.TaskManifestActionUnchanged:
+			summary.Unchanged++
+		}
+	}
+	return summary
+}
+
+// keyOf returns the key a task is declared under
+func keyOf(task *models.Task) string {
+	if task.ExternalKey != nil {
+		return *task.ExternalKey
+	}
+	return task.ID.String()
+}
+
+// diffTasks lists the manifest fields that differ between two tasks
+func diffTasks(current, desired *models.Task) []models.TaskManifestFieldChange {
+	var fields []models.TaskManifestFieldChange
+	add := func(field string, from, to interface{}) {
+		fields = append(fields, models.TaskManifestFieldChange{Field: field, From: from, To: to})
+	}
+
+	if stringValue(current.ExternalKey) != stringValue(desired.ExternalKey) {
+		add("key", current.ExternalKey, desired.ExternalKey)
+	}
+	if current.Name != desired.Name {
+		add("name", current.Name, desired.Name)
+	}
+	if stringValue(current.Description) != stringValue(desired.Description) {
+		add("description", current.Description, desired.Description)
+	}
+	if current.ScriptContent != desired.ScriptContent {
+		fields = append(fields, models.TaskManifestFieldChange{
+			Field: "script",
+			Diff:  diffScripts(current.ScriptContent, desired.ScriptContent),
+		})
+	}
+	if current.ScriptType != desired.ScriptType {
+		add("script_type", current.ScriptType, desired.ScriptType)
+	}
+	if current.Priority != desired.Priority {
+		add("priority", current.Priority, desired.Priority)
+	}
+	if current.TimeoutSeconds != desired.TimeoutSeconds {
+		add("timeout_seconds", current.TimeoutSeconds, desired.TimeoutSeconds)
+	}
+	if !sameMetadata(current.Metadata, desired.Metadata) {
+		add("metadata", current.Metadata, desired.Metadata)
+	}
+	if !sameStrings(models.NormalizeCapabilities(current.RequiredCapabilities), desired.RequiredCapabilities) {
+		add("required_capabilities", current.RequiredCapabilities, desired.RequiredCapabilities)
+	}
+	if current.SecurityLevel != desired.SecurityLevel {
+		add("security_level", current.SecurityLevel, desired.SecurityLevel)
+	}
+	if stringValue(current.Image) != stringValue(desired.Image) {
+		add("image", current.Image, desired.Image)
+	}
+	if current.NetworkMode != desired.NetworkMode {
+		add("network_mode", current.NetworkMode, desired.NetworkMode)
+	}
+	if !sameStrings(models.NormalizeNetworkAllowlist(current.NetworkAllowlist), desired.NetworkAllowlist) {
+		add("network_allowlist", current.NetworkAllowlist, desired.NetworkAllowlist)
+	}
+	if !sameLabels(current.Labels, desired.Labels) {
+		add("labels", current.Labels, desired.Labels)
+	}
+
+	return fields
+}
+
+// diffScripts returns a unified diff of two scripts
+func diffScripts(from, to string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: "current",
+		ToFile:   "manifest",
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// sameMetadata compares metadata by its JSON value, as numbers decoded from
+// YAML and from the database have different Go types
+func sameMetadata(a, b models.JSONB) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var normalized [2]interface{}
+	for i, metadata := range []models.JSONB{a, b} {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return false
+		}
+		if err := json.Unmarshal(data, &normalized[i]); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(normalized[0], normalized[1])
+}
+
+// sameStrings compares string lists, treating nil and empty as equal
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameLabels compares labels, treating nil and empty as equal
+func sameLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
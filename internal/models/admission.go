@@ -0,0 +1,15 @@
+package models
+
+import "fmt"
+
+// PolicyViolation explains why an admission policy denied a task
+type PolicyViolation struct {
+	// Rule is the policy rule the task violates, e.g. "script_types"
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String formats the violation for logs and error messages
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
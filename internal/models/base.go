@@ -31,6 +31,9 @@ type ErrorResponse struct {
 	Error            string            `json:"error"`
 	Details          string            `json:"details,omitempty"`
 	ValidationErrors []ValidationError `json:"validation_errors,omitempty"`
+	Findings         []ScriptFinding   `json:"findings,omitempty"`
+	Policy           string            `json:"policy,omitempty"`
+	Violations       []PolicyViolation `json:"violations,omitempty"`
 }
 
 // ValidationError represents a field validation error
@@ -41,6 +44,13 @@ type ValidationError struct {
 	Message string `json:"message"`
 }
 
+// CursorPagination describes the page of a cursor-paginated listing
+type CursorPagination struct {
+	HasMore    bool    `json:"has_more"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
+}
+
 // JSONB represents a JSONB field that can be scanned from database and marshaled to JSON
 type JSONB map[string]interface{}
 
@@ -0,0 +1,254 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBulkJobItems is the maximum number of operations a bulk job performs
+const MaxBulkJobItems = 1000
+
+// BulkJobKind is how the operations of a bulk job were given
+type BulkJobKind string
+
+const (
+	// BulkJobKindBatch performs a list of operations, each on its own task
+	BulkJobKindBatch BulkJobKind = "batch"
+
+	// BulkJobKindBulk performs one action on every task matching a filter
+	BulkJobKindBulk BulkJobKind = "bulk"
+)
+
+// BulkAction is the operation a bulk job performs on a task
+type BulkAction string
+
+const (
+	BulkActionCreate  BulkAction = "create"
+	BulkActionUpdate  BulkAction = "update"
+	BulkActionDelete  BulkAction = "delete"
+	BulkActionExecute BulkAction = "execute"
+
+	// BulkActionCancel cancels the pending or running execution of the task
+	BulkActionCancel BulkAction = "cancel"
+)
+
+// BulkJobStatus represents the status of a bulk job
+type BulkJobStatus string
+
+const (
+	BulkJobStatusPending   BulkJobStatus = "pending"
+	BulkJobStatusRunning   BulkJobStatus = "running"
+	BulkJobStatusCompleted BulkJobStatus = "completed"
+
+	// BulkJobStatusFailed means the job stopped before performing every
+	// operation, for instance because the server shut down. The operations
+	// that failed in a completed job only fail their own result.
+	BulkJobStatusFailed BulkJobStatus = "failed"
+)
+
+// BulkOperationStatus represents the outcome of a single operation of a bulk job
+type BulkOperationStatus string
+
+const (
+	BulkOperationStatusSucceeded BulkOperationStatus = "succeeded"
+	BulkOperationStatusFailed    BulkOperationStatus = "failed"
+)
+
+// BulkOperation is a single operation of a bulk job
+type BulkOperation struct {
+	Action BulkAction `json:"action" validate:"required,oneof=create update delete execute"`
+
+	// TaskID is the task the operation applies to; all actions but create
+	// require it
+	TaskID *uuid.UUID `json:"task_id,omitempty"`
+
+	// Task is the task to create, for the create action
+	Task *CreateTaskRequest `json:"task,omitempty"`
+
+	// Changes are the fields to update, for the update action
+	Changes *UpdateTaskRequest `json:"changes,omitempty"`
+}
+
+// BatchTaskRequest represents the request to perform a list of task operations
+type BatchTaskRequest struct {
+	Operations []BulkOperation `json:"operations" validate:"required,min=1,max=1000,dive"`
+}
+
+// BulkTaskActionRequest represents the request to perform an action on every
+// task matching a filter
+type BulkTaskActionRequest struct {
+	Action BulkAction `json:"action" validate:"required,oneof=delete execute cancel"`
+}
+
+// BulkOperationResult is the outcome of a single operation of a bulk job
+type BulkOperationResult struct {
+	// Index is the position of the operation in the job
+	Index  int                 `json:"index"`
+	Action BulkAction          `json:"action"`
+	Status BulkOperationStatus `json:"status"`
+
+	// TaskID is the task operated on, or the created task
+	TaskID *uuid.UUID `json:"task_id,omitempty"`
+
+	// ExecutionID is the execution started or cancelled
+	ExecutionID *uuid.UUID `json:"execution_id,omitempty"`
+
+	// Error tells why the operation failed
+	Error string `json:"error,omitempty"`
+}
+
+// BulkJob performs a set of task operations in the background, recording the
+// outcome of each
+type BulkJob struct {
+	BaseModel
+	UserID uuid.UUID   `json:"user_id" db:"user_id"`
+	Kind   BulkJobKind `json:"kind" db:"kind"`
+
+	// Action and Filter are the action and the task filter of bulk jobs;
+	// the filter is kept as the query string it was given as
+	Action *BulkAction `json:"action,omitempty" db:"action"`
+	Filter *string     `json:"filter,omitempty" db:"filter"`
+
+	Status    BulkJobStatus `json:"status" db:"status"`
+	Total     int           `json:"total" db:"total"`
+	Processed int           `json:"processed" db:"processed"`
+	Succeeded int           `json:"succeeded" db:"succeeded"`
+	Failed    int           `json:"failed" db:"failed"`
+
+	Results []BulkOperationResult `json:"results" db:"results"`
+
+	// Error tells why a failed job stopped
+	Error *string `json:"error,omitempty" db:"error"`
+
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// BulkJobResponse represents the bulk job response
+type BulkJobResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	Kind      BulkJobKind   `json:"kind"`
+	Action    *BulkAction   `json:"action,omitempty"`
+	Filter    *string       `json:"filter,omitempty"`
+	Status    BulkJobStatus `json:"status"`
+	Total     int           `json:"total"`
+	Processed int           `json:"processed"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+
+	// Results are the outcomes of the operations processed so far, in the
+	// order of the operations; they are left out of job listings
+	Results []BulkOperationResult `json:"results,omitempty"`
+	Error   *string               `json:"error,omitempty"`
+
+	CreatedAt   string  `json:"created_at"`
+	StartedAt   *string `json:"started_at,omitempty"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+}
+
+// BulkJobListResponse represents a paginated list of bulk jobs
+type BulkJobListResponse struct {
+	Jobs   []BulkJobResponse `json:"jobs"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// ToResponse converts BulkJob to BulkJobResponse
+func (j *BulkJob) ToResponse() BulkJobResponse {
+	response := BulkJobResponse{
+		ID:        j.ID,
+		Kind:      j.Kind,
+		Action:    j.Action,
+		Filter:    j.Filter,
+		Status:    j.Status,
+		Total:     j.Total,
+		Processed: j.Processed,
+		Succeeded: j.Succeeded,
+		Failed:    j.Failed,
+		Results:   j.Results,
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if j.StartedAt != nil {
+		startedAt := j.StartedAt.Format("2006-01-02T15:04:05Z07:00")
+		response.StartedAt = &startedAt
+	}
+	if j.CompletedAt != nil {
+		completedAt := j.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
+		response.CompletedAt = &completedAt
+	}
+
+	return response
+}
+
+// Finished reports whether the job has stopped processing operations
+func (j *BulkJob) Finished() bool {
+	return j.Status == BulkJobStatusCompleted || j.Status == BulkJobStatusFailed
+}
+
+// Record adds the outcome of an operation to the job's progress
+func (j *BulkJob) Record(result BulkOperationResult) {
+	j.Results = append(j.Results, result)
+	j.Processed++
+	if result.Status == BulkOperationStatusSucceeded {
+		j.Succeeded++
+	} else {
+		j.Failed++
+	}
+}
+
+// Validate checks that the operation carries what its action needs. The
+// task and changes themselves are validated when the operation is performed.
+func (o *BulkOperation) Validate() error {
+	switch o.Action {
+	case BulkActionCreate:
+		if o.Task == nil {
+			return fmt.Errorf("create requires task")
+		}
+		if o.TaskID != nil || o.Changes != nil {
+			return fmt.Errorf("create takes only task")
+		}
+	case BulkActionUpdate:
+		if o.TaskID == nil || o.Changes == nil {
+			return fmt.Errorf("update requires task_id and changes")
+		}
+		if o.Task != nil {
+			return fmt.Errorf("update takes only task_id and changes")
+		}
+	case BulkActionDelete, BulkActionExecute, BulkActionCancel:
+		if o.TaskID == nil {
+			return fmt.Errorf("%s requires task_id", o.Action)
+		}
+		if o.Task != nil || o.Changes != nil {
+			return fmt.Errorf("%s takes only task_id", o.Action)
+		}
+	default:
+		return fmt.Errorf("invalid action: %s", o.Action)
+	}
+	return nil
+}
+
+// Validate validates the batch and each of its operations
+func (r *BatchTaskRequest) Validate() error {
+	if len(r.Operations) == 0 {
+		return fmt.Errorf("operations are required")
+	}
+	if len(r.Operations) > MaxBulkJobItems {
+		return fmt.Errorf("too many operations: %d (maximum %d)", len(r.Operations), MaxBulkJobItems)
+	}
+
+	for i := range r.Operations {
+		operation := &r.Operations[i]
+		if operation.Action == BulkActionCancel {
+			return fmt.Errorf("operation %d: invalid action: %s", i, operation.Action)
+		}
+		if err := operation.Validate(); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return nil
+}
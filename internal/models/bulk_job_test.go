@@ -0,0 +1,156 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkOperation_Validate(t *testing.T) {
+	taskID := uuid.New()
+	name := "renamed"
+
+	tests := []struct {
+		name      string
+		operation BulkOperation
+		errMsg    string
+	}{
+		{
+			name:      "create",
+			operation: BulkOperation{Action: BulkActionCreate, Task: &CreateTaskRequest{Name: "task"}},
+		},
+		{
+			name:      "create without task",
+			operation: BulkOperation{Action: BulkActionCreate},
+			errMsg:    "create requires task",
+		},
+		{
+			name:      "create with task ID",
+			operation: BulkOperation{Action: BulkActionCreate, TaskID: &taskID, Task: &CreateTaskRequest{}},
+			errMsg:    "create takes only task",
+		},
+		{
+			name:      "update",
+			operation: BulkOperation{Action: BulkActionUpdate, TaskID: &taskID, Changes: &UpdateTaskRequest{Name: &name}},
+		},
+		{
+			name:      "update without changes",
+			operation: BulkOperation{Action: BulkActionUpdate, TaskID: &taskID},
+			errMsg:    "update requires task_id and changes",
+		},
+		{
+			name:      "delete",
+			operation: BulkOperation{Action: BulkActionDelete, TaskID: &taskID},
+		},
+		{
+			name:      "execute without task ID",
+			operation: BulkOperation{Action: BulkActionExecute},
+			errMsg:    "execute requires task_id",
+		},
+		{
+			name:      "cancel with changes",
+			operation: BulkOperation{Action: BulkActionCancel, TaskID: &taskID, Changes: &UpdateTaskRequest{}},
+			errMsg:    "cancel takes only task_id",
+		},
+		{
+			name:      "unknown action",
+			operation: BulkOperation{Action: "archive", TaskID: &taskID},
+			errMsg:    "invalid action: archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.operation.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestBatchTaskRequest_Validate(t *testing.T) {
+	taskID := uuid.New()
+
+	t.Run("valid", func(t *testing.T) {
+		req := BatchTaskRequest{Operations: []BulkOperation{
+			{Action: BulkActionDelete, TaskID: &taskID},
+			{Action: BulkActionExecute, TaskID: &taskID},
+		}}
+		assert.NoError(t, req.Validate())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		req := BatchTaskRequest{}
+		assert.EqualError(t, req.Validate(), "operations are required")
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		operations := make([]BulkOperation, MaxBulkJobItems+1)
+		for i := range operations {
+			operations[i] = BulkOperation{Action: BulkActionDelete, TaskID: &taskID}
+		}
+		req := BatchTaskRequest{Operations: operations}
+		assert.Contains(t, req.Validate().Error(), "too many operations")
+	})
+
+	t.Run("invalid operation is reported with its index", func(t *testing.T) {
+		req := BatchTaskRequest{Operations: []BulkOperation{
+			{Action: BulkActionDelete, TaskID: &taskID},
+			{Action: BulkActionUpdate, TaskID: &taskID},
+		}}
+		assert.EqualError(t, req.Validate(), "operation 1: update requires task_id and changes")
+	})
+
+	t.Run("cancel is only a bulk action", func(t *testing.T) {
+		req := BatchTaskRequest{Operations: []BulkOperation{{Action: BulkActionCancel, TaskID: &taskID}}}
+		assert.True(t, strings.HasPrefix(req.Validate().Error(), "operation 0: invalid action"))
+	})
+}
+
+func TestBulkJob_Record(t *testing.T) {
+	job := &BulkJob{Status: BulkJobStatusRunning, Total: 3}
+
+	job.Record(BulkOperationResult{Index: 0, Action: BulkActionDelete, Status: BulkOperationStatusSucceeded})
+	job.Record(BulkOperationResult{Index: 1, Action: BulkActionDelete, Status: BulkOperationStatusFailed, Error: "task not found"})
+
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+	assert.Len(t, job.Results, 2)
+	assert.False(t, job.Finished())
+
+	job.Status = BulkJobStatusCompleted
+	assert.True(t, job.Finished())
+}
+
+func TestBulkJob_ToResponse(t *testing.T) {
+	action := BulkActionCancel
+	filter := "selector=team%3Dml"
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := &BulkJob{
+		BaseModel: BaseModel{ID: uuid.New(), CreatedAt: started},
+		Kind:      BulkJobKindBulk,
+		Action:    &action,
+		Filter:    &filter,
+		Status:    BulkJobStatusRunning,
+		Total:     2,
+		StartedAt: &started,
+	}
+
+	response := job.ToResponse()
+	assert.Equal(t, job.ID, response.ID)
+	assert.Equal(t, &action, response.Action)
+	assert.Equal(t, &filter, response.Filter)
+	assert.Equal(t, "2024-01-02T03:04:05Z", response.CreatedAt)
+	require.NotNil(t, response.StartedAt)
+	assert.Equal(t, "2024-01-02T03:04:05Z", *response.StartedAt)
+	assert.Nil(t, response.CompletedAt)
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskNetworkMode represents which network destinations a task may reach
+type TaskNetworkMode string
+
+const (
+	// NetworkModeNone runs the task without any network access
+	NetworkModeNone TaskNetworkMode = "none"
+
+	// NetworkModeInternal allows connections to internal (private) addresses only
+	NetworkModeInternal TaskNetworkMode = "internal"
+
+	// NetworkModeAllowlist allows connections to the task's allowlisted hosts and CIDRs only
+	NetworkModeAllowlist TaskNetworkMode = "allowlist"
+)
+
+// MaxNetworkAllowlistEntries is the maximum number of destinations a task may allowlist
+const MaxNetworkAllowlistEntries = 32
+
+// hostnamePattern matches a DNS name, optionally prefixed with "*." to match subdomains
+var hostnamePattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidateNetworkMode validates the task network mode
+func ValidateNetworkMode(mode TaskNetworkMode) error {
+	switch mode {
+	case NetworkModeNone, NetworkModeInternal, NetworkModeAllowlist:
+		return nil
+	default:
+		return fmt.Errorf("invalid network mode: %s", mode)
+	}
+}
+
+// ValidateNetworkDestination validates a single allowlist entry: a hostname
+// ("api.internal", "*.example.com"), an IP address or a CIDR
+func ValidateNetworkDestination(destination string) error {
+	if destination == "" {
+		return fmt.Errorf("network destination cannot be empty")
+	}
+	if len(destination) > 253 {
+		return fmt.Errorf("network destination %q is too long (max 253 characters)", destination)
+	}
+	if strings.Contains(destination, "/") {
+		if _, err := netip.ParsePrefix(destination); err != nil {
+			return fmt.Errorf("invalid network destination: %s", destination)
+		}
+		return nil
+	}
+	if net.ParseIP(destination) != nil {
+		return nil
+	}
+	if !hostnamePattern.MatchString(strings.ToLower(destination)) {
+		return fmt.Errorf("invalid network destination: %s", destination)
+	}
+	return nil
+}
+
+// ValidateNetworkPolicy validates a network mode together with its allowlist.
+// Only the allowlist mode takes destinations, and it requires at least one.
+func ValidateNetworkPolicy(mode TaskNetworkMode, allowlist []string) error {
+	if err := ValidateNetworkMode(mode); err != nil {
+		return err
+	}
+
+	allowlist = NormalizeNetworkAllowlist(allowlist)
+	if mode != NetworkModeAllowlist {
+		if len(allowlist) > 0 {
+			return fmt.Errorf("network allowlist requires network mode %s", NetworkModeAllowlist)
+		}
+		return nil
+	}
+
+	if len(allowlist) == 0 {
+		return fmt.Errorf("network mode %s requires at least one destination", NetworkModeAllowlist)
+	}
+	if len(allowlist) > MaxNetworkAllowlistEntries {
+		return fmt.Errorf("too many network destinations (max %d)", MaxNetworkAllowlistEntries)
+	}
+	for _, destination := range allowlist {
+		if err := ValidateNetworkDestination(destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeNetworkAllowlist lowercases, trims, de-duplicates and sorts destinations
+func NormalizeNetworkAllowlist(allowlist []string) []string {
+	if len(allowlist) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(allowlist))
+	result := make([]string, 0, len(allowlist))
+	for _, destination := range allowlist {
+		destination = strings.ToLower(strings.TrimSpace(destination))
+		if destination == "" {
+			continue
+		}
+		if _, exists := seen[destination]; exists {
+			continue
+		}
+		seen[destination] = struct{}{}
+		result = append(result, destination)
+	}
+	sort.Strings(result)
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// HasNetworkAccess reports whether the task's network policy grants any network access
+func (t *Task) HasNetworkAccess() bool {
+	return t.NetworkMode != "" && t.NetworkMode != NetworkModeNone
+}
+
+// NetworkEvent records a connection attempt made by an execution
+type NetworkEvent struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ExecutionID uuid.UUID `json:"execution_id" db:"execution_id"`
+	Host        string    `json:"host" db:"host" validate:"required,max=253"`
+	Port        int       `json:"port" db:"port" validate:"min=0,max=65535"`
+	Allowed     bool      `json:"allowed" db:"allowed"`
+	Reason      string    `json:"reason,omitempty" db:"reason" validate:"max=255"`
+	OccurredAt  time.Time `json:"occurred_at" db:"occurred_at" validate:"required"`
+}
+
+// NetworkEventListResponse represents the response for listing network events
+type NetworkEventListResponse struct {
+	Events []NetworkEvent `json:"events"`
+	Total  int            `json:"total"`
+}
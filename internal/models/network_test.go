@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNetworkDestination(t *testing.T) {
+	tests := []struct {
+		destination string
+		wantErr     bool
+	}{
+		{"pypi.org", false},
+		{"files.pythonhosted.org", false},
+		{"*.example.com", false},
+		{"10.0.0.0/8", false},
+		{"192.168.1.10", false},
+		{"2001:db8::/32", false},
+		{"", true},
+		{"10.0.0.0/33", true},
+		{"*example.com", true},
+		{"example.*.com", true},
+		{"https://pypi.org", true},
+		{"pypi.org:443", true},
+		{strings.Repeat("a", 254), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			err := ValidateNetworkDestination(tt.destination)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      TaskNetworkMode
+		allowlist []string
+		errMsg    string
+	}{
+		{name: "no network", mode: NetworkModeNone},
+		{name: "internal", mode: NetworkModeInternal},
+		{name: "allowlist", mode: NetworkModeAllowlist, allowlist: []string{"pypi.org", "10.0.0.0/8"}},
+		{name: "unknown mode", mode: "public", errMsg: "invalid network mode"},
+		{name: "allowlist with internal mode", mode: NetworkModeInternal, allowlist: []string{"pypi.org"}, errMsg: "requires network mode allowlist"},
+		{name: "empty allowlist", mode: NetworkModeAllowlist, allowlist: []string{" "}, errMsg: "requires at least one destination"},
+		{name: "invalid destination", mode: NetworkModeAllowlist, allowlist: []string{"pypi.org/simple"}, errMsg: "invalid network destination"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNetworkPolicy(tt.mode, tt.allowlist)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+
+	tooMany := make([]string, MaxNetworkAllowlistEntries+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("a", i+1) + ".example.com"
+	}
+	assert.ErrorContains(t, ValidateNetworkPolicy(NetworkModeAllowlist, tooMany), "too many network destinations")
+}
+
+func TestNormalizeNetworkAllowlist(t *testing.T) {
+	assert.Equal(t, []string{"10.0.0.0/8", "pypi.org"}, NormalizeNetworkAllowlist([]string{" PyPI.org", "10.0.0.0/8", "pypi.org", ""}))
+	assert.Nil(t, NormalizeNetworkAllowlist([]string{" "}))
+	assert.Nil(t, NormalizeNetworkAllowlist(nil))
+}
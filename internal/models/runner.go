@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxRunnerLogChunkBytes bounds the size of a single log upload from a runner
+const MaxRunnerLogChunkBytes = 128 * 1024
+
+// RunnerJobRequest represents a runner's request to claim a job
+type RunnerJobRequest struct {
+	Capabilities []string `json:"capabilities,omitempty" validate:"omitempty,max=16,dive,capability"`
+	WaitSeconds  int      `json:"wait_seconds,omitempty" validate:"omitempty,min=0,max=60"`
+}
+
+// RunnerJob represents a job leased to a remote runner
+type RunnerJob struct {
+	ExecutionID          uuid.UUID         `json:"execution_id"`
+	TaskID               uuid.UUID         `json:"task_id"`
+	LeaseToken           string            `json:"lease_token"`
+	Name                 string            `json:"name"`
+	ScriptContent        string            `json:"script_content"`
+	ScriptType           ScriptType        `json:"script_type"`
+	TimeoutSeconds       int               `json:"timeout_seconds"`
+	RequiredCapabilities []string          `json:"required_capabilities,omitempty"`
+	SecurityLevel        TaskSecurityLevel `json:"security_level,omitempty"`
+	Image                *string           `json:"image,omitempty"`
+	ImageDigest          *string           `json:"image_digest,omitempty"`
+	NetworkMode          TaskNetworkMode   `json:"network_mode,omitempty"`
+	NetworkAllowlist     []string          `json:"network_allowlist,omitempty"`
+}
+
+// RunnerHeartbeatRequest represents a runner's lease renewal for a job
+type RunnerHeartbeatRequest struct {
+	LeaseToken string `json:"lease_token" validate:"required"`
+}
+
+// RunnerHeartbeatResponse tells the runner whether to keep executing the job
+type RunnerHeartbeatResponse struct {
+	Cancelled bool `json:"cancelled"`
+}
+
+// RunnerLogRequest represents a chunk of output streamed by a runner
+type RunnerLogRequest struct {
+	LeaseToken string `json:"lease_token" validate:"required"`
+	Stdout     string `json:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+}
+
+// RunnerResultRequest represents the final result of a job submitted by a runner.
+// Stdout and Stderr, when set, replace any output streamed through log uploads.
+type RunnerResultRequest struct {
+	LeaseToken       string                  `json:"lease_token" validate:"required"`
+	Status           ExecutionStatus         `json:"status" validate:"required"`
+	ReturnCode       *int                    `json:"return_code,omitempty"`
+	Stdout           *string                 `json:"stdout,omitempty"`
+	Stderr           *string                 `json:"stderr,omitempty"`
+	ExecutionTimeMs  *int                    `json:"execution_time_ms,omitempty" validate:"omitempty,min=0"`
+	MemoryUsageBytes *int64                  `json:"memory_usage_bytes,omitempty" validate:"omitempty,min=0"`
+	Runtime          *string                 `json:"runtime,omitempty" validate:"omitempty,max=64"`
+	Truncated        bool                    `json:"truncated,omitempty"`
+	OOMKilled        bool                    `json:"oom_killed,omitempty"`
+	ExitSignal       *string                 `json:"exit_signal,omitempty" validate:"omitempty,max=16"`
+	TimeoutPhase     *TimeoutPhase           `json:"timeout_phase,omitempty" validate:"omitempty,oneof=image_pull start run"`
+	ErrorCategory    *ExecutionErrorCategory `json:"error_category,omitempty" validate:"omitempty,max=32"`
+	NetworkEvents    []NetworkEvent          `json:"network_events,omitempty" validate:"omitempty,max=1000,dive"`
+}
+
+// ValidateRunnerResultStatus validates that a runner reported a terminal execution status
+func ValidateRunnerResultStatus(status ExecutionStatus) error {
+	switch status {
+	case ExecutionStatusCompleted, ExecutionStatusFailed, ExecutionStatusTimeout:
+		return nil
+	default:
+		return fmt.Errorf("invalid result status: %s", status)
+	}
+}
+
+// ValidateRunnerLogChunk validates the size of a runner log upload
+func ValidateRunnerLogChunk(stdout, stderr string) error {
+	if len(stdout)+len(stderr) > MaxRunnerLogChunkBytes {
+		return fmt.Errorf("log chunk exceeds %d bytes", MaxRunnerLogChunkBytes)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import "fmt"
+
+// ScriptAnalysisMode controls what happens when script analysis reports findings
+type ScriptAnalysisMode string
+
+const (
+	// ScriptAnalysisModeBlock rejects scripts with findings
+	ScriptAnalysisModeBlock ScriptAnalysisMode = "block"
+
+	// ScriptAnalysisModeWarn accepts scripts with findings and returns them as warnings
+	ScriptAnalysisModeWarn ScriptAnalysisMode = "warn"
+
+	// ScriptAnalysisModeAudit accepts scripts with findings and only logs them
+	ScriptAnalysisModeAudit ScriptAnalysisMode = "audit"
+)
+
+// ValidateScriptAnalysisMode validates the script analysis mode
+func ValidateScriptAnalysisMode(mode ScriptAnalysisMode) error {
+	switch mode {
+	case ScriptAnalysisModeBlock, ScriptAnalysisModeWarn, ScriptAnalysisModeAudit:
+		return nil
+	default:
+		return fmt.Errorf("invalid script analysis mode: %s", mode)
+	}
+}
+
+// FindingSeverity represents how dangerous a script finding is
+type FindingSeverity string
+
+const (
+	FindingSeverityLow      FindingSeverity = "low"
+	FindingSeverityMedium   FindingSeverity = "medium"
+	FindingSeverityHigh     FindingSeverity = "high"
+	FindingSeverityCritical FindingSeverity = "critical"
+)
+
+// findingSeverityRanks orders the severities from least to most severe
+var findingSeverityRanks = map[FindingSeverity]int{
+	FindingSeverityLow:      1,
+	FindingSeverityMedium:   2,
+	FindingSeverityHigh:     3,
+	FindingSeverityCritical: 4,
+}
+
+// ValidateFindingSeverity validates the finding severity
+func ValidateFindingSeverity(severity FindingSeverity) error {
+	if _, ok := findingSeverityRanks[severity]; !ok {
+		return fmt.Errorf("invalid finding severity: %s", severity)
+	}
+	return nil
+}
+
+// Exceeds reports whether the severity is more severe than other
+func (s FindingSeverity) Exceeds(other FindingSeverity) bool {
+	return findingSeverityRanks[s] > findingSeverityRanks[other]
+}
+
+// ScriptFinding is an issue script analysis found in a task's script
+type ScriptFinding struct {
+	RuleID   string          `json:"rule_id"`
+	Severity FindingSeverity `json:"severity"`
+	Line     int             `json:"line"`
+	Column   int             `json:"column,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// String formats the finding for logs and error messages
+func (f ScriptFinding) String() string {
+	return fmt.Sprintf("%s at line %d: %s", f.RuleID, f.Line, f.Message)
+}
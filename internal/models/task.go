@@ -2,9 +2,13 @@ package models
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/labels"
 )
 
 // TaskStatus represents the status of a task
@@ -29,6 +33,20 @@ const (
 	ScriptTypeGo         ScriptType = "go"
 )
 
+// TaskSecurityLevel represents how strongly a task must be isolated from the host
+type TaskSecurityLevel string
+
+const (
+	// SecurityLevelStandard runs the task with the default container runtime
+	SecurityLevelStandard TaskSecurityLevel = "standard"
+
+	// SecurityLevelSandboxed runs the task under a user-space kernel (e.g. gVisor)
+	SecurityLevelSandboxed TaskSecurityLevel = "sandboxed"
+
+	// SecurityLevelIsolated runs the task in a lightweight VM (e.g. Kata Containers)
+	SecurityLevelIsolated TaskSecurityLevel = "isolated"
+)
+
 // Task represents a task in the system
 type Task struct {
 	BaseModel
@@ -41,6 +59,59 @@ type Task struct {
 	Priority       int        `json:"priority" db:"priority"`
 	TimeoutSeconds int        `json:"timeout_seconds" db:"timeout_seconds"`
 	Metadata       JSONB      `json:"metadata" db:"metadata"`
+
+	// RequiredCapabilities lists the worker capabilities (e.g. "script:python",
+	// "memory:large") a worker must advertise to pick up this task
+	RequiredCapabilities []string `json:"required_capabilities,omitempty" db:"required_capabilities"`
+
+	// SecurityLevel selects the container runtime the task is executed with
+	SecurityLevel TaskSecurityLevel `json:"security_level" db:"security_level"`
+
+	// Image is the custom container image the task runs in, as named by the
+	// user. When nil the default image for the script type is used.
+	Image *string `json:"image,omitempty" db:"image"`
+
+	// ImageDigest is the content digest Image resolved to when the task was
+	// saved. Executions always run this exact digest.
+	ImageDigest *string `json:"image_digest,omitempty" db:"image_digest"`
+
+	// NetworkMode and NetworkAllowlist restrict the destinations the task
+	// may connect to; the allowlist only applies to the allowlist mode
+	NetworkMode      TaskNetworkMode `json:"network_mode" db:"network_mode"`
+	NetworkAllowlist []string        `json:"network_allowlist,omitempty" db:"network_allowlist"`
+
+	// Revision is the number of the task's current script revision. It is
+	// incremented every time the script content or type changes.
+	Revision int `json:"revision" db:"revision"`
+
+	// ExternalKey identifies the task in the manifests it is managed by. It
+	// is unique per user and nil for tasks that aren't managed by a manifest.
+	ExternalKey *string `json:"external_key,omitempty" db:"external_key"`
+
+	// Labels are key/value pairs the task is selected by with label
+	// selectors, e.g. env=prod
+	Labels map[string]string `json:"labels,omitempty" db:"labels"`
+
+	// Version is incremented by every write to the task. Updates only apply
+	// to the version they were read at, and it is the task's ETag.
+	Version int `json:"version" db:"version"`
+
+	// DeletedAt is when the task was moved to the trash, where it is kept
+	// until it is restored or purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// PinnedImage returns the digest-pinned reference of the task's custom image,
+// or an empty string when the task uses the default image
+func (t *Task) PinnedImage() string {
+	if t.Image == nil || t.ImageDigest == nil {
+		return ""
+	}
+	name := *t.Image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	return name + "@" + *t.ImageDigest
 }
 
 // CreateTaskRequest represents the request to create a new task
@@ -52,6 +123,17 @@ type CreateTaskRequest struct {
 	Priority       *int       `json:"priority,omitempty" validate:"omitempty,min=0,max=10"`
 	TimeoutSeconds *int       `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=3600"`
 	Metadata       JSONB      `json:"metadata,omitempty"`
+
+	RequiredCapabilities []string `json:"required_capabilities,omitempty" validate:"omitempty,max=16,dive,capability"`
+
+	SecurityLevel *TaskSecurityLevel `json:"security_level,omitempty" validate:"omitempty,security_level"`
+
+	Image *string `json:"image,omitempty" validate:"omitempty,max=512"`
+
+	NetworkMode      *TaskNetworkMode `json:"network_mode,omitempty" validate:"omitempty,network_mode"`
+	NetworkAllowlist []string         `json:"network_allowlist,omitempty" validate:"omitempty,max=32,dive,network_destination"`
+
+	Labels map[string]string `json:"labels,omitempty" validate:"omitempty,max=64,dive,keys,label_key,endkeys,label_value"`
 }
 
 // UpdateTaskRequest represents the request to update a task
@@ -63,6 +145,17 @@ type UpdateTaskRequest struct {
 	Priority       *int        `json:"priority,omitempty" validate:"omitempty,min=0,max=10"`
 	TimeoutSeconds *int        `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=3600"`
 	Metadata       JSONB       `json:"metadata,omitempty"`
+
+	RequiredCapabilities []string `json:"required_capabilities,omitempty" validate:"omitempty,max=16,dive,capability"`
+
+	SecurityLevel *TaskSecurityLevel `json:"security_level,omitempty" validate:"omitempty,security_level"`
+
+	Image *string `json:"image,omitempty" validate:"omitempty,max=512"`
+
+	NetworkMode      *TaskNetworkMode `json:"network_mode,omitempty" validate:"omitempty,network_mode"`
+	NetworkAllowlist []string         `json:"network_allowlist,omitempty" validate:"omitempty,max=32,dive,network_destination"`
+
+	Labels map[string]string `json:"labels,omitempty" validate:"omitempty,max=64,dive,keys,label_key,endkeys,label_value"`
 }
 
 // TaskResponse represents the task response
@@ -79,10 +172,41 @@ type TaskResponse struct {
 	Metadata       JSONB      `json:"metadata"`
 	CreatedAt      string     `json:"created_at"`
 	UpdatedAt      string     `json:"updated_at"`
+
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+
+	SecurityLevel TaskSecurityLevel `json:"security_level"`
+
+	Image       *string `json:"image,omitempty"`
+	ImageDigest *string `json:"image_digest,omitempty"`
+
+	NetworkMode      TaskNetworkMode `json:"network_mode"`
+	NetworkAllowlist []string        `json:"network_allowlist,omitempty"`
+
+	Revision int `json:"revision"`
+
+	ExternalKey *string `json:"external_key,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Version int `json:"version"`
+
+	// DeletedAt is set on the tasks listed from the trash
+	DeletedAt *string `json:"deleted_at,omitempty"`
+
+	// ScriptFindings are the script analysis warnings reported when the task
+	// is saved with script analysis in warn mode
+	ScriptFindings []ScriptFinding `json:"script_findings,omitempty"`
 }
 
 // ToResponse converts Task to TaskResponse
 func (t *Task) ToResponse() TaskResponse {
+	var deletedAt *string
+	if t.DeletedAt != nil {
+		formatted := t.DeletedAt.Format("2006-01-02T15:04:05Z07:00")
+		deletedAt = &formatted
+	}
+
 	return TaskResponse{
 		ID:             t.ID,
 		UserID:         t.UserID,
@@ -96,6 +220,26 @@ func (t *Task) ToResponse() TaskResponse {
 		Metadata:       t.Metadata,
 		CreatedAt:      t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:      t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		RequiredCapabilities: t.RequiredCapabilities,
+
+		SecurityLevel: t.SecurityLevel,
+
+		Image:       t.Image,
+		ImageDigest: t.ImageDigest,
+
+		NetworkMode:      t.NetworkMode,
+		NetworkAllowlist: t.NetworkAllowlist,
+
+		Revision: t.Revision,
+
+		ExternalKey: t.ExternalKey,
+
+		Labels: t.Labels,
+
+		Version: t.Version,
+
+		DeletedAt: deletedAt,
 	}
 }
 
@@ -127,6 +271,16 @@ func ValidateScriptType(scriptType ScriptType) error {
 	}
 }
 
+// ValidateSecurityLevel validates the task security level
+func ValidateSecurityLevel(level TaskSecurityLevel) error {
+	switch level {
+	case SecurityLevelStandard, SecurityLevelSandboxed, SecurityLevelIsolated:
+		return nil
+	default:
+		return fmt.Errorf("invalid security level: %s", level)
+	}
+}
+
 // ValidateScriptContent validates the script content
 func ValidateScriptContent(content string) error {
 	if content == "" {
@@ -142,11 +296,6 @@ func ValidateScriptContent(content string) error {
 		return fmt.Errorf("script content is too long (max 65535 characters)")
 	}
 
-	// Basic security checks
-	if strings.Contains(strings.ToLower(content), "rm -rf") {
-		return fmt.Errorf("potentially dangerous script content detected")
-	}
-
 	return nil
 }
 
@@ -179,6 +328,110 @@ func ValidateTimeout(timeout int) error {
 	return nil
 }
 
+// MaxTaskCapabilities is the maximum number of capabilities a task may require
+const MaxTaskCapabilities = 16
+
+// capabilityPattern matches a capability name with an optional value, e.g. "gpu" or "script:python"
+var capabilityPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*(:[a-z0-9][a-z0-9_.-]*)?$`)
+
+// ValidateCapability validates a single capability name
+func ValidateCapability(capability string) error {
+	if capability == "" {
+		return fmt.Errorf("capability cannot be empty")
+	}
+	if len(capability) > 64 {
+		return fmt.Errorf("capability %q is too long (max 64 characters)", capability)
+	}
+	if !capabilityPattern.MatchString(capability) {
+		return fmt.Errorf("invalid capability: %s", capability)
+	}
+	return nil
+}
+
+// ValidateCapabilities validates a list of required capabilities
+func ValidateCapabilities(capabilities []string) error {
+	if len(capabilities) > MaxTaskCapabilities {
+		return fmt.Errorf("too many capabilities (max %d)", MaxTaskCapabilities)
+	}
+	for _, capability := range NormalizeCapabilities(capabilities) {
+		if err := ValidateCapability(capability); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxTaskLabels is the maximum number of labels on a task
+const MaxTaskLabels = 64
+
+// ValidateLabels validates the labels of a task
+func ValidateLabels(taskLabels map[string]string) error {
+	if len(taskLabels) > MaxTaskLabels {
+		return fmt.Errorf("too many labels (max %d)", MaxTaskLabels)
+	}
+	keys := make([]string, 0, len(taskLabels))
+	for key := range taskLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := labels.ValidateKey(key); err != nil {
+			return err
+		}
+		if err := labels.ValidateValue(taskLabels[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeCapabilities lowercases, trims, de-duplicates and sorts capabilities
+// so that equivalent sets always compare and route identically
+func NormalizeCapabilities(capabilities []string) []string {
+	if len(capabilities) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(capabilities))
+	result := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		capability = strings.ToLower(strings.TrimSpace(capability))
+		if capability == "" {
+			continue
+		}
+		if _, exists := seen[capability]; exists {
+			continue
+		}
+		seen[capability] = struct{}{}
+		result = append(result, capability)
+	}
+	sort.Strings(result)
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// HasCapabilities returns true if offered contains every capability in required
+func HasCapabilities(offered, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+
+	available := make(map[string]struct{}, len(offered))
+	for _, capability := range NormalizeCapabilities(offered) {
+		available[capability] = struct{}{}
+	}
+	for _, capability := range NormalizeCapabilities(required) {
+		if _, ok := available[capability]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // TaskListResponse represents the response for listing tasks
 type TaskListResponse struct {
 	Tasks  []TaskResponse `json:"tasks"`
@@ -187,6 +440,44 @@ type TaskListResponse struct {
 	Offset int            `json:"offset"`
 }
 
+// TaskSearchResponse represents a page of a filtered or sorted task listing
+type TaskSearchResponse struct {
+	Tasks      []TaskResponse   `json:"tasks"`
+	Pagination CursorPagination `json:"pagination"`
+	Limit      int              `json:"limit"`
+	SortOrder  string           `json:"sort_order"`
+	SortField  string           `json:"sort_field"`
+}
+
+// ImageUsage lists the tasks that run a specific image digest
+type ImageUsage struct {
+	Image   string      `json:"image"`
+	Digest  string      `json:"digest"`
+	TaskIDs []uuid.UUID `json:"task_ids"`
+}
+
+// ImageInventoryResponse represents the response for the image inventory
+type ImageInventoryResponse struct {
+	Images []ImageUsage `json:"images"`
+}
+
+// LabelUsage counts the tasks carrying a label key and value
+type LabelUsage struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	TaskCount int64  `json:"task_count"`
+}
+
+// LabelInventoryResponse represents the response for the label inventory
+type LabelInventoryResponse struct {
+	Labels []LabelUsage `json:"labels"`
+}
+
+// TrashPurgeResponse represents the response for purging tasks from the trash
+type TrashPurgeResponse struct {
+	Purged int64 `json:"purged"`
+}
+
 // State transition definitions for task status
 var taskStatusTransitions = map[TaskStatus][]TaskStatus{
 	TaskStatusPending: {
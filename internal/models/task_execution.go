@@ -19,19 +19,103 @@ const (
 	ExecutionStatusCancelled ExecutionStatus = "cancelled"
 )
 
+// TimeoutPhase is the phase an execution was in when it ran out of time
+type TimeoutPhase string
+
+const (
+	TimeoutPhaseImagePull TimeoutPhase = "image_pull"
+	TimeoutPhaseStart     TimeoutPhase = "start"
+	TimeoutPhaseRun       TimeoutPhase = "run"
+)
+
+// ExecutionErrorCategory classifies why an execution did not complete
+type ExecutionErrorCategory string
+
+const (
+	// ErrorCategoryScript means the script exited with a non-zero code
+	ErrorCategoryScript ExecutionErrorCategory = "script"
+	// ErrorCategoryOutOfMemory means the script was killed for exceeding its memory limit
+	ErrorCategoryOutOfMemory ExecutionErrorCategory = "out_of_memory"
+	ErrorCategoryTimeout     ExecutionErrorCategory = "timeout"
+	ErrorCategoryCancelled   ExecutionErrorCategory = "cancelled"
+	// ErrorCategorySecurity means the task was rejected by security validation
+	ErrorCategorySecurity ExecutionErrorCategory = "security"
+	ErrorCategoryConfig   ExecutionErrorCategory = "config"
+	// ErrorCategoryImage means the container image was missing, not allowed or failed to pull
+	ErrorCategoryImage ExecutionErrorCategory = "image"
+	// ErrorCategoryRuntime means the container runtime or sandbox was unavailable or failed
+	ErrorCategoryRuntime    ExecutionErrorCategory = "runtime"
+	ErrorCategoryResource   ExecutionErrorCategory = "resource"
+	ErrorCategoryNetwork    ExecutionErrorCategory = "network"
+	ErrorCategoryPermission ExecutionErrorCategory = "permission"
+	ErrorCategoryInternal   ExecutionErrorCategory = "internal"
+)
+
+// IsTransient reports whether failures in this category come from the
+// environment rather than the task, so the same task may succeed if retried
+func (c ExecutionErrorCategory) IsTransient() bool {
+	switch c {
+	case ErrorCategoryRuntime, ErrorCategoryResource, ErrorCategoryNetwork, ErrorCategoryInternal:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExecutionOutputChunkBytes is the largest chunk execution output is stored in
+const ExecutionOutputChunkBytes = 64 * 1024
+
+// Ranged reads of execution output return DefaultExecutionOutputLimit bytes
+// unless asked for fewer, up to MaxExecutionOutputLimit
+const (
+	DefaultExecutionOutputLimit = 64 * 1024
+	MaxExecutionOutputLimit     = 1024 * 1024
+)
+
 // TaskExecution represents a task execution in the system
 type TaskExecution struct {
 	ID               uuid.UUID       `json:"id" db:"id"`
 	TaskID           uuid.UUID       `json:"task_id" db:"task_id"`
 	Status           ExecutionStatus `json:"status" db:"status"`
 	ReturnCode       *int            `json:"return_code,omitempty" db:"return_code"`
-	Stdout           *string         `json:"stdout,omitempty" db:"stdout"`
-	Stderr           *string         `json:"stderr,omitempty" db:"stderr"`
 	ExecutionTimeMs  *int            `json:"execution_time_ms,omitempty" db:"execution_time_ms"`
 	MemoryUsageBytes *int64          `json:"memory_usage_bytes,omitempty" db:"memory_usage_bytes"`
 	StartedAt        *time.Time      `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt      *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
 	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+
+	// Stdout and Stderr are stored in chunks apart from the execution and are
+	// only loaded on request. Writing an execution replaces the streams that
+	// are set and keeps the ones that are nil.
+	Stdout *string `json:"stdout,omitempty" db:"-"`
+	Stderr *string `json:"stderr,omitempty" db:"-"`
+
+	// SecurityLevel and Runtime record how the execution was isolated, for audit
+	SecurityLevel TaskSecurityLevel `json:"security_level" db:"security_level"`
+	Runtime       *string           `json:"runtime,omitempty" db:"runtime"`
+
+	// Truncated reports that stdout or stderr exceeded the output limits and
+	// only their start and end were kept
+	Truncated bool `json:"truncated" db:"truncated"`
+
+	// Exit diagnostics: whether the script was OOM-killed, the signal that
+	// terminated it, the phase a timeout hit and why the execution failed
+	OOMKilled     bool                    `json:"oom_killed" db:"oom_killed"`
+	ExitSignal    *string                 `json:"exit_signal,omitempty" db:"exit_signal"`
+	TimeoutPhase  *TimeoutPhase           `json:"timeout_phase,omitempty" db:"timeout_phase"`
+	ErrorCategory *ExecutionErrorCategory `json:"error_category,omitempty" db:"error_category"`
+
+	// Revision is the task revision the execution ran. It is nil for
+	// executions created before task revisions were recorded.
+	Revision *int `json:"revision,omitempty" db:"revision"`
+
+	// Version is incremented by every write to the execution. Updates only
+	// apply to the version they were read at, and it is the execution's ETag.
+	Version int `json:"version" db:"version"`
+
+	// OutputExpiredAt is when retention dropped the execution's output,
+	// keeping the rest of it
+	OutputExpiredAt *time.Time `json:"output_expired_at,omitempty" db:"output_expired_at"`
 }
 
 // CreateTaskExecutionRequest represents the request to create a new task execution
@@ -53,17 +137,42 @@ type UpdateTaskExecutionRequest struct {
 
 // TaskExecutionResponse represents the task execution response
 type TaskExecutionResponse struct {
-	ID               uuid.UUID       `json:"id"`
-	TaskID           uuid.UUID       `json:"task_id"`
-	Status           ExecutionStatus `json:"status"`
-	ReturnCode       *int            `json:"return_code,omitempty"`
-	Stdout           *string         `json:"stdout,omitempty"`
-	Stderr           *string         `json:"stderr,omitempty"`
-	ExecutionTimeMs  *int            `json:"execution_time_ms,omitempty"`
-	MemoryUsageBytes *int64          `json:"memory_usage_bytes,omitempty"`
-	StartedAt        *string         `json:"started_at,omitempty"`
-	CompletedAt      *string         `json:"completed_at,omitempty"`
-	CreatedAt        string          `json:"created_at"`
+	ID               uuid.UUID         `json:"id"`
+	TaskID           uuid.UUID         `json:"task_id"`
+	Status           ExecutionStatus   `json:"status"`
+	ReturnCode       *int              `json:"return_code,omitempty"`
+	Stdout           *string           `json:"stdout,omitempty"`
+	Stderr           *string           `json:"stderr,omitempty"`
+	ExecutionTimeMs  *int              `json:"execution_time_ms,omitempty"`
+	MemoryUsageBytes *int64            `json:"memory_usage_bytes,omitempty"`
+	StartedAt        *string           `json:"started_at,omitempty"`
+	CompletedAt      *string           `json:"completed_at,omitempty"`
+	CreatedAt        string            `json:"created_at"`
+	SecurityLevel    TaskSecurityLevel `json:"security_level"`
+	Runtime          *string           `json:"runtime,omitempty"`
+	Truncated        bool              `json:"truncated"`
+
+	OOMKilled     bool                    `json:"oom_killed"`
+	ExitSignal    *string                 `json:"exit_signal,omitempty"`
+	TimeoutPhase  *TimeoutPhase           `json:"timeout_phase,omitempty"`
+	ErrorCategory *ExecutionErrorCategory `json:"error_category,omitempty"`
+
+	Revision *int `json:"revision,omitempty"`
+
+	Version int `json:"version"`
+
+	OutputExpiredAt *string `json:"output_expired_at,omitempty"`
+}
+
+// ExecutionOutputResponse is a range of a stream of the output of an
+// execution. Offsets are in bytes; the next range starts at NextOffset.
+type ExecutionOutputResponse struct {
+	Stream     string `json:"stream"`
+	Offset     int64  `json:"offset"`
+	NextOffset int64  `json:"next_offset"`
+	Size       int64  `json:"size"`
+	Data       string `json:"data"`
+	Complete   bool   `json:"complete"`
 }
 
 // ToResponse converts TaskExecution to TaskExecutionResponse
@@ -78,6 +187,15 @@ func (te *TaskExecution) ToResponse() TaskExecutionResponse {
 		ExecutionTimeMs:  te.ExecutionTimeMs,
 		MemoryUsageBytes: te.MemoryUsageBytes,
 		CreatedAt:        te.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		SecurityLevel:    te.SecurityLevel,
+		Runtime:          te.Runtime,
+		Truncated:        te.Truncated,
+		OOMKilled:        te.OOMKilled,
+		ExitSignal:       te.ExitSignal,
+		TimeoutPhase:     te.TimeoutPhase,
+		ErrorCategory:    te.ErrorCategory,
+		Revision:         te.Revision,
+		Version:          te.Version,
 	}
 
 	if te.StartedAt != nil {
@@ -90,6 +208,11 @@ func (te *TaskExecution) ToResponse() TaskExecutionResponse {
 		response.CompletedAt = &completedAtStr
 	}
 
+	if te.OutputExpiredAt != nil {
+		outputExpiredAtStr := te.OutputExpiredAt.Format("2006-01-02T15:04:05Z07:00")
+		response.OutputExpiredAt = &outputExpiredAtStr
+	}
+
 	return response
 }
 
@@ -122,6 +245,23 @@ func (te *TaskExecution) IsPending() bool {
 	return te.Status == ExecutionStatusPending
 }
 
+// IsRetryable reports whether a failed execution may succeed if the task is
+// run again unchanged. Timeouts while pulling the image or starting the
+// container and failures of the environment are retryable; script errors,
+// OOM kills and timeouts while the script was running are not.
+func (te *TaskExecution) IsRetryable() bool {
+	if te.Status != ExecutionStatusFailed && te.Status != ExecutionStatusTimeout {
+		return false
+	}
+	if te.OOMKilled {
+		return false
+	}
+	if te.TimeoutPhase != nil {
+		return *te.TimeoutPhase != TimeoutPhaseRun
+	}
+	return te.ErrorCategory != nil && te.ErrorCategory.IsTransient()
+}
+
 // GetDuration returns the execution duration in milliseconds
 func (te *TaskExecution) GetDuration() *int {
 	if te.StartedAt != nil && te.CompletedAt != nil {
@@ -139,6 +279,14 @@ type ExecutionListResponse struct {
 	Offset     int                     `json:"offset"`
 }
 
+// ExecutionSearchResponse represents a page of a filtered execution listing
+type ExecutionSearchResponse struct {
+	Executions []TaskExecutionResponse `json:"executions"`
+	Pagination CursorPagination        `json:"pagination"`
+	Limit      int                     `json:"limit"`
+	SortOrder  string                  `json:"sort_order"`
+}
+
 // State transition definitions for execution status
 var executionStatusTransitions = map[ExecutionStatus][]ExecutionStatus{
 	ExecutionStatusPending: {
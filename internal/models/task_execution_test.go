@@ -170,6 +170,64 @@ func TestTaskExecution_IsPending(t *testing.T) {
 	}
 }
 
+func TestTaskExecution_IsRetryable(t *testing.T) {
+	phase := func(p TimeoutPhase) *TimeoutPhase { return &p }
+	category := func(c ExecutionErrorCategory) *ExecutionErrorCategory { return &c }
+
+	tests := []struct {
+		name      string
+		execution TaskExecution
+		expected  bool
+	}{
+		{
+			name:      "completed is not retryable",
+			execution: TaskExecution{Status: ExecutionStatusCompleted},
+			expected:  false,
+		},
+		{
+			name:      "cancelled is not retryable",
+			execution: TaskExecution{Status: ExecutionStatusCancelled, ErrorCategory: category(ErrorCategoryCancelled)},
+			expected:  false,
+		},
+		{
+			name:      "script error is not retryable",
+			execution: TaskExecution{Status: ExecutionStatusFailed, ErrorCategory: category(ErrorCategoryScript)},
+			expected:  false,
+		},
+		{
+			name:      "OOM kill is not retryable",
+			execution: TaskExecution{Status: ExecutionStatusFailed, OOMKilled: true, ErrorCategory: category(ErrorCategoryOutOfMemory)},
+			expected:  false,
+		},
+		{
+			name:      "timeout while running is not retryable",
+			execution: TaskExecution{Status: ExecutionStatusTimeout, TimeoutPhase: phase(TimeoutPhaseRun), ErrorCategory: category(ErrorCategoryTimeout)},
+			expected:  false,
+		},
+		{
+			name:      "timeout while pulling the image is retryable",
+			execution: TaskExecution{Status: ExecutionStatusTimeout, TimeoutPhase: phase(TimeoutPhaseImagePull), ErrorCategory: category(ErrorCategoryTimeout)},
+			expected:  true,
+		},
+		{
+			name:      "runtime failure is retryable",
+			execution: TaskExecution{Status: ExecutionStatusFailed, ErrorCategory: category(ErrorCategoryRuntime)},
+			expected:  true,
+		},
+		{
+			name:      "failure without a category is not retryable",
+			execution: TaskExecution{Status: ExecutionStatusFailed},
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.execution.IsRetryable())
+		})
+	}
+}
+
 func TestTaskExecution_GetDuration(t *testing.T) {
 	t.Run("with started and completed times", func(t *testing.T) {
 		startTime := time.Now()
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// TaskManifestVersion is the version of the task manifest format
+	TaskManifestVersion = 1
+
+	// MaxManifestTasks is the maximum number of tasks a manifest can declare
+	MaxManifestTasks = 500
+)
+
+// externalKeyPattern matches valid task external keys. Slashes allow keys
+// to follow the layout of the repository the manifests are kept in.
+var externalKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,254}$`)
+
+// TaskManifest declares the desired state of a user's tasks
+type TaskManifest struct {
+	Version int                 `json:"version,omitempty" yaml:"version,omitempty"`
+	Tasks   []TaskManifestEntry `json:"tasks" yaml:"tasks"`
+}
+
+// TaskManifestEntry declares a single task. Fields that are left out take
+// their default value, just like when the task is created through the API.
+type TaskManifestEntry struct {
+	// Key identifies the task across imports; it is stored as the task's
+	// external key
+	Key string `json:"key" yaml:"key"`
+
+	Name        string  `json:"name" yaml:"name"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Script holds the script inline. ScriptFile instead refers to a file
+	// relative to the manifest, which must be read into Script before the
+	// manifest is imported.
+	Script     string     `json:"script,omitempty" yaml:"script,omitempty"`
+	ScriptFile string     `json:"script_file,omitempty" yaml:"script_file,omitempty"`
+	ScriptType ScriptType `json:"script_type" yaml:"script_type"`
+
+	Priority       *int  `json:"priority,omitempty" yaml:"priority,omitempty"`
+	TimeoutSeconds *int  `json:"timeout_seconds,omitempty" yaml:"timeout_seconds,omitempty"`
+	Metadata       JSONB `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	RequiredCapabilities []string          `json:"required_capabilities,omitempty" yaml:"required_capabilities,omitempty"`
+	SecurityLevel        TaskSecurityLevel `json:"security_level,omitempty" yaml:"security_level,omitempty"`
+	Image                *string           `json:"image,omitempty" yaml:"image,omitempty"`
+
+	NetworkMode      TaskNetworkMode `json:"network_mode,omitempty" yaml:"network_mode,omitempty"`
+	NetworkAllowlist []string        `json:"network_allowlist,omitempty" yaml:"network_allowlist,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// TaskManifestAction is what importing a manifest does to a task
+type TaskManifestAction string
+
+const (
+	TaskManifestActionCreate    TaskManifestAction = "create"
+	TaskManifestActionUpdate    TaskManifestAction = "update"
+	TaskManifestActionDelete    TaskManifestAction = "delete"
+	TaskManifestActionUnchanged TaskManifestAction = "unchanged"
+)
+
+// TaskImportMode selects whether an import only reports its changes or applies them
+type TaskImportMode string
+
+const (
+	// TaskImportModeDryRun reports the action for each task
+	TaskImportModeDryRun TaskImportMode = "dry-run"
+
+	// TaskImportModeDiff also reports the fields that change
+	TaskImportModeDiff TaskImportMode = "diff"
+
+	// TaskImportModeApply applies the changes
+	TaskImportModeApply TaskImportMode = "apply"
+)
+
+// TaskManifestFieldChange describes a field that differs between a task and its manifest entry
+type TaskManifestFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+
+	// Diff is a unified diff of the script, set instead of From and To
+	Diff string `json:"diff,omitempty"`
+}
+
+// TaskManifestChange describes what importing a manifest does to a task
+type TaskManifestChange struct {
+	Action TaskManifestAction `json:"action"`
+	Key    string             `json:"key"`
+	Name   string             `json:"name"`
+
+	// TaskID is the existing task, or the created one once applied
+	TaskID *uuid.UUID `json:"task_id,omitempty"`
+
+	// Fields lists the changed fields of an update, in diff mode only
+	Fields []TaskManifestFieldChange `json:"fields,omitempty"`
+
+	// Error tells why the change can't be applied
+	Error string `json:"error,omitempty"`
+
+	// Current is the existing task and Desired the task as the manifest
+	// declares it. Only the fields of Desired the manifest covers are set;
+	// the others are copied from Current.
+	Current *Task `json:"-"`
+	Desired *Task `json:"-"`
+}
+
+// TaskImportSummary counts the changes of an import by action
+type TaskImportSummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// TaskImportResponse represents the result of importing a task manifest
+type TaskImportResponse struct {
+	Mode    TaskImportMode       `json:"mode"`
+	Applied bool                 `json:"applied"`
+	Summary TaskImportSummary    `json:"summary"`
+	Changes []TaskManifestChange `json:"changes"`
+
+	// Error is set when the manifest was rejected; the changes that can't
+	// be applied carry their own error
+	Error string `json:"error,omitempty"`
+}
+
+// ValidateExternalKey validates a task external key
+func ValidateExternalKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if !externalKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid key %q: must start with a letter or digit and contain only letters, digits, '.', '_', '-' and '/'", key)
+	}
+	return nil
+}
+
+// ValidateTaskImportMode validates an import mode
+func ValidateTaskImportMode(mode TaskImportMode) error {
+	switch mode {
+	case TaskImportModeDryRun, TaskImportModeDiff, TaskImportModeApply:
+		return nil
+	default:
+		return fmt.Errorf("invalid import mode: %s (must be dry-run, diff or apply)", mode)
+	}
+}
+
+// Validate validates the manifest and each of its entries
+func (m *TaskManifest) Validate() error {
+	if m.Version != 0 && m.Version != TaskManifestVersion {
+		return fmt.Errorf("unsupported manifest version: %d", m.Version)
+	}
+	if len(m.Tasks) > MaxManifestTasks {
+		return fmt.Errorf("too many tasks: %d (maximum %d)", len(m.Tasks), MaxManifestTasks)
+	}
+
+	keys := make(map[string]bool, len(m.Tasks))
+	for i := range m.Tasks {
+		entry := &m.Tasks[i]
+		if err := entry.Validate(); err != nil {
+			if entry.Key != "" {
+				return fmt.Errorf("task %s: %w", entry.Key, err)
+			}
+			return fmt.Errorf("task %d: %w", i+1, err)
+		}
+		if keys[entry.Key] {
+			return fmt.Errorf("duplicate task key: %s", entry.Key)
+		}
+		keys[entry.Key] = true
+	}
+
+	return nil
+}
+
+// Validate validates the manifest entry. Script file references must have
+// been resolved.
+func (e *TaskManifestEntry) Validate() error {
+	if err := ValidateExternalKey(e.Key); err != nil {
+		return err
+	}
+	if err := ValidateTaskName(e.Name); err != nil {
+		return err
+	}
+	if e.Description != nil && len(*e.Description) > 1000 {
+		return fmt.Errorf("description too long (max 1000 characters)")
+	}
+
+	if e.ScriptFile != "" {
+		if e.Script != "" {
+			return fmt.Errorf("script and script_file are mutually exclusive")
+		}
+		return fmt.Errorf("script_file %s must be resolved before the manifest is imported", e.ScriptFile)
+	}
+	if err := ValidateScriptType(e.ScriptType); err != nil {
+		return err
+	}
+	if err := ValidateScriptContent(e.Script); err != nil {
+		return err
+	}
+
+	if e.Priority != nil {
+		if err := ValidatePriority(*e.Priority); err != nil {
+			return err
+		}
+	}
+	if e.TimeoutSeconds != nil {
+		if err := ValidateTimeout(*e.TimeoutSeconds); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateCapabilities(e.RequiredCapabilities); err != nil {
+		return err
+	}
+	if e.SecurityLevel != "" {
+		if err := ValidateSecurityLevel(e.SecurityLevel); err != nil {
+			return err
+		}
+	}
+	if e.Image != nil && len(*e.Image) > 512 {
+		return fmt.Errorf("image reference too long (max 512 characters)")
+	}
+
+	networkMode := e.NetworkMode
+	if networkMode == "" {
+		networkMode = NetworkModeNone
+	}
+	if err := ValidateNetworkPolicy(networkMode, e.NetworkAllowlist); err != nil {
+		return err
+	}
+
+	return ValidateLabels(e.Labels)
+}
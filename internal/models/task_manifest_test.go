@@ -0,0 +1,124 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskManifest_Validate(t *testing.T) {
+	valid := func() TaskManifestEntry {
+		return TaskManifestEntry{Key: "reports/nightly", Name: "Nightly report", Script: "print(1)", ScriptType: ScriptTypePython}
+	}
+
+	tests := []struct {
+		name     string
+		manifest func() TaskManifest
+		errMsg   string
+	}{
+		{
+			name:     "valid manifest",
+			manifest: func() TaskManifest { return TaskManifest{Tasks: []TaskManifestEntry{valid()}} },
+		},
+		{
+			name:     "empty manifest",
+			manifest: func() TaskManifest { return TaskManifest{} },
+		},
+		{
+			name:     "unsupported version",
+			manifest: func() TaskManifest { return TaskManifest{Version: 2} },
+			errMsg:   "unsupported manifest version: 2",
+		},
+		{
+			name: "missing key",
+			manifest: func() TaskManifest {
+				entry := valid()
+				entry.Key = ""
+				return TaskManifest{Tasks: []TaskManifestEntry{entry}}
+			},
+			errMsg: "task 1: key is required",
+		},
+		{
+			name: "invalid key",
+			manifest: func() TaskManifest {
+				entry := valid()
+				entry.Key = "/absolute"
+				return TaskManifest{Tasks: []TaskManifestEntry{entry}}
+			},
+			errMsg: "invalid key",
+		},
+		{
+			name: "duplicate key",
+			manifest: func() TaskManifest {
+				return TaskManifest{Tasks: []TaskManifestEntry{valid(), valid()}}
+			},
+			errMsg: "duplicate task key: reports/nightly",
+		},
+		{
+			name: "unresolved script file",
+			manifest: func() TaskManifest {
+				entry := valid()
+				entry.Script = ""
+				entry.ScriptFile = "nightly.py"
+				return TaskManifest{Tasks: []TaskManifestEntry{entry}}
+			},
+			errMsg: "script_file nightly.py must be resolved",
+		},
+		{
+			name: "script and script file",
+			manifest: func() TaskManifest {
+				entry := valid()
+				entry.ScriptFile = "nightly.py"
+				return TaskManifest{Tasks: []TaskManifestEntry{entry}}
+			},
+			errMsg: "mutually exclusive",
+		},
+		{
+			name: "invalid priority",
+			manifest: func() TaskManifest {
+				entry := valid()
+				priority := 11
+				entry.Priority = &priority
+				return TaskManifest{Tasks: []TaskManifestEntry{entry}}
+			},
+			errMsg: "task reports/nightly: priority must be between 0 and 10",
+		},
+		{
+			name: "allowlist without allowlist mode",
+			manifest: func() TaskManifest {
+				entry := valid()
+				entry.NetworkAllowlist = []string{"example.com"}
+				return TaskManifest{Tasks: []TaskManifestEntry{entry}}
+			},
+			errMsg: "allowlist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := tt.manifest()
+			err := manifest.Validate()
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("too many tasks", func(t *testing.T) {
+		manifest := TaskManifest{Tasks: make([]TaskManifestEntry, MaxManifestTasks+1)}
+		err := manifest.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many tasks")
+	})
+}
+
+func TestValidateTaskImportMode(t *testing.T) {
+	for _, mode := range []TaskImportMode{TaskImportModeDryRun, TaskImportModeDiff, TaskImportModeApply} {
+		assert.NoError(t, ValidateTaskImportMode(mode))
+	}
+	assert.Error(t, ValidateTaskImportMode("force"))
+}
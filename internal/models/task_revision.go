@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// TaskRevision is an immutable snapshot of a task's script. A new revision is
+// recorded every time the script content or type changes.
+type TaskRevision struct {
+	TaskID        uuid.UUID  `json:"task_id" db:"task_id"`
+	Revision      int        `json:"revision" db:"revision"`
+	ScriptContent string     `json:"script_content" db:"script_content"`
+	ScriptType    ScriptType `json:"script_type" db:"script_type"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TaskRevisionResponse represents the task revision response
+type TaskRevisionResponse struct {
+	TaskID        uuid.UUID  `json:"task_id"`
+	Revision      int        `json:"revision"`
+	ScriptContent string     `json:"script_content"`
+	ScriptType    ScriptType `json:"script_type"`
+	CreatedAt     string     `json:"created_at"`
+}
+
+// ToResponse converts TaskRevision to TaskRevisionResponse
+func (r *TaskRevision) ToResponse() TaskRevisionResponse {
+	return TaskRevisionResponse{
+		TaskID:        r.TaskID,
+		Revision:      r.Revision,
+		ScriptContent: r.ScriptContent,
+		ScriptType:    r.ScriptType,
+		CreatedAt:     r.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// TaskRevisionListResponse represents the response for listing task revisions
+type TaskRevisionListResponse struct {
+	Revisions []TaskRevisionResponse `json:"revisions"`
+	Total     int64                  `json:"total"`
+	Limit     int                    `json:"limit"`
+	Offset    int                    `json:"offset"`
+}
+
+// TaskRevisionDiffResponse represents the differences between two task revisions
+type TaskRevisionDiffResponse struct {
+	TaskID            uuid.UUID `json:"task_id"`
+	From              int       `json:"from"`
+	To                int       `json:"to"`
+	ScriptTypeChanged bool      `json:"script_type_changed"`
+
+	// Diff is a unified diff of the script content, empty when it is unchanged
+	Diff string `json:"diff"`
+}
+
+// DiffTaskRevisions returns a unified diff of the script content of two revisions
+func DiffTaskRevisions(from, to *TaskRevision) (TaskRevisionDiffResponse, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.ScriptContent),
+		B:        difflib.SplitLines(to.ScriptContent),
+		FromFile: fmt.Sprintf("revision %d", from.Revision),
+		ToFile:   fmt.Sprintf("revision %d", to.Revision),
+		Context:  3,
+	})
+	if err != nil {
+		return TaskRevisionDiffResponse{}, fmt.Errorf("failed to diff revisions: %w", err)
+	}
+
+	return TaskRevisionDiffResponse{
+		TaskID:            to.TaskID,
+		From:              from.Revision,
+		To:                to.Revision,
+		ScriptTypeChanged: from.ScriptType != to.ScriptType,
+		Diff:              diff,
+	}, nil
+}
+
+// AtRevision returns a copy of the task that runs the script of the given
+// revision instead of its current script
+func (t *Task) AtRevision(revision *TaskRevision) *Task {
+	task := *t
+	task.Revision = revision.Revision
+	task.ScriptContent = revision.ScriptContent
+	task.ScriptType = revision.ScriptType
+	return &task
+}
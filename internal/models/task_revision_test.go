@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTaskRevisions(t *testing.T) {
+	taskID := uuid.New()
+	from := &TaskRevision{
+		TaskID:        taskID,
+		Revision:      1,
+		ScriptContent: "echo one\necho common\n",
+		ScriptType:    ScriptTypeBash,
+	}
+	to := &TaskRevision{
+		TaskID:        taskID,
+		Revision:      2,
+		ScriptContent: "echo two\necho common\n",
+		ScriptType:    ScriptTypeBash,
+	}
+
+	diff, err := DiffTaskRevisions(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, taskID, diff.TaskID)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 2, diff.To)
+	assert.False(t, diff.ScriptTypeChanged)
+	assert.Contains(t, diff.Diff, "--- revision 1")
+	assert.Contains(t, diff.Diff, "+++ revision 2")
+	assert.Contains(t, diff.Diff, "-echo one")
+	assert.Contains(t, diff.Diff, "+echo two")
+	assert.Contains(t, diff.Diff, " echo common")
+
+	t.Run("identical scripts", func(t *testing.T) {
+		diff, err := DiffTaskRevisions(from, from)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Diff)
+	})
+
+	t.Run("script type change", func(t *testing.T) {
+		python := *to
+		python.ScriptType = ScriptTypePython
+		diff, err := DiffTaskRevisions(from, &python)
+		require.NoError(t, err)
+		assert.True(t, diff.ScriptTypeChanged)
+	})
+}
+
+func TestTask_AtRevision(t *testing.T) {
+	task := &Task{
+		BaseModel:     BaseModel{ID: uuid.New()},
+		Name:          "Test Task",
+		ScriptContent: "echo current",
+		ScriptType:    ScriptTypeBash,
+		Revision:      5,
+	}
+	revision := &TaskRevision{
+		TaskID:        task.ID,
+		Revision:      2,
+		ScriptContent: "print('old')",
+		ScriptType:    ScriptTypePython,
+	}
+
+	old := task.AtRevision(revision)
+
+	assert.Equal(t, task.ID, old.ID)
+	assert.Equal(t, task.Name, old.Name)
+	assert.Equal(t, 2, old.Revision)
+	assert.Equal(t, "print('old')", old.ScriptContent)
+	assert.Equal(t, ScriptTypePython, old.ScriptType)
+
+	assert.Equal(t, 5, task.Revision)
+	assert.Equal(t, "echo current", task.ScriptContent)
+}
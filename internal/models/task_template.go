@@ -0,0 +1,404 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+)
+
+// TemplateVisibility represents who can see and use a task template
+type TemplateVisibility string
+
+const (
+	// TemplateVisibilityPrivate makes the template visible to its owner only
+	TemplateVisibilityPrivate TemplateVisibility = "private"
+
+	// TemplateVisibilityTeam makes the template visible to the members of its team
+	TemplateVisibilityTeam TemplateVisibility = "team"
+
+	// TemplateVisibilityGlobal makes the template visible to every user
+	TemplateVisibilityGlobal TemplateVisibility = "global"
+)
+
+// TemplateParameterType represents the type of a template parameter's value
+type TemplateParameterType string
+
+const (
+	TemplateParameterTypeString  TemplateParameterType = "string"
+	TemplateParameterTypeInteger TemplateParameterType = "integer"
+	TemplateParameterTypeNumber  TemplateParameterType = "number"
+	TemplateParameterTypeBoolean TemplateParameterType = "boolean"
+)
+
+// MaxTemplateParameters is the maximum number of parameters a template may declare
+const MaxTemplateParameters = 32
+
+// templateParameterNamePattern matches parameter names usable as {{.name}}
+var templateParameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// TemplateParameter describes a value substituted into a template's script
+type TemplateParameter struct {
+	Name        string                `json:"name" yaml:"name"`
+	Description string                `json:"description,omitempty" yaml:"description"`
+	Type        TemplateParameterType `json:"type" yaml:"type"`
+	Required    bool                  `json:"required,omitempty" yaml:"required"`
+
+	// Default is used when a task is created without a value for the
+	// parameter. Optional parameters without a default are the zero value of
+	// their type.
+	Default any `json:"default,omitempty" yaml:"default"`
+}
+
+// TaskTemplate is a parameterized task that tasks can be created from
+type TaskTemplate struct {
+	BaseModel
+
+	// OwnerID is the user who created the template. Templates imported from
+	// the template directory have no owner and can't be changed through the API.
+	OwnerID *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description,omitempty" db:"description"`
+	ScriptType  ScriptType `json:"script_type" db:"script_type"`
+
+	// ScriptTemplate is the script body, with text/template placeholders such
+	// as {{.name}} for the parameters
+	ScriptTemplate string              `json:"script_template" db:"script_template"`
+	Parameters     []TemplateParameter `json:"parameters" db:"parameters"`
+
+	// Visibility and Team decide who can use the template; the team only
+	// applies to the team visibility
+	Visibility TemplateVisibility `json:"visibility" db:"visibility"`
+	Team       *string            `json:"team,omitempty" db:"team"`
+
+	// Defaults of the tasks created from the template
+	Priority             int               `json:"priority" db:"priority"`
+	TimeoutSeconds       int               `json:"timeout_seconds" db:"timeout_seconds"`
+	SecurityLevel        TaskSecurityLevel `json:"security_level" db:"security_level"`
+	RequiredCapabilities []string          `json:"required_capabilities,omitempty" db:"required_capabilities"`
+}
+
+// CreateTaskTemplateRequest represents the request to create a new task template
+type CreateTaskTemplateRequest struct {
+	Name           string              `json:"name" validate:"required,task_name,min=1,max=255"`
+	Description    *string             `json:"description,omitempty" validate:"omitempty,max=1000"`
+	ScriptType     ScriptType          `json:"script_type" validate:"required,script_type"`
+	ScriptTemplate string              `json:"script_template" validate:"required,script_content,min=1,max=65535"`
+	Parameters     []TemplateParameter `json:"parameters,omitempty" validate:"omitempty,max=32"`
+
+	Visibility *TemplateVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=private team global"`
+	Team       *string             `json:"team,omitempty" validate:"omitempty,min=1,max=255"`
+
+	Priority             *int               `json:"priority,omitempty" validate:"omitempty,min=0,max=10"`
+	TimeoutSeconds       *int               `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=3600"`
+	SecurityLevel        *TaskSecurityLevel `json:"security_level,omitempty" validate:"omitempty,security_level"`
+	RequiredCapabilities []string           `json:"required_capabilities,omitempty" validate:"omitempty,max=16,dive,capability"`
+}
+
+// UpdateTaskTemplateRequest represents the request to update a task template
+type UpdateTaskTemplateRequest struct {
+	Name           *string             `json:"name,omitempty" validate:"omitempty,task_name,min=1,max=255"`
+	Description    *string             `json:"description,omitempty" validate:"omitempty,max=1000"`
+	ScriptType     *ScriptType         `json:"script_type,omitempty" validate:"omitempty,script_type"`
+	ScriptTemplate *string             `json:"script_template,omitempty" validate:"omitempty,script_content,min=1,max=65535"`
+	Parameters     []TemplateParameter `json:"parameters,omitempty" validate:"omitempty,max=32"`
+
+	Visibility *TemplateVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=private team global"`
+	Team       *string             `json:"team,omitempty" validate:"omitempty,min=1,max=255"`
+
+	Priority             *int               `json:"priority,omitempty" validate:"omitempty,min=0,max=10"`
+	TimeoutSeconds       *int               `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=3600"`
+	SecurityLevel        *TaskSecurityLevel `json:"security_level,omitempty" validate:"omitempty,security_level"`
+	RequiredCapabilities []string           `json:"required_capabilities,omitempty" validate:"omitempty,max=16,dive,capability"`
+}
+
+// CreateTaskFromTemplateRequest represents the request to create a task from a template
+type CreateTaskFromTemplateRequest struct {
+	Name        string  `json:"name" validate:"required,task_name,min=1,max=255"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+
+	// Parameters are the values substituted into the template's script
+	Parameters map[string]any `json:"parameters,omitempty"`
+
+	// Overrides of the template's defaults
+	Priority       *int  `json:"priority,omitempty" validate:"omitempty,min=0,max=10"`
+	TimeoutSeconds *int  `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=3600"`
+	Metadata       JSONB `json:"metadata,omitempty"`
+}
+
+// TaskTemplateResponse represents the task template response
+type TaskTemplateResponse struct {
+	ID             uuid.UUID           `json:"id"`
+	OwnerID        *uuid.UUID          `json:"owner_id,omitempty"`
+	Name           string              `json:"name"`
+	Description    *string             `json:"description,omitempty"`
+	ScriptType     ScriptType          `json:"script_type"`
+	ScriptTemplate string              `json:"script_template"`
+	Parameters     []TemplateParameter `json:"parameters"`
+	Visibility     TemplateVisibility  `json:"visibility"`
+	Team           *string             `json:"team,omitempty"`
+
+	Priority             int               `json:"priority"`
+	TimeoutSeconds       int               `json:"timeout_seconds"`
+	SecurityLevel        TaskSecurityLevel `json:"security_level"`
+	RequiredCapabilities []string          `json:"required_capabilities,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// TaskTemplateListResponse represents a paginated list of task templates
+type TaskTemplateListResponse struct {
+	Templates []TaskTemplateResponse `json:"templates"`
+	Total     int64                  `json:"total"`
+	Limit     int                    `json:"limit"`
+	Offset    int                    `json:"offset"`
+}
+
+// ToResponse converts TaskTemplate to TaskTemplateResponse
+func (t *TaskTemplate) ToResponse() TaskTemplateResponse {
+	parameters := t.Parameters
+	if parameters == nil {
+		parameters = []TemplateParameter{}
+	}
+
+	return TaskTemplateResponse{
+		ID:             t.ID,
+		OwnerID:        t.OwnerID,
+		Name:           t.Name,
+		Description:    t.Description,
+		ScriptType:     t.ScriptType,
+		ScriptTemplate: t.ScriptTemplate,
+		Parameters:     parameters,
+		Visibility:     t.Visibility,
+		Team:           t.Team,
+
+		Priority:             t.Priority,
+		TimeoutSeconds:       t.TimeoutSeconds,
+		SecurityLevel:        t.SecurityLevel,
+		RequiredCapabilities: t.RequiredCapabilities,
+
+		CreatedAt: t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// ValidateTemplateVisibility validates a template visibility together with
+// its team. Only the team visibility takes a team, and it requires one.
+func ValidateTemplateVisibility(visibility TemplateVisibility, team *string) error {
+	switch visibility {
+	case TemplateVisibilityPrivate, TemplateVisibilityGlobal:
+		if team != nil {
+			return fmt.Errorf("team requires visibility %s", TemplateVisibilityTeam)
+		}
+	case TemplateVisibilityTeam:
+		if team == nil || strings.TrimSpace(*team) == "" {
+			return fmt.Errorf("visibility %s requires a team", TemplateVisibilityTeam)
+		}
+	default:
+		return fmt.Errorf("invalid template visibility: %s", visibility)
+	}
+	return nil
+}
+
+// ValidateTemplateParameters validates a template's parameter declarations
+func ValidateTemplateParameters(parameters []TemplateParameter) error {
+	if len(parameters) > MaxTemplateParameters {
+		return fmt.Errorf("too many parameters (max %d)", MaxTemplateParameters)
+	}
+
+	seen := make(map[string]bool, len(parameters))
+	for _, parameter := range parameters {
+		if !templateParameterNamePattern.MatchString(parameter.Name) {
+			return fmt.Errorf("invalid parameter name: %q", parameter.Name)
+		}
+		if seen[parameter.Name] {
+			return fmt.Errorf("duplicate parameter: %s", parameter.Name)
+		}
+		seen[parameter.Name] = true
+
+		switch parameter.Type {
+		case TemplateParameterTypeString, TemplateParameterTypeInteger, TemplateParameterTypeNumber, TemplateParameterTypeBoolean:
+		default:
+			return fmt.Errorf("parameter %s has invalid type: %s", parameter.Name, parameter.Type)
+		}
+
+		if parameter.Default != nil {
+			if _, err := parameter.coerce(parameter.Default); err != nil {
+				return fmt.Errorf("default of %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks that the template's script and parameters are well-formed.
+// The script is rendered with the parameters' defaults, so placeholders
+// naming undeclared parameters are reported.
+func (t *TaskTemplate) Validate() error {
+	if err := ValidateTaskName(t.Name); err != nil {
+		return err
+	}
+	if err := ValidateScriptType(t.ScriptType); err != nil {
+		return err
+	}
+	if err := ValidateScriptContent(t.ScriptTemplate); err != nil {
+		return err
+	}
+	if err := ValidatePriority(t.Priority); err != nil {
+		return err
+	}
+	if err := ValidateTimeout(t.TimeoutSeconds); err != nil {
+		return err
+	}
+	if err := ValidateSecurityLevel(t.SecurityLevel); err != nil {
+		return err
+	}
+	if err := ValidateCapabilities(t.RequiredCapabilities); err != nil {
+		return err
+	}
+	if err := ValidateTemplateVisibility(t.Visibility, t.Team); err != nil {
+		return err
+	}
+	if err := ValidateTemplateParameters(t.Parameters); err != nil {
+		return err
+	}
+
+	values := make(map[string]any, len(t.Parameters))
+	for _, parameter := range t.Parameters {
+		if parameter.Default == nil {
+			values[parameter.Name] = parameter.zero()
+		}
+	}
+	if _, err := t.Render(values); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Render substitutes the parameter values into the template's script.
+// Omitted parameters take their default; unknown parameters, missing
+// required parameters and values of the wrong type are rejected.
+//
+// Besides the text/template builtins, scripts can use the shellquote function
+// to quote a value as a single shell word and the json function to write it
+// as a JSON literal, which is also a valid Python, JavaScript and Go literal
+// for strings and numbers.
+func (t *TaskTemplate) Render(values map[string]any) (string, error) {
+	declared := make(map[string]bool, len(t.Parameters))
+	data := make(map[string]any, len(t.Parameters))
+	for _, parameter := range t.Parameters {
+		declared[parameter.Name] = true
+
+		value, ok := values[parameter.Name]
+		if !ok || value == nil {
+			switch {
+			case parameter.Default != nil:
+				value = parameter.Default
+			case parameter.Required:
+				return "", fmt.Errorf("parameter %s is required", parameter.Name)
+			default:
+				value = parameter.zero()
+			}
+		}
+
+		coerced, err := parameter.coerce(value)
+		if err != nil {
+			return "", err
+		}
+		data[parameter.Name] = coerced
+	}
+
+	for name := range values {
+		if !declared[name] {
+			return "", fmt.Errorf("unknown parameter: %s", name)
+		}
+	}
+
+	tmpl, err := template.New(t.Name).
+		Funcs(templateFuncs).
+		Option("missingkey=error").
+		Parse(t.ScriptTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid script template: %w", err)
+	}
+
+	var script bytes.Buffer
+	if err := tmpl.Execute(&script, data); err != nil {
+		return "", fmt.Errorf("failed to render script template: %w", err)
+	}
+	return script.String(), nil
+}
+
+// templateFuncs are the functions available to script templates
+var templateFuncs = template.FuncMap{
+	"shellquote": func(value any) string {
+		return "'" + strings.ReplaceAll(fmt.Sprint(value), "'", `'\''`) + "'"
+	},
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// coerce converts a parameter value decoded from JSON or YAML to the
+// parameter's type
+func (p TemplateParameter) coerce(value any) (any, error) {
+	switch p.Type {
+	case TemplateParameterTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case TemplateParameterTypeInteger:
+		switch v := value.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case uint64:
+			if v <= math.MaxInt64 {
+				return int64(v), nil
+			}
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+				return int64(v), nil
+			}
+		}
+	case TemplateParameterTypeNumber:
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case TemplateParameterTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("parameter %s must be of type %s", p.Name, p.Type)
+}
+
+// zero returns the zero value of the parameter's type
+func (p TemplateParameter) zero() any {
+	switch p.Type {
+	case TemplateParameterTypeInteger:
+		return int64(0)
+	case TemplateParameterTypeNumber:
+		return float64(0)
+	case TemplateParameterTypeBoolean:
+		return false
+	default:
+		return ""
+	}
+}
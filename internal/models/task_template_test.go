@@ -0,0 +1,192 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTaskTemplate(script string, parameters ...TemplateParameter) *TaskTemplate {
+	return &TaskTemplate{
+		Name:           "Test Template",
+		ScriptType:     ScriptTypeBash,
+		ScriptTemplate: script,
+		Parameters:     parameters,
+		Visibility:     TemplateVisibilityPrivate,
+		Priority:       5,
+		TimeoutSeconds: 30,
+		SecurityLevel:  SecurityLevelStandard,
+	}
+}
+
+func TestTaskTemplate_Render(t *testing.T) {
+	template := testTaskTemplate(
+		"echo {{shellquote .greeting}} {{.count}} {{.ratio}} {{if .loud}}LOUD{{end}}",
+		TemplateParameter{Name: "greeting", Type: TemplateParameterTypeString, Required: true},
+		TemplateParameter{Name: "count", Type: TemplateParameterTypeInteger, Default: 3},
+		TemplateParameter{Name: "ratio", Type: TemplateParameterTypeNumber},
+		TemplateParameter{Name: "loud", Type: TemplateParameterTypeBoolean},
+	)
+
+	tests := []struct {
+		name     string
+		values   map[string]any
+		expected string
+		errMsg   string
+	}{
+		{
+			name:     "defaults and zero values",
+			values:   map[string]any{"greeting": "hi"},
+			expected: "echo 'hi' 3 0 ",
+		},
+		{
+			name:     "values decoded from JSON",
+			values:   map[string]any{"greeting": "it's", "count": float64(7), "ratio": 0.5, "loud": true},
+			expected: `echo 'it'\''s' 7 0.5 LOUD`,
+		},
+		{
+			name:   "missing required parameter",
+			values: map[string]any{},
+			errMsg: "parameter greeting is required",
+		},
+		{
+			name:   "unknown parameter",
+			values: map[string]any{"greeting": "hi", "name": "x"},
+			errMsg: "unknown parameter: name",
+		},
+		{
+			name:   "wrong type",
+			values: map[string]any{"greeting": "hi", "count": "seven"},
+			errMsg: "parameter count must be of type integer",
+		},
+		{
+			name:   "fractional integer",
+			values: map[string]any{"greeting": "hi", "count": 1.5},
+			errMsg: "parameter count must be of type integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := template.Render(tt.values)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, script)
+		})
+	}
+
+	t.Run("json quotes code literals", func(t *testing.T) {
+		template := testTaskTemplate("url = {{json .url}}",
+			TemplateParameter{Name: "url", Type: TemplateParameterTypeString, Required: true})
+
+		script, err := template.Render(map[string]any{"url": `http://example.com/"quoted"`})
+		require.NoError(t, err)
+		assert.Equal(t, `url = "http://example.com/\"quoted\""`, script)
+	})
+}
+
+func TestTaskTemplate_Validate(t *testing.T) {
+	team := "platform"
+
+	tests := []struct {
+		name     string
+		template *TaskTemplate
+		errMsg   string
+	}{
+		{
+			name: "valid template",
+			template: testTaskTemplate("echo {{.name}}",
+				TemplateParameter{Name: "name", Type: TemplateParameterTypeString, Required: true}),
+		},
+		{
+			name:     "undeclared parameter",
+			template: testTaskTemplate("echo {{.name}}"),
+			errMsg:   "failed to render script template",
+		},
+		{
+			name:     "syntax error",
+			template: testTaskTemplate("echo {{.name"),
+			errMsg:   "invalid script template",
+		},
+		{
+			name: "invalid parameter name",
+			template: testTaskTemplate("echo",
+				TemplateParameter{Name: "my-name", Type: TemplateParameterTypeString}),
+			errMsg: "invalid parameter name",
+		},
+		{
+			name: "duplicate parameter",
+			template: testTaskTemplate("echo",
+				TemplateParameter{Name: "a", Type: TemplateParameterTypeString},
+				TemplateParameter{Name: "a", Type: TemplateParameterTypeString}),
+			errMsg: "duplicate parameter: a",
+		},
+		{
+			name: "invalid parameter type",
+			template: testTaskTemplate("echo",
+				TemplateParameter{Name: "a", Type: "list"}),
+			errMsg: "parameter a has invalid type: list",
+		},
+		{
+			name: "default of the wrong type",
+			template: testTaskTemplate("echo",
+				TemplateParameter{Name: "a", Type: TemplateParameterTypeBoolean, Default: "yes"}),
+			errMsg: "default of parameter a must be of type boolean",
+		},
+		{
+			name: "team visibility without team",
+			template: func() *TaskTemplate {
+				template := testTaskTemplate("echo")
+				template.Visibility = TemplateVisibilityTeam
+				return template
+			}(),
+			errMsg: "visibility team requires a team",
+		},
+		{
+			name: "team on private template",
+			template: func() *TaskTemplate {
+				template := testTaskTemplate("echo")
+				template.Team = &team
+				return template
+			}(),
+			errMsg: "team requires visibility team",
+		},
+		{
+			name: "invalid timeout",
+			template: func() *TaskTemplate {
+				template := testTaskTemplate("echo")
+				template.TimeoutSeconds = 0
+				return template
+			}(),
+			errMsg: "timeout must be greater than 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.template.Validate()
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("too many parameters", func(t *testing.T) {
+		parameters := make([]TemplateParameter, MaxTemplateParameters+1)
+		for i := range parameters {
+			parameters[i] = TemplateParameter{Name: "p" + strings.Repeat("x", i), Type: TemplateParameterTypeString}
+		}
+		err := testTaskTemplate("echo", parameters...).Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many parameters")
+	})
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -129,10 +131,9 @@ func TestValidateScriptContent(t *testing.T) {
 			errMsg:  "script content is too long",
 		},
 		{
-			name:    "dangerous content",
+			name:    "dangerous content is left to script analysis",
 			content: "rm -rf /",
-			wantErr: true,
-			errMsg:  "potentially dangerous script content detected",
+			wantErr: false,
 		},
 	}
 
@@ -301,6 +302,111 @@ func TestValidateTimeout(t *testing.T) {
 	}
 }
 
+func TestValidateCapabilities(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities []string
+		wantErr      bool
+	}{
+		{
+			name:         "no capabilities",
+			capabilities: nil,
+			wantErr:      false,
+		},
+		{
+			name:         "simple and keyed capabilities",
+			capabilities: []string{"gpu", "script:python", "memory:large", "image-set:ml_v2.1"},
+			wantErr:      false,
+		},
+		{
+			name:         "mixed case is normalized",
+			capabilities: []string{" Script:Python "},
+			wantErr:      false,
+		},
+		{
+			name:         "invalid characters",
+			capabilities: []string{"script python"},
+			wantErr:      true,
+		},
+		{
+			name:         "route separator is not allowed",
+			capabilities: []string{"gpu+memory:large"},
+			wantErr:      true,
+		},
+		{
+			name:         "empty value",
+			capabilities: []string{"memory:"},
+			wantErr:      true,
+		},
+		{
+			name:         "too long",
+			capabilities: []string{strings.Repeat("a", 65)},
+			wantErr:      true,
+		},
+		{
+			name:         "too many",
+			capabilities: make([]string, MaxTaskCapabilities+1),
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCapabilities(tt.capabilities)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	assert.NoError(t, ValidateLabels(nil))
+	assert.NoError(t, ValidateLabels(map[string]string{"env": "prod", "example.com/team": "data", "deprecated": ""}))
+
+	err := ValidateLabels(map[string]string{"env": "prod", "bad key": "x"})
+	assert.ErrorContains(t, err, `label key "bad key"`)
+
+	err = ValidateLabels(map[string]string{"env": "prod/eu"})
+	assert.ErrorContains(t, err, `label value "prod/eu"`)
+
+	tooMany := make(map[string]string, MaxTaskLabels+1)
+	for i := 0; i <= MaxTaskLabels; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "x"
+	}
+	assert.ErrorContains(t, ValidateLabels(tooMany), "too many labels")
+}
+
+func TestValidateSecurityLevel(t *testing.T) {
+	for _, level := range []TaskSecurityLevel{SecurityLevelStandard, SecurityLevelSandboxed, SecurityLevelIsolated} {
+		assert.NoError(t, ValidateSecurityLevel(level))
+	}
+
+	assert.Error(t, ValidateSecurityLevel(""))
+	assert.Error(t, ValidateSecurityLevel("paranoid"))
+}
+
+func TestNormalizeCapabilities(t *testing.T) {
+	assert.Nil(t, NormalizeCapabilities(nil))
+	assert.Nil(t, NormalizeCapabilities([]string{" ", ""}))
+	assert.Equal(t,
+		[]string{"gpu", "memory:large", "script:python"},
+		NormalizeCapabilities([]string{"Script:Python", "gpu", "memory:large", "GPU "}),
+	)
+}
+
+func TestHasCapabilities(t *testing.T) {
+	offered := []string{"script:python", "memory:large", "gpu"}
+
+	assert.True(t, HasCapabilities(offered, nil))
+	assert.True(t, HasCapabilities(offered, []string{"script:python"}))
+	assert.True(t, HasCapabilities(offered, []string{"GPU", "memory:large"}))
+	assert.False(t, HasCapabilities(offered, []string{"script:bash"}))
+	assert.False(t, HasCapabilities(nil, []string{"gpu"}))
+}
+
 func TestTask_ToResponse(t *testing.T) {
 	task := &Task{
 		BaseModel: BaseModel{
@@ -313,6 +419,7 @@ func TestTask_ToResponse(t *testing.T) {
 		Status:         TaskStatusPending,
 		Priority:       1,
 		TimeoutSeconds: 30,
+		Labels:         map[string]string{"env": "prod"},
 	}
 
 	response := task.ToResponse()
@@ -325,6 +432,24 @@ func TestTask_ToResponse(t *testing.T) {
 	assert.Equal(t, task.Status, response.Status)
 	assert.Equal(t, task.Priority, response.Priority)
 	assert.Equal(t, task.TimeoutSeconds, response.TimeoutSeconds)
+	assert.Equal(t, task.Labels, response.Labels)
 	assert.NotEmpty(t, response.CreatedAt)
 	assert.NotEmpty(t, response.UpdatedAt)
 }
+
+func TestTask_PinnedImage(t *testing.T) {
+	task := &Task{}
+	assert.Empty(t, task.PinnedImage())
+
+	image := "python:3.12"
+	task.Image = &image
+	assert.Empty(t, task.PinnedImage(), "unpinned image")
+
+	digest := "sha256:abcd"
+	task.ImageDigest = &digest
+	assert.Equal(t, "python:3.12@sha256:abcd", task.PinnedImage())
+
+	// An image named by digest is not pinned twice
+	image = "python@sha256:abcd"
+	assert.Equal(t, "python@sha256:abcd", task.PinnedImage())
+}
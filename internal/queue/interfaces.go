@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,20 +24,38 @@ type TaskMessage struct {
 	NextRetryAt   *time.Time `json:"next_retry_at,omitempty"`
 	FailureReason *string    `json:"failure_reason,omitempty"`
 
+	// Routing requirements; workers only receive messages whose capabilities
+	// are a subset of the capabilities they advertise
+	Capabilities []string `json:"capabilities,omitempty"`
+
 	// Message metadata
 	MessageID     string            `json:"message_id"`
 	ReceiptHandle *string           `json:"receipt_handle,omitempty"`
 	Attributes    map[string]string `json:"attributes,omitempty"`
 }
 
+// Revision returns the task revision the message asks to run, or nil to run
+// the task's current revision
+func (m *TaskMessage) Revision() *int {
+	revision, err := strconv.Atoi(m.Attributes["revision"])
+	if err != nil || revision <= 0 {
+		return nil
+	}
+	return &revision
+}
+
 // TaskQueue defines the interface for task queue operations
 type TaskQueue interface {
 	// Enqueue adds a task to the queue with priority
 	Enqueue(ctx context.Context, message *TaskMessage) error
 
-	// Dequeue retrieves tasks from the queue
+	// Dequeue retrieves tasks that do not require any capabilities
 	Dequeue(ctx context.Context, maxMessages int) ([]*TaskMessage, error)
 
+	// DequeueMatching retrieves tasks whose required capabilities are all
+	// contained in the given capability set
+	DequeueMatching(ctx context.Context, maxMessages int, capabilities []string) ([]*TaskMessage, error)
+
 	// DeleteMessage removes a processed message from the queue
 	DeleteMessage(ctx context.Context, receiptHandle string) error
 
@@ -107,6 +126,9 @@ type QueueStats struct {
 	MessagesInFlight    int64          `json:"messages_in_flight"`
 	MessagesDelayed     int64          `json:"messages_delayed"`
 	OldestMessageAge    *time.Duration `json:"oldest_message_age,omitempty"`
+
+	// Routes holds the number of queued messages per capability route
+	Routes map[string]int64 `json:"routes,omitempty"`
 }
 
 // RetryStats represents statistics for the retry queue
@@ -75,6 +75,26 @@ func TestTaskMessage(t *testing.T) {
 	}
 }
 
+func TestTaskMessage_Revision(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		expected   *int
+	}{
+		{name: "no attributes", attributes: nil, expected: nil},
+		{name: "revision attribute", attributes: map[string]string{"revision": "4"}, expected: intPtrHelper(4)},
+		{name: "invalid revision", attributes: map[string]string{"revision": "latest"}, expected: nil},
+		{name: "non-positive revision", attributes: map[string]string{"revision": "0"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &TaskMessage{Attributes: tt.attributes}
+			assert.Equal(t, tt.expected, msg.Revision())
+		})
+	}
+}
+
 func TestQueueError(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -277,3 +297,7 @@ func timePtrHelper(t time.Time) *time.Time {
 func stringPtrHelper(s string) *string {
 	return &s
 }
+
+func intPtrHelper(i int) *int {
+	return &i
+}
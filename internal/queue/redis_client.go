@@ -247,6 +247,17 @@ func (r *RedisClient) Expire(ctx context.Context, key string, expiration time.Du
 	return nil
 }
 
+// SMembers returns all members of a set
+func (r *RedisClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	result := r.client.SMembers(ctx, key)
+
+	if result.Err() != nil {
+		return nil, NewQueueError("smembers", result.Err(), true)
+	}
+
+	return result.Val(), nil
+}
+
 // ExecuteLuaScript executes a Lua script
 func (r *RedisClient) ExecuteLuaScript(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
 	luaScript := redis.NewScript(script)
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 // RedisTaskQueue implements the TaskQueue interface using Redis sorted sets
@@ -20,6 +22,7 @@ type RedisTaskQueue struct {
 	messagesKey string
 	inFlightKey string
 	statsKey    string
+	routesKey   string
 	closed      bool
 }
 
@@ -45,6 +48,7 @@ func NewRedisTaskQueue(client *RedisClient, cfg *config.QueueConfig, logger *slo
 		messagesKey: FormatQueueKey(cfg.TaskQueueName, "queue"),
 		inFlightKey: FormatQueueKey(cfg.TaskQueueName, "inflight"),
 		statsKey:    FormatStatsKey(cfg.TaskQueueName),
+		routesKey:   FormatQueueKey(cfg.TaskQueueName, "routes"),
 		closed:      false,
 	}
 
@@ -71,6 +75,11 @@ func (q *RedisTaskQueue) Enqueue(ctx context.Context, message *TaskMessage) erro
 		message.QueuedAt = time.Now()
 	}
 
+	// Resolve the capability sub-queue for this message
+	route := FormatRouteKey(message.Capabilities)
+	message.Capabilities = ParseRouteKey(route)
+	queueKey := q.routeQueueKey(route)
+
 	// Calculate priority score for sorted set
 	priorityScore := CalculatePriorityScore(message.Priority, message.QueuedAt)
 
@@ -84,11 +93,16 @@ func (q *RedisTaskQueue) Enqueue(ctx context.Context, message *TaskMessage) erro
 	pipe := q.client.Pipeline()
 
 	// Add message to priority queue (sorted set)
-	pipe.ZAdd(ctx, q.messagesKey, &redis.Z{
+	pipe.ZAdd(ctx, queueKey, &redis.Z{
 		Score:  priorityScore,
 		Member: message.MessageID,
 	})
 
+	// Register the route so workers can discover the sub-queue
+	if route != "" {
+		pipe.SAdd(ctx, q.routesKey, route)
+	}
+
 	// Store message data in hash
 	messageKey := FormatMessageKey(q.queueName, message.MessageID)
 	pipe.HSet(ctx, messageKey,
@@ -96,6 +110,7 @@ func (q *RedisTaskQueue) Enqueue(ctx context.Context, message *TaskMessage) erro
 		"priority", message.Priority,
 		"queued_at", message.QueuedAt.Unix(),
 		"attempts", message.Attempts,
+		"route", route,
 	)
 
 	// Set TTL for message data
@@ -117,13 +132,21 @@ func (q *RedisTaskQueue) Enqueue(ctx context.Context, message *TaskMessage) erro
 		"task_id", message.TaskID,
 		"priority", message.Priority,
 		"priority_score", priorityScore,
+		"route", route,
 	)
 
 	return nil
 }
 
-// Dequeue retrieves tasks from the queue
+// Dequeue retrieves tasks that do not require any capabilities
 func (q *RedisTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*TaskMessage, error) {
+	return q.DequeueMatching(ctx, maxMessages, nil)
+}
+
+// DequeueMatching retrieves tasks whose required capabilities are all contained
+// in the given capability set. Eligible sub-queues are drained in order of their
+// highest-priority message so that priority is respected across routes.
+func (q *RedisTaskQueue) DequeueMatching(ctx context.Context, maxMessages int, capabilities []string) ([]*TaskMessage, error) {
 	if q.closed {
 		return nil, ErrQueueClosed
 	}
@@ -137,6 +160,97 @@ func (q *RedisTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*TaskM
 		maxMessages = q.config.BatchSize
 	}
 
+	queueKeys, err := q.matchingQueueKeys(ctx, capabilities)
+	if err != nil {
+		return nil, NewQueueOperationError("dequeue", q.queueName, "", err, true)
+	}
+
+	messages := make([]*TaskMessage, 0, maxMessages)
+	for _, queueKey := range queueKeys {
+		if len(messages) >= maxMessages {
+			break
+		}
+
+		batch, err := q.dequeueFrom(ctx, queueKey, maxMessages-len(messages))
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, batch...)
+	}
+
+	q.logger.Debug("messages dequeued successfully",
+		"count", len(messages),
+		"requested", maxMessages,
+		"routes", len(queueKeys),
+	)
+
+	return messages, nil
+}
+
+// routeQueueKey returns the sorted set key holding messages for a route
+func (q *RedisTaskQueue) routeQueueKey(route string) string {
+	if route == "" {
+		return q.messagesKey
+	}
+	return FormatQueueKey(q.messagesKey, route)
+}
+
+// matchingQueueKeys returns the sub-queue keys a worker with the given
+// capabilities may consume from, ordered by their highest-priority message
+func (q *RedisTaskQueue) matchingQueueKeys(ctx context.Context, capabilities []string) ([]string, error) {
+	if len(capabilities) == 0 {
+		return []string{q.messagesKey}, nil
+	}
+
+	routes, err := q.client.SMembers(ctx, q.routesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	queueKeys := []string{q.messagesKey}
+	for _, route := range routes {
+		if models.HasCapabilities(capabilities, ParseRouteKey(route)) {
+			queueKeys = append(queueKeys, q.routeQueueKey(route))
+		}
+	}
+
+	if len(queueKeys) == 1 {
+		return queueKeys, nil
+	}
+
+	// Peek at the head of each eligible sub-queue
+	pipe := q.client.Pipeline()
+	heads := make([]*redis.ZSliceCmd, len(queueKeys))
+	for i, queueKey := range queueKeys {
+		heads[i] = pipe.ZRangeWithScores(ctx, queueKey, 0, 0)
+	}
+	if err := q.client.ExecutePipeline(ctx, pipe); err != nil {
+		return nil, err
+	}
+
+	type routeHead struct {
+		key   string
+		score float64
+	}
+	candidates := make([]routeHead, 0, len(queueKeys))
+	for i, head := range heads {
+		if entries := head.Val(); len(entries) > 0 {
+			candidates = append(candidates, routeHead{key: queueKeys[i], score: entries[0].Score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	result := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		result[i] = candidate.key
+	}
+	return result, nil
+}
+
+// dequeueFrom atomically moves up to maxMessages from a single sub-queue to in-flight
+func (q *RedisTaskQueue) dequeueFrom(ctx context.Context, queueKey string, maxMessages int) ([]*TaskMessage, error) {
 	// Generate secure random components for receipt handles (one per potential message)
 	randomComponents := make([]string, maxMessages)
 	for i := 0; i < maxMessages; i++ {
@@ -183,7 +297,7 @@ func (q *RedisTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*TaskM
 	`
 
 	keys := []string{
-		q.messagesKey,                           // KEYS[1]: main queue
+		queueKey,                                // KEYS[1]: route queue
 		q.inFlightKey,                           // KEYS[2]: in-flight queue
 		FormatQueueKey(q.queueName, "messages"), // KEYS[3]: message data prefix
 		q.statsKey,                              // KEYS[4]: stats key
@@ -258,11 +372,6 @@ func (q *RedisTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*TaskM
 		messages = append(messages, message)
 	}
 
-	q.logger.Debug("messages dequeued successfully",
-		"count", len(messages),
-		"requested", maxMessages,
-	)
-
 	return messages, nil
 }
 
@@ -371,10 +480,19 @@ func (q *RedisTaskQueue) GetQueueStats(ctx context.Context) (*QueueStats, error)
 		return nil, ErrQueueClosed
 	}
 
+	routes, err := q.client.SMembers(ctx, q.routesKey)
+	if err != nil {
+		return nil, NewQueueOperationError("stats", q.queueName, "", err, true)
+	}
+
 	// Get counts using pipeline
 	pipe := q.client.Pipeline()
 	mainQueueCount := pipe.ZCard(ctx, q.messagesKey)
 	inFlightCount := pipe.ZCard(ctx, q.inFlightKey)
+	routeCounts := make(map[string]*redis.IntCmd, len(routes))
+	for _, route := range routes {
+		routeCounts[route] = pipe.ZCard(ctx, q.routeQueueKey(route))
+	}
 
 	// Execute pipeline
 	if err := q.client.ExecutePipeline(ctx, pipe); err != nil {
@@ -384,6 +502,19 @@ func (q *RedisTaskQueue) GetQueueStats(ctx context.Context) (*QueueStats, error)
 	mainCount := mainQueueCount.Val()
 	flightCount := inFlightCount.Val()
 
+	var routeStats map[string]int64
+	if len(routeCounts) > 0 {
+		routeStats = make(map[string]int64, len(routeCounts))
+		for route, count := range routeCounts {
+			routeStats[route] = count.Val()
+		}
+	}
+
+	totalCount := mainCount
+	for _, count := range routeStats {
+		totalCount += count
+	}
+
 	// Get oldest message age
 	var oldestAge *time.Duration
 	if mainCount > 0 {
@@ -403,10 +534,11 @@ func (q *RedisTaskQueue) GetQueueStats(ctx context.Context) (*QueueStats, error)
 
 	stats := &QueueStats{
 		Name:                q.queueName,
-		ApproximateMessages: mainCount,
+		ApproximateMessages: totalCount,
 		MessagesInFlight:    flightCount,
 		MessagesDelayed:     0, // Redis doesn't have delayed messages in this implementation
 		OldestMessageAge:    oldestAge,
+		Routes:              routeStats,
 	}
 
 	return stats, nil
@@ -489,7 +621,7 @@ func (q *RedisTaskQueue) processExpiredBatch(ctx context.Context, messageIDs []s
 			-- Check if message still exists
 			if redis.call('EXISTS', messageKey) == 1 then
 				-- Get message data
-				local messageData = redis.call('HMGET', messageKey, 'data', 'priority', 'queued_at')
+				local messageData = redis.call('HMGET', messageKey, 'data', 'priority', 'queued_at', 'route')
 				if messageData[1] and messageData[2] and messageData[3] then
 					-- Calculate priority score
 					local priority = tonumber(messageData[2])
@@ -499,8 +631,12 @@ func (q *RedisTaskQueue) processExpiredBatch(ctx context.Context, messageIDs []s
 					-- Remove from in-flight
 					redis.call('ZREM', KEYS[2], messageId)
 					
-					-- Add back to main queue
-					redis.call('ZADD', KEYS[1], priorityScore, messageId)
+					-- Add back to the queue for the message's capability route
+					local queueKey = KEYS[1]
+					if messageData[4] and messageData[4] ~= '' then
+						queueKey = KEYS[1] .. ':' .. messageData[4]
+					end
+					redis.call('ZADD', queueKey, priorityScore, messageId)
 					
 					-- Clear receipt handle
 					redis.call('HDEL', messageKey, 'receipt_handle', 'dequeued_at')
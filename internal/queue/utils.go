@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
 )
 
 // GenerateMessageID generates a unique message ID
@@ -85,6 +87,10 @@ func ValidateTaskMessage(message *TaskMessage) error {
 		return NewValidationError("attempts", message.Attempts, "cannot be negative")
 	}
 
+	if err := models.ValidateCapabilities(message.Capabilities); err != nil {
+		return NewValidationError("capabilities", message.Capabilities, err.Error())
+	}
+
 	return nil
 }
 
@@ -217,6 +223,7 @@ func CreateRetryMessage(original *TaskMessage) *TaskMessage {
 		Attempts:      original.Attempts + 1,
 		LastAttempt:   timePtr(time.Now()),
 		FailureReason: original.FailureReason,
+		Capabilities:  append([]string(nil), original.Capabilities...),
 		MessageID:     GenerateMessageID(), // Generate new message ID for retry
 		Attributes:    copyAttributes(original.Attributes),
 	}
@@ -232,6 +239,21 @@ func FormatQueueKey(queueName, suffix string) string {
 	return fmt.Sprintf("%s:%s", queueName, suffix)
 }
 
+// FormatRouteKey returns the canonical route for a set of required capabilities.
+// Equivalent capability sets always map to the same route; an empty set maps to
+// the empty route, which is the shared default queue.
+func FormatRouteKey(capabilities []string) string {
+	return strings.Join(models.NormalizeCapabilities(capabilities), "+")
+}
+
+// ParseRouteKey returns the capabilities encoded in a route
+func ParseRouteKey(route string) []string {
+	if route == "" {
+		return nil
+	}
+	return strings.Split(route, "+")
+}
+
 // FormatMessageKey formats a Redis key for message storage
 func FormatMessageKey(queueName, messageID string) string {
 	return fmt.Sprintf("%s:messages:%s", queueName, messageID)
@@ -179,6 +179,19 @@ func TestValidateTaskMessage(t *testing.T) {
 			expectError: true,
 			errorMsg:    "validation",
 		},
+		{
+			name: "invalid capabilities",
+			message: &TaskMessage{
+				TaskID:       uuid.New(),
+				UserID:       uuid.New(),
+				Priority:     PriorityNormal,
+				QueuedAt:     time.Now(),
+				Capabilities: []string{"not a capability"},
+				MessageID:    "test-message",
+			},
+			expectError: true,
+			errorMsg:    "capabilities",
+		},
 		{
 			name:        "nil message",
 			message:     nil,
@@ -331,6 +344,43 @@ func TestFormatQueueKey(t *testing.T) {
 	}
 }
 
+func TestFormatRouteKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities []string
+		expected     string
+	}{
+		{
+			name:         "no capabilities uses default route",
+			capabilities: nil,
+			expected:     "",
+		},
+		{
+			name:         "single capability",
+			capabilities: []string{"script:python"},
+			expected:     "script:python",
+		},
+		{
+			name:         "equivalent sets share a route",
+			capabilities: []string{"memory:large", "Script:Python", "memory:large"},
+			expected:     "memory:large+script:python",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := FormatRouteKey(tt.capabilities)
+			assert.Equal(t, tt.expected, route)
+			assert.Equal(t, FormatRouteKey(ParseRouteKey(route)), route)
+		})
+	}
+}
+
+func TestParseRouteKey(t *testing.T) {
+	assert.Nil(t, ParseRouteKey(""))
+	assert.Equal(t, []string{"gpu", "script:python"}, ParseRouteKey("gpu+script:python"))
+}
+
 func TestFormatStatsKey(t *testing.T) {
 	tests := []struct {
 		name     string
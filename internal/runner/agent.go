@@ -0,0 +1,248 @@
+// Package runner implements the remote runner agent, which executes tasks on
+// hosts without database or queue access by pulling jobs from the API.
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// claimRetryDelay is how long the agent backs off after a failed claim
+const claimRetryDelay = 5 * time.Second
+
+// AgentConfig configures a runner agent
+type AgentConfig struct {
+	Capabilities      []string
+	PollWait          time.Duration
+	HeartbeatInterval time.Duration
+	ResourceLimits    executor.ResourceLimits
+}
+
+// Agent pulls jobs from the API and executes them one at a time
+type Agent struct {
+	client   *Client
+	executor executor.TaskExecutor
+	config   AgentConfig
+	logger   *slog.Logger
+}
+
+// NewAgent creates a new runner agent
+func NewAgent(client *Client, taskExecutor executor.TaskExecutor, config AgentConfig, logger *slog.Logger) *Agent {
+	config.Capabilities = models.NormalizeCapabilities(config.Capabilities)
+	return &Agent{
+		client:   client,
+		executor: taskExecutor,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Run claims and executes jobs until the context is cancelled
+func (a *Agent) Run(ctx context.Context) error {
+	a.logger.Info("runner agent started", "capabilities", a.config.Capabilities)
+
+	for {
+		if ctx.Err() != nil {
+			a.logger.Info("runner agent stopped")
+			return nil
+		}
+
+		job, err := a.client.ClaimJob(ctx, a.config.Capabilities, a.config.PollWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			a.logger.Error("failed to claim job", "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(claimRetryDelay):
+			}
+			continue
+		}
+
+		if job == nil {
+			continue
+		}
+
+		a.runJob(ctx, job)
+	}
+}
+
+// runJob executes a leased job and reports its output and result
+func (a *Agent) runJob(ctx context.Context, job *models.RunnerJob) {
+	logger := a.logger.With("execution_id", job.ExecutionID, "task_id", job.TaskID)
+	logger.Info("executing job", "script_type", job.ScriptType)
+
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	defer cancelJob()
+
+	// Keep the lease alive while executing; a cancelled job or lost lease
+	// aborts the execution by cancelling its context
+	var aborted bool
+	var abortMu sync.Mutex
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		a.heartbeatLoop(jobCtx, job, logger, func() {
+			abortMu.Lock()
+			aborted = true
+			abortMu.Unlock()
+			cancelJob()
+		})
+	}()
+
+	// Send the output to the API while the job runs
+	streamer := newLogStreamer(a.client, job, logger)
+	go streamer.run(ctx)
+	defer streamer.stop()
+
+	limits := a.config.ResourceLimits
+	limits.TimeoutSeconds = job.TimeoutSeconds
+
+	execCtx := &executor.ExecutionContext{
+		Task: &models.Task{
+			BaseModel:            models.BaseModel{ID: job.TaskID},
+			Name:                 job.Name,
+			ScriptContent:        job.ScriptContent,
+			ScriptType:           job.ScriptType,
+			TimeoutSeconds:       job.TimeoutSeconds,
+			RequiredCapabilities: job.RequiredCapabilities,
+			SecurityLevel:        job.SecurityLevel,
+			Image:                job.Image,
+			ImageDigest:          job.ImageDigest,
+			NetworkMode:          job.NetworkMode,
+			NetworkAllowlist:     job.NetworkAllowlist,
+			Status:               models.TaskStatusRunning,
+		},
+		Execution: &models.TaskExecution{
+			ID:            job.ExecutionID,
+			TaskID:        job.TaskID,
+			Status:        models.ExecutionStatusRunning,
+			SecurityLevel: job.SecurityLevel,
+		},
+		Context:        jobCtx,
+		Timeout:        time.Duration(job.TimeoutSeconds) * time.Second,
+		ResourceLimits: limits,
+		Stdout:         streamer.writer(streamStdout),
+		Stderr:         streamer.writer(streamStderr),
+	}
+
+	result, execErr := a.executor.Execute(jobCtx, execCtx)
+
+	cancelJob()
+	<-heartbeatDone
+
+	abortMu.Lock()
+	wasAborted := aborted
+	abortMu.Unlock()
+
+	if wasAborted {
+		logger.Info("job aborted before completion")
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down: leave the job to be handed out again once the lease lapses
+		logger.Warn("runner stopping, abandoning job")
+		return
+	}
+
+	resultReq := models.RunnerResultRequest{
+		LeaseToken: job.LeaseToken,
+	}
+	if result != nil {
+		resultReq.NetworkEvents = result.NetworkEvents
+		resultReq.OOMKilled = result.OOMKilled
+		resultReq.ExitSignal = result.ExitSignal
+		resultReq.TimeoutPhase = result.TimeoutPhase
+		resultReq.ErrorCategory = result.ErrorCategory
+	}
+	var stdout, stderr string
+	if execErr != nil {
+		logger.Error("job execution failed", "error", execErr)
+		resultReq.Status = models.ExecutionStatusFailed
+		if result != nil && result.Status == models.ExecutionStatusTimeout {
+			resultReq.Status = models.ExecutionStatusTimeout
+		}
+		if resultReq.ErrorCategory == nil {
+			category := executor.CategorizeError(execErr)
+			resultReq.ErrorCategory = &category
+		}
+		streamer.stop()
+		stdout, stderr = streamer.streamed()
+		if stderr != "" && !strings.HasSuffix(stderr, "\n") {
+			stderr += "\n"
+		}
+		stderr += execErr.Error()
+	} else {
+		resultReq.Status = result.Status
+		if models.ValidateRunnerResultStatus(result.Status) != nil {
+			resultReq.Status = models.ExecutionStatusFailed
+		}
+		resultReq.ReturnCode = result.ReturnCode
+		resultReq.ExecutionTimeMs = result.ExecutionTimeMs
+		resultReq.MemoryUsageBytes = result.MemoryUsageBytes
+		resultReq.Runtime = result.Runtime
+		resultReq.Truncated = result.Truncated
+		if result.Stdout != nil {
+			stdout = *result.Stdout
+		}
+		if result.Stderr != nil {
+			stderr = *result.Stderr
+		}
+	}
+
+	replaceStdout, replaceStderr, err := streamer.complete(ctx, stdout, stderr)
+	if err != nil {
+		logger.Warn("lease lost while streaming output", "error", err)
+		return
+	}
+	resultReq.Stdout = replaceStdout
+	resultReq.Stderr = replaceStderr
+
+	if err := a.client.SubmitResult(ctx, job.ExecutionID, resultReq); err != nil {
+		logger.Error("failed to submit job result", "error", err)
+		return
+	}
+
+	logger.Info("job completed", "status", resultReq.Status)
+}
+
+// heartbeatLoop renews the job lease until the context is cancelled, calling
+// abort when the job was cancelled or the lease was lost
+func (a *Agent) heartbeatLoop(ctx context.Context, job *models.RunnerJob, logger *slog.Logger, abort func()) {
+	ticker := time.NewTicker(a.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			response, err := a.client.Heartbeat(ctx, job.ExecutionID, job.LeaseToken)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if IsLeaseLost(err) {
+					logger.Warn("job lease lost", "error", err)
+					abort()
+					return
+				}
+				logger.Warn("failed to send heartbeat", "error", err)
+				continue
+			}
+
+			if response.Cancelled {
+				logger.Info("job cancelled")
+				abort()
+				return
+			}
+		}
+	}
+}
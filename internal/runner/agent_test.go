@@ -0,0 +1,314 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// fakeRunnerAPI serves a single job and records what the agent reports back
+type fakeRunnerAPI struct {
+	mu          sync.Mutex
+	job         *models.RunnerJob
+	cancelled   bool
+	heartbeats  int
+	stdout      strings.Builder
+	stderr      strings.Builder
+	logChunks   []models.RunnerLogRequest
+	failLogs    bool
+	result      *models.RunnerResultRequest
+	resultReady chan struct{}
+}
+
+func newFakeRunnerAPI(job *models.RunnerJob) *fakeRunnerAPI {
+	return &fakeRunnerAPI{job: job, resultReady: make(chan struct{})}
+}
+
+func (f *fakeRunnerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test-token" || r.Header.Get(runnerIDHeader) != "runner-01" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	base := "/api/v1/runner/jobs"
+	switch {
+	case r.URL.Path == base:
+		if f.job == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.job)
+		f.job = nil
+	case strings.HasSuffix(r.URL.Path, "/heartbeat"):
+		f.heartbeats++
+		_ = json.NewEncoder(w).Encode(models.RunnerHeartbeatResponse{Cancelled: f.cancelled})
+	case strings.HasSuffix(r.URL.Path, "/logs"):
+		if f.failLogs {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req models.RunnerLogRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.stdout.WriteString(req.Stdout)
+		f.stderr.WriteString(req.Stderr)
+		f.logChunks = append(f.logChunks, req)
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(r.URL.Path, "/result"):
+		var req models.RunnerResultRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.result = &req
+		close(f.resultReady)
+		_ = json.NewEncoder(w).Encode(models.TaskExecutionResponse{Status: req.Status})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestAgent(serverURL string, taskExecutor executor.TaskExecutor) *Agent {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewClient(serverURL, "test-token", "runner-01", 5*time.Second)
+	return NewAgent(client, taskExecutor, AgentConfig{
+		PollWait:          time.Second,
+		HeartbeatInterval: 10 * time.Millisecond,
+	}, logger)
+}
+
+func TestAgent_RunExecutesJob(t *testing.T) {
+	job := &models.RunnerJob{
+		ExecutionID:    uuid.New(),
+		TaskID:         uuid.New(),
+		LeaseToken:     "lease",
+		Name:           "hello",
+		ScriptContent:  "print('Hello, World!')",
+		ScriptType:     models.ScriptTypePython,
+		TimeoutSeconds: 30,
+	}
+	api := newFakeRunnerAPI(job)
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	agent := newTestAgent(server.URL, executor.NewMockExecutor(nil, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- agent.Run(ctx) }()
+
+	select {
+	case <-api.resultReady:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not submit a result")
+	}
+	cancel()
+	require.NoError(t, <-done)
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	assert.Equal(t, models.ExecutionStatusCompleted, api.result.Status)
+	assert.Equal(t, "lease", api.result.LeaseToken)
+	assert.Nil(t, api.result.Stdout, "output is streamed through log uploads")
+	assert.Equal(t, "Mock Python execution output\n", api.stdout.String())
+	assert.Greater(t, api.heartbeats, 0)
+}
+
+func TestAgent_CancelledJobIsNotReported(t *testing.T) {
+	job := &models.RunnerJob{
+		ExecutionID:    uuid.New(),
+		TaskID:         uuid.New(),
+		LeaseToken:     "lease",
+		ScriptContent:  "sleep 10",
+		ScriptType:     models.ScriptTypeBash,
+		TimeoutSeconds: 30,
+	}
+	api := newFakeRunnerAPI(job)
+	api.cancelled = true
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	agent := newTestAgent(server.URL, &blockingExecutor{})
+	agent.runJob(context.Background(), job)
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	assert.Nil(t, api.result)
+	assert.Equal(t, 1, api.heartbeats)
+}
+
+func TestClient_ClaimJobNoContent(t *testing.T) {
+	server := httptest.NewServer(newFakeRunnerAPI(nil))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "test-token", "runner-01", time.Second)
+	job, err := client.ClaimJob(context.Background(), nil, time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+}
+
+func TestClient_LeaseLost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":"Lease is no longer held by this runner"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token", "runner-01", time.Second)
+	_, err := client.Heartbeat(context.Background(), uuid.New(), "lease")
+	require.Error(t, err)
+	assert.True(t, IsLeaseLost(err))
+	assert.Contains(t, err.Error(), "Lease is no longer held")
+}
+
+func TestAgent_StreamsOutputWhileRunning(t *testing.T) {
+	job := &models.RunnerJob{
+		ExecutionID:    uuid.New(),
+		TaskID:         uuid.New(),
+		LeaseToken:     "lease",
+		ScriptContent:  "echo started; sleep 10",
+		ScriptType:     models.ScriptTypeBash,
+		TimeoutSeconds: 30,
+	}
+	api := newFakeRunnerAPI(job)
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	// The job only finishes once its first output has reached the API
+	streamedOutput := func() string {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		return api.stdout.String()
+	}
+	taskExecutor := &streamingExecutor{
+		before: "started\n",
+		after:  "finished\n",
+		wait: func() bool {
+			return assert.Eventually(t, func() bool { return streamedOutput() == "started\n" }, 5*time.Second, 10*time.Millisecond)
+		},
+	}
+
+	agent := newTestAgent(server.URL, taskExecutor)
+	agent.runJob(context.Background(), job)
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	require.NotNil(t, api.result)
+	assert.Equal(t, models.ExecutionStatusCompleted, api.result.Status)
+	assert.Nil(t, api.result.Stdout, "output is streamed through log uploads")
+	assert.Equal(t, "started\nfinished\n", api.stdout.String())
+}
+
+func TestLogStreamer_Complete(t *testing.T) {
+	job := &models.RunnerJob{ExecutionID: uuid.New(), LeaseToken: "lease"}
+	newStreamer := func(t *testing.T, api *fakeRunnerAPI) *logStreamer {
+		server := httptest.NewServer(api)
+		t.Cleanup(server.Close)
+		client := NewClient(server.URL, "test-token", "runner-01", 5*time.Second)
+		streamer := newLogStreamer(client, job, slog.Default())
+		go streamer.run(context.Background())
+		return streamer
+	}
+
+	t.Run("rest of truncated output follows the streamed start", func(t *testing.T) {
+		api := newFakeRunnerAPI(nil)
+		streamer := newStreamer(t, api)
+
+		_, _ = streamer.writer(streamStdout).Write([]byte("01234"))
+		kept := "01234\n[... output truncated: 90 bytes and 0 lines omitted ...]\n56789"
+		stdout, stderr, err := streamer.complete(context.Background(), kept, "")
+		require.NoError(t, err)
+
+		assert.Nil(t, stdout)
+		assert.Nil(t, stderr)
+		assert.Equal(t, kept, api.stdout.String())
+	})
+
+	t.Run("output is sent in bounded chunks of whole characters", func(t *testing.T) {
+		api := newFakeRunnerAPI(nil)
+		streamer := newStreamer(t, api)
+
+		output := strings.Repeat("aé€", models.MaxRunnerLogChunkBytes/3)
+		_, _ = streamer.writer(streamStdout).Write([]byte(output))
+		_, _ = streamer.writer(streamStderr).Write([]byte(output))
+		_, _, err := streamer.complete(context.Background(), output, output)
+		require.NoError(t, err)
+
+		assert.Equal(t, output, api.stdout.String())
+		assert.Equal(t, output, api.stderr.String())
+		require.Greater(t, len(api.logChunks), 2)
+		for _, chunk := range api.logChunks {
+			assert.LessOrEqual(t, len(chunk.Stdout)+len(chunk.Stderr), models.MaxRunnerLogChunkBytes)
+			assert.True(t, utf8.ValidString(chunk.Stdout))
+			assert.True(t, utf8.ValidString(chunk.Stderr))
+		}
+	})
+
+	t.Run("output that failed to stream is submitted with the result", func(t *testing.T) {
+		api := newFakeRunnerAPI(nil)
+		api.failLogs = true
+		streamer := newStreamer(t, api)
+
+		_, _ = streamer.writer(streamStdout).Write([]byte("hello\n"))
+		stdout, stderr, err := streamer.complete(context.Background(), "hello\n", "")
+		require.NoError(t, err)
+
+		require.NotNil(t, stdout)
+		require.NotNil(t, stderr)
+		assert.Equal(t, "hello\n", *stdout)
+		assert.Empty(t, *stderr)
+	})
+}
+
+// streamingExecutor writes output before and after waiting on wait
+type streamingExecutor struct {
+	blockingExecutor
+	before, after string
+	wait          func() bool
+}
+
+func (s *streamingExecutor) Execute(ctx context.Context, execCtx *executor.ExecutionContext) (*executor.ExecutionResult, error) {
+	_, _ = io.WriteString(execCtx.Stdout, s.before)
+	if !s.wait() {
+		return nil, errors.New("output was not streamed")
+	}
+	_, _ = io.WriteString(execCtx.Stdout, s.after)
+
+	stdout := s.before + s.after
+	return &executor.ExecutionResult{Status: models.ExecutionStatusCompleted, Stdout: &stdout}, nil
+}
+
+// blockingExecutor runs until its context is cancelled
+type blockingExecutor struct{}
+
+func (b *blockingExecutor) Execute(ctx context.Context, execCtx *executor.ExecutionContext) (*executor.ExecutionResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (b *blockingExecutor) Cancel(ctx context.Context, executionID uuid.UUID) error {
+	return nil
+}
+
+func (b *blockingExecutor) IsHealthy(ctx context.Context) error {
+	return nil
+}
+
+func (b *blockingExecutor) Cleanup(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,140 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// runnerIDHeader mirrors middleware.RunnerIDHeader on the API side
+const runnerIDHeader = "X-Runner-ID"
+
+// APIError represents a non-successful response from the runner API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("runner API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsLeaseLost reports whether the error means the runner no longer holds the
+// job, in which case it must stop working on it without submitting a result
+func IsLeaseLost(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusConflict || apiErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// Client talks to the VoidRunner runner job API
+type Client struct {
+	baseURL    string
+	token      string
+	runnerID   string
+	httpClient *http.Client
+}
+
+// NewClient creates a new runner API client. The timeout must exceed the
+// longest poll wait requested from the server.
+func NewClient(baseURL, token, runnerID string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/") + "/api/v1/runner",
+		token:    token,
+		runnerID: runnerID,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// ClaimJob long-polls for the next job. It returns nil when none became available.
+func (c *Client) ClaimJob(ctx context.Context, capabilities []string, wait time.Duration) (*models.RunnerJob, error) {
+	req := models.RunnerJobRequest{
+		Capabilities: capabilities,
+		WaitSeconds:  int(wait / time.Second),
+	}
+
+	var job models.RunnerJob
+	status, err := c.post(ctx, "/jobs", req, &job)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNoContent {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// Heartbeat renews the lease on a job
+func (c *Client) Heartbeat(ctx context.Context, executionID uuid.UUID, leaseToken string) (*models.RunnerHeartbeatResponse, error) {
+	var response models.RunnerHeartbeatResponse
+	if _, err := c.post(ctx, fmt.Sprintf("/jobs/%s/heartbeat", executionID), models.RunnerHeartbeatRequest{LeaseToken: leaseToken}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// AppendLogs uploads a chunk of job output
+func (c *Client) AppendLogs(ctx context.Context, executionID uuid.UUID, req models.RunnerLogRequest) error {
+	_, err := c.post(ctx, fmt.Sprintf("/jobs/%s/logs", executionID), req, nil)
+	return err
+}
+
+// SubmitResult reports the final result of a job
+func (c *Client) SubmitResult(ctx context.Context, executionID uuid.UUID, req models.RunnerResultRequest) error {
+	_, err := c.post(ctx, fmt.Sprintf("/jobs/%s/result", executionID), req, nil)
+	return err
+}
+
+// post sends a JSON request and decodes a JSON response into out when present
+func (c *Client) post(ctx context.Context, path string, body interface{}, out interface{}) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set(runnerIDHeader, c.runnerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("runner API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errorBody struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(data, &errorBody) != nil || errorBody.Error == "" {
+			errorBody.Error = http.StatusText(resp.StatusCode)
+		}
+		return resp.StatusCode, &APIError{StatusCode: resp.StatusCode, Message: errorBody.Error}
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
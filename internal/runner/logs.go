@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// logFlushInterval is how often output written by a job is sent to the API
+const logFlushInterval = time.Second
+
+// Output streams of a job, indexing the streamer's buffers
+const (
+	streamStdout = iota
+	streamStderr
+)
+
+// errOutputDiverged reports that the kept output of a job doesn't continue
+// the output streamed while it ran
+var errOutputDiverged = errors.New("kept output differs from the streamed output")
+
+// logStreamer sends the output of a job to the API while the job runs.
+// Writes only buffer the output, which is sent in the background, so a slow
+// API never holds up the job.
+type logStreamer struct {
+	client *Client
+	job    *models.RunnerJob
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending [2][]byte
+	sent    [2]strings.Builder
+	// err is the first failed upload, after which nothing more is sent
+	err error
+
+	wake     chan struct{}
+	stopped  chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newLogStreamer creates a log streamer for a job. Its output is only sent
+// while run is running.
+func newLogStreamer(client *Client, job *models.RunnerJob, logger *slog.Logger) *logStreamer {
+	return &logStreamer{
+		client:  client,
+		job:     job,
+		logger:  logger,
+		wake:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// writer returns the writer of an output stream
+func (s *logStreamer) writer(stream int) io.Writer {
+	return streamWriter{streamer: s, stream: stream}
+}
+
+// run sends the buffered output every logFlushInterval, or as soon as a
+// full chunk is buffered, until the streamer is stopped
+func (s *logStreamer) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopped:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.send(ctx, false)
+	}
+}
+
+// stop stops sending output in the background, waiting for an upload in
+// flight to finish
+func (s *logStreamer) stop() {
+	s.stopOnce.Do(func() { close(s.stopped) })
+	<-s.done
+}
+
+// streamed returns all the output written so far, sent or not
+func (s *logStreamer) streamed() (stdout, stderr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent[streamStdout].String() + string(s.pending[streamStdout]),
+		s.sent[streamStderr].String() + string(s.pending[streamStderr])
+}
+
+// complete stops streaming and sends the rest of the job's kept output. As
+// the executor only streams the start of a truncated stream, the rest is
+// normally what follows the streamed output. When the output couldn't be
+// streamed, the kept output is returned instead, to be submitted with the
+// result in place of the streamed output. Only a lost lease is an error.
+func (s *logStreamer) complete(ctx context.Context, stdout, stderr string) (replaceStdout, replaceStderr *string, err error) {
+	s.stop()
+	s.send(ctx, true)
+
+	s.mu.Lock()
+	if s.err == nil {
+		sentStdout, sentStderr := s.sent[streamStdout].String(), s.sent[streamStderr].String()
+		if strings.HasPrefix(stdout, sentStdout) && strings.HasPrefix(stderr, sentStderr) {
+			s.pending[streamStdout] = []byte(stdout[len(sentStdout):])
+			s.pending[streamStderr] = []byte(stderr[len(sentStderr):])
+			s.mu.Unlock()
+			s.send(ctx, true)
+			s.mu.Lock()
+		} else {
+			s.logger.Warn("kept output differs from the streamed output, it will be submitted with the result")
+			s.err = errOutputDiverged
+		}
+	}
+	sendErr := s.err
+	s.mu.Unlock()
+
+	if sendErr == nil {
+		return nil, nil, nil
+	}
+	if IsLeaseLost(sendErr) {
+		return nil, nil, sendErr
+	}
+	return &stdout, &stderr, nil
+}
+
+// send uploads the buffered output in chunks of at most
+// models.MaxRunnerLogChunkBytes. Unless final, an incomplete character at the
+// end of a stream is held back until the rest of it is written.
+func (s *logStreamer) send(ctx context.Context, final bool) {
+	for {
+		s.mu.Lock()
+		if s.err != nil {
+			s.mu.Unlock()
+			return
+		}
+		var chunk [2]string
+		budget := models.MaxRunnerLogChunkBytes
+		for stream := range s.pending {
+			p := s.pending[stream]
+			n := min(len(p), budget)
+			if n < len(p) || !final {
+				n = completeRunes(p[:n])
+			}
+			chunk[stream] = string(p[:n])
+			budget -= n
+		}
+		s.mu.Unlock()
+
+		if chunk[streamStdout] == "" && chunk[streamStderr] == "" {
+			return
+		}
+
+		err := s.client.AppendLogs(ctx, s.job.ExecutionID, models.RunnerLogRequest{
+			LeaseToken: s.job.LeaseToken,
+			Stdout:     chunk[streamStdout],
+			Stderr:     chunk[streamStderr],
+		})
+
+		s.mu.Lock()
+		if err != nil {
+			s.logger.Warn("failed to stream job output, it will be submitted with the result", "error", err)
+			s.err = err
+			s.mu.Unlock()
+			return
+		}
+		for stream := range s.pending {
+			s.sent[stream].WriteString(chunk[stream])
+			s.pending[stream] = s.pending[stream][len(chunk[stream]):]
+		}
+		s.mu.Unlock()
+	}
+}
+
+// write buffers output of a stream, waking the sender once a chunk is full
+func (s *logStreamer) write(stream int, p []byte) {
+	s.mu.Lock()
+	s.pending[stream] = append(s.pending[stream], p...)
+	full := len(s.pending[streamStdout])+len(s.pending[streamStderr]) >= models.MaxRunnerLogChunkBytes
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// streamWriter writes to one stream of a log streamer
+type streamWriter struct {
+	streamer *logStreamer
+	stream   int
+}
+
+// Write implements io.Writer. Writes never block on the API.
+func (w streamWriter) Write(p []byte) (int, error) {
+	w.streamer.write(w.stream, p)
+	return len(p), nil
+}
+
+// completeRunes returns the length of the longest prefix of p that doesn't
+// end in an incomplete UTF-8 sequence
+func completeRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
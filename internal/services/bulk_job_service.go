@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// ErrBulkJobServiceStopped is returned for jobs submitted after the service stopped
+var ErrBulkJobServiceStopped = errors.New("bulk job service is stopped")
+
+// BulkJobService performs bulk jobs in the background. Operations are
+// performed one after another, each with its own timeout, and the progress is
+// saved as the job goes so it can be followed through the job resource. A
+// limited number of jobs run at once; the others wait as pending.
+//
+// Each server reports the jobs it performs alive with a periodic heartbeat,
+// and fails the jobs of any server whose heartbeat expired, since their
+// remaining operations are lost with it. Jobs of the other running servers
+// are left alone.
+type BulkJobService struct {
+	jobRepo          database.BulkJobRepository
+	slots            chan struct{}
+	progressInterval time.Duration
+	operationTimeout time.Duration
+	heartbeat        time.Duration
+	heartbeatTimeout time.Duration
+	logger           *slog.Logger
+
+	// IDs of the jobs this server performs
+	mu     sync.Mutex
+	active map[uuid.UUID]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBulkJobService creates a new bulk job service
+func NewBulkJobService(jobRepo database.BulkJobRepository, logger *slog.Logger) *BulkJobService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BulkJobService{
+		jobRepo:          jobRepo,
+		slots:            make(chan struct{}, config.DefaultBulkJobConcurrency),
+		progressInterval: config.DefaultBulkJobProgressInterval,
+		operationTimeout: config.DefaultBulkOperationTimeout,
+		heartbeat:        config.DefaultBulkJobHeartbeat,
+		heartbeatTimeout: config.DefaultBulkJobHeartbeatTimeout,
+		logger:           logger,
+		active:           make(map[uuid.UUID]struct{}),
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+}
+
+// Start fails the jobs stopped servers left unfinished, and starts the
+// heartbeat of the jobs this server performs
+func (s *BulkJobService) Start(ctx context.Context) error {
+	if err := s.failStale(ctx); err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go s.keepAlive()
+	return nil
+}
+
+// keepAlive periodically records the heartbeat of the jobs this server
+// performs and fails the jobs whose heartbeat expired, until the service stops
+func (s *BulkJobService) keepAlive() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(s.ctx, config.DefaultDatabaseTimeout)
+			if err := s.jobRepo.Heartbeat(ctx, s.activeJobs()); err != nil {
+				s.logger.Error("failed to record bulk job heartbeat", "error", err)
+			}
+			if err := s.failStale(ctx); err != nil {
+				s.logger.Error("failed to fail stale bulk jobs", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// failStale fails the unfinished jobs whose heartbeat expired
+func (s *BulkJobService) failStale(ctx context.Context) error {
+	failed, err := s.jobRepo.FailStale(ctx, time.Now().Add(-s.heartbeatTimeout), "interrupted by a server restart")
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		s.logger.Warn("failed bulk jobs left unfinished", "count", failed)
+	}
+	return nil
+}
+
+// activeJobs returns the IDs of the jobs this server performs
+func (s *BulkJobService) activeJobs() []uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]uuid.UUID, 0, len(s.active))
+	for id := range s.active {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Stop stops starting operations and waits for the operations in progress
+// to finish. Jobs that didn't get to perform all their operations fail.
+func (s *BulkJobService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for bulk jobs to stop: %w", ctx.Err())
+	}
+}
+
+// Submit saves the job as pending and performs its operations in the
+// background, calling perform for each. Operations that fail are reported in
+// their result; they don't stop the job. The job is not changed once Submit
+// returns; its progress is read back from the repository.
+func (s *BulkJobService) Submit(ctx context.Context, job *models.BulkJob, operations []models.BulkOperation, perform func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult) error {
+	if s.ctx.Err() != nil {
+		return ErrBulkJobServiceStopped
+	}
+
+	job.Status = models.BulkJobStatusPending
+	job.Total = len(operations)
+	job.Processed, job.Succeeded, job.Failed = 0, 0, 0
+	job.Results = []models.BulkOperationResult{}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return err
+	}
+
+	running := *job
+	running.Results = make([]models.BulkOperationResult, 0, len(operations))
+
+	s.mu.Lock()
+	s.active[job.ID] = struct{}{}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run(&running, operations, perform)
+
+	s.logger.Info("bulk job submitted", "job_id", job.ID, "user_id", job.UserID, "kind", job.Kind, "total", job.Total)
+	return nil
+}
+
+// run waits for a free slot and performs the job's operations
+func (s *BulkJobService) run(job *models.BulkJob, operations []models.BulkOperation, perform func(context.Context, models.BulkOperation) models.BulkOperationResult) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.active, job.ID)
+		s.mu.Unlock()
+	}()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-s.ctx.Done():
+		s.finish(job, "interrupted by server shutdown")
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("bulk job panicked", "job_id", job.ID, "panic", r)
+			s.finish(job, "internal error")
+		}
+	}()
+
+	startedAt := time.Now()
+	job.Status = models.BulkJobStatusRunning
+	job.StartedAt = &startedAt
+	s.save(job)
+
+	lastSaved := time.Now()
+	for i, operation := range operations {
+		if s.ctx.Err() != nil {
+			s.finish(job, "interrupted by server shutdown")
+			return
+		}
+
+		result := s.perform(operation, perform)
+		result.Index = i
+		job.Record(result)
+
+		if time.Since(lastSaved) >= s.progressInterval {
+			s.save(job)
+			lastSaved = time.Now()
+		}
+	}
+
+	s.finish(job, "")
+}
+
+// perform performs a single operation with its own timeout, so that stopping
+// the service lets it finish
+func (s *BulkJobService) perform(operation models.BulkOperation, perform func(context.Context, models.BulkOperation) models.BulkOperationResult) models.BulkOperationResult {
+	ctx, cancel := context.WithTimeout(context.Background(), s.operationTimeout)
+	defer cancel()
+
+	result := perform(ctx, operation)
+	result.Action = operation.Action
+	if result.TaskID == nil {
+		result.TaskID = operation.TaskID
+	}
+	return result
+}
+
+// finish completes the job, or fails it with the reason when one is given
+func (s *BulkJobService) finish(job *models.BulkJob, reason string) {
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+	if reason != "" {
+		job.Status = models.BulkJobStatusFailed
+		job.Error = &reason
+	} else {
+		job.Status = models.BulkJobStatusCompleted
+	}
+	s.save(job)
+
+	s.logger.Info("bulk job finished", "job_id", job.ID, "user_id", job.UserID, "status", job.Status,
+		"processed", job.Processed, "succeeded", job.Succeeded, "failed", job.Failed)
+}
+
+// save saves the job's progress. The job goes on when saving fails; the
+// next save catches up.
+func (s *BulkJobService) save(job *models.BulkJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.DefaultDatabaseTimeout)
+	defer cancel()
+
+	if err := s.jobRepo.UpdateProgress(ctx, job); err != nil {
+		s.logger.Error("failed to save bulk job progress", "error", err, "job_id", job.ID)
+	}
+}
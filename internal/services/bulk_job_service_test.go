@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// fakeBulkJobRepository keeps bulk jobs in memory
+type fakeBulkJobRepository struct {
+	mu         sync.Mutex
+	jobs       map[uuid.UUID]models.BulkJob
+	heartbeats map[uuid.UUID]time.Time
+}
+
+func newFakeBulkJobRepository() *fakeBulkJobRepository {
+	return &fakeBulkJobRepository{jobs: make(map[uuid.UUID]models.BulkJob), heartbeats: make(map[uuid.UUID]time.Time)}
+}
+
+func (r *fakeBulkJobRepository) Create(ctx context.Context, job *models.BulkJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.CreatedAt = time.Now()
+	r.jobs[job.ID] = *job
+	r.heartbeats[job.ID] = job.CreatedAt
+	return nil
+}
+
+func (r *fakeBulkJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BulkJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, database.ErrBulkJobNotFound
+	}
+	job.Results = append([]models.BulkOperationResult(nil), job.Results...)
+	return &job, nil
+}
+
+func (r *fakeBulkJobRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.BulkJob, error) {
+	return nil, nil
+}
+
+func (r *fakeBulkJobRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeBulkJobRepository) UpdateProgress(ctx context.Context, job *models.BulkJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *job
+	saved.Results = append([]models.BulkOperationResult(nil), job.Results...)
+	r.jobs[job.ID] = saved
+	return nil
+}
+
+func (r *fakeBulkJobRepository) Heartbeat(ctx context.Context, ids []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		r.heartbeats[id] = time.Now()
+	}
+	return nil
+}
+
+func (r *fakeBulkJobRepository) FailStale(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var failed int64
+	for id, job := range r.jobs {
+		if !job.Finished() && r.heartbeats[id].Before(heartbeatBefore) {
+			job.Status = models.BulkJobStatusFailed
+			job.Error = &reason
+			r.jobs[id] = job
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// setHeartbeat sets the last heartbeat of a job
+func (r *fakeBulkJobRepository) setHeartbeat(id uuid.UUID, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeats[id] = at
+}
+
+// heartbeat returns the last heartbeat of a job
+func (r *fakeBulkJobRepository) heartbeat(id uuid.UUID) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.heartbeats[id]
+}
+
+func newTestBulkJobService(repo database.BulkJobRepository) *BulkJobService {
+	return NewBulkJobService(repo, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+}
+
+// waitForJob waits until the job has finished and returns it
+func waitForJob(t *testing.T, repo *fakeBulkJobRepository, id uuid.UUID) *models.BulkJob {
+	t.Helper()
+	var job *models.BulkJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = repo.GetByID(context.Background(), id)
+		return err == nil && job.Finished()
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestBulkJobService_Submit(t *testing.T) {
+	repo := newFakeBulkJobRepository()
+	service := newTestBulkJobService(repo)
+	defer func() { _ = service.Stop(context.Background()) }()
+
+	first, second := uuid.New(), uuid.New()
+	executionID := uuid.New()
+	operations := []models.BulkOperation{
+		{Action: models.BulkActionExecute, TaskID: &first},
+		{Action: models.BulkActionExecute, TaskID: &second},
+	}
+
+	perform := func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult {
+		if *operation.TaskID == second {
+			return models.BulkOperationResult{Status: models.BulkOperationStatusFailed, Error: "task is already running"}
+		}
+		return models.BulkOperationResult{Status: models.BulkOperationStatusSucceeded, ExecutionID: &executionID}
+	}
+
+	job := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch}
+	require.NoError(t, service.Submit(context.Background(), job, operations, perform))
+
+	assert.Equal(t, models.BulkJobStatusPending, job.Status)
+	assert.Equal(t, 2, job.Total)
+
+	finished := waitForJob(t, repo, job.ID)
+	assert.Equal(t, models.BulkJobStatusCompleted, finished.Status)
+	assert.Equal(t, 2, finished.Processed)
+	assert.Equal(t, 1, finished.Succeeded)
+	assert.Equal(t, 1, finished.Failed)
+	assert.NotNil(t, finished.StartedAt)
+	assert.NotNil(t, finished.CompletedAt)
+	assert.Nil(t, finished.Error)
+
+	require.Len(t, finished.Results, 2)
+	assert.Equal(t, models.BulkOperationResult{
+		Index: 0, Action: models.BulkActionExecute, Status: models.BulkOperationStatusSucceeded,
+		TaskID: &first, ExecutionID: &executionID,
+	}, finished.Results[0])
+	assert.Equal(t, 1, finished.Results[1].Index)
+	assert.Equal(t, &second, finished.Results[1].TaskID)
+	assert.Equal(t, "task is already running", finished.Results[1].Error)
+}
+
+func TestBulkJobService_Stop(t *testing.T) {
+	repo := newFakeBulkJobRepository()
+	service := newTestBulkJobService(repo)
+
+	taskID := uuid.New()
+	operations := make([]models.BulkOperation, 5)
+	for i := range operations {
+		operations[i] = models.BulkOperation{Action: models.BulkActionDelete, TaskID: &taskID}
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	perform := func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+		return models.BulkOperationResult{Status: models.BulkOperationStatusSucceeded}
+	}
+
+	job := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch}
+	require.NoError(t, service.Submit(context.Background(), job, operations, perform))
+	<-started
+
+	stopped := make(chan error)
+	go func() { stopped <- service.Stop(context.Background()) }()
+
+	// The operation in progress finishes before the service stops
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	require.NoError(t, <-stopped)
+
+	finished := waitForJob(t, repo, job.ID)
+	assert.Equal(t, models.BulkJobStatusFailed, finished.Status)
+	assert.Equal(t, 1, finished.Processed)
+	require.NotNil(t, finished.Error)
+	assert.Equal(t, "interrupted by server shutdown", *finished.Error)
+
+	err := service.Submit(context.Background(), &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch}, operations, perform)
+	assert.ErrorIs(t, err, ErrBulkJobServiceStopped)
+}
+
+func TestBulkJobService_Start(t *testing.T) {
+	repo := newFakeBulkJobRepository()
+	stale := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch, Status: models.BulkJobStatusRunning}
+	require.NoError(t, repo.Create(context.Background(), stale))
+	repo.setHeartbeat(stale.ID, time.Now().Add(-2*time.Minute))
+	other := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch, Status: models.BulkJobStatusRunning}
+	require.NoError(t, repo.Create(context.Background(), other))
+
+	service := newTestBulkJobService(repo)
+	require.NoError(t, service.Start(context.Background()))
+	defer func() { _ = service.Stop(context.Background()) }()
+
+	job, err := repo.GetByID(context.Background(), stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BulkJobStatusFailed, job.Status)
+	require.NotNil(t, job.Error)
+	assert.Equal(t, "interrupted by a server restart", *job.Error)
+
+	job, err = repo.GetByID(context.Background(), other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BulkJobStatusRunning, job.Status, "jobs another server is performing are kept")
+}
+
+func TestBulkJobService_Heartbeat(t *testing.T) {
+	repo := newFakeBulkJobRepository()
+	service := newTestBulkJobService(repo)
+	service.heartbeat = 10 * time.Millisecond
+	service.heartbeatTimeout = 50 * time.Millisecond
+
+	// A job of a server that stopped reporting it alive
+	abandoned := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch, Status: models.BulkJobStatusRunning}
+	require.NoError(t, repo.Create(context.Background(), abandoned))
+
+	require.NoError(t, service.Start(context.Background()))
+
+	release := make(chan struct{})
+	taskID := uuid.New()
+	job := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch}
+	require.NoError(t, service.Submit(context.Background(), job, []models.BulkOperation{{Action: models.BulkActionDelete, TaskID: &taskID}},
+		func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult {
+			<-release
+			return models.BulkOperationResult{Status: models.BulkOperationStatusSucceeded}
+		}))
+	submittedAt := repo.heartbeat(job.ID)
+
+	// The abandoned job expires while this server's job is kept alive
+	finished := waitForJob(t, repo, abandoned.ID)
+	assert.Equal(t, models.BulkJobStatusFailed, finished.Status)
+	assert.True(t, repo.heartbeat(job.ID).After(submittedAt))
+
+	running, err := repo.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.False(t, running.Finished())
+
+	close(release)
+	assert.Equal(t, models.BulkJobStatusCompleted, waitForJob(t, repo, job.ID).Status)
+	require.NoError(t, service.Stop(context.Background()))
+}
+
+func TestBulkJobService_Panic(t *testing.T) {
+	repo := newFakeBulkJobRepository()
+	service := newTestBulkJobService(repo)
+	defer func() { _ = service.Stop(context.Background()) }()
+
+	taskID := uuid.New()
+	job := &models.BulkJob{UserID: uuid.New(), Kind: models.BulkJobKindBatch}
+	err := service.Submit(context.Background(), job, []models.BulkOperation{{Action: models.BulkActionDelete, TaskID: &taskID}},
+		func(ctx context.Context, operation models.BulkOperation) models.BulkOperationResult {
+			panic("boom")
+		})
+	require.NoError(t, err)
+
+	finished := waitForJob(t, repo, job.ID)
+	assert.Equal(t, models.BulkJobStatusFailed, finished.Status)
+	require.NotNil(t, finished.Error)
+	assert.Equal(t, "internal error", *finished.Error)
+}
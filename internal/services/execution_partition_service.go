@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+)
+
+// ExecutionPartitionService creates the monthly partitions of task executions
+// ahead of time, so new executions never land in the default partition. The
+// partitions are checked when the service starts and then periodically, with
+// the partitions locked so that the API servers and schedulers running the
+// service never race. Expired partitions are dropped by the execution
+// retention service.
+type ExecutionPartitionService struct {
+	partitionRepo database.ExecutionPartitionRepository
+	monthsAhead   int
+	interval      time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewExecutionPartitionService creates a new execution partition service
+// keeping the partitions of the current month and monthsAhead months after
+func NewExecutionPartitionService(partitionRepo database.ExecutionPartitionRepository, monthsAhead int, interval time.Duration, logger *slog.Logger) *ExecutionPartitionService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ExecutionPartitionService{
+		partitionRepo: partitionRepo,
+		monthsAhead:   monthsAhead,
+		interval:      interval,
+		logger:        logger,
+		now:           time.Now,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start creates the partitions in the background until the service is stopped
+func (s *ExecutionPartitionService) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop stops creating partitions, waiting for a check in progress to stop
+func (s *ExecutionPartitionService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for the execution partition check to stop: %w", ctx.Err())
+	}
+}
+
+// run creates the missing partitions now and then every interval
+func (s *ExecutionPartitionService) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := s.Ensure(s.ctx)
+		if err != nil && s.ctx.Err() == nil {
+			s.logger.Error("failed to create execution partitions", "error", err)
+		}
+		if len(created) > 0 {
+			s.logger.Info("created execution partitions", "months", created)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// Ensure creates the missing partitions of the current month and the months
+// ahead in a single transaction, returning the months it created, e.g.
+// 2026-01
+func (s *ExecutionPartitionService) Ensure(ctx context.Context) ([]string, error) {
+	now := s.now().UTC()
+
+	var created []string
+	err := s.partitionRepo.WithLock(ctx, func(partitions database.ExecutionPartitionRepository) error {
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i <= s.monthsAhead; i++ {
+			ok, err := partitions.Create(ctx, month)
+			if err != nil {
+				return err
+			}
+			if ok {
+				created = append(created, month.Format("2006-01"))
+			}
+			month = month.AddDate(0, 1, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// MockExecutionPartitionRepository is a mock implementation of ExecutionPartitionRepository
+type MockExecutionPartitionRepository struct {
+	mock.Mock
+}
+
+func (m *MockExecutionPartitionRepository) Create(ctx context.Context, month time.Time) (bool, error) {
+	args := m.Called(ctx, month)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockExecutionPartitionRepository) List(ctx context.Context) ([]database.ExecutionPartition, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ExecutionPartition), args.Error(1)
+}
+
+func (m *MockExecutionPartitionRepository) Count(ctx context.Context, partition database.ExecutionPartition) (database.RetentionCount, error) {
+	args := m.Called(ctx, partition)
+	return args.Get(0).(database.RetentionCount), args.Error(1)
+}
+
+func (m *MockExecutionPartitionRepository) GetExecutions(ctx context.Context, partition database.ExecutionPartition, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*models.TaskExecution, error) {
+	args := m.Called(ctx, partition, afterCreatedAt, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Error(1)
+}
+
+func (m *MockExecutionPartitionRepository) Drop(ctx context.Context, partition database.ExecutionPartition) ([]uuid.UUID, error) {
+	args := m.Called(ctx, partition)
+	executionIDs, _ := args.Get(0).([]uuid.UUID)
+	return executionIDs, args.Error(1)
+}
+
+func (m *MockExecutionPartitionRepository) WithLock(ctx context.Context, fn func(partitions database.ExecutionPartitionRepository) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func TestExecutionPartitionService_Ensure(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 11, 20, 3, 4, 5, 0, time.UTC)
+
+	t.Run("creates the missing partitions of the current month and ahead", func(t *testing.T) {
+		repo := new(MockExecutionPartitionRepository)
+		service := NewExecutionPartitionService(repo, 2, time.Hour, logger)
+		service.now = func() time.Time { return now }
+
+		repo.On("WithLock", mock.Anything).Return(nil).Once()
+		repo.On("Create", mock.Anything, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)).Return(false, nil).Once()
+		repo.On("Create", mock.Anything, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)).Return(true, nil).Once()
+		repo.On("Create", mock.Anything, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)).Return(true, nil).Once()
+
+		created, err := service.Ensure(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2026-12", "2027-01"}, created)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		repo := new(MockExecutionPartitionRepository)
+		service := NewExecutionPartitionService(repo, 2, time.Hour, logger)
+		service.now = func() time.Time { return now }
+
+		repo.On("WithLock", mock.Anything).Return(nil).Once()
+		repo.On("Create", mock.Anything, mock.Anything).Return(false, errors.New("permission denied")).Once()
+
+		_, err := service.Ensure(context.Background())
+		assert.Error(t, err)
+		repo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("creates nothing without the lock", func(t *testing.T) {
+		repo := new(MockExecutionPartitionRepository)
+		service := NewExecutionPartitionService(repo, 2, time.Hour, logger)
+		service.now = func() time.Time { return now }
+
+		repo.On("WithLock", mock.Anything).Return(errors.New("canceling statement due to statement timeout")).Once()
+
+		_, err := service.Ensure(context.Background())
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestExecutionPartitionService_StartStop(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := new(MockExecutionPartitionRepository)
+	service := NewExecutionPartitionService(repo, 0, time.Hour, logger)
+
+	created := make(chan struct{}, 1)
+	repo.On("WithLock", mock.Anything).Return(nil)
+	repo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { created <- struct{}{} }).
+		Return(true, nil).Once()
+
+	service.Start()
+
+	select {
+	case <-created:
+	case <-time.After(5 * time.Second):
+		t.Fatal("partitions were not created on start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/archive"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// Reasons executions are archived for
+const (
+	ArchiveReasonDeleted       = "deleted"
+	ArchiveReasonExpired       = "expired"
+	ArchiveReasonOutputExpired = "output_expired"
+)
+
+// ExecutionRetentionPolicy holds the retention rules of executions. Zero
+// disables a rule.
+type ExecutionRetentionPolicy struct {
+	// KeepLast is the number of executions kept per task; older finished
+	// executions are deleted
+	KeepLast int
+	// MaxAge is how long executions are kept; the monthly partitions of
+	// executions that all are older are dropped, so executions are kept for
+	// up to a month longer
+	MaxAge time.Duration
+	// OutputMaxAge is how long the output of finished executions is kept;
+	// older executions keep their summary without stdout and stderr
+	OutputMaxAge time.Duration
+}
+
+// ExecutionRetentionReport reports what a retention run did, or would do
+// for a dry run. A dry run counts the executions of each rule separately,
+// so executions several rules apply to are counted more than once.
+type ExecutionRetentionReport struct {
+	DryRun            bool     `json:"dry_run"`
+	Deleted           int64    `json:"deleted"`
+	PartitionsDropped []string `json:"partitions_dropped,omitempty"`
+	OutputsExpired    int64    `json:"outputs_expired"`
+	OutputBytes       int64    `json:"output_bytes"`
+	Archives          []string `json:"archives,omitempty"`
+}
+
+// archivedExecution is the archive record of an execution
+type archivedExecution struct {
+	*models.TaskExecution
+	ArchiveReason string    `json:"archive_reason"`
+	ArchivedAt    time.Time `json:"archived_at"`
+}
+
+// ExecutionRetentionService applies the retention policy of executions.
+// Executions are archived, when an archive store is configured, before they
+// are deleted or their output is dropped, and a batch is only deleted once
+// its archive is written. The full output spilled for truncated executions
+// is removed with them.
+type ExecutionRetentionService struct {
+	executionRepo database.TaskExecutionRepository
+	partitionRepo database.ExecutionPartitionRepository
+	archive       archive.Store
+	outputStore   *executor.OutputStore
+	policy        ExecutionRetentionPolicy
+	batchSize     int
+	logger        *slog.Logger
+	now           func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewExecutionRetentionService creates a new execution retention service.
+// The archive store and output store may be nil.
+func NewExecutionRetentionService(executionRepo database.TaskExecutionRepository, partitionRepo database.ExecutionPartitionRepository, archiveStore archive.Store, outputStore *executor.OutputStore, policy ExecutionRetentionPolicy, logger *slog.Logger) *ExecutionRetentionService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ExecutionRetentionService{
+		executionRepo: executionRepo,
+		partitionRepo: partitionRepo,
+		archive:       archiveStore,
+		outputStore:   outputStore,
+		policy:        policy,
+		batchSize:     config.DefaultRetentionBatchSize,
+		logger:        logger,
+		now:           time.Now,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start applies the retention policy now and then every interval until the
+// service is stopped. With dryRun, runs only report what they would do.
+func (s *ExecutionRetentionService) Start(interval time.Duration, dryRun bool) {
+	s.wg.Add(1)
+	go s.run(interval, dryRun)
+}
+
+// Stop stops applying the retention policy, waiting for a run in progress
+// to stop
+func (s *ExecutionRetentionService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for execution retention to stop: %w", ctx.Err())
+	}
+}
+
+// run applies the retention policy now and then every interval
+func (s *ExecutionRetentionService) run(interval time.Duration, dryRun bool) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := s.Run(s.ctx, dryRun)
+		if err != nil && s.ctx.Err() == nil {
+			s.logger.Error("failed to apply execution retention", "error", err)
+		}
+		if report != nil {
+			s.logger.Info("execution retention applied",
+				"dry_run", report.DryRun,
+				"deleted", report.Deleted,
+				"partitions_dropped", report.PartitionsDropped,
+				"outputs_expired", report.OutputsExpired,
+				"output_bytes", report.OutputBytes,
+				"archives", len(report.Archives),
+			)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// Run applies the retention policy once: the partitions of executions older
+// than MaxAge are dropped, then executions beyond the newest KeepLast of
+// their task are deleted, then the output of the remaining executions older
+// than OutputMaxAge is dropped. With dryRun, nothing is
+// changed and the report counts what would be. On failure, the report
+// covers what was done before it.
+func (s *ExecutionRetentionService) Run(ctx context.Context, dryRun bool) (*ExecutionRetentionReport, error) {
+	report := &ExecutionRetentionReport{DryRun: dryRun}
+	startedAt := s.now().UTC()
+	outputBefore := startedAt.Add(-s.policy.OutputMaxAge)
+
+	var expired []database.ExecutionPartition
+	if s.policy.MaxAge > 0 {
+		var err error
+		if expired, err = s.expiredPartitions(ctx, startedAt.Add(-s.policy.MaxAge)); err != nil {
+			return report, err
+		}
+	}
+
+	if dryRun {
+		for _, partition := range expired {
+			count, err := s.partitionRepo.Count(ctx, partition)
+			if err != nil {
+				return report, err
+			}
+			report.Deleted += count.Executions
+			report.OutputBytes += count.OutputBytes
+			report.PartitionsDropped = append(report.PartitionsDropped, partition.Name)
+		}
+		if s.policy.KeepLast > 0 {
+			count, err := s.executionRepo.CountBeyondKeepLast(ctx, s.policy.KeepLast)
+			if err != nil {
+				return report, err
+			}
+			report.Deleted += count.Executions
+			report.OutputBytes += count.OutputBytes
+		}
+		if s.policy.OutputMaxAge > 0 {
+			count, err := s.executionRepo.CountOutputBefore(ctx, outputBefore)
+			if err != nil {
+				return report, err
+			}
+			report.OutputsExpired = count.Executions
+			report.OutputBytes += count.OutputBytes
+		}
+		return report, nil
+	}
+
+	batch := 0
+	for _, partition := range expired {
+		if err := s.dropPartition(ctx, report, startedAt, partition, &batch); err != nil {
+			return report, err
+		}
+	}
+
+	if s.policy.KeepLast > 0 {
+		err := s.apply(ctx, report, startedAt, ArchiveReasonDeleted,
+			func() ([]*models.TaskExecution, error) {
+				return s.executionRepo.GetBeyondKeepLast(ctx, s.policy.KeepLast, s.batchSize)
+			},
+			func(ids []uuid.UUID) error {
+				deleted, err := s.executionRepo.DeleteByIDs(ctx, ids)
+				report.Deleted += deleted
+				return err
+			},
+		)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if s.policy.OutputMaxAge > 0 {
+		err := s.apply(ctx, report, startedAt, ArchiveReasonOutputExpired,
+			func() ([]*models.TaskExecution, error) {
+				return s.executionRepo.GetOutputBefore(ctx, outputBefore, s.batchSize)
+			},
+			func(ids []uuid.UUID) error {
+				expired, err := s.executionRepo.ExpireOutput(ctx, ids)
+				report.OutputsExpired += expired
+				return err
+			},
+		)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// expiredPartitions returns the partitions of executions all created before
+// the given time
+func (s *ExecutionRetentionService) expiredPartitions(ctx context.Context, before time.Time) ([]database.ExecutionPartition, error) {
+	partitions, err := s.partitionRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []database.ExecutionPartition
+	for _, partition := range partitions {
+		if !partition.To.After(before) {
+			expired = append(expired, partition)
+		}
+	}
+	return expired, nil
+}
+
+// dropPartition archives the executions of a partition in batches, numbered
+// on from the given batch, then drops the partition with its executions
+func (s *ExecutionRetentionService) dropPartition(ctx context.Context, report *ExecutionRetentionReport, startedAt time.Time, partition database.ExecutionPartition, batch *int) error {
+	var ids []uuid.UUID
+	var outputBytes int64
+	afterCreatedAt, afterID := partition.From, uuid.Nil
+	for {
+		executions, err := s.partitionRepo.GetExecutions(ctx, partition, afterCreatedAt, afterID, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(executions) == 0 {
+			break
+		}
+		if err := s.executionRepo.LoadOutputs(ctx, executions); err != nil {
+			return err
+		}
+
+		if s.archive != nil {
+			*batch++
+			key, err := s.archiveBatch(ctx, executions, startedAt, ArchiveReasonExpired, *batch)
+			if err != nil {
+				return err
+			}
+			report.Archives = append(report.Archives, key)
+		}
+
+		for _, execution := range executions {
+			ids = append(ids, execution.ID)
+			outputBytes += int64(outputLength(execution))
+		}
+		if len(executions) < s.batchSize {
+			break
+		}
+		last := executions[len(executions)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+
+	// The drop returns the executions with spilled output, including any
+	// added to the partition since it was archived. The partitions are
+	// locked so the drop doesn't race partition maintenance elsewhere.
+	var truncatedIDs []uuid.UUID
+	err := s.partitionRepo.WithLock(ctx, func(partitions database.ExecutionPartitionRepository) error {
+		var err error
+		truncatedIDs, err = partitions.Drop(ctx, partition)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	report.Deleted += int64(len(ids))
+	report.OutputBytes += outputBytes
+	report.PartitionsDropped = append(report.PartitionsDropped, partition.Name)
+
+	if s.outputStore != nil {
+		for _, id := range truncatedIDs {
+			if err := s.outputStore.Remove(id); err != nil {
+				s.logger.Warn("failed to remove spilled execution output", "error", err, "execution_id", id)
+			}
+		}
+	}
+	return nil
+}
+
+// apply applies a retention rule in batches until a batch isn't full: each
+// batch is loaded with its output and archived, then passed to the rule,
+// then its spilled output is removed
+func (s *ExecutionRetentionService) apply(ctx context.Context, report *ExecutionRetentionReport, startedAt time.Time, reason string, next func() ([]*models.TaskExecution, error), remove func(ids []uuid.UUID) error) error {
+	for batch := 1; ; batch++ {
+		executions, err := next()
+		if err != nil {
+			return err
+		}
+		if len(executions) == 0 {
+			return nil
+		}
+		if err := s.executionRepo.LoadOutputs(ctx, executions); err != nil {
+			return err
+		}
+
+		if s.archive != nil {
+			key, err := s.archiveBatch(ctx, executions, startedAt, reason, batch)
+			if err != nil {
+				return err
+			}
+			report.Archives = append(report.Archives, key)
+		}
+
+		ids := make([]uuid.UUID, len(executions))
+		for i, execution := range executions {
+			ids[i] = execution.ID
+			report.OutputBytes += int64(outputLength(execution))
+		}
+		if err := remove(ids); err != nil {
+			return err
+		}
+
+		if s.outputStore != nil {
+			for _, execution := range executions {
+				if err := s.outputStore.Remove(execution.ID); err != nil {
+					s.logger.Warn("failed to remove spilled execution output", "error", err, "execution_id", execution.ID)
+				}
+			}
+		}
+
+		if len(executions) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+// archiveBatch writes the archive of a batch of executions and returns its
+// key, e.g. executions/2026/01/02/20260102T030405Z-deleted-0001.ndjson.gz
+func (s *ExecutionRetentionService) archiveBatch(ctx context.Context, executions []*models.TaskExecution, startedAt time.Time, reason string, batch int) (string, error) {
+	archivedAt := s.now().UTC()
+	records := make([]archivedExecution, len(executions))
+	for i, execution := range executions {
+		records[i] = archivedExecution{TaskExecution: execution, ArchiveReason: reason, ArchivedAt: archivedAt}
+	}
+
+	data, err := archive.EncodeNDJSON(records)
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("executions/%s/%s-%s-%04d.ndjson.gz",
+		startedAt.Format("2006/01/02"), startedAt.Format("20060102T150405Z"), reason, batch)
+	if err := s.archive.Put(ctx, key, data); err != nil {
+		return "", fmt.Errorf("failed to archive executions: %w", err)
+	}
+	return key, nil
+}
+
+// outputLength returns the size of an execution's stored output
+func outputLength(execution *models.TaskExecution) int {
+	length := 0
+	if execution.Stdout != nil {
+		length += len(*execution.Stdout)
+	}
+	if execution.Stderr != nil {
+		length += len(*execution.Stderr)
+	}
+	return length
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+// memoryArchive is an archive store keeping archives in memory
+type memoryArchive struct {
+	archives map[string][]byte
+	err      error
+}
+
+func (a *memoryArchive) Put(ctx context.Context, key string, data []byte) error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.archives == nil {
+		a.archives = make(map[string][]byte)
+	}
+	a.archives[key] = data
+	return nil
+}
+
+func (a *memoryArchive) records(t *testing.T, key string) []map[string]interface{} {
+	gz, err := gzip.NewReader(bytes.NewReader(a.archives[key]))
+	require.NoError(t, err)
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func newRetentionExecutions(n int) ([]*models.TaskExecution, []uuid.UUID) {
+	executions := make([]*models.TaskExecution, n)
+	ids := make([]uuid.UUID, n)
+	for i := range executions {
+		executions[i] = &models.TaskExecution{ID: uuid.New(), TaskID: uuid.New(), Status: models.ExecutionStatusCompleted}
+		ids[i] = executions[i].ID
+	}
+	return executions, ids
+}
+
+// onLoadOutputs fills in the output of the executions the service loads
+func onLoadOutputs(repo *MockTaskExecutionRepository) {
+	repo.On("LoadOutputs", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, execution := range args.Get(1).([]*models.TaskExecution) {
+			stdout := "output"
+			execution.Stdout = &stdout
+		}
+	}).Return(nil)
+}
+
+func TestExecutionRetentionService_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("archives, then deletes and expires in batches", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		onLoadOutputs(repo)
+		store := &memoryArchive{}
+		outputStore := executor.NewOutputStore(t.TempDir())
+		service := NewExecutionRetentionService(repo, nil, store, outputStore, ExecutionRetentionPolicy{KeepLast: 10, OutputMaxAge: 24 * time.Hour}, logger)
+		service.batchSize = 2
+		service.now = func() time.Time { return now }
+
+		full, fullIDs := newRetentionExecutions(2)
+		last, lastIDs := newRetentionExecutions(1)
+		expired, expiredIDs := newRetentionExecutions(1)
+
+		spill, err := outputStore.Create(full[0].ID, "stdout")
+		require.NoError(t, err)
+		require.NoError(t, spill.Close())
+
+		repo.On("GetBeyondKeepLast", mock.Anything, 10, 2).Return(full, nil).Once()
+		repo.On("DeleteByIDs", mock.Anything, fullIDs).Return(int64(2), nil).Once()
+		repo.On("GetBeyondKeepLast", mock.Anything, 10, 2).Return(last, nil).Once()
+		repo.On("DeleteByIDs", mock.Anything, lastIDs).Return(int64(1), nil).Once()
+		repo.On("GetOutputBefore", mock.Anything, now.Add(-24*time.Hour), 2).Return(expired, nil).Once()
+		repo.On("ExpireOutput", mock.Anything, expiredIDs).Return(int64(1), nil).Once()
+
+		report, err := service.Run(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), report.Deleted)
+		assert.Equal(t, int64(1), report.OutputsExpired)
+		assert.Equal(t, int64(4*len("output")), report.OutputBytes)
+		assert.Equal(t, []string{
+			"executions/2026/01/02/20260102T030405Z-deleted-0001.ndjson.gz",
+			"executions/2026/01/02/20260102T030405Z-deleted-0002.ndjson.gz",
+			"executions/2026/01/02/20260102T030405Z-output_expired-0001.ndjson.gz",
+		}, report.Archives)
+		repo.AssertExpectations(t)
+
+		records := store.records(t, report.Archives[0])
+		require.Len(t, records, 2)
+		assert.Equal(t, full[0].ID.String(), records[0]["id"])
+		assert.Equal(t, "output", records[0]["stdout"])
+		assert.Equal(t, ArchiveReasonDeleted, records[0]["archive_reason"])
+
+		_, err = outputStore.Open(full[0].ID, "stdout")
+		assert.True(t, errors.Is(err, os.ErrNotExist), "spilled output is removed")
+	})
+
+	t.Run("doesn't delete what it failed to archive", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		onLoadOutputs(repo)
+		store := &memoryArchive{err: errors.New("bucket not found")}
+		service := NewExecutionRetentionService(repo, nil, store, nil, ExecutionRetentionPolicy{KeepLast: 10}, logger)
+
+		executions, _ := newRetentionExecutions(1)
+		repo.On("GetBeyondKeepLast", mock.Anything, 10, service.batchSize).Return(executions, nil).Once()
+
+		report, err := service.Run(context.Background(), false)
+		assert.Error(t, err)
+		assert.Equal(t, int64(0), report.Deleted)
+		repo.AssertNotCalled(t, "DeleteByIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes without archiving when no archive is configured", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		onLoadOutputs(repo)
+		service := NewExecutionRetentionService(repo, nil, nil, nil, ExecutionRetentionPolicy{KeepLast: 10}, logger)
+
+		executions, ids := newRetentionExecutions(1)
+		repo.On("GetBeyondKeepLast", mock.Anything, 10, service.batchSize).Return(executions, nil).Once()
+		repo.On("DeleteByIDs", mock.Anything, ids).Return(int64(1), nil).Once()
+
+		report, err := service.Run(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), report.Deleted)
+		assert.Empty(t, report.Archives)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("dry run only counts", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		store := &memoryArchive{}
+		service := NewExecutionRetentionService(repo, nil, store, nil, ExecutionRetentionPolicy{KeepLast: 10, OutputMaxAge: time.Hour}, logger)
+		service.now = func() time.Time { return now }
+
+		repo.On("CountBeyondKeepLast", mock.Anything, 10).Return(database.RetentionCount{Executions: 5, OutputBytes: 500}, nil)
+		repo.On("CountOutputBefore", mock.Anything, now.Add(-time.Hour)).Return(database.RetentionCount{Executions: 7, OutputBytes: 700}, nil)
+
+		report, err := service.Run(context.Background(), true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, int64(5), report.Deleted)
+		assert.Equal(t, int64(7), report.OutputsExpired)
+		assert.Equal(t, int64(1200), report.OutputBytes)
+		assert.Empty(t, store.archives)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestExecutionRetentionService_MaxAge(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 3, 5, 3, 4, 5, 0, time.UTC)
+	december := database.ExecutionPartition{Name: "task_executions_p202512", From: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	january := database.ExecutionPartition{Name: "task_executions_p202601", From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}
+	february := database.ExecutionPartition{Name: "task_executions_p202602", From: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	partitions := []database.ExecutionPartition{december, january, february}
+	// Only the partitions ending at least 30 days ago have expired
+	policy := ExecutionRetentionPolicy{MaxAge: 30 * 24 * time.Hour}
+
+	t.Run("archives, then drops expired partitions", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		onLoadOutputs(repo)
+		partitionRepo := new(MockExecutionPartitionRepository)
+		store := &memoryArchive{}
+		service := NewExecutionRetentionService(repo, partitionRepo, store, nil, policy, logger)
+		service.batchSize = 2
+		service.now = func() time.Time { return now }
+
+		full, _ := newRetentionExecutions(2)
+		last, _ := newRetentionExecutions(1)
+		partitionRepo.On("List", mock.Anything).Return(partitions, nil)
+		partitionRepo.On("WithLock", mock.Anything).Return(nil).Maybe()
+		partitionRepo.On("GetExecutions", mock.Anything, december, december.From, uuid.Nil, 2).Return(full, nil).Once()
+		partitionRepo.On("GetExecutions", mock.Anything, december, full[1].CreatedAt, full[1].ID, 2).Return(last, nil).Once()
+		partitionRepo.On("Drop", mock.Anything, december).Return(nil, nil).Once()
+		partitionRepo.On("GetExecutions", mock.Anything, january, january.From, uuid.Nil, 2).Return([]*models.TaskExecution{}, nil).Once()
+		partitionRepo.On("Drop", mock.Anything, january).Return(nil, nil).Once()
+
+		report, err := service.Run(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), report.Deleted)
+		assert.Equal(t, []string{"task_executions_p202512", "task_executions_p202601"}, report.PartitionsDropped)
+		assert.Equal(t, []string{
+			"executions/2026/03/05/20260305T030405Z-expired-0001.ndjson.gz",
+			"executions/2026/03/05/20260305T030405Z-expired-0002.ndjson.gz",
+		}, report.Archives)
+		partitionRepo.AssertExpectations(t)
+		partitionRepo.AssertNotCalled(t, "Drop", mock.Anything, february)
+	})
+
+	t.Run("removes the spilled output of dropped executions", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		onLoadOutputs(repo)
+		partitionRepo := new(MockExecutionPartitionRepository)
+		outputStore := executor.NewOutputStore(t.TempDir())
+		service := NewExecutionRetentionService(repo, partitionRepo, &memoryArchive{}, outputStore, policy, logger)
+		service.now = func() time.Time { return now }
+
+		truncatedID := uuid.New()
+		spill, err := outputStore.Create(truncatedID, "stdout")
+		require.NoError(t, err)
+		require.NoError(t, spill.Close())
+
+		partitionRepo.On("List", mock.Anything).Return(partitions, nil)
+		partitionRepo.On("WithLock", mock.Anything).Return(nil).Maybe()
+		partitionRepo.On("GetExecutions", mock.Anything, mock.Anything, mock.Anything, uuid.Nil, service.batchSize).Return([]*models.TaskExecution{}, nil)
+		partitionRepo.On("Drop", mock.Anything, december).Return([]uuid.UUID{truncatedID}, nil).Once()
+		partitionRepo.On("Drop", mock.Anything, january).Return(nil, nil).Once()
+
+		_, err = service.Run(context.Background(), false)
+		require.NoError(t, err)
+		_, err = outputStore.Open(truncatedID, "stdout")
+		assert.True(t, errors.Is(err, os.ErrNotExist), "spilled output is removed")
+		partitionRepo.AssertExpectations(t)
+	})
+
+	t.Run("doesn't drop a partition it failed to archive", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		onLoadOutputs(repo)
+		partitionRepo := new(MockExecutionPartitionRepository)
+		store := &memoryArchive{err: errors.New("bucket not found")}
+		service := NewExecutionRetentionService(repo, partitionRepo, store, nil, policy, logger)
+		service.now = func() time.Time { return now }
+
+		executions, _ := newRetentionExecutions(1)
+		partitionRepo.On("List", mock.Anything).Return(partitions, nil)
+		partitionRepo.On("WithLock", mock.Anything).Return(nil).Maybe()
+		partitionRepo.On("GetExecutions", mock.Anything, december, december.From, uuid.Nil, service.batchSize).Return(executions, nil).Once()
+
+		_, err := service.Run(context.Background(), false)
+		assert.Error(t, err)
+		partitionRepo.AssertNotCalled(t, "Drop", mock.Anything, mock.Anything)
+	})
+
+	t.Run("dry run counts expired partitions", func(t *testing.T) {
+		repo := new(MockTaskExecutionRepository)
+		partitionRepo := new(MockExecutionPartitionRepository)
+		service := NewExecutionRetentionService(repo, partitionRepo, nil, nil, policy, logger)
+		service.now = func() time.Time { return now }
+
+		partitionRepo.On("List", mock.Anything).Return(partitions, nil)
+		partitionRepo.On("WithLock", mock.Anything).Return(nil).Maybe()
+		partitionRepo.On("Count", mock.Anything, december).Return(database.RetentionCount{Executions: 3, OutputBytes: 30}, nil)
+		partitionRepo.On("Count", mock.Anything, january).Return(database.RetentionCount{Executions: 4, OutputBytes: 40}, nil)
+
+		report, err := service.Run(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), report.Deleted)
+		assert.Equal(t, int64(70), report.OutputBytes)
+		assert.Equal(t, []string{"task_executions_p202512", "task_executions_p202601"}, report.PartitionsDropped)
+		partitionRepo.AssertExpectations(t)
+	})
+}
+
+func TestExecutionRetentionService_StartStop(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := new(MockTaskExecutionRepository)
+	service := NewExecutionRetentionService(repo, nil, nil, nil, ExecutionRetentionPolicy{KeepLast: 10}, logger)
+
+	counted := make(chan struct{}, 1)
+	repo.On("CountBeyondKeepLast", mock.Anything, 10).
+		Run(func(args mock.Arguments) { counted <- struct{}{} }).
+		Return(database.RetentionCount{}, nil).Once()
+
+	service.Start(time.Hour, true)
+
+	select {
+	case <-counted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retention did not run on start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+)
+
+// OpenOutputStore opens the store of spilled execution output in dir, or
+// returns nil when dir is empty. Spill files are only readable where they
+// were written, so every process spilling or serving output must share the
+// volume: the store's volume is recorded by the first process to open it,
+// and opening another one fails.
+func OpenOutputStore(ctx context.Context, dir string, volumes database.OutputVolumeRepository) (*executor.OutputStore, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	store := executor.NewOutputStore(dir)
+	volumeID, err := store.VolumeID()
+	if err != nil {
+		return nil, fmt.Errorf("output spill directory %s: %w", dir, err)
+	}
+
+	recorded, err := volumes.Claim(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if recorded != volumeID {
+		return nil, fmt.Errorf("output spill directory %s is not on the volume the other processes spill to (volume %s, expected %s): the API servers and schedulers must share it", dir, volumeID, recorded)
+	}
+
+	return store, nil
+}
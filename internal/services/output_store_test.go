@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+)
+
+// MockOutputVolumeRepository is a mock implementation of OutputVolumeRepository
+type MockOutputVolumeRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutputVolumeRepository) Claim(ctx context.Context, volumeID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, volumeID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func TestOpenOutputStore(t *testing.T) {
+	t.Run("no directory disables spilling", func(t *testing.T) {
+		volumes := new(MockOutputVolumeRepository)
+		store, err := OpenOutputStore(context.Background(), "", volumes)
+		require.NoError(t, err)
+		assert.Nil(t, store)
+		volumes.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything)
+	})
+
+	t.Run("opens the recorded volume", func(t *testing.T) {
+		dir := t.TempDir()
+		volumeID, err := executor.NewOutputStore(dir).VolumeID()
+		require.NoError(t, err)
+
+		volumes := new(MockOutputVolumeRepository)
+		volumes.On("Claim", mock.Anything, volumeID).Return(volumeID, nil)
+
+		store, err := OpenOutputStore(context.Background(), dir, volumes)
+		require.NoError(t, err)
+		assert.NotNil(t, store)
+		volumes.AssertExpectations(t)
+	})
+
+	t.Run("rejects a directory on another volume", func(t *testing.T) {
+		volumes := new(MockOutputVolumeRepository)
+		volumes.On("Claim", mock.Anything, mock.Anything).Return(uuid.New(), nil)
+
+		_, err := OpenOutputStore(context.Background(), t.TempDir(), volumes)
+		assert.ErrorContains(t, err, "must share it")
+	})
+
+	t.Run("rejects a missing directory", func(t *testing.T) {
+		volumes := new(MockOutputVolumeRepository)
+		_, err := OpenOutputStore(context.Background(), filepath.Join(t.TempDir(), "unmounted"), volumes)
+		assert.Error(t, err)
+		volumes.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/queue"
+)
+
+// RunnerService mediates queue and database access for remote runner agents.
+// A claimed job is leased to the runner through the queue receipt handle, which
+// the runner must present on every heartbeat, log upload and result submission.
+// The lease is bound to the execution it was handed out for, so it cannot be
+// used for another execution.
+type RunnerService struct {
+	taskQueue    queue.TaskQueue
+	repos        *database.Repositories
+	leaseTimeout time.Duration
+	pollInterval time.Duration
+	logger       *slog.Logger
+}
+
+// NewRunnerService creates a new runner service
+func NewRunnerService(taskQueue queue.TaskQueue, repos *database.Repositories, leaseTimeout time.Duration, logger *slog.Logger) *RunnerService {
+	return &RunnerService{
+		taskQueue:    taskQueue,
+		repos:        repos,
+		leaseTimeout: leaseTimeout,
+		pollInterval: config.DefaultRunnerPollInterval,
+		logger:       logger,
+	}
+}
+
+// ClaimJob leases the next task the runner is able to execute, waiting up to
+// wait for one to become available. It returns nil when no job was found.
+func (s *RunnerService) ClaimJob(ctx context.Context, runnerID string, capabilities []string, wait time.Duration) (*models.RunnerJob, error) {
+	capabilities = models.NormalizeCapabilities(capabilities)
+	deadline := time.Now().Add(wait)
+
+	for {
+		job, err := s.claimNext(ctx, runnerID, capabilities)
+		if err != nil || job != nil {
+			return job, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, nil
+		}
+
+		delay := s.pollInterval
+		if remaining < delay {
+			delay = remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// claimNext dequeues messages until one can be leased or the queue is empty
+func (s *RunnerService) claimNext(ctx context.Context, runnerID string, capabilities []string) (*models.RunnerJob, error) {
+	for {
+		var messages []*queue.TaskMessage
+		var err error
+		if len(capabilities) > 0 {
+			messages, err = s.taskQueue.DequeueMatching(ctx, 1, capabilities)
+		} else {
+			messages, err = s.taskQueue.Dequeue(ctx, 1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to dequeue task: %w", err)
+		}
+
+		if len(messages) == 0 {
+			return nil, nil
+		}
+
+		job, err := s.leaseJob(ctx, runnerID, messages[0])
+		if err != nil || job != nil {
+			return job, err
+		}
+	}
+}
+
+// leaseJob prepares the execution for a dequeued message. Messages for tasks
+// that no longer exist or executions that already finished are discarded.
+func (s *RunnerService) leaseJob(ctx context.Context, runnerID string, message *queue.TaskMessage) (*models.RunnerJob, error) {
+	if message.ReceiptHandle == nil {
+		return nil, fmt.Errorf("dequeued message %s has no receipt handle", message.MessageID)
+	}
+	leaseToken := *message.ReceiptHandle
+
+	task, err := s.repos.Tasks.GetByID(ctx, message.TaskID)
+	if err != nil {
+		if errors.Is(err, database.ErrTaskNotFound) {
+			s.logger.Warn("discarding queued message for missing task", "task_id", message.TaskID)
+			s.deleteMessage(ctx, leaseToken)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	execution, err := s.resolveExecution(ctx, task, message)
+	if err != nil {
+		return nil, err
+	}
+	if execution == nil {
+		s.deleteMessage(ctx, leaseToken)
+		return nil, nil
+	}
+
+	// Hand out the script of the revision the execution was created for
+	task, err = database.TaskAtRevision(ctx, s.repos.TaskRevisions, task, execution.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task revision: %w", err)
+	}
+
+	now := time.Now()
+	execution.Status = models.ExecutionStatusRunning
+	execution.StartedAt = &now
+	// Clear the output of a previous attempt
+	empty := ""
+	execution.Stdout = &empty
+	execution.Stderr = &empty
+	execution.Truncated = false
+	execution.OOMKilled = false
+	execution.ExitSignal = nil
+	execution.TimeoutPhase = nil
+	execution.ErrorCategory = nil
+	if err := s.repos.TaskExecutions.Update(ctx, execution); err != nil {
+		return nil, fmt.Errorf("failed to update execution: %w", err)
+	}
+
+	if err := s.repos.TaskExecutions.SetLease(ctx, execution.ID, leaseToken); err != nil {
+		return nil, fmt.Errorf("failed to set execution lease: %w", err)
+	}
+
+	if err := s.repos.Tasks.UpdateStatus(ctx, task.ID, models.TaskStatusRunning); err != nil {
+		return nil, fmt.Errorf("failed to update task status: %w", err)
+	}
+
+	// Shorten the visibility timeout so that jobs held by an unresponsive runner
+	// are handed out again once the lease lapses
+	if err := s.taskQueue.ExtendVisibility(ctx, leaseToken, s.leaseTimeout); err != nil {
+		s.logger.Warn("failed to set job lease timeout", "execution_id", execution.ID, "error", err)
+	}
+
+	s.logger.Info("job leased to runner",
+		"runner_id", runnerID,
+		"task_id", task.ID,
+		"execution_id", execution.ID,
+	)
+
+	return &models.RunnerJob{
+		ExecutionID:          execution.ID,
+		TaskID:               task.ID,
+		LeaseToken:           leaseToken,
+		Name:                 task.Name,
+		ScriptContent:        task.ScriptContent,
+		ScriptType:           task.ScriptType,
+		TimeoutSeconds:       task.TimeoutSeconds,
+		RequiredCapabilities: task.RequiredCapabilities,
+		SecurityLevel:        task.SecurityLevel,
+		Image:                task.Image,
+		ImageDigest:          task.ImageDigest,
+		NetworkMode:          task.NetworkMode,
+		NetworkAllowlist:     task.NetworkAllowlist,
+	}, nil
+}
+
+// resolveExecution returns the execution referenced by the message, creating
+// one when the message predates execution tracking. It returns nil when the
+// referenced execution has already reached a final state.
+func (s *RunnerService) resolveExecution(ctx context.Context, task *models.Task, message *queue.TaskMessage) (*models.TaskExecution, error) {
+	if id, err := uuid.Parse(message.Attributes["execution_id"]); err == nil {
+		execution, err := s.repos.TaskExecutions.GetByID(ctx, id)
+		if err != nil && !errors.Is(err, database.ErrExecutionNotFound) {
+			return nil, fmt.Errorf("failed to get execution: %w", err)
+		}
+
+		if execution != nil && execution.TaskID == task.ID {
+			switch execution.Status {
+			case models.ExecutionStatusPending, models.ExecutionStatusRunning:
+				// A running execution means a previous lease lapsed; start it over
+				return execution, nil
+			default:
+				s.logger.Info("discarding queued message for finished execution",
+					"execution_id", execution.ID,
+					"status", execution.Status,
+				)
+				return nil, nil
+			}
+		}
+	}
+
+	revision := task.Revision
+	if requested := message.Revision(); requested != nil {
+		revision = *requested
+	}
+	execution := &models.TaskExecution{
+		ID:     models.NewID(),
+		TaskID: task.ID,
+		Status: models.ExecutionStatusPending,
+
+		SecurityLevel: task.SecurityLevel,
+		Revision:      &revision,
+	}
+	if err := s.repos.TaskExecutions.Create(ctx, execution); err != nil {
+		return nil, fmt.Errorf("failed to create execution: %w", err)
+	}
+
+	return execution, nil
+}
+
+// Heartbeat renews the lease on a job and reports whether it was cancelled
+func (s *RunnerService) Heartbeat(ctx context.Context, executionID uuid.UUID, leaseToken string) (*models.RunnerHeartbeatResponse, error) {
+	execution, err := s.getLeasedExecution(ctx, executionID, leaseToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.Status == models.ExecutionStatusCancelled {
+		// The job will not be completed, so drop it from the queue for good
+		s.deleteMessage(ctx, leaseToken)
+		return &models.RunnerHeartbeatResponse{Cancelled: true}, nil
+	}
+
+	if execution.Status != models.ExecutionStatusRunning {
+		return nil, fmt.Errorf("cannot renew lease for execution with status: %s", execution.Status)
+	}
+
+	if err := s.renewLease(ctx, leaseToken); err != nil {
+		return nil, err
+	}
+
+	return &models.RunnerHeartbeatResponse{Cancelled: false}, nil
+}
+
+// AppendLogs appends a chunk of streamed output to a leased job
+func (s *RunnerService) AppendLogs(ctx context.Context, executionID uuid.UUID, req models.RunnerLogRequest) error {
+	if err := models.ValidateRunnerLogChunk(req.Stdout, req.Stderr); err != nil {
+		return err
+	}
+
+	execution, err := s.getLeasedExecution(ctx, executionID, req.LeaseToken)
+	if err != nil {
+		return err
+	}
+
+	if execution.Status != models.ExecutionStatusRunning {
+		return fmt.Errorf("cannot append logs to execution with status: %s", execution.Status)
+	}
+
+	if err := s.renewLease(ctx, req.LeaseToken); err != nil {
+		return err
+	}
+
+	if req.Stdout == "" && req.Stderr == "" {
+		return nil
+	}
+
+	if err := s.repos.TaskExecutions.AppendOutput(ctx, executionID, req.Stdout, req.Stderr); err != nil {
+		return fmt.Errorf("failed to append logs: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteJob records the result of a leased job and releases it from the queue
+func (s *RunnerService) CompleteJob(ctx context.Context, executionID uuid.UUID, req models.RunnerResultRequest) (*models.TaskExecution, error) {
+	if err := models.ValidateRunnerResultStatus(req.Status); err != nil {
+		return nil, err
+	}
+
+	execution, err := s.getLeasedExecution(ctx, executionID, req.LeaseToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.Status == models.ExecutionStatusCancelled {
+		s.deleteMessage(ctx, req.LeaseToken)
+	}
+
+	if execution.Status != models.ExecutionStatusRunning {
+		return nil, fmt.Errorf("cannot complete execution with status: %s", execution.Status)
+	}
+
+	if err := s.renewLease(ctx, req.LeaseToken); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	execution.Status = req.Status
+	execution.ReturnCode = req.ReturnCode
+	execution.ExecutionTimeMs = req.ExecutionTimeMs
+	execution.MemoryUsageBytes = req.MemoryUsageBytes
+	execution.Runtime = req.Runtime
+	execution.Truncated = req.Truncated
+	execution.OOMKilled = req.OOMKilled
+	execution.ExitSignal = req.ExitSignal
+	execution.TimeoutPhase = req.TimeoutPhase
+	execution.ErrorCategory = req.ErrorCategory
+	execution.CompletedAt = &now
+	if req.Stdout != nil {
+		execution.Stdout = req.Stdout
+	}
+	if req.Stderr != nil {
+		execution.Stderr = req.Stderr
+	}
+
+	if err := s.repos.TaskExecutions.Update(ctx, execution); err != nil {
+		return nil, fmt.Errorf("failed to update execution: %w", err)
+	}
+
+	if len(req.NetworkEvents) > 0 {
+		if err := s.repos.NetworkEvents.CreateBatch(ctx, executionID, req.NetworkEvents); err != nil {
+			return nil, fmt.Errorf("failed to record network events: %w", err)
+		}
+	}
+
+	if err := s.repos.Tasks.UpdateStatus(ctx, execution.TaskID, taskStatusForExecution(req.Status)); err != nil {
+		return nil, fmt.Errorf("failed to update task status: %w", err)
+	}
+
+	s.deleteMessage(ctx, req.LeaseToken)
+
+	return execution, nil
+}
+
+// getExecution loads an execution, mapping a missing row to a matchable error
+func (s *RunnerService) getExecution(ctx context.Context, executionID uuid.UUID) (*models.TaskExecution, error) {
+	execution, err := s.repos.TaskExecutions.GetByID(ctx, executionID)
+	if err != nil {
+		if errors.Is(err, database.ErrExecutionNotFound) {
+			return nil, fmt.Errorf("execution not found")
+		}
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+	return execution, nil
+}
+
+// getLeasedExecution loads an execution, checking that the lease token was
+// handed out for it before the caller acts on the execution or its message
+func (s *RunnerService) getLeasedExecution(ctx context.Context, executionID uuid.UUID, leaseToken string) (*models.TaskExecution, error) {
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	holds, err := s.repos.TaskExecutions.HoldsLease(ctx, executionID, leaseToken)
+	if err != nil {
+		if errors.Is(err, database.ErrExecutionNotFound) {
+			return nil, fmt.Errorf("execution not found")
+		}
+		return nil, fmt.Errorf("failed to check execution lease: %w", err)
+	}
+	if !holds {
+		return nil, fmt.Errorf("lease does not belong to this execution")
+	}
+
+	return execution, nil
+}
+
+// renewLease extends the visibility of the job's queue message, which also
+// proves the runner still holds the current lease
+func (s *RunnerService) renewLease(ctx context.Context, leaseToken string) error {
+	if err := s.taskQueue.ExtendVisibility(ctx, leaseToken, s.leaseTimeout); err != nil {
+		if queue.IsRetryableError(err) {
+			return fmt.Errorf("failed to renew lease: %w", err)
+		}
+		return fmt.Errorf("invalid or expired lease")
+	}
+	return nil
+}
+
+// deleteMessage removes a job from the queue, logging failures
+func (s *RunnerService) deleteMessage(ctx context.Context, leaseToken string) {
+	if err := s.taskQueue.DeleteMessage(ctx, leaseToken); err != nil {
+		s.logger.Error("failed to delete message from queue", "error", err)
+	}
+}
+
+// taskStatusForExecution maps a final execution status to the task status
+func taskStatusForExecution(status models.ExecutionStatus) models.TaskStatus {
+	switch status {
+	case models.ExecutionStatusCompleted:
+		return models.TaskStatusCompleted
+	case models.ExecutionStatusTimeout:
+		return models.TaskStatusTimeout
+	default:
+		return models.TaskStatusFailed
+	}
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/queue"
+)
+
+// MockTaskQueue is a mock implementation of queue.TaskQueue
+type MockTaskQueue struct {
+	mock.Mock
+}
+
+func (m *MockTaskQueue) Enqueue(ctx context.Context, message *queue.TaskMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*queue.TaskMessage, error) {
+	args := m.Called(ctx, maxMessages)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*queue.TaskMessage), args.Error(1)
+}
+
+func (m *MockTaskQueue) DequeueMatching(ctx context.Context, maxMessages int, capabilities []string) ([]*queue.TaskMessage, error) {
+	args := m.Called(ctx, maxMessages, capabilities)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*queue.TaskMessage), args.Error(1)
+}
+
+func (m *MockTaskQueue) DeleteMessage(ctx context.Context, receiptHandle string) error {
+	args := m.Called(ctx, receiptHandle)
+	return args.Error(0)
+}
+
+func (m *MockTaskQueue) ExtendVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error {
+	args := m.Called(ctx, receiptHandle, timeout)
+	return args.Error(0)
+}
+
+func (m *MockTaskQueue) GetQueueStats(ctx context.Context) (*queue.QueueStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.QueueStats), args.Error(1)
+}
+
+func (m *MockTaskQueue) IsHealthy(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockTaskQueue) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func setupRunnerServiceTest() (*RunnerService, *MockTaskQueue, *MockTaskRepository, *MockTaskExecutionRepository) {
+	mockQueue := new(MockTaskQueue)
+	mockTaskRepo := new(MockTaskRepository)
+	mockExecutionRepo := new(MockTaskExecutionRepository)
+	repos := &database.Repositories{
+		Tasks:          mockTaskRepo,
+		TaskExecutions: mockExecutionRepo,
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	service := NewRunnerService(mockQueue, repos, time.Minute, logger)
+	service.pollInterval = 10 * time.Millisecond
+
+	return service, mockQueue, mockTaskRepo, mockExecutionRepo
+}
+
+func TestRunnerService_ClaimJob(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+	executionID := uuid.New()
+	receiptHandle := "task-msg:1700000000:abc"
+
+	task := &models.Task{
+		BaseModel:            models.BaseModel{ID: taskID},
+		Name:                 "gpu task",
+		ScriptContent:        "print('hi')",
+		ScriptType:           models.ScriptTypePython,
+		TimeoutSeconds:       60,
+		RequiredCapabilities: []string{"gpu"},
+	}
+	message := &queue.TaskMessage{
+		TaskID:        taskID,
+		MessageID:     "task-msg",
+		ReceiptHandle: &receiptHandle,
+		Attributes:    map[string]string{"execution_id": executionID.String()},
+	}
+
+	t.Run("leases queued execution", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setupRunnerServiceTest()
+		execution := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusPending}
+
+		mockQueue.On("DequeueMatching", ctx, 1, []string{"gpu", "script:python"}).Return([]*queue.TaskMessage{message}, nil)
+		mockTaskRepo.On("GetByID", ctx, taskID).Return(task, nil)
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(execution, nil)
+		mockExecutionRepo.On("Update", ctx, mock.MatchedBy(func(e *models.TaskExecution) bool {
+			return e.ID == executionID && e.Status == models.ExecutionStatusRunning && e.StartedAt != nil
+		})).Return(nil)
+		mockExecutionRepo.On("SetLease", ctx, executionID, receiptHandle).Return(nil)
+		mockTaskRepo.On("UpdateStatus", ctx, taskID, models.TaskStatusRunning).Return(nil)
+		mockQueue.On("ExtendVisibility", ctx, receiptHandle, time.Minute).Return(nil)
+
+		job, err := service.ClaimJob(ctx, "runner-01", []string{"script:python", "GPU"}, time.Second)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+
+		assert.Equal(t, executionID, job.ExecutionID)
+		assert.Equal(t, taskID, job.TaskID)
+		assert.Equal(t, receiptHandle, job.LeaseToken)
+		assert.Equal(t, task.ScriptContent, job.ScriptContent)
+		assert.Equal(t, 60, job.TimeoutSeconds)
+		mockQueue.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+		mockExecutionRepo.AssertExpectations(t)
+	})
+
+	t.Run("discards message for cancelled execution", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setupRunnerServiceTest()
+		execution := &models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCancelled}
+
+		mockQueue.On("Dequeue", ctx, 1).Return([]*queue.TaskMessage{message}, nil).Once()
+		mockQueue.On("Dequeue", ctx, 1).Return([]*queue.TaskMessage{}, nil)
+		mockTaskRepo.On("GetByID", ctx, taskID).Return(task, nil)
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(execution, nil)
+		mockQueue.On("DeleteMessage", ctx, receiptHandle).Return(nil)
+
+		job, err := service.ClaimJob(ctx, "runner-01", nil, 0)
+		require.NoError(t, err)
+		assert.Nil(t, job)
+		mockQueue.AssertExpectations(t)
+		mockExecutionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("discards message for missing task", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, _ := setupRunnerServiceTest()
+
+		mockQueue.On("Dequeue", ctx, 1).Return([]*queue.TaskMessage{message}, nil).Once()
+		mockQueue.On("Dequeue", ctx, 1).Return([]*queue.TaskMessage{}, nil)
+		mockTaskRepo.On("GetByID", ctx, taskID).Return(nil, database.ErrTaskNotFound)
+		mockQueue.On("DeleteMessage", ctx, receiptHandle).Return(nil)
+
+		job, err := service.ClaimJob(ctx, "runner-01", nil, 0)
+		require.NoError(t, err)
+		assert.Nil(t, job)
+		mockQueue.AssertExpectations(t)
+	})
+
+	t.Run("waits until the deadline when the queue is empty", func(t *testing.T) {
+		service, mockQueue, _, _ := setupRunnerServiceTest()
+		mockQueue.On("Dequeue", ctx, 1).Return([]*queue.TaskMessage{}, nil)
+
+		start := time.Now()
+		job, err := service.ClaimJob(ctx, "runner-01", nil, 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.Nil(t, job)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Greater(t, len(mockQueue.Calls), 1)
+	})
+}
+
+func TestRunnerService_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	executionID := uuid.New()
+
+	t.Run("renews lease for running execution", func(t *testing.T) {
+		service, mockQueue, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{ID: executionID, Status: models.ExecutionStatusRunning}, nil)
+		mockExecutionRepo.On("HoldsLease", ctx, executionID, "lease").Return(true, nil)
+		mockQueue.On("ExtendVisibility", ctx, "lease", time.Minute).Return(nil)
+
+		response, err := service.Heartbeat(ctx, executionID, "lease")
+		require.NoError(t, err)
+		assert.False(t, response.Cancelled)
+		mockQueue.AssertExpectations(t)
+	})
+
+	t.Run("reports cancellation and releases the job", func(t *testing.T) {
+		service, mockQueue, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{ID: executionID, Status: models.ExecutionStatusCancelled}, nil)
+		mockExecutionRepo.On("HoldsLease", ctx, executionID, "lease").Return(true, nil)
+		mockQueue.On("DeleteMessage", ctx, "lease").Return(nil)
+
+		response, err := service.Heartbeat(ctx, executionID, "lease")
+		require.NoError(t, err)
+		assert.True(t, response.Cancelled)
+		mockQueue.AssertExpectations(t)
+	})
+
+	t.Run("rejects a lease that is no longer held", func(t *testing.T) {
+		service, mockQueue, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{ID: executionID, Status: models.ExecutionStatusRunning}, nil)
+		mockExecutionRepo.On("HoldsLease", ctx, executionID, "stale").Return(true, nil)
+		mockQueue.On("ExtendVisibility", ctx, "stale", time.Minute).Return(
+			queue.NewQueueOperationError("extend_visibility", "tasks", "msg", queue.ErrInvalidReceiptHandle, false))
+
+		_, err := service.Heartbeat(ctx, executionID, "stale")
+		require.Error(t, err)
+		assert.Equal(t, "invalid or expired lease", err.Error())
+	})
+
+	t.Run("execution not found", func(t *testing.T) {
+		service, _, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(nil, database.ErrExecutionNotFound)
+
+		_, err := service.Heartbeat(ctx, executionID, "lease")
+		require.Error(t, err)
+		assert.Equal(t, "execution not found", err.Error())
+	})
+}
+
+func TestRunnerService_AppendLogs(t *testing.T) {
+	ctx := context.Background()
+	executionID := uuid.New()
+
+	t.Run("appends output to running execution", func(t *testing.T) {
+		service, mockQueue, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{ID: executionID, Status: models.ExecutionStatusRunning}, nil)
+		mockExecutionRepo.On("HoldsLease", ctx, executionID, "lease").Return(true, nil)
+		mockQueue.On("ExtendVisibility", ctx, "lease", time.Minute).Return(nil)
+		mockExecutionRepo.On("AppendOutput", ctx, executionID, "line\n", "").Return(nil)
+
+		err := service.AppendLogs(ctx, executionID, models.RunnerLogRequest{LeaseToken: "lease", Stdout: "line\n"})
+		require.NoError(t, err)
+		mockExecutionRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects oversized chunks", func(t *testing.T) {
+		service, _, _, _ := setupRunnerServiceTest()
+		large := make([]byte, models.MaxRunnerLogChunkBytes+1)
+
+		err := service.AppendLogs(ctx, executionID, models.RunnerLogRequest{LeaseToken: "lease", Stdout: string(large)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "log chunk exceeds")
+	})
+
+	t.Run("rejects finished execution", func(t *testing.T) {
+		service, _, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{ID: executionID, Status: models.ExecutionStatusCompleted}, nil)
+		mockExecutionRepo.On("HoldsLease", ctx, executionID, "lease").Return(true, nil)
+
+		err := service.AppendLogs(ctx, executionID, models.RunnerLogRequest{LeaseToken: "lease", Stdout: "late"})
+		require.Error(t, err)
+		assert.Equal(t, "cannot append logs to execution with status: completed", err.Error())
+	})
+}
+
+func TestRunnerService_CompleteJob(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+	executionID := uuid.New()
+	returnCode := 1
+	streamed := "streamed output"
+
+	tests := []struct {
+		name           string
+		status         models.ExecutionStatus
+		wantTaskStatus models.TaskStatus
+	}{
+		{name: "completed", status: models.ExecutionStatusCompleted, wantTaskStatus: models.TaskStatusCompleted},
+		{name: "failed", status: models.ExecutionStatusFailed, wantTaskStatus: models.TaskStatusFailed},
+		{name: "timeout", status: models.ExecutionStatusTimeout, wantTaskStatus: models.TaskStatusTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockQueue, mockTaskRepo, mockExecutionRepo := setupRunnerServiceTest()
+			mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{
+				ID:     executionID,
+				TaskID: taskID,
+				Status: models.ExecutionStatusRunning,
+				Stdout: &streamed,
+			}, nil)
+			mockExecutionRepo.On("HoldsLease", ctx, executionID, "lease").Return(true, nil)
+			mockQueue.On("ExtendVisibility", ctx, "lease", time.Minute).Return(nil)
+			mockExecutionRepo.On("Update", ctx, mock.AnythingOfType("*models.TaskExecution")).Return(nil)
+			mockTaskRepo.On("UpdateStatus", ctx, taskID, tt.wantTaskStatus).Return(nil)
+			mockQueue.On("DeleteMessage", ctx, "lease").Return(nil)
+
+			execution, err := service.CompleteJob(ctx, executionID, models.RunnerResultRequest{
+				LeaseToken: "lease",
+				Status:     tt.status,
+				ReturnCode: &returnCode,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, execution.Status)
+			assert.Equal(t, &returnCode, execution.ReturnCode)
+			assert.Equal(t, &streamed, execution.Stdout, "streamed output is kept when the result omits stdout")
+			assert.NotNil(t, execution.CompletedAt)
+			mockQueue.AssertExpectations(t)
+			mockTaskRepo.AssertExpectations(t)
+			mockExecutionRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("rejects non-terminal status", func(t *testing.T) {
+		service, _, _, _ := setupRunnerServiceTest()
+
+		_, err := service.CompleteJob(ctx, executionID, models.RunnerResultRequest{LeaseToken: "lease", Status: models.ExecutionStatusRunning})
+		require.Error(t, err)
+		assert.Equal(t, "invalid result status: running", err.Error())
+	})
+
+	t.Run("releases cancelled execution without recording the result", func(t *testing.T) {
+		service, mockQueue, _, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionID).Return(&models.TaskExecution{ID: executionID, TaskID: taskID, Status: models.ExecutionStatusCancelled}, nil)
+		mockExecutionRepo.On("HoldsLease", ctx, executionID, "lease").Return(true, nil)
+		mockQueue.On("DeleteMessage", ctx, "lease").Return(nil)
+
+		_, err := service.CompleteJob(ctx, executionID, models.RunnerResultRequest{LeaseToken: "lease", Status: models.ExecutionStatusCompleted})
+		require.Error(t, err)
+		assert.Equal(t, "cannot complete execution with status: cancelled", err.Error())
+		mockQueue.AssertExpectations(t)
+		mockExecutionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRunnerService_LeaseOfAnotherExecution(t *testing.T) {
+	ctx := context.Background()
+	taskID := uuid.New()
+	executionB := uuid.New()
+	leaseA := "task-a:1700000000:aaa"
+
+	setup := func(status models.ExecutionStatus) (*RunnerService, *MockTaskQueue, *MockTaskRepository, *MockTaskExecutionRepository) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setupRunnerServiceTest()
+		mockExecutionRepo.On("GetByID", ctx, executionB).Return(&models.TaskExecution{ID: executionB, TaskID: taskID, Status: status}, nil)
+		// Runner A's lease is valid in the queue, but was handed out for another execution
+		mockQueue.On("ExtendVisibility", ctx, leaseA, time.Minute).Return(nil).Maybe()
+		mockExecutionRepo.On("HoldsLease", ctx, executionB, leaseA).Return(false, nil)
+		return service, mockQueue, mockTaskRepo, mockExecutionRepo
+	}
+
+	assertUntouched := func(t *testing.T, mockQueue *MockTaskQueue, mockTaskRepo *MockTaskRepository, mockExecutionRepo *MockTaskExecutionRepository) {
+		mockQueue.AssertNotCalled(t, "ExtendVisibility", mock.Anything, mock.Anything, mock.Anything)
+		mockQueue.AssertNotCalled(t, "DeleteMessage", mock.Anything, mock.Anything)
+		mockExecutionRepo.AssertNotCalled(t, "AppendOutput", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockExecutionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockTaskRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	}
+
+	t.Run("heartbeat", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setup(models.ExecutionStatusRunning)
+
+		_, err := service.Heartbeat(ctx, executionB, leaseA)
+		require.Error(t, err)
+		assert.Equal(t, "lease does not belong to this execution", err.Error())
+		assertUntouched(t, mockQueue, mockTaskRepo, mockExecutionRepo)
+	})
+
+	t.Run("heartbeat of a cancelled execution", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setup(models.ExecutionStatusCancelled)
+
+		_, err := service.Heartbeat(ctx, executionB, leaseA)
+		require.Error(t, err)
+		assert.Equal(t, "lease does not belong to this execution", err.Error())
+		assertUntouched(t, mockQueue, mockTaskRepo, mockExecutionRepo)
+	})
+
+	t.Run("log upload", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setup(models.ExecutionStatusRunning)
+
+		err := service.AppendLogs(ctx, executionB, models.RunnerLogRequest{LeaseToken: leaseA, Stdout: "forged"})
+		require.Error(t, err)
+		assert.Equal(t, "lease does not belong to this execution", err.Error())
+		assertUntouched(t, mockQueue, mockTaskRepo, mockExecutionRepo)
+	})
+
+	t.Run("result submission", func(t *testing.T) {
+		service, mockQueue, mockTaskRepo, mockExecutionRepo := setup(models.ExecutionStatusRunning)
+
+		_, err := service.CompleteJob(ctx, executionB, models.RunnerResultRequest{LeaseToken: leaseA, Status: models.ExecutionStatusCompleted})
+		require.Error(t, err)
+		assert.Equal(t, "lease does not belong to this execution", err.Error())
+		assertUntouched(t, mockQueue, mockTaskRepo, mockExecutionRepo)
+	})
+}
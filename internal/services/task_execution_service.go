@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,6 +32,18 @@ func NewTaskExecutionService(conn *database.Connection, queueManager queue.Queue
 
 // CreateExecutionAndUpdateTaskStatus atomically creates a task execution and enqueues it for processing
 func (s *TaskExecutionService) CreateExecutionAndUpdateTaskStatus(ctx context.Context, taskID uuid.UUID, userID uuid.UUID) (*models.TaskExecution, error) {
+	return s.createExecution(ctx, taskID, userID, nil)
+}
+
+// CreateRevisionExecution atomically creates an execution that runs the given
+// revision of the task and enqueues it for processing
+func (s *TaskExecutionService) CreateRevisionExecution(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision int) (*models.TaskExecution, error) {
+	return s.createExecution(ctx, taskID, userID, &revision)
+}
+
+// createExecution creates an execution of the task's current revision, or of
+// the given revision when it is not nil
+func (s *TaskExecutionService) createExecution(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision *int) (*models.TaskExecution, error) {
 	var execution *models.TaskExecution
 	var task *models.Task
 
@@ -63,11 +77,24 @@ func (s *TaskExecutionService) CreateExecutionAndUpdateTaskStatus(ctx context.Co
 			return fmt.Errorf("cannot execute task with status: %s", task.Status)
 		}
 
+		// Queue the task as it was at the requested revision
+		task, err = database.TaskAtRevision(ctx, repos.TaskRevisions, task, revision)
+		if err != nil {
+			if err == database.ErrTaskRevisionNotFound {
+				return fmt.Errorf("revision not found")
+			}
+			return fmt.Errorf("failed to get task revision: %w", err)
+		}
+		executionRevision := task.Revision
+
 		// Create task execution
 		execution = &models.TaskExecution{
 			ID:     uuid.New(),
 			TaskID: taskID,
 			Status: models.ExecutionStatusPending,
+
+			SecurityLevel: task.SecurityLevel,
+			Revision:      &executionRevision,
 		}
 
 		if err := repos.TaskExecutions.Create(ctx, execution); err != nil {
@@ -141,18 +168,22 @@ func (s *TaskExecutionService) enqueueTask(ctx context.Context, task *models.Tas
 
 	// Create task message for queue
 	message := &queue.TaskMessage{
-		TaskID:    task.ID,
-		UserID:    task.UserID,
-		Priority:  determinePriority(task),
-		QueuedAt:  time.Now(),
-		Attempts:  0,
-		MessageID: fmt.Sprintf("task-%s-exec-%s", task.ID, execution.ID),
+		TaskID:       task.ID,
+		UserID:       task.UserID,
+		Priority:     determinePriority(task),
+		QueuedAt:     time.Now(),
+		Attempts:     0,
+		Capabilities: task.RequiredCapabilities,
+		MessageID:    fmt.Sprintf("task-%s-exec-%s", task.ID, execution.ID),
 		Attributes: map[string]string{
 			"execution_id": execution.ID.String(),
 			"script_type":  string(task.ScriptType),
 			"priority":     fmt.Sprintf("%d", task.Priority),
 		},
 	}
+	if execution.Revision != nil {
+		message.Attributes["revision"] = strconv.Itoa(*execution.Revision)
+	}
 
 	// Enqueue to task queue
 	if err := s.queueManager.TaskQueue().Enqueue(ctx, message); err != nil {
@@ -224,6 +255,10 @@ func (s *TaskExecutionService) UpdateExecutionAndTaskStatus(ctx context.Context,
 		// Verify the task belongs to the user
 		task, err := repos.Tasks.GetByID(ctx, execution.TaskID)
 		if err != nil {
+			// The executions of a task in the trash are hidden with it
+			if errors.Is(err, database.ErrTaskNotFound) {
+				return fmt.Errorf("execution not found")
+			}
 			return fmt.Errorf("failed to get task: %w", err)
 		}
 
@@ -287,6 +322,10 @@ func (s *TaskExecutionService) CancelExecutionAndResetTaskStatus(ctx context.Con
 		// Verify the task belongs to the user
 		task, err := repos.Tasks.GetByID(ctx, execution.TaskID)
 		if err != nil {
+			// The executions of a task in the trash are hidden with it
+			if errors.Is(err, database.ErrTaskNotFound) {
+				return fmt.Errorf("execution not found")
+			}
 			return fmt.Errorf("failed to get task: %w", err)
 		}
 
@@ -349,6 +388,10 @@ func (s *TaskExecutionService) CompleteExecutionAndFinalizeTaskStatus(ctx contex
 		// Verify the task belongs to the user
 		task, err := repos.Tasks.GetByID(ctx, existingExecution.TaskID)
 		if err != nil {
+			// The executions of a task in the trash are hidden with it
+			if errors.Is(err, database.ErrTaskNotFound) {
+				return fmt.Errorf("execution not found")
+			}
 			return fmt.Errorf("failed to get task: %w", err)
 		}
 
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -344,11 +345,36 @@ func (m *MockTaskRepository) Update(ctx context.Context, task *models.Task) erro
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockTaskRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Restore(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) Purge(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	executionIDs, _ := args.Get(0).([]uuid.UUID)
+	return executionIDs, args.Error(1)
+}
+
+func (m *MockTaskRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, []uuid.UUID, error) {
+	args := m.Called(ctx, before, limit)
+	executionIDs, _ := args.Get(1).([]uuid.UUID)
+	return args.Get(0).(int64), executionIDs, args.Error(2)
+}
+
 func (m *MockTaskRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Task, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	if args.Get(0) == nil {
@@ -405,6 +431,14 @@ func (m *MockTaskRepository) ListCursor(ctx context.Context, req database.Cursor
 	return args.Get(0).([]*models.Task), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
 }
 
+func (m *MockTaskRepository) Search(ctx context.Context, filter database.TaskFilter, req database.CursorPaginationRequest) ([]*models.Task, database.CursorPaginationResponse, error) {
+	args := m.Called(ctx, filter, req)
+	if args.Get(0) == nil {
+		return nil, database.CursorPaginationResponse{}, args.Error(2)
+	}
+	return args.Get(0).([]*models.Task), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
+}
+
 func (m *MockTaskRepository) GetTasksWithExecutionCount(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Task, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	if args.Get(0) == nil {
@@ -421,6 +455,30 @@ func (m *MockTaskRepository) GetTasksWithLatestExecution(ctx context.Context, us
 	return args.Get(0).([]*models.Task), args.Error(1)
 }
 
+func (m *MockTaskRepository) GetImageInventory(ctx context.Context, userID uuid.UUID) ([]models.ImageUsage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ImageUsage), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetLabelInventory(ctx context.Context, userID uuid.UUID) ([]models.LabelUsage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LabelUsage), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Task, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
 func (m *MockTaskRepository) Count(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
@@ -472,6 +530,34 @@ func (m *MockTaskExecutionRepository) GetLatestByTaskID(ctx context.Context, tas
 	return args.Get(0).(*models.TaskExecution), args.Error(1)
 }
 
+func (m *MockTaskExecutionRepository) AppendOutput(ctx context.Context, id uuid.UUID, stdout, stderr string) error {
+	args := m.Called(ctx, id, stdout, stderr)
+	return args.Error(0)
+}
+
+func (m *MockTaskExecutionRepository) SetLease(ctx context.Context, id uuid.UUID, leaseToken string) error {
+	args := m.Called(ctx, id, leaseToken)
+	return args.Error(0)
+}
+
+func (m *MockTaskExecutionRepository) HoldsLease(ctx context.Context, id uuid.UUID, leaseToken string) (bool, error) {
+	args := m.Called(ctx, id, leaseToken)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) LoadOutputs(ctx context.Context, executions []*models.TaskExecution) error {
+	args := m.Called(ctx, executions)
+	return args.Error(0)
+}
+
+func (m *MockTaskExecutionRepository) GetOutput(ctx context.Context, id uuid.UUID, stream string, offset, limit int64) (*database.ExecutionOutput, error) {
+	args := m.Called(ctx, id, stream, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ExecutionOutput), args.Error(1)
+}
+
 func (m *MockTaskExecutionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -525,6 +611,14 @@ func (m *MockTaskExecutionRepository) ListCursor(ctx context.Context, req databa
 	return args.Get(0).([]*models.TaskExecution), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
 }
 
+func (m *MockTaskExecutionRepository) Search(ctx context.Context, filter database.ExecutionFilter, req database.CursorPaginationRequest) ([]*models.TaskExecution, database.CursorPaginationResponse, error) {
+	args := m.Called(ctx, filter, req)
+	if args.Get(0) == nil {
+		return nil, database.CursorPaginationResponse{}, args.Error(2)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Get(1).(database.CursorPaginationResponse), args.Error(2)
+}
+
 func (m *MockTaskExecutionRepository) Count(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
@@ -540,6 +634,42 @@ func (m *MockTaskExecutionRepository) CountByStatus(ctx context.Context, status
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockTaskExecutionRepository) GetBeyondKeepLast(ctx context.Context, keepLast, limit int) ([]*models.TaskExecution, error) {
+	args := m.Called(ctx, keepLast, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) CountBeyondKeepLast(ctx context.Context, keepLast int) (database.RetentionCount, error) {
+	args := m.Called(ctx, keepLast)
+	return args.Get(0).(database.RetentionCount), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) GetOutputBefore(ctx context.Context, before time.Time, limit int) ([]*models.TaskExecution, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskExecution), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) CountOutputBefore(ctx context.Context, before time.Time) (database.RetentionCount, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(database.RetentionCount), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskExecutionRepository) ExpireOutput(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestNewTaskExecutionService(t *testing.T) {
 	// Test service instantiation
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
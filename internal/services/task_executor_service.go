@@ -16,6 +16,7 @@ import (
 type TaskExecutorService struct {
 	taskExecutionService *TaskExecutionService
 	taskRepo             database.TaskRepository
+	revisionRepo         database.TaskRevisionRepository
 	executor             executor.TaskExecutor
 	cleanupManager       *executor.CleanupManager
 	logger               *slog.Logger
@@ -25,6 +26,7 @@ type TaskExecutorService struct {
 func NewTaskExecutorService(
 	taskExecutionService *TaskExecutionService,
 	taskRepo database.TaskRepository,
+	revisionRepo database.TaskRevisionRepository,
 	executor executor.TaskExecutor,
 	cleanupManager *executor.CleanupManager,
 	logger *slog.Logger,
@@ -32,6 +34,7 @@ func NewTaskExecutorService(
 	return &TaskExecutorService{
 		taskExecutionService: taskExecutionService,
 		taskRepo:             taskRepo,
+		revisionRepo:         revisionRepo,
 		executor:             executor,
 		cleanupManager:       cleanupManager,
 		logger:               logger,
@@ -40,6 +43,16 @@ func NewTaskExecutorService(
 
 // ExecuteTask executes a task using the container executor
 func (s *TaskExecutorService) ExecuteTask(ctx context.Context, taskID uuid.UUID, userID uuid.UUID) (*models.TaskExecution, error) {
+	return s.executeTask(ctx, taskID, userID, nil)
+}
+
+// ExecuteTaskRevision executes the given revision of a task using the container executor
+func (s *TaskExecutorService) ExecuteTaskRevision(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision int) (*models.TaskExecution, error) {
+	return s.executeTask(ctx, taskID, userID, &revision)
+}
+
+// executeTask executes the task's current revision, or the given revision when it is not nil
+func (s *TaskExecutorService) executeTask(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision *int) (*models.TaskExecution, error) {
 	logger := s.logger.With(
 		"task_id", taskID.String(),
 		"user_id", userID.String(),
@@ -49,14 +62,23 @@ func (s *TaskExecutorService) ExecuteTask(ctx context.Context, taskID uuid.UUID,
 	logger.Info("starting task execution")
 
 	// First, create the execution record and update task status
-	execution, err := s.taskExecutionService.CreateExecutionAndUpdateTaskStatus(ctx, taskID, userID)
+	var execution *models.TaskExecution
+	var err error
+	if revision != nil {
+		execution, err = s.taskExecutionService.CreateRevisionExecution(ctx, taskID, userID, *revision)
+	} else {
+		execution, err = s.taskExecutionService.CreateExecutionAndUpdateTaskStatus(ctx, taskID, userID)
+	}
 	if err != nil {
 		logger.Error("failed to create execution record", "error", err)
 		return nil, fmt.Errorf("failed to create execution record: %w", err)
 	}
 
-	// Get the task details for execution
+	// Get the task details for execution, as of the revision being run
 	task, err := s.getTaskByID(ctx, taskID)
+	if err == nil {
+		task, err = database.TaskAtRevision(ctx, s.revisionRepo, task, execution.Revision)
+	}
 	if err != nil {
 		// Rollback the execution if we can't get task details
 		if rollbackErr := s.rollbackExecution(ctx, execution.ID, userID); rollbackErr != nil {
@@ -110,9 +132,11 @@ func (s *TaskExecutorService) executeTaskAsync(ctx context.Context, task *models
 	if err != nil {
 		logger.Error("task execution failed", "error", err)
 		if result == nil {
+			category := executor.CategorizeError(err)
 			result = &executor.ExecutionResult{
-				Status: models.ExecutionStatusFailed,
-				Stderr: stringPtr(fmt.Sprintf("Execution error: %s", err.Error())),
+				Status:        models.ExecutionStatusFailed,
+				Stderr:        stringPtr(fmt.Sprintf("Execution error: %s", err.Error())),
+				ErrorCategory: &category,
 			}
 		}
 	}
@@ -154,6 +178,12 @@ func (s *TaskExecutorService) updateExecutionWithResults(ctx context.Context, ex
 		MemoryUsageBytes: result.MemoryUsageBytes,
 		StartedAt:        result.StartedAt,
 		CompletedAt:      result.CompletedAt,
+		Runtime:          result.Runtime,
+		Truncated:        result.Truncated,
+		OOMKilled:        result.OOMKilled,
+		ExitSignal:       result.ExitSignal,
+		TimeoutPhase:     result.TimeoutPhase,
+		ErrorCategory:    result.ErrorCategory,
 	}
 
 	// Determine task status based on execution status
@@ -171,6 +201,14 @@ func (s *TaskExecutorService) updateExecutionWithResults(ctx context.Context, ex
 		taskStatus = models.TaskStatusFailed // Fallback
 	}
 
+	// Record connection attempts made through the egress proxy
+	if len(result.NetworkEvents) > 0 {
+		repos := database.NewRepositories(s.taskExecutionService.conn)
+		if err := repos.NetworkEvents.CreateBatch(ctx, executionID, result.NetworkEvents); err != nil {
+			s.logger.Error("failed to record network events", "execution_id", executionID, "error", err)
+		}
+	}
+
 	// Use the existing service method to update both execution and task status atomically
 	return s.taskExecutionService.CompleteExecutionAndFinalizeTaskStatus(ctx, execution, taskStatus, userID)
 }
@@ -218,6 +256,15 @@ func (s *TaskExecutorService) GetExecutorHealth(ctx context.Context) error {
 	return s.executor.IsHealthy(ctx)
 }
 
+// ResolveImage pins a task's custom image to its current registry digest
+func (s *TaskExecutorService) ResolveImage(ctx context.Context, image string) (*executor.ResolvedImage, error) {
+	resolver, ok := s.executor.(executor.ImageResolver)
+	if !ok {
+		return nil, executor.ErrCustomImagesUnsupported
+	}
+	return resolver.ResolveImage(ctx, image)
+}
+
 // GetExecutionStats returns statistics about running executions
 func (s *TaskExecutorService) GetExecutionStats() executor.CleanupStats {
 	if s.cleanupManager != nil {
@@ -290,6 +337,11 @@ func (s *TaskExecutorService) CreateExecutionAndUpdateTaskStatus(ctx context.Con
 	return s.ExecuteTask(ctx, taskID, userID)
 }
 
+// CreateRevisionExecution creates an execution and starts running the given revision of the task
+func (s *TaskExecutorService) CreateRevisionExecution(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, revision int) (*models.TaskExecution, error) {
+	return s.ExecuteTaskRevision(ctx, taskID, userID, revision)
+}
+
 // CancelExecutionAndResetTaskStatus cancels an execution and resets task status
 func (s *TaskExecutorService) CancelExecutionAndResetTaskStatus(ctx context.Context, executionID uuid.UUID, userID uuid.UUID) error {
 	return s.CancelTaskExecution(ctx, executionID, userID)
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+var (
+	// ErrTaskAccessDenied is returned for tasks of another user
+	ErrTaskAccessDenied = errors.New("access denied")
+
+	// ErrCannotUpdateRunningTask is returned for updates of running tasks
+	ErrCannotUpdateRunningTask = errors.New("cannot update running task")
+
+	// ErrCannotDeleteRunningTask is returned for deletes of running tasks
+	ErrCannotDeleteRunningTask = errors.New("cannot delete running task")
+)
+
+// TaskValidationError is returned for task requests with an invalid field
+type TaskValidationError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *TaskValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the validation error
+func (e *TaskValidationError) Unwrap() error {
+	return e.Err
+}
+
+// TaskImageError is returned when the custom image of a task can't be
+// pinned. Err wraps one of the executor image errors when the image itself
+// is rejected; any other error means the registry couldn't be reached.
+type TaskImageError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *TaskImageError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the image resolution error
+func (e *TaskImageError) Unwrap() error {
+	return e.Err
+}
+
+// TaskTransactor runs functions within a database transaction, committed
+// when the function succeeds and rolled back otherwise. It is implemented by
+// the database connection.
+type TaskTransactor interface {
+	WithTransaction(ctx context.Context, fn func(tx database.Transaction) error) error
+}
+
+// TaskService saves tasks. Every write of a task goes through the same
+// checks, whether it comes from its own endpoint or from a bulk job:
+// requests are validated, scripts analyzed, tasks admitted and custom images
+// pinned before the task is written, and updates and deletes only apply to
+// the version of the task that was read.
+//
+// Besides the sentinel errors of this package and of the task repository,
+// its methods return a *TaskValidationError, an *analyzer.Error, an
+// *admission.DeniedError or a *TaskImageError for the tasks they reject.
+type TaskService struct {
+	taskRepo       database.TaskRepository
+	transactor     TaskTransactor
+	imageResolver  executor.ImageResolver
+	scriptAnalyzer *analyzer.Pipeline
+	admission      *admission.Engine
+	logger         *slog.Logger
+}
+
+// NewTaskService creates a new task service. The transactor runs the writes
+// made through WithTransaction in a single transaction. The image resolver
+// pins custom task images to a digest; when nil, tasks can't name their own
+// image. The
+// script analyzer checks scripts when tasks are saved; when nil, scripts are
+// analyzed in block mode. The admission engine applies the admission
+// policies; when nil, every task is admitted.
+func NewTaskService(taskRepo database.TaskRepository, transactor TaskTransactor, imageResolver executor.ImageResolver, scriptAnalyzer *analyzer.Pipeline, admissionEngine *admission.Engine, logger *slog.Logger) *TaskService {
+	if scriptAnalyzer == nil {
+		scriptAnalyzer = analyzer.NewPipeline(models.ScriptAnalysisModeBlock)
+	}
+
+	return &TaskService{
+		taskRepo:       taskRepo,
+		transactor:     transactor,
+		imageResolver:  imageResolver,
+		scriptAnalyzer: scriptAnalyzer,
+		admission:      admissionEngine,
+		logger:         logger,
+	}
+}
+
+// WithTransaction calls fn with a task service whose writes are made in a
+// single database transaction: they are committed when fn returns nil, and
+// none of them is made otherwise
+func (s *TaskService) WithTransaction(ctx context.Context, fn func(tasks *TaskService) error) error {
+	return s.transactor.WithTransaction(ctx, func(tx database.Transaction) error {
+		txService := *s
+		txService.taskRepo = tx.Repositories().Tasks
+		return fn(&txService)
+	})
+}
+
+// CreateTask validates a create request and saves the task it describes. The
+// findings of script analysis in warn mode are returned with the task.
+func (s *TaskService) CreateTask(ctx context.Context, user *models.User, req models.CreateTaskRequest) (*models.Task, []models.ScriptFinding, error) {
+	if err := validateCreateRequest(req); err != nil {
+		return nil, nil, &TaskValidationError{Err: err}
+	}
+
+	task := newTaskFromRequest(user, req)
+	if req.Image != nil && *req.Image != "" {
+		task.Image = req.Image
+	}
+
+	findings, err := s.SaveNewTask(ctx, user, task)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, findings, nil
+}
+
+// SaveNewTask checks and saves a task built by the caller, such as one
+// instantiated from a template
+func (s *TaskService) SaveNewTask(ctx context.Context, user *models.User, task *models.Task) ([]models.ScriptFinding, error) {
+	findings, err := s.CheckTask(ctx, user, task)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	s.logger.Info("task created successfully", "task_id", task.ID, "user_id", user.ID)
+	return findings, nil
+}
+
+// GetOwnedTask gets a task the user owns
+func (s *TaskService) GetOwnedTask(ctx context.Context, user *models.User, taskID uuid.UUID) (*models.Task, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, database.ErrTaskNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.UserID != user.ID {
+		return nil, ErrTaskAccessDenied
+	}
+	return task, nil
+}
+
+// UpdateTask applies an update request to a task the user owns and saves it,
+// provided the task still has the version it was read at. An empty image
+// reverts the task to the default image; any other image is pinned again,
+// picking up its current digest.
+func (s *TaskService) UpdateTask(ctx context.Context, user *models.User, task *models.Task, req models.UpdateTaskRequest) ([]models.ScriptFinding, error) {
+	if task.Status == models.TaskStatusRunning {
+		return nil, ErrCannotUpdateRunningTask
+	}
+
+	if err := applyTaskUpdates(task, req); err != nil {
+		return nil, &TaskValidationError{Err: err}
+	}
+	if req.Image != nil {
+		if *req.Image == "" {
+			task.Image = nil
+		} else {
+			task.Image = req.Image
+		}
+		task.ImageDigest = nil
+	}
+
+	return s.SaveTask(ctx, user, task)
+}
+
+// SaveTask checks and saves the changes the caller made to a task the user
+// owns, such as those of a manifest, provided the task still has the version
+// it was read at
+func (s *TaskService) SaveTask(ctx context.Context, user *models.User, task *models.Task) ([]models.ScriptFinding, error) {
+	if task.Status == models.TaskStatusRunning {
+		return nil, ErrCannotUpdateRunningTask
+	}
+
+	findings, err := s.CheckTask(ctx, user, task)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Update(ctx, task); err != nil {
+		if errors.Is(err, database.ErrTaskVersionConflict) || errors.Is(err, database.ErrTaskNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	s.logger.Info("task updated successfully", "task_id", task.ID, "user_id", user.ID)
+	return findings, nil
+}
+
+// DeleteTask moves a task the user owns to the trash, provided it still has
+// the version it was read at
+func (s *TaskService) DeleteTask(ctx context.Context, user *models.User, task *models.Task) error {
+	if task.Status == models.TaskStatusRunning {
+		return ErrCannotDeleteRunningTask
+	}
+
+	if err := s.taskRepo.Delete(ctx, task.ID, task.Version); err != nil {
+		if errors.Is(err, database.ErrTaskVersionConflict) || errors.Is(err, database.ErrTaskNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	s.logger.Info("task deleted successfully", "task_id", task.ID, "user_id", user.ID)
+	return nil
+}
+
+// CheckTask runs the checks of saving a task without writing it: script
+// analysis, admission, and the pinning of a changed image, one without a
+// digest. The findings of script analysis in warn mode are returned; in
+// audit mode they are only logged.
+func (s *TaskService) CheckTask(ctx context.Context, user *models.User, task *models.Task) ([]models.ScriptFinding, error) {
+	report := s.scriptAnalyzer.AnalyzeTask(task)
+	if err := report.Err(); err != nil {
+		s.logger.Warn("task script blocked by script analysis",
+			"task_id", task.ID, "user_id", user.ID, "findings", len(report.Findings), "rule_id", report.Findings[0].RuleID)
+		return nil, err
+	}
+	for _, finding := range report.Findings {
+		s.logger.Info("task script analysis finding",
+			"task_id", task.ID, "user_id", user.ID, "mode", report.Mode,
+			"rule_id", finding.RuleID, "severity", finding.Severity, "line", finding.Line, "message", finding.Message)
+	}
+
+	if err := s.AdmitTask(user, task); err != nil {
+		return nil, err
+	}
+
+	if task.Image != nil && task.ImageDigest == nil {
+		if err := s.pinTaskImage(ctx, task, *task.Image); err != nil {
+			return nil, &TaskImageError{Err: err}
+		}
+	}
+
+	if report.Mode == models.ScriptAnalysisModeWarn {
+		return report.Findings, nil
+	}
+	return nil, nil
+}
+
+// AdmitTask evaluates the task against the admission policy that applies to
+// the user, returning an *admission.DeniedError when it is denied
+func (s *TaskService) AdmitTask(user *models.User, task *models.Task) error {
+	if s.admission == nil {
+		return nil
+	}
+
+	decision := s.admission.Evaluate(user, task)
+	if err := decision.Err(); err != nil {
+		s.logger.Warn("task denied by admission policy",
+			"task_id", task.ID, "user_id", user.ID, "policy", decision.Policy, "error", err)
+		return err
+	}
+	return nil
+}
+
+// pinTaskImage resolves a custom image and pins the task to its digest
+func (s *TaskService) pinTaskImage(ctx context.Context, task *models.Task, image string) error {
+	if s.imageResolver == nil {
+		return executor.ErrCustomImagesUnsupported
+	}
+
+	resolved, err := s.imageResolver.ResolveImage(ctx, image)
+	if err != nil {
+		return err
+	}
+
+	task.Image = &resolved.Image
+	task.ImageDigest = &resolved.Digest
+	return nil
+}
+
+// newTaskFromRequest builds the task a validated create request describes,
+// with the defaults of the fields it leaves out. The image is left to be
+// pinned.
+func newTaskFromRequest(user *models.User, req models.CreateTaskRequest) *models.Task {
+	task := &models.Task{
+		BaseModel: models.BaseModel{
+			ID: uuid.New(),
+		},
+		UserID:         user.ID,
+		Name:           req.Name,
+		Description:    req.Description,
+		ScriptContent:  req.ScriptContent,
+		ScriptType:     req.ScriptType,
+		Status:         models.TaskStatusPending,
+		Priority:       5, // Default priority
+		TimeoutSeconds: config.DefaultTaskTimeout,
+		Metadata:       req.Metadata,
+
+		RequiredCapabilities: models.NormalizeCapabilities(req.RequiredCapabilities),
+		SecurityLevel:        models.SecurityLevelStandard,
+		NetworkMode:          models.NetworkModeNone,
+		NetworkAllowlist:     models.NormalizeNetworkAllowlist(req.NetworkAllowlist),
+		Labels:               req.Labels,
+	}
+
+	// Set optional fields
+	if req.Priority != nil {
+		task.Priority = *req.Priority
+	}
+	if req.TimeoutSeconds != nil {
+		task.TimeoutSeconds = *req.TimeoutSeconds
+	}
+	if req.SecurityLevel != nil {
+		task.SecurityLevel = *req.SecurityLevel
+	}
+	if req.NetworkMode != nil {
+		task.NetworkMode = *req.NetworkMode
+	}
+
+	return task
+}
+
+// validateCreateRequest validates the create task request
+func validateCreateRequest(req models.CreateTaskRequest) error {
+	if err := models.ValidateTaskName(req.Name); err != nil {
+		return err
+	}
+
+	if err := models.ValidateScriptType(req.ScriptType); err != nil {
+		return err
+	}
+
+	if err := models.ValidateScriptContent(req.ScriptContent); err != nil {
+		return err
+	}
+
+	if req.Priority != nil {
+		if err := models.ValidatePriority(*req.Priority); err != nil {
+			return err
+		}
+	}
+
+	if req.TimeoutSeconds != nil {
+		if err := models.ValidateTimeout(*req.TimeoutSeconds); err != nil {
+			return err
+		}
+	}
+
+	if err := models.ValidateCapabilities(req.RequiredCapabilities); err != nil {
+		return err
+	}
+
+	if req.SecurityLevel != nil {
+		if err := models.ValidateSecurityLevel(*req.SecurityLevel); err != nil {
+			return err
+		}
+	}
+
+	networkMode := models.NetworkModeNone
+	if req.NetworkMode != nil {
+		networkMode = *req.NetworkMode
+	}
+	if err := models.ValidateNetworkPolicy(networkMode, req.NetworkAllowlist); err != nil {
+		return err
+	}
+
+	if err := models.ValidateLabels(req.Labels); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// applyTaskUpdates applies the update request to the task
+func applyTaskUpdates(task *models.Task, req models.UpdateTaskRequest) error {
+	if req.Name != nil {
+		if err := models.ValidateTaskName(*req.Name); err != nil {
+			return err
+		}
+		task.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		task.Description = req.Description
+	}
+
+	if req.ScriptContent != nil {
+		if err := models.ValidateScriptContent(*req.ScriptContent); err != nil {
+			return err
+		}
+		task.ScriptContent = *req.ScriptContent
+	}
+
+	if req.ScriptType != nil {
+		if err := models.ValidateScriptType(*req.ScriptType); err != nil {
+			return err
+		}
+		task.ScriptType = *req.ScriptType
+	}
+
+	if req.Priority != nil {
+		if err := models.ValidatePriority(*req.Priority); err != nil {
+			return err
+		}
+		task.Priority = *req.Priority
+	}
+
+	if req.TimeoutSeconds != nil {
+		if err := models.ValidateTimeout(*req.TimeoutSeconds); err != nil {
+			return err
+		}
+		task.TimeoutSeconds = *req.TimeoutSeconds
+	}
+
+	if req.Metadata != nil {
+		task.Metadata = req.Metadata
+	}
+
+	if req.RequiredCapabilities != nil {
+		if err := models.ValidateCapabilities(req.RequiredCapabilities); err != nil {
+			return err
+		}
+		task.RequiredCapabilities = models.NormalizeCapabilities(req.RequiredCapabilities)
+	}
+
+	if req.SecurityLevel != nil {
+		if err := models.ValidateSecurityLevel(*req.SecurityLevel); err != nil {
+			return err
+		}
+		task.SecurityLevel = *req.SecurityLevel
+	}
+
+	// The mode and allowlist are validated together, so switching away from
+	// the allowlist mode drops the allowlist unless a new one is given
+	if req.NetworkMode != nil || req.NetworkAllowlist != nil {
+		networkMode := task.NetworkMode
+		if req.NetworkMode != nil {
+			networkMode = *req.NetworkMode
+		}
+
+		allowlist := task.NetworkAllowlist
+		if req.NetworkAllowlist != nil {
+			allowlist = req.NetworkAllowlist
+		} else if networkMode != models.NetworkModeAllowlist {
+			allowlist = nil
+		}
+
+		if err := models.ValidateNetworkPolicy(networkMode, allowlist); err != nil {
+			return err
+		}
+		task.NetworkMode = networkMode
+		task.NetworkAllowlist = models.NormalizeNetworkAllowlist(allowlist)
+	}
+
+	// The labels are replaced as a whole; an empty object removes them all
+	if req.Labels != nil {
+		if err := models.ValidateLabels(req.Labels); err != nil {
+			return err
+		}
+		task.Labels = req.Labels
+	}
+
+	return nil
+}
@@ -0,0 +1,522 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/admission"
+	"github.com/voidrunnerhq/voidrunner/internal/analyzer"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+func newTestTaskService(taskRepo database.TaskRepository, admissionEngine *admission.Engine) *TaskService {
+	return NewTaskService(taskRepo, nil, nil, nil, admissionEngine, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+}
+
+func TestTaskService_CreateTask(t *testing.T) {
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}}
+
+	t.Run("saves the task", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		taskRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+			return task.UserID == user.ID && task.Name == "Task" && task.Priority == 5 && task.Status == models.TaskStatusPending
+		})).Return(nil)
+
+		task, findings, err := newTestTaskService(taskRepo, nil).CreateTask(context.Background(), user, models.CreateTaskRequest{
+			Name:          "Task",
+			ScriptType:    models.ScriptTypePython,
+			ScriptContent: "print('hello')",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, task.UserID)
+		assert.Empty(t, findings)
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+
+		_, _, err := newTestTaskService(taskRepo, nil).CreateTask(context.Background(), user, models.CreateTaskRequest{
+			ScriptType:    models.ScriptTypePython,
+			ScriptContent: "print('hello')",
+		})
+		var validationErr *TaskValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects blocked scripts", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		service := NewTaskService(taskRepo, nil, nil, analyzer.NewPipeline(models.ScriptAnalysisModeBlock), nil, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+		_, _, err := service.CreateTask(context.Background(), user, models.CreateTaskRequest{
+			Name:          "Task",
+			ScriptType:    models.ScriptTypeBash,
+			ScriptContent: "echo start\nrm -rf /",
+		})
+		var analysisErr *analyzer.Error
+		require.ErrorAs(t, err, &analysisErr)
+		assert.NotEmpty(t, analysisErr.Findings)
+		taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects custom images without an image resolver", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		image := "python:3.12"
+
+		_, _, err := newTestTaskService(taskRepo, nil).CreateTask(context.Background(), user, models.CreateTaskRequest{
+			Name:          "Task",
+			ScriptType:    models.ScriptTypePython,
+			ScriptContent: "print('hello')",
+			Image:         &image,
+		})
+		var imageErr *TaskImageError
+		assert.ErrorAs(t, err, &imageErr)
+		taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_UpdateTask(t *testing.T) {
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}}
+	name := "Renamed"
+
+	newTask := func(status models.TaskStatus) *models.Task {
+		return &models.Task{
+			BaseModel:      models.BaseModel{ID: uuid.New()},
+			UserID:         user.ID,
+			Name:           "Task",
+			ScriptType:     models.ScriptTypePython,
+			ScriptContent:  "print('hello')",
+			Status:         status,
+			Priority:       9,
+			TimeoutSeconds: 30,
+			Version:        2,
+		}
+	}
+
+	t.Run("saves the updated task", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		task := newTask(models.TaskStatusPending)
+		taskRepo.On("Update", mock.Anything, task).Return(nil)
+
+		_, err := newTestTaskService(taskRepo, nil).UpdateTask(context.Background(), user, task, models.UpdateTaskRequest{Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, name, task.Name)
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects running tasks", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+
+		_, err := newTestTaskService(taskRepo, nil).UpdateTask(context.Background(), user, newTask(models.TaskStatusRunning), models.UpdateTaskRequest{Name: &name})
+		assert.ErrorIs(t, err, ErrCannotUpdateRunningTask)
+		taskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects tasks denied by admission", func(t *testing.T) {
+		maxPriority := 5
+		engine, err := admission.NewEngine(admission.File{
+			Policies: []admission.Policy{{Name: "interns", Rules: admission.Rules{MaxPriority: &maxPriority}}},
+		}, nil)
+		require.NoError(t, err)
+		taskRepo := new(MockTaskRepository)
+
+		_, err = newTestTaskService(taskRepo, engine).UpdateTask(context.Background(), user, newTask(models.TaskStatusPending), models.UpdateTaskRequest{Name: &name})
+		var deniedErr *admission.DeniedError
+		require.ErrorAs(t, err, &deniedErr)
+		assert.Equal(t, "interns", deniedErr.Policy)
+		taskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("passes on version conflicts", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		taskRepo.On("Update", mock.Anything, mock.Anything).Return(database.ErrTaskVersionConflict)
+
+		_, err := newTestTaskService(taskRepo, nil).UpdateTask(context.Background(), user, newTask(models.TaskStatusPending), models.UpdateTaskRequest{Name: &name})
+		assert.ErrorIs(t, err, database.ErrTaskVersionConflict)
+	})
+}
+
+func TestTaskService_DeleteTask(t *testing.T) {
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}}
+
+	t.Run("deletes the version that was read", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		task := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: user.ID, Status: models.TaskStatusPending, Version: 3}
+		taskRepo.On("Delete", mock.Anything, task.ID, 3).Return(nil)
+
+		require.NoError(t, newTestTaskService(taskRepo, nil).DeleteTask(context.Background(), user, task))
+		taskRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects running tasks", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		task := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: user.ID, Status: models.TaskStatusRunning}
+
+		assert.ErrorIs(t, newTestTaskService(taskRepo, nil).DeleteTask(context.Background(), user, task), ErrCannotDeleteRunningTask)
+		taskRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("passes on version conflicts", func(t *testing.T) {
+		taskRepo := new(MockTaskRepository)
+		task := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: user.ID, Status: models.TaskStatusPending, Version: 3}
+		taskRepo.On("Delete", mock.Anything, task.ID, 3).Return(database.ErrTaskVersionConflict)
+
+		assert.ErrorIs(t, newTestTaskService(taskRepo, nil).DeleteTask(context.Background(), user, task), database.ErrTaskVersionConflict)
+	})
+}
+
+func TestTaskService_WithTransaction(t *testing.T) {
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}}
+	task := &models.Task{
+		BaseModel:      models.BaseModel{ID: uuid.New()},
+		UserID:         user.ID,
+		Name:           "Task",
+		ScriptType:     models.ScriptTypePython,
+		ScriptContent:  "print('hello')",
+		Status:         models.TaskStatusPending,
+		Priority:       5,
+		TimeoutSeconds: 30,
+	}
+
+	taskRepo := new(MockTaskRepository)
+	txTaskRepo := new(MockTaskRepository)
+	txTaskRepo.On("Update", mock.Anything, task).Return(nil)
+	conn := new(MockConnection)
+	conn.On("WithTransaction", mock.Anything, mock.Anything).Return(func(fn func(tx database.Transaction) error) error {
+		tx := new(MockTransaction)
+		tx.On("Repositories").Return(database.TransactionalRepositories{Tasks: txTaskRepo})
+		return fn(tx)
+	})
+	service := NewTaskService(taskRepo, conn, nil, nil, nil, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	err := service.WithTransaction(context.Background(), func(tasks *TaskService) error {
+		_, err := tasks.SaveTask(context.Background(), user, task)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Writes go to the repository of the transaction
+	txTaskRepo.AssertExpectations(t)
+	taskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestTaskService_GetOwnedTask(t *testing.T) {
+	user := &models.User{BaseModel: models.BaseModel{ID: uuid.New()}}
+	own := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: user.ID}
+	other := &models.Task{BaseModel: models.BaseModel{ID: uuid.New()}, UserID: uuid.New()}
+	missing := uuid.New()
+
+	taskRepo := new(MockTaskRepository)
+	taskRepo.On("GetByID", mock.Anything, own.ID).Return(own, nil)
+	taskRepo.On("GetByID", mock.Anything, other.ID).Return(other, nil)
+	taskRepo.On("GetByID", mock.Anything, missing).Return(nil, database.ErrTaskNotFound)
+	service := newTestTaskService(taskRepo, nil)
+
+	task, err := service.GetOwnedTask(context.Background(), user, own.ID)
+	require.NoError(t, err)
+	assert.Equal(t, own, task)
+
+	_, err = service.GetOwnedTask(context.Background(), user, other.ID)
+	assert.ErrorIs(t, err, ErrTaskAccessDenied)
+
+	_, err = service.GetOwnedTask(context.Background(), user, missing)
+	assert.ErrorIs(t, err, database.ErrTaskNotFound)
+}
+
+func TestApplyTaskUpdatesNetworkPolicy(t *testing.T) {
+	allowlistMode := models.NetworkModeAllowlist
+	noneMode := models.NetworkModeNone
+
+	task := &models.Task{NetworkMode: models.NetworkModeNone}
+
+	// An allowlist alone doesn't switch the mode
+	err := applyTaskUpdates(task, models.UpdateTaskRequest{NetworkAllowlist: []string{"pypi.org"}})
+	require.Error(t, err)
+
+	err = applyTaskUpdates(task, models.UpdateTaskRequest{
+		NetworkMode:      &allowlistMode,
+		NetworkAllowlist: []string{"pypi.org"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.NetworkModeAllowlist, task.NetworkMode)
+	assert.Equal(t, []string{"pypi.org"}, task.NetworkAllowlist)
+
+	// Switching back to no network drops the allowlist
+	err = applyTaskUpdates(task, models.UpdateTaskRequest{NetworkMode: &noneMode})
+	require.NoError(t, err)
+	assert.Equal(t, models.NetworkModeNone, task.NetworkMode)
+	assert.Nil(t, task.NetworkAllowlist)
+}
+
+func TestApplyTaskUpdatesLabels(t *testing.T) {
+	task := &models.Task{Labels: map[string]string{"env": "dev"}}
+
+	// Omitted labels are left unchanged
+	require.NoError(t, applyTaskUpdates(task, models.UpdateTaskRequest{}))
+	assert.Equal(t, map[string]string{"env": "dev"}, task.Labels)
+
+	require.NoError(t, applyTaskUpdates(task, models.UpdateTaskRequest{Labels: map[string]string{"env": "prod", "team": "data"}}))
+	assert.Equal(t, map[string]string{"env": "prod", "team": "data"}, task.Labels)
+
+	err := applyTaskUpdates(task, models.UpdateTaskRequest{Labels: map[string]string{"-env": "prod"}})
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "data"}, task.Labels)
+
+	// An empty object removes all labels
+	require.NoError(t, applyTaskUpdates(task, models.UpdateTaskRequest{Labels: map[string]string{}}))
+	assert.Empty(t, task.Labels)
+}
+
+// Test applyTaskUpdates error scenarios
+func TestApplyTaskUpdates(t *testing.T) {
+	// Create a base task for testing
+	baseTask := &models.Task{
+		BaseModel: models.BaseModel{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		UserID:         uuid.New(),
+		Name:           "Original Task",
+		Description:    stringPtr("Original description"),
+		ScriptContent:  "print('original')",
+		ScriptType:     models.ScriptTypePython,
+		Priority:       1,
+		TimeoutSeconds: 30,
+		Metadata:       models.JSONB{"original": "value"},
+	}
+
+	tests := []struct {
+		name          string
+		updateReq     models.UpdateTaskRequest
+		expectedError string
+	}{
+		{
+			name: "valid name update",
+			updateReq: models.UpdateTaskRequest{
+				Name: stringPtr("Updated Task Name"),
+			},
+		},
+		{
+			name: "invalid name - empty",
+			updateReq: models.UpdateTaskRequest{
+				Name: stringPtr(""),
+			},
+			expectedError: "task name is required",
+		},
+		{
+			name: "invalid name - too long",
+			updateReq: models.UpdateTaskRequest{
+				Name: stringPtr(string(make([]byte, 256))), // > 255 characters
+			},
+			expectedError: "task name is too long",
+		},
+		{
+			name: "invalid name - whitespace only",
+			updateReq: models.UpdateTaskRequest{
+				Name: stringPtr("   \t\n  "),
+			},
+			expectedError: "task name cannot be empty",
+		},
+		{
+			name: "valid description update",
+			updateReq: models.UpdateTaskRequest{
+				Description: stringPtr("Updated description"),
+			},
+		},
+		{
+			name: "null description update",
+			updateReq: models.UpdateTaskRequest{
+				Description: nil,
+			},
+		},
+		{
+			name: "valid script content update",
+			updateReq: models.UpdateTaskRequest{
+				ScriptContent: stringPtr("print('updated')"),
+			},
+		},
+		{
+			name: "invalid script content - empty",
+			updateReq: models.UpdateTaskRequest{
+				ScriptContent: stringPtr(""),
+			},
+			expectedError: "script content is required",
+		},
+		{
+			name: "invalid script content - too large",
+			updateReq: models.UpdateTaskRequest{
+				ScriptContent: stringPtr(string(make([]byte, 65536))), // > 65535 characters
+			},
+			expectedError: "script content is too long",
+		},
+		{
+			name: "invalid script content - whitespace only",
+			updateReq: models.UpdateTaskRequest{
+				ScriptContent: stringPtr("   \t\n  "),
+			},
+			expectedError: "script content cannot be empty",
+		},
+		{
+			name: "valid script type update",
+			updateReq: models.UpdateTaskRequest{
+				ScriptType: func() *models.ScriptType { st := models.ScriptTypeJavaScript; return &st }(),
+			},
+		},
+		{
+			name: "invalid script type",
+			updateReq: models.UpdateTaskRequest{
+				ScriptType: func() *models.ScriptType { st := models.ScriptType("invalid"); return &st }(),
+			},
+			expectedError: "invalid script type",
+		},
+		{
+			name: "valid priority update",
+			updateReq: models.UpdateTaskRequest{
+				Priority: func() *int { p := 5; return &p }(),
+			},
+		},
+		{
+			name: "invalid priority - negative",
+			updateReq: models.UpdateTaskRequest{
+				Priority: func() *int { p := -1; return &p }(),
+			},
+			expectedError: "priority must be between 0 and 10",
+		},
+		{
+			name: "valid priority - zero",
+			updateReq: models.UpdateTaskRequest{
+				Priority: func() *int { p := 0; return &p }(),
+			},
+		},
+		{
+			name: "invalid priority - too high",
+			updateReq: models.UpdateTaskRequest{
+				Priority: func() *int { p := 11; return &p }(),
+			},
+			expectedError: "priority must be between 0 and 10",
+		},
+		{
+			name: "valid timeout update",
+			updateReq: models.UpdateTaskRequest{
+				TimeoutSeconds: func() *int { t := 60; return &t }(),
+			},
+		},
+		{
+			name: "invalid timeout - negative",
+			updateReq: models.UpdateTaskRequest{
+				TimeoutSeconds: func() *int { t := -1; return &t }(),
+			},
+			expectedError: "timeout must be greater than 0",
+		},
+		{
+			name: "invalid timeout - zero",
+			updateReq: models.UpdateTaskRequest{
+				TimeoutSeconds: func() *int { t := 0; return &t }(),
+			},
+			expectedError: "timeout must be greater than 0",
+		},
+		{
+			name: "invalid timeout - too high",
+			updateReq: models.UpdateTaskRequest{
+				TimeoutSeconds: func() *int { t := 3601; return &t }(),
+			},
+			expectedError: "timeout cannot exceed 3600 seconds",
+		},
+		{
+			name: "valid metadata update",
+			updateReq: models.UpdateTaskRequest{
+				Metadata: models.JSONB{"updated": "metadata"},
+			},
+		},
+		{
+			name: "null metadata update",
+			updateReq: models.UpdateTaskRequest{
+				Metadata: nil,
+			},
+		},
+		{
+			name: "multiple valid updates",
+			updateReq: models.UpdateTaskRequest{
+				Name:           stringPtr("Multi Update Task"),
+				Description:    stringPtr("Multi update description"),
+				ScriptContent:  stringPtr("print('multi update')"),
+				ScriptType:     func() *models.ScriptType { st := models.ScriptTypeBash; return &st }(),
+				Priority:       func() *int { p := 3; return &p }(),
+				TimeoutSeconds: func() *int { t := 120; return &t }(),
+				Metadata:       models.JSONB{"multi": "update"},
+			},
+		},
+		{
+			name: "multiple updates with one invalid",
+			updateReq: models.UpdateTaskRequest{
+				Name:          stringPtr("Valid Name"),
+				Description:   stringPtr("Valid description"),
+				ScriptContent: stringPtr(""), // Invalid
+				Priority:      func() *int { p := 3; return &p }(),
+			},
+			expectedError: "script content is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a copy of the base task for each test
+			task := &models.Task{
+				BaseModel:      baseTask.BaseModel,
+				UserID:         baseTask.UserID,
+				Name:           baseTask.Name,
+				Description:    baseTask.Description,
+				ScriptContent:  baseTask.ScriptContent,
+				ScriptType:     baseTask.ScriptType,
+				Priority:       baseTask.Priority,
+				TimeoutSeconds: baseTask.TimeoutSeconds,
+				Metadata:       baseTask.Metadata,
+			}
+
+			err := applyTaskUpdates(task, tt.updateReq)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+
+				// Note: Task may be partially modified before error occurs
+				// This is expected behavior as validation happens per field
+			} else {
+				assert.NoError(t, err)
+
+				// Verify updates were applied correctly
+				if tt.updateReq.Name != nil {
+					assert.Equal(t, *tt.updateReq.Name, task.Name)
+				}
+				if tt.updateReq.Description != nil {
+					assert.Equal(t, tt.updateReq.Description, task.Description)
+				}
+				if tt.updateReq.ScriptContent != nil {
+					assert.Equal(t, *tt.updateReq.ScriptContent, task.ScriptContent)
+				}
+				if tt.updateReq.ScriptType != nil {
+					assert.Equal(t, *tt.updateReq.ScriptType, task.ScriptType)
+				}
+				if tt.updateReq.Priority != nil {
+					assert.Equal(t, *tt.updateReq.Priority, task.Priority)
+				}
+				if tt.updateReq.TimeoutSeconds != nil {
+					assert.Equal(t, *tt.updateReq.TimeoutSeconds, task.TimeoutSeconds)
+				}
+				if tt.updateReq.Metadata != nil {
+					assert.Equal(t, tt.updateReq.Metadata, task.Metadata)
+				}
+			}
+		})
+	}
+}
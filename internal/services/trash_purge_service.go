@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+)
+
+// TrashPurgeService purges the tasks that have been in the trash for longer
+// than the retention, with their executions and the executions' spilled
+// output. The trash is checked when the service starts and then periodically.
+type TrashPurgeService struct {
+	taskRepo    database.TaskRepository
+	outputStore *executor.OutputStore
+	retention   time.Duration
+	interval    time.Duration
+	batchSize   int
+	logger      *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTrashPurgeService creates a new trash purge service
+func NewTrashPurgeService(taskRepo database.TaskRepository, outputStore *executor.OutputStore, retention, interval time.Duration, logger *slog.Logger) *TrashPurgeService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TrashPurgeService{
+		taskRepo:    taskRepo,
+		outputStore: outputStore,
+		retention:   retention,
+		interval:    interval,
+		batchSize:   config.DefaultTrashPurgeBatchSize,
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Retention returns how long tasks are kept in the trash
+func (s *TrashPurgeService) Retention() time.Duration {
+	return s.retention
+}
+
+// Start purges the trash in the background until the service is stopped
+func (s *TrashPurgeService) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop stops purging the trash, waiting for a purge in progress to stop
+func (s *TrashPurgeService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for the trash purge to stop: %w", ctx.Err())
+	}
+}
+
+// run purges the expired tasks now and then every interval
+func (s *TrashPurgeService) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired(s.ctx)
+		if err != nil && s.ctx.Err() == nil {
+			s.logger.Error("failed to purge the trash", "error", err)
+		} else if purged > 0 {
+			s.logger.Info("purged tasks from the trash", "count", purged, "retention", s.retention)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// PurgeExpired purges the tasks that have been in the trash for longer than
+// the retention
+func (s *TrashPurgeService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.Purge(ctx, time.Now().Add(-s.retention))
+}
+
+// Purge purges every task moved to the trash before the given time, in
+// batches so that no single statement holds its locks for long, and returns
+// how many it purged
+func (s *TrashPurgeService) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		purged, executionIDs, err := s.taskRepo.PurgeDeleted(ctx, before, s.batchSize)
+		total += purged
+		s.removeOutput(executionIDs)
+		if err != nil {
+			return total, err
+		}
+		if purged < int64(s.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// PurgeTask purges a task in the trash
+func (s *TrashPurgeService) PurgeTask(ctx context.Context, id uuid.UUID) error {
+	executionIDs, err := s.taskRepo.Purge(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.removeOutput(executionIDs)
+	return nil
+}
+
+// removeOutput removes the spilled output of purged executions
+func (s *TrashPurgeService) removeOutput(executionIDs []uuid.UUID) {
+	if s.outputStore == nil {
+		return
+	}
+	for _, id := range executionIDs {
+		if err := s.outputStore.Remove(id); err != nil {
+			s.logger.Warn("failed to remove spilled execution output", "error", err, "execution_id", id)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+)
+
+func TestTrashPurgeService_Purge(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	before := time.Now()
+
+	t.Run("purges in batches until a batch isn't full", func(t *testing.T) {
+		repo := new(MockTaskRepository)
+		service := NewTrashPurgeService(repo, nil, time.Hour, time.Hour, logger)
+		service.batchSize = 2
+		repo.On("PurgeDeleted", mock.Anything, before, 2).Return(int64(2), nil, nil).Twice()
+		repo.On("PurgeDeleted", mock.Anything, before, 2).Return(int64(1), nil, nil).Once()
+
+		purged, err := service.Purge(context.Background(), before)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), purged)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		repo := new(MockTaskRepository)
+		service := NewTrashPurgeService(repo, nil, time.Hour, time.Hour, logger)
+		service.batchSize = 2
+		repo.On("PurgeDeleted", mock.Anything, before, 2).Return(int64(2), nil, nil).Once()
+		repo.On("PurgeDeleted", mock.Anything, before, 2).Return(int64(0), nil, errors.New("connection lost")).Once()
+
+		purged, err := service.Purge(context.Background(), before)
+		assert.Error(t, err)
+		assert.Equal(t, int64(2), purged)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTrashPurgeService_RemovesSpilledOutput(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	outputStore := executor.NewOutputStore(t.TempDir())
+	spill := func(t *testing.T) uuid.UUID {
+		id := uuid.New()
+		file, err := outputStore.Create(id, executor.OutputStreamStdout)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		return id
+	}
+	assertRemoved := func(t *testing.T, id uuid.UUID) {
+		_, err := outputStore.Open(id, executor.OutputStreamStdout)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	}
+
+	t.Run("purging a task", func(t *testing.T) {
+		repo := new(MockTaskRepository)
+		service := NewTrashPurgeService(repo, outputStore, time.Hour, time.Hour, logger)
+		taskID, executionID := uuid.New(), spill(t)
+		repo.On("Purge", mock.Anything, taskID).Return([]uuid.UUID{executionID}, nil)
+
+		require.NoError(t, service.PurgeTask(context.Background(), taskID))
+		assertRemoved(t, executionID)
+	})
+
+	t.Run("purging the trash", func(t *testing.T) {
+		repo := new(MockTaskRepository)
+		service := NewTrashPurgeService(repo, outputStore, time.Hour, time.Hour, logger)
+		before := time.Now()
+		executionID := spill(t)
+		repo.On("PurgeDeleted", mock.Anything, before, service.batchSize).Return(int64(1), []uuid.UUID{executionID}, nil)
+
+		purged, err := service.Purge(context.Background(), before)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		assertRemoved(t, executionID)
+	})
+}
+
+func TestTrashPurgeService_StartStop(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := new(MockTaskRepository)
+	service := NewTrashPurgeService(repo, nil, 24*time.Hour, time.Hour, logger)
+
+	purged := make(chan time.Time, 1)
+	repo.On("PurgeDeleted", mock.Anything, mock.AnythingOfType("time.Time"), service.batchSize).
+		Run(func(args mock.Arguments) { purged <- args.Get(1).(time.Time) }).
+		Return(int64(0), nil, nil).Once()
+
+	service.Start()
+
+	select {
+	case before := <-purged:
+		assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the trash was not purged on start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,170 @@
+// Package templates imports task templates from a directory.
+//
+// Each .yaml, .yml or .json file in the directory defines one template. The
+// templates are imported without an owner, as global templates or as team
+// templates of one of the admission policy groups, and replace the imported
+// template of the same name on every import. Imported templates can't be
+// changed through the API.
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/voidrunnerhq/voidrunner/internal/config"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the content of a template file, in YAML or JSON
+type File struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	ScriptType  models.ScriptType `yaml:"script_type"`
+
+	// Script is the script body, with text/template placeholders for the
+	// parameters
+	Script     string                     `yaml:"script"`
+	Parameters []models.TemplateParameter `yaml:"parameters"`
+
+	// Visibility is global when omitted; team templates name their team
+	Visibility models.TemplateVisibility `yaml:"visibility"`
+	Team       string                    `yaml:"team"`
+
+	Priority             *int                      `yaml:"priority"`
+	TimeoutSeconds       *int                      `yaml:"timeout_seconds"`
+	SecurityLevel        *models.TaskSecurityLevel `yaml:"security_level"`
+	RequiredCapabilities []string                  `yaml:"required_capabilities"`
+}
+
+// LoadDir reads and validates every template file in the directory, sorted
+// by file name
+func LoadDir(dir string) ([]*models.TaskTemplate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	templates := make([]*models.TaskTemplate, 0, len(paths))
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		template, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := files[template.Name]; ok {
+			return nil, fmt.Errorf("template %q is defined in both %s and %s", template.Name, other, path)
+		}
+		files[template.Name] = path
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// LoadFile reads and validates a template file
+func LoadFile(path string) (*models.TaskTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	// Unknown fields are rejected, as a misspelled default would silently
+	// be ignored
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse template file %s: %w", path, err)
+	}
+
+	template, err := file.Template()
+	if err != nil {
+		return nil, fmt.Errorf("invalid template file %s: %w", path, err)
+	}
+	return template, nil
+}
+
+// Template converts the file to a validated, owner-less task template
+func (f File) Template() (*models.TaskTemplate, error) {
+	template := &models.TaskTemplate{
+		Name:           strings.TrimSpace(f.Name),
+		ScriptType:     f.ScriptType,
+		ScriptTemplate: f.Script,
+		Parameters:     f.Parameters,
+		Visibility:     f.Visibility,
+		Priority:       5,
+		TimeoutSeconds: config.DefaultTaskTimeout,
+		SecurityLevel:  models.SecurityLevelStandard,
+
+		RequiredCapabilities: models.NormalizeCapabilities(f.RequiredCapabilities),
+	}
+
+	if f.Description != "" {
+		template.Description = &f.Description
+	}
+	if template.Visibility == "" {
+		template.Visibility = models.TemplateVisibilityGlobal
+	}
+	if f.Team != "" {
+		team := strings.TrimSpace(f.Team)
+		template.Team = &team
+	}
+	if f.Priority != nil {
+		template.Priority = *f.Priority
+	}
+	if f.TimeoutSeconds != nil {
+		template.TimeoutSeconds = *f.TimeoutSeconds
+	}
+	if f.SecurityLevel != nil {
+		template.SecurityLevel = *f.SecurityLevel
+	}
+
+	// Private templates are only visible to their owner, which imported
+	// templates don't have
+	if template.Visibility == models.TemplateVisibilityPrivate {
+		return nil, fmt.Errorf("imported templates must have visibility %s or %s",
+			models.TemplateVisibilityGlobal, models.TemplateVisibilityTeam)
+	}
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Import loads the templates in the directory and saves them to the
+// repository. Nothing is saved unless every template file is valid.
+func Import(ctx context.Context, repo database.TaskTemplateRepository, dir string) ([]*models.TaskTemplate, error) {
+	templates, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		if err := repo.Import(ctx, template); err != nil {
+			return nil, fmt.Errorf("failed to import template %q: %w", template.Name, err)
+		}
+	}
+	return templates, nil
+}
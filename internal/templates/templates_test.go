@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+)
+
+const testTemplateFile = `
+name: Greeting
+description: Say hello
+script_type: bash
+script: echo {{shellquote .name}}
+parameters:
+  - name: name
+    type: string
+    default: world
+timeout_seconds: 60
+`
+
+func writeTemplateFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeTemplateFiles(t, map[string]string{
+		"greeting.yaml": testTemplateFile,
+		"team.json":     `{"name": "Team", "script_type": "python", "script": "print(1)", "visibility": "team", "team": "platform"}`,
+		"README.md":     "not a template",
+	})
+
+	templates, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+
+	greeting := templates[0]
+	assert.Equal(t, "Greeting", greeting.Name)
+	assert.Nil(t, greeting.OwnerID)
+	assert.Equal(t, models.TemplateVisibilityGlobal, greeting.Visibility)
+	assert.Equal(t, 60, greeting.TimeoutSeconds)
+	assert.Equal(t, 5, greeting.Priority)
+
+	script, err := greeting.Render(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "echo 'world'", script)
+
+	team := templates[1]
+	assert.Equal(t, models.TemplateVisibilityTeam, team.Visibility)
+	require.NotNil(t, team.Team)
+	assert.Equal(t, "platform", *team.Team)
+}
+
+func TestLoadDir_ExampleTemplates(t *testing.T) {
+	templates, err := LoadDir(filepath.Join("..", "..", "config", "templates"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, templates)
+}
+
+func TestLoadDir_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		errMsg string
+	}{
+		{
+			name:   "unknown field",
+			files:  map[string]string{"a.yaml": testTemplateFile + "memory: 512\n"},
+			errMsg: "field memory not found",
+		},
+		{
+			name:   "private visibility",
+			files:  map[string]string{"a.yaml": testTemplateFile + "visibility: private\n"},
+			errMsg: "imported templates must have visibility global or team",
+		},
+		{
+			name:   "undeclared parameter",
+			files:  map[string]string{"a.yaml": "name: A\nscript_type: bash\nscript: echo {{.missing}}\n"},
+			errMsg: "invalid template file",
+		},
+		{
+			name: "duplicate name",
+			files: map[string]string{
+				"a.yaml": testTemplateFile,
+				"b.yaml": testTemplateFile,
+			},
+			errMsg: `template "Greeting" is defined in both`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadDir(writeTemplateFiles(t, tt.files))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := LoadDir(filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+	})
+}
+
+// fakeTemplateRepository records imported templates
+type fakeTemplateRepository struct {
+	database.TaskTemplateRepository
+	imported []*models.TaskTemplate
+}
+
+func (r *fakeTemplateRepository) Import(ctx context.Context, template *models.TaskTemplate) error {
+	template.ID = uuid.New()
+	r.imported = append(r.imported, template)
+	return nil
+}
+
+func TestImport(t *testing.T) {
+	repo := &fakeTemplateRepository{}
+
+	t.Run("imports every template", func(t *testing.T) {
+		dir := writeTemplateFiles(t, map[string]string{"greeting.yaml": testTemplateFile})
+
+		templates, err := Import(context.Background(), repo, dir)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		require.Len(t, repo.imported, 1)
+		assert.NotEqual(t, uuid.Nil, templates[0].ID)
+	})
+
+	t.Run("saves nothing when a file is invalid", func(t *testing.T) {
+		repo.imported = nil
+		dir := writeTemplateFiles(t, map[string]string{
+			"a.yaml": testTemplateFile,
+			"b.yaml": "name: B\nscript_type: cobol\nscript: x\n",
+		})
+
+		_, err := Import(context.Background(), repo, dir)
+		require.Error(t, err)
+		assert.Empty(t, repo.imported)
+	})
+}
@@ -156,6 +156,7 @@ type ProcessingSlot struct {
 // WorkerStats represents statistics for a single worker
 type WorkerStats struct {
 	WorkerID            string        `json:"worker_id"`
+	Capabilities        []string      `json:"capabilities,omitempty"`
 	IsRunning           bool          `json:"is_running"`
 	IsHealthy           bool          `json:"is_healthy"`
 	TasksProcessed      int64         `json:"tasks_processed"`
@@ -259,6 +260,7 @@ type WorkerConfig struct {
 	StaleTaskThreshold   time.Duration `json:"stale_task_threshold"`
 	EnableAutoScaling    bool          `json:"enable_auto_scaling"`
 	ScalingCheckInterval time.Duration `json:"scaling_check_interval"`
+	Capabilities         []string      `json:"capabilities,omitempty"`
 }
 
 // WorkerError represents a worker-specific error
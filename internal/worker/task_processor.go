@@ -67,10 +67,13 @@ func (p *BaseTaskProcessor) ProcessTask(ctx context.Context, message *queue.Task
 
 	// Get task from database
 	task, err := p.repos.Tasks.GetByID(ctx, message.TaskID)
+	if err == nil {
+		task, err = database.TaskAtRevision(ctx, p.repos.TaskRevisions, task, message.Revision())
+	}
 	if err != nil {
 		p.failedExecs++
-		if err == database.ErrTaskNotFound {
-			p.logger.Warn("task not found, skipping", "task_id", message.TaskID)
+		if err == database.ErrTaskNotFound || err == database.ErrTaskRevisionNotFound {
+			p.logger.Warn("task not found, skipping", "task_id", message.TaskID, "error", err)
 			return nil // Don't retry for non-existent tasks
 		}
 		return fmt.Errorf("failed to get task: %w", err)
@@ -174,11 +177,15 @@ func (p *BaseTaskProcessor) canProcessTask(task *models.Task) bool {
 
 // createExecution creates a new task execution record
 func (p *BaseTaskProcessor) createExecution(ctx context.Context, task *models.Task) (*models.TaskExecution, error) {
+	revision := task.Revision
 	execution := &models.TaskExecution{
 		ID:        models.NewID(),
 		TaskID:    task.ID,
 		Status:    models.ExecutionStatusPending,
 		StartedAt: new(time.Time),
+
+		SecurityLevel: task.SecurityLevel,
+		Revision:      &revision,
 	}
 	*execution.StartedAt = time.Now()
 
@@ -219,7 +226,8 @@ func (p *BaseTaskProcessor) executeTask(
 		p.logger.Error("task execution failed",
 			"task_id", task.ID,
 			"error", err)
-		return nil, fmt.Errorf("execution failed: %w", err)
+		// The result, if any, still describes how the execution failed
+		return result, fmt.Errorf("execution failed: %w", err)
 	}
 
 	p.logger.Debug("task execution completed",
@@ -240,12 +248,20 @@ func (p *BaseTaskProcessor) processResult(
 ) error {
 	now := time.Now()
 
+	// Connection attempts are kept whatever the outcome of the execution
+	if result != nil && len(result.NetworkEvents) > 0 && p.repos.NetworkEvents != nil {
+		if err := p.repos.NetworkEvents.CreateBatch(ctx, execution.ID, result.NetworkEvents); err != nil {
+			p.logger.Error("failed to record network events", "execution_id", execution.ID, "error", err)
+		}
+	}
+
 	if execErr != nil {
 		// Execution failed - update execution record
 		execution.Status = models.ExecutionStatusFailed
 		execution.CompletedAt = &now
 		stderr := execErr.Error()
 		execution.Stderr = &stderr
+		recordFailureDiagnostics(execution, result, execErr)
 
 		// Update execution in database
 		if err := p.repos.TaskExecutions.Update(ctx, execution); err != nil {
@@ -253,7 +269,11 @@ func (p *BaseTaskProcessor) processResult(
 		}
 
 		// Update task status
-		if err := p.repos.Tasks.UpdateStatus(ctx, task.ID, models.TaskStatusFailed); err != nil {
+		taskStatus := models.TaskStatusFailed
+		if execution.Status == models.ExecutionStatusTimeout {
+			taskStatus = models.TaskStatusTimeout
+		}
+		if err := p.repos.Tasks.UpdateStatus(ctx, task.ID, taskStatus); err != nil {
 			p.logger.Error("failed to update task status to failed", "error", err)
 		}
 
@@ -267,6 +287,12 @@ func (p *BaseTaskProcessor) processResult(
 	execution.Stderr = result.Stderr
 	execution.ExecutionTimeMs = result.ExecutionTimeMs
 	execution.MemoryUsageBytes = result.MemoryUsageBytes
+	execution.Runtime = result.Runtime
+	execution.Truncated = result.Truncated
+	execution.OOMKilled = result.OOMKilled
+	execution.ExitSignal = result.ExitSignal
+	execution.TimeoutPhase = result.TimeoutPhase
+	execution.ErrorCategory = result.ErrorCategory
 	execution.CompletedAt = &now
 
 	// Update execution in database
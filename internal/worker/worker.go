@@ -55,6 +55,7 @@ func NewWorker(
 	logger *slog.Logger,
 ) Worker {
 	workerID := fmt.Sprintf("%s-%s", config.WorkerIDPrefix, uuid.New().String()[:8])
+	config.Capabilities = models.NormalizeCapabilities(config.Capabilities)
 
 	return &BaseWorker{
 		id:          workerID,
@@ -67,9 +68,10 @@ func NewWorker(
 		shutdownCh:  make(chan struct{}),
 		isHealthy:   true,
 		stats: WorkerStats{
-			WorkerID:  workerID,
-			IsRunning: false,
-			IsHealthy: true,
+			WorkerID:     workerID,
+			Capabilities: config.Capabilities,
+			IsRunning:    false,
+			IsHealthy:    true,
 		},
 	}
 }
@@ -217,8 +219,14 @@ func (w *BaseWorker) processingLoop() {
 
 // processNextTask dequeues and processes a single task
 func (w *BaseWorker) processNextTask() error {
-	// Dequeue message
-	messages, err := w.queue.Dequeue(w.ctx, 1)
+	// Dequeue message, restricted to the capabilities this worker advertises
+	var messages []*queue.TaskMessage
+	var err error
+	if len(w.config.Capabilities) > 0 {
+		messages, err = w.queue.DequeueMatching(w.ctx, 1, w.config.Capabilities)
+	} else {
+		messages, err = w.queue.Dequeue(w.ctx, 1)
+	}
 	if err != nil {
 		return NewWorkerError(w.id, "dequeue", err, true)
 	}
@@ -268,6 +276,9 @@ func (w *BaseWorker) processTask(message *queue.TaskMessage) error {
 
 	// Get task from database
 	task, err := w.repos.Tasks.GetByID(w.ctx, message.TaskID)
+	if err == nil {
+		task, err = database.TaskAtRevision(w.ctx, w.repos.TaskRevisions, task, message.Revision())
+	}
 	if err != nil {
 		w.deleteMessage(message)
 		return NewWorkerError(w.id, "get_task", err, false)
@@ -324,7 +335,8 @@ func (w *BaseWorker) executeTask(task *models.Task, execution *models.TaskExecut
 	result, err := w.executor.Execute(w.ctx, execCtx)
 	if err != nil {
 		w.logger.Error("task execution failed", "task_id", task.ID, "error", err)
-		return nil, err
+		// The result, if any, still describes how the execution failed
+		return result, err
 	}
 
 	w.logger.Info("task execution completed",
@@ -337,11 +349,15 @@ func (w *BaseWorker) executeTask(task *models.Task, execution *models.TaskExecut
 
 // createExecution creates a new task execution record
 func (w *BaseWorker) createExecution(task *models.Task) (*models.TaskExecution, error) {
+	revision := task.Revision
 	execution := &models.TaskExecution{
 		ID:        models.NewID(),
 		TaskID:    task.ID,
 		Status:    models.ExecutionStatusPending,
 		StartedAt: new(time.Time),
+
+		SecurityLevel: task.SecurityLevel,
+		Revision:      &revision,
 	}
 	*execution.StartedAt = time.Now()
 
@@ -360,15 +376,29 @@ func (w *BaseWorker) processExecutionResult(
 	execErr error,
 	message *queue.TaskMessage,
 ) error {
+	// Connection attempts are kept whatever the outcome of the execution
+	w.recordNetworkEvents(execution, result)
+
 	if execErr != nil {
 		// Execution failed
-		return w.handleExecutionFailure(task, execution, execErr, message)
+		return w.handleExecutionFailure(task, execution, result, execErr, message)
 	}
 
 	// Execution succeeded, update records
 	return w.handleExecutionSuccess(task, execution, result)
 }
 
+// recordNetworkEvents stores the connection attempts made by the execution
+func (w *BaseWorker) recordNetworkEvents(execution *models.TaskExecution, result *executor.ExecutionResult) {
+	if result == nil || len(result.NetworkEvents) == 0 || w.repos.NetworkEvents == nil {
+		return
+	}
+
+	if err := w.repos.NetworkEvents.CreateBatch(w.ctx, execution.ID, result.NetworkEvents); err != nil {
+		w.logger.Error("failed to record network events", "execution_id", execution.ID, "error", err)
+	}
+}
+
 // handleExecutionSuccess handles successful task execution
 func (w *BaseWorker) handleExecutionSuccess(
 	task *models.Task,
@@ -383,6 +413,12 @@ func (w *BaseWorker) handleExecutionSuccess(
 	execution.Stderr = result.Stderr
 	execution.ExecutionTimeMs = result.ExecutionTimeMs
 	execution.MemoryUsageBytes = result.MemoryUsageBytes
+	execution.Runtime = result.Runtime
+	execution.Truncated = result.Truncated
+	execution.OOMKilled = result.OOMKilled
+	execution.ExitSignal = result.ExitSignal
+	execution.TimeoutPhase = result.TimeoutPhase
+	execution.ErrorCategory = result.ErrorCategory
 	execution.CompletedAt = &now
 
 	if err := w.repos.TaskExecutions.Update(w.ctx, execution); err != nil {
@@ -409,6 +445,7 @@ func (w *BaseWorker) handleExecutionSuccess(
 func (w *BaseWorker) handleExecutionFailure(
 	task *models.Task,
 	execution *models.TaskExecution,
+	result *executor.ExecutionResult,
 	execErr error,
 	message *queue.TaskMessage,
 ) error {
@@ -418,18 +455,28 @@ func (w *BaseWorker) handleExecutionFailure(
 	execution.CompletedAt = &now
 	stderr := execErr.Error()
 	execution.Stderr = &stderr
+	recordFailureDiagnostics(execution, result, execErr)
 
 	if err := w.repos.TaskExecutions.Update(w.ctx, execution); err != nil {
 		w.logger.Error("failed to update failed execution", "error", err)
 	}
 
 	// Update task status to failed
-	if err := w.updateTaskStatus(task.ID, models.TaskStatusFailed); err != nil {
+	taskStatus := models.TaskStatusFailed
+	if execution.Status == models.ExecutionStatusTimeout {
+		taskStatus = models.TaskStatusTimeout
+	}
+	if err := w.updateTaskStatus(task.ID, taskStatus); err != nil {
 		w.logger.Error("failed to update task status to failed", "error", err)
 	}
 
-	// Handle retry logic if needed
-	if message.Attempts < w.config.MaxRetryAttempts {
+	// Handle retry logic if needed. Failures of the task itself, such as
+	// script errors and OOM kills, would only fail again.
+	if !execution.IsRetryable() {
+		w.logger.Info("task failed permanently, not retrying",
+			"task_id", task.ID,
+			"error_category", *execution.ErrorCategory)
+	} else if message.Attempts < w.config.MaxRetryAttempts {
 		w.logger.Info("task will be retried",
 			"task_id", task.ID,
 			"attempt", message.Attempts,
@@ -440,6 +487,24 @@ func (w *BaseWorker) handleExecutionFailure(
 	return nil
 }
 
+// recordFailureDiagnostics stores why an execution that returned an error
+// failed, from its result when the executor produced one
+func recordFailureDiagnostics(execution *models.TaskExecution, result *executor.ExecutionResult, execErr error) {
+	if result != nil {
+		if result.Status == models.ExecutionStatusTimeout {
+			execution.Status = models.ExecutionStatusTimeout
+		}
+		execution.OOMKilled = result.OOMKilled
+		execution.ExitSignal = result.ExitSignal
+		execution.TimeoutPhase = result.TimeoutPhase
+		execution.ErrorCategory = result.ErrorCategory
+	}
+	if execution.ErrorCategory == nil {
+		category := executor.CategorizeError(execErr)
+		execution.ErrorCategory = &category
+	}
+}
+
 // updateTaskStatus updates the task status
 func (w *BaseWorker) updateTaskStatus(taskID uuid.UUID, status models.TaskStatus) error {
 	if err := w.repos.Tasks.UpdateStatus(w.ctx, taskID, status); err != nil {
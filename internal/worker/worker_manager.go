@@ -387,7 +387,9 @@ func (wm *BaseWorkerManager) registerDefaultProcessors() error {
 	)
 	wm.processorRegistry.RegisterProcessor(ProcessorTypeGeneral, generalProcessor)
 
-	// Register specialized processors (optional, for future optimization)
+	// Register specialized processors. Routing tasks to specialized worker pools
+	// happens at the queue via WorkerConfig.Capabilities; these only tailor
+	// processing once a worker has received a task.
 	processors := []struct {
 		Type ProcessorType
 		Name string
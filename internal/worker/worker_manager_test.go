@@ -93,6 +93,11 @@ func (m *MockTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*queue.
 	return args.Get(0).([]*queue.TaskMessage), args.Error(1)
 }
 
+func (m *MockTaskQueue) DequeueMatching(ctx context.Context, maxMessages int, capabilities []string) ([]*queue.TaskMessage, error) {
+	args := m.Called(ctx, maxMessages, capabilities)
+	return args.Get(0).([]*queue.TaskMessage), args.Error(1)
+}
+
 func (m *MockTaskQueue) DeleteMessage(ctx context.Context, receiptHandle string) error {
 	args := m.Called(ctx, receiptHandle)
 	return args.Error(0)
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/voidrunnerhq/voidrunner/internal/executor"
+	"github.com/voidrunnerhq/voidrunner/internal/models"
+	"github.com/voidrunnerhq/voidrunner/internal/queue"
+)
+
+func TestBaseWorker_ProcessNextTask_CapabilityRouting(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities []string
+		expectCall   string
+		expectArgs   []interface{}
+	}{
+		{
+			name:         "worker without capabilities dequeues unrestricted work",
+			capabilities: nil,
+			expectCall:   "Dequeue",
+			expectArgs:   []interface{}{mock.Anything, 1},
+		},
+		{
+			name:         "worker with capabilities dequeues matching work",
+			capabilities: []string{"Script:Python", "memory:large"},
+			expectCall:   "DequeueMatching",
+			expectArgs:   []interface{}{mock.Anything, 1, []string{"memory:large", "script:python"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskQueue := &MockTaskQueue{}
+			taskQueue.On(tt.expectCall, tt.expectArgs...).Return([]*queue.TaskMessage{}, nil).Once()
+
+			w := NewWorker(taskQueue, nil, nil, nil, WorkerConfig{
+				WorkerIDPrefix: "test-worker",
+				Capabilities:   tt.capabilities,
+			}, slog.Default()).(*BaseWorker)
+
+			// A cancelled context skips the idle wait when the queue is empty
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			w.ctx = ctx
+
+			require.NoError(t, w.processNextTask())
+			taskQueue.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewWorker_AdvertisesCapabilities(t *testing.T) {
+	w := NewWorker(&MockTaskQueue{}, nil, nil, nil, WorkerConfig{
+		WorkerIDPrefix: "test-worker",
+		Capabilities:   []string{"gpu", "script:python", "GPU"},
+	}, slog.Default())
+
+	assert.Equal(t, []string{"gpu", "script:python"}, w.GetStats().Capabilities)
+}
+
+func TestRecordFailureDiagnostics(t *testing.T) {
+	t.Run("keeps the executor's diagnostics", func(t *testing.T) {
+		phase := models.TimeoutPhaseImagePull
+		category := models.ErrorCategoryTimeout
+		execution := &models.TaskExecution{Status: models.ExecutionStatusFailed}
+
+		recordFailureDiagnostics(execution, &executor.ExecutionResult{
+			Status:        models.ExecutionStatusTimeout,
+			TimeoutPhase:  &phase,
+			ErrorCategory: &category,
+		}, executor.ErrExecutionTimeout)
+
+		assert.Equal(t, models.ExecutionStatusTimeout, execution.Status)
+		assert.Equal(t, &phase, execution.TimeoutPhase)
+		assert.Equal(t, &category, execution.ErrorCategory)
+		assert.True(t, execution.IsRetryable())
+	})
+
+	t.Run("categorizes the error without a result", func(t *testing.T) {
+		execution := &models.TaskExecution{Status: models.ExecutionStatusFailed}
+
+		recordFailureDiagnostics(execution, nil, executor.NewExecutorError("execute", "failed", executor.ErrDockerUnavailable))
+
+		assert.Equal(t, models.ExecutionStatusFailed, execution.Status)
+		require.NotNil(t, execution.ErrorCategory)
+		assert.Equal(t, models.ErrorCategoryRuntime, *execution.ErrorCategory)
+		assert.True(t, execution.IsRetryable())
+	})
+}
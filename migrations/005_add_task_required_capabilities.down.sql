@@ -0,0 +1,3 @@
+-- Remove required capabilities from tasks table
+DROP INDEX IF EXISTS idx_tasks_required_capabilities_gin;
+ALTER TABLE tasks DROP COLUMN IF EXISTS required_capabilities;
\ No newline at end of file
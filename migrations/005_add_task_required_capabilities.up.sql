@@ -0,0 +1,5 @@
+-- Add required capabilities to tasks so they can be routed to matching workers
+ALTER TABLE tasks ADD COLUMN required_capabilities TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create index for capability lookups
+CREATE INDEX idx_tasks_required_capabilities_gin ON tasks USING GIN(required_capabilities);
\ No newline at end of file
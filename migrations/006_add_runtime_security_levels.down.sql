@@ -0,0 +1,4 @@
+-- Remove runtime and security level columns
+ALTER TABLE task_executions DROP COLUMN IF EXISTS runtime;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS security_level;
+ALTER TABLE tasks DROP COLUMN IF EXISTS security_level;
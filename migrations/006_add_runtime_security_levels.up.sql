@@ -0,0 +1,8 @@
+-- Add security level to tasks so untrusted code can be routed to a hardened OCI runtime
+ALTER TABLE tasks ADD COLUMN security_level TEXT NOT NULL DEFAULT 'standard'
+    CHECK (security_level IN ('standard', 'sandboxed', 'isolated'));
+
+-- Record the security level and OCI runtime used for each execution for audit
+ALTER TABLE task_executions ADD COLUMN security_level TEXT NOT NULL DEFAULT 'standard'
+    CHECK (security_level IN ('standard', 'sandboxed', 'isolated'));
+ALTER TABLE task_executions ADD COLUMN runtime TEXT;
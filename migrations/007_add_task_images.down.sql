@@ -0,0 +1,4 @@
+-- Remove custom images from tasks table
+DROP INDEX IF EXISTS idx_tasks_user_image;
+ALTER TABLE tasks DROP COLUMN IF EXISTS image_digest;
+ALTER TABLE tasks DROP COLUMN IF EXISTS image;
@@ -0,0 +1,6 @@
+-- Allow tasks to run in a custom image, pinned to the digest it resolved to when saved
+ALTER TABLE tasks ADD COLUMN image TEXT;
+ALTER TABLE tasks ADD COLUMN image_digest TEXT;
+
+-- Create index for the image inventory
+CREATE INDEX idx_tasks_user_image ON tasks(user_id, image, image_digest) WHERE image IS NOT NULL;
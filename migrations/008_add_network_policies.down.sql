@@ -0,0 +1,4 @@
+-- Remove network egress policies
+DROP TABLE IF EXISTS execution_network_events;
+ALTER TABLE tasks DROP COLUMN IF EXISTS network_allowlist;
+ALTER TABLE tasks DROP COLUMN IF EXISTS network_mode;
@@ -0,0 +1,18 @@
+-- Add per-task network egress policy
+ALTER TABLE tasks ADD COLUMN network_mode TEXT NOT NULL DEFAULT 'none'
+    CHECK (network_mode IN ('none', 'internal', 'allowlist'));
+ALTER TABLE tasks ADD COLUMN network_allowlist TEXT[] NOT NULL DEFAULT '{}';
+
+-- Record every connection attempt made by an execution through the egress proxy
+CREATE TABLE execution_network_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    execution_id UUID NOT NULL REFERENCES task_executions(id) ON DELETE CASCADE,
+    host TEXT NOT NULL,
+    port INTEGER NOT NULL DEFAULT 0 CHECK (port >= 0 AND port <= 65535),
+    allowed BOOLEAN NOT NULL,
+    reason TEXT,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index for listing an execution's events in order
+CREATE INDEX idx_execution_network_events_execution_id ON execution_network_events(execution_id, occurred_at);
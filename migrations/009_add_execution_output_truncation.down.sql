@@ -0,0 +1,2 @@
+-- Remove execution output truncation flag
+ALTER TABLE task_executions DROP COLUMN IF EXISTS truncated;
@@ -0,0 +1,2 @@
+-- Record whether an execution's output exceeded the output limits
+ALTER TABLE task_executions ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT FALSE;
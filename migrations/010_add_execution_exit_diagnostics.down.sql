@@ -0,0 +1,5 @@
+-- Remove execution exit diagnostics
+ALTER TABLE task_executions DROP COLUMN IF EXISTS error_category;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS timeout_phase;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS exit_signal;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS oom_killed;
@@ -0,0 +1,6 @@
+-- Record how an execution ended, so an OOM kill can be told apart from a script error
+ALTER TABLE task_executions ADD COLUMN oom_killed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE task_executions ADD COLUMN exit_signal TEXT;
+ALTER TABLE task_executions ADD COLUMN timeout_phase TEXT
+    CHECK (timeout_phase IN ('image_pull', 'start', 'run'));
+ALTER TABLE task_executions ADD COLUMN error_category TEXT;
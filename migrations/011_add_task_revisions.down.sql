@@ -0,0 +1,4 @@
+-- Remove task revisions
+ALTER TABLE task_executions DROP COLUMN IF EXISTS revision;
+DROP TABLE IF EXISTS task_revisions;
+ALTER TABLE tasks DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,18 @@
+-- Keep every version of a task's script so executions can be tied to the code they ran
+ALTER TABLE tasks ADD COLUMN revision INTEGER NOT NULL DEFAULT 1 CHECK (revision > 0);
+
+CREATE TABLE IF NOT EXISTS task_revisions (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL CHECK (revision > 0),
+    script_content TEXT NOT NULL,
+    script_type VARCHAR(50) NOT NULL CHECK (script_type IN ('python', 'javascript', 'bash', 'go')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (task_id, revision)
+);
+
+-- The current script of existing tasks becomes their first revision
+INSERT INTO task_revisions (task_id, revision, script_content, script_type, created_at)
+SELECT id, revision, script_content, script_type, updated_at FROM tasks;
+
+-- Executions created before revisions were tracked have no revision
+ALTER TABLE task_executions ADD COLUMN revision INTEGER;
@@ -0,0 +1,3 @@
+-- Remove task templates
+DROP TRIGGER IF EXISTS update_task_templates_updated_at ON task_templates;
+DROP TABLE IF EXISTS task_templates;
@@ -0,0 +1,40 @@
+-- Create task_templates table for parameterized tasks shared between users
+CREATE TABLE task_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Templates imported from the template directory have no owner
+    owner_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    script_type VARCHAR(50) NOT NULL,
+    script_template TEXT NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '[]',
+    visibility VARCHAR(20) NOT NULL DEFAULT 'private',
+    team VARCHAR(255),
+    priority INTEGER NOT NULL DEFAULT 5,
+    timeout_seconds INTEGER NOT NULL DEFAULT 30,
+    security_level TEXT NOT NULL DEFAULT 'standard',
+    required_capabilities TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    -- Constraints
+    CONSTRAINT chk_template_script_type CHECK (script_type IN ('python', 'javascript', 'bash', 'go')),
+    CONSTRAINT chk_template_visibility CHECK (visibility IN ('private', 'team', 'global')),
+    CONSTRAINT chk_template_team CHECK ((visibility = 'team') = (team IS NOT NULL)),
+    CONSTRAINT chk_template_private_owner CHECK (visibility <> 'private' OR owner_id IS NOT NULL),
+    CONSTRAINT chk_template_priority CHECK (priority >= 0 AND priority <= 10),
+    CONSTRAINT chk_template_timeout CHECK (timeout_seconds > 0 AND timeout_seconds <= 3600),
+    CONSTRAINT chk_template_security_level CHECK (security_level IN ('standard', 'sandboxed', 'isolated'))
+);
+
+-- Template names are unique per owner, and among imported templates
+CREATE UNIQUE INDEX idx_task_templates_owner_name ON task_templates(owner_id, name) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_task_templates_imported_name ON task_templates(name) WHERE owner_id IS NULL;
+
+-- Create indexes for listing the templates visible to a user
+CREATE INDEX idx_task_templates_visibility_team ON task_templates(visibility, team);
+
+CREATE TRIGGER update_task_templates_updated_at
+    BEFORE UPDATE ON task_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Remove external keys from tasks table
+DROP INDEX IF EXISTS idx_tasks_user_external_key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS external_key;
@@ -0,0 +1,6 @@
+-- Let manifests identify the tasks they manage by a stable, user-chosen key
+ALTER TABLE tasks ADD COLUMN external_key VARCHAR(255)
+    CHECK (external_key ~ '^[A-Za-z0-9][A-Za-z0-9._/-]{0,254}$');
+
+-- Keys are unique per user
+CREATE UNIQUE INDEX idx_tasks_user_external_key ON tasks(user_id, external_key) WHERE external_key IS NOT NULL;
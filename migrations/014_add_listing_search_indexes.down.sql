@@ -0,0 +1,4 @@
+-- Remove listing search indexes
+DROP INDEX IF EXISTS idx_executions_duration;
+DROP INDEX IF EXISTS idx_executions_return_code_created;
+DROP INDEX IF EXISTS idx_tasks_name_search;
@@ -0,0 +1,6 @@
+-- Full-text search on task names, matching the expression used by task listings
+CREATE INDEX idx_tasks_name_search ON tasks USING GIN(to_tsvector('simple', name));
+
+-- Filtering executions by exit code and duration
+CREATE INDEX idx_executions_return_code_created ON task_executions(return_code, created_at DESC) WHERE return_code IS NOT NULL;
+CREATE INDEX idx_executions_duration ON task_executions(execution_time_ms) WHERE execution_time_ms IS NOT NULL;
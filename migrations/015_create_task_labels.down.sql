@@ -0,0 +1,2 @@
+-- Remove task labels
+DROP TABLE IF EXISTS task_labels;
@@ -0,0 +1,10 @@
+-- First-class key/value labels on tasks, selected by label selectors
+CREATE TABLE task_labels (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    key VARCHAR(317) NOT NULL,
+    value VARCHAR(63) NOT NULL DEFAULT '',
+    PRIMARY KEY (task_id, key)
+);
+
+-- Selector requirements look tasks up by key, or by key and value
+CREATE INDEX idx_task_labels_key_value ON task_labels(key, value);
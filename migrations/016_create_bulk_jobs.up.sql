@@ -0,0 +1,35 @@
+-- Asynchronous bulk operations on tasks and executions, with their progress
+-- and the result of each item
+CREATE TABLE bulk_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    action VARCHAR(20),
+    filter TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    results JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    -- Constraints
+    CONSTRAINT chk_bulk_job_kind CHECK (kind IN ('batch', 'bulk')),
+    CONSTRAINT chk_bulk_job_action CHECK (action IS NULL OR action IN ('delete', 'execute', 'cancel')),
+    CONSTRAINT chk_bulk_job_status CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    CONSTRAINT chk_bulk_job_progress CHECK (processed >= 0 AND processed <= total AND succeeded + failed = processed)
+);
+
+-- Jobs are listed per user, newest first, and unfinished jobs are failed on startup
+CREATE INDEX idx_bulk_jobs_user_created ON bulk_jobs(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_bulk_jobs_unfinished ON bulk_jobs(status) WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER update_bulk_jobs_updated_at
+    BEFORE UPDATE ON bulk_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Remove the versions of tasks and executions
+ALTER TABLE task_executions DROP COLUMN IF EXISTS version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every write to a task or execution increments its
+-- version, and updates only apply to the version they were read at
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1 CHECK (version > 0);
+ALTER TABLE task_executions ADD COLUMN version INTEGER NOT NULL DEFAULT 1 CHECK (version > 0);
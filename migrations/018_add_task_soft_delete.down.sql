@@ -0,0 +1,8 @@
+-- Purge the trash and remove soft delete
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tasks_user_external_key;
+CREATE UNIQUE INDEX idx_tasks_user_external_key ON tasks(user_id, external_key) WHERE external_key IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tasks_deleted;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
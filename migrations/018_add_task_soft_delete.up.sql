@@ -0,0 +1,11 @@
+-- Deleted tasks are kept in the trash, with their executions, until they are
+-- restored or purged
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- The trash is listed per user and purged oldest first
+CREATE INDEX idx_tasks_deleted ON tasks(deleted_at, user_id) WHERE deleted_at IS NOT NULL;
+
+-- Keys only need to be unique among the tasks that aren't in the trash, so a
+-- manifest can recreate a task it pruned
+DROP INDEX idx_tasks_user_external_key;
+CREATE UNIQUE INDEX idx_tasks_user_external_key ON tasks(user_id, external_key) WHERE external_key IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- Remove the output expiry of executions
+DROP INDEX IF EXISTS idx_executions_output_kept;
+ALTER TABLE task_executions DROP COLUMN IF EXISTS output_expired_at;
@@ -0,0 +1,6 @@
+-- Execution retention: the output of old executions is dropped while their
+-- summary is kept, recording when
+ALTER TABLE task_executions ADD COLUMN output_expired_at TIMESTAMPTZ;
+
+-- Retention finds the oldest executions whose output is still kept
+CREATE INDEX idx_executions_output_kept ON task_executions(created_at) WHERE output_expired_at IS NULL;
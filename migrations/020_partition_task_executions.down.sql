@@ -0,0 +1,33 @@
+-- Move executions back to an unpartitioned table
+ALTER TABLE execution_network_events DROP CONSTRAINT IF EXISTS execution_network_events_execution_fkey;
+DROP INDEX IF EXISTS idx_execution_network_events_execution_created;
+ALTER TABLE execution_network_events DROP COLUMN IF EXISTS execution_created_at;
+
+ALTER TABLE task_executions RENAME TO task_executions_partitioned;
+ALTER TABLE task_executions_partitioned RENAME CONSTRAINT task_executions_pkey TO task_executions_partitioned_pkey;
+
+CREATE TABLE task_executions (
+    LIKE task_executions_partitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS,
+    PRIMARY KEY (id),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+INSERT INTO task_executions SELECT * FROM task_executions_partitioned;
+DROP TABLE task_executions_partitioned;
+DROP FUNCTION IF EXISTS create_task_executions_partition(DATE);
+
+CREATE INDEX idx_executions_task_created ON task_executions(task_id, created_at DESC);
+CREATE INDEX idx_executions_status_created ON task_executions(status, created_at DESC);
+CREATE INDEX idx_executions_task_created_cursor ON task_executions(task_id, created_at DESC, id);
+CREATE INDEX idx_executions_status_created_cursor ON task_executions(status, created_at DESC, id);
+CREATE INDEX idx_executions_status_started ON task_executions(status, started_at DESC) WHERE started_at IS NOT NULL;
+CREATE INDEX idx_executions_list_covering ON task_executions(task_id, created_at DESC)
+INCLUDE (status, return_code, execution_time_ms);
+CREATE INDEX idx_executions_active_status ON task_executions(status, created_at DESC)
+WHERE status IN ('pending', 'running');
+CREATE INDEX idx_executions_return_code_created ON task_executions(return_code, created_at DESC) WHERE return_code IS NOT NULL;
+CREATE INDEX idx_executions_duration ON task_executions(execution_time_ms) WHERE execution_time_ms IS NOT NULL;
+CREATE INDEX idx_executions_output_kept ON task_executions(created_at) WHERE output_expired_at IS NULL;
+
+ALTER TABLE execution_network_events ADD CONSTRAINT execution_network_events_execution_id_fkey
+    FOREIGN KEY (execution_id) REFERENCES task_executions(id) ON DELETE CASCADE;
@@ -0,0 +1,83 @@
+-- Partition executions by month of creation, so writes and the cursor indexes
+-- only touch the recent partitions and expired executions are dropped a
+-- partition at a time. The primary key of a partitioned table must include
+-- the partition key, so execution IDs, which are random UUIDs, are only
+-- enforced unique within a month.
+
+-- Creates the partition of the month of the given day, returning whether it
+-- was created. Partitions are named task_executions_pYYYYMM and bounded in UTC.
+CREATE OR REPLACE FUNCTION create_task_executions_partition(month DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    start_at TIMESTAMP := date_trunc('month', month::timestamp);
+    partition_name TEXT := 'task_executions_p' || to_char(start_at, 'YYYYMM');
+BEGIN
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    EXECUTE format(
+        'CREATE TABLE %I PARTITION OF task_executions FOR VALUES FROM (%L) TO (%L)',
+        partition_name,
+        start_at AT TIME ZONE 'UTC',
+        (start_at + INTERVAL '1 month') AT TIME ZONE 'UTC'
+    );
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE task_executions RENAME TO task_executions_unpartitioned;
+ALTER TABLE task_executions_unpartitioned RENAME CONSTRAINT task_executions_pkey TO task_executions_unpartitioned_pkey;
+ALTER TABLE execution_network_events DROP CONSTRAINT execution_network_events_execution_id_fkey;
+
+UPDATE task_executions_unpartitioned SET created_at = COALESCE(started_at, NOW()) WHERE created_at IS NULL;
+
+CREATE TABLE task_executions (
+    LIKE task_executions_unpartitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS,
+    PRIMARY KEY (id, created_at),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+) PARTITION BY RANGE (created_at);
+
+-- Executions outside every monthly partition, e.g. when future partitions
+-- weren't created in time, land in the default partition
+CREATE TABLE task_executions_default PARTITION OF task_executions DEFAULT;
+
+-- Partitions from the month of the oldest execution to three months ahead
+DO $$
+DECLARE
+    month DATE := date_trunc('month', LEAST(
+        (SELECT MIN(created_at) FROM task_executions_unpartitioned),
+        NOW()
+    ) AT TIME ZONE 'UTC');
+BEGIN
+    WHILE month <= (NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months' LOOP
+        PERFORM create_task_executions_partition(month);
+        month := month + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+INSERT INTO task_executions SELECT * FROM task_executions_unpartitioned;
+DROP TABLE task_executions_unpartitioned;
+
+-- Indexes of the unpartitioned table, created on every partition
+CREATE INDEX idx_executions_task_created ON task_executions(task_id, created_at DESC);
+CREATE INDEX idx_executions_status_created ON task_executions(status, created_at DESC);
+CREATE INDEX idx_executions_task_created_cursor ON task_executions(task_id, created_at DESC, id);
+CREATE INDEX idx_executions_status_created_cursor ON task_executions(status, created_at DESC, id);
+CREATE INDEX idx_executions_status_started ON task_executions(status, started_at DESC) WHERE started_at IS NOT NULL;
+CREATE INDEX idx_executions_list_covering ON task_executions(task_id, created_at DESC)
+INCLUDE (status, return_code, execution_time_ms);
+CREATE INDEX idx_executions_active_status ON task_executions(status, created_at DESC)
+WHERE status IN ('pending', 'running');
+CREATE INDEX idx_executions_return_code_created ON task_executions(return_code, created_at DESC) WHERE return_code IS NOT NULL;
+CREATE INDEX idx_executions_duration ON task_executions(execution_time_ms) WHERE execution_time_ms IS NOT NULL;
+CREATE INDEX idx_executions_output_kept ON task_executions(created_at) WHERE output_expired_at IS NULL;
+
+-- Network events reference the execution by its full primary key
+ALTER TABLE execution_network_events ADD COLUMN execution_created_at TIMESTAMP WITH TIME ZONE;
+UPDATE execution_network_events e SET execution_created_at = x.created_at
+FROM task_executions x WHERE x.id = e.execution_id;
+ALTER TABLE execution_network_events ALTER COLUMN execution_created_at SET NOT NULL;
+ALTER TABLE execution_network_events ADD CONSTRAINT execution_network_events_execution_fkey
+    FOREIGN KEY (execution_id, execution_created_at) REFERENCES task_executions(id, created_at) ON DELETE CASCADE;
+CREATE INDEX idx_execution_network_events_execution_created ON execution_network_events(execution_created_at);
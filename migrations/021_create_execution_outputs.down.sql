@@ -0,0 +1,16 @@
+-- Move execution output back inline
+ALTER TABLE task_executions ADD COLUMN stdout TEXT;
+ALTER TABLE task_executions ADD COLUMN stderr TEXT;
+
+UPDATE task_executions e
+SET stdout = (
+        SELECT convert_from(string_agg(o.data, ''::bytea ORDER BY o.seq), 'UTF8')
+        FROM execution_outputs o WHERE o.execution_id = e.id AND o.stream = 'stdout'
+    ),
+    stderr = (
+        SELECT convert_from(string_agg(o.data, ''::bytea ORDER BY o.seq), 'UTF8')
+        FROM execution_outputs o WHERE o.execution_id = e.id AND o.stream = 'stderr'
+    )
+WHERE EXISTS (SELECT 1 FROM execution_outputs o WHERE o.execution_id = e.id);
+
+DROP TABLE IF EXISTS execution_outputs;
@@ -0,0 +1,29 @@
+-- Store execution output apart from the executions, so listings and the
+-- covering indexes never touch it. Each stream is stored as chunks in
+-- sequence order; a chunk's offset is the position of its first byte in the
+-- stream, so output can be appended while an execution runs and read by range.
+CREATE TABLE execution_outputs (
+    execution_id UUID NOT NULL,
+    execution_created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    stream TEXT NOT NULL CHECK (stream IN ('stdout', 'stderr')),
+    seq INTEGER NOT NULL CHECK (seq >= 0),
+    byte_offset BIGINT NOT NULL CHECK (byte_offset >= 0),
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (execution_id, stream, seq),
+    FOREIGN KEY (execution_id, execution_created_at) REFERENCES task_executions(id, created_at) ON DELETE CASCADE
+);
+
+-- Dropping a partition of executions first deletes the output of its month
+CREATE INDEX idx_execution_outputs_execution_created ON execution_outputs(execution_created_at);
+
+INSERT INTO execution_outputs (execution_id, execution_created_at, stream, seq, byte_offset, data)
+SELECT id, created_at, 'stdout', 0, 0, convert_to(stdout, 'UTF8')
+FROM task_executions WHERE stdout IS NOT NULL AND stdout <> '';
+
+INSERT INTO execution_outputs (execution_id, execution_created_at, stream, seq, byte_offset, data)
+SELECT id, created_at, 'stderr', 0, 0, convert_to(stderr, 'UTF8')
+FROM task_executions WHERE stderr IS NOT NULL AND stderr <> '';
+
+ALTER TABLE task_executions DROP COLUMN stdout;
+ALTER TABLE task_executions DROP COLUMN stderr;
@@ -0,0 +1,2 @@
+-- Remove the lease binding of executions
+ALTER TABLE task_executions DROP COLUMN IF EXISTS lease_token_hash;
@@ -0,0 +1,5 @@
+-- Bind the lease a runner holds on an execution to that execution. The
+-- SHA-256 of the lease token is stored when the job is leased, and runner
+-- calls for the execution must present the token that hashes to it.
+-- Executions leased before this migration hold no lease until leased again.
+ALTER TABLE task_executions ADD COLUMN lease_token_hash BYTEA;
@@ -0,0 +1,20 @@
+-- Restore the partition function that leaves the default partition alone
+CREATE OR REPLACE FUNCTION create_task_executions_partition(month DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    start_at TIMESTAMP := date_trunc('month', month::timestamp);
+    partition_name TEXT := 'task_executions_p' || to_char(start_at, 'YYYYMM');
+BEGIN
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    EXECUTE format(
+        'CREATE TABLE %I PARTITION OF task_executions FOR VALUES FROM (%L) TO (%L)',
+        partition_name,
+        start_at AT TIME ZONE 'UTC',
+        (start_at + INTERVAL '1 month') AT TIME ZONE 'UTC'
+    );
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,67 @@
+-- Creates the partition of the month of the given day, returning whether it
+-- was created. Partitions are named task_executions_pYYYYMM and bounded in UTC.
+--
+-- Executions of the month that landed in the default partition, because the
+-- partition wasn't created in time, are moved into the new partition: it
+-- can't be created while the default partition holds rows of its range. The
+-- network events and output of those executions reference them and are
+-- deleted with them, so they are set aside during the move and put back.
+-- Everything happens in the transaction of the caller, so either the
+-- partition is created with its executions or nothing changes.
+CREATE OR REPLACE FUNCTION create_task_executions_partition(month DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    start_at TIMESTAMP := date_trunc('month', month::timestamp);
+    partition_name TEXT := 'task_executions_p' || to_char(start_at, 'YYYYMM');
+    from_at TIMESTAMPTZ := start_at AT TIME ZONE 'UTC';
+    to_at TIMESTAMPTZ := (start_at + INTERVAL '1 month') AT TIME ZONE 'UTC';
+BEGIN
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    -- Attaching the partition locks the default partition anyway; lock it
+    -- first so no execution of the month is added to it during the move
+    LOCK TABLE task_executions_default IN ACCESS EXCLUSIVE MODE;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM task_executions_default WHERE created_at >= from_at AND created_at < to_at
+    ) THEN
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF task_executions FOR VALUES FROM (%L) TO (%L)',
+            partition_name, from_at, to_at
+        );
+        RETURN TRUE;
+    END IF;
+
+    EXECUTE format(
+        'CREATE TABLE %I (LIKE task_executions INCLUDING DEFAULTS INCLUDING CONSTRAINTS)',
+        partition_name
+    );
+    EXECUTE format(
+        'INSERT INTO %I SELECT * FROM task_executions_default WHERE created_at >= $1 AND created_at < $2',
+        partition_name
+    ) USING from_at, to_at;
+
+    CREATE TEMPORARY TABLE moved_execution_network_events ON COMMIT DROP AS
+        SELECT * FROM execution_network_events
+        WHERE execution_created_at >= from_at AND execution_created_at < to_at;
+    CREATE TEMPORARY TABLE moved_execution_outputs ON COMMIT DROP AS
+        SELECT * FROM execution_outputs
+        WHERE execution_created_at >= from_at AND execution_created_at < to_at;
+
+    DELETE FROM task_executions_default WHERE created_at >= from_at AND created_at < to_at;
+
+    EXECUTE format(
+        'ALTER TABLE task_executions ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+        partition_name, from_at, to_at
+    );
+
+    INSERT INTO execution_network_events SELECT * FROM moved_execution_network_events;
+    INSERT INTO execution_outputs SELECT * FROM moved_execution_outputs;
+    DROP TABLE moved_execution_network_events;
+    DROP TABLE moved_execution_outputs;
+
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,5 @@
+-- Remove the heartbeat of bulk jobs
+DROP INDEX IF EXISTS idx_bulk_jobs_unfinished_heartbeat;
+CREATE INDEX idx_bulk_jobs_unfinished ON bulk_jobs(status) WHERE status IN ('pending', 'running');
+
+ALTER TABLE bulk_jobs DROP COLUMN IF EXISTS heartbeat_at;
@@ -0,0 +1,7 @@
+-- Track when the server performing a bulk job last reported it alive. Jobs
+-- whose heartbeat expires were left behind by a server that stopped, and are
+-- failed by the servers still running; jobs of the other servers are kept.
+ALTER TABLE bulk_jobs ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+DROP INDEX IF EXISTS idx_bulk_jobs_unfinished;
+CREATE INDEX idx_bulk_jobs_unfinished_heartbeat ON bulk_jobs(heartbeat_at) WHERE status IN ('pending', 'running');
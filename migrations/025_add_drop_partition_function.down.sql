@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS drop_task_executions_partition(DATE);
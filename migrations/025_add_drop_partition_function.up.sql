@@ -0,0 +1,26 @@
+-- Drops the partition of the month of the given day, with the network events
+-- and output of its executions, returning the IDs of its executions whose
+-- output was truncated. Their full output was spilled outside the database,
+-- for the caller to remove once the partition is gone. The IDs are read in
+-- the transaction of the caller before the partition is detached, so
+-- executions added up to the drop are included.
+CREATE OR REPLACE FUNCTION drop_task_executions_partition(month DATE)
+RETURNS SETOF UUID AS $$
+DECLARE
+    start_at TIMESTAMP := date_trunc('month', month::timestamp);
+    partition_name TEXT := 'task_executions_p' || to_char(start_at, 'YYYYMM');
+    from_at TIMESTAMPTZ := start_at AT TIME ZONE 'UTC';
+    to_at TIMESTAMPTZ := (start_at + INTERVAL '1 month') AT TIME ZONE 'UTC';
+BEGIN
+    -- Block new executions of the month until the partition is gone
+    EXECUTE format('LOCK TABLE %I IN ACCESS EXCLUSIVE MODE', partition_name);
+
+    RETURN QUERY EXECUTE format('SELECT id FROM %I WHERE truncated', partition_name);
+
+    DELETE FROM execution_network_events WHERE execution_created_at >= from_at AND execution_created_at < to_at;
+    DELETE FROM execution_outputs WHERE execution_created_at >= from_at AND execution_created_at < to_at;
+
+    EXECUTE format('ALTER TABLE task_executions DETACH PARTITION %I', partition_name);
+    EXECUTE format('DROP TABLE %I', partition_name);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,9 @@
+-- Record the volume the full output of truncated executions is spilled to.
+-- Every process spilling or serving output must use this volume, as spill
+-- files are only readable where they were written; a process whose spill
+-- directory holds another volume ID refuses to start.
+CREATE TABLE output_spill_volume (
+    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+    volume_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
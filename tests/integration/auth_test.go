@@ -72,6 +72,9 @@ func (m *mockTaskQueue) Enqueue(ctx context.Context, message *queue.TaskMessage)
 func (m *mockTaskQueue) Dequeue(ctx context.Context, maxMessages int) ([]*queue.TaskMessage, error) {
 	return []*queue.TaskMessage{}, nil
 }
+func (m *mockTaskQueue) DequeueMatching(ctx context.Context, maxMessages int, capabilities []string) ([]*queue.TaskMessage, error) {
+	return []*queue.TaskMessage{}, nil
+}
 func (m *mockTaskQueue) DeleteMessage(ctx context.Context, receiptHandle string) error {
 	return nil
 }
@@ -152,7 +155,7 @@ func (s *AuthIntegrationSuite) SetupSuite() {
 	taskExecutionService := services.NewTaskExecutionService(s.DB.DB, queueManager, log.Logger)
 	var taskExecutorService *services.TaskExecutorService // nil is fine for auth tests
 	workerManager := &mockWorkerManager{}
-	routes.Setup(router, s.Config, log, s.DB.DB, s.DB.Repositories, s.AuthService, taskExecutionService, taskExecutorService, workerManager)
+	routes.Setup(router, s.Config, log, s.DB.DB, s.DB.Repositories, s.AuthService, taskExecutionService, taskExecutorService, workerManager, nil, nil, nil, nil)
 
 	// Initialize helpers
 	s.HTTP = testutil.NewHTTPHelper(router, s.AuthService)
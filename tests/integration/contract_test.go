@@ -64,7 +64,7 @@ func (s *ContractTestSuite) SetupSuite() {
 	taskExecutionService := services.NewTaskExecutionService(s.db, queueManager, log.Logger)
 	var taskExecutorService *services.TaskExecutorService // nil is fine for contract tests
 	workerManager := &mockWorkerManager{}
-	routes.Setup(s.router, cfg, log, s.db, s.repos, s.authService, taskExecutionService, taskExecutorService, workerManager)
+	routes.Setup(s.router, cfg, log, s.db, s.repos, s.authService, taskExecutionService, taskExecutorService, workerManager, nil, nil, nil, nil)
 
 	// Initialize OpenAPI validator
 	s.validator = testutil.NewOpenAPIValidator()
@@ -281,7 +281,7 @@ func (s *ContractTestSuite) TestTaskEndpointsContract() {
 		assert.NotEmpty(s.T(), taskResponse.CreatedAt)
 
 		// Cleanup
-		s.repos.Tasks.Delete(context.Background(), taskResponse.ID)
+		s.repos.Tasks.Delete(context.Background(), taskResponse.ID, taskResponse.Version)
 	})
 
 	s.Run("list tasks endpoint contract", func() {
@@ -328,7 +328,7 @@ func (s *ContractTestSuite) TestTaskEndpointsContract() {
 		}
 
 		// Cleanup
-		s.repos.Tasks.Delete(context.Background(), createdTask.ID)
+		s.repos.Tasks.Delete(context.Background(), createdTask.ID, createdTask.Version)
 	})
 }
 
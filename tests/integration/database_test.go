@@ -5,12 +5,15 @@ package integration_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/voidrunnerhq/voidrunner/internal/database"
 	"github.com/voidrunnerhq/voidrunner/internal/models"
 	"github.com/voidrunnerhq/voidrunner/tests/testutil"
 )
@@ -150,11 +153,267 @@ func (s *DatabaseIntegrationSuite) TestTaskLifecycle() {
 
 		finalExecution, err := s.DB.Repositories.TaskExecutions.GetLatestByTaskID(ctx, task.ID)
 		require.NoError(s.T(), err)
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.LoadOutputs(ctx, []*models.TaskExecution{finalExecution}))
 		assert.Equal(s.T(), models.ExecutionStatusCompleted, finalExecution.Status)
 		assert.Equal(s.T(), "Hello, World!\n", *finalExecution.Stdout)
 	})
 }
 
+// TestExecutionOutput validates storing execution output in chunks, appending
+// to it and reading it by range
+func (s *DatabaseIntegrationSuite) TestExecutionOutput() {
+	ctx := context.Background()
+	repo := s.DB.Repositories.TaskExecutions
+
+	user := s.DB.CreateMinimalUser(s.T(), ctx, "execution-output@test.com", "Output User")
+	task := s.DB.CreateMinimalTask(s.T(), ctx, user.ID, "Output Task")
+
+	s.Run("appends and reads ranges", func() {
+		execution := testutil.NewExecutionFactory(task.ID).Running().Build()
+		require.NoError(s.T(), repo.Create(ctx, execution))
+
+		large := strings.Repeat("x", models.ExecutionOutputChunkBytes+100)
+		require.NoError(s.T(), repo.AppendOutput(ctx, execution.ID, "hello ", ""))
+		require.NoError(s.T(), repo.AppendOutput(ctx, execution.ID, large, "warning"))
+		require.NoError(s.T(), repo.AppendOutput(ctx, execution.ID, "bye", ""))
+
+		output, err := repo.GetOutput(ctx, execution.ID, "stdout", 3, 6)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), "lo xxx", string(output.Data))
+		assert.Equal(s.T(), int64(len("hello ")+len(large)+len("bye")), output.Size)
+
+		output, err = repo.GetOutput(ctx, execution.ID, "stdout", output.Size-5, 100)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), "xxbye", string(output.Data))
+
+		retrieved, err := repo.GetByID(ctx, execution.ID)
+		require.NoError(s.T(), err)
+		assert.Nil(s.T(), retrieved.Stdout, "output is only loaded on request")
+
+		require.NoError(s.T(), repo.LoadOutputs(ctx, []*models.TaskExecution{retrieved}))
+		assert.Equal(s.T(), "hello "+large+"bye", *retrieved.Stdout)
+		assert.Equal(s.T(), "warning", *retrieved.Stderr)
+	})
+
+	s.Run("update replaces the streams that are set", func() {
+		execution := testutil.NewExecutionFactory(task.ID).WithOutput("first", "error").Build()
+		require.NoError(s.T(), repo.Create(ctx, execution))
+
+		execution.Stdout = testutil.StringPtr("second")
+		execution.Stderr = nil
+		require.NoError(s.T(), repo.Update(ctx, execution))
+
+		retrieved, err := repo.GetByID(ctx, execution.ID)
+		require.NoError(s.T(), err)
+		require.NoError(s.T(), repo.LoadOutputs(ctx, []*models.TaskExecution{retrieved}))
+		assert.Equal(s.T(), "second", *retrieved.Stdout)
+		assert.Equal(s.T(), "error", *retrieved.Stderr)
+
+		output, err := repo.GetOutput(ctx, execution.ID, "stdout", 0, 100)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), "second", string(output.Data))
+		assert.Equal(s.T(), int64(len("second")), output.Size)
+	})
+}
+
+// TestTaskTrash validates moving tasks to the trash, restoring and purging them
+func (s *DatabaseIntegrationSuite) TestTaskTrash() {
+	ctx := context.Background()
+
+	s.Run("delete, restore and purge", func() {
+		user := s.DB.CreateMinimalUser(s.T(), ctx, "task-trash@test.com", "Trash User")
+
+		task := testutil.NewTaskFactory(user.ID).WithName("Trashed Task").Build()
+		require.NoError(s.T(), s.DB.Repositories.Tasks.Create(ctx, task))
+
+		// Deleted tasks are hidden from the other queries
+		require.NoError(s.T(), s.DB.Repositories.Tasks.Delete(ctx, task.ID, task.Version))
+		_, err := s.DB.Repositories.Tasks.GetByID(ctx, task.ID)
+		assert.ErrorIs(s.T(), err, database.ErrTaskNotFound)
+		count, err := s.DB.Repositories.Tasks.CountByUserID(ctx, user.ID)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), int64(0), count)
+
+		deleted, err := s.DB.Repositories.Tasks.GetDeletedByID(ctx, task.ID)
+		require.NoError(s.T(), err)
+		assert.NotNil(s.T(), deleted.DeletedAt)
+
+		trash, _, err := s.DB.Repositories.Tasks.Search(ctx, database.TaskFilter{UserID: &user.ID, Deleted: true}, database.CursorPaginationRequest{})
+		require.NoError(s.T(), err)
+		require.Len(s.T(), trash, 1)
+		assert.Equal(s.T(), task.ID, trash[0].ID)
+
+		// Restored tasks are back
+		require.NoError(s.T(), s.DB.Repositories.Tasks.Restore(ctx, task.ID))
+		restored, err := s.DB.Repositories.Tasks.GetByID(ctx, task.ID)
+		require.NoError(s.T(), err)
+		assert.Nil(s.T(), restored.DeletedAt)
+
+		// Only tasks in the trash are purged
+		_, err = s.DB.Repositories.Tasks.Purge(ctx, task.ID)
+		assert.ErrorIs(s.T(), err, database.ErrTaskNotFound)
+		require.NoError(s.T(), s.DB.Repositories.Tasks.Delete(ctx, task.ID, restored.Version))
+		purged, executionIDs, err := s.DB.Repositories.Tasks.PurgeDeleted(ctx, time.Now().Add(time.Minute), 10)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), int64(1), purged)
+		assert.Empty(s.T(), executionIDs)
+		_, err = s.DB.Repositories.Tasks.GetDeletedByID(ctx, task.ID)
+		assert.ErrorIs(s.T(), err, database.ErrTaskNotFound)
+	})
+}
+
+// TestExecutionRetention validates the queries the execution retention
+// policy is applied with
+func (s *DatabaseIntegrationSuite) TestExecutionRetention() {
+	ctx := context.Background()
+
+	s.Run("keep last and expire output", func() {
+		user := s.DB.CreateMinimalUser(s.T(), ctx, "execution-retention@test.com", "Retention User")
+		task := s.DB.CreateMinimalTask(s.T(), ctx, user.ID, "Retention Task")
+
+		executions := make([]*models.TaskExecution, 3)
+		for i := range executions {
+			executions[i] = testutil.NewExecutionFactory(task.ID).Completed().WithOutput("output", "").Build()
+			require.NoError(s.T(), s.DB.Repositories.TaskExecutions.Create(ctx, executions[i]))
+		}
+
+		// Only the oldest execution is beyond the newest two of the task
+		beyond, err := s.DB.Repositories.TaskExecutions.GetBeyondKeepLast(ctx, 2, 1000)
+		require.NoError(s.T(), err)
+		var ids []uuid.UUID
+		for _, execution := range beyond {
+			if execution.TaskID == task.ID {
+				ids = append(ids, execution.ID)
+			}
+		}
+		require.Equal(s.T(), []uuid.UUID{executions[0].ID}, ids)
+
+		deleted, err := s.DB.Repositories.TaskExecutions.DeleteByIDs(ctx, ids)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), int64(1), deleted)
+
+		// Expired executions keep their summary without output
+		expired, err := s.DB.Repositories.TaskExecutions.ExpireOutput(ctx, []uuid.UUID{executions[1].ID})
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), int64(1), expired)
+
+		retrieved, err := s.DB.Repositories.TaskExecutions.GetByID(ctx, executions[1].ID)
+		require.NoError(s.T(), err)
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.LoadOutputs(ctx, []*models.TaskExecution{retrieved}))
+		assert.Equal(s.T(), models.ExecutionStatusCompleted, retrieved.Status)
+		assert.Nil(s.T(), retrieved.Stdout)
+		assert.NotNil(s.T(), retrieved.OutputExpiredAt)
+
+		expired, err = s.DB.Repositories.TaskExecutions.ExpireOutput(ctx, []uuid.UUID{executions[1].ID})
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), int64(0), expired, "output is expired once")
+	})
+}
+
+// TestExecutionPartitions validates the management of the monthly partitions
+// of executions
+func (s *DatabaseIntegrationSuite) TestExecutionPartitions() {
+	ctx := context.Background()
+	partitions := s.DB.Repositories.ExecutionPartitions
+
+	s.Run("executions land in the partition of their month", func() {
+		user := s.DB.CreateMinimalUser(s.T(), ctx, "execution-partitions@test.com", "Partition User")
+		task := s.DB.CreateMinimalTask(s.T(), ctx, user.ID, "Partition Task")
+
+		_, err := partitions.Create(ctx, time.Now())
+		require.NoError(s.T(), err)
+
+		execution := testutil.NewExecutionFactory(task.ID).Completed().Build()
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.Create(ctx, execution))
+		require.NoError(s.T(), s.DB.Repositories.NetworkEvents.CreateBatch(ctx, execution.ID, []models.NetworkEvent{
+			{Host: "example.com", Port: 443, Allowed: true, OccurredAt: time.Now()},
+		}))
+
+		var partition string
+		err = s.DB.DB.Pool.QueryRow(ctx, `SELECT tableoid::regclass::text FROM task_executions WHERE id = $1`, execution.ID).Scan(&partition)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), "task_executions_p"+time.Now().UTC().Format("200601"), partition)
+
+		events, err := s.DB.Repositories.NetworkEvents.GetByExecutionID(ctx, execution.ID, 10)
+		require.NoError(s.T(), err)
+		assert.Len(s.T(), events, 1)
+	})
+
+	s.Run("create, list and drop", func() {
+		month := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		created, err := partitions.Create(ctx, month)
+		require.NoError(s.T(), err)
+		assert.True(s.T(), created)
+		created, err = partitions.Create(ctx, month.AddDate(0, 0, 14))
+		require.NoError(s.T(), err)
+		assert.False(s.T(), created, "a partition is created once per month")
+
+		list, err := partitions.List(ctx)
+		require.NoError(s.T(), err)
+		require.NotEmpty(s.T(), list)
+		partition := list[len(list)-1]
+		assert.Equal(s.T(), database.ExecutionPartition{Name: "task_executions_p209901", From: month, To: month.AddDate(0, 1, 0)}, partition)
+
+		count, err := partitions.Count(ctx, partition)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), int64(0), count.Executions)
+
+		var truncated []uuid.UUID
+		err = partitions.WithLock(ctx, func(locked database.ExecutionPartitionRepository) error {
+			var err error
+			truncated, err = locked.Drop(ctx, partition)
+			return err
+		})
+		require.NoError(s.T(), err)
+		assert.Empty(s.T(), truncated)
+		list, err = partitions.List(ctx)
+		require.NoError(s.T(), err)
+		assert.NotContains(s.T(), list, partition)
+	})
+
+	s.Run("executions of the default partition are moved to their month", func() {
+		user := s.DB.CreateMinimalUser(s.T(), ctx, "default-partition@test.com", "Default Partition User")
+		task := s.DB.CreateMinimalTask(s.T(), ctx, user.ID, "Default Partition Task")
+		month := time.Date(2098, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		// Date the execution in a month without a partition before adding
+		// the rows that reference it
+		execution := testutil.NewExecutionFactory(task.ID).Completed().Build()
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.Create(ctx, execution))
+		_, err := s.DB.DB.Pool.Exec(ctx, `UPDATE task_executions SET created_at = $2 WHERE id = $1`, execution.ID, month.AddDate(0, 0, 14))
+		require.NoError(s.T(), err)
+		require.NoError(s.T(), s.DB.Repositories.NetworkEvents.CreateBatch(ctx, execution.ID, []models.NetworkEvent{
+			{Host: "example.com", Port: 443, Allowed: true, OccurredAt: time.Now()},
+		}))
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.AppendOutput(ctx, execution.ID, "kept", ""))
+
+		var partition string
+		err = s.DB.DB.Pool.QueryRow(ctx, `SELECT tableoid::regclass::text FROM task_executions WHERE id = $1`, execution.ID).Scan(&partition)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "task_executions_default", partition)
+
+		created, err := partitions.Create(ctx, month)
+		require.NoError(s.T(), err)
+		assert.True(s.T(), created)
+
+		err = s.DB.DB.Pool.QueryRow(ctx, `SELECT tableoid::regclass::text FROM task_executions WHERE id = $1`, execution.ID).Scan(&partition)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), "task_executions_p209805", partition)
+
+		events, err := s.DB.Repositories.NetworkEvents.GetByExecutionID(ctx, execution.ID, 10)
+		require.NoError(s.T(), err)
+		assert.Len(s.T(), events, 1, "network events are kept")
+
+		output, err := s.DB.Repositories.TaskExecutions.GetOutput(ctx, execution.ID, "stdout", 0, 100)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), "kept", string(output.Data), "output is kept")
+
+		_, err = partitions.Drop(ctx, database.ExecutionPartition{Name: partition, From: month, To: month.AddDate(0, 1, 0)})
+		require.NoError(s.T(), err)
+	})
+}
+
 // TestTaskExecutionScenarios validates various execution scenarios
 func (s *DatabaseIntegrationSuite) TestTaskExecutionScenarios() {
 	ctx := context.Background()
@@ -178,6 +437,9 @@ func (s *DatabaseIntegrationSuite) TestTaskExecutionScenarios() {
 		require.NoError(s.T(), err)
 		assert.Equal(s.T(), models.ExecutionStatusFailed, retrieved.Status)
 		assert.Equal(s.T(), 1, *retrieved.ReturnCode)
+		assert.Nil(s.T(), retrieved.Stderr, "output is only loaded on request")
+
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.LoadOutputs(ctx, []*models.TaskExecution{retrieved}))
 		assert.Contains(s.T(), *retrieved.Stderr, "Something went wrong")
 	})
 
@@ -62,13 +62,14 @@ func (s *E2EIntegrationSuite) SetupSuite() {
 	taskExecutorService := services.NewTaskExecutorService(
 		taskExecutionService,
 		s.DB.Repositories.Tasks,
+		s.DB.Repositories.TaskRevisions,
 		mockExecutor,
 		nil, // cleanup manager not needed for mock executor
 		log.Logger,
 	)
 
 	workerManager := &mockWorkerManager{}
-	routes.Setup(router, s.Config, log, s.DB.DB, s.DB.Repositories, s.AuthService, taskExecutionService, taskExecutorService, workerManager)
+	routes.Setup(router, s.Config, log, s.DB.DB, s.DB.Repositories, s.AuthService, taskExecutionService, taskExecutorService, workerManager, nil, nil, nil, nil)
 
 	// Initialize helpers
 	s.HTTP = testutil.NewHTTPHelper(router, s.AuthService)
@@ -529,6 +530,7 @@ func (s *E2EIntegrationSuite) TestDataConsistencyAcrossOperations() {
 
 		finalDbExecution, err := s.DB.Repositories.TaskExecutions.GetByID(ctx, execution.ID)
 		require.NoError(s.T(), err)
+		require.NoError(s.T(), s.DB.Repositories.TaskExecutions.LoadOutputs(ctx, []*models.TaskExecution{finalDbExecution}))
 		assert.Equal(s.T(), models.ExecutionStatusCompleted, finalDbExecution.Status)
 		assert.Equal(s.T(), *updateReq.ReturnCode, *finalDbExecution.ReturnCode)
 		assert.Equal(s.T(), *updateReq.Stdout, *finalDbExecution.Stdout)
@@ -108,6 +108,9 @@ func GetTestConfig() *config.Config {
 			User:     getEnvOrDefault("TEST_DB_USER", "testuser"),
 			Password: getEnvOrDefault("TEST_DB_PASSWORD", "testpassword"),
 			SSLMode:  getEnvOrDefault("TEST_DB_SSLMODE", "disable"),
+
+			MaxConns:         config.DefaultDatabaseMaxConns,
+			StatementTimeout: config.DefaultDatabaseStatementTimeout,
 		},
 		JWT: config.JWTConfig{
 			SecretKey:            getEnvOrDefault("JWT_SECRET_KEY", "test-secret-key-for-integration"),
@@ -133,8 +136,10 @@ func (h *DatabaseHelper) CleanupDatabase(t *testing.T) {
 
 	// Delete in correct order to avoid foreign key constraints
 	queries := []string{
+		"DELETE FROM bulk_jobs",
 		"DELETE FROM task_executions",
 		"DELETE FROM tasks",
+		"DELETE FROM task_templates",
 		"DELETE FROM users",
 	}
 
@@ -59,6 +59,7 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	taskExecutorService := services.NewTaskExecutorService(
 		taskExecutionService,
 		s.DB.Repositories.Tasks,
+		s.DB.Repositories.TaskRevisions,
 		mockExecutor,
 		nil, // cleanup manager not needed for mock executor
 		log.Logger,
@@ -67,7 +68,7 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	// Create mock worker manager for integration tests (nil since embedded workers disabled in tests)
 	var mockWorkerManager worker.WorkerManager = nil
 
-	routes.Setup(router, s.DB.Config, log, s.DB.DB, s.DB.Repositories, authService, taskExecutionService, taskExecutorService, mockWorkerManager)
+	routes.Setup(router, s.DB.Config, log, s.DB.DB, s.DB.Repositories, authService, taskExecutionService, taskExecutorService, mockWorkerManager, nil, nil, nil, nil)
 
 	// Initialize HTTP helper
 	s.HTTP = NewHTTPHelper(router, authService)